@@ -0,0 +1,126 @@
+// Command fix-timezone moves the timestamps written before the times were
+// kept in UTC back to UTC. The create and update hooks used to add 7 hours
+// to the current time, so created_at, updated_at and confirmed_at are ahead
+// of the real time by that much. Run it once, after the migration and before
+// the new version starts writing; it records itself in data_fixes and will
+// not run again.
+//
+// The default shift of -7h is right for PostgreSQL and for MySQL on a server
+// in UTC. MySQL on a server in Asia/Jakarta also wrote its local time, so the
+// values there are 14 hours ahead: run it with -shift=-14h. Use -dry-run to
+// see how many rows would change.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"gorm.io/gorm"
+)
+
+const fixName = "timezone-utc"
+
+// shiftedColumns are the columns each table had written with the offset.
+var shiftedColumns = map[string][]string{
+	entity.ApprovalChain{}.TableName():             {"created_at", "updated_at"},
+	entity.ApprovalChainStep{}.TableName():         {"created_at", "updated_at"},
+	entity.ApprovalDelegation{}.TableName():        {"created_at", "updated_at"},
+	entity.ApprovalSLA{}.TableName():               {"created_at", "updated_at"},
+	entity.AuditLog{}.TableName():                  {"created_at", "updated_at"},
+	entity.BatchHeader{}.TableName():               {"created_at", "updated_at"},
+	entity.BatchLine{}.TableName():                 {"created_at", "updated_at"},
+	entity.BudgetLedgerEntry{}.TableName():         {"created_at", "updated_at"},
+	entity.DocumentSequence{}.TableName():          {"created_at", "updated_at"},
+	entity.JobPlafon{}.TableName():                 {"created_at", "updated_at"},
+	entity.Major{}.TableName():                     {"created_at", "updated_at"},
+	entity.ManpowerAttachment{}.TableName():        {"created_at", "updated_at"},
+	entity.MPPlanningApprovalHistory{}.TableName(): {"created_at", "updated_at"},
+	entity.MPPlanningHeader{}.TableName():          {"created_at", "updated_at"},
+	entity.MPPlanningLine{}.TableName():            {"created_at", "updated_at"},
+	entity.MPPlanningLineImport{}.TableName():      {"created_at", "updated_at", "confirmed_at"},
+	entity.MPPlanningLineImportRow{}.TableName():   {"created_at", "updated_at"},
+	entity.MPRequestApprovalHistory{}.TableName():  {"created_at", "updated_at"},
+	entity.MPRequestHeader{}.TableName():           {"created_at", "updated_at"},
+	(&entity.MPPPeriod{}).TableName():              {"created_at", "updated_at"},
+	entity.OutboxMessage{}.TableName():             {"created_at", "updated_at"},
+	entity.PlafonOverride{}.TableName():            {"created_at", "updated_at"},
+	entity.RequestCategory{}.TableName():           {"created_at", "updated_at"},
+	entity.RequestMajor{}.TableName():              {"created_at", "updated_at"},
+}
+
+func main() {
+	shift := flag.Duration("shift", -7*time.Hour, "how much to move the timestamps")
+	dryRun := flag.Bool("dry-run", false, "count the rows without changing them")
+	flag.Parse()
+
+	viper := config.NewViper()
+	log := config.NewLogrus(viper)
+	db := config.NewDatabase()
+
+	if err := db.AutoMigrate(&entity.DataFix{}); err != nil {
+		log.Fatal(err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var applied int64
+		if err := tx.Model(&entity.DataFix{}).Where("name = ?", fixName).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			return errors.New(fixName + " has already been applied")
+		}
+
+		for table, columns := range shiftedColumns {
+			if !tx.Migrator().HasTable(table) {
+				log.Infof("%s does not exist, skipped", table)
+				continue
+			}
+
+			for _, column := range columns {
+				var count int64
+				if err := tx.Table(table).Where(column + " IS NOT NULL").Count(&count).Error; err != nil {
+					return err
+				}
+				if *dryRun {
+					log.Infof("%s.%s: %d rows would move by %s", table, column, count, *shift)
+					continue
+				}
+
+				if err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NOT NULL", table, column, shifted(tx, column), column), shift.Seconds()).Error; err != nil {
+					return err
+				}
+				log.Infof("%s.%s: %d rows moved by %s", table, column, count, *shift)
+			}
+		}
+
+		if *dryRun {
+			return nil
+		}
+		return tx.Create(&entity.DataFix{
+			Name: fixName,
+			Note: "timestamps moved by " + shift.String(),
+		}).Error
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRun {
+		log.Info("Dry run, nothing changed")
+	} else {
+		log.Info("Timestamps moved to UTC")
+	}
+}
+
+// shifted is the SQL of column moved by a number of seconds given as the
+// parameter.
+func shifted(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == "postgres" {
+		return column + " + (? * INTERVAL '1 second')"
+	}
+	return "DATE_ADD(" + column + ", INTERVAL ? SECOND)"
+}
@@ -1,189 +1,145 @@
+// Command migration manages the schema and the reference data:
+//
+//	migration up [-steps=N]    apply the pending migrations, all by default
+//	migration down [-steps=N]  roll back the last N migrations, 1 by default
+//	migration status           list the migrations and whether they are applied
+//	migration create <name>    write an empty SQL migration for both databases
+//	migration seed             upsert the reference data
+//	migration backfill-budget  record the requests made before the budget ledger
+//
+// Without a subcommand it runs up.
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/service"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/migration"
+	"github.com/sirupsen/logrus"
 )
 
+const usage = `usage: migration <command> [flags]
+
+commands:
+  up [-steps=N]     apply the pending migrations, all by default
+  down [-steps=N]   roll back the last N migrations, 1 by default
+  status            list the migrations and whether they are applied
+  create <name>     write an empty SQL migration for both databases
+  seed              upsert the reference data
+  backfill-budget   record the requests made before the budget ledger
+`
+
 func main() {
+	command := "up"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
 	viper := config.NewViper()
 	log := config.NewLogrus(viper)
-	db := config.NewDatabase()
 
-	// migrate the schema
-	err := db.AutoMigrate(
-		&entity.JobPlafon{},
-		&entity.MPPPeriod{},
-		&entity.MPPlanningHeader{},
-		&entity.MPPlanningLine{},
-		&entity.Major{},
-		&entity.ManpowerAttachment{},
-		&entity.RequestCategory{},
-		&entity.MPRequestHeader{},
-		&entity.RequestMajor{},
-		&entity.MPRequestApprovalHistory{},
-		&entity.MPPlanningApprovalHistory{},
-		&entity.BatchHeader{},
-		&entity.BatchLine{})
+	var err error
+	switch command {
+	case "up":
+		err = up(log, args)
+	case "down":
+		err = down(log, args)
+	case "status":
+		err = status(log)
+	case "create":
+		err = create(log, args)
+	case "seed":
+		err = migration.Seed(config.NewDatabase(), log)
+	case "backfill-budget":
+		// run once after upgrading to the budget ledger; requests already in it are skipped
+		err = service.BudgetServiceFactory(viper, log).Backfill()
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
 	if err != nil {
 		log.Fatal(err)
-	} else {
-		log.Info("Migration success")
 	}
+}
 
-	mppPeriods := []entity.MPPPeriod{}
+func up(log *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("up", flag.ExitOnError)
+	steps := flags.Int("steps", 0, "how many migrations to apply, 0 for all")
+	flags.Parse(args)
 
-	startDate1, err := time.Parse("2006-01-02", "2024-06-01")
+	runner, err := migration.NewRunner(config.NewDatabase(), log)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	endDate1, err := time.Parse("2006-01-02", "2025-07-01")
+	applied, err := runner.Up(*steps)
 	if err != nil {
-		log.Fatal(err)
-	}
-	startDate2, err := time.Parse("2006-01-02", "2023-06-01")
-	if err != nil {
-		log.Fatal(err)
-	}
-	endDate2, err := time.Parse("2006-01-02", "2024-07-01")
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	log.Infof("Migration success, %d applied", len(applied))
+	return nil
+}
 
-	budgetDateStart1, err := time.Parse("2006-01-02", "2024-06-01")
+func down(log *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("down", flag.ExitOnError)
+	steps := flags.Int("steps", 1, "how many migrations to roll back")
+	flags.Parse(args)
+
+	runner, err := migration.NewRunner(config.NewDatabase(), log)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	budgetDateEnd1, err := time.Parse("2006-01-02", "2025-07-01")
+	rolledBack, err := runner.Down(*steps)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	budgetDateStart2, err := time.Parse("2006-01-02", "2023-06-01")
+	log.Infof("Rollback success, %d rolled back", len(rolledBack))
+	return nil
+}
+
+func status(log *logrus.Logger) error {
+	runner, err := migration.NewRunner(config.NewDatabase(), log)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	budgetDateEnd2, err := time.Parse("2006-01-02", "2024-07-01")
+	statuses, err := runner.Status()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	mppPeriods = append(mppPeriods, entity.MPPPeriod{
-		Title:           "MPP Period 1",
-		StartDate:       startDate1,
-		EndDate:         endDate1,
-		BudgetStartDate: budgetDateStart1,
-		BudgetEndDate:   budgetDateEnd1,
-		Status:          entity.MPPeriodStatusOpen,
-	}, entity.MPPPeriod{
-		Title:           "MPP Period 2",
-		StartDate:       startDate2,
-		EndDate:         endDate2,
-		BudgetStartDate: budgetDateStart2,
-		BudgetEndDate:   budgetDateEnd2,
-		Status:          entity.MPPeriodStatusComplete,
-	})
-
-	for _, mppPeriod := range mppPeriods {
-		if err := db.Create(&mppPeriod).Error; err != nil {
-			log.Fatalf("Error when creating mppPeriod: " + err.Error())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
 		}
-	}
-
-	requestCategories := []entity.RequestCategory{
-		{
-			Name:          "Undur Diri",
-			IsReplacement: true,
-		},
-		{
-			Name:          "Dimutasikan",
-			IsReplacement: true,
-		},
-		{
-			Name:          "Pensiun",
-			IsReplacement: true,
-		},
-		{
-			Name:          "Diberhentikan",
-			IsReplacement: true,
-		},
-		{
-			Name:          "Promosi",
-			IsReplacement: true,
-		},
-		{
-			Name:          "Meninggal Dunia",
-			IsReplacement: true,
-		},
-		{
-			Name:          "Posisi Baru",
-			IsReplacement: false,
-		},
-		{
-			Name:          "Pegawai Baru",
-			IsReplacement: false,
-		},
-	}
-
-	for _, requestCategory := range requestCategories {
-		if err := db.Create(&requestCategory).Error; err != nil {
-			log.Fatalf("Error when creating requestCategory: " + err.Error())
+		if s.Unknown {
+			appliedAt += " (not in this build)"
 		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Version, s.Name, appliedAt)
 	}
+	return w.Flush()
+}
 
-	majors := []entity.Major{
-		{
-			Major:          "S1 Teknik Informatika",
-			EducationLevel: entity.EducationLevelEnumBachelor,
-		},
-		{
-			Major:          "S2 Teknik Elektro",
-			EducationLevel: entity.EducationLevelEnumBachelor,
-		},
-		{
-			Major:          "S3 Teknik Mesin",
-			EducationLevel: entity.EducationLevelEnumBachelor,
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumD1,
-			Major:          "D1 Teknik Informatika",
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumD2,
-			Major:          "D2 Teknik Informatika",
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumSD,
-			Major:          "SD",
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumSMP,
-			Major:          "SMP",
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumSMA,
-			Major:          "SMA",
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumMaster,
-			Major:          "S2 Teknik Informatika",
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumDoctoral,
-			Major:          "S3 Teknik Informatika",
-		},
-		{
-			EducationLevel: entity.EducationLevelEnumTK,
-			Major:          "Belum Sekolah",
-		},
+func create(log *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	dir := flags.String("dir", "internal/migration/sql", "where the SQL migrations are")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
 	}
 
-	for _, major := range majors {
-		if err := db.Create(&major).Error; err != nil {
-			log.Fatalf("Error when creating major: " + err.Error())
-		}
+	files, err := migration.Create(*dir, flags.Arg(0), time.Now())
+	for _, file := range files {
+		log.Infof("Created %s", file)
 	}
-
-	log.Info("Seed success")
+	return err
 }
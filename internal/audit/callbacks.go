@@ -0,0 +1,376 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SystemActor is the actor of the changes made outside of a request, by the
+// consumers and the scheduled jobs.
+const SystemActor = "system"
+
+const (
+	oldRecordsKey     = "audit:old_records"
+	updatedColumnsKey = "audit:updated_columns"
+)
+
+// ignoredFields change with every update and tell nothing of the change.
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+	"version":    true,
+}
+
+type auditor struct {
+	tables map[string]bool
+}
+
+// Register adds the audit callbacks for tables to db. They run inside the
+// transaction of the change, so a change is never kept without its audit log.
+func Register(db *gorm.DB, tables []string) error {
+	a := &auditor{tables: make(map[string]bool, len(tables))}
+	for _, table := range tables {
+		a.tables[table] = true
+	}
+
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("audit:after_create", a.afterCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:begin_transaction").Before("gorm:update").Register("audit:before_update", a.beforeUpdate); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("audit:after_update", a.afterUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:begin_transaction").Before("gorm:delete").Register("audit:before_delete", a.beforeChange); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("audit:after_delete", a.afterDelete)
+}
+
+func (a *auditor) audited(db *gorm.DB) bool {
+	return db.Error == nil && !db.DryRun && a.tables[db.Statement.Table]
+}
+
+// beforeUpdate keeps the columns an update is about to change. An update of
+// ignored fields only, like a version claim, is not read at all.
+func (a *auditor) beforeUpdate(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	columns := updatedColumns(db.Statement)
+	if columns != nil {
+		ignored := true
+		for _, column := range columns {
+			ignored = ignored && ignoredFields[column]
+		}
+		if ignored {
+			return
+		}
+		db.InstanceSet(updatedColumnsKey, columns)
+	}
+
+	a.keepAffectedRecords(db, columns)
+}
+
+// beforeChange keeps the records a delete is about to remove.
+func (a *auditor) beforeChange(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	a.keepAffectedRecords(db, nil)
+}
+
+func (a *auditor) keepAffectedRecords(db *gorm.DB, columns []string) {
+	records, err := a.affectedRecords(db, columns)
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	db.InstanceSet(oldRecordsKey, records)
+}
+
+func (a *auditor) afterCreate(db *gorm.DB) {
+	if !a.audited(db) || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return
+	}
+
+	var ids []interface{}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	reflectValue := db.Statement.ReflectValue
+	switch reflectValue.Kind() {
+	case reflect.Struct:
+		if id, isZero := field.ValueOf(db.Statement.Context, reflectValue); !isZero {
+			ids = append(ids, id)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < reflectValue.Len(); i++ {
+			if id, isZero := field.ValueOf(db.Statement.Context, reflect.Indirect(reflectValue.Index(i))); !isZero {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	records, err := a.records(db, ids, nil)
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+
+	logs := make([]entity.AuditLog, 0, len(records))
+	for _, record := range records {
+		logs = append(logs, newLog(db, entity.AuditActionCreate, record, "", nil, recordText(record)))
+	}
+	a.write(db, logs)
+}
+
+func (a *auditor) afterUpdate(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	oldRecords := instanceRecords(db)
+	if len(oldRecords) == 0 {
+		return
+	}
+
+	// read back rather than taken from the statement, the new values are
+	// often SQL expressions only the database knows the result of
+	ids := make([]interface{}, 0, len(oldRecords))
+	for _, record := range oldRecords {
+		ids = append(ids, recordID(record))
+	}
+	columns, _ := db.InstanceGet(updatedColumnsKey)
+	newRecords, err := a.records(db, ids, columnsOf(columns))
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	updated := make(map[string]map[string]interface{}, len(newRecords))
+	for _, record := range newRecords {
+		updated[recordID(record)] = record
+	}
+
+	var logs []entity.AuditLog
+	for _, oldRecord := range oldRecords {
+		newRecord, ok := updated[recordID(oldRecord)]
+		if !ok {
+			continue
+		}
+
+		fields := make([]string, 0, len(oldRecord))
+		for field := range oldRecord {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			if ignoredFields[field] {
+				continue
+			}
+			oldValue, newValue := valueText(oldRecord[field]), valueText(newRecord[field])
+			if sameText(oldValue, newValue) {
+				continue
+			}
+			logs = append(logs, newLog(db, entity.AuditActionUpdate, oldRecord, field, oldValue, newValue))
+		}
+	}
+	a.write(db, logs)
+}
+
+func (a *auditor) afterDelete(db *gorm.DB) {
+	if !a.audited(db) {
+		return
+	}
+
+	oldRecords := instanceRecords(db)
+	logs := make([]entity.AuditLog, 0, len(oldRecords))
+	for _, record := range oldRecords {
+		logs = append(logs, newLog(db, entity.AuditActionDelete, record, "", recordText(record), nil))
+	}
+	a.write(db, logs)
+}
+
+// affectedRecords are the records the conditions of the statement match, read
+// before the statement changes them, with only the columns when given. A
+// statement without conditions is refused by GORM anyway.
+func (a *auditor) affectedRecords(db *gorm.DB, columns []string) ([]map[string]interface{}, error) {
+	stmt := db.Statement
+	query := selectColumns(db.Session(&gorm.Session{NewDB: true}).Table(stmt.Table), columns)
+	conditioned := false
+
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(where)
+			conditioned = true
+		}
+	}
+
+	if stmt.Schema != nil {
+		if stmt.ReflectValue.Kind() == reflect.Struct {
+			for _, field := range stmt.Schema.PrimaryFields {
+				if value, isZero := field.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
+					query = query.Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: value})
+					conditioned = true
+				}
+			}
+		}
+		if stmt.Schema.LookUpField("DeletedAt") != nil && !stmt.Unscoped {
+			query = query.Where("deleted_at IS NULL")
+		}
+	}
+
+	if !conditioned {
+		return nil, nil
+	}
+
+	var records []map[string]interface{}
+	if err := query.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// records reads the records with the ids as they are now, deleted or not,
+// with only the columns when given.
+func (a *auditor) records(db *gorm.DB, ids []interface{}, columns []string) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	query := selectColumns(db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table), columns)
+	if err := query.Where("id IN ?", ids).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// updatedColumns are the columns an update sets, nil when it may set the
+// whole record, e.g. a Save or an Updates with a struct.
+func updatedColumns(stmt *gorm.Statement) []string {
+	values, ok := stmt.Dest.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	columns := make([]string, 0, len(values))
+	for name := range values {
+		if stmt.Schema != nil {
+			if field := stmt.Schema.LookUpField(name); field != nil {
+				name = field.DBName
+			}
+		}
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func selectColumns(query *gorm.DB, columns []string) *gorm.DB {
+	if columns == nil {
+		return query
+	}
+	return query.Select(append([]string{"id"}, columns...))
+}
+
+func columnsOf(value interface{}) []string {
+	columns, _ := value.([]string)
+	return columns
+}
+
+func (a *auditor) write(db *gorm.DB, logs []entity.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&logs).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+	}
+}
+
+func instanceRecords(db *gorm.DB) []map[string]interface{} {
+	records, ok := db.InstanceGet(oldRecordsKey)
+	if !ok {
+		return nil
+	}
+	oldRecords, _ := records.([]map[string]interface{})
+	return oldRecords
+}
+
+func newLog(db *gorm.DB, action entity.AuditAction, record map[string]interface{}, field string, oldValue *string, newValue *string) entity.AuditLog {
+	actor := ActorFrom(db.Statement.Context)
+	if actor.ID == "" && actor.Name == "" {
+		actor.Name = SystemActor
+	}
+
+	return entity.AuditLog{
+		Action:    action,
+		Entity:    db.Statement.Table,
+		EntityID:  recordID(record),
+		Field:     field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		ActorID:   actor.ID,
+		ActorName: actor.Name,
+		RequestID: RequestIDFrom(db.Statement.Context),
+	}
+}
+
+func recordID(record map[string]interface{}) string {
+	if id := valueText(record["id"]); id != nil {
+		return *id
+	}
+	return ""
+}
+
+// recordText is the whole record as JSON.
+func recordText(record map[string]interface{}) *string {
+	values := make(map[string]interface{}, len(record))
+	for field, value := range record {
+		if bytes, ok := value.([]byte); ok {
+			value = string(bytes)
+		}
+		values[field] = value
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	text := string(data)
+	return &text
+}
+
+// valueText is how a value read from the database is written to the audit
+// log, nil for NULL.
+func valueText(value interface{}) *string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case time.Time:
+		text = v.Format(time.RFC3339Nano)
+	default:
+		text = fmt.Sprint(v)
+	}
+	return &text
+}
+
+func sameText(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
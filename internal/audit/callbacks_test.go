@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func mockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:                 logger.Discard,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(db, entity.AuditedTables); err != nil {
+		t.Fatal(err)
+	}
+	mock.MatchExpectationsInOrder(true)
+	return db, mock
+}
+
+func TestAuditVersionClaim(t *testing.T) {
+	db, mock := mockDB(t)
+
+	// only ignored fields change, so nothing is read or logged
+	mock.ExpectExec("UPDATE `mp_planning_lines` SET `version`=version \\+ 1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := db.Model(&entity.MPPlanningLine{}).Where("id = ?", uuid.New()).UpdateColumn("version", gorm.Expr("version + 1")).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAuditExpressionUpdate(t *testing.T) {
+	db, mock := mockDB(t)
+	id := uuid.New().String()
+
+	// both reads are limited to the updated column, the new value is read back
+	mock.ExpectQuery("SELECT id,remaining_balance_ph FROM `mp_planning_lines` WHERE id IN").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "remaining_balance_ph"}).AddRow(id, 5))
+	mock.ExpectExec("UPDATE `mp_planning_lines` SET `remaining_balance_ph`=recruit_ph - 2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id,remaining_balance_ph FROM `mp_planning_lines` WHERE id IN").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "remaining_balance_ph"}).AddRow(id, 3))
+	mock.ExpectExec("INSERT INTO `audit_logs`").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, entity.AuditActionUpdate, "mp_planning_lines", id, "remaining_balance_ph", "5", "3", SystemActor).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	updates := map[string]interface{}{"remaining_balance_ph": gorm.Expr("recruit_ph - 2")}
+	if err := db.Model(&entity.MPPlanningLine{}).Where("id IN ?", []string{id}).UpdateColumns(updates).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
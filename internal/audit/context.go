@@ -0,0 +1,49 @@
+// Package audit records who changed what in the audited tables. GORM
+// callbacks compare each audited record before and after a create, update or
+// delete and write the difference to the audit log, in the same transaction as
+// the change. The actor and the request are taken from the context of the
+// statement, so a change is attributed as long as the context of the request
+// reaches the database; changes made without one are attributed to the system.
+// Statements run with Exec or Raw skip the callbacks and are not audited, so
+// the audited tables are only written through the model API.
+package audit
+
+import "context"
+
+// Actor is the user a change is made by.
+type Actor struct {
+	ID   string
+	Name string
+}
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFrom is the actor of ctx, the zero Actor when there is none.
+func ActorFrom(ctx context.Context) Actor {
+	if ctx == nil {
+		return Actor{}
+	}
+	actor, _ := ctx.Value(actorKey).(Actor)
+	return actor
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestIDFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
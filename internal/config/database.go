@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/audit"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
 	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -51,14 +53,20 @@ func NewDatabase() *gorm.DB {
 
 		var dsn string
 		var db *gorm.DB
+		// times are kept in UTC, the API shows them in the business timezone
+		gormConfig := &gorm.Config{
+			NowFunc: func() time.Time {
+				return time.Now().UTC()
+			},
+		}
 
 		switch driver {
 		case "mysql":
-			dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local", username, password, host, port, database)
-			db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+			dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=UTC", username, password, host, port, database)
+			db, err = gorm.Open(mysql.Open(dsn), gormConfig)
 		case "postgres":
-			dsn = fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=disable", host, port, username, database, password)
-			db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+			dsn = fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=disable TimeZone=UTC", host, port, username, database, password)
+			db, err = gorm.Open(postgres.Open(dsn), gormConfig)
 		default:
 			log.Fatalf("unsupported database driver: %s", driver)
 		}
@@ -76,6 +84,10 @@ func NewDatabase() *gorm.DB {
 		connection.SetMaxOpenConns(maxConnection)
 		connection.SetConnMaxLifetime(time.Second * time.Duration(maxLifeTimeConnection))
 
+		if err := audit.Register(db, entity.AuditedTables); err != nil {
+			log.Fatalf("failed to register audit callbacks: %v", err)
+		}
+
 		dbInstance = db
 	})
 
@@ -21,6 +21,10 @@ func NewValidator(viper *viper.Viper) *validator.Validate {
 	validate.RegisterValidation("MPRequestApprovalHistoryStatusValidation", request.MPRequestApprovalHistoryStatusValidation)
 	validate.RegisterValidation("MPRequestApprovalHistoryLevelValidation", request.MPRequestApprovalHistoryLevelValidation)
 	validate.RegisterValidation("BatchHeaderApproverTypeValidation", request.BatchHeaderApproverTypeValidation)
+	validate.RegisterValidation("ApprovalChainDocumentTypeValidation", request.ApprovalChainDocumentTypeValidation)
+	validate.RegisterValidation("ApprovalChainStepConditionValidation", request.ApprovalChainStepConditionValidation)
+	validate.RegisterValidation("ApprovalDelegationScopeValidation", request.ApprovalDelegationScopeValidation)
+	validate.RegisterValidation("PlafonOverrideDecisionValidation", request.PlafonOverrideDecisionValidation)
 	validate.RegisterValidation("date_today_or_later", request.ValidateDateMoreThanEqualToday)
 	return validate
 }
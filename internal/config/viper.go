@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/spf13/viper"
 )
 
@@ -18,5 +19,9 @@ func NewViper() *viper.Viper {
 		panic(fmt.Errorf("Fatal error config file: %w \n", err))
 	}
 
+	if err := timezone.Configure(config.GetString("app.timezone")); err != nil {
+		panic(fmt.Errorf("Fatal error app.timezone: %w \n", err))
+	}
+
 	return config
 }
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApprovalChainDocumentType string
+
+const (
+	ApprovalChainDocumentTypeMPPlanning ApprovalChainDocumentType = "mp_planning"
+	ApprovalChainDocumentTypeMPRequest  ApprovalChainDocumentType = "mp_request"
+	ApprovalChainDocumentTypeBatch      ApprovalChainDocumentType = "batch"
+)
+
+type ApprovalChain struct {
+	gorm.Model     `json:"-"`
+	ID             uuid.UUID                 `json:"id" gorm:"type:char(36);primaryKey;"`
+	OrganizationID *uuid.UUID                `json:"organization_id" gorm:"type:char(36);not null;"`
+	DocumentType   ApprovalChainDocumentType `json:"document_type" gorm:"type:varchar(50);not null;"`
+	Name           string                    `json:"name" gorm:"type:varchar(255);not null;"`
+	IsActive       bool                      `json:"is_active" gorm:"type:boolean;default:false;"`
+
+	ApprovalChainSteps []ApprovalChainStep `json:"approval_chain_steps" gorm:"foreignKey:ApprovalChainID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	OrganizationName string `json:"organization_name" gorm:"-"`
+}
+
+// NextStep returns the step that follows the given level, skipping steps whose
+// condition does not match the MP request type. An unknown level starts the
+// chain from its first applicable step; nil means the chain is finished.
+func (m *ApprovalChain) NextStep(currentLevel string, mpRequestType MPRequestTypeEnum) *ApprovalChainStep {
+	steps := make([]*ApprovalChainStep, 0, len(m.ApprovalChainSteps))
+	for i := range m.ApprovalChainSteps {
+		if m.ApprovalChainSteps[i].AppliesTo(mpRequestType) {
+			steps = append(steps, &m.ApprovalChainSteps[i])
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Sequence < steps[j].Sequence
+	})
+
+	for i, step := range steps {
+		if step.Level != currentLevel {
+			continue
+		}
+		if i+1 < len(steps) {
+			return steps[i+1]
+		}
+		return nil
+	}
+
+	return steps[0]
+}
+
+func (m *ApprovalChain) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *ApprovalChain) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (ApprovalChain) TableName() string {
+	return "approval_chains"
+}
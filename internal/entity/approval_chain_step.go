@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApprovalChainStepCondition string
+
+const (
+	ApprovalChainStepConditionAll       ApprovalChainStepCondition = "ALL"
+	ApprovalChainStepConditionOnBudget  ApprovalChainStepCondition = "ON_BUDGET"
+	ApprovalChainStepConditionOffBudget ApprovalChainStepCondition = "OFF_BUDGET"
+)
+
+type ApprovalChainStep struct {
+	gorm.Model      `json:"-"`
+	ID              uuid.UUID                  `json:"id" gorm:"type:char(36);primaryKey;"`
+	ApprovalChainID uuid.UUID                  `json:"approval_chain_id" gorm:"type:char(36);not null;"`
+	Sequence        int                        `json:"sequence" gorm:"type:int;not null;"`
+	Level           string                     `json:"level" gorm:"type:varchar(255);not null;"`
+	ApproverID      *uuid.UUID                 `json:"approver_id" gorm:"type:char(36);"`
+	ApproverName    string                     `json:"approver_name" gorm:"type:varchar(255);"`
+	Condition       ApprovalChainStepCondition `json:"condition" gorm:"type:varchar(50);default:'ALL';"`
+
+	ApprovalChain ApprovalChain `json:"-" gorm:"foreignKey:ApprovalChainID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// AppliesTo reports whether the step takes part in the chain for the given
+// MP request type. Steps without a budget condition always apply.
+func (m *ApprovalChainStep) AppliesTo(mpRequestType MPRequestTypeEnum) bool {
+	switch m.Condition {
+	case ApprovalChainStepConditionOnBudget:
+		return mpRequestType == MPRequestTypeEnumOnBudget
+	case ApprovalChainStepConditionOffBudget:
+		return mpRequestType == MPRequestTypeEnumOffBudget
+	default:
+		return true
+	}
+}
+
+func (m *ApprovalChainStep) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *ApprovalChainStep) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (ApprovalChainStep) TableName() string {
+	return "approval_chain_steps"
+}
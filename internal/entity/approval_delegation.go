@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApprovalDelegationScope string
+
+const (
+	ApprovalDelegationScopeAll        ApprovalDelegationScope = "all"
+	ApprovalDelegationScopeMPPlanning ApprovalDelegationScope = "mp_planning"
+	ApprovalDelegationScopeMPRequest  ApprovalDelegationScope = "mp_request"
+	ApprovalDelegationScopeBatch      ApprovalDelegationScope = "batch"
+)
+
+type ApprovalDelegation struct {
+	gorm.Model    `json:"-"`
+	ID            uuid.UUID               `json:"id" gorm:"type:char(36);primaryKey;"`
+	DelegatorID   *uuid.UUID              `json:"delegator_id" gorm:"type:char(36);not null;"` // employee_id
+	DelegatorName string                  `json:"delegator_name" gorm:"type:varchar(255);"`
+	DelegateID    *uuid.UUID              `json:"delegate_id" gorm:"type:char(36);not null;"` // employee_id
+	DelegateName  string                  `json:"delegate_name" gorm:"type:varchar(255);"`
+	Scope         ApprovalDelegationScope `json:"scope" gorm:"type:varchar(50);default:'all';"`
+	StartDate     time.Time               `json:"start_date" gorm:"type:date;not null;"`
+	EndDate       time.Time               `json:"end_date" gorm:"type:date;not null;"`
+	Reason        string                  `json:"reason" gorm:"type:text;"`
+}
+
+func (m *ApprovalDelegation) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *ApprovalDelegation) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (ApprovalDelegation) TableName() string {
+	return "approval_delegations"
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApprovalSLA holds the reminder and escalation deadlines, in working days,
+// for documents waiting in NEED APPROVAL at one approval level. An empty Level
+// is the default for every level of the document type.
+type ApprovalSLA struct {
+	gorm.Model         `json:"-"`
+	ID                 uuid.UUID                 `json:"id" gorm:"type:char(36);primaryKey;"`
+	DocumentType       ApprovalChainDocumentType `json:"document_type" gorm:"type:varchar(50);not null;"`
+	Level              string                    `json:"level" gorm:"type:varchar(255);"`
+	ReminderAfterDays  int                       `json:"reminder_after_days" gorm:"type:int;not null;"`
+	EscalateAfterDays  int                       `json:"escalate_after_days" gorm:"type:int;not null;"`
+	EscalateToLevel    string                    `json:"escalate_to_level" gorm:"type:varchar(255);"`
+	BackupApproverID   *uuid.UUID                `json:"backup_approver_id" gorm:"type:char(36);"` // employee_id
+	BackupApproverName string                    `json:"backup_approver_name" gorm:"type:varchar(255);"`
+	PermissionName     string                    `json:"permission_name" gorm:"type:varchar(255);"` // users notified when the approver is unknown
+	IsActive           bool                      `json:"is_active" gorm:"type:boolean;default:false;"`
+}
+
+func (m *ApprovalSLA) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *ApprovalSLA) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (ApprovalSLA) TableName() string {
+	return "approval_slas"
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "CREATE"
+	AuditActionUpdate AuditAction = "UPDATE"
+	AuditActionDelete AuditAction = "DELETE"
+)
+
+// AuditLog is one change of an audited record. An update is recorded field by
+// field with the old and the new value; a create keeps the whole new record
+// and a delete the whole old one, as JSON, with Field left empty.
+type AuditLog struct {
+	gorm.Model `json:"-"`
+	ID         uuid.UUID   `json:"id" gorm:"type:char(36);primaryKey;"`
+	Action     AuditAction `json:"action" gorm:"type:varchar(10);not null"`
+	Entity     string      `json:"entity" gorm:"type:varchar(100);not null;index:idx_audit_logs_entity"` // table of the record
+	EntityID   string      `json:"entity_id" gorm:"type:varchar(36);not null;index:idx_audit_logs_entity"`
+	Field      string      `json:"field" gorm:"type:varchar(100);default:null"`
+	OldValue   *string     `json:"old_value" gorm:"type:text;default:null"`
+	NewValue   *string     `json:"new_value" gorm:"type:text;default:null"`
+	ActorID    string      `json:"actor_id" gorm:"type:varchar(36);default:null;index"`
+	ActorName  string      `json:"actor_name" gorm:"type:varchar(255);default:null"`
+	RequestID  string      `json:"request_id" gorm:"type:varchar(64);default:null;index"`
+}
+
+// AuditedTables are the tables whose changes are kept in the audit log.
+var AuditedTables = []string{
+	MPPlanningHeader{}.TableName(),
+	MPPlanningLine{}.TableName(),
+	MPRequestHeader{}.TableName(),
+	BatchHeader{}.TableName(),
+	BatchLine{}.TableName(),
+	(&MPPPeriod{}).TableName(),
+	JobPlafon{}.TableName(),
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	a.CreatedAt = time.Now().UTC()
+	a.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -34,18 +34,27 @@ type BatchHeader struct {
 	ApproverType   BatchHeaderApproverType   `json:"approver_type" gorm:"type:varchar(255);default:CEO"`
 	OrganizationID *uuid.UUID                `json:"organization_id" gorm:"type:char(36);default:null;"`
 
+	ApprovalRequestedAt *time.Time `json:"approval_requested_at" gorm:"default:null;"` // start of the current approval step, for SLA tracking
+	ApprovalRemindedAt  *time.Time `json:"approval_reminded_at" gorm:"default:null;"`
+	EscalatedAt         *time.Time `json:"escalated_at" gorm:"default:null;"`
+	EscalatedLevel      string     `json:"escalated_level" gorm:"type:varchar(255);default:null;"` // approver type the SLA escalated the current step to
+	Version             int        `json:"version" gorm:"type:int;not null;default:1"`             // moves on with every change, for optimistic locking
+
 	BatchLines []BatchLine `json:"batch_lines" gorm:"foreignKey:BatchHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 func (m *BatchHeader) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(time.Hour * 7)
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	if m.Version == 0 {
+		m.Version = 1
+	}
 	return nil
 }
 
 func (m *BatchHeader) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
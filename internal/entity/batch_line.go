@@ -24,13 +24,13 @@ type BatchLine struct {
 
 func (m *BatchLine) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(time.Hour * 7)
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *BatchLine) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
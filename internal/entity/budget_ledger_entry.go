@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BudgetLedgerEntryType string
+
+const (
+	BudgetLedgerEntryTypeReservation BudgetLedgerEntryType = "RESERVATION"
+	BudgetLedgerEntryTypeConsumption BudgetLedgerEntryType = "CONSUMPTION"
+	BudgetLedgerEntryTypeRelease     BudgetLedgerEntryType = "RELEASE"
+)
+
+// BudgetBalance is the planning line balance an entry draws from.
+type BudgetBalance string
+
+const (
+	BudgetBalanceMT BudgetBalance = "MT"
+	BudgetBalancePH BudgetBalance = "PH"
+)
+
+// BudgetLedgerEntry records what a manpower request holds on one balance of a
+// planning line. Reserved and Consumed are signed changes: a reservation adds
+// to Reserved, a consumption moves its quantity from Reserved to Consumed and
+// a release takes back whatever the request still holds. The remaining
+// balances of the line are its recruits less the sums of both.
+type BudgetLedgerEntry struct {
+	gorm.Model        `json:"-"`
+	ID                uuid.UUID             `json:"id" gorm:"type:char(36);primaryKey;"`
+	MPRequestHeaderID uuid.UUID             `json:"mp_request_header_id" gorm:"type:char(36);not null;index"`
+	MPPlanningLineID  uuid.UUID             `json:"mp_planning_line_id" gorm:"type:char(36);not null;index"`
+	EntryType         BudgetLedgerEntryType `json:"entry_type" gorm:"type:varchar(20);not null"`
+	Balance           BudgetBalance         `json:"balance" gorm:"type:varchar(5);not null"`
+	Quantity          int                   `json:"quantity" gorm:"type:int;default:0"`
+	Reserved          int                   `json:"reserved" gorm:"type:int;default:0"`
+	Consumed          int                   `json:"consumed" gorm:"type:int;default:0"`
+	MPRequestStatus   MPRequestStatus       `json:"mp_request_status" gorm:"type:varchar(50)"` // status the request moved to
+	MPRequestHeader   MPRequestHeader       `json:"-" gorm:"foreignKey:MPRequestHeaderID;references:ID"`
+}
+
+func (m *BudgetLedgerEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *BudgetLedgerEntry) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (BudgetLedgerEntry) TableName() string {
+	return "budget_ledger_entries"
+}
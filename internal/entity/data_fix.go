@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataFix is a one-time correction of stored data that has been applied, so
+// it is never applied twice.
+type DataFix struct {
+	gorm.Model `json:"-"`
+	ID         uuid.UUID `json:"id" gorm:"type:char(36);primaryKey;"`
+	Name       string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Note       string    `json:"note" gorm:"type:text;default:null"`
+}
+
+func (m *DataFix) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (DataFix) TableName() string {
+	return "data_fixes"
+}
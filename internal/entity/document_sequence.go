@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DocumentSequence is the last number given to a document type in a period.
+// The row is locked while a number is taken, so numbers are handed out one
+// at a time and a rolled back document gives its number back.
+type DocumentSequence struct {
+	gorm.Model   `json:"-"`
+	ID           uuid.UUID `json:"id" gorm:"type:char(36);primaryKey;"`
+	DocumentType string    `json:"document_type" gorm:"type:varchar(50);not null;uniqueIndex:idx_document_sequences_type_period"`
+	Period       string    `json:"period" gorm:"type:varchar(20);not null;uniqueIndex:idx_document_sequences_type_period"` // empty when the numbers never reset
+	LastNumber   int       `json:"last_number" gorm:"type:int;not null;default:0"`
+}
+
+func (m *DocumentSequence) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *DocumentSequence) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (DocumentSequence) TableName() string {
+	return "document_sequences"
+}
@@ -18,14 +18,16 @@ type JobPlafon struct {
 }
 
 func (m *JobPlafon) BeforeCreate(tx *gorm.DB) (err error) {
-	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(7 * time.Hour)
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *JobPlafon) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
@@ -34,13 +34,13 @@ type Major struct {
 
 func (m *Major) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(7 * time.Hour)
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *Major) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
@@ -19,13 +19,13 @@ type ManpowerAttachment struct {
 
 func (m *ManpowerAttachment) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(7 * time.Hour)
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *ManpowerAttachment) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
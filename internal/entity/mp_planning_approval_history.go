@@ -13,6 +13,7 @@ const (
 	MPPlanningApprovalHistoryStatusApproved     MPPlanningApprovalHistoryStatus = "APPROVED"
 	MPPlanningApprovalHistoryStatusRejected     MPPlanningApprovalHistoryStatus = "REJECTED"
 	MPPlanningApprovalHistoryStatusNeedApproval MPPlanningApprovalHistoryStatus = "NEED APPROVAL"
+	MPPlanningApprovalHistoryStatusEscalated    MPPlanningApprovalHistoryStatus = "ESCALATED"
 )
 
 type MPPlanningApprovalHistoryLevel string
@@ -30,6 +31,8 @@ type MPPlanningApprovalHistory struct {
 	MPPlanningHeaderID uuid.UUID                       `json:"mp_planning_header_id" gorm:"type:char(36);"`
 	ApproverID         uuid.UUID                       `json:"approver_id" gorm:"type:char(36);"`
 	ApproverName       string                          `json:"approver_name" gorm:"type:varchar(255);"`
+	OnBehalfOfID       *uuid.UUID                      `json:"on_behalf_of_id" gorm:"type:char(36);"` // employee_id of the delegator
+	OnBehalfOfName     string                          `json:"on_behalf_of_name" gorm:"type:varchar(255);"`
 	Notes              string                          `json:"notes" gorm:"type:text;"`
 	Level              string                          `json:"level" gorm:"type:varchar(255);"`
 	Status             MPPlanningApprovalHistoryStatus `json:"status" gorm:"not null"`
@@ -40,13 +43,13 @@ type MPPlanningApprovalHistory struct {
 
 func (m *MPPlanningApprovalHistory) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(7 * time.Hour)
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *MPPlanningApprovalHistory) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
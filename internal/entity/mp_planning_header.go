@@ -41,6 +41,11 @@ type MPPlanningHeader struct {
 	NotesManager           string          `json:"notes_manager" gorm:"type:text;"`
 	ApproverRecruitmentID  *uuid.UUID      `json:"approver_recruitment_id" gorm:"type:char(36);"` // user_id
 	NotesRecruitment       string          `json:"notes_recruitment" gorm:"type:text;"`
+	NextApproverID         *uuid.UUID      `json:"next_approver_id" gorm:"type:char(36);"` // employee_id, from the approval chain
+	NextApproverLevel      string          `json:"next_approver_level" gorm:"type:varchar(255);"`
+	ApprovalRequestedAt    *time.Time      `json:"approval_requested_at" gorm:"default:null;"` // start of the current approval step, for SLA tracking
+	ApprovalRemindedAt     *time.Time      `json:"approval_reminded_at" gorm:"default:null;"`
+	Version                int             `json:"version" gorm:"type:int;not null;default:1"` // moves on with every change, for optimistic locking
 	// CreatedAt              time.Time       `json:"created_at" gorm:"autoCreateTime"`
 
 	MPPPeriod                   MPPPeriod                   `json:"mpp_period" gorm:"foreignKey:MPPPeriodID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
@@ -62,15 +67,18 @@ type MPPlanningHeader struct {
 
 func (m *MPPlanningHeader) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(time.Hour * 7)
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	if m.Version == 0 {
+		m.Version = 1
+	}
 	// m.UpdatedAt = time.Now()
 	// m.CreatedAt = m.UpdatedAt
 	return nil
 }
 
 func (m *MPPlanningHeader) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
@@ -9,20 +9,23 @@ import (
 
 type MPPlanningLine struct {
 	gorm.Model             `json:"-"`
-	ID                     uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey;"`
-	MPPlanningHeaderID     uuid.UUID  `json:"mp_planning_header_id" gorm:"type:char(36);not null"`
-	OrganizationLocationID *uuid.UUID `json:"organization_location_id" gorm:"type:char(36)"`
-	JobLevelID             *uuid.UUID `json:"job_level_id" gorm:"type:char(36);not null"`
-	JobID                  *uuid.UUID `json:"job_id" gorm:"type:char(36);not null"`
-	Existing               int        `json:"existing" gorm:"type:int;default:0"`
-	Recruit                int        `json:"recruit" gorm:"type:int;default:0"`
-	SuggestedRecruit       int        `json:"suggested_recruit" gorm:"type:int;default:0"`
-	Promotion              int        `json:"promotion" gorm:"type:int;default:0"`
-	Total                  int        `json:"total" gorm:"type:int;default:0"`
-	RecruitPH              int        `json:"recruit_ph" gorm:"type:int;default:0"`
-	RemainingBalancePH     int        `json:"remaining_balance_ph" gorm:"type:int;default:0"`
-	RecruitMT              int        `json:"recruit_mt" gorm:"type:int;default:0"`
-	RemainingBalanceMT     int        `json:"remaining_balance_mt" gorm:"type:int;default:0"`
+	ID                     uuid.UUID            `json:"id" gorm:"type:char(36);primaryKey;"`
+	MPPlanningHeaderID     uuid.UUID            `json:"mp_planning_header_id" gorm:"type:char(36);not null"`
+	OrganizationLocationID *uuid.UUID           `json:"organization_location_id" gorm:"type:char(36)"`
+	JobLevelID             *uuid.UUID           `json:"job_level_id" gorm:"type:char(36);not null"`
+	JobID                  *uuid.UUID           `json:"job_id" gorm:"type:char(36);not null"`
+	Existing               int                  `json:"existing" gorm:"type:int;default:0"`
+	Recruit                int                  `json:"recruit" gorm:"type:int;default:0"`
+	SuggestedRecruit       int                  `json:"suggested_recruit" gorm:"type:int;default:0"`
+	Promotion              int                  `json:"promotion" gorm:"type:int;default:0"`
+	Total                  int                  `json:"total" gorm:"type:int;default:0"`
+	RecruitPH              int                  `json:"recruit_ph" gorm:"type:int;default:0"`
+	RemainingBalancePH     int                  `json:"remaining_balance_ph" gorm:"type:int;default:0"`
+	RecruitMT              int                  `json:"recruit_mt" gorm:"type:int;default:0"`
+	RemainingBalanceMT     int                  `json:"remaining_balance_mt" gorm:"type:int;default:0"`
+	IsOverPlafon           bool                 `json:"is_over_plafon" gorm:"type:boolean;default:false"`
+	PlafonOverrideStatus   PlafonOverrideStatus `json:"plafon_override_status" gorm:"type:varchar(20);default:null"`
+	Version                int                  `json:"version" gorm:"type:int;not null;default:1"` // moves on with every change, for optimistic locking
 
 	MPPlanningHeader         MPPlanningHeader `json:"mp_planning_header" gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	OrganizationLocationName string           `json:"organization_location_name" gorm:"-"`
@@ -33,13 +36,16 @@ type MPPlanningLine struct {
 
 func (m *MPPlanningLine) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(7 * time.Hour)
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	if m.Version == 0 {
+		m.Version = 1
+	}
 	return nil
 }
 
 func (m *MPPlanningLine) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
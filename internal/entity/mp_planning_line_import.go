@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MPPlanningLineImportStatus string
+
+const (
+	MPPlanningLineImportStatusPending   MPPlanningLineImportStatus = "PENDING"   // dry run, waiting for the user to confirm
+	MPPlanningLineImportStatusConfirmed MPPlanningLineImportStatus = "CONFIRMED" // lines created or updated
+)
+
+// MPPlanningLineImport is a spreadsheet of planning lines uploaded for a
+// planning header. Its rows are checked and kept as a dry run; the lines are
+// only saved once the user confirms it.
+type MPPlanningLineImport struct {
+	gorm.Model         `json:"-"`
+	ID                 uuid.UUID                  `json:"id" gorm:"type:char(36);primaryKey;"`
+	MPPlanningHeaderID uuid.UUID                  `json:"mp_planning_header_id" gorm:"type:char(36);not null;index"`
+	FileName           string                     `json:"file_name" gorm:"type:varchar(255);not null"`
+	Status             MPPlanningLineImportStatus `json:"status" gorm:"type:varchar(20);default:'PENDING'"`
+	TotalRows          int                        `json:"total_rows" gorm:"type:int;default:0"`
+	ErrorRows          int                        `json:"error_rows" gorm:"type:int;default:0"`
+	HeaderVersion      int                        `json:"header_version" gorm:"type:int;default:0"`      // version of the planning the dry run was checked against
+	ImportedBy         *uuid.UUID                 `json:"imported_by" gorm:"type:char(36);default:null"` // employee_id
+	ConfirmedAt        *time.Time                 `json:"confirmed_at" gorm:"default:null"`
+
+	MPPlanningLineImportRows []MPPlanningLineImportRow `json:"mp_planning_line_import_rows" gorm:"foreignKey:MPPlanningLineImportID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (m *MPPlanningLineImport) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *MPPlanningLineImport) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (MPPlanningLineImport) TableName() string {
+	return "mp_planning_line_imports"
+}
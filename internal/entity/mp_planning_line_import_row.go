@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MPPlanningLineImportRow is a row of an import as it was read, with the ids
+// its names resolved to. A row with errors is not saved on confirm; a row
+// matching a line of the header by location, job level and job updates it.
+type MPPlanningLineImportRow struct {
+	gorm.Model               `json:"-"`
+	ID                       uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey;"`
+	MPPlanningLineImportID   uuid.UUID  `json:"mp_planning_line_import_id" gorm:"type:char(36);not null;index"`
+	RowNumber                int        `json:"row_number" gorm:"type:int;not null"` // row of the spreadsheet, the header is row 1
+	OrganizationLocationName string     `json:"organization_location_name" gorm:"type:varchar(255)"`
+	JobLevelName             string     `json:"job_level_name" gorm:"type:varchar(255)"`
+	JobName                  string     `json:"job_name" gorm:"type:varchar(255)"`
+	OrganizationLocationID   *uuid.UUID `json:"organization_location_id" gorm:"type:char(36);default:null"`
+	JobLevelID               *uuid.UUID `json:"job_level_id" gorm:"type:char(36);default:null"`
+	JobID                    *uuid.UUID `json:"job_id" gorm:"type:char(36);default:null"`
+	Existing                 int        `json:"existing" gorm:"type:int;default:0"`
+	RecruitMT                int        `json:"recruit_mt" gorm:"type:int;default:0"`
+	RecruitPH                int        `json:"recruit_ph" gorm:"type:int;default:0"`
+	Promotion                int        `json:"promotion" gorm:"type:int;default:0"`
+	MPPlanningLineID         *uuid.UUID `json:"mp_planning_line_id" gorm:"type:char(36);default:null"` // line the row updates, nil when it creates one
+	Errors                   string     `json:"errors" gorm:"type:text;default:null"`                  // one per line
+}
+
+func (m *MPPlanningLineImportRow) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *MPPlanningLineImportRow) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (MPPlanningLineImportRow) TableName() string {
+	return "mp_planning_line_import_rows"
+}
@@ -14,6 +14,7 @@ const (
 	MPRequestApprovalHistoryStatusRejected     MPRequestApprovalHistoryStatus = "REJECTED"
 	MPRequestApprovalHistoryStatusNeedApproval MPRequestApprovalHistoryStatus = "NEED APPROVAL"
 	MPRequestApprovalHistoryStatusCompleted    MPRequestApprovalHistoryStatus = "COMPLETED"
+	MPRequestApprovalHistoryStatusEscalated    MPRequestApprovalHistoryStatus = "ESCALATED"
 )
 
 type MPRequestApprovalHistoryLevel string
@@ -32,6 +33,8 @@ type MPRequestApprovalHistory struct {
 	MPRequestHeaderID uuid.UUID                      `json:"mp_request_header_id" gorm:"type:char(36);"`
 	ApproverID        uuid.UUID                      `json:"approver_id" gorm:"type:char(36);"`
 	ApproverName      string                         `json:"approver_name" gorm:"type:varchar(255);"`
+	OnBehalfOfID      *uuid.UUID                     `json:"on_behalf_of_id" gorm:"type:char(36);"` // employee_id of the delegator
+	OnBehalfOfName    string                         `json:"on_behalf_of_name" gorm:"type:varchar(255);"`
 	Notes             string                         `json:"notes" gorm:"type:text;"`
 	Level             string                         `json:"level" gorm:"type:varchar(255);"`
 	Status            MPRequestApprovalHistoryStatus `json:"status" gorm:"not null"`
@@ -42,13 +45,13 @@ type MPRequestApprovalHistory struct {
 
 func (m *MPRequestApprovalHistory) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(7 * time.Hour)
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *MPRequestApprovalHistory) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
@@ -30,15 +30,26 @@ type MPPPeriod struct {
 	MPPlanningHeaders []MPPlanningHeader `json:"mp_planning_headers" gorm:"foreignKey:MPPPeriodID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
+// SavedStatus returns the status the period is saved with on date: a period
+// that is not a draft stays not open until its start date.
+func (m *MPPPeriod) SavedStatus(date time.Time) MPPPeriodStatus {
+	if m.Status != MPPPeriodStatusDraft && date.Format("2006-01-02") < m.StartDate.Format("2006-01-02") {
+		return MPPeriodStatusNotOpen
+	}
+	return m.Status
+}
+
 func (m *MPPPeriod) BeforeCreate(tx *gorm.DB) (err error) {
-	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(7 * time.Hour)
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *MPPPeriod) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(7 * time.Hour)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OutboxMessageStatus string
+
+const (
+	OutboxMessageStatusPending OutboxMessageStatus = "PENDING"
+	OutboxMessageStatusSent    OutboxMessageStatus = "SENT"
+	OutboxMessageStatusFailed  OutboxMessageStatus = "FAILED" // retried after NextAttemptAt
+	OutboxMessageStatusDead    OutboxMessageStatus = "DEAD"   // gave up, waits for a manual replay
+)
+
+type OutboxMessageType string
+
+const (
+	OutboxMessageTypeNotification   OutboxMessageType = "notification"
+	OutboxMessageTypeCloneMPRequest OutboxMessageType = "clone_mp_request"
+	OutboxMessageTypeDomainEvent    OutboxMessageType = "domain_event" // destination is the routing key
+)
+
+// OutboxMessage is a message to another service that is saved in the same
+// transaction as the state change it belongs to and delivered later by the
+// outbox relay.
+type OutboxMessage struct {
+	gorm.Model    `json:"-"`
+	ID            uuid.UUID           `json:"id" gorm:"type:char(36);primaryKey;"`
+	MessageType   OutboxMessageType   `json:"message_type" gorm:"type:varchar(100);not null;index"`
+	Destination   string              `json:"destination" gorm:"type:varchar(255);not null;"` // queue name or service
+	Payload       string              `json:"payload" gorm:"type:text;not null;"`
+	Status        OutboxMessageStatus `json:"status" gorm:"type:varchar(20);default:'PENDING';index"`
+	Attempts      int                 `json:"attempts" gorm:"type:int;default:0"`
+	NextAttemptAt time.Time           `json:"next_attempt_at" gorm:"not null;index"`
+	LastError     string              `json:"last_error" gorm:"type:text;default:null"`
+	SentAt        *time.Time          `json:"sent_at" gorm:"default:null"`
+}
+
+// NewOutboxMessage returns a pending outbox message with the payload encoded as JSON.
+func NewOutboxMessage(messageType OutboxMessageType, destination string, payload interface{}) (*OutboxMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		MessageType:   messageType,
+		Destination:   destination,
+		Payload:       string(data),
+		Status:        OutboxMessageStatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+func (m *OutboxMessage) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *OutboxMessage) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PlafonOverrideDocumentType string
+
+const (
+	PlafonOverrideDocumentTypeMPPlanningLine PlafonOverrideDocumentType = "mp_planning_line"
+	PlafonOverrideDocumentTypeMPRequest      PlafonOverrideDocumentType = "mp_request"
+)
+
+type PlafonOverrideStatus string
+
+const (
+	PlafonOverrideStatusPending  PlafonOverrideStatus = "PENDING"
+	PlafonOverrideStatusApproved PlafonOverrideStatus = "APPROVED"
+	PlafonOverrideStatusRejected PlafonOverrideStatus = "REJECTED"
+)
+
+// PlafonOverride asks to let a planning line or manpower request take a job
+// over its plafon in a period. Headcount is the job's total in the period,
+// the document included, at the time the override was requested.
+type PlafonOverride struct {
+	gorm.Model   `json:"-"`
+	ID           uuid.UUID                  `json:"id" gorm:"type:char(36);primaryKey;"`
+	DocumentType PlafonOverrideDocumentType `json:"document_type" gorm:"type:varchar(50);not null;index:idx_plafon_overrides_document"`
+	DocumentID   uuid.UUID                  `json:"document_id" gorm:"type:char(36);not null;index:idx_plafon_overrides_document"`
+	MPPPeriodID  uuid.UUID                  `json:"mpp_period_id" gorm:"type:char(36);not null;"`
+	JobID        uuid.UUID                  `json:"job_id" gorm:"type:char(36);not null;"`
+	Plafon       int                        `json:"plafon" gorm:"type:int;default:0"`
+	Headcount    int                        `json:"headcount" gorm:"type:int;default:0"`
+	Reason       string                     `json:"reason" gorm:"type:text;not null"`
+	Status       PlafonOverrideStatus       `json:"status" gorm:"type:varchar(20);default:'PENDING'"`
+	ApproverID   *uuid.UUID                 `json:"approver_id" gorm:"type:char(36);"` // employee_id
+	ApproverName string                     `json:"approver_name" gorm:"type:varchar(255);"`
+	Notes        string                     `json:"notes" gorm:"type:text;default:null"`
+	DecidedAt    *time.Time                 `json:"decided_at" gorm:"default:null"`
+}
+
+func (m *PlafonOverride) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (m *PlafonOverride) BeforeUpdate(tx *gorm.DB) (err error) {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (PlafonOverride) TableName() string {
+	return "plafon_overrides"
+}
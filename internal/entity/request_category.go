@@ -18,13 +18,13 @@ type RequestCategory struct {
 
 func (m *RequestCategory) BeforeCreate(tx *gorm.DB) (err error) {
 	m.ID = uuid.New()
-	m.CreatedAt = time.Now().Add(time.Hour * 7)
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
 func (m *RequestCategory) BeforeUpdate(tx *gorm.DB) (err error) {
-	m.UpdatedAt = time.Now().Add(time.Hour * 7)
+	m.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
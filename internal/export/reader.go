@@ -0,0 +1,394 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrTooManyRows is returned when a spreadsheet has more rows than the
+	// reader was allowed to read.
+	ErrTooManyRows = errors.New("spreadsheet has too many rows")
+	// ErrWorkbookTooLarge is returned when an xlsx workbook unzips into more
+	// than the reader is willing to read.
+	ErrWorkbookTooLarge = errors.New("xlsx: the workbook is too large")
+)
+
+// Limits of an xlsx workbook. A few kilobytes of zip can unzip into
+// gigabytes, so every part is read through a limit and the sheet is held to
+// the size a spreadsheet program would give it.
+const (
+	// xlsxMaxRows and xlsxMaxColumns are the size of a sheet, 1 to 1048576
+	// and A to XFD.
+	xlsxMaxRows    = 1048576
+	xlsxMaxColumns = 16384
+	// xlsxMaxPartSize is the most read of a part once unzipped.
+	xlsxMaxPartSize = 64 << 20
+	// xlsxMaxSharedStrings and xlsxMaxSharedStringBytes bound the shared
+	// strings, which are held in memory while the sheet is read.
+	xlsxMaxSharedStrings     = 1 << 20
+	xlsxMaxSharedStringBytes = 16 << 20
+)
+
+// ReadRows reads every row of a csv file, or of the first sheet of an xlsx
+// workbook, as text. At most maxRows rows are read, the header row included.
+// Trailing empty rows are dropped.
+func ReadRows(format Format, r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	var rows [][]string
+	var err error
+	switch format {
+	case FormatCSV:
+		rows, err = readCSVRows(io.NewSectionReader(r, 0, size), maxRows)
+	case FormatXLSX:
+		rows, err = readXLSXRows(r, size, maxRows)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for len(rows) > 0 && isEmptyRow(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows, nil
+}
+
+func readCSVRows(r io.Reader, maxRows int) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: more than %d", ErrTooManyRows, maxRows)
+		}
+		// drop the byte order mark spreadsheet programs put in front of csv files
+		if len(rows) == 0 && len(record) > 0 {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+		rows = append(rows, record)
+	}
+}
+
+// xlsxCell is a cell of a worksheet. Shared strings hold the index of the
+// string in V; inline strings hold the text in IS.
+type xlsxCell struct {
+	Ref  string `xml:"r,attr"`
+	Type string `xml:"t,attr"`
+	V    string `xml:"v"`
+	IS   struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"is"`
+}
+
+func readXLSXRows(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	if maxRows <= 0 || maxRows > xlsxMaxRows {
+		maxRows = xlsxMaxRows
+	}
+
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(z.File))
+	for _, file := range z.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedStrings, err := readSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx: %s is missing", sheetPath)
+	}
+	content, err := openPart(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var rows [][]string
+	decoder := xml.NewDecoder(content)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "row":
+			rowNumber := len(rows) + 1
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "r" {
+					if n, err := strconv.Atoi(attr.Value); err == nil && n > rowNumber {
+						rowNumber = n
+					}
+				}
+			}
+			if rowNumber > maxRows {
+				return nil, fmt.Errorf("%w: more than %d", ErrTooManyRows, maxRows)
+			}
+			// rows left out of the sheet are empty
+			for len(rows) < rowNumber {
+				rows = append(rows, nil)
+			}
+		case "c":
+			if len(rows) == 0 {
+				continue
+			}
+			var cell xlsxCell
+			if err := decoder.DecodeElement(&cell, &start); err != nil {
+				return nil, err
+			}
+
+			row := &rows[len(rows)-1]
+			column := len(*row)
+			if cell.Ref != "" {
+				index, ok := columnIndex(cell.Ref)
+				if !ok && startsWithLetter(cell.Ref) {
+					return nil, fmt.Errorf("xlsx: cell %s is out of the sheet", cell.Ref)
+				}
+				if ok {
+					column = index
+				}
+			}
+			if column >= xlsxMaxColumns {
+				return nil, fmt.Errorf("xlsx: row %d has more than %d columns", len(rows), xlsxMaxColumns)
+			}
+			for len(*row) <= column {
+				*row = append(*row, "")
+			}
+			(*row)[column] = cellText(&cell, sharedStrings)
+		}
+	}
+}
+
+// firstSheetPath follows the workbook relationships to the part of the first
+// sheet.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXMLFile(files["xl/workbook.xml"], &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("xlsx: the workbook has no sheets")
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXMLFile(files["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+
+	return "", errors.New("xlsx: the first sheet has no relationship")
+}
+
+// readSharedStrings reads the shared strings one at a time, so their number
+// and their text can be held to xlsxMaxSharedStrings and
+// xlsxMaxSharedStringBytes.
+func readSharedStrings(file *zip.File) ([]string, error) {
+	if file == nil {
+		return nil, nil
+	}
+
+	content, err := openPart(file)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var values []string
+	var total int
+	decoder := xml.NewDecoder(content)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "si" {
+			continue
+		}
+
+		var item struct {
+			T    string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		}
+		if err := decoder.DecodeElement(&item, &start); err != nil {
+			return nil, err
+		}
+
+		value := item.T
+		for _, run := range item.Runs {
+			value += run.T
+		}
+
+		total += len(value)
+		if len(values) == xlsxMaxSharedStrings || total > xlsxMaxSharedStringBytes {
+			return nil, fmt.Errorf("%w: too many shared strings", ErrWorkbookTooLarge)
+		}
+		values = append(values, value)
+	}
+}
+
+func decodeXMLFile(file *zip.File, v interface{}) error {
+	if file == nil {
+		return errors.New("xlsx: the workbook is incomplete")
+	}
+	content, err := openPart(file)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return xml.NewDecoder(content).Decode(v)
+}
+
+// openPart opens a part of the workbook, read no further than
+// xlsxMaxPartSize.
+func openPart(file *zip.File) (io.ReadCloser, error) {
+	if file.UncompressedSize64 > xlsxMaxPartSize {
+		return nil, fmt.Errorf("%w: %s unzips into %d bytes", ErrWorkbookTooLarge, file.Name, file.UncompressedSize64)
+	}
+	content, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &limitedPart{
+		Reader: io.LimitReader(content, xlsxMaxPartSize+1),
+		Closer: content,
+		name:   file.Name,
+	}, nil
+}
+
+// limitedPart fails once more than xlsxMaxPartSize is read, rather than
+// ending the part early as if it was complete: the size in the zip header is
+// not to be trusted.
+type limitedPart struct {
+	io.Reader
+	io.Closer
+	name string
+	read int64
+}
+
+func (p *limitedPart) Read(b []byte) (int, error) {
+	n, err := p.Reader.Read(b)
+	p.read += int64(n)
+	if p.read > xlsxMaxPartSize {
+		return n, fmt.Errorf("%w: %s unzips into more than %d bytes", ErrWorkbookTooLarge, p.name, xlsxMaxPartSize)
+	}
+	return n, err
+}
+
+func cellText(cell *xlsxCell, sharedStrings []string) string {
+	switch cell.Type {
+	case "s":
+		index, err := strconv.Atoi(strings.TrimSpace(cell.V))
+		if err != nil || index < 0 || index >= len(sharedStrings) {
+			return ""
+		}
+		return sharedStrings[index]
+	case "inlineStr":
+		value := cell.IS.T
+		for _, run := range cell.IS.Runs {
+			value += run.T
+		}
+		return value
+	default:
+		return cell.V
+	}
+}
+
+// columnIndex turns the letters of a cell reference into a zero based column
+// index: A1 is 0, AA7 is 26. References past XFD, the last column of a sheet,
+// are not accepted.
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+		if index > xlsxMaxColumns {
+			return 0, false
+		}
+	}
+	if letters == 0 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+func startsWithLetter(ref string) bool {
+	return ref != "" && ref[0] >= 'A' && ref[0] <= 'Z'
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
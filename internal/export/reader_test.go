@@ -0,0 +1,216 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// workbook zips the parts of an xlsx workbook whose first sheet is sheet1.xml.
+func workbook(t *testing.T, sheetData string, sharedStrings string) []byte {
+	t.Helper()
+
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Lines" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	if sharedStrings != "" {
+		parts["xl/sharedStrings.xml"] = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + sharedStrings + `</sst>`
+	}
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	for name, content := range parts {
+		entry, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readRows(format Format, data []byte, maxRows int) ([][]string, error) {
+	return ReadRows(format, bytes.NewReader(data), int64(len(data)), maxRows)
+}
+
+func TestReadCSVRows(t *testing.T) {
+	data := []byte("\ufeffjob_id, existing\nJ1,3\nJ2\n,\n\n")
+
+	rows, err := readRows(FormatCSV, data, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"job_id", "existing"}, {"J1", "3"}, {"J2"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+
+	if _, err := readRows(FormatCSV, data, 2); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("err = %v, want ErrTooManyRows", err)
+	}
+}
+
+func TestReadRowsUnsupportedFormat(t *testing.T) {
+	if _, err := readRows("ods", nil, 10); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestReadXLSXRowsFromWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, "Lines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"job_id", "notes"}, {"J1", "R&D <lead>"}, {"J2", "  spaced  "}}
+	for _, row := range want {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := readRows(FormatXLSX, buf.Bytes(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSXRows(t *testing.T) {
+	sheetData := `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+		// row 2 is left out of the sheet, row 3 has a number and a rich inline string
+		`<row r="3"><c r="A3"><v>42</v></c><c r="B3" t="inlineStr"><is><r><t>Dri</t></r><r><t>ver</t></r></is></c><c r="D3" t="s"><v>9</v></c></row>` +
+		// cells without a reference follow each other
+		`<row r="4"><c><v>1</v></c><c><v>2</v></c></row>` +
+		`<row r="5"><c r="A5" t="inlineStr"><is><t> </t></is></c></row>`
+	sharedStrings := `<si><t>job_id</t></si><si><r><t>exist</t></r><r><t>ing</t></r></si>`
+
+	rows, err := readRows(FormatXLSX, workbook(t, sheetData, sharedStrings), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"job_id", "", "existing"},
+		nil,
+		{"42", "Driver", "", ""},
+		{"1", "2"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSXRowsLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		maxRows   int
+		wantErr   error
+		wantText  string
+	}{
+		{
+			name:      "too many rows",
+			sheetData: `<row r="1"><c><v>1</v></c></row><row r="2"><c><v>2</v></c></row><row r="3"><c><v>3</v></c></row>`,
+			maxRows:   2,
+			wantErr:   ErrTooManyRows,
+		},
+		{
+			name:      "a row number past the limit",
+			sheetData: `<row r="1048577"><c><v>1</v></c></row>`,
+			maxRows:   0,
+			wantErr:   ErrTooManyRows,
+		},
+		{
+			name:      "a column past XFD",
+			sheetData: `<row r="1"><c r="XFE1"><v>1</v></c></row>`,
+			maxRows:   10,
+			wantText:  "out of the sheet",
+		},
+		{
+			name:      "a broken sheet",
+			sheetData: `<row r="1"><c r="A1"><v>1</v></row>`,
+			maxRows:   10,
+			wantText:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := readRows(FormatXLSX, workbook(t, tt.sheetData, ""), tt.maxRows)
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if tt.wantText != "" && !strings.Contains(err.Error(), tt.wantText) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.wantText)
+		}
+	}
+}
+
+func TestReadXLSXRowsTooManySharedStrings(t *testing.T) {
+	var sharedStrings strings.Builder
+	for i := 0; i <= xlsxMaxSharedStrings; i++ {
+		sharedStrings.WriteString("<si/>")
+	}
+
+	_, err := readRows(FormatXLSX, workbook(t, `<row r="1"><c><v>1</v></c></row>`, sharedStrings.String()), 10)
+	if !errors.Is(err, ErrWorkbookTooLarge) {
+		t.Errorf("err = %v, want ErrWorkbookTooLarge", err)
+	}
+}
+
+func TestReadXLSXRowsNotAWorkbook(t *testing.T) {
+	if _, err := readRows(FormatXLSX, []byte("job_id,existing\n"), 10); err == nil {
+		t.Error("a csv file read as xlsx gave no error")
+	}
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRows(FormatXLSX, buf.Bytes(), 10); err == nil {
+		t.Error("an empty zip gave no error")
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref    string
+		want   int
+		wantOK bool
+	}{
+		{"A1", 0, true},
+		{"Z9", 25, true},
+		{"AA7", 26, true},
+		{"XFD1048576", 16383, true},
+		{"XFE1", 0, false},
+		{"AAAAAAAAAAAAAAAAAAAAAA1", 0, false},
+		{"1", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := columnIndex(tt.ref)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d, %v", tt.ref, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
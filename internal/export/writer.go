@@ -0,0 +1,134 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format, use csv or xlsx")
+
+// ParseFormat reads the format of an export request, csv when it is empty.
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, value)
+	}
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName appends the extension of the format to name.
+func (f Format) FileName(name string) string {
+	return name + "." + string(f)
+}
+
+// Writer writes a table row by row straight to its destination, so an export
+// never holds more than the row being written. Close must be called once
+// every row is written; the output is incomplete until then.
+type Writer interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// NewWriter returns a writer of the format on w. The sheet name is only used
+// by xlsx.
+func NewWriter(format Format, w io.Writer, sheetName string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheetName)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// csvFlushRows is how many rows the csv writer buffers before flushing.
+const csvFlushRows = 500
+
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escapeFormula(cell)
+	}
+	if err := c.w.Write(escaped); err != nil {
+		return err
+	}
+	c.rows++
+	if c.rows%csvFlushRows == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula keeps a spreadsheet opening the csv from running a cell as a
+// formula: text starting with =, +, - or @ gets a leading quote. Numbers are
+// left as they are.
+func escapeFormula(cell string) string {
+	if cell == "" || isNumber(cell) || !strings.ContainsRune("=+-@", rune(cell[0])) {
+		return cell
+	}
+	return "'" + cell
+}
+
+// isNumber reports whether the cell is a number as Int and Float write it.
+// Text that only looks like one, such as 007, is not.
+func isNumber(cell string) bool {
+	value, err := strconv.ParseFloat(cell, 64)
+	return err == nil && !math.IsNaN(value) && !math.IsInf(value, 0) && Float(value) == cell
+}
+
+// Int formats a number cell.
+func Int(value int) string {
+	return strconv.Itoa(value)
+}
+
+// Float formats a decimal cell without trailing zeros.
+func Float(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Date formats a date cell, empty when t is nil or zero.
+func Date(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,183 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatCSV, false},
+		{"csv", FormatCSV, false},
+		{" XLSX ", FormatXLSX, false},
+		{"pdf", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.value)
+		if tt.wantErr {
+			if !errors.Is(err, ErrUnsupportedFormat) {
+				t.Errorf("ParseFormat(%q) err = %v, want ErrUnsupportedFormat", tt.value, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "ignored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]string{
+		{"Job", "Notes"},
+		{"Driver", `says "hi", twice`},
+		{"Clerk", "line\nbreak"},
+		{"=HYPERLINK(\"x\")", "+1"},
+		{"-3", "@sum"},
+		{"-cmd", "2.5"},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Job,Notes\nDriver,\"says \"\"hi\"\", twice\"\nClerk,\"line\nbreak\"\n" +
+		"\"'=HYPERLINK(\"\"x\"\")\",'+1\n-3,'@sum\n'-cmd,2.5\n"
+	if buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, "Plan: 2026/2027")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"Job", "Total"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"R&D <lead>", "12", "007", "-2.5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, file := range z.File {
+		content, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(content)
+		content.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[file.Name] = string(data)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/workbook.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("part %s is missing", name)
+		}
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Plan- 2026-2027"`) {
+		t.Errorf("workbook does not name the sheet: %s", parts["xl/workbook.xml"])
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<row r="1"><c r="A1" t="inlineStr" s="1">`,
+		`<c r="B1" t="inlineStr" s="1"><is><t xml:space="preserve">Total</t></is></c>`,
+		`<c r="B2"><v>12</v></c>`,
+		`<c r="C2" t="inlineStr"><is><t xml:space="preserve">007</t></is></c>`,
+		`<c r="D2"><v>-2.5</v></c>`,
+		`R&amp;D &lt;lead&gt;`,
+		`</sheetData></worksheet>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet does not contain %s", want)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 701: "ZZ", 702: "AAA", 16383: "XFD"}
+	for index, want := range tests {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestSheetTitle(t *testing.T) {
+	tests := map[string]string{
+		"":                                 "Sheet1",
+		"a/b:c":                            "a-b-c",
+		"[x]*?":                            "-x---",
+		strings.Repeat("é", 40):            strings.Repeat("é", 31),
+		"Manpower Planning Batch Document": "Manpower Planning Batch Documen",
+	}
+	for name, want := range tests {
+		if got := sheetTitle(name); got != want {
+			t.Errorf("sheetTitle(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	tests := map[string]bool{"12": true, "-3": true, "2.5": true, "0": true, "007": false, "1e5": false, "2.50": false, "": false, "12 ": false, "NaN": false, "+Inf": false}
+	for cell, want := range tests {
+		if got := isNumber(cell); got != want {
+			t.Errorf("isNumber(%q) = %v, want %v", cell, got, want)
+		}
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	if got := Int(-3); got != "-3" {
+		t.Errorf("Int(-3) = %q", got)
+	}
+	if got := Float(2.50); got != "2.5" {
+		t.Errorf("Float(2.50) = %q", got)
+	}
+	if got := Float(3); got != "3" {
+		t.Errorf("Float(3) = %q", got)
+	}
+
+	date := time.Date(2026, time.October, 17, 23, 0, 0, 0, time.UTC)
+	if got := Date(&date); got != "2026-10-17" {
+		t.Errorf("Date = %q", got)
+	}
+	if got := Date(nil); got != "" {
+		t.Errorf("Date(nil) = %q", got)
+	}
+	if got := Date(&time.Time{}); got != "" {
+		t.Errorf("Date(zero) = %q", got)
+	}
+}
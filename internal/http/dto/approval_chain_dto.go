@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+)
+
+type IApprovalChainDTO interface {
+	ConvertApprovalChainEntityToResponse(approvalChain *entity.ApprovalChain) *response.ApprovalChainResponse
+}
+
+type ApprovalChainDTO struct {
+	log *logrus.Logger
+}
+
+func NewApprovalChainDTO(log *logrus.Logger) IApprovalChainDTO {
+	return &ApprovalChainDTO{
+		log: log,
+	}
+}
+
+func (d *ApprovalChainDTO) ConvertApprovalChainEntityToResponse(approvalChain *entity.ApprovalChain) *response.ApprovalChainResponse {
+	steps := make([]response.ApprovalChainStepResponse, 0, len(approvalChain.ApprovalChainSteps))
+	for _, step := range approvalChain.ApprovalChainSteps {
+		steps = append(steps, response.ApprovalChainStepResponse{
+			ID:           step.ID,
+			Sequence:     step.Sequence,
+			Level:        step.Level,
+			ApproverID:   step.ApproverID,
+			ApproverName: step.ApproverName,
+			Condition:    step.Condition,
+		})
+	}
+
+	return &response.ApprovalChainResponse{
+		ID:               approvalChain.ID,
+		OrganizationID:   approvalChain.OrganizationID,
+		OrganizationName: approvalChain.OrganizationName,
+		DocumentType:     approvalChain.DocumentType,
+		Name:             approvalChain.Name,
+		IsActive:         approvalChain.IsActive,
+		Steps:            steps,
+		CreatedAt:        approvalChain.CreatedAt,
+		UpdatedAt:        approvalChain.UpdatedAt,
+	}
+}
+
+func ApprovalChainDTOFactory(log *logrus.Logger) IApprovalChainDTO {
+	return NewApprovalChainDTO(log)
+}
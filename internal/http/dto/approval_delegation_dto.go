@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+)
+
+type IApprovalDelegationDTO interface {
+	ConvertApprovalDelegationEntityToResponse(delegation *entity.ApprovalDelegation) *response.ApprovalDelegationResponse
+}
+
+type ApprovalDelegationDTO struct {
+	log *logrus.Logger
+}
+
+func NewApprovalDelegationDTO(log *logrus.Logger) IApprovalDelegationDTO {
+	return &ApprovalDelegationDTO{
+		log: log,
+	}
+}
+
+func (d *ApprovalDelegationDTO) ConvertApprovalDelegationEntityToResponse(delegation *entity.ApprovalDelegation) *response.ApprovalDelegationResponse {
+	return &response.ApprovalDelegationResponse{
+		ID:            delegation.ID,
+		DelegatorID:   delegation.DelegatorID,
+		DelegatorName: delegation.DelegatorName,
+		DelegateID:    delegation.DelegateID,
+		DelegateName:  delegation.DelegateName,
+		Scope:         delegation.Scope,
+		StartDate:     delegation.StartDate,
+		EndDate:       delegation.EndDate,
+		Reason:        delegation.Reason,
+		CreatedAt:     delegation.CreatedAt,
+		UpdatedAt:     delegation.UpdatedAt,
+	}
+}
+
+func ApprovalDelegationDTOFactory(log *logrus.Logger) IApprovalDelegationDTO {
+	return NewApprovalDelegationDTO(log)
+}
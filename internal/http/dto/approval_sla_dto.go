@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+)
+
+type IApprovalSLADTO interface {
+	ConvertApprovalSLAEntityToResponse(approvalSLA *entity.ApprovalSLA) *response.ApprovalSLAResponse
+}
+
+type ApprovalSLADTO struct {
+	log *logrus.Logger
+}
+
+func NewApprovalSLADTO(log *logrus.Logger) IApprovalSLADTO {
+	return &ApprovalSLADTO{
+		log: log,
+	}
+}
+
+func (d *ApprovalSLADTO) ConvertApprovalSLAEntityToResponse(approvalSLA *entity.ApprovalSLA) *response.ApprovalSLAResponse {
+	return &response.ApprovalSLAResponse{
+		ID:                 approvalSLA.ID,
+		DocumentType:       approvalSLA.DocumentType,
+		Level:              approvalSLA.Level,
+		ReminderAfterDays:  approvalSLA.ReminderAfterDays,
+		EscalateAfterDays:  approvalSLA.EscalateAfterDays,
+		EscalateToLevel:    approvalSLA.EscalateToLevel,
+		BackupApproverID:   approvalSLA.BackupApproverID,
+		BackupApproverName: approvalSLA.BackupApproverName,
+		PermissionName:     approvalSLA.PermissionName,
+		IsActive:           approvalSLA.IsActive,
+		CreatedAt:          approvalSLA.CreatedAt,
+		UpdatedAt:          approvalSLA.UpdatedAt,
+	}
+}
+
+func ApprovalSLADTOFactory(log *logrus.Logger) IApprovalSLADTO {
+	return NewApprovalSLADTO(log)
+}
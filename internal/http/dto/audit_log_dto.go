@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+)
+
+type IAuditLogDTO interface {
+	ConvertAuditLogEntityToResponse(auditLog *entity.AuditLog) *response.AuditLogResponse
+}
+
+type AuditLogDTO struct {
+	log *logrus.Logger
+}
+
+func NewAuditLogDTO(log *logrus.Logger) IAuditLogDTO {
+	return &AuditLogDTO{
+		log: log,
+	}
+}
+
+func (d *AuditLogDTO) ConvertAuditLogEntityToResponse(auditLog *entity.AuditLog) *response.AuditLogResponse {
+	return &response.AuditLogResponse{
+		ID:        auditLog.ID,
+		Action:    auditLog.Action,
+		Entity:    auditLog.Entity,
+		EntityID:  auditLog.EntityID,
+		Field:     auditLog.Field,
+		OldValue:  auditLog.OldValue,
+		NewValue:  auditLog.NewValue,
+		ActorID:   auditLog.ActorID,
+		ActorName: auditLog.ActorName,
+		RequestID: auditLog.RequestID,
+		CreatedAt: auditLog.CreatedAt,
+	}
+}
+
+func AuditLogDTOFactory(log *logrus.Logger) IAuditLogDTO {
+	return NewAuditLogDTO(log)
+}
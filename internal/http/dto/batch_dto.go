@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"context"
 	"sort"
 	"strconv"
 
@@ -13,11 +14,11 @@ import (
 )
 
 type IBatchDTO interface {
-	ConvertBatchHeaderEntityToResponse(batch *entity.BatchHeader) *response.BatchResponse
-	ConvertToDocumentBatchResponse(batch *entity.BatchHeader, operatingUnit string) *response.DocumentBatchResponse
-	ConvertToDocumentCalculationBatchResponse(mpPlanningLine entity.MPPlanningLine, isTotal bool, planningLines *[]entity.MPPlanningLine) *response.DocumentCalculationBatchResponse
-	ConvertDocumentCalculationBatchResponses(mpPlanningLines []entity.MPPlanningLine) []response.DocumentCalculationBatchResponse
-	ConvertRealDocumentBatchResponse(batch *entity.BatchHeader) *response.RealDocumentBatchResponse
+	ConvertBatchHeaderEntityToResponse(ctx context.Context, batch *entity.BatchHeader) *response.BatchResponse
+	ConvertToDocumentBatchResponse(ctx context.Context, batch *entity.BatchHeader, operatingUnit string) *response.DocumentBatchResponse
+	ConvertToDocumentCalculationBatchResponse(ctx context.Context, mpPlanningLine entity.MPPlanningLine, isTotal bool, planningLines *[]entity.MPPlanningLine) *response.DocumentCalculationBatchResponse
+	ConvertDocumentCalculationBatchResponses(ctx context.Context, mpPlanningLines []entity.MPPlanningLine) []response.DocumentCalculationBatchResponse
+	ConvertRealDocumentBatchResponse(ctx context.Context, batch *entity.BatchHeader) *response.RealDocumentBatchResponse
 }
 
 type BatchDTO struct {
@@ -38,10 +39,10 @@ func NewBatchDTO(log *logrus.Logger, batchLineDTO IBatchLineDTO, jpm messaging.I
 	}
 }
 
-func (d *BatchDTO) ConvertRealDocumentBatchResponse(batch *entity.BatchHeader) *response.RealDocumentBatchResponse {
+func (d *BatchDTO) ConvertRealDocumentBatchResponse(ctx context.Context, batch *entity.BatchHeader) *response.RealDocumentBatchResponse {
 	return &response.RealDocumentBatchResponse{
 		ApproverType: batch.ApproverType,
-		Overall:      *d.ConvertToDocumentBatchResponse(batch, "Julong Group"),
+		Overall:      *d.ConvertToDocumentBatchResponse(ctx, batch, "Julong Group"),
 		OrganizationOverall: func() []response.OrganizationOverallResponse {
 			var organizationOverall []response.OrganizationOverallResponse
 			// group batch lines by organization id
@@ -51,7 +52,7 @@ func (d *BatchDTO) ConvertRealDocumentBatchResponse(batch *entity.BatchHeader) *
 			}
 			for orgID, bls := range groupedBatchLines {
 				// check org name
-				messageResponse, err := d.OrgMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+				messageResponse, err := d.OrgMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 					ID: orgID,
 				})
 				if err != nil {
@@ -59,7 +60,7 @@ func (d *BatchDTO) ConvertRealDocumentBatchResponse(batch *entity.BatchHeader) *
 				}
 				orgName := messageResponse.Name
 				organizationOverall = append(organizationOverall, response.OrganizationOverallResponse{
-					Overall: *d.ConvertToDocumentBatchResponse(&entity.BatchHeader{
+					Overall: *d.ConvertToDocumentBatchResponse(ctx, &entity.BatchHeader{
 						BatchLines: bls,
 					}, orgName),
 					LocationOverall: func() []response.DocumentBatchResponse {
@@ -71,14 +72,14 @@ func (d *BatchDTO) ConvertRealDocumentBatchResponse(batch *entity.BatchHeader) *
 						}
 						for locID, bls := range groupedBatchLines {
 							// check location name
-							messageResponse, err := d.OrgMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+							messageResponse, err := d.OrgMessage.SendFindOrganizationLocationByIDMessage(ctx, request.SendFindOrganizationLocationByIDMessageRequest{
 								ID: locID,
 							})
 							if err != nil {
 								d.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
 							}
 							orgLocationName := messageResponse.Name
-							locationOverall = append(locationOverall, *d.ConvertToDocumentBatchResponse(&entity.BatchHeader{
+							locationOverall = append(locationOverall, *d.ConvertToDocumentBatchResponse(ctx, &entity.BatchHeader{
 								BatchLines: bls,
 							}, orgLocationName+" ("+orgName+")"))
 						}
@@ -91,19 +92,20 @@ func (d *BatchDTO) ConvertRealDocumentBatchResponse(batch *entity.BatchHeader) *
 	}
 }
 
-func (d *BatchDTO) ConvertBatchHeaderEntityToResponse(batch *entity.BatchHeader) *response.BatchResponse {
+func (d *BatchDTO) ConvertBatchHeaderEntityToResponse(ctx context.Context, batch *entity.BatchHeader) *response.BatchResponse {
 	return &response.BatchResponse{
 		ID:             batch.ID,
 		DocumentNumber: batch.DocumentNumber,
 		DocumentDate:   batch.DocumentDate,
 		Status:         string(batch.Status),
+		Version:        batch.Version,
 		CreatedAt:      batch.CreatedAt,
 		UpdatedAt:      batch.UpdatedAt,
-		BatchLines:     d.BatchLineDTO.ConvertBatchLineEntitiesToResponse(&batch.BatchLines),
+		BatchLines:     d.BatchLineDTO.ConvertBatchLineEntitiesToResponse(ctx, &batch.BatchLines),
 	}
 }
 
-func (d *BatchDTO) ConvertToDocumentBatchResponse(batch *entity.BatchHeader, operatingUnit string) *response.DocumentBatchResponse {
+func (d *BatchDTO) ConvertToDocumentBatchResponse(ctx context.Context, batch *entity.BatchHeader, operatingUnit string) *response.DocumentBatchResponse {
 	currentMppPeriod, err := d.mppPeriodRepo.FindByStatus(entity.MPPeriodStatusOpen)
 	if err != nil {
 		d.Log.Errorf("[BatchDTO.ConvertToDocumentBatchResponse] " + err.Error())
@@ -137,7 +139,7 @@ func (d *BatchDTO) ConvertToDocumentBatchResponse(batch *entity.BatchHeader, ope
 					for _, bl := range batch.BatchLines {
 						for _, mpl := range bl.MPPlanningHeader.MPPlanningLines {
 							// Check job level name
-							message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+							message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
 								ID: mpl.JobLevelID.String(),
 							})
 							if err != nil {
@@ -212,7 +214,7 @@ func (d *BatchDTO) ConvertToDocumentBatchResponse(batch *entity.BatchHeader, ope
 					// First pass: Group data by job level and calculate existing, promote, and recruit
 					for _, bl := range batch.BatchLines {
 						for _, mpl := range bl.MPPlanningHeader.MPPlanningLines {
-							message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+							message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
 								ID: mpl.JobLevelID.String(),
 							})
 							if err != nil {
@@ -299,7 +301,7 @@ func (d *BatchDTO) ConvertToDocumentBatchResponse(batch *entity.BatchHeader, ope
 							totalPromote += mpl.Promotion
 							totalRecruit += mpl.RecruitPH + mpl.RecruitMT
 
-							message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+							message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
 								ID: mpl.JobLevelID.String(),
 							})
 							if err != nil {
@@ -326,9 +328,9 @@ func (d *BatchDTO) ConvertToDocumentBatchResponse(batch *entity.BatchHeader, ope
 	}
 }
 
-func (d *BatchDTO) findPreviousMPPlanningLineByJobLevel(jobLevel int, mpPlanningLines []entity.MPPlanningLine) (*entity.MPPlanningLine, error) {
+func (d *BatchDTO) findPreviousMPPlanningLineByJobLevel(ctx context.Context, jobLevel int, mpPlanningLines []entity.MPPlanningLine) (*entity.MPPlanningLine, error) {
 	for _, mpl := range mpPlanningLines {
-		message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+		message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
 			ID: mpl.JobLevelID.String(),
 		})
 		if err != nil {
@@ -344,8 +346,8 @@ func (d *BatchDTO) findPreviousMPPlanningLineByJobLevel(jobLevel int, mpPlanning
 	return nil, nil
 }
 
-func (d *BatchDTO) ConvertToDocumentCalculationBatchResponse(mpPlanningLine entity.MPPlanningLine, isTotal bool, planningLines *[]entity.MPPlanningLine) *response.DocumentCalculationBatchResponse {
-	message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+func (d *BatchDTO) ConvertToDocumentCalculationBatchResponse(ctx context.Context, mpPlanningLine entity.MPPlanningLine, isTotal bool, planningLines *[]entity.MPPlanningLine) *response.DocumentCalculationBatchResponse {
+	message2Response, err := d.JobPlafonMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
 		ID: mpPlanningLine.JobLevelID.String(),
 	})
 	if err != nil {
@@ -356,7 +358,7 @@ func (d *BatchDTO) ConvertToDocumentCalculationBatchResponse(mpPlanningLine enti
 
 	var totalOverall int = 0
 	if planningLines != nil {
-		previousMpPlanningLine, err := d.findPreviousMPPlanningLineByJobLevel(mpPlanningLine.JobLevel+1, *planningLines)
+		previousMpPlanningLine, err := d.findPreviousMPPlanningLineByJobLevel(ctx, mpPlanningLine.JobLevel+1, *planningLines)
 		if err != nil {
 			d.Log.Errorf("[BatchDTO.ConvertToDocumentBatchResponse] " + err.Error())
 		}
@@ -378,10 +380,10 @@ func (d *BatchDTO) ConvertToDocumentCalculationBatchResponse(mpPlanningLine enti
 	}
 }
 
-func (d *BatchDTO) ConvertDocumentCalculationBatchResponses(mpPlanningLines []entity.MPPlanningLine) []response.DocumentCalculationBatchResponse {
+func (d *BatchDTO) ConvertDocumentCalculationBatchResponses(ctx context.Context, mpPlanningLines []entity.MPPlanningLine) []response.DocumentCalculationBatchResponse {
 	var responses []response.DocumentCalculationBatchResponse
 	for _, mpPlanningLine := range mpPlanningLines {
-		responses = append(responses, *d.ConvertToDocumentCalculationBatchResponse(mpPlanningLine, false, &mpPlanningLines))
+		responses = append(responses, *d.ConvertToDocumentCalculationBatchResponse(ctx, mpPlanningLine, false, &mpPlanningLines))
 	}
 	return responses
 }
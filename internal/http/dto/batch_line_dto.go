@@ -1,14 +1,16 @@
 package dto
 
 import (
+	"context"
+
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
 	"github.com/sirupsen/logrus"
 )
 
 type IBatchLineDTO interface {
-	ConvertBatchLineEntityToResponse(batch *entity.BatchLine) *response.BatchLineResponse
-	ConvertBatchLineEntitiesToResponse(batches *[]entity.BatchLine) []*response.BatchLineResponse
+	ConvertBatchLineEntityToResponse(ctx context.Context, batch *entity.BatchLine) *response.BatchLineResponse
+	ConvertBatchLineEntitiesToResponse(ctx context.Context, batches *[]entity.BatchLine) []*response.BatchLineResponse
 }
 
 type BatchLineDTO struct {
@@ -23,7 +25,7 @@ func NewBatchLineDTO(log *logrus.Logger, mpHeaderDTO IMPPlanningDTO) IBatchLineD
 	}
 }
 
-func (d *BatchLineDTO) ConvertBatchLineEntityToResponse(batch *entity.BatchLine) *response.BatchLineResponse {
+func (d *BatchLineDTO) ConvertBatchLineEntityToResponse(ctx context.Context, batch *entity.BatchLine) *response.BatchLineResponse {
 	return &response.BatchLineResponse{
 		ID:                       batch.ID,
 		BatchHeaderID:            batch.BatchHeaderID,
@@ -34,14 +36,14 @@ func (d *BatchLineDTO) ConvertBatchLineEntityToResponse(batch *entity.BatchLine)
 		OrganizationLocationName: batch.OrganizationLocationName,
 		CreatedAt:                batch.CreatedAt,
 		UpdatedAt:                batch.UpdatedAt,
-		MPPlanningHeader:         d.mpHeaderDTO.ConvertMPPlanningHeaderEntityToResponse(&batch.MPPlanningHeader),
+		MPPlanningHeader:         d.mpHeaderDTO.ConvertMPPlanningHeaderEntityToResponse(ctx, &batch.MPPlanningHeader),
 	}
 }
 
-func (d *BatchLineDTO) ConvertBatchLineEntitiesToResponse(batches *[]entity.BatchLine) []*response.BatchLineResponse {
+func (d *BatchLineDTO) ConvertBatchLineEntitiesToResponse(ctx context.Context, batches *[]entity.BatchLine) []*response.BatchLineResponse {
 	var response []*response.BatchLineResponse
 	for _, batch := range *batches {
-		response = append(response, d.ConvertBatchLineEntityToResponse(&batch))
+		response = append(response, d.ConvertBatchLineEntityToResponse(ctx, &batch))
 	}
 	return response
 }
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+)
+
+type IBudgetLedgerDTO interface {
+	ConvertBudgetLedgerEntryEntityToResponse(entry *entity.BudgetLedgerEntry) *response.BudgetLedgerEntryResponse
+}
+
+type BudgetLedgerDTO struct {
+	log *logrus.Logger
+}
+
+func NewBudgetLedgerDTO(log *logrus.Logger) IBudgetLedgerDTO {
+	return &BudgetLedgerDTO{
+		log: log,
+	}
+}
+
+func (d *BudgetLedgerDTO) ConvertBudgetLedgerEntryEntityToResponse(entry *entity.BudgetLedgerEntry) *response.BudgetLedgerEntryResponse {
+	return &response.BudgetLedgerEntryResponse{
+		ID:                entry.ID,
+		MPRequestHeaderID: entry.MPRequestHeaderID,
+		DocumentNumber:    entry.MPRequestHeader.DocumentNumber,
+		RecruitmentType:   entry.MPRequestHeader.RecruitmentType,
+		MPPlanningLineID:  entry.MPPlanningLineID,
+		EntryType:         entry.EntryType,
+		Balance:           entry.Balance,
+		Quantity:          entry.Quantity,
+		Reserved:          entry.Reserved,
+		Consumed:          entry.Consumed,
+		MPRequestStatus:   entry.MPRequestStatus,
+		CreatedAt:         entry.CreatedAt,
+	}
+}
+
+func BudgetLedgerDTOFactory(log *logrus.Logger) IBudgetLedgerDTO {
+	return NewBudgetLedgerDTO(log)
+}
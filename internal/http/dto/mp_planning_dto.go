@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"context"
+
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
@@ -12,8 +14,8 @@ import (
 type IMPPlanningDTO interface {
 	ConvertMPPlanningApprovalHistoryToResponse(approvalHistories *entity.MPPlanningApprovalHistory, viper *viper.Viper) *response.MPPlanningApprovalHistoryResponse
 	ConvertMPPlanningApprovalHistoriesToResponse(approvalHistories *[]entity.MPPlanningApprovalHistory, viper *viper.Viper) []*response.MPPlanningApprovalHistoryResponse
-	ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader *entity.MPPlanningHeader) *response.MPPlanningHeaderResponse
-	ConvertMPPlanningHeaderEntititesToResponse(mpPlanningHeaders *[]entity.MPPlanningHeader) []*response.MPPlanningHeaderResponse
+	ConvertMPPlanningHeaderEntityToResponse(ctx context.Context, mpPlanningHeader *entity.MPPlanningHeader) *response.MPPlanningHeaderResponse
+	ConvertMPPlanningHeaderEntititesToResponse(ctx context.Context, mpPlanningHeaders *[]entity.MPPlanningHeader) []*response.MPPlanningHeaderResponse
 }
 
 type MPPlanningDTO struct {
@@ -40,6 +42,8 @@ func (d *MPPlanningDTO) ConvertMPPlanningApprovalHistoryToResponse(approvalHisto
 		MPPlanningHeaderID: approvalHistories.MPPlanningHeaderID,
 		ApproverID:         approvalHistories.ApproverID,
 		ApproverName:       approvalHistories.ApproverName,
+		OnBehalfOfID:       approvalHistories.OnBehalfOfID,
+		OnBehalfOfName:     approvalHistories.OnBehalfOfName,
 		Notes:              approvalHistories.Notes,
 		Level:              approvalHistories.Level,
 		Status:             approvalHistories.Status,
@@ -57,10 +61,10 @@ func (d *MPPlanningDTO) ConvertMPPlanningApprovalHistoriesToResponse(approvalHis
 	return response
 }
 
-func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader *entity.MPPlanningHeader) *response.MPPlanningHeaderResponse {
+func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(ctx context.Context, mpPlanningHeader *entity.MPPlanningHeader) *response.MPPlanningHeaderResponse {
 	if mpPlanningHeader.OrganizationName == "" {
 		d.log.Infof("Organization ID: %s", mpPlanningHeader.OrganizationID)
-		organization, err := d.orgMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+		organization, err := d.orgMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 			ID: mpPlanningHeader.OrganizationID.String(),
 		})
 		if err != nil {
@@ -72,7 +76,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 	}
 
 	if mpPlanningHeader.EmpOrganizationName == "" {
-		organization, err := d.orgMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+		organization, err := d.orgMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 			ID: mpPlanningHeader.EmpOrganizationID.String(),
 		})
 		if err != nil {
@@ -84,7 +88,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 	}
 
 	if mpPlanningHeader.JobName == "" {
-		job, err := d.jpMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+		job, err := d.jpMessage.SendFindJobByIDMessage(ctx, request.SendFindJobByIDMessageRequest{
 			ID: mpPlanningHeader.JobID.String(),
 		})
 		if err != nil {
@@ -96,7 +100,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 	}
 
 	if mpPlanningHeader.RequestorName == "" || mpPlanningHeader.RequestorID == nil {
-		employee, err := d.empMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		employee, err := d.empMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
 			ID: mpPlanningHeader.RequestorID.String(),
 		})
 		if err != nil {
@@ -108,7 +112,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 	}
 
 	if mpPlanningHeader.OrganizationLocationName == "" {
-		organizationLocation, err := d.orgMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+		organizationLocation, err := d.orgMessage.SendFindOrganizationLocationByIDMessage(ctx, request.SendFindOrganizationLocationByIDMessageRequest{
 			ID: mpPlanningHeader.OrganizationLocationID.String(),
 		})
 		if err != nil {
@@ -139,6 +143,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 		ApproverManagerID:        mpPlanningHeader.ApproverManagerID,
 		ApproverRecruitmentID:    mpPlanningHeader.ApproverRecruitmentID,
 		RequestorID:              mpPlanningHeader.RequestorID,
+		Version:                  mpPlanningHeader.Version,
 		NotesAttach:              mpPlanningHeader.NotesAttach,
 		OrganizationName:         mpPlanningHeader.OrganizationName,
 		EmpOrganizationName:      mpPlanningHeader.EmpOrganizationName,
@@ -163,7 +168,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 			var lines []*response.MPPlanningLineResponse
 			for _, line := range mpPlanningHeader.MPPlanningLines {
 				if line.JobName == "" {
-					job, err := d.jpMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+					job, err := d.jpMessage.SendFindJobByIDMessage(ctx, request.SendFindJobByIDMessageRequest{
 						ID: line.JobID.String(),
 					})
 					if err != nil {
@@ -173,7 +178,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 				}
 
 				if line.JobLevelName == "" {
-					jobLevel, err := d.jpMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+					jobLevel, err := d.jpMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
 						ID: line.JobLevelID.String(),
 					})
 					if err != nil {
@@ -184,7 +189,7 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 				}
 
 				if line.OrganizationLocationName == "" {
-					organizationLocation, err := d.orgMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+					organizationLocation, err := d.orgMessage.SendFindOrganizationLocationByIDMessage(ctx, request.SendFindOrganizationLocationByIDMessageRequest{
 						ID: line.OrganizationLocationID.String(),
 					})
 					if err != nil {
@@ -211,6 +216,9 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 					RemainingBalanceMT:       line.RemainingBalanceMT,
 					RecruitPH:                line.RecruitPH,
 					RecruitMT:                line.RecruitMT,
+					IsOverPlafon:             line.IsOverPlafon,
+					PlafonOverrideStatus:     line.PlafonOverrideStatus,
+					Version:                  line.Version,
 					OrganizationLocationName: line.OrganizationLocationName,
 					JobLevelName:             line.JobLevelName,
 					JobName:                  line.JobName,
@@ -224,10 +232,10 @@ func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader
 	}
 }
 
-func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntititesToResponse(mpPlanningHeaders *[]entity.MPPlanningHeader) []*response.MPPlanningHeaderResponse {
+func (d *MPPlanningDTO) ConvertMPPlanningHeaderEntititesToResponse(ctx context.Context, mpPlanningHeaders *[]entity.MPPlanningHeader) []*response.MPPlanningHeaderResponse {
 	var response []*response.MPPlanningHeaderResponse
 	for _, mpPlanningHeader := range *mpPlanningHeaders {
-		response = append(response, d.ConvertMPPlanningHeaderEntityToResponse(&mpPlanningHeader))
+		response = append(response, d.ConvertMPPlanningHeaderEntityToResponse(ctx, &mpPlanningHeader))
 	}
 	return response
 }
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"context"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
@@ -13,7 +14,7 @@ import (
 
 type IMPRequestDTO interface {
 	ConvertToEntity(req *request.CreateMPRequestHeaderRequest) *entity.MPRequestHeader
-	ConvertToResponse(ent *entity.MPRequestHeader) *response.MPRequestHeaderResponse
+	ConvertToResponse(ctx context.Context, ent *entity.MPRequestHeader) *response.MPRequestHeaderResponse
 	ConvertToResponseMinimal(ent *entity.MPRequestHeader) *response.MPRequestHeaderResponse
 	ConvertEntityToRequest(ent *entity.MPRequestHeader) *request.CreateMPRequestHeaderRequest
 	ConvertMPRequestApprovalHistoryToResponse(approvalHistories *entity.MPRequestApprovalHistory) *response.MPRequestApprovalHistoryResponse
@@ -98,6 +99,8 @@ func (d *MPRequestDTO) ConvertMPRequestApprovalHistoryToResponse(approvalHistori
 		MPRequestHeaderID: approvalHistories.MPRequestHeaderID,
 		ApproverID:        approvalHistories.ApproverID,
 		ApproverName:      approvalHistories.ApproverName,
+		OnBehalfOfID:      approvalHistories.OnBehalfOfID,
+		OnBehalfOfName:    approvalHistories.OnBehalfOfName,
 		Notes:             approvalHistories.Notes,
 		Level:             approvalHistories.Level,
 		Status:            approvalHistories.Status,
@@ -124,10 +127,10 @@ func parseDate(dateStr string) time.Time {
 	return date
 }
 
-func (d *MPRequestDTO) ConvertToResponse(ent *entity.MPRequestHeader) *response.MPRequestHeaderResponse {
+func (d *MPRequestDTO) ConvertToResponse(ctx context.Context, ent *entity.MPRequestHeader) *response.MPRequestHeaderResponse {
 	var mpPlanningHeader response.MPPlanningHeaderResponse
 	if ent.MPPlanningHeaderID != nil && ent.MPPlanningHeader.ID != uuid.Nil {
-		mpPlanningHeader = *d.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(&ent.MPPlanningHeader)
+		mpPlanningHeader = *d.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(ctx, &ent.MPPlanningHeader)
 	} else {
 		mpPlanningHeader = response.MPPlanningHeaderResponse{}
 	}
@@ -165,6 +168,8 @@ func (d *MPRequestDTO) ConvertToResponse(ent *entity.MPRequestHeader) *response.
 		ForOrganizationStructureID: *ent.ForOrganizationStructureID,
 		JobID:                      *ent.JobID,
 		GradeID:                    ent.GradeID,
+		NextApproverID:             ent.NextApproverID,
+		NextApproverLevel:          ent.NextApproverLevel,
 		RequestCategoryID:          ent.RequestCategoryID,
 		ExpectedDate:               *ent.ExpectedDate,
 		Experiences:                ent.Experiences,
@@ -174,6 +179,8 @@ func (d *MPRequestDTO) ConvertToResponse(ent *entity.MPRequestHeader) *response.
 		FemaleNeeds:                ent.FemaleNeeds,
 		AnyGender:                  ent.AnyGender,
 		TotalNeeds:                 ent.TotalNeeds,
+		IsOverPlafon:               ent.IsOverPlafon,
+		PlafonOverrideStatus:       ent.PlafonOverrideStatus,
 		MinimumAge:                 ent.MinimumAge,
 		MaximumAge:                 ent.MaximumAge,
 		MinimumExperience:          ent.MinimumExperience,
@@ -201,6 +208,7 @@ func (d *MPRequestDTO) ConvertToResponse(ent *entity.MPRequestHeader) *response.
 		EmpOrganizationID:          ent.EmpOrganizationID,
 		JobLevelID:                 ent.JobLevelID,
 		Revised:                    revised,
+		Version:                    ent.Version,
 		CreatedAt:                  ent.CreatedAt,
 		UpdatedAt:                  ent.UpdatedAt,
 
@@ -291,6 +299,8 @@ func (d *MPRequestDTO) ConvertToResponseMinimal(ent *entity.MPRequestHeader) *re
 		FemaleNeeds:           ent.FemaleNeeds,
 		AnyGender:             ent.AnyGender,
 		TotalNeeds:            ent.TotalNeeds,
+		IsOverPlafon:          ent.IsOverPlafon,
+		PlafonOverrideStatus:  ent.PlafonOverrideStatus,
 		MinimumAge:            ent.MinimumAge,
 		MaximumAge:            ent.MaximumAge,
 		MinimumExperience:     ent.MinimumExperience,
@@ -317,6 +327,7 @@ func (d *MPRequestDTO) ConvertToResponseMinimal(ent *entity.MPRequestHeader) *re
 		IsReplacement:         ent.IsReplacement,
 		EmpOrganizationID:     ent.EmpOrganizationID,
 		JobLevelID:            ent.JobLevelID,
+		Version:               ent.Version,
 		CreatedAt:             ent.CreatedAt,
 		UpdatedAt:             ent.UpdatedAt,
 	}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+)
+
+type IOutboxDTO interface {
+	ConvertOutboxMessageEntityToResponse(outboxMessage *entity.OutboxMessage) *response.OutboxMessageResponse
+}
+
+type OutboxDTO struct {
+	log *logrus.Logger
+}
+
+func NewOutboxDTO(log *logrus.Logger) IOutboxDTO {
+	return &OutboxDTO{
+		log: log,
+	}
+}
+
+func (d *OutboxDTO) ConvertOutboxMessageEntityToResponse(outboxMessage *entity.OutboxMessage) *response.OutboxMessageResponse {
+	return &response.OutboxMessageResponse{
+		ID:            outboxMessage.ID,
+		MessageType:   outboxMessage.MessageType,
+		Destination:   outboxMessage.Destination,
+		Payload:       outboxMessage.Payload,
+		Status:        outboxMessage.Status,
+		Attempts:      outboxMessage.Attempts,
+		NextAttemptAt: outboxMessage.NextAttemptAt,
+		LastError:     outboxMessage.LastError,
+		SentAt:        outboxMessage.SentAt,
+		CreatedAt:     outboxMessage.CreatedAt,
+		UpdatedAt:     outboxMessage.UpdatedAt,
+	}
+}
+
+func OutboxDTOFactory(log *logrus.Logger) IOutboxDTO {
+	return NewOutboxDTO(log)
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+)
+
+type IPlafonOverrideDTO interface {
+	ConvertPlafonOverrideEntityToResponse(override *entity.PlafonOverride) *response.PlafonOverrideResponse
+}
+
+type PlafonOverrideDTO struct {
+	log *logrus.Logger
+}
+
+func NewPlafonOverrideDTO(log *logrus.Logger) IPlafonOverrideDTO {
+	return &PlafonOverrideDTO{
+		log: log,
+	}
+}
+
+func (d *PlafonOverrideDTO) ConvertPlafonOverrideEntityToResponse(override *entity.PlafonOverride) *response.PlafonOverrideResponse {
+	return &response.PlafonOverrideResponse{
+		ID:           override.ID,
+		DocumentType: override.DocumentType,
+		DocumentID:   override.DocumentID,
+		MPPPeriodID:  override.MPPPeriodID,
+		JobID:        override.JobID,
+		Plafon:       override.Plafon,
+		Headcount:    override.Headcount,
+		Reason:       override.Reason,
+		Status:       override.Status,
+		ApproverID:   override.ApproverID,
+		ApproverName: override.ApproverName,
+		Notes:        override.Notes,
+		DecidedAt:    override.DecidedAt,
+		CreatedAt:    override.CreatedAt,
+		UpdatedAt:    override.UpdatedAt,
+	}
+}
+
+func PlafonOverrideDTOFactory(log *logrus.Logger) IPlafonOverrideDTO {
+	return NewPlafonOverrideDTO(log)
+}
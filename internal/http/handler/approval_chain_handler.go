@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IApprovalChainHandler interface {
+	FindAllPaginated(ctx *gin.Context)
+	FindById(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+}
+
+type ApprovalChainHandler struct {
+	Log      *logrus.Logger
+	Viper    *viper.Viper
+	UseCase  usecase.IApprovalChainUseCase
+	Validate *validator.Validate
+}
+
+func NewApprovalChainHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IApprovalChainUseCase, validate *validator.Validate) IApprovalChainHandler {
+	return &ApprovalChainHandler{
+		Log:      log,
+		Viper:    viper,
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func ApprovalChainHandlerFactory(log *logrus.Logger, viper *viper.Viper) IApprovalChainHandler {
+	useCase := usecase.ApprovalChainUseCaseFactory(log)
+	validate := config.NewValidator(viper)
+	return NewApprovalChainHandler(log, viper, useCase, validate)
+}
+
+func (h *ApprovalChainHandler) FindAllPaginated(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllPaginated(&request.FindAllPaginatedApprovalChainRequest{
+		Page:           page,
+		PageSize:       pageSize,
+		Search:         ctx.Query("search"),
+		OrganizationID: ctx.Query("organization_id"),
+		DocumentType:   ctx.Query("document_type"),
+	})
+	if err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.FindAllPaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find all paginated success", resp)
+}
+
+func (h *ApprovalChainHandler) FindById(ctx *gin.Context) {
+	req := request.FindByIdApprovalChainRequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindById(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find by id success", resp)
+}
+
+func (h *ApprovalChainHandler) Create(ctx *gin.Context) {
+	var req request.CreateApprovalChainRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Create(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "approval chain created successfully", resp)
+}
+
+func (h *ApprovalChainHandler) Update(ctx *gin.Context) {
+	var req request.UpdateApprovalChainRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Update(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "approval chain updated successfully", resp)
+}
+
+func (h *ApprovalChainHandler) Delete(ctx *gin.Context) {
+	req := request.DeleteApprovalChainRequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Delete] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.UseCase.WithContext(ctx.Request.Context()).Delete(&req); err != nil {
+		h.Log.Errorf("[ApprovalChainHandler.Delete] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "approval chain deleted successfully", nil)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/middleware"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IApprovalDelegationHandler interface {
+	FindAllPaginated(ctx *gin.Context)
+	FindById(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+}
+
+type ApprovalDelegationHandler struct {
+	Log        *logrus.Logger
+	Viper      *viper.Viper
+	UseCase    usecase.IApprovalDelegationUseCase
+	Validate   *validator.Validate
+	UserHelper helper.IUserHelper
+}
+
+func NewApprovalDelegationHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IApprovalDelegationUseCase, validate *validator.Validate, userHelper helper.IUserHelper) IApprovalDelegationHandler {
+	return &ApprovalDelegationHandler{
+		Log:        log,
+		Viper:      viper,
+		UseCase:    useCase,
+		Validate:   validate,
+		UserHelper: userHelper,
+	}
+}
+
+func ApprovalDelegationHandlerFactory(log *logrus.Logger, viper *viper.Viper) IApprovalDelegationHandler {
+	useCase := usecase.ApprovalDelegationUseCaseFactory(log)
+	validate := config.NewValidator(viper)
+	userHelper := helper.UserHelperFactory(log)
+	return NewApprovalDelegationHandler(log, viper, useCase, validate, userHelper)
+}
+
+// actor is the employee of the request; ManageAll when the user may manage
+// the delegations of others.
+func (h *ApprovalDelegationHandler) actor(ctx *gin.Context) (request.DelegationActor, error) {
+	user, err := middleware.GetUser(ctx, h.Log)
+	if err != nil {
+		return request.DelegationActor{}, err
+	}
+
+	employeeID, err := h.UserHelper.GetEmployeeId(user)
+	if err != nil {
+		return request.DelegationActor{}, err
+	}
+
+	actor := request.DelegationActor{EmployeeID: employeeID}
+	if grant, ok := middleware.GetGrant(ctx); ok {
+		actor.ManageAll = grant.HasPermission("manage-approval-delegation")
+	}
+	return actor, nil
+}
+
+// errorStatus is the status of a failed create, update or delete.
+func (h *ApprovalDelegationHandler) errorStatus(err error) int {
+	if errors.Is(err, workflow.ErrNotDelegator) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ApprovalDelegationHandler) FindAllPaginated(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllPaginated(&request.FindAllPaginatedApprovalDelegationRequest{
+		Page:        page,
+		PageSize:    pageSize,
+		Search:      ctx.Query("search"),
+		DelegatorID: ctx.Query("delegator_id"),
+		DelegateID:  ctx.Query("delegate_id"),
+		Scope:       ctx.Query("scope"),
+		ActiveOn:    ctx.Query("active_on"),
+	})
+	if err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.FindAllPaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find all paginated success", resp)
+}
+
+func (h *ApprovalDelegationHandler) FindById(ctx *gin.Context) {
+	req := request.FindByIdApprovalDelegationRequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindById(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find by id success", resp)
+}
+
+func (h *ApprovalDelegationHandler) Create(ctx *gin.Context) {
+	var req request.CreateApprovalDelegationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	actor, err := h.actor(ctx)
+	if err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+	req.Actor = actor
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Create(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, h.errorStatus(err), "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "approval delegation created successfully", resp)
+}
+
+func (h *ApprovalDelegationHandler) Update(ctx *gin.Context) {
+	var req request.UpdateApprovalDelegationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	actor, err := h.actor(ctx)
+	if err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+	req.Actor = actor
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Update(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, h.errorStatus(err), "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "approval delegation updated successfully", resp)
+}
+
+func (h *ApprovalDelegationHandler) Delete(ctx *gin.Context) {
+	req := request.DeleteApprovalDelegationRequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Delete] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	actor, err := h.actor(ctx)
+	if err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Delete] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+	req.Actor = actor
+
+	if err := h.UseCase.WithContext(ctx.Request.Context()).Delete(&req); err != nil {
+		h.Log.Errorf("[ApprovalDelegationHandler.Delete] " + err.Error())
+		utils.ErrorResponse(ctx, h.errorStatus(err), "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "approval delegation deleted successfully", nil)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IApprovalSLAHandler interface {
+	FindAllPaginated(ctx *gin.Context)
+	FindById(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+}
+
+type ApprovalSLAHandler struct {
+	Log      *logrus.Logger
+	Viper    *viper.Viper
+	UseCase  usecase.IApprovalSLAUseCase
+	Validate *validator.Validate
+}
+
+func NewApprovalSLAHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IApprovalSLAUseCase, validate *validator.Validate) IApprovalSLAHandler {
+	return &ApprovalSLAHandler{
+		Log:      log,
+		Viper:    viper,
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func ApprovalSLAHandlerFactory(log *logrus.Logger, viper *viper.Viper) IApprovalSLAHandler {
+	useCase := usecase.ApprovalSLAUseCaseFactory(viper, log)
+	validate := config.NewValidator(viper)
+	return NewApprovalSLAHandler(log, viper, useCase, validate)
+}
+
+func (h *ApprovalSLAHandler) FindAllPaginated(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllPaginated(&request.FindAllPaginatedApprovalSLARequest{
+		Page:         page,
+		PageSize:     pageSize,
+		DocumentType: ctx.Query("document_type"),
+		Level:        ctx.Query("level"),
+	})
+	if err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.FindAllPaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find all paginated success", resp)
+}
+
+func (h *ApprovalSLAHandler) FindById(ctx *gin.Context) {
+	req := request.FindByIdApprovalSLARequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindById(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find by id success", resp)
+}
+
+func (h *ApprovalSLAHandler) Create(ctx *gin.Context) {
+	var req request.CreateApprovalSLARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Create(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Create] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "approval SLA created successfully", resp)
+}
+
+func (h *ApprovalSLAHandler) Update(ctx *gin.Context) {
+	var req request.UpdateApprovalSLARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Update(&req)
+	if err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Update] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "approval SLA updated successfully", resp)
+}
+
+func (h *ApprovalSLAHandler) Delete(ctx *gin.Context) {
+	req := request.DeleteApprovalSLARequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Delete] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.UseCase.WithContext(ctx.Request.Context()).Delete(&req); err != nil {
+		h.Log.Errorf("[ApprovalSLAHandler.Delete] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "approval SLA deleted successfully", nil)
+}
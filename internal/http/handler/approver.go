@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/middleware"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// isCallerApprover tells whether approverID sent in the body is the employee
+// of the logged-in user; a decision sent for someone else is answered with 403.
+func isCallerApprover(ctx *gin.Context, log *logrus.Logger, userHelper helper.IUserHelper, approverID string) bool {
+	user, err := middleware.GetUser(ctx, log)
+	if err != nil {
+		log.Errorf("[isCallerApprover] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "error", err.Error())
+		return false
+	}
+
+	employeeID, err := userHelper.GetEmployeeId(user)
+	if err != nil {
+		log.Errorf("[isCallerApprover] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "error", err.Error())
+		return false
+	}
+
+	if parsed, err := uuid.Parse(approverID); err != nil || parsed != employeeID {
+		utils.ErrorResponse(ctx, http.StatusForbidden, "error", "approver_id must be the employee of the logged-in user")
+		return false
+	}
+
+	return true
+}
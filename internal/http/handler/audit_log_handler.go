@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IAuditLogHandler interface {
+	FindAllPaginated(ctx *gin.Context)
+}
+
+type AuditLogHandler struct {
+	Log      *logrus.Logger
+	Viper    *viper.Viper
+	UseCase  usecase.IAuditLogUseCase
+	Validate *validator.Validate
+}
+
+func NewAuditLogHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IAuditLogUseCase, validate *validator.Validate) IAuditLogHandler {
+	return &AuditLogHandler{
+		Log:      log,
+		Viper:    viper,
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func AuditLogHandlerFactory(log *logrus.Logger, viper *viper.Viper) IAuditLogHandler {
+	useCase := usecase.AuditLogUseCaseFactory(viper, log)
+	validate := config.NewValidator(viper)
+	return NewAuditLogHandler(log, viper, useCase, validate)
+}
+
+// FindAllPaginated is the audit log of an entity, named by its table (for
+// example job_plafons), optionally of one record (id) and one field.
+func (h *AuditLogHandler) FindAllPaginated(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	req := request.FindAllPaginatedAuditLogRequest{
+		Page:      page,
+		PageSize:  pageSize,
+		Entity:    ctx.Query("entity"),
+		EntityID:  ctx.Query("id"),
+		Field:     ctx.Query("field"),
+		Action:    ctx.Query("action"),
+		ActorID:   ctx.Query("actor_id"),
+		RequestID: ctx.Query("request_id"),
+	}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[AuditLogHandler.FindAllPaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.FindAllPaginated(&req)
+	if err != nil {
+		h.Log.Errorf("[AuditLogHandler.FindAllPaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find all paginated success", resp)
+}
@@ -1,15 +1,19 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/export"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/middleware"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -26,6 +30,8 @@ type IBatchHandler interface {
 	GetBatchedMPPlanningHeaders(c *gin.Context)
 	FindById(c *gin.Context)
 	FindDocumentByID(c *gin.Context)
+	ExportDocument(c *gin.Context)
+	RenderDocumentPDF(c *gin.Context)
 	FindByNeedApproval(c *gin.Context)
 	FindByCurrentDocumentDateAndStatus(c *gin.Context)
 	UpdateStatusBatchHeader(c *gin.Context)
@@ -107,7 +113,7 @@ func (h *BatchHandler) GetCompletedBatchHeader(c *gin.Context) {
 		utils.ErrorResponse(c, 500, "error", err.Error())
 		return
 	}
-	batch, total, err := h.UseCase.GetCompletedBatchHeader(page, pageSize, search, sort, employeeUUID)
+	batch, total, err := h.UseCase.WithContext(c.Request.Context()).GetCompletedBatchHeader(page, pageSize, search, sort, employeeUUID)
 
 	if err != nil {
 		h.Log.Error(err)
@@ -190,7 +196,7 @@ func (h *BatchHandler) GetBatchHeadersByStatus(c *gin.Context) {
 		return
 	}
 
-	batch, total, err := h.UseCase.GetBatchHeadersByStatusPaginated(entity.BatchHeaderApprovalStatus(status), approverType, orgUUID.String(), page, pageSize, search, sort, employeeUUID)
+	batch, total, err := h.UseCase.WithContext(c.Request.Context()).GetBatchHeadersByStatusPaginated(entity.BatchHeaderApprovalStatus(status), approverType, orgUUID.String(), page, pageSize, search, sort, employeeUUID)
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get batch headers by status", err.Error())
@@ -243,9 +249,19 @@ func (h *BatchHandler) CreateBatchHeaderAndLines(c *gin.Context) {
 
 	req.OrganizationID = orgUUID.String()
 
-	batchHeader, err := h.UseCase.CreateBatchHeaderAndLines(&req)
+	for _, batchLine := range req.BatchLines {
+		if !h.planningInScope(c, batchLine.MPPlanningHeaderID) {
+			return
+		}
+	}
+
+	batchHeader, err := h.UseCase.WithContext(c.Request.Context()).CreateBatchHeaderAndLines(&req)
 	if err != nil {
 		h.Log.Error(err)
+		if errors.Is(err, workflow.ErrInvalidDelegation) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Failed to create batch header and lines", err.Error())
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create batch header and lines", err.Error())
 		return
 	}
@@ -261,7 +277,11 @@ func (h *BatchHandler) GetOrganizationsForBatchApproval(c *gin.Context) {
 		return
 	}
 
-	organizations, err := h.UseCase.GetOrganizationsForBatchApproval(id)
+	if !h.batchInScope(c, id) {
+		return
+	}
+
+	organizations, err := h.UseCase.WithContext(c.Request.Context()).GetOrganizationsForBatchApproval(id)
 
 	if err != nil {
 		h.Log.Error(err)
@@ -298,7 +318,7 @@ func (h *BatchHandler) TriggerCreate(c *gin.Context) {
 		return
 	}
 
-	batch, err := h.UseCase.TriggerCreate(approverType, orgUUID.String())
+	batch, err := h.UseCase.WithContext(c.Request.Context()).TriggerCreate(approverType, orgUUID.String())
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to trigger create batch", err.Error())
@@ -334,7 +354,7 @@ func (h *BatchHandler) GetBatchedMPPlanningHeaders(c *gin.Context) {
 		return
 	}
 
-	mpPlanningHeaders, err := h.UseCase.GetBatchedMPPlanningHeaders(approverType, orgUUID.String())
+	mpPlanningHeaders, err := h.UseCase.WithContext(c.Request.Context()).GetBatchedMPPlanningHeaders(approverType, orgUUID.String())
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get batched MP Planning headers", err.Error())
@@ -371,7 +391,7 @@ func (h *BatchHandler) FindByStatus(c *gin.Context) {
 	}
 
 	approvalStatus := entity.BatchHeaderApprovalStatus(status)
-	batch, err := h.UseCase.FindByStatus(approvalStatus, approverType, orgUUID.String())
+	batch, err := h.UseCase.WithContext(c.Request.Context()).FindByStatus(approvalStatus, approverType, orgUUID.String())
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to find batch by status", err.Error())
@@ -383,19 +403,30 @@ func (h *BatchHandler) FindByStatus(c *gin.Context) {
 
 func (h *BatchHandler) FindById(c *gin.Context) {
 	id := c.Param("id")
-	batch, err := h.UseCase.FindById(id)
+	if !h.batchInScope(c, id) {
+		return
+	}
+
+	batch, err := h.UseCase.WithContext(c.Request.Context()).FindById(id)
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to find batch by id", err.Error())
 		return
 	}
 
+	if batch != nil {
+		utils.SetVersion(c, batch.Version)
+	}
 	utils.SuccessResponse(c, http.StatusOK, "Batch found", batch)
 }
 
 func (h *BatchHandler) FindDocumentByID(c *gin.Context) {
 	id := c.Param("id")
-	batch, err := h.UseCase.FindDocumentByID(id)
+	if !h.batchInScope(c, id) {
+		return
+	}
+
+	batch, err := h.UseCase.WithContext(c.Request.Context()).FindDocumentByID(id)
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to find document by id", err.Error())
@@ -405,6 +436,44 @@ func (h *BatchHandler) FindDocumentByID(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Document found", batch)
 }
 
+// ExportDocument streams the batch document as csv or xlsx.
+func (h *BatchHandler) ExportDocument(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if !h.batchInScope(c, id) {
+		return
+	}
+
+	err = utils.ExportResponse(c, format, "batch-document-"+id, func(w io.Writer) error {
+		return h.UseCase.WithContext(c.Request.Context()).ExportDocument(id, format, w)
+	})
+	if err != nil {
+		h.Log.Error(err)
+	}
+}
+
+// RenderDocumentPDF prints the batch document and sends the PDF.
+func (h *BatchHandler) RenderDocumentPDF(c *gin.Context) {
+	id := c.Param("id")
+	if !h.batchInScope(c, id) {
+		return
+	}
+
+	attachment, err := h.UseCase.WithContext(c.Request.Context()).RenderDocumentPDF(id)
+	if err != nil {
+		h.Log.Error(err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to render document", err.Error())
+		return
+	}
+
+	utils.DocumentResponse(c, attachment.ID, attachment.FileName, attachment.FilePath)
+}
+
 func (h *BatchHandler) FindByNeedApproval(c *gin.Context) {
 	approverType := c.Query("approver_type")
 	if approverType == "" {
@@ -429,7 +498,7 @@ func (h *BatchHandler) FindByNeedApproval(c *gin.Context) {
 		utils.ErrorResponse(c, 500, "error", err.Error())
 		return
 	}
-	batch, err := h.UseCase.FindByNeedApproval(approverType, orgUUID.String())
+	batch, err := h.UseCase.WithContext(c.Request.Context()).FindByNeedApproval(approverType, orgUUID.String())
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to find batch by need approval", err.Error())
@@ -442,13 +511,17 @@ func (h *BatchHandler) FindByNeedApproval(c *gin.Context) {
 func (h *BatchHandler) FindByCurrentDocumentDateAndStatus(c *gin.Context) {
 	status := c.Param("status")
 	approvalStatus := entity.BatchHeaderApprovalStatus(status)
-	batch, err := h.UseCase.FindByCurrentDocumentDateAndStatus(approvalStatus)
+	batch, err := h.UseCase.WithContext(c.Request.Context()).FindByCurrentDocumentDateAndStatus(approvalStatus)
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to find batch by current document date and status", err.Error())
 		return
 	}
 
+	if batch != nil && !h.batchInScope(c, batch.ID.String()) {
+		return
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "Batch found", batch)
 }
 
@@ -466,7 +539,23 @@ func (h *BatchHandler) UpdateStatusBatchHeader(c *gin.Context) {
 		return
 	}
 
-	batchExist, err := h.UseCase.FindById(req.ID)
+	if !h.batchInScope(c, req.ID) {
+		return
+	}
+
+	if !isCallerApprover(c, h.Log, h.UserHelper, req.ApprovedBy) {
+		return
+	}
+
+	version, err := utils.RequestVersion(c, req.Version)
+	if err != nil {
+		h.Log.Error(err)
+		utils.VersionErrorResponse(c, err)
+		return
+	}
+	req.Version = version
+
+	batchExist, err := h.UseCase.WithContext(c.Request.Context()).FindById(req.ID)
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to find batch by id", err.Error())
@@ -483,13 +572,31 @@ func (h *BatchHandler) UpdateStatusBatchHeader(c *gin.Context) {
 		return
 	}
 
-	batchHeader, err := h.UseCase.UpdateStatusBatchHeader(&req)
+	batchHeader, err := h.UseCase.WithContext(c.Request.Context()).UpdateStatusBatchHeader(&req)
 	if err != nil {
 		h.Log.Error(err)
+		if errors.Is(err, workflow.ErrVersionConflict) {
+			if current, findErr := h.UseCase.WithContext(c.Request.Context()).FindById(req.ID); findErr == nil && current != nil {
+				utils.SetVersion(c, current.Version)
+				utils.ConflictResponse(c, err.Error(), current)
+				return
+			}
+			utils.ConflictResponse(c, err.Error(), nil)
+			return
+		}
+		if workflow.IsTransitionError(err) {
+			utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Failed to update batch header status", err.Error())
+			return
+		}
+		if errors.Is(err, workflow.ErrNotNextApprover) || errors.Is(err, workflow.ErrInvalidDelegation) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Failed to update batch header status", err.Error())
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update batch header status", err.Error())
 		return
 	}
 
+	utils.SetVersion(c, batchHeader.Version)
 	utils.SuccessResponse(c, http.StatusOK, "Batch header status updated", batchHeader)
 }
 
@@ -525,7 +632,11 @@ func (h *BatchHandler) MPPlanningDetailByBatchHeaderPaginated(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request", err.Error())
 		return
 	}
-	mpPlanningHeaders, total, err := h.UseCase.MPPlanningDetailsByBatchHeader(parsedId, page, pageSize, search, sort)
+	if !h.batchInScope(c, id) {
+		return
+	}
+
+	mpPlanningHeaders, total, err := h.UseCase.WithContext(c.Request.Context()).MPPlanningDetailsByBatchHeader(parsedId, page, pageSize, search, sort)
 	if err != nil {
 		h.Log.Error(err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get MP Planning detail by batch header", err.Error())
@@ -543,6 +654,22 @@ func (h *BatchHandler) MPPlanningDetailByBatchHeaderPaginated(c *gin.Context) {
 	})
 }
 
+// batchInScope keeps a user limited to an organization to the batches of
+// that organization.
+func (h *BatchHandler) batchInScope(c *gin.Context, batchID string) bool {
+	return inOrganizationScope(c, func() (*uuid.UUID, error) {
+		return h.UseCase.WithContext(c.Request.Context()).FindOrganizationID(batchID)
+	}, "Batch not found")
+}
+
+// planningInScope keeps a user limited to an organization to batching the
+// plannings of that organization.
+func (h *BatchHandler) planningInScope(c *gin.Context, mpPlanningHeaderID string) bool {
+	return inOrganizationScope(c, func() (*uuid.UUID, error) {
+		return h.UseCase.WithContext(c.Request.Context()).FindPlanningOrganizationID(mpPlanningHeaderID)
+	}, "MP Planning Header not found")
+}
+
 func BatchHandlerFactory(log *logrus.Logger, viper *viper.Viper) IBatchHandler {
 	validate := config.NewValidator(viper)
 	userHelper := helper.NewUserHelper(log)
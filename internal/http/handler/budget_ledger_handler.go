@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IBudgetLedgerHandler interface {
+	FindAllByLinePaginated(ctx *gin.Context)
+}
+
+type BudgetLedgerHandler struct {
+	Log      *logrus.Logger
+	Viper    *viper.Viper
+	UseCase  usecase.IBudgetLedgerUseCase
+	Validate *validator.Validate
+}
+
+func NewBudgetLedgerHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IBudgetLedgerUseCase, validate *validator.Validate) IBudgetLedgerHandler {
+	return &BudgetLedgerHandler{
+		Log:      log,
+		Viper:    viper,
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func BudgetLedgerHandlerFactory(log *logrus.Logger, viper *viper.Viper) IBudgetLedgerHandler {
+	useCase := usecase.BudgetLedgerUseCaseFactory(log)
+	validate := config.NewValidator(viper)
+	return NewBudgetLedgerHandler(log, viper, useCase, validate)
+}
+
+func (h *BudgetLedgerHandler) FindAllByLinePaginated(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	req := request.FindAllByLinePaginatedBudgetLedgerRequest{
+		MPPlanningLineID: ctx.Param("id"),
+		Page:             page,
+		PageSize:         pageSize,
+	}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[BudgetLedgerHandler.FindAllByLinePaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	inScope := inOrganizationScope(ctx, func() (*uuid.UUID, error) {
+		return h.UseCase.FindLineOrganizationID(uuid.MustParse(req.MPPlanningLineID))
+	}, "MP Planning Line not found")
+	if !inScope {
+		return
+	}
+
+	resp, err := h.UseCase.FindAllByLinePaginated(&req)
+	if err != nil {
+		h.Log.Errorf("[BudgetLedgerHandler.FindAllByLinePaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find all by line paginated success", resp)
+}
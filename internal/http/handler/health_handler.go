@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+type IHealthHandler interface {
+	Live(ctx *gin.Context)
+	Ready(ctx *gin.Context)
+}
+
+type HealthHandler struct {
+	Log       *logrus.Logger
+	Viper     *viper.Viper
+	DB        *gorm.DB
+	RPCClient *messaging.RPCClient
+}
+
+func NewHealthHandler(log *logrus.Logger, viper *viper.Viper, db *gorm.DB, rpcClient *messaging.RPCClient) IHealthHandler {
+	return &HealthHandler{
+		Log:       log,
+		Viper:     viper,
+		DB:        db,
+		RPCClient: rpcClient,
+	}
+}
+
+func HealthHandlerFactory(log *logrus.Logger, viper *viper.Viper) IHealthHandler {
+	db := config.NewDatabase()
+	rpcClient := messaging.RPCClientFactory(log)
+	return NewHealthHandler(log, viper, db, rpcClient)
+}
+
+func (h *HealthHandler) Live(ctx *gin.Context) {
+	utils.SuccessResponse(ctx, http.StatusOK, "alive", nil)
+}
+
+// Ready reports whether the database and the rabbitmq broker can be reached.
+func (h *HealthHandler) Ready(ctx *gin.Context) {
+	sqlDB, err := h.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx.Request.Context())
+	}
+	if err != nil {
+		h.Log.Errorf("[HealthHandler.Ready] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusServiceUnavailable, "error", "database is unavailable")
+		return
+	}
+
+	if !h.RPCClient.Ready() {
+		utils.ErrorResponse(ctx, http.StatusServiceUnavailable, "error", messaging.ErrBrokerUnavailable.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "ready", nil)
+}
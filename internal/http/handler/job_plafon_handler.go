@@ -52,7 +52,7 @@ func JobPlafonHandlerFactory(log *logrus.Logger, viper *viper.Viper) IJobPlafonH
 }
 
 func (h *JobPlafonHandler) SyncJobPlafon(ctx *gin.Context) {
-	err := h.UseCase.SyncJobPlafon()
+	err := h.UseCase.WithContext(ctx.Request.Context()).SyncJobPlafon()
 	if err != nil {
 		h.Log.Errorf("[JobPlafonHandler.SyncJobPlafon] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -102,7 +102,7 @@ func (h *JobPlafonHandler) FindAllPaginated(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.FindAllPaginated(&request.FindAllPaginatedJobPlafonRequest{
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllPaginated(&request.FindAllPaginatedJobPlafonRequest{
 		Page:           page,
 		PageSize:       pageSize,
 		Search:         search,
@@ -121,7 +121,7 @@ func (h *JobPlafonHandler) FindAllPaginated(ctx *gin.Context) {
 func (h *JobPlafonHandler) FindById(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	resp, err := h.UseCase.FindById(&request.FindByIdJobPlafonRequest{
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindById(&request.FindByIdJobPlafonRequest{
 		ID: id,
 	})
 	if err != nil {
@@ -142,7 +142,7 @@ func (h *JobPlafonHandler) FindByJobId(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.FindByJobId(&request.FindByJobIdJobPlafonRequest{
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindByJobId(&request.FindByJobIdJobPlafonRequest{
 		JobID: jobId,
 	})
 
@@ -169,7 +169,7 @@ func (h *JobPlafonHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.Create(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Create(&req)
 	if err != nil {
 		h.Log.Errorf("[JobPlafonHandler.Create] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -193,7 +193,7 @@ func (h *JobPlafonHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.Update(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Update(&req)
 	if err != nil {
 		h.Log.Errorf("[JobPlafonHandler.Update] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -206,7 +206,7 @@ func (h *JobPlafonHandler) Update(ctx *gin.Context) {
 func (h *JobPlafonHandler) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := h.UseCase.Delete(&request.DeleteJobPlafonRequest{
+	err := h.UseCase.WithContext(ctx.Request.Context()).Delete(&request.DeleteJobPlafonRequest{
 		ID: id,
 	})
 	if err != nil {
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -10,10 +12,14 @@ import (
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/export"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/middleware"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -24,6 +30,7 @@ import (
 
 type IMPPlanningHandler interface {
 	FindAllHeadersPaginated(ctx *gin.Context)
+	ExportHeaders(ctx *gin.Context)
 	FindAllHeadersByRequestorIDPaginated(ctx *gin.Context)
 	CountMPPlanningHeaderByMPPPeriodIDAndApproverType(ctx *gin.Context)
 	FindAllHeadersForBatchPaginated(ctx *gin.Context)
@@ -52,6 +59,10 @@ type IMPPlanningHandler interface {
 	UpdateLine(ctx *gin.Context)
 	DeleteLine(ctx *gin.Context)
 	CreateOrUpdateBatchLineMPPlanningLines(ctx *gin.Context)
+	ImportLines(ctx *gin.Context)
+	FindLineImportById(ctx *gin.Context)
+	ConfirmLineImport(ctx *gin.Context)
+	CopyFromPeriod(ctx *gin.Context)
 }
 
 type MPPlanningHandler struct {
@@ -109,6 +120,13 @@ func (h *MPPlanningHandler) FindAllHeadersPaginated(ctx *gin.Context) {
 	if orgID == "" {
 		orgID = ""
 	}
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		orgID = scopedOrgID
+	}
 
 	status := ctx.Query("status")
 	if status == "" {
@@ -150,7 +168,7 @@ func (h *MPPlanningHandler) FindAllHeadersPaginated(ctx *gin.Context) {
 		RequestorID:   requestorID,
 	}
 
-	resp, err := h.UseCase.FindAllHeadersPaginated(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllHeadersPaginated(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindAllHeadersPaginated] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -160,6 +178,66 @@ func (h *MPPlanningHandler) FindAllHeadersPaginated(ctx *gin.Context) {
 	utils.SuccessResponse(ctx, http.StatusOK, "find all headers paginated success", resp)
 }
 
+// ExportHeaders streams the planning headers and lines of the list, with the
+// same filters, as csv or xlsx.
+func (h *MPPlanningHandler) ExportHeaders(ctx *gin.Context) {
+	format, err := export.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	approverType := ctx.Query("approver_type")
+
+	var requestorID string
+	if approverType == "" || approverType == "requestor" {
+		user, err := middleware.GetUser(ctx, h.Log)
+		if err != nil {
+			h.Log.Errorf("Error when getting user: %v", err)
+			utils.ErrorResponse(ctx, 500, "error", err.Error())
+			return
+		}
+		if user == nil {
+			h.Log.Errorf("User not found")
+			utils.ErrorResponse(ctx, 404, "error", "User not found")
+			return
+		}
+		requestorUUID, err := h.UserHelper.GetEmployeeId(user)
+		if err != nil {
+			h.Log.Errorf("Error when getting employee id: %v", err)
+			utils.ErrorResponse(ctx, 500, "error", err.Error())
+			return
+		}
+		requestorID = requestorUUID.String()
+	}
+
+	orgID := ctx.Query("org_id")
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		orgID = scopedOrgID
+	}
+
+	req := request.ExportMPPlanningHeadersRequest{
+		Search:        ctx.Query("search"),
+		ApproverType:  approverType,
+		OrgLocationID: ctx.Query("org_location_id"),
+		OrgID:         orgID,
+		Status:        ctx.Query("status"),
+		RequestorID:   requestorID,
+		MPPPeriodID:   ctx.Query("mpp_period_id"),
+	}
+
+	err = utils.ExportResponse(ctx, format, "mp-plannings", func(w io.Writer) error {
+		return h.UseCase.WithContext(ctx.Request.Context()).ExportHeaders(&req, format, w)
+	})
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ExportHeaders] " + err.Error())
+	}
+}
+
 func (h *MPPlanningHandler) CountMPPlanningHeaderByMPPPeriodIDAndApproverType(ctx *gin.Context) {
 	mppPeriodID := ctx.Query("mpp_period_id")
 	if mppPeriodID == "" {
@@ -173,7 +251,7 @@ func (h *MPPlanningHandler) CountMPPlanningHeaderByMPPPeriodIDAndApproverType(ct
 		return
 	}
 
-	resp, err := h.UseCase.CountMPPlanningHeaderByMPPPeriodIDAndApproverType(uuid.MustParse(mppPeriodID), approverType)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).CountMPPlanningHeaderByMPPPeriodIDAndApproverType(uuid.MustParse(mppPeriodID), approverType)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.CountMPPlanningHeaderByMPPPeriodIDAndApproverType] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -190,7 +268,11 @@ func (h *MPPlanningHandler) FindJobsByHeaderID(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.FindJobsByHeaderID(uuid.MustParse(headerID))
+	if !h.headerInScope(ctx, headerID) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindJobsByHeaderID(uuid.MustParse(headerID))
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindJobsByHeaderID] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -207,7 +289,11 @@ func (h *MPPlanningHandler) FindOrganizationLocationsByHeaderID(ctx *gin.Context
 		return
 	}
 
-	resp, err := h.UseCase.FindOrganizationLocationsByHeaderID(uuid.MustParse(headerID))
+	if !h.headerInScope(ctx, headerID) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindOrganizationLocationsByHeaderID(uuid.MustParse(headerID))
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindOrganizationLocationsByHeaderID] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -253,7 +339,15 @@ func (h *MPPlanningHandler) FindHeaderBySomething(ctx *gin.Context) {
 		status = ""
 	}
 
-	resp, err := h.UseCase.FindHeaderBySomething(&request.MPPlanningHeaderRequest{
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		organizationId = scopedOrgID
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindHeaderBySomething(&request.MPPlanningHeaderRequest{
 		ID:                     id,
 		DocumentNumber:         documentNumber,
 		OrganizationID:         organizationId,
@@ -308,7 +402,15 @@ func (h *MPPlanningHandler) GetHeadersBySomething(ctx *gin.Context) {
 		status = ""
 	}
 
-	resp, err := h.UseCase.GetHeadersBySomething(&request.MPPlanningHeaderRequest{
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		organizationId = scopedOrgID
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).GetHeadersBySomething(&request.MPPlanningHeaderRequest{
 		ID:                     id,
 		DocumentNumber:         documentNumber,
 		OrganizationID:         organizationId,
@@ -357,7 +459,7 @@ func (h *MPPlanningHandler) GetHeadersByMPPeriodCompleted(ctx *gin.Context) {
 		return
 	}
 
-	resp, total, err := h.UseCase.GetHeadersByMPPeriodCompletePaginated(organizationLocationId, page, pageSize)
+	resp, total, err := h.UseCase.WithContext(ctx.Request.Context()).GetHeadersByMPPeriodCompletePaginated(organizationLocationId, page, pageSize)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.GetHeadersByMPPeriodCompleted] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -384,7 +486,25 @@ func (h *MPPlanningHandler) RejectStatusPartialMPPlanningHeaderUsingPT(ctx *gin.
 		return
 	}
 
-	err := h.UseCase.RejectStatusPartialMPPlanningHeaderUsingPT(&req)
+	// the payload is keyed by organization here
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		for _, payload := range req.Payload {
+			if payload.ID != scopedOrgID {
+				utils.ErrorResponse(ctx, http.StatusNotFound, "error", "MP Planning Header not found")
+				return
+			}
+		}
+	}
+
+	if !isCallerApprover(ctx, h.Log, h.UserHelper, req.ApproverID.String()) {
+		return
+	}
+
+	err := h.UseCase.WithContext(ctx.Request.Context()).RejectStatusPartialMPPlanningHeaderUsingPT(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.RejectStatusPartialMPPlanningHeaderUsingPT] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -407,13 +527,28 @@ func (h *MPPlanningHandler) FindAllHeadersByStatusAndMPPeriodID(ctx *gin.Context
 		return
 	}
 
-	resp, err := h.UseCase.FindAllHeadersByStatusAndMPPeriodID(entity.MPPlaningStatus(status), uuid.MustParse(mpPeriodID))
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllHeadersByStatusAndMPPeriodID(entity.MPPlaningStatus(status), uuid.MustParse(mpPeriodID))
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindAllHeadersByStatusAndMPPeriodID] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
 
+	if scopedOrgID != "" {
+		scoped := make([]*response.MPPlanningHeaderResponse, 0, len(resp))
+		for _, header := range resp {
+			if header.OrganizationID != nil && header.OrganizationID.String() == scopedOrgID {
+				scoped = append(scoped, header)
+			}
+		}
+		resp = scoped
+	}
+
 	utils.SuccessResponse(ctx, http.StatusOK, "find all headers by status and mpp period id success", resp)
 }
 
@@ -430,7 +565,11 @@ func (h *MPPlanningHandler) CountTotalApprovalHistoryByStatus(ctx *gin.Context)
 		return
 	}
 
-	resp, err := h.UseCase.CountTotalApprovalHistoryByStatus(uuid.MustParse(mpPlanningHeaderId), entity.MPPlanningApprovalHistoryStatus(status))
+	if !h.headerInScope(ctx, mpPlanningHeaderId) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).CountTotalApprovalHistoryByStatus(uuid.MustParse(mpPlanningHeaderId), entity.MPPlanningApprovalHistoryStatus(status))
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.CountTotalApprovalHistoryByStatus] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -468,10 +607,16 @@ func (h *MPPlanningHandler) FindAllHeadersByRequestorIDPaginated(ctx *gin.Contex
 		search = ""
 	}
 
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
 	req := request.FindAllHeadersPaginatedMPPlanningRequest{
 		Page:     page,
 		PageSize: pageSize,
 		Search:   search,
+		OrgID:    scopedOrgID,
 	}
 
 	userData, ok := user["user"].(map[string]interface{})
@@ -495,7 +640,7 @@ func (h *MPPlanningHandler) FindAllHeadersByRequestorIDPaginated(ctx *gin.Contex
 		return
 	}
 
-	resp, err := h.UseCase.FindAllHeadersByRequestorIDPaginated(requestorID, &req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllHeadersByRequestorIDPaginated(requestorID, &req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindAllHeadersByRequestorIDPaginated] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -537,15 +682,21 @@ func (h *MPPlanningHandler) FindAllHeadersForBatchPaginated(ctx *gin.Context) {
 		}
 	}
 
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+
 	req := request.FindAllHeadersPaginatedMPPlanningRequest{
 		Page:     page,
 		PageSize: pageSize,
 		Search:   search,
 		Status:   status,
 		IsNull:   isNull,
+		OrgID:    scopedOrgID,
 	}
 
-	resp, err := h.UseCase.FindAllHeadersForBatchPaginated(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllHeadersForBatchPaginated(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindAllHeadersForBatchPaginated] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -590,6 +741,13 @@ func (h *MPPlanningHandler) FindAllHeadersGroupedApproverPaginated(ctx *gin.Cont
 	if organizationId == "" {
 		organizationId = ""
 	}
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		organizationId = scopedOrgID
+	}
 
 	approverType := ctx.Query("approver_type")
 	if approverType == "" {
@@ -632,7 +790,7 @@ func (h *MPPlanningHandler) FindAllHeadersGroupedApproverPaginated(ctx *gin.Cont
 		IsNull:        isNull,
 	}
 
-	resp, err := h.UseCase.FindAllHeadersGroupedApproverPaginated(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllHeadersGroupedApproverPaginated(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindAllHeadersGroupedApproverPaginated] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -643,9 +801,9 @@ func (h *MPPlanningHandler) FindAllHeadersGroupedApproverPaginated(ctx *gin.Cont
 }
 
 func (h *MPPlanningHandler) GenerateDocumentNumber(ctx *gin.Context) {
-	dateNow := time.Now().Add(7 * time.Hour)
+	dateNow := timezone.Now()
 
-	resp, err := h.UseCase.GenerateDocumentNumber(dateNow)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).GenerateDocumentNumber(dateNow)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.GenerateDocumentNumber] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -667,13 +825,23 @@ func (h *MPPlanningHandler) FindById(ctx *gin.Context) {
 		ID: id,
 	}
 
-	resp, err := h.UseCase.FindById(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindById(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindById] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
 
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" && (resp.OrganizationID == nil || resp.OrganizationID.String() != scopedOrgID) {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "error", "MP Planning Header not found")
+		return
+	}
+
+	utils.SetVersion(ctx, resp.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "find by id success", resp)
 }
 
@@ -707,6 +875,15 @@ func (h *MPPlanningHandler) Create(ctx *gin.Context) {
 		return
 	}
 
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" && payload.OrganizationID.String() != scopedOrgID {
+		utils.ErrorResponse(ctx, http.StatusForbidden, "error", "You can only plan for your own organization")
+		return
+	}
+
 	// Get user information
 	user, err := middleware.GetUser(ctx, h.Log)
 	if err != nil {
@@ -776,7 +953,7 @@ func (h *MPPlanningHandler) Create(ctx *gin.Context) {
 	payload.Attachments = attachments
 
 	// Call use case to create the record
-	resp, err := h.UseCase.Create(payload)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Create(payload)
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		h.Log.Errorf("[MPPlanningHandler.Create] " + err.Error())
@@ -800,7 +977,17 @@ func (h *MPPlanningHandler) RejectStatusPartialMPPlanningHeader(ctx *gin.Context
 		return
 	}
 
-	err := h.UseCase.RejectStatusPartialMPPlanningHeader(&req)
+	for _, payload := range req.Payload {
+		if !h.headerInScope(ctx, payload.ID) {
+			return
+		}
+	}
+
+	if !isCallerApprover(ctx, h.Log, h.UserHelper, req.ApproverID.String()) {
+		return
+	}
+
+	err := h.UseCase.WithContext(ctx.Request.Context()).RejectStatusPartialMPPlanningHeader(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.RejectStatusPartialMPPlanningHeader] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -841,6 +1028,22 @@ func (h *MPPlanningHandler) UpdateStatusMPPPlanningHeader(ctx *gin.Context) {
 		return
 	}
 
+	if !h.headerInScope(ctx, payload.ID) {
+		return
+	}
+
+	if !isCallerApprover(ctx, h.Log, h.UserHelper, payload.ApproverID.String()) {
+		return
+	}
+
+	version, err := utils.RequestVersion(ctx, payload.Version)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.UpdateStatusMPPPlanningHeader] " + err.Error())
+		utils.VersionErrorResponse(ctx, err)
+		return
+	}
+	payload.Version = version
+
 	// Process uploaded files
 	form, err := ctx.MultipartForm()
 	if err != nil {
@@ -882,9 +1085,20 @@ func (h *MPPlanningHandler) UpdateStatusMPPPlanningHeader(ctx *gin.Context) {
 	// Add attachments to payload
 	payload.Attachments = attachments
 
-	err = h.UseCase.UpdateStatusMPPlanningHeader(payload)
+	err = h.UseCase.WithContext(ctx.Request.Context()).UpdateStatusMPPlanningHeader(payload)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.UpdateStatusMPPPlanningHeader] " + err.Error())
+		if h.concurrencyErrorResponse(ctx, err, payload.ID, "") {
+			return
+		}
+		if workflow.IsTransitionError(err) || errors.Is(err, workflow.ErrPlafonOverrideUnresolved) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "error", err.Error())
+			return
+		}
+		if errors.Is(err, workflow.ErrNotNextApprover) || errors.Is(err, workflow.ErrInvalidDelegation) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, "error", err.Error())
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
@@ -900,7 +1114,11 @@ func (h *MPPlanningHandler) GetPlanningApprovalHistoryByHeaderId(ctx *gin.Contex
 		return
 	}
 
-	resp, err := h.UseCase.GetPlanningApprovalHistoryByHeaderId(uuid.MustParse(headerId))
+	if !h.headerInScope(ctx, headerId) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).GetPlanningApprovalHistoryByHeaderId(uuid.MustParse(headerId))
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.GetPlanningApprovalHistoryByHeaderId] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -918,7 +1136,11 @@ func (h *MPPlanningHandler) GetPlanningApprovalHistoryAttachmentsByApprovalHisto
 		return
 	}
 
-	resp, err := h.UseCase.GetPlanningApprovalHistoryAttachmentsByApprovalHistoryId(uuid.MustParse(approvalHistoryId))
+	if !h.approvalHistoryInScope(ctx, approvalHistoryId) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).GetPlanningApprovalHistoryAttachmentsByApprovalHistoryId(uuid.MustParse(approvalHistoryId))
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.GetPlanningApprovalHistoryAttachmentsByApprovalHistoryId] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -958,6 +1180,26 @@ func (h *MPPlanningHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	if !h.headerInScope(ctx, payload.ID.String()) {
+		return
+	}
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" && payload.OrganizationID.String() != scopedOrgID {
+		utils.ErrorResponse(ctx, http.StatusForbidden, "error", "You can only plan for your own organization")
+		return
+	}
+
+	version, err := utils.RequestVersion(ctx, payload.Version)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.Update] " + err.Error())
+		utils.VersionErrorResponse(ctx, err)
+		return
+	}
+	payload.Version = version
+
 	// Get user information
 	user, err := middleware.GetUser(ctx, h.Log)
 	if err != nil {
@@ -1026,13 +1268,17 @@ func (h *MPPlanningHandler) Update(ctx *gin.Context) {
 	// Add attachments to payload
 	payload.Attachments = attachments
 
-	resp, err := h.UseCase.Update(payload)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Update(payload)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.Update] " + err.Error())
+		if h.concurrencyErrorResponse(ctx, err, payload.ID.String(), "") {
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
 
+	utils.SetVersion(ctx, resp.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "update success", resp)
 }
 
@@ -1044,11 +1290,15 @@ func (h *MPPlanningHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
+	if !h.headerInScope(ctx, id) {
+		return
+	}
+
 	req := request.DeleteHeaderMPPlanningRequest{
 		ID: id,
 	}
 
-	err := h.UseCase.Delete(&req)
+	err := h.UseCase.WithContext(ctx.Request.Context()).Delete(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.Delete] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -1070,13 +1320,17 @@ func (h *MPPlanningHandler) FindHeaderByMPPPeriodId(ctx *gin.Context) {
 		MPPPeriodID: mppPeriodId,
 	}
 
-	resp, err := h.UseCase.FindHeaderByMPPPeriodId(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindHeaderByMPPPeriodId(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindHeaderByMPPPeriodId] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
 
+	if !inOrganizationScope(ctx, func() (*uuid.UUID, error) { return resp.OrganizationID, nil }, "MP Planning Header not found") {
+		return
+	}
+
 	utils.SuccessResponse(ctx, http.StatusOK, "find header by mpp period id success", resp)
 }
 
@@ -1088,6 +1342,10 @@ func (h *MPPlanningHandler) FindAllLinesByHeaderIdPaginated(ctx *gin.Context) {
 		return
 	}
 
+	if !h.headerInScope(ctx, headerId) {
+		return
+	}
+
 	page, err := strconv.Atoi(ctx.Query("page"))
 	if err != nil || page < 1 {
 		page = 1
@@ -1104,7 +1362,7 @@ func (h *MPPlanningHandler) FindAllLinesByHeaderIdPaginated(ctx *gin.Context) {
 		PageSize: pageSize,
 	}
 
-	resp, err := h.UseCase.FindAllLinesByHeaderIdPaginated(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllLinesByHeaderIdPaginated(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindAllLinesByHeaderIdPaginated] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -1122,11 +1380,15 @@ func (h *MPPlanningHandler) FindLineById(ctx *gin.Context) {
 		return
 	}
 
+	if !h.lineInScope(ctx, id) {
+		return
+	}
+
 	req := request.FindLineByIdMPPlanningLineRequest{
 		ID: id,
 	}
 
-	resp, err := h.UseCase.FindLineById(&req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindLineById(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.FindLineById] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -1150,9 +1412,20 @@ func (h *MPPlanningHandler) CreateLine(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.CreateLine(&req)
+	if !h.headerInScope(ctx, req.MPPlanningHeaderID.String()) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).CreateLine(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.CreateLine] " + err.Error())
+		if h.concurrencyErrorResponse(ctx, err, req.MPPlanningHeaderID.String(), "") {
+			return
+		}
+		if errors.Is(err, workflow.ErrOverPlafon) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "error", err.Error())
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
@@ -1174,13 +1447,33 @@ func (h *MPPlanningHandler) UpdateLine(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.UpdateLine(&req)
+	version, err := utils.RequestVersion(ctx, req.Version)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.UpdateLine] " + err.Error())
+		utils.VersionErrorResponse(ctx, err)
+		return
+	}
+	req.Version = version
+
+	if !h.lineInScope(ctx, req.ID.String()) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).UpdateLine(&req)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.UpdateLine] " + err.Error())
+		if h.concurrencyErrorResponse(ctx, err, req.MPPlanningHeaderID.String(), req.ID.String()) {
+			return
+		}
+		if errors.Is(err, workflow.ErrOverPlafon) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "error", err.Error())
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
 
+	utils.SetVersion(ctx, resp.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "update line success", resp)
 }
 
@@ -1192,13 +1485,20 @@ func (h *MPPlanningHandler) DeleteLine(ctx *gin.Context) {
 		return
 	}
 
+	if !h.lineInScope(ctx, id) {
+		return
+	}
+
 	req := request.DeleteLineMPPlanningLineRequest{
 		ID: id,
 	}
 
-	err := h.UseCase.DeleteLine(&req)
+	err := h.UseCase.WithContext(ctx.Request.Context()).DeleteLine(&req)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.DeleteLine] " + err.Error())
+		if h.concurrencyErrorResponse(ctx, err, "", "") {
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
@@ -1220,12 +1520,284 @@ func (h *MPPlanningHandler) CreateOrUpdateBatchLineMPPlanningLines(ctx *gin.Cont
 		return
 	}
 
-	err := h.UseCase.CreateOrUpdateBatchLineMPPlanningLines(&req)
+	version, err := utils.RequestVersion(ctx, req.Version)
 	if err != nil {
 		h.Log.Errorf("[MPPlanningHandler.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
+		utils.VersionErrorResponse(ctx, err)
+		return
+	}
+	req.Version = version
+
+	if !h.headerInScope(ctx, req.MPPlanningHeaderID.String()) {
+		return
+	}
+
+	err = h.UseCase.WithContext(ctx.Request.Context()).CreateOrUpdateBatchLineMPPlanningLines(&req)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
+		if h.concurrencyErrorResponse(ctx, err, req.MPPlanningHeaderID.String(), "") {
+			return
+		}
+		if errors.Is(err, workflow.ErrOverPlafon) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "error", err.Error())
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusCreated, "create or update batch line success", nil)
 }
+
+// ImportLines reads an uploaded csv or xlsx of planning lines and returns the
+// dry run. The lines are saved by ConfirmLineImport.
+func (h *MPPlanningHandler) ImportLines(ctx *gin.Context) {
+	var req request.ImportMPPlanningLinesRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ImportLines] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ImportLines] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if !h.headerInScope(ctx, req.MPPlanningHeaderID) {
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ImportLines] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	user, err := middleware.GetUser(ctx, h.Log)
+	if err != nil {
+		h.Log.Errorf("Error when getting user: %v", err)
+		utils.ErrorResponse(ctx, 500, "error", err.Error())
+		return
+	}
+	if user == nil {
+		h.Log.Errorf("User not found")
+		utils.ErrorResponse(ctx, 404, "error", "User not found")
+		return
+	}
+	employeeID, err := h.UserHelper.GetEmployeeId(user)
+	if err != nil {
+		h.Log.Errorf("Error when getting employee id: %v", err)
+		utils.ErrorResponse(ctx, 500, "error", err.Error())
+		return
+	}
+	req.ImportedBy = employeeID.String()
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ImportLines] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+	defer file.Close()
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).ImportLines(&req, fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ImportLines] " + err.Error())
+		if errors.Is(err, workflow.ErrPlanningImportUnreadable) || errors.Is(err, workflow.ErrPlanningImportRequest) {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "import lines success", resp)
+}
+
+func (h *MPPlanningHandler) FindLineImportById(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.FindLineImportById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if !h.lineImportInScope(ctx, id.String()) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindLineImportById(id)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.FindLineImportById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find line import by id success", resp)
+}
+
+func (h *MPPlanningHandler) ConfirmLineImport(ctx *gin.Context) {
+	var req request.ConfirmMPPlanningLineImportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ConfirmLineImport] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ConfirmLineImport] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if !h.lineImportInScope(ctx, req.ID) {
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).ConfirmLineImport(&req)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.ConfirmLineImport] " + err.Error())
+		if h.concurrencyErrorResponse(ctx, err, "", "") {
+			return
+		}
+		if errors.Is(err, workflow.ErrPlanningImportRequest) {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+		if errors.Is(err, workflow.ErrPlanningImportInvalid) || errors.Is(err, workflow.ErrPlanningImportConfirmed) || errors.Is(err, workflow.ErrOverPlafon) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "error", err.Error())
+			return
+		}
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "confirm line import success", resp)
+}
+
+// CopyFromPeriod rolls the approved plannings of a period forward as drafts
+// of another and returns what was copied. A user limited to an organization
+// only copies its plannings.
+func (h *MPPlanningHandler) CopyFromPeriod(ctx *gin.Context) {
+	var req request.CopyMPPlanningFromPeriodRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[MPPlanningHandler.CopyFromPeriod] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[MPPlanningHandler.CopyFromPeriod] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		orgID, err := uuid.Parse(scopedOrgID)
+		if err != nil {
+			h.Log.Errorf("[MPPlanningHandler.CopyFromPeriod] " + err.Error())
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+			return
+		}
+		req.OrganizationID = &orgID
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).CopyFromPeriod(&req)
+	if err != nil {
+		h.Log.Errorf("[MPPlanningHandler.CopyFromPeriod] " + err.Error())
+		if errors.Is(err, workflow.ErrPlanningRolloverInvalid) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "error", err.Error())
+			return
+		}
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	if req.DryRun {
+		utils.SuccessResponse(ctx, http.StatusOK, "copy from period dry run success", resp)
+		return
+	}
+	utils.SuccessResponse(ctx, http.StatusCreated, "copy from period success", resp)
+}
+
+// headerInScope keeps a user limited to an organization to the plannings of
+// that organization.
+func (h *MPPlanningHandler) headerInScope(ctx *gin.Context, headerID string) bool {
+	return inOrganizationScope(ctx, func() (*uuid.UUID, error) {
+		id, err := uuid.Parse(headerID)
+		if err != nil {
+			return nil, nil
+		}
+		return h.UseCase.WithContext(ctx.Request.Context()).FindHeaderOrganizationID(id)
+	}, "MP Planning Header not found")
+}
+
+// lineInScope is headerInScope for the planning of a line.
+func (h *MPPlanningHandler) lineInScope(ctx *gin.Context, lineID string) bool {
+	return inOrganizationScope(ctx, func() (*uuid.UUID, error) {
+		id, err := uuid.Parse(lineID)
+		if err != nil {
+			return nil, nil
+		}
+		return h.UseCase.WithContext(ctx.Request.Context()).FindLineOrganizationID(id)
+	}, "MP Planning Line not found")
+}
+
+// lineImportInScope is headerInScope for the planning of a line import.
+func (h *MPPlanningHandler) lineImportInScope(ctx *gin.Context, importID string) bool {
+	return inOrganizationScope(ctx, func() (*uuid.UUID, error) {
+		id, err := uuid.Parse(importID)
+		if err != nil {
+			return nil, nil
+		}
+		return h.UseCase.WithContext(ctx.Request.Context()).FindLineImportOrganizationID(id)
+	}, "MP Planning Line Import not found")
+}
+
+// approvalHistoryInScope is headerInScope for the planning of an approval history.
+func (h *MPPlanningHandler) approvalHistoryInScope(ctx *gin.Context, approvalHistoryID string) bool {
+	return inOrganizationScope(ctx, func() (*uuid.UUID, error) {
+		id, err := uuid.Parse(approvalHistoryID)
+		if err != nil {
+			return nil, nil
+		}
+		return h.UseCase.WithContext(ctx.Request.Context()).FindApprovalHistoryOrganizationID(id)
+	}, "MP Planning Approval History not found")
+}
+
+// concurrencyErrorResponse answers a stale version or a locked planning with 409, true when it did.
+func (h *MPPlanningHandler) concurrencyErrorResponse(ctx *gin.Context, err error, headerID string, lineID string) bool {
+	if errors.Is(err, workflow.ErrPlanningLinesLocked) {
+		utils.ErrorResponse(ctx, http.StatusConflict, "error", err.Error())
+		return true
+	}
+	if !errors.Is(err, workflow.ErrVersionConflict) {
+		return false
+	}
+
+	var current interface{}
+	if lineID != "" {
+		if line, findErr := h.UseCase.WithContext(ctx.Request.Context()).FindLineById(&request.FindLineByIdMPPlanningLineRequest{ID: lineID}); findErr == nil && line.MPPlanningLine != nil {
+			utils.SetVersion(ctx, line.MPPlanningLine.Version)
+			current = line.MPPlanningLine
+		}
+	} else if headerID != "" {
+		if header, findErr := h.UseCase.WithContext(ctx.Request.Context()).FindById(&request.FindHeaderByIdMPPlanningRequest{ID: headerID}); findErr == nil {
+			utils.SetVersion(ctx, header.Version)
+			current = header
+		}
+		// batches move their plannings on without a version, see repository.AnyVersion
+		utils.ConflictResponse(ctx, err.Error()+"; the planning may have been sent to, approved or rejected in a batch", current)
+		return true
+	}
+
+	utils.ConflictResponse(ctx, err.Error(), current)
+	return true
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -10,10 +12,13 @@ import (
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/export"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/middleware"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -27,6 +32,8 @@ type IMPRequestHandler interface {
 	Update(ctx *gin.Context)
 	Delete(ctx *gin.Context)
 	FindAllPaginated(ctx *gin.Context)
+	Export(ctx *gin.Context)
+	RenderPDF(ctx *gin.Context)
 	FindByID(ctx *gin.Context)
 	FindByIDOnly(ctx *gin.Context)
 	FindByIDForTesting(ctx *gin.Context)
@@ -68,8 +75,8 @@ func MPRequestHandlerFactory(log *logrus.Logger, viper *viper.Viper) IMPRequestH
 }
 
 func (h *MPRequestHandler) GenerateDocumentNumber(ctx *gin.Context) {
-	dateNow := time.Now()
-	res, err := h.UseCase.GenerateDocumentNumber(dateNow)
+	dateNow := timezone.Now()
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).GenerateDocumentNumber(dateNow)
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.GenerateDocumentNumber] error when generate document number: %v", err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate document number", err.Error())
@@ -93,7 +100,11 @@ func (h *MPRequestHandler) GetRequestApprovalHistoryByHeaderId(ctx *gin.Context)
 		status = ""
 	}
 
-	res, err := h.UseCase.GetRequestApprovalHistoryByHeaderId(uuid.MustParse(mpHeaderID), status)
+	if !h.requestInScope(ctx, mpHeaderID) {
+		return
+	}
+
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).GetRequestApprovalHistoryByHeaderId(uuid.MustParse(mpHeaderID), status)
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.GetRequestApprovalHistoryByHeaderId] error when get request approval history by header ID: %v", err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get request approval history", err.Error())
@@ -106,7 +117,11 @@ func (h *MPRequestHandler) GetRequestApprovalHistoryByHeaderId(ctx *gin.Context)
 func (h *MPRequestHandler) FindByIDForTesting(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	res, err := h.UseCase.FindByIDForTesting(uuid.MustParse(id))
+	if !h.requestInScope(ctx, id) {
+		return
+	}
+
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).FindByIDForTesting(uuid.MustParse(id))
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.FindByIDForTesting] error when find by ID for testing: %v", err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to find by ID for testing", err.Error())
@@ -124,7 +139,11 @@ func (h *MPRequestHandler) FindByIDOnly(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.UseCase.FindByIDOnly(uuid.MustParse(id))
+	if !h.requestInScope(ctx, id) {
+		return
+	}
+
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).FindByIDOnly(uuid.MustParse(id))
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.FindByIDOnly] error when find by ID: %v", err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to find by ID", err.Error())
@@ -149,7 +168,11 @@ func (h *MPRequestHandler) CountTotalApprovalHistoryByStatus(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.UseCase.CountTotalApprovalHistoryByStatus(uuid.MustParse(mpHeaderID), entity.MPRequestApprovalHistoryStatus(status))
+	if !h.requestInScope(ctx, mpHeaderID) {
+		return
+	}
+
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).CountTotalApprovalHistoryByStatus(uuid.MustParse(mpHeaderID), entity.MPRequestApprovalHistoryStatus(status))
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.CountTotalApprovalHistoryByStatus] error when count total approval history by status: %v", err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to count total approval history", err.Error())
@@ -167,7 +190,11 @@ func (h *MPRequestHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	err := h.UseCase.Delete(uuid.MustParse(id))
+	if !h.requestInScope(ctx, id) {
+		return
+	}
+
+	err := h.UseCase.WithContext(ctx.Request.Context()).Delete(uuid.MustParse(id))
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.Delete] error when delete: %v", err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete", err.Error())
@@ -193,6 +220,46 @@ func (h *MPRequestHandler) FindAllPaginated(ctx *gin.Context) {
 		search = ""
 	}
 
+	filter, ok := h.findAllFilter(ctx)
+	if !ok {
+		return
+	}
+
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllPaginated(page, pageSize, search, filter)
+	if err != nil {
+		h.Log.Errorf("[MPRequestHandler.FindAllPaginated] error when find all paginated: %v", err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to find all paginated", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "MP Request Headers found", res)
+}
+
+// Export streams the mp requests of the list, with the same search and
+// filters, as csv or xlsx.
+func (h *MPRequestHandler) Export(ctx *gin.Context) {
+	format, err := export.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	filter, ok := h.findAllFilter(ctx)
+	if !ok {
+		return
+	}
+
+	err = utils.ExportResponse(ctx, format, "mp-requests", func(w io.Writer) error {
+		return h.UseCase.WithContext(ctx.Request.Context()).Export(ctx.Query("search"), filter, format, w)
+	})
+	if err != nil {
+		h.Log.Errorf("[MPRequestHandler.Export] error when export: %v", err)
+	}
+}
+
+// findAllFilter reads the filters of the list from the query and the user. It
+// writes the error response itself when the user cannot be resolved.
+func (h *MPRequestHandler) findAllFilter(ctx *gin.Context) (map[string]interface{}, bool) {
 	filter := make(map[string]interface{})
 
 	status := ctx.Query("status")
@@ -229,18 +296,18 @@ func (h *MPRequestHandler) FindAllPaginated(ctx *gin.Context) {
 	if err != nil {
 		h.Log.Errorf("Error when getting user: %v", err)
 		utils.ErrorResponse(ctx, 500, "error", err.Error())
-		return
+		return nil, false
 	}
 	if user == nil {
 		h.Log.Errorf("User not found")
 		utils.ErrorResponse(ctx, 404, "error", "User not found")
-		return
+		return nil, false
 	}
 	requestorUUID, err := h.UserHelper.GetEmployeeId(user)
 	if err != nil {
 		h.Log.Errorf("Error when getting employee id: %v", err)
 		utils.ErrorResponse(ctx, 500, "error", err.Error())
-		return
+		return nil, false
 	}
 	requestorID = requestorUUID.String()
 	filter["requestor_id"] = requestorID
@@ -249,7 +316,7 @@ func (h *MPRequestHandler) FindAllPaginated(ctx *gin.Context) {
 	if err != nil {
 		h.Log.Errorf("Error when getting organization structure id: %v", err)
 		utils.ErrorResponse(ctx, 500, "error", err.Error())
-		return
+		return nil, false
 	}
 	filter["organization_structure_id"] = orgStructureUUID.String()
 
@@ -257,7 +324,7 @@ func (h *MPRequestHandler) FindAllPaginated(ctx *gin.Context) {
 	if err != nil {
 		h.Log.Errorf("Error when getting organization id: %v", err)
 		utils.ErrorResponse(ctx, 500, "error", err.Error())
-		return
+		return nil, false
 	}
 	filter["organization_id"] = orgUUID.String()
 
@@ -268,14 +335,15 @@ func (h *MPRequestHandler) FindAllPaginated(ctx *gin.Context) {
 		filter["is_admin"] = isAdmin
 	}
 
-	res, err := h.UseCase.FindAllPaginated(page, pageSize, search, filter)
-	if err != nil {
-		h.Log.Errorf("[MPRequestHandler.FindAllPaginated] error when find all paginated: %v", err)
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to find all paginated", err.Error())
-		return
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return nil, false
+	}
+	if scopedOrgID != "" {
+		filter["scope_organization_id"] = scopedOrgID
 	}
 
-	utils.SuccessResponse(ctx, http.StatusOK, "MP Request Headers found", res)
+	return filter, true
 }
 
 func (h *MPRequestHandler) FindByID(ctx *gin.Context) {
@@ -286,16 +354,49 @@ func (h *MPRequestHandler) FindByID(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.UseCase.FindByID(uuid.MustParse(id))
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).FindByID(uuid.MustParse(id))
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.FindByID] error when find by ID: %v", err)
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to find by ID", err.Error())
 		return
 	}
 
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" && res.OrganizationID.String() != scopedOrgID {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "MP Request Header not found", "MP Request Header not found")
+		return
+	}
+
+	utils.SetVersion(ctx, res.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "MP Request Header found", res)
 }
 
+// RenderPDF prints the request form and sends the PDF.
+func (h *MPRequestHandler) RenderPDF(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		h.Log.Errorf("[MPRequestHandler.RenderPDF] error when parse id: %v", err)
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	if !h.requestInScope(ctx, id.String()) {
+		return
+	}
+
+	attachment, err := h.UseCase.WithContext(ctx.Request.Context()).RenderPDF(id)
+	if err != nil {
+		h.Log.Errorf("[MPRequestHandler.RenderPDF] error when render pdf: %v", err)
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to render pdf", err.Error())
+		return
+	}
+
+	utils.DocumentResponse(ctx, attachment.ID, attachment.FileName, attachment.FilePath)
+}
+
 func (h *MPRequestHandler) Create(ctx *gin.Context) {
 	var req request.CreateMPRequestHeaderRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -310,9 +411,22 @@ func (h *MPRequestHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.UseCase.Create(&req)
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" && req.OrganizationID.String() != scopedOrgID {
+		utils.ErrorResponse(ctx, http.StatusForbidden, "Failed to create mp request header", "You can only request for your own organization")
+		return
+	}
+
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).Create(&req)
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.Create] error when create mp request header: %v", err)
+		if errors.Is(err, workflow.ErrOverPlafon) || errors.Is(err, workflow.ErrOverConsumption) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "Failed to create mp request header", err.Error())
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create mp request header", err.Error())
 		return
 	}
@@ -340,13 +454,42 @@ func (h *MPRequestHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.UseCase.Update(&req)
+	version, err := utils.RequestVersion(ctx, req.Version)
+	if err != nil {
+		h.Log.Errorf("[MPRequestHandler.Update] error when read version: %v", err)
+		utils.VersionErrorResponse(ctx, err)
+		return
+	}
+	req.Version = version
+
+	if !h.requestInScope(ctx, req.ID) {
+		return
+	}
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" && req.OrganizationID.String() != scopedOrgID {
+		utils.ErrorResponse(ctx, http.StatusForbidden, "Failed to update mp request header", "You can only request for your own organization")
+		return
+	}
+
+	res, err := h.UseCase.WithContext(ctx.Request.Context()).Update(&req)
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.Update] error when update mp request header: %v", err)
+		if errors.Is(err, workflow.ErrVersionConflict) {
+			h.versionConflictResponse(ctx, err, req.ID)
+			return
+		}
+		if errors.Is(err, workflow.ErrOverPlafon) || errors.Is(err, workflow.ErrOverConsumption) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "Failed to update mp request header", err.Error())
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update mp request header", err.Error())
 		return
 	}
 
+	utils.SetVersion(ctx, res.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "MP Request Header updated", res)
 }
 
@@ -380,6 +523,22 @@ func (h *MPRequestHandler) UpdateStatusMPRequestHeader(ctx *gin.Context) {
 		return
 	}
 
+	version, err := utils.RequestVersion(ctx, payload.Version)
+	if err != nil {
+		h.Log.Errorf("[MPRequestHandler.UpdateStatusMPRequestHeader] error when read version: %v", err)
+		utils.VersionErrorResponse(ctx, err)
+		return
+	}
+	payload.Version = version
+
+	if !h.requestInScope(ctx, payload.ID) {
+		return
+	}
+
+	if !isCallerApprover(ctx, h.Log, h.UserHelper, payload.ApproverID.String()) {
+		return
+	}
+
 	// process attachments
 	form, err := ctx.MultipartForm()
 	if err != nil {
@@ -419,12 +578,50 @@ func (h *MPRequestHandler) UpdateStatusMPRequestHeader(ctx *gin.Context) {
 
 	payload.Attachments = attachments
 
-	err = h.UseCase.UpdateStatusHeader(payload)
+	err = h.UseCase.WithContext(ctx.Request.Context()).UpdateStatusHeader(payload)
 	if err != nil {
 		h.Log.Errorf("[MPRequestHandler.UpdateStatusMPRequestHeader] error when update status: %v", err)
+		if errors.Is(err, workflow.ErrVersionConflict) {
+			h.versionConflictResponse(ctx, err, payload.ID)
+			return
+		}
+		if workflow.IsTransitionError(err) || errors.Is(err, workflow.ErrPlafonOverrideUnresolved) || errors.Is(err, workflow.ErrOverConsumption) {
+			utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, "Failed to update status", err.Error())
+			return
+		}
+		if errors.Is(err, workflow.ErrNotNextApprover) || errors.Is(err, workflow.ErrInvalidDelegation) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, "Failed to update status", err.Error())
+			return
+		}
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update status", err.Error())
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "MP Request Header status updated", nil)
 }
+
+// requestInScope keeps a user limited to an organization to the requests of
+// that organization.
+func (h *MPRequestHandler) requestInScope(ctx *gin.Context, requestID string) bool {
+	return inOrganizationScope(ctx, func() (*uuid.UUID, error) {
+		id, err := uuid.Parse(requestID)
+		if err != nil {
+			return nil, nil
+		}
+		return h.UseCase.WithContext(ctx.Request.Context()).FindOrganizationID(id)
+	}, "MP Request Header not found")
+}
+
+// versionConflictResponse answers a change made on an old version of the
+// request with id with 409 and the request as it is now.
+func (h *MPRequestHandler) versionConflictResponse(ctx *gin.Context, err error, id string) {
+	var current interface{}
+	if parsedID, parseErr := uuid.Parse(id); parseErr == nil {
+		if res, findErr := h.UseCase.WithContext(ctx.Request.Context()).FindByID(parsedID); findErr == nil && res != nil {
+			utils.SetVersion(ctx, res.Version)
+			current = res
+		}
+	}
+
+	utils.ConflictResponse(ctx, err.Error(), current)
+}
@@ -129,7 +129,7 @@ func (h *MPPPeriodHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.Create(req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Create(req)
 	if err != nil {
 		h.Log.Errorf("[MPPPeriodHandler.Create] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -154,7 +154,7 @@ func (h *MPPPeriodHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	err = h.NotificationService.CreatePeriodNotification(userUUID.String())
+	err = h.NotificationService.CreatePeriodNotification(ctx.Request.Context(), userUUID.String())
 	if err != nil {
 		h.Log.Errorf("Error when creating notification: %v", err)
 		utils.ErrorResponse(ctx, 500, "error", err.Error())
@@ -178,7 +178,7 @@ func (h *MPPPeriodHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.UseCase.Update(req)
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Update(req)
 	if err != nil {
 		h.Log.Errorf("[MPPPeriodHandler.Update] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -195,7 +195,7 @@ func (h *MPPPeriodHandler) Delete(ctx *gin.Context) {
 		ID: uuid.MustParse(id),
 	}
 
-	err := h.UseCase.Delete(req)
+	err := h.UseCase.WithContext(ctx.Request.Context()).Delete(req)
 	if err != nil {
 		h.Log.Errorf("[MPPPeriodHandler.Delete] " + err.Error())
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -267,14 +267,14 @@ func (h *MPPPeriodHandler) UpdateStatusByDate(ctx *gin.Context) {
 		return
 	}
 	if status == "open" {
-		err = h.UseCase.UpdateStatusToOpenByDate(parsedDate)
+		err = h.UseCase.WithContext(ctx.Request.Context()).UpdateStatusToOpenByDate(parsedDate)
 		if err != nil {
 			h.Log.Errorf("[MPPPeriodHandler.UpdateStatusByDate] " + err.Error())
 			utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
 			return
 		}
 	} else if status == "close" {
-		err := h.UseCase.UpdateStatusToCloseByDate(parsedDate)
+		err := h.UseCase.WithContext(ctx.Request.Context()).UpdateStatusToCloseByDate(parsedDate)
 		if err != nil {
 			h.Log.Errorf("[MPPPeriodHandler.UpdateStatusByDate] " + err.Error())
 			utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/middleware"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// scopedOrganizationID is the organization the request is limited to, empty
+// when the user may see every organization. A route without the scope
+// middleware is refused, so a forgotten scope never lets everything through.
+func scopedOrganizationID(ctx *gin.Context) (string, bool) {
+	scopedOrgID, ok := middleware.ScopedOrganizationID(ctx)
+	if !ok {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", "the organization scope is not applied to this route")
+		return "", false
+	}
+	return scopedOrgID, true
+}
+
+// inOrganizationScope tells whether the record a scoped route is keyed by
+// belongs to the organization the user is limited to. A record of another
+// organization is answered with 404, as if it did not exist.
+func inOrganizationScope(ctx *gin.Context, findOrganizationID func() (*uuid.UUID, error), notFound string) bool {
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return false
+	}
+	if scopedOrgID == "" {
+		return true
+	}
+
+	organizationID, err := findOrganizationID()
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return false
+	}
+
+	if organizationID == nil || organizationID.String() != scopedOrgID {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "error", notFound)
+		return false
+	}
+
+	return true
+}
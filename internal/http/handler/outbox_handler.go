@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IOutboxHandler interface {
+	FindAllPaginated(ctx *gin.Context)
+	Replay(ctx *gin.Context)
+}
+
+type OutboxHandler struct {
+	Log      *logrus.Logger
+	Viper    *viper.Viper
+	UseCase  usecase.IOutboxUseCase
+	Validate *validator.Validate
+}
+
+func NewOutboxHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IOutboxUseCase, validate *validator.Validate) IOutboxHandler {
+	return &OutboxHandler{
+		Log:      log,
+		Viper:    viper,
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func OutboxHandlerFactory(log *logrus.Logger, viper *viper.Viper) IOutboxHandler {
+	useCase := usecase.OutboxUseCaseFactory(viper, log)
+	validate := config.NewValidator(viper)
+	return NewOutboxHandler(log, viper, useCase, validate)
+}
+
+func (h *OutboxHandler) FindAllPaginated(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllPaginated(&request.FindAllPaginatedOutboxMessageRequest{
+		Page:        page,
+		PageSize:    pageSize,
+		Status:      ctx.Query("status"),
+		MessageType: ctx.Query("message_type"),
+	})
+	if err != nil {
+		h.Log.Errorf("[OutboxHandler.FindAllPaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find all paginated success", resp)
+}
+
+func (h *OutboxHandler) Replay(ctx *gin.Context) {
+	req := request.ReplayOutboxMessageRequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[OutboxHandler.Replay] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).Replay(&req)
+	if err != nil {
+		h.Log.Errorf("[OutboxHandler.Replay] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "outbox message queued for replay", resp)
+}
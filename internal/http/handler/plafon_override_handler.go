@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IPlafonOverrideHandler interface {
+	FindAllPaginated(ctx *gin.Context)
+	FindById(ctx *gin.Context)
+	UpdateStatus(ctx *gin.Context)
+}
+
+type PlafonOverrideHandler struct {
+	Log      *logrus.Logger
+	Viper    *viper.Viper
+	UseCase  usecase.IPlafonOverrideUseCase
+	Validate *validator.Validate
+}
+
+func NewPlafonOverrideHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IPlafonOverrideUseCase, validate *validator.Validate) IPlafonOverrideHandler {
+	return &PlafonOverrideHandler{
+		Log:      log,
+		Viper:    viper,
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func PlafonOverrideHandlerFactory(log *logrus.Logger, viper *viper.Viper) IPlafonOverrideHandler {
+	useCase := usecase.PlafonOverrideUseCaseFactory(log)
+	validate := config.NewValidator(viper)
+	return NewPlafonOverrideHandler(log, viper, useCase, validate)
+}
+
+func (h *PlafonOverrideHandler) FindAllPaginated(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindAllPaginated(&request.FindAllPaginatedPlafonOverrideRequest{
+		Page:         page,
+		PageSize:     pageSize,
+		Search:       ctx.Query("search"),
+		Status:       ctx.Query("status"),
+		DocumentType: ctx.Query("document_type"),
+		JobID:        ctx.Query("job_id"),
+		MPPPeriodID:  ctx.Query("mpp_period_id"),
+	})
+	if err != nil {
+		h.Log.Errorf("[PlafonOverrideHandler.FindAllPaginated] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find all paginated success", resp)
+}
+
+func (h *PlafonOverrideHandler) FindById(ctx *gin.Context) {
+	req := request.FindByIdPlafonOverrideRequest{ID: ctx.Param("id")}
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[PlafonOverrideHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).FindById(&req)
+	if err != nil {
+		h.Log.Errorf("[PlafonOverrideHandler.FindById] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "find by id success", resp)
+}
+
+func (h *PlafonOverrideHandler) UpdateStatus(ctx *gin.Context) {
+	var req request.UpdateStatusPlafonOverrideRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.Log.Errorf("[PlafonOverrideHandler.UpdateStatus] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[PlafonOverrideHandler.UpdateStatus] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).UpdateStatus(&req)
+	if err != nil {
+		h.Log.Errorf("[PlafonOverrideHandler.UpdateStatus] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "plafon override status updated successfully", resp)
+}
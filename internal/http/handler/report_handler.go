@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IReportHandler interface {
+	PlanVsActual(ctx *gin.Context)
+}
+
+type ReportHandler struct {
+	Log      *logrus.Logger
+	Viper    *viper.Viper
+	UseCase  usecase.IReportUseCase
+	Validate *validator.Validate
+}
+
+func NewReportHandler(log *logrus.Logger, viper *viper.Viper, useCase usecase.IReportUseCase, validate *validator.Validate) IReportHandler {
+	return &ReportHandler{
+		Log:      log,
+		Viper:    viper,
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func ReportHandlerFactory(log *logrus.Logger, viper *viper.Viper) IReportHandler {
+	useCase := usecase.ReportUseCaseFactory(log)
+	validate := config.NewValidator(viper)
+	return NewReportHandler(log, viper, useCase, validate)
+}
+
+// PlanVsActual takes the filters as query parameters and group_by as a comma
+// separated list of mpp_period, organization, organization_location,
+// job_level and job.
+func (h *ReportHandler) PlanVsActual(ctx *gin.Context) {
+	req := request.PlanVsActualReportRequest{
+		MPPPeriodID:            ctx.Query("mpp_period_id"),
+		OrganizationID:         ctx.Query("organization_id"),
+		OrganizationLocationID: ctx.Query("organization_location_id"),
+		JobLevelID:             ctx.Query("job_level_id"),
+		JobID:                  ctx.Query("job_id"),
+	}
+	scopedOrgID, ok := scopedOrganizationID(ctx)
+	if !ok {
+		return
+	}
+	if scopedOrgID != "" {
+		req.OrganizationID = scopedOrgID
+	}
+	for _, dimension := range strings.Split(ctx.Query("group_by"), ",") {
+		if dimension = strings.TrimSpace(dimension); dimension != "" {
+			req.GroupBy = append(req.GroupBy, dimension)
+		}
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		h.Log.Errorf("[ReportHandler.PlanVsActual] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	resp, err := h.UseCase.WithContext(ctx.Request.Context()).PlanVsActual(&req)
+	if err != nil {
+		h.Log.Errorf("[ReportHandler.PlanVsActual] " + err.Error())
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "plan vs actual report success", resp)
+}
@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -12,8 +13,8 @@ import (
 )
 
 type IMPRequestHelper interface {
-	CheckPortalData(req *request.CreateMPRequestHeaderRequest) (*response.CheckPortalDataMPRequestResponse, error)
-	CheckPortalDataMinimal(req *response.MPRequestHeaderResponse) (*response.MPRequestHeaderResponse, error)
+	CheckPortalData(ctx context.Context, req *request.CreateMPRequestHeaderRequest) (*response.CheckPortalDataMPRequestResponse, error)
+	CheckPortalDataMinimal(ctx context.Context, req *response.MPRequestHeaderResponse) (*response.MPRequestHeaderResponse, error)
 }
 
 type MPRequestHelper struct {
@@ -52,9 +53,9 @@ func MPRequestHelperFactory(log *logrus.Logger) IMPRequestHelper {
 	return NewMPRequestHelper(log, organizationMessage, jobPlafonMessage, userMessage, em, gradeMessage)
 }
 
-func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequest) (*response.CheckPortalDataMPRequestResponse, error) {
+func (h *MPRequestHelper) CheckPortalData(ctx context.Context, req *request.CreateMPRequestHeaderRequest) (*response.CheckPortalDataMPRequestResponse, error) {
 	// check if organization is exist
-	orgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	orgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 		ID: req.OrganizationID.String(),
 	})
 	if err != nil {
@@ -68,7 +69,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if organization location is exist
-	orgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	orgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(ctx, request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: req.OrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -82,7 +83,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if for organization is exist
-	forOrgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	forOrgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 		ID: req.ForOrganizationID.String(),
 	})
 	if err != nil {
@@ -96,7 +97,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if for organization location is exist
-	forOrgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	forOrgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(ctx, request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: req.ForOrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -110,7 +111,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if for organization structure is exist
-	forOrgStructExist, err := h.OrganizationMessage.SendFindOrganizationStructureByIDMessage(request.SendFindOrganizationStructureByIDMessageRequest{
+	forOrgStructExist, err := h.OrganizationMessage.SendFindOrganizationStructureByIDMessage(ctx, request.SendFindOrganizationStructureByIDMessageRequest{
 		ID: req.ForOrganizationStructureID.String(),
 	})
 	if err != nil {
@@ -124,7 +125,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if job ID is exist
-	jobExist, err := h.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	jobExist, err := h.JobPlafonMessage.SendFindJobByIDMessage(ctx, request.SendFindJobByIDMessageRequest{
 		ID: req.JobID.String(),
 	})
 	if err != nil {
@@ -138,7 +139,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if requestor ID is exist
-	requestorExist, err := h.EmpMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	requestorExist, err := h.EmpMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
 		ID: req.RequestorID.String(),
 	})
 	if err != nil {
@@ -154,7 +155,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	// check if department head is exist
 	var deptHeadExist *response.EmployeeResponse
 	if req.DepartmentHead != nil {
-		deptHeadExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		deptHeadExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
 			ID: req.DepartmentHead.String(),
 		})
 		if err != nil {
@@ -173,7 +174,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	// check if vp gm director is exist
 	var vpGmDirectorExist *response.EmployeeResponse
 	if req.VpGmDirector != nil {
-		vpGmDirectorExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		vpGmDirectorExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
 			ID: req.VpGmDirector.String(),
 		})
 		if err != nil {
@@ -187,7 +188,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	// check if ceo is exist
 	var ceoExist *response.EmployeeResponse
 	if req.CEO != nil {
-		ceoExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		ceoExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
 			ID: req.CEO.String(),
 		})
 		if err != nil {
@@ -206,7 +207,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	// check if hrd ho unit is exist
 	var hrdHoUnitExist *response.EmployeeResponse
 	if req.HrdHoUnit != nil {
-		hrdHoUnitExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		hrdHoUnitExist, err = h.EmpMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
 			ID: req.HrdHoUnit.String(),
 		})
 		if err != nil {
@@ -218,7 +219,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if emp organization is exist
-	empOrgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	empOrgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 		ID: req.EmpOrganizationID.String(),
 	})
 	if err != nil {
@@ -227,7 +228,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}
 
 	// check if job level is exist
-	jobLevelExist, err := h.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+	jobLevelExist, err := h.JobPlafonMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
 		ID: req.JobLevelID.String(),
 	})
 	if err != nil {
@@ -244,7 +245,7 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 
 	var gradeResponse *response.GradeResponse
 	if req.GradeID != nil {
-		gradeResponse, err = h.GradeMessage.SendFindByIDMessage(req.GradeID.String())
+		gradeResponse, err = h.GradeMessage.SendFindByIDMessage(ctx, req.GradeID.String())
 		if err != nil {
 			h.Log.Errorf("[MPRequestHelper] error when send find grade by id message: %v", err)
 			return nil, err
@@ -280,11 +281,11 @@ func (h *MPRequestHelper) CheckPortalData(req *request.CreateMPRequestHeaderRequ
 	}, nil
 }
 
-func (h *MPRequestHelper) CheckPortalDataMinimal(req *response.MPRequestHeaderResponse) (*response.MPRequestHeaderResponse, error) {
+func (h *MPRequestHelper) CheckPortalDataMinimal(ctx context.Context, req *response.MPRequestHeaderResponse) (*response.MPRequestHeaderResponse, error) {
 	// check if organization is exist
 	var orgExist *response.SendFindOrganizationByIDMessageResponse
 	if req.OrganizationID != uuid.Nil {
-		orgExistData, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+		orgExistData, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 			ID: req.OrganizationID.String(),
 		})
 		if err != nil {
@@ -301,7 +302,7 @@ func (h *MPRequestHelper) CheckPortalDataMinimal(req *response.MPRequestHeaderRe
 	}
 
 	// check if organization location is exist
-	orgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	orgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(ctx, request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: req.OrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -315,7 +316,7 @@ func (h *MPRequestHelper) CheckPortalDataMinimal(req *response.MPRequestHeaderRe
 	}
 
 	// check if for organization is exist
-	forOrgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	forOrgExist, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
 		ID: req.ForOrganizationID.String(),
 	})
 	if err != nil {
@@ -329,7 +330,7 @@ func (h *MPRequestHelper) CheckPortalDataMinimal(req *response.MPRequestHeaderRe
 	}
 
 	// check if for organization location is exist
-	forOrgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	forOrgLocExist, err := h.OrganizationMessage.SendFindOrganizationLocationByIDMessage(ctx, request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: req.ForOrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -343,7 +344,7 @@ func (h *MPRequestHelper) CheckPortalDataMinimal(req *response.MPRequestHeaderRe
 	}
 
 	// check if for organization structure is exist
-	forOrgStructExist, err := h.OrganizationMessage.SendFindOrganizationStructureByIDMessage(request.SendFindOrganizationStructureByIDMessageRequest{
+	forOrgStructExist, err := h.OrganizationMessage.SendFindOrganizationStructureByIDMessage(ctx, request.SendFindOrganizationStructureByIDMessageRequest{
 		ID: req.ForOrganizationStructureID.String(),
 	})
 	if err != nil {
@@ -357,7 +358,7 @@ func (h *MPRequestHelper) CheckPortalDataMinimal(req *response.MPRequestHeaderRe
 	}
 
 	// check if job ID is exist
-	jobExist, err := h.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	jobExist, err := h.JobPlafonMessage.SendFindJobByIDMessage(ctx, request.SendFindJobByIDMessageRequest{
 		ID: req.JobID.String(),
 	})
 	if err != nil {
@@ -372,7 +373,7 @@ func (h *MPRequestHelper) CheckPortalDataMinimal(req *response.MPRequestHeaderRe
 
 	var gradeResponse *response.GradeResponse
 	if req.GradeID != nil {
-		gradeResponse, err = h.GradeMessage.SendFindByIDMessage(req.GradeID.String())
+		gradeResponse, err = h.GradeMessage.SendFindByIDMessage(ctx, req.GradeID.String())
 		if err != nil {
 			h.Log.Errorf("[MPRequestHelper] error when send find grade by id message: %v", err)
 			return nil, err
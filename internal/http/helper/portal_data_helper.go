@@ -0,0 +1,524 @@
+package helper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// portalLookupConcurrency bounds the by id messages in flight for the kinds
+// the portal has no bulk message for.
+const portalLookupConcurrency = 8
+
+// IPortalDataHelper fills the portal names of a page of rows. It collects the
+// ids referenced by the whole page first and resolves every kind once, instead
+// of sending messages row by row. The messages are sent under ctx.
+type IPortalDataHelper interface {
+	EnrichMPPlanningHeaders(ctx context.Context, mpPlanningHeaders []entity.MPPlanningHeader) error
+	EnrichMPPlanningLines(ctx context.Context, mpPlanningLines []entity.MPPlanningLine) error
+	EnrichMPRequestHeaders(ctx context.Context, mpRequestHeaders []entity.MPRequestHeader) error
+	EnrichBatchLines(ctx context.Context, batchLines []entity.BatchLine) error
+	EnrichPlanVsActualRows(ctx context.Context, rows []response.PlanVsActualRowResponse) error
+}
+
+type PortalDataHelper struct {
+	Log                 *logrus.Logger
+	OrganizationMessage messaging.IOrganizationMessage
+	JobMessage          messaging.IJobMessage
+	JobPlafonMessage    messaging.IJobPlafonMessage
+	EmployeeMessage     messaging.IEmployeeMessage
+	GradeMessage        messaging.IGradeMessage
+}
+
+func NewPortalDataHelper(
+	log *logrus.Logger,
+	organizationMessage messaging.IOrganizationMessage,
+	jobMessage messaging.IJobMessage,
+	jobPlafonMessage messaging.IJobPlafonMessage,
+	employeeMessage messaging.IEmployeeMessage,
+	gradeMessage messaging.IGradeMessage,
+) IPortalDataHelper {
+	return &PortalDataHelper{
+		Log:                 log,
+		OrganizationMessage: organizationMessage,
+		JobMessage:          jobMessage,
+		JobPlafonMessage:    jobPlafonMessage,
+		EmployeeMessage:     employeeMessage,
+		GradeMessage:        gradeMessage,
+	}
+}
+
+func PortalDataHelperFactory(log *logrus.Logger) IPortalDataHelper {
+	organizationMessage := messaging.OrganizationMessageFactory(log)
+	jobMessage := messaging.JobMessageFactory(log)
+	jobPlafonMessage := messaging.JobPlafonMessageFactory(log)
+	employeeMessage := messaging.EmployeeMessageFactory(log)
+	gradeMessage := messaging.GradeMessageFactory(log)
+	return NewPortalDataHelper(log, organizationMessage, jobMessage, jobPlafonMessage, employeeMessage, gradeMessage)
+}
+
+// EnrichMPPlanningHeaders fills the names of the headers and their lines in place.
+func (h *PortalDataHelper) EnrichMPPlanningHeaders(ctx context.Context, mpPlanningHeaders []entity.MPPlanningHeader) error {
+	ids := newPortalIDs()
+	for i := range mpPlanningHeaders {
+		ids.addMPPlanningHeader(&mpPlanningHeaders[i])
+	}
+
+	data, err := h.resolve(ctx, ids)
+	if err != nil {
+		h.Log.Errorf("[PortalDataHelper.EnrichMPPlanningHeaders] " + err.Error())
+		return err
+	}
+
+	for i := range mpPlanningHeaders {
+		data.fillMPPlanningHeader(&mpPlanningHeaders[i])
+	}
+
+	return nil
+}
+
+// EnrichMPPlanningLines fills the names of the lines in place.
+func (h *PortalDataHelper) EnrichMPPlanningLines(ctx context.Context, mpPlanningLines []entity.MPPlanningLine) error {
+	ids := newPortalIDs()
+	for i := range mpPlanningLines {
+		ids.addMPPlanningLine(&mpPlanningLines[i])
+	}
+
+	data, err := h.resolve(ctx, ids)
+	if err != nil {
+		h.Log.Errorf("[PortalDataHelper.EnrichMPPlanningLines] " + err.Error())
+		return err
+	}
+
+	for i := range mpPlanningLines {
+		data.fillMPPlanningLine(&mpPlanningLines[i])
+	}
+
+	return nil
+}
+
+// EnrichMPRequestHeaders fills the organization, location, structure, job, job
+// level, grade and approver names of the headers in place, and the category of
+// their organization.
+func (h *PortalDataHelper) EnrichMPRequestHeaders(ctx context.Context, mpRequestHeaders []entity.MPRequestHeader) error {
+	ids := newPortalIDs()
+	for i := range mpRequestHeaders {
+		ids.addMPRequestHeader(&mpRequestHeaders[i])
+	}
+
+	data, err := h.resolve(ctx, ids)
+	if err != nil {
+		h.Log.Errorf("[PortalDataHelper.EnrichMPRequestHeaders] " + err.Error())
+		return err
+	}
+
+	for i := range mpRequestHeaders {
+		data.fillMPRequestHeader(&mpRequestHeaders[i])
+	}
+
+	return nil
+}
+
+// EnrichBatchLines fills the names of the batch lines and of their preloaded
+// mp planning headers in place.
+func (h *PortalDataHelper) EnrichBatchLines(ctx context.Context, batchLines []entity.BatchLine) error {
+	ids := newPortalIDs()
+	for i := range batchLines {
+		ids.addBatchLine(&batchLines[i])
+	}
+
+	data, err := h.resolve(ctx, ids)
+	if err != nil {
+		h.Log.Errorf("[PortalDataHelper.EnrichBatchLines] " + err.Error())
+		return err
+	}
+
+	for i := range batchLines {
+		data.fillBatchLine(&batchLines[i])
+	}
+
+	return nil
+}
+
+// EnrichPlanVsActualRows fills the organization, location, job level and job
+// names of the report rows in place.
+func (h *PortalDataHelper) EnrichPlanVsActualRows(ctx context.Context, rows []response.PlanVsActualRowResponse) error {
+	ids := newPortalIDs()
+	for i := range rows {
+		ids.addPlanVsActualRow(&rows[i])
+	}
+
+	data, err := h.resolve(ctx, ids)
+	if err != nil {
+		h.Log.Errorf("[PortalDataHelper.EnrichPlanVsActualRows] " + err.Error())
+		return err
+	}
+
+	for i := range rows {
+		data.fillPlanVsActualRow(&rows[i])
+	}
+
+	return nil
+}
+
+// resolve looks up every collected id, one kind at a time in parallel.
+// Organizations, organization locations and jobs are resolved with one bulk
+// message each. The portal has no bulk message for organization categories,
+// organization structures, job levels, employees and grades, so their distinct
+// ids are looked up concurrently through the cached by id messages. Employee
+// lookups that fail leave the name empty, as the row by row lookups did.
+func (h *PortalDataHelper) resolve(ctx context.Context, ids *portalIDs) (*portalData, error) {
+	data := &portalData{}
+	// the first lookup to fail cancels the others
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		var err error
+		data.organizations, err = h.findOrganizationNames(ctx, ids.organizations.list())
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		data.organizationLocations, err = h.findOrganizationLocationNames(ctx, ids.organizationLocations.list())
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		data.jobs, err = h.findJobNames(ctx, ids.jobs.list())
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		data.organizationCategories, err = findEach(ids.organizationCategories, func(id string) (*string, error) {
+			org, err := h.OrganizationMessage.SendFindOrganizationByIDMessage(ctx, request.SendFindOrganizationByIDMessageRequest{
+				ID: id,
+			})
+			if err != nil || org == nil {
+				return nil, err
+			}
+			return &org.OrganizationCategory, nil
+		})
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		data.organizationStructures, err = findEach(ids.organizationStructures, func(id string) (*string, error) {
+			orgStructure, err := h.OrganizationMessage.SendFindOrganizationStructureByIDMessage(ctx, request.SendFindOrganizationStructureByIDMessageRequest{
+				ID: id,
+			})
+			if err != nil || orgStructure == nil {
+				return nil, err
+			}
+			return &orgStructure.Name, nil
+		})
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		data.jobLevels, err = findEach(ids.jobLevels, func(id string) (*response.SendFindJobLevelByIDMessageResponse, error) {
+			return h.JobPlafonMessage.SendFindJobLevelByIDMessage(ctx, request.SendFindJobLevelByIDMessageRequest{
+				ID: id,
+			})
+		})
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		data.employees, err = findEach(ids.employees, func(id string) (*string, error) {
+			employee, err := h.EmployeeMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
+				ID: id,
+			})
+			if err != nil {
+				h.Log.Warnf("[PortalDataHelper.resolve] employee %s: %v", id, err)
+				return nil, nil
+			}
+			if employee == nil {
+				return nil, nil
+			}
+			return &employee.Name, nil
+		})
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		data.grades, err = findEach(ids.grades, func(id string) (*string, error) {
+			grade, err := h.GradeMessage.SendFindByIDMessage(ctx, id)
+			if err != nil || grade == nil {
+				return nil, err
+			}
+			return &grade.Name, nil
+		})
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (h *PortalDataHelper) findOrganizationNames(ctx context.Context, ids []string) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	orgs, err := h.OrganizationMessage.SendFindAllOrganizationMessage(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, org := range *orgs {
+		names[org.ID.String()] = org.Name
+	}
+
+	return names, nil
+}
+
+func (h *PortalDataHelper) findOrganizationLocationNames(ctx context.Context, ids []string) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	orgLocations, err := h.OrganizationMessage.SendFindAllOrganizationLocationsMessage(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, orgLocation := range *orgLocations {
+		names[orgLocation.ID.String()] = orgLocation.Name
+	}
+
+	return names, nil
+}
+
+func (h *PortalDataHelper) findJobNames(ctx context.Context, ids []string) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	jobs, err := h.JobMessage.SendFindAllJobsIDsMessage(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range *jobs {
+		names[job.ID.String()] = job.Name
+	}
+
+	return names, nil
+}
+
+// findEach calls find for every id with at most portalLookupConcurrency calls
+// in flight. Ids that find returns nil for are left out of the result.
+func findEach[V any](ids idSet, find func(id string) (*V, error)) (map[string]V, error) {
+	results := make(map[string]V, len(ids))
+	var mu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(portalLookupConcurrency)
+
+	for id := range ids {
+		g.Go(func() error {
+			value, err := find(id)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				return nil
+			}
+			mu.Lock()
+			results[id] = *value
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type idSet map[string]struct{}
+
+func (s idSet) add(id *uuid.UUID) {
+	if id == nil || *id == uuid.Nil {
+		return
+	}
+	s[id.String()] = struct{}{}
+}
+
+func (s idSet) list() []string {
+	ids := make([]string, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// portalIDs are the distinct ids referenced by a page, by kind.
+type portalIDs struct {
+	organizations          idSet
+	organizationCategories idSet
+	organizationLocations  idSet
+	organizationStructures idSet
+	jobs                   idSet
+	jobLevels              idSet
+	employees              idSet
+	grades                 idSet
+}
+
+func newPortalIDs() *portalIDs {
+	return &portalIDs{
+		organizations:          idSet{},
+		organizationCategories: idSet{},
+		organizationLocations:  idSet{},
+		organizationStructures: idSet{},
+		jobs:                   idSet{},
+		jobLevels:              idSet{},
+		employees:              idSet{},
+		grades:                 idSet{},
+	}
+}
+
+func (ids *portalIDs) addMPPlanningHeader(header *entity.MPPlanningHeader) {
+	ids.organizations.add(header.OrganizationID)
+	ids.organizations.add(header.EmpOrganizationID)
+	ids.organizationLocations.add(header.OrganizationLocationID)
+	ids.jobs.add(header.JobID)
+	ids.employees.add(header.RequestorID)
+	ids.employees.add(header.ApproverManagerID)
+	ids.employees.add(header.ApproverRecruitmentID)
+
+	for i := range header.MPPlanningLines {
+		ids.addMPPlanningLine(&header.MPPlanningLines[i])
+	}
+}
+
+func (ids *portalIDs) addMPPlanningLine(line *entity.MPPlanningLine) {
+	ids.organizationLocations.add(line.OrganizationLocationID)
+	ids.jobLevels.add(line.JobLevelID)
+	ids.jobs.add(line.JobID)
+}
+
+func (ids *portalIDs) addMPRequestHeader(header *entity.MPRequestHeader) {
+	ids.organizations.add(header.OrganizationID)
+	ids.organizations.add(header.ForOrganizationID)
+	ids.organizations.add(header.EmpOrganizationID)
+	ids.organizationCategories.add(header.OrganizationID)
+	ids.organizationLocations.add(header.OrganizationLocationID)
+	ids.organizationLocations.add(header.ForOrganizationLocationID)
+	ids.organizationStructures.add(header.ForOrganizationStructureID)
+	ids.jobs.add(header.JobID)
+	ids.jobLevels.add(header.JobLevelID)
+	ids.grades.add(header.GradeID)
+	ids.employees.add(header.RequestorID)
+	ids.employees.add(header.DepartmentHead)
+	ids.employees.add(header.VpGmDirector)
+	ids.employees.add(header.CEO)
+	ids.employees.add(header.HrdHoUnit)
+}
+
+func (ids *portalIDs) addBatchLine(batchLine *entity.BatchLine) {
+	ids.organizations.add(&batchLine.OrganizationID)
+	ids.organizationLocations.add(&batchLine.OrganizationLocationID)
+	if batchLine.MPPlanningHeader.ID != uuid.Nil {
+		ids.addMPPlanningHeader(&batchLine.MPPlanningHeader)
+	}
+}
+
+func (ids *portalIDs) addPlanVsActualRow(row *response.PlanVsActualRowResponse) {
+	ids.organizations.add(row.OrganizationID)
+	ids.organizationLocations.add(row.OrganizationLocationID)
+	ids.jobLevels.add(row.JobLevelID)
+	ids.jobs.add(row.JobID)
+}
+
+// portalData is the resolved portal data of a page, keyed by id.
+type portalData struct {
+	organizations          map[string]string
+	organizationCategories map[string]string
+	organizationLocations  map[string]string
+	organizationStructures map[string]string
+	jobs                   map[string]string
+	jobLevels              map[string]response.SendFindJobLevelByIDMessageResponse
+	employees              map[string]string
+	grades                 map[string]string
+}
+
+func (d *portalData) fillMPPlanningHeader(header *entity.MPPlanningHeader) {
+	header.OrganizationName = lookup(d.organizations, header.OrganizationID)
+	header.EmpOrganizationName = lookup(d.organizations, header.EmpOrganizationID)
+	header.OrganizationLocationName = lookup(d.organizationLocations, header.OrganizationLocationID)
+	header.JobName = lookup(d.jobs, header.JobID)
+	header.RequestorName = lookup(d.employees, header.RequestorID)
+	header.ApproverManagerName = lookup(d.employees, header.ApproverManagerID)
+	header.ApproverRecruitmentName = lookup(d.employees, header.ApproverRecruitmentID)
+
+	for i := range header.MPPlanningLines {
+		d.fillMPPlanningLine(&header.MPPlanningLines[i])
+	}
+}
+
+func (d *portalData) fillMPPlanningLine(line *entity.MPPlanningLine) {
+	line.OrganizationLocationName = lookup(d.organizationLocations, line.OrganizationLocationID)
+	line.JobName = lookup(d.jobs, line.JobID)
+	jobLevel := lookup(d.jobLevels, line.JobLevelID)
+	line.JobLevelName = jobLevel.Name
+	line.JobLevel = int(jobLevel.Level)
+}
+
+func (d *portalData) fillMPRequestHeader(header *entity.MPRequestHeader) {
+	header.OrganizationName = lookup(d.organizations, header.OrganizationID)
+	header.OrganizationCategory = lookup(d.organizationCategories, header.OrganizationID)
+	header.OrganizationLocationName = lookup(d.organizationLocations, header.OrganizationLocationID)
+	header.ForOrganizationName = lookup(d.organizations, header.ForOrganizationID)
+	header.ForOrganizationLocation = lookup(d.organizationLocations, header.ForOrganizationLocationID)
+	header.ForOrganizationStructure = lookup(d.organizationStructures, header.ForOrganizationStructureID)
+	header.EmpOrganizationName = lookup(d.organizations, header.EmpOrganizationID)
+	header.JobName = lookup(d.jobs, header.JobID)
+	header.GradeName = lookup(d.grades, header.GradeID)
+	if jobLevel := lookup(d.jobLevels, header.JobLevelID); jobLevel.Name != "" {
+		header.JobLevelName = jobLevel.Name
+		header.JobLevel = int(jobLevel.Level)
+	}
+	header.RequestorName = lookup(d.employees, header.RequestorID)
+	header.DepartmentHeadName = lookup(d.employees, header.DepartmentHead)
+	header.VpGmDirectorName = lookup(d.employees, header.VpGmDirector)
+	header.CeoName = lookup(d.employees, header.CEO)
+	header.HrdHoUnitName = lookup(d.employees, header.HrdHoUnit)
+}
+
+func (d *portalData) fillBatchLine(batchLine *entity.BatchLine) {
+	batchLine.OrganizationName = lookup(d.organizations, &batchLine.OrganizationID)
+	batchLine.OrganizationLocationName = lookup(d.organizationLocations, &batchLine.OrganizationLocationID)
+	if batchLine.MPPlanningHeader.ID != uuid.Nil {
+		d.fillMPPlanningHeader(&batchLine.MPPlanningHeader)
+	}
+}
+
+func (d *portalData) fillPlanVsActualRow(row *response.PlanVsActualRowResponse) {
+	row.OrganizationName = lookup(d.organizations, row.OrganizationID)
+	row.OrganizationLocationName = lookup(d.organizationLocations, row.OrganizationLocationID)
+	jobLevel := lookup(d.jobLevels, row.JobLevelID)
+	row.JobLevelName = jobLevel.Name
+	row.JobLevel = int(jobLevel.Level)
+	row.JobName = lookup(d.jobs, row.JobID)
+}
+
+// lookup returns the value of id, or the zero value when id is nil or was not found.
+func lookup[V any](values map[string]V, id *uuid.UUID) V {
+	if id == nil {
+		var zero V
+		return zero
+	}
+	return values[id.String()]
+}
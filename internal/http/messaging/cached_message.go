@@ -0,0 +1,225 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+)
+
+// The cached messages answer the find by id messages from the lookup cache
+// and pass every other message through to the wrapped message. Results are
+// returned as copies so callers cannot change the cached values.
+
+type CachedOrganizationMessage struct {
+	IOrganizationMessage
+	Cache *LookupCache
+}
+
+func NewCachedOrganizationMessage(message IOrganizationMessage, cache *LookupCache) IOrganizationMessage {
+	return &CachedOrganizationMessage{
+		IOrganizationMessage: message,
+		Cache:                cache,
+	}
+}
+
+func (m *CachedOrganizationMessage) SendFindOrganizationByIDMessage(ctx context.Context, req request.SendFindOrganizationByIDMessageRequest) (*response.SendFindOrganizationByIDMessageResponse, error) {
+	return cachedGet(m.Cache.Organizations, req.ID, func() (*response.SendFindOrganizationByIDMessageResponse, error) {
+		return m.IOrganizationMessage.SendFindOrganizationByIDMessage(ctx, req)
+	})
+}
+
+func (m *CachedOrganizationMessage) SendFindOrganizationLocationByIDMessage(ctx context.Context, req request.SendFindOrganizationLocationByIDMessageRequest) (*response.SendFindOrganizationLocationByIDMessageResponse, error) {
+	return cachedGet(m.Cache.OrganizationLocations, req.ID, func() (*response.SendFindOrganizationLocationByIDMessageResponse, error) {
+		return m.IOrganizationMessage.SendFindOrganizationLocationByIDMessage(ctx, req)
+	})
+}
+
+func (m *CachedOrganizationMessage) SendFindOrganizationStructureByIDMessage(ctx context.Context, req request.SendFindOrganizationStructureByIDMessageRequest) (*response.SendFindOrganizationStructureByIDMessageResponse, error) {
+	return cachedGet(m.Cache.OrganizationStructures, req.ID, func() (*response.SendFindOrganizationStructureByIDMessageResponse, error) {
+		return m.IOrganizationMessage.SendFindOrganizationStructureByIDMessage(ctx, req)
+	})
+}
+
+// SendFindAllOrganizationLocationsMessage also caches the locations it finds.
+func (m *CachedOrganizationMessage) SendFindAllOrganizationLocationsMessage(ctx context.Context, includedIDs []string) (*[]response.OrganizationLocationResponse, error) {
+	orgLocs, err := m.IOrganizationMessage.SendFindAllOrganizationLocationsMessage(ctx, includedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, orgLoc := range *orgLocs {
+		m.Cache.OrganizationLocations.Set(orgLoc.ID.String(), response.SendFindOrganizationLocationByIDMessageResponse{
+			OrganizationLocationID: orgLoc.ID.String(),
+			Name:                   orgLoc.Name,
+		})
+	}
+
+	return orgLocs, nil
+}
+
+// PrefetchOrganizationLocations loads the uncached ids in one bulk message.
+// The bulk organizations message has no organization category, so
+// organizations are only cached one by one.
+func (m *CachedOrganizationMessage) PrefetchOrganizationLocations(ctx context.Context, ids []string) error {
+	missing := m.Cache.OrganizationLocations.Missing(ids)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	_, err := m.SendFindAllOrganizationLocationsMessage(ctx, missing)
+	return err
+}
+
+type CachedJobMessage struct {
+	IJobMessage
+	Cache *LookupCache
+}
+
+func NewCachedJobMessage(message IJobMessage, cache *LookupCache) IJobMessage {
+	return &CachedJobMessage{
+		IJobMessage: message,
+		Cache:       cache,
+	}
+}
+
+func (m *CachedJobMessage) SendFindJobDataByIdMessage(ctx context.Context, req request.SendFindJobByIDMessageRequest) (*response.JobResponse, error) {
+	return cachedGet(m.Cache.Jobs, req.ID, func() (*response.JobResponse, error) {
+		return m.IJobMessage.SendFindJobDataByIdMessage(ctx, req)
+	})
+}
+
+// SendFindAllJobsIDsMessage also caches the jobs it finds.
+func (m *CachedJobMessage) SendFindAllJobsIDsMessage(ctx context.Context, ids []string) (*[]response.JobResponse, error) {
+	jobs, err := m.IJobMessage.SendFindAllJobsIDsMessage(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range *jobs {
+		m.Cache.Jobs.Set(job.ID.String(), job)
+		m.Cache.JobNames.Set(job.ID.String(), response.SendFindJobByIDMessageResponse{
+			JobID: job.ID,
+			Name:  job.Name,
+		})
+	}
+
+	return jobs, nil
+}
+
+// PrefetchJobs loads the uncached ids in one bulk message.
+func (m *CachedJobMessage) PrefetchJobs(ctx context.Context, ids []string) error {
+	missing := m.Cache.Jobs.Missing(ids)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	_, err := m.SendFindAllJobsIDsMessage(ctx, missing)
+	return err
+}
+
+type CachedJobPlafonMessage struct {
+	IJobPlafonMessage
+	Cache *LookupCache
+}
+
+func NewCachedJobPlafonMessage(message IJobPlafonMessage, cache *LookupCache) IJobPlafonMessage {
+	return &CachedJobPlafonMessage{
+		IJobPlafonMessage: message,
+		Cache:             cache,
+	}
+}
+
+func (m *CachedJobPlafonMessage) SendFindJobByIDMessage(ctx context.Context, req request.SendFindJobByIDMessageRequest) (*response.SendFindJobByIDMessageResponse, error) {
+	return cachedGet(m.Cache.JobNames, req.ID, func() (*response.SendFindJobByIDMessageResponse, error) {
+		return m.IJobPlafonMessage.SendFindJobByIDMessage(ctx, req)
+	})
+}
+
+func (m *CachedJobPlafonMessage) SendFindJobLevelByIDMessage(ctx context.Context, req request.SendFindJobLevelByIDMessageRequest) (*response.SendFindJobLevelByIDMessageResponse, error) {
+	return cachedGet(m.Cache.JobLevels, req.ID, func() (*response.SendFindJobLevelByIDMessageResponse, error) {
+		return m.IJobPlafonMessage.SendFindJobLevelByIDMessage(ctx, req)
+	})
+}
+
+type CachedEmployeeMessage struct {
+	IEmployeeMessage
+	Cache *LookupCache
+}
+
+func NewCachedEmployeeMessage(message IEmployeeMessage, cache *LookupCache) IEmployeeMessage {
+	return &CachedEmployeeMessage{
+		IEmployeeMessage: message,
+		Cache:            cache,
+	}
+}
+
+func (m *CachedEmployeeMessage) SendFindEmployeeByIDMessage(ctx context.Context, req request.SendFindEmployeeByIDMessageRequest) (*response.EmployeeResponse, error) {
+	return cachedGet(m.Cache.Employees, req.ID, func() (*response.EmployeeResponse, error) {
+		return m.IEmployeeMessage.SendFindEmployeeByIDMessage(ctx, req)
+	})
+}
+
+type CachedGradeMessage struct {
+	IGradeMessage
+	Cache *LookupCache
+}
+
+func NewCachedGradeMessage(message IGradeMessage, cache *LookupCache) IGradeMessage {
+	return &CachedGradeMessage{
+		IGradeMessage: message,
+		Cache:         cache,
+	}
+}
+
+func (m *CachedGradeMessage) SendFindByIDMessage(ctx context.Context, id string) (*response.GradeResponse, error) {
+	return cachedGet(m.Cache.Grades, id, func() (*response.GradeResponse, error) {
+		return m.IGradeMessage.SendFindByIDMessage(ctx, id)
+	})
+}
+
+// PrefetchJobs loads the jobs of ids into the lookup cache in one message when
+// the job message is cached, so the lookups of a page that follow hit the cache.
+func PrefetchJobs(ctx context.Context, message IJobMessage, ids []string) error {
+	if cached, ok := message.(*CachedJobMessage); ok {
+		return cached.PrefetchJobs(ctx, ids)
+	}
+	return nil
+}
+
+// PrefetchOrganizationLocations is PrefetchJobs for organization locations.
+func PrefetchOrganizationLocations(ctx context.Context, message IOrganizationMessage, ids []string) error {
+	if cached, ok := message.(*CachedOrganizationMessage); ok {
+		return cached.PrefetchOrganizationLocations(ctx, ids)
+	}
+	return nil
+}
+
+// errNotCached keeps a lookup that found nothing out of the cache.
+var errNotCached = errors.New("lookup result not cached")
+
+// cachedGet returns a copy of the cached value of id, loading it when missing.
+// A nil result is not cached.
+func cachedGet[V any](cache *ttlCache[V], id string, load func() (*V, error)) (*V, error) {
+	value, err := cache.Get(id, func() (V, error) {
+		loaded, err := load()
+		if err != nil {
+			var zero V
+			return zero, err
+		}
+		if loaded == nil {
+			var zero V
+			return zero, errNotCached
+		}
+		return *loaded, nil
+	})
+	if err == errNotCached {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
@@ -1,58 +1,39 @@
 package messaging
 
 import (
+	"context"
 	"errors"
 	"log"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type IEmployeeMessage interface {
-	SendFindEmployeeByIDMessage(req request.SendFindEmployeeByIDMessageRequest) (*response.EmployeeResponse, error)
+	SendFindEmployeeByIDMessage(ctx context.Context, req request.SendFindEmployeeByIDMessageRequest) (*response.EmployeeResponse, error)
 }
 
 type EmployeeMessage struct {
-	Log *logrus.Logger
+	Log       *logrus.Logger
+	RPCClient *RPCClient
 }
 
-func NewEmployeeMessage(log *logrus.Logger) IEmployeeMessage {
+func NewEmployeeMessage(log *logrus.Logger, rpcClient *RPCClient) IEmployeeMessage {
 	return &EmployeeMessage{
-		Log: log,
+		Log:       log,
+		RPCClient: rpcClient,
 	}
 }
 
-func (m *EmployeeMessage) SendFindEmployeeByIDMessage(req request.SendFindEmployeeByIDMessageRequest) (*response.EmployeeResponse, error) {
+func (m *EmployeeMessage) SendFindEmployeeByIDMessage(ctx context.Context, req request.SendFindEmployeeByIDMessageRequest) (*response.EmployeeResponse, error) {
 	payload := map[string]interface{}{
 		"employee_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_employee_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_employee_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +60,7 @@ func convertInterfaceToEmployeeResponse(data map[string]interface{}) *response.E
 	email := data["email"].(string)
 	mobilePhone := data["mobile_phone"].(string)
 	employeeJob := data["employee_job"].(map[string]interface{})
+	userID, _ := data["user_id"].(string)
 
 	return &response.EmployeeResponse{
 		ID:             uuid.MustParse(id),
@@ -88,10 +70,12 @@ func convertInterfaceToEmployeeResponse(data map[string]interface{}) *response.E
 		RetirementDate: retirementDate,
 		Email:          email,
 		MobilePhone:    mobilePhone,
+		UserID:         userID,
 		EmployeeJob:    employeeJob,
 	}
 }
 
 func EmployeeMessageFactory(log *logrus.Logger) IEmployeeMessage {
-	return NewEmployeeMessage(log)
+	rpcClient := RPCClientFactory(log)
+	return NewCachedEmployeeMessage(NewEmployeeMessage(log, rpcClient), LookupCacheFactory(log))
 }
@@ -0,0 +1,163 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultEventsExchange is the topic exchange domain events are published on
+// when rabbitmq.events_exchange is not configured.
+const DefaultEventsExchange = "julong_manpower.events"
+
+// DomainEventSource identifies this service in the events it publishes.
+const DomainEventSource = "julong_manpower"
+
+// DomainEventType is also the routing key of the event, so consumers can bind
+// to a single event or to a document with e.g. "mp_planning.*".
+type DomainEventType string
+
+const (
+	DomainEventMPPPeriodOpened    DomainEventType = "mpp_period.opened"
+	DomainEventMPPlanningApproved DomainEventType = "mp_planning.approved"
+	DomainEventMPRequestCompleted DomainEventType = "mp_request.completed"
+	DomainEventBatchCompleted     DomainEventType = "batch.completed"
+	DomainEventJobPlafonChanged   DomainEventType = "job_plafon.changed"
+)
+
+// domainEventVersions are the current schema versions of the event data. Bump
+// the version of an event whenever its data changes incompatibly.
+var domainEventVersions = map[DomainEventType]int{
+	DomainEventMPPPeriodOpened:    1,
+	DomainEventMPPlanningApproved: 1,
+	DomainEventMPRequestCompleted: 1,
+	DomainEventBatchCompleted:     1,
+	DomainEventJobPlafonChanged:   1,
+}
+
+// DomainEvent is the envelope of every published event. Data is the snapshot
+// of the document at the time of the event.
+type DomainEvent struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       DomainEventType `json:"type"`
+	Version    int             `json:"version"`
+	Source     string          `json:"source"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewDomainEvent wraps the data snapshot in a new event of eventType.
+func NewDomainEvent(eventType DomainEventType, data interface{}) (*DomainEvent, error) {
+	version, ok := domainEventVersions[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown domain event type %q", eventType)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DomainEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		Version:    version,
+		Source:     DomainEventSource,
+		OccurredAt: time.Now().UTC(),
+		Data:       raw,
+	}, nil
+}
+
+// JobPlafonChange is the action reported by a job_plafon.changed event.
+type JobPlafonChange string
+
+const (
+	JobPlafonChangeCreated JobPlafonChange = "created"
+	JobPlafonChangeUpdated JobPlafonChange = "updated"
+	JobPlafonChangeDeleted JobPlafonChange = "deleted"
+)
+
+// JobPlafonChangedData is the data of a job_plafon.changed event, job plafons
+// have no response DTO so the entity is the snapshot.
+type JobPlafonChangedData struct {
+	Change    JobPlafonChange   `json:"change"`
+	JobPlafon *entity.JobPlafon `json:"job_plafon"`
+}
+
+type IEventPublisher interface {
+	Publish(ctx context.Context, event *DomainEvent) error
+}
+
+// EventPublisher publishes domain events on the events exchange and waits for
+// the broker to confirm them.
+type EventPublisher struct {
+	Log       *logrus.Logger
+	Exchange  string
+	RPCClient *RPCClient // tracks whether the broker connection is up
+}
+
+func NewEventPublisher(log *logrus.Logger, exchange string, rpcClient *RPCClient) IEventPublisher {
+	return &EventPublisher{
+		Log:       log,
+		Exchange:  exchange,
+		RPCClient: rpcClient,
+	}
+}
+
+func (p *EventPublisher) Publish(ctx context.Context, event *DomainEvent) error {
+	if !p.RPCClient.Ready() {
+		return fmt.Errorf("[EventPublisher.Publish] %s: %w", event.Type, ErrBrokerUnavailable)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.New("[EventPublisher.Publish] " + err.Error())
+	}
+
+	// buffered so a late confirmation never blocks the producer
+	result := make(chan error, 1)
+	msg := utils.EventMsg{
+		Exchange:   p.Exchange,
+		RoutingKey: string(event.Type),
+		MessageID:  event.ID.String(),
+		Version:    event.Version,
+		Body:       body,
+		Result:     result,
+	}
+
+	select {
+	case utils.Echan <- msg:
+	case <-ctx.Done():
+		return fmt.Errorf("[EventPublisher.Publish] publish %s: %w", event.Type, ctx.Err())
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("[EventPublisher.Publish] publish %s: %w", event.Type, err)
+		}
+		p.Log.Infof("[EventPublisher.Publish] published %s event %s", event.Type, event.ID)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[EventPublisher.Publish] confirm %s: %w", event.Type, ctx.Err())
+	}
+}
+
+func EventPublisherFactory(log *logrus.Logger) IEventPublisher {
+	viper := config.NewViper()
+
+	exchange := viper.GetString("rabbitmq.events_exchange")
+	if exchange == "" {
+		exchange = DefaultEventsExchange
+	}
+
+	return NewEventPublisher(log, exchange, RPCClientFactory(log))
+}
@@ -1,57 +1,36 @@
 package messaging
 
 import (
+	"context"
 	"errors"
 	"log"
 
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type IGradeMessage interface {
-	SendFindByIDMessage(id string) (*response.GradeResponse, error)
+	SendFindByIDMessage(ctx context.Context, id string) (*response.GradeResponse, error)
 }
 
 type GradeMessage struct {
-	Log *logrus.Logger
+	Log       *logrus.Logger
+	RPCClient *RPCClient
 }
 
-func NewGradeMessage(log *logrus.Logger) IGradeMessage {
+func NewGradeMessage(log *logrus.Logger, rpcClient *RPCClient) IGradeMessage {
 	return &GradeMessage{
-		Log: log,
+		Log:       log,
+		RPCClient: rpcClient,
 	}
 }
 
-func (m *GradeMessage) SendFindByIDMessage(id string) (*response.GradeResponse, error) {
+func (m *GradeMessage) SendFindByIDMessage(ctx context.Context, id string) (*response.GradeResponse, error) {
 	payload := map[string]interface{}{
 		"id": id,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_grade_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_grade_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -73,5 +52,6 @@ func (m *GradeMessage) SendFindByIDMessage(id string) (*response.GradeResponse,
 }
 
 func GradeMessageFactory(log *logrus.Logger) IGradeMessage {
-	return NewGradeMessage(log)
+	rpcClient := RPCClientFactory(log)
+	return NewCachedGradeMessage(NewGradeMessage(log, rpcClient), LookupCacheFactory(log))
 }
@@ -1,61 +1,42 @@
 package messaging
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type IJobMessage interface {
-	SendFindJobDataByIdMessage(request request.SendFindJobByIDMessageRequest) (*response.JobResponse, error)
-	SendGetAllJobDataMessage() (*[]response.JobResponse, error)
-	SendFindAllJobsIDsMessage(ids []string) (*[]response.JobResponse, error)
-	SendFindAllJobsByOrganizationIDMessage(orgId string) (*[]response.JobResponse, error)
+	SendFindJobDataByIdMessage(ctx context.Context, request request.SendFindJobByIDMessageRequest) (*response.JobResponse, error)
+	SendGetAllJobDataMessage(ctx context.Context) (*[]response.JobResponse, error)
+	SendFindAllJobsIDsMessage(ctx context.Context, ids []string) (*[]response.JobResponse, error)
+	SendFindAllJobsByOrganizationIDMessage(ctx context.Context, orgId string) (*[]response.JobResponse, error)
 }
 
 type JobMessage struct {
-	Log *logrus.Logger
+	Log       *logrus.Logger
+	RPCClient *RPCClient
 }
 
-func NewJobMessage(log *logrus.Logger) IJobMessage {
+func NewJobMessage(log *logrus.Logger, rpcClient *RPCClient) IJobMessage {
 	return &JobMessage{
-		Log: log,
+		Log:       log,
+		RPCClient: rpcClient,
 	}
 }
 
-func (m *JobMessage) SendFindJobDataByIdMessage(req request.SendFindJobByIDMessageRequest) (*response.JobResponse, error) {
+func (m *JobMessage) SendFindJobDataByIdMessage(ctx context.Context, req request.SendFindJobByIDMessageRequest) (*response.JobResponse, error) {
 	payload := map[string]interface{}{
 		"job_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_job_data_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_job_data_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -70,30 +51,8 @@ func (m *JobMessage) SendFindJobDataByIdMessage(req request.SendFindJobByIDMessa
 	return convertInterfaceToJobResponse(jobData), nil
 }
 
-func (m *JobMessage) SendGetAllJobDataMessage() (*[]response.JobResponse, error) {
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "get_all_job_data",
-		MessageData: nil,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+func (m *JobMessage) SendGetAllJobDataMessage(ctx context.Context) (*[]response.JobResponse, error) {
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "get_all_job_data", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -115,33 +74,12 @@ func (m *JobMessage) SendGetAllJobDataMessage() (*[]response.JobResponse, error)
 	return &jobsResponse, nil
 }
 
-func (m *JobMessage) SendFindAllJobsIDsMessage(ids []string) (*[]response.JobResponse, error) {
+func (m *JobMessage) SendFindAllJobsIDsMessage(ctx context.Context, ids []string) (*[]response.JobResponse, error) {
 	payload := map[string]interface{}{
 		"included_ids": ids,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_all_jobs_by_ids",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_all_jobs_by_ids", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -163,33 +101,12 @@ func (m *JobMessage) SendFindAllJobsIDsMessage(ids []string) (*[]response.JobRes
 	return &jobsResponse, nil
 }
 
-func (m *JobMessage) SendFindAllJobsByOrganizationIDMessage(orgId string) (*[]response.JobResponse, error) {
+func (m *JobMessage) SendFindAllJobsByOrganizationIDMessage(ctx context.Context, orgId string) (*[]response.JobResponse, error) {
 	payload := map[string]interface{}{
 		"organization_id": orgId,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_all_jobs_by_organization_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_all_jobs_by_organization_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -212,7 +129,8 @@ func (m *JobMessage) SendFindAllJobsByOrganizationIDMessage(orgId string) (*[]re
 }
 
 func JobMessageFactory(log *logrus.Logger) IJobMessage {
-	return NewJobMessage(log)
+	rpcClient := RPCClientFactory(log)
+	return NewCachedJobMessage(NewJobMessage(log, rpcClient), LookupCacheFactory(log))
 }
 
 func convertInterfaceToJobResponse(job map[string]interface{}) *response.JobResponse {
@@ -227,7 +145,11 @@ func convertInterfaceToJobResponse(job map[string]interface{}) *response.JobResp
 	level, _ := job["level"].(int)
 	parentID, _ := job["parent_id"].(string)
 	path, _ := job["path"].(string)
-	existing, _ := job["existing"].(int)
+	// the reply is decoded from json, where every number is a float64
+	existing, ok := job["existing"].(int)
+	if value, isFloat := job["existing"].(float64); !ok && isFloat {
+		existing = int(value)
+	}
 
 	// Handle Parent
 	var parentResponse *response.ParentJobResponse
@@ -1,66 +1,45 @@
 package messaging
 
 import (
+	"context"
 	"errors"
 	"log"
 
-	// "github.com/IlhamSetiaji/go-rabbitmq-utils/rabbitmq"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
 	jobResponse "github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type IJobPlafonMessage interface {
-	SendCheckJobExistMessage(request request.CheckJobExistMessageRequest) (*jobResponse.CheckJobExistMessageResponse, error)
-	SendFindJobByIDMessage(request request.SendFindJobByIDMessageRequest) (*jobResponse.SendFindJobByIDMessageResponse, error)
-	SendFindJobLevelByIDMessage(request request.SendFindJobLevelByIDMessageRequest) (*jobResponse.SendFindJobLevelByIDMessageResponse, error)
-	SendCheckJobByJobLevelMessage(request request.CheckJobByJobLevelRequest) (*jobResponse.CheckJobExistMessageResponse, error)
+	SendCheckJobExistMessage(ctx context.Context, request request.CheckJobExistMessageRequest) (*jobResponse.CheckJobExistMessageResponse, error)
+	SendFindJobByIDMessage(ctx context.Context, request request.SendFindJobByIDMessageRequest) (*jobResponse.SendFindJobByIDMessageResponse, error)
+	SendFindJobLevelByIDMessage(ctx context.Context, request request.SendFindJobLevelByIDMessageRequest) (*jobResponse.SendFindJobLevelByIDMessageResponse, error)
+	SendCheckJobByJobLevelMessage(ctx context.Context, request request.CheckJobByJobLevelRequest) (*jobResponse.CheckJobExistMessageResponse, error)
 	FindJobPlafonByJobIDMessage(jobID uuid.UUID) (*jobResponse.JobPlafonResponse, error)
 }
 
 type JobPlafonMessage struct {
 	Log           *logrus.Logger
+	RPCClient     *RPCClient
 	JobPlafonRepo repository.IJobPlafonRepository
 }
 
-func NewJobPlafonMessage(log *logrus.Logger, jpRepo repository.IJobPlafonRepository) IJobPlafonMessage {
+func NewJobPlafonMessage(log *logrus.Logger, rpcClient *RPCClient, jpRepo repository.IJobPlafonRepository) IJobPlafonMessage {
 	return &JobPlafonMessage{
 		Log:           log,
+		RPCClient:     rpcClient,
 		JobPlafonRepo: jpRepo,
 	}
 }
 
-func (m *JobPlafonMessage) SendCheckJobExistMessage(req request.CheckJobExistMessageRequest) (*jobResponse.CheckJobExistMessageResponse, error) {
+func (m *JobPlafonMessage) SendCheckJobExistMessage(ctx context.Context, req request.CheckJobExistMessageRequest) (*jobResponse.CheckJobExistMessageResponse, error) {
 	payload := map[string]interface{}{
 		"job_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_job_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_job_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -79,33 +58,12 @@ func (m *JobPlafonMessage) SendCheckJobExistMessage(req request.CheckJobExistMes
 	}, nil
 }
 
-func (m *JobPlafonMessage) SendFindJobByIDMessage(req request.SendFindJobByIDMessageRequest) (*jobResponse.SendFindJobByIDMessageResponse, error) {
+func (m *JobPlafonMessage) SendFindJobByIDMessage(ctx context.Context, req request.SendFindJobByIDMessageRequest) (*jobResponse.SendFindJobByIDMessageResponse, error) {
 	payload := map[string]interface{}{
 		"job_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_job_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_job_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -122,33 +80,12 @@ func (m *JobPlafonMessage) SendFindJobByIDMessage(req request.SendFindJobByIDMes
 	}, nil
 }
 
-func (m *JobPlafonMessage) SendFindJobLevelByIDMessage(req request.SendFindJobLevelByIDMessageRequest) (*jobResponse.SendFindJobLevelByIDMessageResponse, error) {
+func (m *JobPlafonMessage) SendFindJobLevelByIDMessage(ctx context.Context, req request.SendFindJobLevelByIDMessageRequest) (*jobResponse.SendFindJobLevelByIDMessageResponse, error) {
 	payload := map[string]interface{}{
 		"job_level_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_job_level_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_job_level_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -166,34 +103,13 @@ func (m *JobPlafonMessage) SendFindJobLevelByIDMessage(req request.SendFindJobLe
 	}, nil
 }
 
-func (m *JobPlafonMessage) SendCheckJobByJobLevelMessage(req request.CheckJobByJobLevelRequest) (*jobResponse.CheckJobExistMessageResponse, error) {
+func (m *JobPlafonMessage) SendCheckJobByJobLevelMessage(ctx context.Context, req request.CheckJobByJobLevelRequest) (*jobResponse.CheckJobExistMessageResponse, error) {
 	payload := map[string]interface{}{
 		"job_id":       req.JobID,
 		"job_level_id": req.JobLevelID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "check_job_by_job_level",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "check_job_by_job_level", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -229,6 +145,7 @@ func (m *JobPlafonMessage) FindJobPlafonByJobIDMessage(jobID uuid.UUID) (*jobRes
 }
 
 func JobPlafonMessageFactory(log *logrus.Logger) IJobPlafonMessage {
+	rpcClient := RPCClientFactory(log)
 	jpRepo := repository.JobPlafonRepositoryFactory(log)
-	return NewJobPlafonMessage(log, jpRepo)
+	return NewCachedJobPlafonMessage(NewJobPlafonMessage(log, rpcClient, jpRepo), LookupCacheFactory(log))
 }
@@ -0,0 +1,282 @@
+package messaging
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// LookupKind is a kind of portal data the lookup cache holds, it is also the
+// entity name of the portal change events.
+type LookupKind string
+
+const (
+	LookupKindOrganization          LookupKind = "organization"
+	LookupKindOrganizationLocation  LookupKind = "organization_location"
+	LookupKindOrganizationStructure LookupKind = "organization_structure"
+	LookupKindJob                   LookupKind = "job"
+	LookupKindJobLevel              LookupKind = "job_level"
+	LookupKindEmployee              LookupKind = "employee"
+	LookupKindGrade                 LookupKind = "grade"
+)
+
+// defaultLookupTTLs are used when cache.ttl.<kind> is not configured, in
+// seconds. A configured ttl of 0 or less disables caching of the kind, the
+// concurrent lookups of an id are still deduplicated.
+var defaultLookupTTLs = map[LookupKind]int{
+	LookupKindOrganization:          600,
+	LookupKindOrganizationLocation:  600,
+	LookupKindOrganizationStructure: 600,
+	LookupKindJob:                   600,
+	LookupKindJobLevel:              3600,
+	LookupKindEmployee:              300,
+	LookupKindGrade:                 3600,
+}
+
+// LookupCache holds the portal data looked up by id over RabbitMQ, shared by
+// all the cached messages of the process.
+type LookupCache struct {
+	Log                    *logrus.Logger
+	Organizations          *ttlCache[response.SendFindOrganizationByIDMessageResponse]
+	OrganizationLocations  *ttlCache[response.SendFindOrganizationLocationByIDMessageResponse]
+	OrganizationStructures *ttlCache[response.SendFindOrganizationStructureByIDMessageResponse]
+	Jobs                   *ttlCache[response.JobResponse]
+	JobNames               *ttlCache[response.SendFindJobByIDMessageResponse]
+	JobLevels              *ttlCache[response.SendFindJobLevelByIDMessageResponse]
+	Employees              *ttlCache[response.EmployeeResponse]
+	Grades                 *ttlCache[response.GradeResponse]
+}
+
+func NewLookupCache(log *logrus.Logger, ttls map[LookupKind]time.Duration) *LookupCache {
+	return &LookupCache{
+		Log:                    log,
+		Organizations:          newTTLCache[response.SendFindOrganizationByIDMessageResponse](ttls[LookupKindOrganization]),
+		OrganizationLocations:  newTTLCache[response.SendFindOrganizationLocationByIDMessageResponse](ttls[LookupKindOrganizationLocation]),
+		OrganizationStructures: newTTLCache[response.SendFindOrganizationStructureByIDMessageResponse](ttls[LookupKindOrganizationStructure]),
+		Jobs:                   newTTLCache[response.JobResponse](ttls[LookupKindJob]),
+		JobNames:               newTTLCache[response.SendFindJobByIDMessageResponse](ttls[LookupKindJob]),
+		JobLevels:              newTTLCache[response.SendFindJobLevelByIDMessageResponse](ttls[LookupKindJobLevel]),
+		Employees:              newTTLCache[response.EmployeeResponse](ttls[LookupKindEmployee]),
+		Grades:                 newTTLCache[response.GradeResponse](ttls[LookupKindGrade]),
+	}
+}
+
+// Invalidate drops the cached ids of kind, or every cached entry of kind when
+// ids is empty.
+func (c *LookupCache) Invalidate(kind LookupKind, ids []string) error {
+	switch kind {
+	case LookupKindOrganization:
+		c.Organizations.Invalidate(ids)
+	case LookupKindOrganizationLocation:
+		c.OrganizationLocations.Invalidate(ids)
+	case LookupKindOrganizationStructure:
+		c.OrganizationStructures.Invalidate(ids)
+	case LookupKindJob:
+		c.Jobs.Invalidate(ids)
+		c.JobNames.Invalidate(ids)
+	case LookupKindJobLevel:
+		c.JobLevels.Invalidate(ids)
+	case LookupKindEmployee:
+		c.Employees.Invalidate(ids)
+	case LookupKindGrade:
+		c.Grades.Invalidate(ids)
+	default:
+		return fmt.Errorf("unknown lookup kind %q", kind)
+	}
+
+	c.Log.Infof("[LookupCache.Invalidate] invalidated %d %s entries", len(ids), kind)
+	return nil
+}
+
+var (
+	lookupCacheInstance *LookupCache
+	lookupCacheOnce     sync.Once
+)
+
+// LookupCacheFactory returns the cache shared by all cached messages and the
+// consumer that invalidates it.
+func LookupCacheFactory(log *logrus.Logger) *LookupCache {
+	lookupCacheOnce.Do(func() {
+		viper := config.NewViper()
+
+		ttls := make(map[LookupKind]time.Duration, len(defaultLookupTTLs))
+		for kind, seconds := range defaultLookupTTLs {
+			key := "cache.ttl." + string(kind)
+			if viper.IsSet(key) {
+				seconds = viper.GetInt(key)
+			}
+			ttls[kind] = time.Duration(seconds) * time.Second
+		}
+
+		lookupCacheInstance = NewLookupCache(log, ttls)
+	})
+
+	return lookupCacheInstance
+}
+
+type ttlCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// maxLookupEntries caps the entries of each ttlCache, so ids that are looked
+// up once do not pile up between prunes.
+const maxLookupEntries = 10000
+
+// ttlCache is a read-through cache of values by id. Concurrent loads of the
+// same id share one call, and a load that races with an invalidation is not
+// stored so the invalidated value cannot come back. Expired entries are
+// dropped when read, and all of them once per ttl when a value is stored.
+type ttlCache[V any] struct {
+	ttl        time.Duration
+	mu         sync.RWMutex
+	entries    map[string]ttlCacheEntry[V]
+	generation uint64
+	prunedAt   time.Time
+	group      singleflight.Group
+}
+
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+	return &ttlCache[V]{
+		ttl:     ttl,
+		entries: make(map[string]ttlCacheEntry[V]),
+	}
+}
+
+// Get returns the cached value of id, or loads and caches it.
+func (c *ttlCache[V]) Get(id string, load func() (V, error)) (V, error) {
+	if value, ok := c.lookup(id); ok {
+		return value, nil
+	}
+
+	result, err, _ := c.group.Do(id, func() (interface{}, error) {
+		generation := c.currentGeneration()
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		c.store(id, value, generation)
+		return value, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	return result.(V), nil
+}
+
+// Set caches a value loaded in bulk.
+func (c *ttlCache[V]) Set(id string, value V) {
+	c.store(id, value, c.currentGeneration())
+}
+
+// Missing returns the ids without a live entry, without duplicates.
+func (c *ttlCache[V]) Missing(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	var missing []string
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, ok := c.lookup(id); !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// Invalidate drops the ids, or every entry when ids is empty.
+func (c *ttlCache[V]) Invalidate(ids []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if len(ids) == 0 {
+		c.entries = make(map[string]ttlCacheEntry[V])
+		return
+	}
+	for _, id := range ids {
+		delete(c.entries, id)
+		c.group.Forget(id)
+	}
+}
+
+func (c *ttlCache[V]) lookup(id string) (V, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[id]
+	c.mu.RUnlock()
+
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.mu.Lock()
+		// unless it was stored again meanwhile
+		if current, ok := c.entries[id]; ok && current.expiresAt.Equal(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+		c.mu.Unlock()
+
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[V]) store(id string, value V, generation uint64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(c.prunedAt) >= c.ttl || len(c.entries) >= maxLookupEntries {
+		c.prune(now)
+	}
+	if _, ok := c.entries[id]; !ok && len(c.entries) >= maxLookupEntries {
+		c.evictSoonest()
+	}
+	c.entries[id] = ttlCacheEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// prune drops the expired entries, with mu held.
+func (c *ttlCache[V]) prune(now time.Time) {
+	for id, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	c.prunedAt = now
+}
+
+// evictSoonest drops the entry closest to expiring, with mu held.
+func (c *ttlCache[V]) evictSoonest() {
+	var soonestID string
+	var soonest time.Time
+	for id, entry := range c.entries {
+		if soonestID == "" || entry.expiresAt.Before(soonest) {
+			soonestID, soonest = id, entry.expiresAt
+		}
+	}
+	delete(c.entries, soonestID)
+}
+
+func (c *ttlCache[V]) currentGeneration() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
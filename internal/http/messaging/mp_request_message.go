@@ -1,61 +1,49 @@
 package messaging
 
 import (
+	"context"
 	"errors"
 	"log"
 
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type IMPRequestMessage interface {
-	SendCloneMPR(id uuid.UUID) (*string, error)
+	SendCloneMPR(ctx context.Context, id uuid.UUID) (*string, error)
+}
+
+// CloneMPRDestination is the queue of the recruitment service that clones an
+// approved MPR.
+const CloneMPRDestination = "julong_recruitment"
+
+type CloneMPRPayload struct {
+	MPRCloneID uuid.UUID `json:"mpr_clone_id"`
 }
 
 type MPRequestMessage struct {
-	Log *logrus.Logger
+	Log       *logrus.Logger
+	RPCClient *RPCClient
 }
 
-func NewMPRequestMessage(log *logrus.Logger) IMPRequestMessage {
+func NewMPRequestMessage(log *logrus.Logger, rpcClient *RPCClient) IMPRequestMessage {
 	return &MPRequestMessage{
-		Log: log,
+		Log:       log,
+		RPCClient: rpcClient,
 	}
 }
 
 func MPRequestMessageFactory(log *logrus.Logger) IMPRequestMessage {
-	return NewMPRequestMessage(log)
+	rpcClient := RPCClientFactory(log)
+	return NewMPRequestMessage(log, rpcClient)
 }
 
-func (m *MPRequestMessage) SendCloneMPR(id uuid.UUID) (*string, error) {
+func (m *MPRequestMessage) SendCloneMPR(ctx context.Context, id uuid.UUID) (*string, error) {
 	payload := map[string]interface{}{
 		"mpr_clone_id": id.String(),
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "clone_mp_request",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_recruitment",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, CloneMPRDestination, "clone_mp_request", payload)
 	if err != nil {
 		return nil, err
 	}
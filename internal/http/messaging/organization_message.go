@@ -1,64 +1,44 @@
 package messaging
 
 import (
+	"context"
 	"errors"
 	"log"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
 	orgResponse "github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type IOrganizationMessage interface {
-	SendFindOrganizationByIDMessage(request request.SendFindOrganizationByIDMessageRequest) (*orgResponse.SendFindOrganizationByIDMessageResponse, error)
-	SendFindOrganizationLocationByIDMessage(request request.SendFindOrganizationLocationByIDMessageRequest) (*orgResponse.SendFindOrganizationLocationByIDMessageResponse, error)
-	SendFindOrganizationStructureByIDMessage(request request.SendFindOrganizationStructureByIDMessageRequest) (*orgResponse.SendFindOrganizationStructureByIDMessageResponse, error)
-	SendFindOrganizationLocationsPaginatedMessage(page int, pageSize int, search string, includedIDs []string, isNull bool, orgID string) (*orgResponse.OrganizationLocationPaginatedResponse, error)
-	SendFindAllOrganizationMessage(includedIDs []string) (*[]orgResponse.OrganizationResponse, error)
-	SendFindAllOrganizationLocationsMessage(includedIDs []string) (*[]orgResponse.OrganizationLocationResponse, error)
-	SendFindAllOrgStructureChildrenIDsMessage(parentID string) (*[]string, error)
+	SendFindOrganizationByIDMessage(ctx context.Context, request request.SendFindOrganizationByIDMessageRequest) (*orgResponse.SendFindOrganizationByIDMessageResponse, error)
+	SendFindOrganizationLocationByIDMessage(ctx context.Context, request request.SendFindOrganizationLocationByIDMessageRequest) (*orgResponse.SendFindOrganizationLocationByIDMessageResponse, error)
+	SendFindOrganizationStructureByIDMessage(ctx context.Context, request request.SendFindOrganizationStructureByIDMessageRequest) (*orgResponse.SendFindOrganizationStructureByIDMessageResponse, error)
+	SendFindOrganizationLocationsPaginatedMessage(ctx context.Context, page int, pageSize int, search string, includedIDs []string, isNull bool, orgID string) (*orgResponse.OrganizationLocationPaginatedResponse, error)
+	SendFindAllOrganizationMessage(ctx context.Context, includedIDs []string) (*[]orgResponse.OrganizationResponse, error)
+	SendFindAllOrganizationLocationsMessage(ctx context.Context, includedIDs []string) (*[]orgResponse.OrganizationLocationResponse, error)
+	SendFindAllOrgStructureChildrenIDsMessage(ctx context.Context, parentID string) (*[]string, error)
 }
 
 type OrganizationMessage struct {
-	Log *logrus.Logger
+	Log       *logrus.Logger
+	RPCClient *RPCClient
 }
 
-func NewOrganizationMessage(log *logrus.Logger) IOrganizationMessage {
+func NewOrganizationMessage(log *logrus.Logger, rpcClient *RPCClient) IOrganizationMessage {
 	return &OrganizationMessage{
-		Log: log,
+		Log:       log,
+		RPCClient: rpcClient,
 	}
 }
 
-func (m *OrganizationMessage) SendFindOrganizationByIDMessage(req request.SendFindOrganizationByIDMessageRequest) (*orgResponse.SendFindOrganizationByIDMessageResponse, error) {
+func (m *OrganizationMessage) SendFindOrganizationByIDMessage(ctx context.Context, req request.SendFindOrganizationByIDMessageRequest) (*orgResponse.SendFindOrganizationByIDMessageResponse, error) {
 	payload := map[string]interface{}{
 		"organization_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_organization_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_organization_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -76,34 +56,12 @@ func (m *OrganizationMessage) SendFindOrganizationByIDMessage(req request.SendFi
 	}, nil
 }
 
-func (m *OrganizationMessage) SendFindAllOrganizationMessage(includedIDs []string) (*[]orgResponse.OrganizationResponse, error) {
+func (m *OrganizationMessage) SendFindAllOrganizationMessage(ctx context.Context, includedIDs []string) (*[]orgResponse.OrganizationResponse, error) {
 	payload := map[string]interface{}{
 		"included_ids": includedIDs,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_all_organization",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_all_organization", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -127,33 +85,12 @@ func (m *OrganizationMessage) SendFindAllOrganizationMessage(includedIDs []strin
 	return &orgs, nil
 }
 
-func (m *OrganizationMessage) SendFindOrganizationLocationByIDMessage(req request.SendFindOrganizationLocationByIDMessageRequest) (*orgResponse.SendFindOrganizationLocationByIDMessageResponse, error) {
+func (m *OrganizationMessage) SendFindOrganizationLocationByIDMessage(ctx context.Context, req request.SendFindOrganizationLocationByIDMessageRequest) (*orgResponse.SendFindOrganizationLocationByIDMessageResponse, error) {
 	payload := map[string]interface{}{
 		"organization_location_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_organization_location_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_organization_location_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -170,33 +107,12 @@ func (m *OrganizationMessage) SendFindOrganizationLocationByIDMessage(req reques
 	}, nil
 }
 
-func (m *OrganizationMessage) SendFindOrganizationStructureByIDMessage(req request.SendFindOrganizationStructureByIDMessageRequest) (*orgResponse.SendFindOrganizationStructureByIDMessageResponse, error) {
+func (m *OrganizationMessage) SendFindOrganizationStructureByIDMessage(ctx context.Context, req request.SendFindOrganizationStructureByIDMessageRequest) (*orgResponse.SendFindOrganizationStructureByIDMessageResponse, error) {
 	payload := map[string]interface{}{
 		"organization_structure_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_organization_structure_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_organization_structure_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -213,7 +129,7 @@ func (m *OrganizationMessage) SendFindOrganizationStructureByIDMessage(req reque
 	}, nil
 }
 
-func (m *OrganizationMessage) SendFindOrganizationLocationsPaginatedMessage(page int, pageSize int, search string, includedIDs []string, isNull bool, orgID string) (*orgResponse.OrganizationLocationPaginatedResponse, error) {
+func (m *OrganizationMessage) SendFindOrganizationLocationsPaginatedMessage(ctx context.Context, page int, pageSize int, search string, includedIDs []string, isNull bool, orgID string) (*orgResponse.OrganizationLocationPaginatedResponse, error) {
 	m.Log.Infof("Included IDs: %v", includedIDs)
 	payload := map[string]interface{}{
 		"page":            page,
@@ -224,28 +140,7 @@ func (m *OrganizationMessage) SendFindOrganizationLocationsPaginatedMessage(page
 		"organization_id": orgID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_organization_locations_paginated",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_organization_locations_paginated", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -275,34 +170,12 @@ func (m *OrganizationMessage) SendFindOrganizationLocationsPaginatedMessage(page
 	}, nil
 }
 
-func (m *OrganizationMessage) SendFindAllOrganizationLocationsMessage(includedIDs []string) (*[]orgResponse.OrganizationLocationResponse, error) {
+func (m *OrganizationMessage) SendFindAllOrganizationLocationsMessage(ctx context.Context, includedIDs []string) (*[]orgResponse.OrganizationLocationResponse, error) {
 	payload := map[string]interface{}{
 		"included_ids": includedIDs,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_all_organization_locations_by_ids",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_all_organization_locations_by_ids", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -329,34 +202,12 @@ func (m *OrganizationMessage) SendFindAllOrganizationLocationsMessage(includedID
 	return &orgLocs, nil
 }
 
-func (m *OrganizationMessage) SendFindAllOrgStructureChildrenIDsMessage(parentID string) (*[]string, error) {
+func (m *OrganizationMessage) SendFindAllOrgStructureChildrenIDsMessage(ctx context.Context, parentID string) (*[]string, error) {
 	payload := map[string]interface{}{
 		"parent_id": parentID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_all_org_structure_children_ids",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_all_org_structure_children_ids", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -376,5 +227,6 @@ func (m *OrganizationMessage) SendFindAllOrgStructureChildrenIDsMessage(parentID
 }
 
 func OrganizationMessageFactory(log *logrus.Logger) IOrganizationMessage {
-	return NewOrganizationMessage(log)
+	rpcClient := RPCClientFactory(log)
+	return NewCachedOrganizationMessage(NewOrganizationMessage(log, rpcClient), LookupCacheFactory(log))
 }
@@ -0,0 +1,149 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ReplyQueue is the queue this service consumes, other services reply to it.
+	ReplyQueue = "julong_manpower"
+
+	defaultRPCTimeout = 100 * time.Second
+)
+
+// ErrBrokerUnavailable is returned by calls made while there is no connection
+// to the broker, instead of waiting for a reply that cannot come.
+var ErrBrokerUnavailable = errors.New("rabbitmq broker is unavailable")
+
+// RPCClient sends request messages to other services over RabbitMQ and waits
+// for their replies. A reply is matched to its call by correlation id, which
+// is also the id in the message body for services that reply by body id.
+type RPCClient struct {
+	Log     *logrus.Logger
+	ReplyTo string
+	Timeout time.Duration
+	pending sync.Map // correlation id -> chan response.RabbitMQResponse
+	ready   atomic.Bool
+}
+
+func NewRPCClient(log *logrus.Logger, replyTo string, timeout time.Duration) *RPCClient {
+	return &RPCClient{
+		Log:     log,
+		ReplyTo: replyTo,
+		Timeout: timeout,
+	}
+}
+
+// SetReady records whether the broker connection is up, see Ready.
+func (c *RPCClient) SetReady(ready bool) {
+	c.ready.Store(ready)
+}
+
+// Ready reports whether calls can currently reach the broker.
+func (c *RPCClient) Ready() bool {
+	return c.ready.Load()
+}
+
+// Call publishes a messageType message with data to queue and waits for the
+// reply until ctx is done. A ctx without deadline waits for the client timeout.
+func (c *RPCClient) Call(ctx context.Context, queue string, messageType string, data map[string]interface{}) (*response.RabbitMQResponse, error) {
+	if !c.Ready() {
+		return nil, fmt.Errorf("[RPCClient.Call] %s: %w", messageType, ErrBrokerUnavailable)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	docMsg := request.RabbitMQRequest{
+		ID:          uuid.New().String(),
+		MessageType: messageType,
+		MessageData: data,
+		ReplyTo:     c.ReplyTo,
+	}
+
+	// buffered so a late reply never blocks the consumer
+	rchan := make(chan response.RabbitMQResponse, 1)
+	c.pending.Store(docMsg.ID, rchan)
+	defer c.pending.Delete(docMsg.ID)
+
+	c.Log.Infof("[RPCClient.Call] send %s to %s with correlation id %s", messageType, queue, docMsg.ID)
+
+	// buffered so the producer never blocks on a call that gave up
+	published := make(chan error, 1)
+
+	select {
+	case utils.Pchan <- utils.RabbitMsg{QueueName: queue, Message: docMsg, Result: published}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("[RPCClient.Call] publish %s: %w", messageType, ctx.Err())
+	}
+
+	// a message that was not published gets no reply, so return at once and
+	// drop the pending entry instead of holding it until the timeout
+	select {
+	case err := <-published:
+		if err != nil {
+			c.Log.Errorf("[RPCClient.Call] publish %s with correlation id %s: %v", messageType, docMsg.ID, err)
+			return nil, fmt.Errorf("[RPCClient.Call] publish %s: %w", messageType, err)
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("[RPCClient.Call] publish %s: %w", messageType, ctx.Err())
+	}
+
+	select {
+	case reply := <-rchan:
+		c.Log.Infof("[RPCClient.Call] received reply of %s with correlation id %s", messageType, docMsg.ID)
+		return &reply, nil
+	case <-ctx.Done():
+		c.Log.Errorf("[RPCClient.Call] no reply of %s with correlation id %s: %v", messageType, docMsg.ID, ctx.Err())
+		return nil, fmt.Errorf("[RPCClient.Call] wait reply of %s: %w", messageType, ctx.Err())
+	}
+}
+
+// Deliver hands a reply to the call waiting for it. It reports false when no
+// call waits for the correlation id anymore, e.g. because it timed out.
+func (c *RPCClient) Deliver(correlationID string, reply response.RabbitMQResponse) bool {
+	value, ok := c.pending.LoadAndDelete(correlationID)
+	if !ok {
+		return false
+	}
+
+	value.(chan response.RabbitMQResponse) <- reply
+	return true
+}
+
+var (
+	rpcClientInstance *RPCClient
+	rpcClientOnce     sync.Once
+)
+
+// RPCClientFactory returns the client shared by all messages and the consumer
+// that delivers their replies.
+func RPCClientFactory(log *logrus.Logger) *RPCClient {
+	rpcClientOnce.Do(func() {
+		viper := config.NewViper()
+
+		timeout := time.Duration(viper.GetInt("rabbitmq.rpc_timeout")) * time.Second
+		if timeout <= 0 {
+			timeout = defaultRPCTimeout
+		}
+
+		rpcClientInstance = NewRPCClient(log, ReplyQueue, timeout)
+	})
+
+	return rpcClientInstance
+}
@@ -1,59 +1,39 @@
 package messaging
 
 import (
+	"context"
 	"errors"
 	"log"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type IUserMessage interface {
-	SendFindUserByIDMessage(request request.SendFindUserByIDMessageRequest) (*response.SendFindUserByIDResponse, error)
-	SendGetUserMe(request request.SendFindUserByIDMessageRequest) (*response.SendGetUserMeResponse, error)
-	SendGetUserIDsByPermissionNames(permissionNames []string) ([]string, error)
+	SendFindUserByIDMessage(ctx context.Context, request request.SendFindUserByIDMessageRequest) (*response.SendFindUserByIDResponse, error)
+	SendGetUserMe(ctx context.Context, request request.SendFindUserByIDMessageRequest) (*response.SendGetUserMeResponse, error)
+	SendGetUserIDsByPermissionNames(ctx context.Context, permissionNames []string) ([]string, error)
 }
 
 type UserMessage struct {
-	Log *logrus.Logger
+	Log       *logrus.Logger
+	RPCClient *RPCClient
 }
 
-func NewUserMessage(log *logrus.Logger) IUserMessage {
+func NewUserMessage(log *logrus.Logger, rpcClient *RPCClient) IUserMessage {
 	return &UserMessage{
-		Log: log,
+		Log:       log,
+		RPCClient: rpcClient,
 	}
 }
 
-func (m *UserMessage) SendFindUserByIDMessage(req request.SendFindUserByIDMessageRequest) (*response.SendFindUserByIDResponse, error) {
+func (m *UserMessage) SendFindUserByIDMessage(ctx context.Context, req request.SendFindUserByIDMessageRequest) (*response.SendFindUserByIDResponse, error) {
 	payload := map[string]interface{}{
 		"user_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "find_user_by_id",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "find_user_by_id", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -70,33 +50,12 @@ func (m *UserMessage) SendFindUserByIDMessage(req request.SendFindUserByIDMessag
 	}, nil
 }
 
-func (m *UserMessage) SendGetUserMe(req request.SendFindUserByIDMessageRequest) (*response.SendGetUserMeResponse, error) {
+func (m *UserMessage) SendGetUserMe(ctx context.Context, req request.SendFindUserByIDMessageRequest) (*response.SendGetUserMeResponse, error) {
 	payload := map[string]interface{}{
 		"user_id": req.ID,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "get_user_me",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "get_user_me", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -112,33 +71,12 @@ func (m *UserMessage) SendGetUserMe(req request.SendFindUserByIDMessageRequest)
 	}, nil
 }
 
-func (m *UserMessage) SendGetUserIDsByPermissionNames(permissionNames []string) ([]string, error) {
+func (m *UserMessage) SendGetUserIDsByPermissionNames(ctx context.Context, permissionNames []string) ([]string, error) {
 	payload := map[string]interface{}{
 		"permission_names": permissionNames,
 	}
 
-	docMsg := &request.RabbitMQRequest{
-		ID:          uuid.New().String(),
-		MessageType: "get_user_ids_by_permission_names",
-		MessageData: payload,
-		ReplyTo:     "julong_manpower",
-	}
-
-	log.Printf("INFO: document message: %v", docMsg)
-
-	// create channel and add to rchans with uid
-	rchan := make(chan response.RabbitMQResponse)
-	utils.Rchans[docMsg.ID] = rchan
-
-	// publish rabbit message
-	msg := utils.RabbitMsg{
-		QueueName: "julong_sso",
-		Message:   *docMsg,
-	}
-	utils.Pchan <- msg
-
-	// wait for reply
-	resp, err := waitReply(docMsg.ID, rchan)
+	resp, err := m.RPCClient.Call(ctx, "julong_sso", "get_user_ids_by_permission_names", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -158,5 +96,6 @@ func (m *UserMessage) SendGetUserIDsByPermissionNames(permissionNames []string)
 }
 
 func UserMessageFactory(log *logrus.Logger) IUserMessage {
-	return NewUserMessage(log)
+	rpcClient := RPCClientFactory(log)
+	return NewUserMessage(log, rpcClient)
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/audit"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/gin-gonic/gin"
@@ -45,6 +46,12 @@ func NewAuth(viper *viper.Viper) gin.HandlerFunc {
 
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 			c.Set("auth", claims)
+
+			// the changes made by the request are audited under the user of the token
+			actor := audit.Actor{}
+			actor.ID, _ = claims["id"].(string)
+			actor.Name, _ = claims["name"].(string)
+			c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
@@ -68,7 +75,7 @@ func GetUser(c *gin.Context, log *logrus.Logger) (map[string]interface{}, error)
 
 	message := messaging.UserMessageFactory(log)
 
-	messageResponse, err := message.SendGetUserMe(request.SendFindUserByIDMessageRequest{
+	messageResponse, err := message.SendGetUserMe(c.Request.Context(), request.SendFindUserByIDMessageRequest{
 		ID: claims["id"].(string),
 	})
 
@@ -0,0 +1,349 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// IPermissionMiddleware authorizes the routes behind NewAuth from the token or get_user_me.
+type IPermissionMiddleware interface {
+	// RequirePermission lets the request through when the user has any of the permissions.
+	RequirePermission(permissionNames ...string) gin.HandlerFunc
+	// OrganizationScope limits the request to the organization of the user.
+	OrganizationScope() gin.HandlerFunc
+}
+
+// UserGrant is what the user is allowed to do.
+type UserGrant struct {
+	UserID         string
+	OrganizationID string
+	Roles          []string
+	Permissions    map[string]bool
+}
+
+func (g *UserGrant) HasPermission(permissionNames ...string) bool {
+	for _, permissionName := range permissionNames {
+		if g.Permissions[permissionName] {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrGrantUnavailable is returned when the SSO could not be asked what the user may do.
+var ErrGrantUnavailable = errors.New("the permissions of the user could not be read, try again later")
+
+type cachedGrant struct {
+	grant     *UserGrant
+	expiresAt time.Time
+}
+
+// maxCachedGrants caps the cached grants between prunes.
+const maxCachedGrants = 10000
+
+type PermissionMiddleware struct {
+	Viper       *viper.Viper
+	Log         *logrus.Logger
+	UserMessage messaging.IUserMessage
+
+	mu       sync.Mutex
+	grants   map[string]cachedGrant
+	prunedAt time.Time
+}
+
+func NewPermissionMiddleware(viper *viper.Viper, log *logrus.Logger, userMessage messaging.IUserMessage) IPermissionMiddleware {
+	return &PermissionMiddleware{
+		Viper:       viper,
+		Log:         log,
+		UserMessage: userMessage,
+		grants:      make(map[string]cachedGrant),
+	}
+}
+
+const (
+	grantContextKey        = "grant"
+	organizationContextKey = "organization_scope"
+)
+
+func (m *PermissionMiddleware) RequirePermission(permissionNames ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		grant, err := m.grant(c)
+		if err != nil {
+			m.Log.Errorf("[PermissionMiddleware.RequirePermission] " + err.Error())
+			grantErrorResponse(c, err)
+			return
+		}
+
+		if !grant.HasPermission(permissionNames...) {
+			m.Log.Warnf("[PermissionMiddleware.RequirePermission] user %s lacks %v for %s %s", grant.UserID, permissionNames, c.Request.Method, c.FullPath())
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to do this"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (m *PermissionMiddleware) OrganizationScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		grant, err := m.grant(c)
+		if err != nil {
+			m.Log.Errorf("[PermissionMiddleware.OrganizationScope] " + err.Error())
+			grantErrorResponse(c, err)
+			return
+		}
+
+		if grant.HasPermission(m.Viper.GetStringSlice("auth.all_organizations_permissions")...) {
+			c.Set(organizationContextKey, "")
+			c.Next()
+			return
+		}
+
+		organizationID := grant.OrganizationID
+		if organizationID == "" {
+			// the token gave the permissions but not the organization
+			userGrant, err := m.userGrant(c.Request.Context(), grant.UserID)
+			if err != nil {
+				m.Log.Errorf("[PermissionMiddleware.OrganizationScope] " + err.Error())
+				grantErrorResponse(c, err)
+				return
+			}
+			organizationID = userGrant.OrganizationID
+		}
+
+		if organizationID == "" {
+			m.Log.Warnf("[PermissionMiddleware.OrganizationScope] user %s has no organization", grant.UserID)
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not belong to any organization"})
+			c.Abort()
+			return
+		}
+
+		c.Set(organizationContextKey, organizationID)
+		c.Next()
+	}
+}
+
+// grantErrorResponse aborts with 503 when the SSO did not answer, 401 otherwise.
+func grantErrorResponse(c *gin.Context, err error) {
+	status := http.StatusUnauthorized
+	if errors.Is(err, ErrGrantUnavailable) {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+	c.Abort()
+}
+
+// GetGrant is the grant of the user once a permission middleware has run.
+func GetGrant(c *gin.Context) (*UserGrant, bool) {
+	grant, ok := c.Get(grantContextKey)
+	if !ok {
+		return nil, false
+	}
+	userGrant, ok := grant.(*UserGrant)
+	return userGrant, ok
+}
+
+// ScopedOrganizationID is the organization the request is limited to, empty for every one.
+func ScopedOrganizationID(c *gin.Context) (string, bool) {
+	organizationID, ok := c.Get(organizationContextKey)
+	if !ok {
+		return "", false
+	}
+	scopedOrgID, ok := organizationID.(string)
+	return scopedOrgID, ok
+}
+
+// grant is the grant of the user of the request, looked up once per request.
+func (m *PermissionMiddleware) grant(c *gin.Context) (*UserGrant, error) {
+	if grant, ok := GetGrant(c); ok {
+		return grant, nil
+	}
+
+	auth, exists := c.Get("auth")
+	if !exists {
+		return nil, errors.New("auth key not found in context")
+	}
+
+	claims, ok := auth.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid auth claims type")
+	}
+
+	userID, _ := claims["id"].(string)
+	if userID == "" {
+		return nil, errors.New("user id not found in token")
+	}
+
+	grant, ok := grantFromClaims(userID, claims)
+	if !ok {
+		var err error
+		grant, err = m.userGrant(c.Request.Context(), userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.Set(grantContextKey, grant)
+	return grant, nil
+}
+
+// grantFromClaims reads the grant from the token, false without a permissions claim.
+func grantFromClaims(userID string, claims jwt.MapClaims) (*UserGrant, bool) {
+	permissions, ok := claims["permissions"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	grant := &UserGrant{
+		UserID:      userID,
+		Permissions: make(map[string]bool),
+	}
+	grant.OrganizationID, _ = claims["organization_id"].(string)
+	for _, permission := range permissions {
+		if name := nameOf(permission); name != "" {
+			grant.Permissions[name] = true
+		}
+	}
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if name := nameOf(role); name != "" {
+				grant.Roles = append(grant.Roles, name)
+			}
+		}
+	}
+
+	return grant, true
+}
+
+// userGrant is the grant of the user from get_user_me, cached.
+func (m *PermissionMiddleware) userGrant(ctx context.Context, userID string) (*UserGrant, error) {
+	m.mu.Lock()
+	cached, ok := m.grants[userID]
+	if ok && !time.Now().Before(cached.expiresAt) {
+		delete(m.grants, userID)
+		ok = false
+	}
+	m.mu.Unlock()
+	if ok {
+		return cached.grant, nil
+	}
+
+	messageResponse, err := m.UserMessage.SendGetUserMe(ctx, request.SendFindUserByIDMessageRequest{
+		ID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGrantUnavailable, err)
+	}
+	if messageResponse.User == nil {
+		return nil, errors.New("User not found")
+	}
+
+	grant := grantFromUser(userID, messageResponse.User)
+
+	ttl := m.Viper.GetDuration("auth.permission_cache_ttl")
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	now := time.Now()
+	m.mu.Lock()
+	if now.Sub(m.prunedAt) >= ttl || len(m.grants) >= maxCachedGrants {
+		m.pruneGrants(now)
+	}
+	if _, ok := m.grants[userID]; !ok && len(m.grants) >= maxCachedGrants {
+		m.evictSoonestGrant()
+	}
+	m.grants[userID] = cachedGrant{grant: grant, expiresAt: now.Add(ttl)}
+	m.mu.Unlock()
+
+	return grant, nil
+}
+
+// pruneGrants drops the expired grants, with mu held.
+func (m *PermissionMiddleware) pruneGrants(now time.Time) {
+	for userID, cached := range m.grants {
+		if !now.Before(cached.expiresAt) {
+			delete(m.grants, userID)
+		}
+	}
+	m.prunedAt = now
+}
+
+// evictSoonestGrant drops the grant closest to expiring, with mu held.
+func (m *PermissionMiddleware) evictSoonestGrant() {
+	var soonestID string
+	var soonest time.Time
+	for userID, cached := range m.grants {
+		if soonestID == "" || cached.expiresAt.Before(soonest) {
+			soonestID, soonest = userID, cached.expiresAt
+		}
+	}
+	delete(m.grants, soonestID)
+}
+
+// grantFromUser reads the grant from the get_user_me response.
+func grantFromUser(userID string, user map[string]interface{}) *UserGrant {
+	grant := &UserGrant{
+		UserID:      userID,
+		Permissions: make(map[string]bool),
+	}
+
+	userData, ok := user["user"].(map[string]interface{})
+	if !ok {
+		return grant
+	}
+
+	addPermissions := func(permissions interface{}) {
+		list, _ := permissions.([]interface{})
+		for _, permission := range list {
+			if name := nameOf(permission); name != "" {
+				grant.Permissions[name] = true
+			}
+		}
+	}
+
+	addPermissions(userData["permissions"])
+	roles, _ := userData["roles"].([]interface{})
+	for _, role := range roles {
+		if name := nameOf(role); name != "" {
+			grant.Roles = append(grant.Roles, name)
+		}
+		if roleData, ok := role.(map[string]interface{}); ok {
+			addPermissions(roleData["permissions"])
+		}
+	}
+
+	if employee, ok := userData["employee"].(map[string]interface{}); ok {
+		grant.OrganizationID, _ = employee["organization_id"].(string)
+	}
+
+	return grant
+}
+
+// nameOf is the name of a permission or role, given as a string or an object.
+func nameOf(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		name, _ := v["name"].(string)
+		return name
+	}
+	return ""
+}
+
+func PermissionMiddlewareFactory(viper *viper.Viper, log *logrus.Logger) IPermissionMiddleware {
+	userMessage := messaging.UserMessageFactory(log)
+	return NewPermissionMiddleware(viper, log, userMessage)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// NewRequestID gives every request an id, the one of the X-Request-ID header
+// when the caller sent one. It is sent back in the same header and kept in the
+// context of the request, so the audit log can tell which request made a
+// change.
+func NewRequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = uuid.New().String()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(audit.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type FindAllPaginatedApprovalChainRequest struct {
+	Page           int    `json:"page"`
+	PageSize       int    `json:"page_size"`
+	Search         string `json:"search"`
+	OrganizationID string `json:"organization_id"`
+	DocumentType   string `json:"document_type"`
+}
+
+type FindByIdApprovalChainRequest struct {
+	ID string `json:"id" validate:"required,uuid"`
+}
+
+type ApprovalChainStepRequest struct {
+	Sequence     int                               `json:"sequence" validate:"required,min=1"`
+	Level        string                            `json:"level" validate:"required"`
+	ApproverID   *uuid.UUID                        `json:"approver_id" validate:"omitempty"`
+	ApproverName string                            `json:"approver_name" validate:"omitempty"`
+	Condition    entity.ApprovalChainStepCondition `json:"condition" validate:"omitempty,ApprovalChainStepConditionValidation"`
+}
+
+type CreateApprovalChainRequest struct {
+	OrganizationID uuid.UUID                        `json:"organization_id" validate:"required"`
+	DocumentType   entity.ApprovalChainDocumentType `json:"document_type" validate:"required,ApprovalChainDocumentTypeValidation"`
+	Name           string                           `json:"name" validate:"required,max=255"`
+	IsActive       *bool                            `json:"is_active" validate:"omitempty"`
+	Steps          []ApprovalChainStepRequest       `json:"steps" validate:"required,min=1,dive"`
+}
+
+type UpdateApprovalChainRequest struct {
+	ID             uuid.UUID                        `json:"id" validate:"required"`
+	OrganizationID uuid.UUID                        `json:"organization_id" validate:"required"`
+	DocumentType   entity.ApprovalChainDocumentType `json:"document_type" validate:"required,ApprovalChainDocumentTypeValidation"`
+	Name           string                           `json:"name" validate:"required,max=255"`
+	IsActive       *bool                            `json:"is_active" validate:"omitempty"`
+	Steps          []ApprovalChainStepRequest       `json:"steps" validate:"required,min=1,dive"`
+}
+
+type DeleteApprovalChainRequest struct {
+	ID string `json:"id" validate:"required,uuid"`
+}
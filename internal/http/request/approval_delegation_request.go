@@ -0,0 +1,55 @@
+package request
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type FindAllPaginatedApprovalDelegationRequest struct {
+	Page        int    `json:"page"`
+	PageSize    int    `json:"page_size"`
+	Search      string `json:"search"`
+	DelegatorID string `json:"delegator_id"`
+	DelegateID  string `json:"delegate_id"`
+	Scope       string `json:"scope"`
+	ActiveOn    string `json:"active_on"`
+}
+
+type FindByIdApprovalDelegationRequest struct {
+	ID string `json:"id" validate:"required,uuid"`
+}
+
+type CreateApprovalDelegationRequest struct {
+	DelegatorID uuid.UUID                      `json:"delegator_id" validate:"omitempty"`
+	DelegateID  uuid.UUID                      `json:"delegate_id" validate:"required"`
+	Scope       entity.ApprovalDelegationScope `json:"scope" validate:"omitempty,ApprovalDelegationScopeValidation"`
+	StartDate   string                         `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate     string                         `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Reason      string                         `json:"reason" validate:"omitempty"`
+	// Actor is the user making the change, set by the handler.
+	Actor DelegationActor `json:"-"`
+}
+
+type UpdateApprovalDelegationRequest struct {
+	ID          uuid.UUID                      `json:"id" validate:"required"`
+	DelegatorID uuid.UUID                      `json:"delegator_id" validate:"omitempty"`
+	DelegateID  uuid.UUID                      `json:"delegate_id" validate:"required"`
+	Scope       entity.ApprovalDelegationScope `json:"scope" validate:"omitempty,ApprovalDelegationScopeValidation"`
+	StartDate   string                         `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate     string                         `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Reason      string                         `json:"reason" validate:"omitempty"`
+	// Actor is the user making the change, set by the handler.
+	Actor DelegationActor `json:"-"`
+}
+
+type DeleteApprovalDelegationRequest struct {
+	ID    string          `json:"id" validate:"required,uuid"`
+	Actor DelegationActor `json:"-"`
+}
+
+// DelegationActor is the employee changing a delegation. Without ManageAll
+// they may only delegate their own approvals.
+type DelegationActor struct {
+	EmployeeID uuid.UUID
+	ManageAll  bool
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type FindAllPaginatedApprovalSLARequest struct {
+	Page         int    `json:"page"`
+	PageSize     int    `json:"page_size"`
+	DocumentType string `json:"document_type"`
+	Level        string `json:"level"`
+}
+
+type FindByIdApprovalSLARequest struct {
+	ID string `json:"id" validate:"required,uuid"`
+}
+
+type CreateApprovalSLARequest struct {
+	DocumentType      entity.ApprovalChainDocumentType `json:"document_type" validate:"required,ApprovalChainDocumentTypeValidation"`
+	Level             string                           `json:"level" validate:"omitempty"`
+	ReminderAfterDays int                              `json:"reminder_after_days" validate:"required,min=1"`
+	EscalateAfterDays int                              `json:"escalate_after_days" validate:"required,gtfield=ReminderAfterDays"`
+	EscalateToLevel   string                           `json:"escalate_to_level" validate:"omitempty"`
+	BackupApproverID  *uuid.UUID                       `json:"backup_approver_id" validate:"omitempty"`
+	PermissionName    string                           `json:"permission_name" validate:"omitempty"`
+	IsActive          *bool                            `json:"is_active" validate:"omitempty"`
+}
+
+type UpdateApprovalSLARequest struct {
+	ID                uuid.UUID                        `json:"id" validate:"required"`
+	DocumentType      entity.ApprovalChainDocumentType `json:"document_type" validate:"required,ApprovalChainDocumentTypeValidation"`
+	Level             string                           `json:"level" validate:"omitempty"`
+	ReminderAfterDays int                              `json:"reminder_after_days" validate:"required,min=1"`
+	EscalateAfterDays int                              `json:"escalate_after_days" validate:"required,gtfield=ReminderAfterDays"`
+	EscalateToLevel   string                           `json:"escalate_to_level" validate:"omitempty"`
+	BackupApproverID  *uuid.UUID                       `json:"backup_approver_id" validate:"omitempty"`
+	PermissionName    string                           `json:"permission_name" validate:"omitempty"`
+	IsActive          *bool                            `json:"is_active" validate:"omitempty"`
+}
+
+type DeleteApprovalSLARequest struct {
+	ID string `json:"id" validate:"required,uuid"`
+}
@@ -0,0 +1,12 @@
+package request
+
+type FindAllPaginatedAuditLogRequest struct {
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+	Entity    string `json:"entity" validate:"required"`
+	EntityID  string `json:"id" validate:"omitempty"`
+	Field     string `json:"field" validate:"omitempty"`
+	Action    string `json:"action" validate:"omitempty,oneof=CREATE UPDATE DELETE"`
+	ActorID   string `json:"actor_id" validate:"omitempty"`
+	RequestID string `json:"request_id" validate:"omitempty"`
+}
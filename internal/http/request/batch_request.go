@@ -1,12 +1,16 @@
 package request
 
-import "github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
 
 type CreateBatchHeaderAndLinesRequest struct {
 	DocumentNumber string                           `json:"document_number" validate:"omitempty,max=255"` // max length 255
 	Status         entity.BatchHeaderApprovalStatus `json:"status" validate:"omitempty,BatchHeaderApprovalStatusValidation"`
 	ApproverID     string                           `json:"approver_id" validate:"required"`
 	ApproverName   string                           `json:"approver_name" validate:"required"`
+	OnBehalfOfID   *uuid.UUID                       `json:"on_behalf_of_id" validate:"omitempty"`
 	BatchLines     []struct {
 		MPPlanningHeaderID string `json:"mp_planning_header_id" validate:"required"`
 		// OrganizationID         string `json:"organization_id" validate:"required"`
@@ -19,7 +23,9 @@ type CreateBatchHeaderAndLinesRequest struct {
 type UpdateStatusBatchHeaderRequest struct {
 	ID           string                           `json:"id" validate:"required"`
 	Status       entity.BatchHeaderApprovalStatus `json:"status" validate:"required,BatchHeaderApprovalStatusValidation"`
-	ApprovedBy   string                           `json:"approved_by" validate:"required"`
+	ApprovedBy   string                           `json:"approved_by" validate:"required"` // must be the logged-in employee
 	ApproverName string                           `json:"approver_name" validate:"required"`
 	ApproverType entity.BatchHeaderApproverType   `json:"approver_type" validate:"omitempty,BatchHeaderApproverTypeValidation"`
+	OnBehalfOfID *uuid.UUID                       `json:"on_behalf_of_id" validate:"omitempty"`
+	Version      int                              `json:"version" validate:"omitempty"` // the If-Match header wins over it
 }
@@ -0,0 +1,7 @@
+package request
+
+type FindAllByLinePaginatedBudgetLedgerRequest struct {
+	MPPlanningLineID string `json:"mp_planning_line_id" validate:"required,uuid"`
+	Page             int    `json:"page"`
+	PageSize         int    `json:"page_size"`
+}
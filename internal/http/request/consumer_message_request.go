@@ -0,0 +1,21 @@
+package request
+
+type FindJobPlafonByJobIDMessageRequest struct {
+	JobID string `json:"job_id" validate:"required,uuid"`
+}
+
+type FindMPRequestHeaderByIDMessageRequest struct {
+	MPRequestHeaderID string `json:"mp_request_header_id" validate:"required,uuid"`
+}
+
+type FindMPRequestHeadersByMajorsMessageRequest struct {
+	Majors          []string `json:"majors" validate:"required"`
+	EducationLevels []string `json:"education_levels" validate:"required,min=1"`
+}
+
+// PortalDataChangedMessageRequest is the change event of the portal service.
+// Empty IDs means every record of the entity changed.
+type PortalDataChangedMessageRequest struct {
+	Entity string   `json:"entity" validate:"required,oneof=organization organization_location organization_structure job job_level employee grade"`
+	IDs    []string `json:"ids"`
+}
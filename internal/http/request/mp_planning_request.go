@@ -17,6 +17,18 @@ type FindAllHeadersPaginatedMPPlanningRequest struct {
 	RequestorID   string `json:"requestor_id"`
 }
 
+// ExportMPPlanningHeadersRequest takes the filters of the header list. Every
+// period is exported when MPPPeriodID is empty.
+type ExportMPPlanningHeadersRequest struct {
+	Search        string `json:"search"`
+	ApproverType  string `json:"approver_type"`
+	OrgLocationID string `json:"org_location_id"`
+	OrgID         string `json:"org_id"`
+	Status        string `json:"status"`
+	RequestorID   string `json:"requestor_id"`
+	MPPPeriodID   string `json:"mpp_period_id"`
+}
+
 type MPPlanningHeaderRequest struct {
 	ID                     string                 `json:"id" validate:"omitempty"`
 	DocumentNumber         string                 `json:"document_number" validate:"omitempty"`
@@ -39,13 +51,15 @@ type ManpowerAttachmentRequest struct {
 }
 
 type UpdateStatusMPPlanningHeaderRequest struct {
-	ID          string                                `json:"id" validate:"required"`
-	Status      entity.MPPlaningStatus                `json:"status" validate:"required,MPPlaningStatusValidation"`
-	Notes       string                                `json:"notes" validate:"omitempty"`
-	Level       entity.MPPlanningApprovalHistoryLevel `json:"level" validate:"required,MPPlanningApprovalHistoryLevelValidation"`
-	Attachments []ManpowerAttachmentRequest           `json:"attachments" validate:"omitempty,dive"`
-	ApprovedBy  string                                `json:"approved_by" validate:"required"`
-	ApproverID  uuid.UUID                             `json:"approver_id" validate:"required"`
+	ID           string                                `json:"id" validate:"required"`
+	Status       entity.MPPlaningStatus                `json:"status" validate:"required,MPPlaningStatusValidation"`
+	Notes        string                                `json:"notes" validate:"omitempty"`
+	Level        entity.MPPlanningApprovalHistoryLevel `json:"level" validate:"required,MPPlanningApprovalHistoryLevelValidation"`
+	Attachments  []ManpowerAttachmentRequest           `json:"attachments" validate:"omitempty,dive"`
+	ApprovedBy   string                                `json:"approved_by" validate:"required"`
+	ApproverID   uuid.UUID                             `json:"approver_id" validate:"required"` // must be the logged-in employee
+	OnBehalfOfID *uuid.UUID                            `json:"on_behalf_of_id" validate:"omitempty"`
+	Version      int                                   `json:"version" validate:"omitempty"` // the If-Match header wins over it
 	// ApproverName string                      `json:"approved_by_name" validate:"omitempty"`
 }
 
@@ -65,7 +79,7 @@ type CreateHeaderMPPlanningRequest struct {
 	EmpOrganizationID      uuid.UUID                   `json:"emp_organization_id" validate:"required"`
 	OrganizationLocationID uuid.UUID                   `json:"organization_location_id" validate:"required"` // organization_location_id
 	JobID                  uuid.UUID                   `json:"job_id" validate:"required"`                   // job_id
-	DocumentNumber         string                      `json:"document_number" validate:"required"`
+	DocumentNumber         string                      `json:"document_number" validate:"omitempty"`         // given by the server when the planning is created
 	DocumentDate           string                      `json:"document_date" validate:"required,datetime=2006-01-02,date_today_or_later"`
 	Notes                  string                      `json:"notes" validate:"omitempty"`
 	TotalRecruit           float64                     `json:"total_recruit" validate:"omitempty"`
@@ -84,8 +98,8 @@ type UpdateHeaderMPPlanningRequest struct {
 	OrganizationID         uuid.UUID                   `json:"organization_id" validate:"required"`
 	EmpOrganizationID      uuid.UUID                   `json:"emp_organization_id" validate:"required"`
 	OrganizationLocationID uuid.UUID                   `json:"organization_location_id" validate:"required"`
-	JobID                  uuid.UUID                   `json:"job_id" validate:"required"` // job_id
-	DocumentNumber         string                      `json:"document_number" validate:"required"`
+	JobID                  uuid.UUID                   `json:"job_id" validate:"required"`           // job_id
+	DocumentNumber         string                      `json:"document_number" validate:"omitempty"` // kept from the planning, it cannot be changed
 	DocumentDate           string                      `json:"document_date" validate:"required,datetime=2006-01-02"`
 	Notes                  string                      `json:"notes" validate:"omitempty"`
 	TotalRecruit           float64                     `json:"total_recruit" validate:"omitempty"`
@@ -96,6 +110,7 @@ type UpdateHeaderMPPlanningRequest struct {
 	RequestorID            uuid.UUID                   `json:"requestor_id" validate:"required"`
 	NotesAttach            string                      `json:"notes_attach" validate:"omitempty"`
 	Attachments            []ManpowerAttachmentRequest `json:"attachments" validate:"omitempty,dive"`
+	Version                int                         `json:"version" validate:"omitempty"` // the If-Match header wins over it
 }
 
 type DeleteHeaderMPPlanningRequest struct {
@@ -125,6 +140,7 @@ type CreateLineMPPlanningLineRequest struct {
 	Total                  int       `json:"total" validate:"required"`
 	RecruitPH              int       `json:"recruit_ph" validate:"required"`
 	RecruitMT              int       `json:"recruit_mt" validate:"required"`
+	PlafonOverrideReason   string    `json:"plafon_override_reason" validate:"omitempty"` // asks for an override when the line goes over the job plafon
 	// RemainingBalancePH     int       `json:"remaining_balance_ph" validate:"required"`
 	// RemainingBalanceMT     int       `json:"remaining_balance_mt" validate:"required"`
 }
@@ -134,24 +150,48 @@ type FindHeaderByMPPPeriodIdMPPlanningRequest struct {
 }
 
 type CreateOrUpdateBatchLineMPPlanningLinesRequest struct {
-	MPPlanningHeaderID uuid.UUID `json:"mp_planning_header_id" validate:"required"`
-	MPPlanningLines    []struct {
-		ID                     uuid.UUID `json:"id" validate:"omitempty"`
-		OrganizationLocationID uuid.UUID `json:"organization_location_id" validate:"omitempty"` // organization_location_id
-		JobLevelID             uuid.UUID `json:"job_level_id" validate:"required"`
-		JobID                  uuid.UUID `json:"job_id" validate:"required"`
-		Existing               int       `json:"existing" validate:"required"`
-		Recruit                int       `json:"recruit" validate:"required"`
-		SuggestedRecruit       int       `json:"suggested_recruit" validate:"required"`
-		Promotion              int       `json:"promotion" validate:"required"`
-		Total                  int       `json:"total" validate:"required"`
-		RecruitPH              int       `json:"recruit_ph" validate:"required"`
-		RecruitMT              int       `json:"recruit_mt" validate:"required"`
-		IsCreate               bool      `json:"is_create" validate:"omitempty"`
-		// RemainingBalancePH     int       `json:"remaining_balance_ph" validate:"required"`
-		// RemainingBalanceMT     int       `json:"remaining_balance_mt" validate:"required"`
-	} `json:"mp_planning_lines" validate:"required"`
-	DeletedLineIDs []string `json:"deleted_line_ids" validate:"omitempty,dive"`
+	MPPlanningHeaderID uuid.UUID                        `json:"mp_planning_header_id" validate:"required"`
+	MPPlanningLines    []BatchLineMPPlanningLineRequest `json:"mp_planning_lines" validate:"required"`
+	DeletedLineIDs     []string                         `json:"deleted_line_ids" validate:"omitempty,dive"`
+	Version            int                              `json:"version" validate:"omitempty"` // version of the header, the If-Match header wins over it
+}
+
+type BatchLineMPPlanningLineRequest struct {
+	ID                     uuid.UUID `json:"id" validate:"omitempty"`
+	OrganizationLocationID uuid.UUID `json:"organization_location_id" validate:"omitempty"` // organization_location_id
+	JobLevelID             uuid.UUID `json:"job_level_id" validate:"required"`
+	JobID                  uuid.UUID `json:"job_id" validate:"required"`
+	Existing               int       `json:"existing" validate:"required"`
+	Recruit                int       `json:"recruit" validate:"required"`
+	SuggestedRecruit       int       `json:"suggested_recruit" validate:"required"`
+	Promotion              int       `json:"promotion" validate:"required"`
+	Total                  int       `json:"total" validate:"required"`
+	RecruitPH              int       `json:"recruit_ph" validate:"required"`
+	RecruitMT              int       `json:"recruit_mt" validate:"required"`
+	IsCreate               bool      `json:"is_create" validate:"omitempty"`
+	PlafonOverrideReason   string    `json:"plafon_override_reason" validate:"omitempty"`
+	// RemainingBalancePH     int       `json:"remaining_balance_ph" validate:"required"`
+	// RemainingBalanceMT     int       `json:"remaining_balance_mt" validate:"required"`
+}
+
+// ImportMPPlanningLinesRequest is the form of a planning line upload; the
+// spreadsheet itself is the file field.
+type ImportMPPlanningLinesRequest struct {
+	MPPlanningHeaderID string `form:"mp_planning_header_id" validate:"required,uuid"`
+	ImportedBy         string `form:"-" validate:"omitempty"`
+}
+
+type ConfirmMPPlanningLineImportRequest struct {
+	ID                   string `json:"id" validate:"required,uuid"`
+	PlafonOverrideReason string `json:"plafon_override_reason" validate:"omitempty"` // asks for an override for the lines that go over the job plafon
+}
+
+type CopyMPPlanningFromPeriodRequest struct {
+	SourceMPPPeriodID uuid.UUID  `json:"source_mpp_period_id" validate:"required"`
+	TargetMPPPeriodID uuid.UUID  `json:"target_mpp_period_id" validate:"required"`
+	OrganizationID    *uuid.UUID `json:"organization_id" validate:"omitempty"` // only the plannings of this organization
+	CarryOverBalance  bool       `json:"carry_over_balance"`                   // plans the recruits the source did not fill again
+	DryRun            bool       `json:"dry_run"`                              // reports what would be copied without saving it
 }
 
 type UpdateLineMPPlanningLineRequest struct {
@@ -167,6 +207,8 @@ type UpdateLineMPPlanningLineRequest struct {
 	Total                  int       `json:"total" validate:"required"`
 	RecruitPH              int       `json:"recruit_ph" validate:"required"`
 	RecruitMT              int       `json:"recruit_mt" validate:"required"`
+	PlafonOverrideReason   string    `json:"plafon_override_reason" validate:"omitempty"` // asks for an override when the line goes over the job plafon
+	Version                int       `json:"version" validate:"omitempty"`                // the If-Match header wins over it
 	// RemainingBalancePH     int       `json:"remaining_balance_ph" validate:"required"`
 	// RemainingBalanceMT     int       `json:"remaining_balance_mt" validate:"required"`
 }
@@ -16,7 +16,7 @@ type CreateMPRequestHeaderRequest struct {
 	RequestCategoryID          uuid.UUID                  `json:"request_category_id" validate:"required,uuid"`
 	ExpectedDate               string                     `json:"expected_date" validate:"required"`
 	Experiences                string                     `json:"experiences" validate:"required"`
-	DocumentNumber             string                     `json:"document_number" validate:"required"`
+	DocumentNumber             string                     `json:"document_number" validate:"omitempty"` // given by the server when the request is created
 	DocumentDate               string                     `json:"document_date" validate:"required,datetime=2006-01-02"`
 	MaleNeeds                  int                        `json:"male_needs" validate:"omitempty"`
 	FemaleNeeds                int                        `json:"female_needs" validate:"omitempty"`
@@ -49,14 +49,18 @@ type CreateMPRequestHeaderRequest struct {
 	EmpOrganizationID          *uuid.UUID                 `json:"emp_organization_id" validate:"required,uuid"`
 	JobLevelID                 *uuid.UUID                 `json:"job_level_id" validate:"required,uuid"`
 	IsReplacement              *bool                      `json:"is_replacement" validate:"required"`
+	PlafonOverrideReason       string                     `json:"plafon_override_reason" validate:"omitempty"` // asks for an override when the request goes over the job plafon
+	Version                    int                        `json:"version" validate:"omitempty"`                // needed on update, the If-Match header wins over it
 }
 
 type UpdateMPRequestHeaderRequest struct {
-	ID          string                               `json:"id" validate:"required"`
-	Status      entity.MPRequestStatus               `json:"status" validate:"required,MPRequestStatusValidation"`
-	Notes       string                               `json:"notes" validate:"omitempty"`
-	Level       entity.MPRequestApprovalHistoryLevel `json:"level" validate:"required,MPRequestApprovalHistoryLevelValidation"`
-	Attachments []ManpowerAttachmentRequest          `json:"attachments" validate:"omitempty,dive"`
-	ApprovedBy  string                               `json:"approved_by" validate:"required"`
-	ApproverID  uuid.UUID                            `json:"approver_id" validate:"required"`
+	ID           string                               `json:"id" validate:"required"`
+	Status       entity.MPRequestStatus               `json:"status" validate:"required,MPRequestStatusValidation"`
+	Notes        string                               `json:"notes" validate:"omitempty"`
+	Level        entity.MPRequestApprovalHistoryLevel `json:"level" validate:"required,MPRequestApprovalHistoryLevelValidation"`
+	Attachments  []ManpowerAttachmentRequest          `json:"attachments" validate:"omitempty,dive"`
+	ApprovedBy   string                               `json:"approved_by" validate:"required"`
+	ApproverID   uuid.UUID                            `json:"approver_id" validate:"required"` // must be the logged-in employee
+	OnBehalfOfID *uuid.UUID                           `json:"on_behalf_of_id" validate:"omitempty"`
+	Version      int                                  `json:"version" validate:"omitempty"` // the If-Match header wins over it
 }
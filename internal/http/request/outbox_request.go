@@ -0,0 +1,12 @@
+package request
+
+type FindAllPaginatedOutboxMessageRequest struct {
+	Page        int    `json:"page"`
+	PageSize    int    `json:"page_size"`
+	Status      string `json:"status"`
+	MessageType string `json:"message_type"`
+}
+
+type ReplayOutboxMessageRequest struct {
+	ID string `json:"id" validate:"required,uuid"`
+}
@@ -0,0 +1,27 @@
+package request
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type FindAllPaginatedPlafonOverrideRequest struct {
+	Page         int    `json:"page"`
+	PageSize     int    `json:"page_size"`
+	Search       string `json:"search"`
+	Status       string `json:"status"`
+	DocumentType string `json:"document_type"`
+	JobID        string `json:"job_id"`
+	MPPPeriodID  string `json:"mpp_period_id"`
+}
+
+type FindByIdPlafonOverrideRequest struct {
+	ID string `json:"id" validate:"required,uuid"`
+}
+
+type UpdateStatusPlafonOverrideRequest struct {
+	ID         uuid.UUID                   `json:"id" validate:"required"`
+	Status     entity.PlafonOverrideStatus `json:"status" validate:"required,PlafonOverrideDecisionValidation"`
+	ApproverID uuid.UUID                   `json:"approver_id" validate:"required"`
+	Notes      string                      `json:"notes" validate:"omitempty"`
+}
@@ -0,0 +1,10 @@
+package request
+
+type PlanVsActualReportRequest struct {
+	MPPPeriodID            string   `json:"mpp_period_id" validate:"omitempty,uuid"`
+	OrganizationID         string   `json:"organization_id" validate:"omitempty,uuid"`
+	OrganizationLocationID string   `json:"organization_location_id" validate:"omitempty,uuid"`
+	JobLevelID             string   `json:"job_level_id" validate:"omitempty,uuid"`
+	JobID                  string   `json:"job_id" validate:"omitempty,uuid"`
+	GroupBy                []string `json:"group_by" validate:"dive,oneof=mpp_period organization organization_location job_level job"`
+}
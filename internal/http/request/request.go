@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -178,6 +179,54 @@ func BatchHeaderApproverTypeValidation(fl validator.FieldLevel) bool {
 	}
 }
 
+func ApprovalChainDocumentTypeValidation(fl validator.FieldLevel) bool {
+	documentType := fl.Field().String()
+	if documentType == "" {
+		return true
+	}
+	switch entity.ApprovalChainDocumentType(documentType) {
+	case entity.ApprovalChainDocumentTypeMPPlanning, entity.ApprovalChainDocumentTypeMPRequest, entity.ApprovalChainDocumentTypeBatch:
+		return true
+	default:
+		return false
+	}
+}
+
+func ApprovalChainStepConditionValidation(fl validator.FieldLevel) bool {
+	condition := fl.Field().String()
+	if condition == "" {
+		return true
+	}
+	switch entity.ApprovalChainStepCondition(condition) {
+	case entity.ApprovalChainStepConditionAll, entity.ApprovalChainStepConditionOnBudget, entity.ApprovalChainStepConditionOffBudget:
+		return true
+	default:
+		return false
+	}
+}
+
+func ApprovalDelegationScopeValidation(fl validator.FieldLevel) bool {
+	scope := fl.Field().String()
+	if scope == "" {
+		return true
+	}
+	switch entity.ApprovalDelegationScope(scope) {
+	case entity.ApprovalDelegationScopeAll, entity.ApprovalDelegationScopeMPPlanning, entity.ApprovalDelegationScopeMPRequest, entity.ApprovalDelegationScopeBatch:
+		return true
+	default:
+		return false
+	}
+}
+
+func PlafonOverrideDecisionValidation(fl validator.FieldLevel) bool {
+	switch entity.PlafonOverrideStatus(fl.Field().String()) {
+	case entity.PlafonOverrideStatusApproved, entity.PlafonOverrideStatusRejected:
+		return true
+	default:
+		return false
+	}
+}
+
 func ValidateDateMoreThanEqualToday(fl validator.FieldLevel) bool {
 	startDateStr := fl.Field().String()
 	startDate, err := time.Parse("2006-01-02", startDateStr)
@@ -185,7 +234,7 @@ func ValidateDateMoreThanEqualToday(fl validator.FieldLevel) bool {
 		return false
 	}
 
-	today := time.Now().Truncate(24 * time.Hour)
+	today := timezone.TodayDate()
 	return !startDate.Before(today)
 }
 
@@ -219,6 +268,14 @@ func MessageValidation(fe validator.FieldError) string {
 		return "Invalid MP request approval history status"
 	case "MPRequestApprovalHistoryLevelValidation":
 		return "Invalid MP request approval history level"
+	case "ApprovalChainDocumentTypeValidation":
+		return "Invalid approval chain document type"
+	case "ApprovalChainStepConditionValidation":
+		return "Invalid approval chain step condition"
+	case "ApprovalDelegationScopeValidation":
+		return "Invalid approval delegation scope"
+	case "PlafonOverrideDecisionValidation":
+		return "Plafon override status must be APPROVED or REJECTED"
 	case "dive":
 		return "Invalid array"
 	}
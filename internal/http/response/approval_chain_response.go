@@ -0,0 +1,34 @@
+package response
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type ApprovalChainStepResponse struct {
+	ID           uuid.UUID                         `json:"id"`
+	Sequence     int                               `json:"sequence"`
+	Level        string                            `json:"level"`
+	ApproverID   *uuid.UUID                        `json:"approver_id"`
+	ApproverName string                            `json:"approver_name"`
+	Condition    entity.ApprovalChainStepCondition `json:"condition"`
+}
+
+type ApprovalChainResponse struct {
+	ID               uuid.UUID                        `json:"id"`
+	OrganizationID   *uuid.UUID                       `json:"organization_id"`
+	OrganizationName string                           `json:"organization_name"`
+	DocumentType     entity.ApprovalChainDocumentType `json:"document_type"`
+	Name             string                           `json:"name"`
+	IsActive         bool                             `json:"is_active"`
+	Steps            []ApprovalChainStepResponse      `json:"steps"`
+	CreatedAt        time.Time                        `json:"created_at"`
+	UpdatedAt        time.Time                        `json:"updated_at"`
+}
+
+type FindAllPaginatedApprovalChainResponse struct {
+	ApprovalChains []ApprovalChainResponse `json:"approval_chains"`
+	Total          int64                   `json:"total"`
+}
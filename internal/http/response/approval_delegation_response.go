@@ -0,0 +1,27 @@
+package response
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type ApprovalDelegationResponse struct {
+	ID            uuid.UUID                      `json:"id"`
+	DelegatorID   *uuid.UUID                     `json:"delegator_id"`
+	DelegatorName string                         `json:"delegator_name"`
+	DelegateID    *uuid.UUID                     `json:"delegate_id"`
+	DelegateName  string                         `json:"delegate_name"`
+	Scope         entity.ApprovalDelegationScope `json:"scope"`
+	StartDate     time.Time                      `json:"start_date"`
+	EndDate       time.Time                      `json:"end_date"`
+	Reason        string                         `json:"reason"`
+	CreatedAt     time.Time                      `json:"created_at"`
+	UpdatedAt     time.Time                      `json:"updated_at"`
+}
+
+type FindAllPaginatedApprovalDelegationResponse struct {
+	ApprovalDelegations []ApprovalDelegationResponse `json:"approval_delegations"`
+	Total               int64                        `json:"total"`
+}
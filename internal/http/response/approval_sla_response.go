@@ -0,0 +1,28 @@
+package response
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type ApprovalSLAResponse struct {
+	ID                 uuid.UUID                        `json:"id"`
+	DocumentType       entity.ApprovalChainDocumentType `json:"document_type"`
+	Level              string                           `json:"level"`
+	ReminderAfterDays  int                              `json:"reminder_after_days"`
+	EscalateAfterDays  int                              `json:"escalate_after_days"`
+	EscalateToLevel    string                           `json:"escalate_to_level"`
+	BackupApproverID   *uuid.UUID                       `json:"backup_approver_id"`
+	BackupApproverName string                           `json:"backup_approver_name"`
+	PermissionName     string                           `json:"permission_name"`
+	IsActive           bool                             `json:"is_active"`
+	CreatedAt          time.Time                        `json:"created_at"`
+	UpdatedAt          time.Time                        `json:"updated_at"`
+}
+
+type FindAllPaginatedApprovalSLAResponse struct {
+	ApprovalSLAs []ApprovalSLAResponse `json:"approval_slas"`
+	Total        int64                 `json:"total"`
+}
@@ -0,0 +1,27 @@
+package response
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type AuditLogResponse struct {
+	ID        uuid.UUID          `json:"id"`
+	Action    entity.AuditAction `json:"action"`
+	Entity    string             `json:"entity"`
+	EntityID  string             `json:"entity_id"`
+	Field     string             `json:"field"`
+	OldValue  *string            `json:"old_value"`
+	NewValue  *string            `json:"new_value"`
+	ActorID   string             `json:"actor_id"`
+	ActorName string             `json:"actor_name"`
+	RequestID string             `json:"request_id"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type FindAllPaginatedAuditLogResponse struct {
+	AuditLogs []AuditLogResponse `json:"audit_logs"`
+	Total     int64              `json:"total"`
+}
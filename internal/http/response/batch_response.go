@@ -14,6 +14,7 @@ type BatchResponse struct {
 	ApproverID     *uuid.UUID           `json:"approver_id"`
 	ApproverName   string               `json:"approver_name"`
 	Status         string               `json:"status"`
+	Version        int                  `json:"version"`
 	CreatedAt      time.Time            `json:"created_at"`
 	UpdatedAt      time.Time            `json:"updated_at"`
 	BatchLines     []*BatchLineResponse `json:"batch_lines"`
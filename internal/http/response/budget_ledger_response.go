@@ -0,0 +1,33 @@
+package response
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type BudgetLedgerEntryResponse struct {
+	ID                uuid.UUID                    `json:"id"`
+	MPRequestHeaderID uuid.UUID                    `json:"mp_request_header_id"`
+	DocumentNumber    string                       `json:"document_number"`
+	RecruitmentType   entity.RecruitmentTypeEnum   `json:"recruitment_type"`
+	MPPlanningLineID  uuid.UUID                    `json:"mp_planning_line_id"`
+	EntryType         entity.BudgetLedgerEntryType `json:"entry_type"`
+	Balance           entity.BudgetBalance         `json:"balance"`
+	Quantity          int                          `json:"quantity"`
+	Reserved          int                          `json:"reserved"`
+	Consumed          int                          `json:"consumed"`
+	MPRequestStatus   entity.MPRequestStatus       `json:"mp_request_status"`
+	CreatedAt         time.Time                    `json:"created_at"`
+}
+
+type FindAllPaginatedBudgetLedgerResponse struct {
+	MPPlanningLineID   uuid.UUID                   `json:"mp_planning_line_id"`
+	RecruitMT          int                         `json:"recruit_mt"`
+	RemainingBalanceMT int                         `json:"remaining_balance_mt"`
+	RecruitPH          int                         `json:"recruit_ph"`
+	RemainingBalancePH int                         `json:"remaining_balance_ph"`
+	Entries            []BudgetLedgerEntryResponse `json:"entries"`
+	Total              int64                       `json:"total"`
+}
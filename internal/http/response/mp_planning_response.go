@@ -28,6 +28,9 @@ type MPPlanningHeaderResponse struct {
 	NotesManager           string                 `json:"notes_manager"`
 	ApproverRecruitmentID  *uuid.UUID             `json:"approver_recruitment_id"` // user_id
 	NotesRecruitment       string                 `json:"notes_recruitment"`
+	NextApproverID         *uuid.UUID             `json:"next_approver_id"`
+	NextApproverLevel      string                 `json:"next_approver_level"`
+	Version                int                    `json:"version"`
 	CreatedAt              time.Time              `json:"created_at"`
 	UpdatedAt              time.Time              `json:"updated_at"`
 	DeletedAt              *time.Time             `json:"deleted_at"`
@@ -51,6 +54,8 @@ type MPPlanningApprovalHistoryResponse struct {
 	MPPlanningHeaderID uuid.UUID                              `json:"mp_planning_header_id"`
 	ApproverID         uuid.UUID                              `json:"approver_id"`
 	ApproverName       string                                 `json:"approver_name"`
+	OnBehalfOfID       *uuid.UUID                             `json:"on_behalf_of_id"`
+	OnBehalfOfName     string                                 `json:"on_behalf_of_name"`
 	Notes              string                                 `json:"notes"`
 	Level              string                                 `json:"level"`
 	Status             entity.MPPlanningApprovalHistoryStatus `json:"status"`
@@ -88,20 +93,23 @@ type FindHeaderByMPPPeriodIdMPPlanningResponse struct {
 }
 
 type MPPlanningLineResponse struct {
-	ID                     uuid.UUID `json:"id"`
-	MPPlanningHeaderID     uuid.UUID `json:"mp_planning_header_id"`
-	OrganizationLocationID uuid.UUID `json:"organization_location_id"`
-	JobLevelID             uuid.UUID `json:"job_level_id"`
-	JobID                  uuid.UUID `json:"job_id"`
-	Existing               int       `json:"existing"`
-	Recruit                int       `json:"recruit"`
-	SuggestedRecruit       int       `json:"suggested_recruit"`
-	Promotion              int       `json:"promotion"`
-	Total                  int       `json:"total"`
-	RemainingBalancePH     int       `json:"remaining_balance_ph"`
-	RemainingBalanceMT     int       `json:"remaining_balance_mt"`
-	RecruitPH              int       `json:"recruit_ph"`
-	RecruitMT              int       `json:"recruit_mt"`
+	ID                     uuid.UUID                   `json:"id"`
+	MPPlanningHeaderID     uuid.UUID                   `json:"mp_planning_header_id"`
+	OrganizationLocationID uuid.UUID                   `json:"organization_location_id"`
+	JobLevelID             uuid.UUID                   `json:"job_level_id"`
+	JobID                  uuid.UUID                   `json:"job_id"`
+	Existing               int                         `json:"existing"`
+	Recruit                int                         `json:"recruit"`
+	SuggestedRecruit       int                         `json:"suggested_recruit"`
+	Promotion              int                         `json:"promotion"`
+	Total                  int                         `json:"total"`
+	RemainingBalancePH     int                         `json:"remaining_balance_ph"`
+	RemainingBalanceMT     int                         `json:"remaining_balance_mt"`
+	RecruitPH              int                         `json:"recruit_ph"`
+	RecruitMT              int                         `json:"recruit_mt"`
+	IsOverPlafon           bool                        `json:"is_over_plafon"`
+	PlafonOverrideStatus   entity.PlafonOverrideStatus `json:"plafon_override_status"`
+	Version                int                         `json:"version"`
 
 	OrganizationLocationName string `json:"organization_location_name"`
 	JobLevelName             string `json:"job_level_name"`
@@ -133,6 +141,7 @@ type FindByIdMPPlanningResponse struct {
 	ApprovedBy              string                 `json:"approved_by"`  // free text
 	RequestorID             *uuid.UUID             `json:"requestor_id"` // user_id
 	NotesAttach             string                 `json:"notes_attach"`
+	Version                 int                    `json:"version"`
 	CreatedAt               time.Time              `json:"created_at"`
 	UpdatedAt               time.Time              `json:"updated_at"`
 	DeletedAt               *time.Time             `json:"deleted_at"`
@@ -141,6 +150,8 @@ type FindByIdMPPlanningResponse struct {
 	NotesManager            string                 `json:"notes_manager"`
 	ApproverRecruitmentID   *uuid.UUID             `json:"approver_recruitment_id"` // user_id
 	NotesRecruitment        string                 `json:"notes_recruitment"`
+	NextApproverID          *uuid.UUID             `json:"next_approver_id"`
+	NextApproverLevel       string                 `json:"next_approver_level"`
 	ApproverCEOName         string                 `json:"approver_ceo_name"`
 	ApproverManagerName     string                 `json:"approver_manager_name"`
 	ApproverRecruitmentName string                 `json:"approver_recruitment_name"`
@@ -193,6 +204,7 @@ type UpdateMPPlanningResponse struct {
 	ApprovedBy        string                       `json:"approved_by"`
 	RequestorID       string                       `json:"requestor_id"`
 	NotesAttach       string                       `json:"notes_attach"`
+	Version           int                          `json:"version"`
 	CreatedAt         time.Time                    `json:"created_at"`
 	UpdatedAt         time.Time                    `json:"updated_at"`
 	DeletedAt         time.Time                    `json:"deleted_at"`
@@ -209,23 +221,26 @@ type FindByIdMPPlanningLineResponse struct {
 }
 
 type CreateMPPlanningLineResponse struct {
-	ID                     string    `json:"id"`
-	MPPlanningHeaderID     string    `json:"mp_planning_header_id"`
-	OrganizationLocationID string    `json:"organization_location_id"`
-	JobLevelID             string    `json:"job_level_id"`
-	JobID                  string    `json:"job_id"`
-	Existing               int       `json:"existing"`
-	Recruit                int       `json:"recruit"`
-	SuggestedRecruit       int       `json:"suggested_recruit"`
-	Promotion              int       `json:"promotion"`
-	Total                  int       `json:"total"`
-	RemainingBalancePH     int       `json:"remaining_balance_ph"`
-	RemainingBalanceMT     int       `json:"remaining_balance_mt"`
-	RecruitPH              int       `json:"recruit_ph"`
-	RecruitMT              int       `json:"recruit_mt"`
-	CreatedAt              time.Time `json:"created_at"`
-	UpdatedAt              time.Time `json:"updated_at"`
-	DeletedAt              time.Time `json:"deleted_at"`
+	ID                     string                      `json:"id"`
+	MPPlanningHeaderID     string                      `json:"mp_planning_header_id"`
+	OrganizationLocationID string                      `json:"organization_location_id"`
+	JobLevelID             string                      `json:"job_level_id"`
+	JobID                  string                      `json:"job_id"`
+	Existing               int                         `json:"existing"`
+	Recruit                int                         `json:"recruit"`
+	SuggestedRecruit       int                         `json:"suggested_recruit"`
+	Promotion              int                         `json:"promotion"`
+	Total                  int                         `json:"total"`
+	RemainingBalancePH     int                         `json:"remaining_balance_ph"`
+	RemainingBalanceMT     int                         `json:"remaining_balance_mt"`
+	RecruitPH              int                         `json:"recruit_ph"`
+	RecruitMT              int                         `json:"recruit_mt"`
+	IsOverPlafon           bool                        `json:"is_over_plafon"`
+	PlafonOverrideStatus   entity.PlafonOverrideStatus `json:"plafon_override_status"`
+	Version                int                         `json:"version"`
+	CreatedAt              time.Time                   `json:"created_at"`
+	UpdatedAt              time.Time                   `json:"updated_at"`
+	DeletedAt              time.Time                   `json:"deleted_at"`
 }
 
 type ManpowerAttachmentResponse struct {
@@ -236,21 +251,108 @@ type ManpowerAttachmentResponse struct {
 }
 
 type UpdateMPPlanningLineResponse struct {
-	ID                     string    `json:"id"`
-	MPPlanningHeaderID     string    `json:"mp_planning_header_id"`
-	OrganizationLocationID string    `json:"organization_location_id"`
-	JobLevelID             string    `json:"job_level_id"`
-	JobID                  string    `json:"job_id"`
-	Existing               int       `json:"existing"`
-	Recruit                int       `json:"recruit"`
-	SuggestedRecruit       int       `json:"suggested_recruit"`
-	Promotion              int       `json:"promotion"`
-	Total                  int       `json:"total"`
-	RemainingBalanceMT     int       `json:"remaining_balance_mt"`
-	RemainingBalancePH     int       `json:"remaining_balance_ph"`
-	RecruitPH              int       `json:"recruit_ph"`
-	RecruitMT              int       `json:"recruit_mt"`
-	CreatedAt              time.Time `json:"created_at"`
-	UpdatedAt              time.Time `json:"updated_at"`
-	DeletedAt              time.Time `json:"deleted_at"`
+	ID                     string                      `json:"id"`
+	MPPlanningHeaderID     string                      `json:"mp_planning_header_id"`
+	OrganizationLocationID string                      `json:"organization_location_id"`
+	JobLevelID             string                      `json:"job_level_id"`
+	JobID                  string                      `json:"job_id"`
+	Existing               int                         `json:"existing"`
+	Recruit                int                         `json:"recruit"`
+	SuggestedRecruit       int                         `json:"suggested_recruit"`
+	Promotion              int                         `json:"promotion"`
+	Total                  int                         `json:"total"`
+	RemainingBalanceMT     int                         `json:"remaining_balance_mt"`
+	RemainingBalancePH     int                         `json:"remaining_balance_ph"`
+	RecruitPH              int                         `json:"recruit_ph"`
+	RecruitMT              int                         `json:"recruit_mt"`
+	IsOverPlafon           bool                        `json:"is_over_plafon"`
+	PlafonOverrideStatus   entity.PlafonOverrideStatus `json:"plafon_override_status"`
+	Version                int                         `json:"version"`
+	CreatedAt              time.Time                   `json:"created_at"`
+	UpdatedAt              time.Time                   `json:"updated_at"`
+	DeletedAt              time.Time                   `json:"deleted_at"`
+}
+
+type MPPlanningLineImportResponse struct {
+	ID                 uuid.UUID                         `json:"id"`
+	MPPlanningHeaderID uuid.UUID                         `json:"mp_planning_header_id"`
+	FileName           string                            `json:"file_name"`
+	Status             entity.MPPlanningLineImportStatus `json:"status"`
+	TotalRows          int                               `json:"total_rows"`
+	ErrorRows          int                               `json:"error_rows"`
+	HeaderVersion      int                               `json:"header_version"`
+	ImportedBy         *uuid.UUID                        `json:"imported_by"`
+	ConfirmedAt        *time.Time                        `json:"confirmed_at"`
+	CreatedAt          time.Time                         `json:"created_at"`
+	Rows               []MPPlanningLineImportRowResponse `json:"rows"`
+}
+
+type MPPlanningLineImportRowResponse struct {
+	RowNumber                int        `json:"row_number"`
+	Action                   string     `json:"action"` // create or update
+	OrganizationLocationName string     `json:"organization_location_name"`
+	JobLevelName             string     `json:"job_level_name"`
+	JobName                  string     `json:"job_name"`
+	OrganizationLocationID   *uuid.UUID `json:"organization_location_id"`
+	JobLevelID               *uuid.UUID `json:"job_level_id"`
+	JobID                    *uuid.UUID `json:"job_id"`
+	MPPlanningLineID         *uuid.UUID `json:"mp_planning_line_id"`
+	Existing                 int        `json:"existing"`
+	RecruitMT                int        `json:"recruit_mt"`
+	RecruitPH                int        `json:"recruit_ph"`
+	Recruit                  int        `json:"recruit"`
+	Promotion                int        `json:"promotion"`
+	Total                    int        `json:"total"`
+	Errors                   []string   `json:"errors"`
+}
+
+type CopyMPPlanningFromPeriodResponse struct {
+	SourceMPPPeriodID uuid.UUID                             `json:"source_mpp_period_id"`
+	TargetMPPPeriodID uuid.UUID                             `json:"target_mpp_period_id"`
+	CarryOverBalance  bool                                  `json:"carry_over_balance"`
+	DryRun            bool                                  `json:"dry_run"`
+	TotalHeaders      int                                   `json:"total_headers"`
+	TotalLines        int                                   `json:"total_lines"`
+	Headers           []CopiedMPPlanningHeaderResponse      `json:"headers"`
+	SkippedHeaders    []SkippedCopyMPPlanningHeaderResponse `json:"skipped_headers"`
+}
+
+type CopiedMPPlanningHeaderResponse struct {
+	ID                     *uuid.UUID                          `json:"id"` // empty on a dry run
+	DocumentNumber         string                              `json:"document_number"`
+	SourceID               uuid.UUID                           `json:"source_id"`
+	SourceDocumentNumber   string                              `json:"source_document_number"`
+	SourceStatus           entity.MPPlaningStatus              `json:"source_status"`
+	OrganizationID         *uuid.UUID                          `json:"organization_id"`
+	OrganizationLocationID *uuid.UUID                          `json:"organization_location_id"`
+	Lines                  []CopiedMPPlanningLineResponse      `json:"lines"`
+	SkippedLines           []SkippedCopyMPPlanningLineResponse `json:"skipped_lines"`
+}
+
+type CopiedMPPlanningLineResponse struct {
+	SourceLineID         uuid.UUID  `json:"source_line_id"`
+	JobLevelID           *uuid.UUID `json:"job_level_id"`
+	JobID                *uuid.UUID `json:"job_id"`
+	PreviousExisting     int        `json:"previous_existing"`
+	Existing             int        `json:"existing"`
+	CarriedOverRecruitPH int        `json:"carried_over_recruit_ph"`
+	CarriedOverRecruitMT int        `json:"carried_over_recruit_mt"`
+	RecruitPH            int        `json:"recruit_ph"`
+	RecruitMT            int        `json:"recruit_mt"`
+	Promotion            int        `json:"promotion"`
+	Total                int        `json:"total"`
+	IsOverPlafon         bool       `json:"is_over_plafon"`
+}
+
+type SkippedCopyMPPlanningHeaderResponse struct {
+	SourceID               uuid.UUID  `json:"source_id"`
+	SourceDocumentNumber   string     `json:"source_document_number"`
+	OrganizationLocationID *uuid.UUID `json:"organization_location_id"`
+	Reason                 string     `json:"reason"`
+}
+
+type SkippedCopyMPPlanningLineResponse struct {
+	SourceLineID uuid.UUID  `json:"source_line_id"`
+	JobID        *uuid.UUID `json:"job_id"`
+	Reason       string     `json:"reason"`
 }
@@ -13,52 +13,57 @@ type MPRequestPaginatedResponse struct {
 }
 
 type MPRequestHeaderResponse struct {
-	ID                         uuid.UUID                  `json:"id"`
-	OrganizationID             uuid.UUID                  `json:"organization_id"`
-	OrganizationLocationID     uuid.UUID                  `json:"organization_location_id"`
-	ForOrganizationID          uuid.UUID                  `json:"for_organization_id"`
-	ForOrganizationLocationID  uuid.UUID                  `json:"for_organization_location_id"`
-	ForOrganizationStructureID uuid.UUID                  `json:"for_organization_structure_id"`
-	JobID                      uuid.UUID                  `json:"job_id"`
-	RequestCategoryID          uuid.UUID                  `json:"request_category_id"`
-	GradeID                    *uuid.UUID                 `json:"grade_id"`
-	ExpectedDate               time.Time                  `json:"expected_date"`
-	Experiences                string                     `json:"experiences"`
-	DocumentNumber             string                     `json:"document_number"`
-	DocumentDate               time.Time                  `json:"document_date"`
-	MaleNeeds                  int                        `json:"male_needs"`
-	FemaleNeeds                int                        `json:"female_needs"`
-	AnyGender                  int                        `json:"any_gender"`
-	TotalNeeds                 int                        `json:"total_needs"`
-	MinimumAge                 int                        `json:"minimum_age"`
-	MaximumAge                 int                        `json:"maximum_age"`
-	MinimumExperience          int                        `json:"minimum_experience"`
-	MaritalStatus              entity.MaritalStatusEnum   `json:"marital_status"`
-	MinimumEducation           entity.EducationLevelEnum  `json:"minimum_education"`
-	RequiredQualification      string                     `json:"required_qualification"`
-	Certificate                string                     `json:"certificate"`
-	ComputerSkill              string                     `json:"computer_skill"`
-	LanguageSkill              string                     `json:"language_skill"`
-	OtherSkill                 string                     `json:"other_skill"`
-	Jobdesc                    string                     `json:"jobdesc"`
-	SalaryMin                  string                     `json:"salary_min"`
-	SalaryMax                  string                     `json:"salary_max"`
-	RequestorID                *uuid.UUID                 `json:"requestor_id"`
-	DepartmentHead             *uuid.UUID                 `json:"department_head"`
-	VpGmDirector               *uuid.UUID                 `json:"vp_gm_director"`
-	CEO                        *uuid.UUID                 `json:"ceo"`
-	HrdHoUnit                  *uuid.UUID                 `json:"hrd_ho_unit"`
-	MPPlanningHeaderID         *uuid.UUID                 `json:"mp_planning_header_id"`
-	Status                     entity.MPRequestStatus     `json:"status"`
-	MPRequestType              entity.MPRequestTypeEnum   `json:"mp_request_type"`
-	RecruitmentType            entity.RecruitmentTypeEnum `json:"recruitment_type"`
-	MPPPeriodID                *uuid.UUID                 `json:"mpp_period_id"`
-	EmpOrganizationID          *uuid.UUID                 `json:"emp_organization_id"`
-	JobLevelID                 *uuid.UUID                 `json:"job_level_id"`
-	IsReplacement              bool                       `json:"is_replacement"`
-	Revised                    int                        `json:"revised"`
-	CreatedAt                  time.Time                  `json:"created_at"`
-	UpdatedAt                  time.Time                  `json:"updated_at"`
+	ID                         uuid.UUID                   `json:"id"`
+	OrganizationID             uuid.UUID                   `json:"organization_id"`
+	OrganizationLocationID     uuid.UUID                   `json:"organization_location_id"`
+	ForOrganizationID          uuid.UUID                   `json:"for_organization_id"`
+	ForOrganizationLocationID  uuid.UUID                   `json:"for_organization_location_id"`
+	ForOrganizationStructureID uuid.UUID                   `json:"for_organization_structure_id"`
+	JobID                      uuid.UUID                   `json:"job_id"`
+	RequestCategoryID          uuid.UUID                   `json:"request_category_id"`
+	GradeID                    *uuid.UUID                  `json:"grade_id"`
+	NextApproverID             *uuid.UUID                  `json:"next_approver_id"`
+	NextApproverLevel          string                      `json:"next_approver_level"`
+	ExpectedDate               time.Time                   `json:"expected_date"`
+	Experiences                string                      `json:"experiences"`
+	DocumentNumber             string                      `json:"document_number"`
+	DocumentDate               time.Time                   `json:"document_date"`
+	MaleNeeds                  int                         `json:"male_needs"`
+	FemaleNeeds                int                         `json:"female_needs"`
+	AnyGender                  int                         `json:"any_gender"`
+	TotalNeeds                 int                         `json:"total_needs"`
+	IsOverPlafon               bool                        `json:"is_over_plafon"`
+	PlafonOverrideStatus       entity.PlafonOverrideStatus `json:"plafon_override_status"`
+	MinimumAge                 int                         `json:"minimum_age"`
+	MaximumAge                 int                         `json:"maximum_age"`
+	MinimumExperience          int                         `json:"minimum_experience"`
+	MaritalStatus              entity.MaritalStatusEnum    `json:"marital_status"`
+	MinimumEducation           entity.EducationLevelEnum   `json:"minimum_education"`
+	RequiredQualification      string                      `json:"required_qualification"`
+	Certificate                string                      `json:"certificate"`
+	ComputerSkill              string                      `json:"computer_skill"`
+	LanguageSkill              string                      `json:"language_skill"`
+	OtherSkill                 string                      `json:"other_skill"`
+	Jobdesc                    string                      `json:"jobdesc"`
+	SalaryMin                  string                      `json:"salary_min"`
+	SalaryMax                  string                      `json:"salary_max"`
+	RequestorID                *uuid.UUID                  `json:"requestor_id"`
+	DepartmentHead             *uuid.UUID                  `json:"department_head"`
+	VpGmDirector               *uuid.UUID                  `json:"vp_gm_director"`
+	CEO                        *uuid.UUID                  `json:"ceo"`
+	HrdHoUnit                  *uuid.UUID                  `json:"hrd_ho_unit"`
+	MPPlanningHeaderID         *uuid.UUID                  `json:"mp_planning_header_id"`
+	Status                     entity.MPRequestStatus      `json:"status"`
+	MPRequestType              entity.MPRequestTypeEnum    `json:"mp_request_type"`
+	RecruitmentType            entity.RecruitmentTypeEnum  `json:"recruitment_type"`
+	MPPPeriodID                *uuid.UUID                  `json:"mpp_period_id"`
+	EmpOrganizationID          *uuid.UUID                  `json:"emp_organization_id"`
+	JobLevelID                 *uuid.UUID                  `json:"job_level_id"`
+	IsReplacement              bool                        `json:"is_replacement"`
+	Revised                    int                         `json:"revised"`
+	Version                    int                         `json:"version"`
+	CreatedAt                  time.Time                   `json:"created_at"`
+	UpdatedAt                  time.Time                   `json:"updated_at"`
 
 	RequestCategory map[string]interface{}   `json:"request_category" gorm:"foreignKey:RequestCategoryID"`
 	RequestMajors   []map[string]interface{} `json:"request_majors" gorm:"foreignKey:MPRequestHeaderID"`
@@ -120,6 +125,8 @@ type MPRequestApprovalHistoryResponse struct {
 	MPRequestHeaderID uuid.UUID                             `json:"mp_request_header_id"`
 	ApproverID        uuid.UUID                             `json:"approver_id"`
 	ApproverName      string                                `json:"approver_name"`
+	OnBehalfOfID      *uuid.UUID                            `json:"on_behalf_of_id"`
+	OnBehalfOfName    string                                `json:"on_behalf_of_name"`
 	Notes             string                                `json:"notes"`
 	Level             string                                `json:"level"`
 	Status            entity.MPRequestApprovalHistoryStatus `json:"status"`
@@ -0,0 +1,27 @@
+package response
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type OutboxMessageResponse struct {
+	ID            uuid.UUID                  `json:"id"`
+	MessageType   entity.OutboxMessageType   `json:"message_type"`
+	Destination   string                     `json:"destination"`
+	Payload       string                     `json:"payload"`
+	Status        entity.OutboxMessageStatus `json:"status"`
+	Attempts      int                        `json:"attempts"`
+	NextAttemptAt time.Time                  `json:"next_attempt_at"`
+	LastError     string                     `json:"last_error"`
+	SentAt        *time.Time                 `json:"sent_at"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	UpdatedAt     time.Time                  `json:"updated_at"`
+}
+
+type FindAllPaginatedOutboxMessageResponse struct {
+	OutboxMessages []OutboxMessageResponse `json:"outbox_messages"`
+	Total          int64                   `json:"total"`
+}
@@ -0,0 +1,31 @@
+package response
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type PlafonOverrideResponse struct {
+	ID           uuid.UUID                         `json:"id"`
+	DocumentType entity.PlafonOverrideDocumentType `json:"document_type"`
+	DocumentID   uuid.UUID                         `json:"document_id"`
+	MPPPeriodID  uuid.UUID                         `json:"mpp_period_id"`
+	JobID        uuid.UUID                         `json:"job_id"`
+	Plafon       int                               `json:"plafon"`
+	Headcount    int                               `json:"headcount"`
+	Reason       string                            `json:"reason"`
+	Status       entity.PlafonOverrideStatus       `json:"status"`
+	ApproverID   *uuid.UUID                        `json:"approver_id"`
+	ApproverName string                            `json:"approver_name"`
+	Notes        string                            `json:"notes"`
+	DecidedAt    *time.Time                        `json:"decided_at"`
+	CreatedAt    time.Time                         `json:"created_at"`
+	UpdatedAt    time.Time                         `json:"updated_at"`
+}
+
+type FindAllPaginatedPlafonOverrideResponse struct {
+	PlafonOverrides []PlafonOverrideResponse `json:"plafon_overrides"`
+	Total           int64                    `json:"total"`
+}
@@ -0,0 +1,44 @@
+package response
+
+import "github.com/google/uuid"
+
+// PlanVsActualRowResponse compares the approved planning of a group with the
+// manpower requests made against it. Requested counts every request past
+// draft that was not rejected, completed only the completed ones. The
+// percentages are of the planned recruits and are null when nothing was
+// planned.
+type PlanVsActualRowResponse struct {
+	MPPPeriodID              *uuid.UUID `json:"mpp_period_id"`
+	MPPPeriodTitle           string     `json:"mpp_period_title"`
+	OrganizationID           *uuid.UUID `json:"organization_id"`
+	OrganizationName         string     `json:"organization_name"`
+	OrganizationLocationID   *uuid.UUID `json:"organization_location_id"`
+	OrganizationLocationName string     `json:"organization_location_name"`
+	JobLevelID               *uuid.UUID `json:"job_level_id"`
+	JobLevelName             string     `json:"job_level_name"`
+	JobLevel                 int        `json:"job_level"`
+	JobID                    *uuid.UUID `json:"job_id"`
+	JobName                  string     `json:"job_name"`
+
+	Existing    int `json:"existing"`
+	Recruit     int `json:"recruit"`
+	RecruitMT   int `json:"recruit_mt"`
+	RecruitPH   int `json:"recruit_ph"`
+	Requested   int `json:"requested"`
+	RequestedMT int `json:"requested_mt"`
+	RequestedPH int `json:"requested_ph"`
+	Completed   int `json:"completed"`
+	CompletedMT int `json:"completed_mt"`
+	CompletedPH int `json:"completed_ph"`
+
+	RequestedPercentage     *float64 `json:"requested_percentage"`
+	UtilizationPercentage   *float64 `json:"utilization_percentage"`
+	UtilizationMTPercentage *float64 `json:"utilization_mt_percentage"`
+	UtilizationPHPercentage *float64 `json:"utilization_ph_percentage"`
+}
+
+type PlanVsActualReportResponse struct {
+	GroupBy []string                  `json:"group_by"`
+	Rows    []PlanVsActualRowResponse `json:"rows"`
+	Total   PlanVsActualRowResponse   `json:"total"`
+}
@@ -9,17 +9,27 @@ import (
 )
 
 type RouteConfig struct {
-	App                    *gin.Engine
-	Log                    *logrus.Logger
-	Viper                  *viper.Viper
-	MPPPeriodHandler       handler.IMPPPeriodHander
-	JobPlafonHandler       handler.IJobPlafonHandler
-	MPPlanningHandler      handler.IMPPlanningHandler
-	RequestCategoryHandler handler.IRequestCategoryHandler
-	MajorHandler           handler.IMajorHandler
-	MPRequestHandler       handler.IMPRequestHandler
-	BatchHandler           handler.IBatchHandler
-	AuthMiddleware         gin.HandlerFunc
+	App                       *gin.Engine
+	Log                       *logrus.Logger
+	Viper                     *viper.Viper
+	MPPPeriodHandler          handler.IMPPPeriodHander
+	JobPlafonHandler          handler.IJobPlafonHandler
+	MPPlanningHandler         handler.IMPPlanningHandler
+	RequestCategoryHandler    handler.IRequestCategoryHandler
+	MajorHandler              handler.IMajorHandler
+	MPRequestHandler          handler.IMPRequestHandler
+	BatchHandler              handler.IBatchHandler
+	ApprovalChainHandler      handler.IApprovalChainHandler
+	ApprovalDelegationHandler handler.IApprovalDelegationHandler
+	ApprovalSLAHandler        handler.IApprovalSLAHandler
+	PlafonOverrideHandler     handler.IPlafonOverrideHandler
+	BudgetLedgerHandler       handler.IBudgetLedgerHandler
+	ReportHandler             handler.IReportHandler
+	OutboxHandler             handler.IOutboxHandler
+	HealthHandler             handler.IHealthHandler
+	AuditLogHandler           handler.IAuditLogHandler
+	AuthMiddleware            gin.HandlerFunc
+	PermissionMiddleware      middleware.IPermissionMiddleware
 }
 
 func (c *RouteConfig) SetupRoutes() {
@@ -29,6 +39,11 @@ func (c *RouteConfig) SetupRoutes() {
 	// 	})
 	// })
 
+	c.App.Use(middleware.NewRequestID())
+
+	c.App.GET("/health/live", c.HealthHandler.Live)
+	c.App.GET("/health/ready", c.HealthHandler.Ready)
+
 	c.SetupAPIRoutes()
 	// c.SetupMPPPeriodRoutes()
 	// c.SetupJobPlafonRoutes()
@@ -39,6 +54,12 @@ func (c *RouteConfig) SetupRoutes() {
 }
 
 func (c *RouteConfig) SetupAPIRoutes() {
+	can := c.PermissionMiddleware.RequirePermission
+	scoped := c.PermissionMiddleware.OrganizationScope()
+	// approvers delegate their own approvals, manage-approval-delegation
+	// delegates for anyone
+	delegate := can("approve-mpp", "approve-mpr", "approve-batch-ceo", "approve-batch-director", "manage-approval-delegation")
+
 	apiRoute := c.App.Group("/api")
 	{
 		apiRoute.Use(c.AuthMiddleware)
@@ -47,53 +68,59 @@ func (c *RouteConfig) SetupAPIRoutes() {
 			apiRoute.GET("/mpp-periods", c.MPPPeriodHandler.FindAllPaginated)
 			apiRoute.GET("/mpp-periods/current", c.MPPPeriodHandler.FindByCurrentDateAndStatus)
 			apiRoute.GET("/mpp-periods/status", c.MPPPeriodHandler.FindByStatus)
-			apiRoute.GET("/mpp-periods/update-status", c.MPPPeriodHandler.UpdateStatusByDate)
+			apiRoute.GET("/mpp-periods/update-status", can("update-mpp-period"), c.MPPPeriodHandler.UpdateStatusByDate)
 			apiRoute.GET("/mpp-periods/:id", c.MPPPeriodHandler.FindById)
-			apiRoute.POST("/mpp-periods", c.MPPPeriodHandler.Create)
-			apiRoute.PUT("/mpp-periods", c.MPPPeriodHandler.Update)
-			apiRoute.DELETE("/mpp-periods/:id", c.MPPPeriodHandler.Delete)
+			apiRoute.POST("/mpp-periods", can("create-mpp-period"), c.MPPPeriodHandler.Create)
+			apiRoute.PUT("/mpp-periods", can("update-mpp-period"), c.MPPPeriodHandler.Update)
+			apiRoute.DELETE("/mpp-periods/:id", can("delete-mpp-period"), c.MPPPeriodHandler.Delete)
 
 			// job plafon
 			apiRoute.GET("/job-plafons", c.JobPlafonHandler.FindAllPaginated)
-			apiRoute.GET("/job-plafons/sync", c.JobPlafonHandler.SyncJobPlafon)
+			apiRoute.GET("/job-plafons/sync", can("update-job-plafon"), c.JobPlafonHandler.SyncJobPlafon)
 			apiRoute.GET("/job-plafons/:id", c.JobPlafonHandler.FindById)
 			apiRoute.GET("/job-plafons/job/:job_id", c.JobPlafonHandler.FindByJobId)
-			apiRoute.POST("/job-plafons", c.JobPlafonHandler.Create)
-			apiRoute.PUT("/job-plafons", c.JobPlafonHandler.Update)
-			apiRoute.DELETE("/job-plafons/:id", c.JobPlafonHandler.Delete)
+			apiRoute.POST("/job-plafons", can("create-job-plafon"), c.JobPlafonHandler.Create)
+			apiRoute.PUT("/job-plafons", can("update-job-plafon"), c.JobPlafonHandler.Update)
+			apiRoute.DELETE("/job-plafons/:id", can("delete-job-plafon"), c.JobPlafonHandler.Delete)
 
 			// mp plannings
-			apiRoute.GET("/mp-plannings", c.MPPlanningHandler.FindAllHeadersPaginated)
-			apiRoute.GET("/mp-plannings/something", c.MPPlanningHandler.FindHeaderBySomething)
-			apiRoute.GET("/mp-plannings/get-something", c.MPPlanningHandler.GetHeadersBySomething)
-			apiRoute.GET("/mp-plannings/status-period", c.MPPlanningHandler.FindAllHeadersByStatusAndMPPeriodID)
-			apiRoute.GET("/mp-plannings/completed", c.MPPlanningHandler.GetHeadersByMPPeriodCompleted)
-			apiRoute.GET("/mp-plannings/total-histories", c.MPPlanningHandler.CountTotalApprovalHistoryByStatus)
+			apiRoute.GET("/mp-plannings", scoped, c.MPPlanningHandler.FindAllHeadersPaginated)
+			apiRoute.GET("/mp-plannings/export", scoped, c.MPPlanningHandler.ExportHeaders)
+			apiRoute.GET("/mp-plannings/something", scoped, c.MPPlanningHandler.FindHeaderBySomething)
+			apiRoute.GET("/mp-plannings/get-something", scoped, c.MPPlanningHandler.GetHeadersBySomething)
+			apiRoute.GET("/mp-plannings/status-period", scoped, c.MPPlanningHandler.FindAllHeadersByStatusAndMPPeriodID)
+			apiRoute.GET("/mp-plannings/completed", scoped, c.MPPlanningHandler.GetHeadersByMPPeriodCompleted)
+			apiRoute.GET("/mp-plannings/total-histories", scoped, c.MPPlanningHandler.CountTotalApprovalHistoryByStatus)
 			apiRoute.GET("/mp-plannings/document-number", c.MPPlanningHandler.GenerateDocumentNumber)
-			apiRoute.GET("/mp-plannings/requestor", c.MPPlanningHandler.FindAllHeadersByRequestorIDPaginated)
-			apiRoute.GET("/mp-plannings/batch", c.MPPlanningHandler.FindAllHeadersForBatchPaginated)
+			apiRoute.GET("/mp-plannings/requestor", scoped, c.MPPlanningHandler.FindAllHeadersByRequestorIDPaginated)
+			apiRoute.GET("/mp-plannings/batch", scoped, c.MPPlanningHandler.FindAllHeadersForBatchPaginated)
 			apiRoute.GET("/mp-plannings/count", c.MPPlanningHandler.CountMPPlanningHeaderByMPPPeriodIDAndApproverType)
-			apiRoute.GET("/mp-plannings/approver-type", c.MPPlanningHandler.FindAllHeadersGroupedApproverPaginated)
-			apiRoute.GET("/mp-plannings/jobs/:header_id", c.MPPlanningHandler.FindJobsByHeaderID)
-			apiRoute.GET("/mp-plannings/locations/:header_id", c.MPPlanningHandler.FindOrganizationLocationsByHeaderID)
-			apiRoute.GET("/mp-plannings/mpp-period/:mpp_period_id", c.MPPlanningHandler.FindHeaderByMPPPeriodId)
-			apiRoute.GET("/mp-plannings/approval-attachments/:approval_history_id", c.MPPlanningHandler.GetPlanningApprovalHistoryAttachmentsByApprovalHistoryId)
-			apiRoute.GET("/mp-plannings/approval-histories/:header_id", c.MPPlanningHandler.GetPlanningApprovalHistoryByHeaderId)
-			apiRoute.GET("/mp-plannings/:id", c.MPPlanningHandler.FindById)
-			apiRoute.POST("/mp-plannings", c.MPPlanningHandler.Create)
-			apiRoute.PUT("/mp-plannings", c.MPPlanningHandler.Update)
-			apiRoute.PUT("/mp-plannings/update-status", c.MPPlanningHandler.UpdateStatusMPPPlanningHeader)
-			apiRoute.DELETE("/mp-plannings/:id", c.MPPlanningHandler.Delete)
+			apiRoute.GET("/mp-plannings/approver-type", scoped, c.MPPlanningHandler.FindAllHeadersGroupedApproverPaginated)
+			apiRoute.GET("/mp-plannings/jobs/:header_id", scoped, c.MPPlanningHandler.FindJobsByHeaderID)
+			apiRoute.GET("/mp-plannings/locations/:header_id", scoped, c.MPPlanningHandler.FindOrganizationLocationsByHeaderID)
+			apiRoute.GET("/mp-plannings/mpp-period/:mpp_period_id", scoped, c.MPPlanningHandler.FindHeaderByMPPPeriodId)
+			apiRoute.GET("/mp-plannings/approval-attachments/:approval_history_id", scoped, c.MPPlanningHandler.GetPlanningApprovalHistoryAttachmentsByApprovalHistoryId)
+			apiRoute.GET("/mp-plannings/approval-histories/:header_id", scoped, c.MPPlanningHandler.GetPlanningApprovalHistoryByHeaderId)
+			apiRoute.GET("/mp-plannings/:id", scoped, c.MPPlanningHandler.FindById)
+			apiRoute.POST("/mp-plannings", can("create-mpp"), scoped, c.MPPlanningHandler.Create)
+			apiRoute.POST("/mp-plannings/copy-from-period", can("create-mpp"), scoped, c.MPPlanningHandler.CopyFromPeriod)
+			apiRoute.PUT("/mp-plannings", can("create-mpp"), scoped, c.MPPlanningHandler.Update)
+			apiRoute.PUT("/mp-plannings/update-status", can("create-mpp", "approve-mpp"), scoped, c.MPPlanningHandler.UpdateStatusMPPPlanningHeader)
+			apiRoute.DELETE("/mp-plannings/:id", can("create-mpp"), scoped, c.MPPlanningHandler.Delete)
 
 			// mp planning lines
-			apiRoute.GET("/mp-plannings/lines/find/:id", c.MPPlanningHandler.FindLineById)
-			apiRoute.GET("/mp-plannings/lines/:header_id", c.MPPlanningHandler.FindAllLinesByHeaderIdPaginated)
-			apiRoute.POST("/mp-plannings/lines/store", c.MPPlanningHandler.CreateLine)
-			apiRoute.PUT("/mp-plannings/lines/update", c.MPPlanningHandler.UpdateLine)
-			apiRoute.PUT("/mp-plannings/lines/reject-partial", c.MPPlanningHandler.RejectStatusPartialMPPlanningHeader)
-			apiRoute.PUT("/mp-plannings/lines/reject-partial-pt", c.MPPlanningHandler.RejectStatusPartialMPPlanningHeaderUsingPT)
-			apiRoute.DELETE("/mp-plannings/lines/delete/:id", c.MPPlanningHandler.DeleteLine)
-			apiRoute.POST("/mp-plannings/lines/batch/store", c.MPPlanningHandler.CreateOrUpdateBatchLineMPPlanningLines)
+			apiRoute.GET("/mp-plannings/lines/find/:id", scoped, c.MPPlanningHandler.FindLineById)
+			apiRoute.GET("/mp-plannings/lines/:header_id", scoped, c.MPPlanningHandler.FindAllLinesByHeaderIdPaginated)
+			apiRoute.POST("/mp-plannings/lines/store", can("create-mpp"), scoped, c.MPPlanningHandler.CreateLine)
+			apiRoute.PUT("/mp-plannings/lines/update", can("create-mpp"), scoped, c.MPPlanningHandler.UpdateLine)
+			apiRoute.PUT("/mp-plannings/lines/reject-partial", can("approve-mpp"), scoped, c.MPPlanningHandler.RejectStatusPartialMPPlanningHeader)
+			apiRoute.PUT("/mp-plannings/lines/reject-partial-pt", can("approve-mpp"), scoped, c.MPPlanningHandler.RejectStatusPartialMPPlanningHeaderUsingPT)
+			apiRoute.DELETE("/mp-plannings/lines/delete/:id", can("create-mpp"), scoped, c.MPPlanningHandler.DeleteLine)
+			apiRoute.POST("/mp-plannings/lines/batch/store", can("approve-mpp"), scoped, c.MPPlanningHandler.CreateOrUpdateBatchLineMPPlanningLines)
+			apiRoute.POST("/mp-plannings/lines/import", can("create-mpp"), scoped, c.MPPlanningHandler.ImportLines)
+			apiRoute.POST("/mp-plannings/lines/import/confirm", can("create-mpp"), scoped, c.MPPlanningHandler.ConfirmLineImport)
+			apiRoute.GET("/mp-plannings/lines/import/:id", scoped, c.MPPlanningHandler.FindLineImportById)
+			apiRoute.GET("/mp-plannings/lines/budget-ledger/:id", scoped, c.BudgetLedgerHandler.FindAllByLinePaginated)
 
 			// request categories
 			apiRoute.GET("/request-categories", c.RequestCategoryHandler.FindAll)
@@ -106,32 +133,69 @@ func (c *RouteConfig) SetupAPIRoutes() {
 			apiRoute.GET("/majors/:id", c.MajorHandler.FindById)
 
 			// mp requests
-			apiRoute.GET("/mp-requests", c.MPRequestHandler.FindAllPaginated)
-			apiRoute.GET("/mp-requests/total-histories", c.MPRequestHandler.CountTotalApprovalHistoryByStatus)
+			apiRoute.GET("/mp-requests", scoped, c.MPRequestHandler.FindAllPaginated)
+			apiRoute.GET("/mp-requests/export", scoped, c.MPRequestHandler.Export)
+			apiRoute.GET("/mp-requests/total-histories", scoped, c.MPRequestHandler.CountTotalApprovalHistoryByStatus)
 			apiRoute.GET("/mp-requests/document-number", c.MPRequestHandler.GenerateDocumentNumber)
-			apiRoute.GET("/mp-requests/approval-histories", c.MPRequestHandler.GetRequestApprovalHistoryByHeaderId)
-			apiRoute.GET("/mp-requests/testing/:id", c.MPRequestHandler.FindByIDForTesting)
-			apiRoute.GET("/mp-requests/only/:id", c.MPRequestHandler.FindByIDOnly)
-			apiRoute.GET("/mp-requests/:id", c.MPRequestHandler.FindByID)
-			apiRoute.POST("/mp-requests", c.MPRequestHandler.Create)
-			apiRoute.PUT("/mp-requests/status", c.MPRequestHandler.UpdateStatusMPRequestHeader)
-			apiRoute.PUT("/mp-requests", c.MPRequestHandler.Update)
-			apiRoute.DELETE("/mp-requests/:id", c.MPRequestHandler.Delete)
+			apiRoute.GET("/mp-requests/approval-histories", scoped, c.MPRequestHandler.GetRequestApprovalHistoryByHeaderId)
+			apiRoute.GET("/mp-requests/testing/:id", scoped, c.MPRequestHandler.FindByIDForTesting)
+			apiRoute.GET("/mp-requests/only/:id", scoped, c.MPRequestHandler.FindByIDOnly)
+			apiRoute.GET("/mp-requests/pdf/:id", scoped, c.MPRequestHandler.RenderPDF)
+			apiRoute.GET("/mp-requests/:id", scoped, c.MPRequestHandler.FindByID)
+			apiRoute.POST("/mp-requests", can("create-mpr"), scoped, c.MPRequestHandler.Create)
+			apiRoute.PUT("/mp-requests/status", can("create-mpr", "approve-mpr"), scoped, c.MPRequestHandler.UpdateStatusMPRequestHeader)
+			apiRoute.PUT("/mp-requests", can("create-mpr"), scoped, c.MPRequestHandler.Update)
+			apiRoute.DELETE("/mp-requests/:id", can("create-mpr"), scoped, c.MPRequestHandler.Delete)
 
 			// batch
-			apiRoute.POST("/batch/create", c.BatchHandler.CreateBatchHeaderAndLines)
-			apiRoute.GET("/batch/trigger-create", c.BatchHandler.TriggerCreate)
-			apiRoute.GET("/batch/batched-list", c.BatchHandler.GetBatchedMPPlanningHeaders)
-			apiRoute.GET("/batch/completed", c.BatchHandler.GetCompletedBatchHeader)
-			apiRoute.GET("/batch/need-approval", c.BatchHandler.FindByNeedApproval)
-			apiRoute.GET("/batch/organizations/:id", c.BatchHandler.GetOrganizationsForBatchApproval)
-			apiRoute.GET("/batch/find-by-status/:status", c.BatchHandler.FindByStatus)
-			apiRoute.GET("/batch/status", c.BatchHandler.GetBatchHeadersByStatus)
-			apiRoute.GET("/batch/mp-plannings/:id", c.BatchHandler.MPPlanningDetailByBatchHeaderPaginated)
-			apiRoute.GET("/batch/find-document/:id", c.BatchHandler.FindDocumentByID)
-			apiRoute.GET("/batch/current-status/:status", c.BatchHandler.FindByCurrentDocumentDateAndStatus)
-			apiRoute.GET("/batch/:id", c.BatchHandler.FindById)
-			apiRoute.PUT("/batch/update-status", c.BatchHandler.UpdateStatusBatchHeader)
+			apiRoute.POST("/batch/create", can("approve-mpp"), scoped, c.BatchHandler.CreateBatchHeaderAndLines)
+			apiRoute.GET("/batch/trigger-create", scoped, c.BatchHandler.TriggerCreate)
+			apiRoute.GET("/batch/batched-list", scoped, c.BatchHandler.GetBatchedMPPlanningHeaders)
+			apiRoute.GET("/batch/completed", scoped, c.BatchHandler.GetCompletedBatchHeader)
+			apiRoute.GET("/batch/need-approval", scoped, c.BatchHandler.FindByNeedApproval)
+			apiRoute.GET("/batch/organizations/:id", scoped, c.BatchHandler.GetOrganizationsForBatchApproval)
+			apiRoute.GET("/batch/find-by-status/:status", scoped, c.BatchHandler.FindByStatus)
+			apiRoute.GET("/batch/status", scoped, c.BatchHandler.GetBatchHeadersByStatus)
+			apiRoute.GET("/batch/mp-plannings/:id", scoped, c.BatchHandler.MPPlanningDetailByBatchHeaderPaginated)
+			apiRoute.GET("/batch/find-document/:id", scoped, c.BatchHandler.FindDocumentByID)
+			apiRoute.GET("/batch/export-document/:id", scoped, c.BatchHandler.ExportDocument)
+			apiRoute.GET("/batch/document-pdf/:id", scoped, c.BatchHandler.RenderDocumentPDF)
+			apiRoute.GET("/batch/current-status/:status", scoped, c.BatchHandler.FindByCurrentDocumentDateAndStatus)
+			apiRoute.GET("/batch/:id", scoped, c.BatchHandler.FindById)
+			apiRoute.PUT("/batch/update-status", can("approve-batch-ceo", "approve-batch-director"), scoped, c.BatchHandler.UpdateStatusBatchHeader)
+
+			// approval chains
+			apiRoute.GET("/approval-chains", c.ApprovalChainHandler.FindAllPaginated)
+			apiRoute.GET("/approval-chains/:id", c.ApprovalChainHandler.FindById)
+			apiRoute.POST("/approval-chains", can("manage-approval-chain"), c.ApprovalChainHandler.Create)
+			apiRoute.PUT("/approval-chains", can("manage-approval-chain"), c.ApprovalChainHandler.Update)
+			apiRoute.DELETE("/approval-chains/:id", can("manage-approval-chain"), c.ApprovalChainHandler.Delete)
+
+			// approval delegations
+			apiRoute.GET("/approval-delegations", c.ApprovalDelegationHandler.FindAllPaginated)
+			apiRoute.GET("/approval-delegations/:id", c.ApprovalDelegationHandler.FindById)
+			apiRoute.POST("/approval-delegations", delegate, c.ApprovalDelegationHandler.Create)
+			apiRoute.PUT("/approval-delegations", delegate, c.ApprovalDelegationHandler.Update)
+			apiRoute.DELETE("/approval-delegations/:id", delegate, c.ApprovalDelegationHandler.Delete)
+
+			// approval slas
+			apiRoute.GET("/approval-slas", c.ApprovalSLAHandler.FindAllPaginated)
+			apiRoute.GET("/approval-slas/:id", c.ApprovalSLAHandler.FindById)
+			apiRoute.POST("/approval-slas", can("manage-approval-sla"), c.ApprovalSLAHandler.Create)
+			apiRoute.PUT("/approval-slas", can("manage-approval-sla"), c.ApprovalSLAHandler.Update)
+			apiRoute.DELETE("/approval-slas/:id", can("manage-approval-sla"), c.ApprovalSLAHandler.Delete)
+
+			// plafon overrides
+			apiRoute.GET("/plafon-overrides", c.PlafonOverrideHandler.FindAllPaginated)
+			apiRoute.GET("/plafon-overrides/:id", c.PlafonOverrideHandler.FindById)
+			apiRoute.PUT("/plafon-overrides/update-status", can("approve-plafon-override"), c.PlafonOverrideHandler.UpdateStatus)
+			// outbox messages
+			apiRoute.GET("/outbox-messages", can("read-outbox-message", "replay-outbox-message"), c.OutboxHandler.FindAllPaginated)
+			apiRoute.POST("/outbox-messages/:id/replay", can("replay-outbox-message"), c.OutboxHandler.Replay)
+			// reports
+			apiRoute.GET("/reports/plan-vs-actual", scoped, c.ReportHandler.PlanVsActual)
+			// audit log
+			apiRoute.GET("/audit", can("read-audit-log"), c.AuditLogHandler.FindAllPaginated)
 		}
 	}
 }
@@ -145,18 +209,38 @@ func NewRouteConfig(app *gin.Engine, viper *viper.Viper, log *logrus.Logger) *Ro
 	majorHandler := handler.MajorHandlerFactory(log, viper)
 	mpRequestHandler := handler.MPRequestHandlerFactory(log, viper)
 	batchHandler := handler.BatchHandlerFactory(log, viper)
+	approvalChainHandler := handler.ApprovalChainHandlerFactory(log, viper)
+	approvalDelegationHandler := handler.ApprovalDelegationHandlerFactory(log, viper)
+	approvalSLAHandler := handler.ApprovalSLAHandlerFactory(log, viper)
+	plafonOverrideHandler := handler.PlafonOverrideHandlerFactory(log, viper)
+	budgetLedgerHandler := handler.BudgetLedgerHandlerFactory(log, viper)
+	reportHandler := handler.ReportHandlerFactory(log, viper)
+	outboxHandler := handler.OutboxHandlerFactory(log, viper)
+	healthHandler := handler.HealthHandlerFactory(log, viper)
+	auditLogHandler := handler.AuditLogHandlerFactory(log, viper)
 
 	// facroty middleware
 	authMiddleware := middleware.NewAuth(viper)
+	permissionMiddleware := middleware.PermissionMiddlewareFactory(viper, log)
 	return &RouteConfig{
-		App:                    app,
-		MPPPeriodHandler:       mppPeriodHandler,
-		AuthMiddleware:         authMiddleware,
-		JobPlafonHandler:       jobPlafonHandler,
-		MPPlanningHandler:      mpPlanningHandler,
-		RequestCategoryHandler: requestCategoryHandler,
-		MajorHandler:           majorHandler,
-		MPRequestHandler:       mpRequestHandler,
-		BatchHandler:           batchHandler,
+		App:                       app,
+		MPPPeriodHandler:          mppPeriodHandler,
+		AuthMiddleware:            authMiddleware,
+		PermissionMiddleware:      permissionMiddleware,
+		JobPlafonHandler:          jobPlafonHandler,
+		MPPlanningHandler:         mpPlanningHandler,
+		RequestCategoryHandler:    requestCategoryHandler,
+		MajorHandler:              majorHandler,
+		MPRequestHandler:          mpRequestHandler,
+		BatchHandler:              batchHandler,
+		ApprovalChainHandler:      approvalChainHandler,
+		ApprovalDelegationHandler: approvalDelegationHandler,
+		ApprovalSLAHandler:        approvalSLAHandler,
+		PlafonOverrideHandler:     plafonOverrideHandler,
+		BudgetLedgerHandler:       budgetLedgerHandler,
+		ReportHandler:             reportHandler,
+		OutboxHandler:             outboxHandler,
+		HealthHandler:             healthHandler,
+		AuditLogHandler:           auditLogHandler,
 	}
 }
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IApprovalSLAScheduler interface {
+	ProcessOverdueApprovals() error
+}
+
+type ApprovalSLAScheduler struct {
+	Log                *logrus.Logger
+	ApprovalSLAUseCase usecase.IApprovalSLAUseCase
+}
+
+func NewApprovalSLAScheduler(log *logrus.Logger, uc usecase.IApprovalSLAUseCase) IApprovalSLAScheduler {
+	return &ApprovalSLAScheduler{
+		Log:                log,
+		ApprovalSLAUseCase: uc,
+	}
+}
+
+func (s *ApprovalSLAScheduler) ProcessOverdueApprovals() error {
+	dateNow := time.Now()
+
+	err := s.ApprovalSLAUseCase.ProcessOverdueApprovals(dateNow)
+	if err != nil {
+		s.Log.Errorf("[ApprovalSLAScheduler.ProcessOverdueApprovals] " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func ApprovalSLASchedulerFactory(viper *viper.Viper, log *logrus.Logger) IApprovalSLAScheduler {
+	uc := usecase.ApprovalSLAUseCaseFactory(viper, log)
+	return NewApprovalSLAScheduler(log, uc)
+}
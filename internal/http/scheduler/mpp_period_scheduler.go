@@ -1,8 +1,7 @@
 package scheduler
 
 import (
-	"time"
-
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
 	"github.com/sirupsen/logrus"
 )
@@ -25,7 +24,7 @@ func NewMPPPeriodScheduler(log *logrus.Logger, uc usecase.IMPPPeriodUseCase) IMP
 }
 
 func (s *MPPPeriodScheduler) UpdateStatusToOpenByDate() error {
-	dateNow := time.Now()
+	dateNow := timezone.Now()
 
 	err := s.MPPPeriodUseCase.UpdateStatusToOpenByDate(dateNow)
 	if err != nil {
@@ -37,7 +36,7 @@ func (s *MPPPeriodScheduler) UpdateStatusToOpenByDate() error {
 }
 
 func (s *MPPPeriodScheduler) UpdateStatusToCloseByDate() error {
-	dateNow := time.Now()
+	dateNow := timezone.Now()
 
 	err := s.MPPPeriodUseCase.UpdateStatusToCloseByDate(dateNow)
 	if err != nil {
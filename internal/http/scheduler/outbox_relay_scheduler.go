@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IOutboxRelayScheduler interface {
+	RelayPending() error
+}
+
+type OutboxRelayScheduler struct {
+	Log           *logrus.Logger
+	OutboxUseCase usecase.IOutboxUseCase
+}
+
+func NewOutboxRelayScheduler(log *logrus.Logger, uc usecase.IOutboxUseCase) IOutboxRelayScheduler {
+	return &OutboxRelayScheduler{
+		Log:           log,
+		OutboxUseCase: uc,
+	}
+}
+
+func (s *OutboxRelayScheduler) RelayPending() error {
+	dateNow := time.Now()
+
+	err := s.OutboxUseCase.RelayPending(dateNow)
+	if err != nil {
+		s.Log.Errorf("[OutboxRelayScheduler.RelayPending] " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func OutboxRelaySchedulerFactory(viper *viper.Viper, log *logrus.Logger) IOutboxRelayScheduler {
+	uc := usecase.OutboxUseCaseFactory(viper, log)
+	return NewOutboxRelayScheduler(log, uc)
+}
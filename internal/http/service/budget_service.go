@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// IBudgetService builds the budget ledger entries of a manpower request on the
+// lines of its planning. Reserve runs when a request is submitted and fails
+// with workflow.ErrOverConsumption when the lines have too little left;
+// Consume runs when HRD HO completes it and Release when it is rejected,
+// sent back to draft or deleted. The entries are returned, not saved, so they
+// can be written in the same transaction as the change to the request, with
+// the service bound to its ledger by WithLedger; Record saves them on their
+// own.
+type IBudgetService interface {
+	Reserve(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus) ([]entity.BudgetLedgerEntry, error)
+	Consume(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus) ([]entity.BudgetLedgerEntry, error)
+	Release(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus) ([]entity.BudgetLedgerEntry, error)
+	Record(entries []entity.BudgetLedgerEntry) error
+	Backfill() error
+	WithLedger(ledger repository.IBudgetLedgerRepository) IBudgetService
+	WithContext(ctx context.Context) IBudgetService
+}
+
+type BudgetService struct {
+	Viper                  *viper.Viper
+	Log                    *logrus.Logger
+	BudgetLedgerRepository repository.IBudgetLedgerRepository
+	MPPlanningRepository   repository.IMPPlanningRepository
+}
+
+func NewBudgetService(viper *viper.Viper, log *logrus.Logger, budgetLedgerRepository repository.IBudgetLedgerRepository, mpPlanningRepository repository.IMPPlanningRepository) IBudgetService {
+	return &BudgetService{
+		Viper:                  viper,
+		Log:                    log,
+		BudgetLedgerRepository: budgetLedgerRepository,
+		MPPlanningRepository:   mpPlanningRepository,
+	}
+}
+
+// WithLedger is the service reading and locking through ledger, the ledger of
+// the transaction the entries it builds are saved in.
+func (s *BudgetService) WithLedger(ledger repository.IBudgetLedgerRepository) IBudgetService {
+	bound := *s
+	bound.BudgetLedgerRepository = ledger
+	return &bound
+}
+
+// WithContext is the service with its repositories run under ctx.
+func (s *BudgetService) WithContext(ctx context.Context) IBudgetService {
+	bound := *s
+	bound.BudgetLedgerRepository = s.BudgetLedgerRepository.WithContext(ctx)
+	bound.MPPlanningRepository = s.MPPlanningRepository.WithContext(ctx)
+	return &bound
+}
+
+// Reserve releases whatever the request still holds and reserves its total
+// needs again, so a resubmitted or edited request never counts twice.
+func (s *BudgetService) Reserve(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus) ([]entity.BudgetLedgerEntry, error) {
+	if !tracksBudget(mpRequestHeader) {
+		return nil, nil
+	}
+
+	entries, err := s.Release(mpRequestHeader, status)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := s.allocate(mpRequestHeader, true)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := workflow.BudgetBalanceFor(mpRequestHeader.RecruitmentType)
+	for _, allocation := range allocations {
+		entries = append(entries, entity.BudgetLedgerEntry{
+			MPRequestHeaderID: mpRequestHeader.ID,
+			MPPlanningLineID:  allocation.MPPlanningLineID,
+			EntryType:         entity.BudgetLedgerEntryTypeReservation,
+			Balance:           balance,
+			Quantity:          allocation.Quantity,
+			Reserved:          allocation.Quantity,
+			MPRequestStatus:   status,
+		})
+	}
+
+	return entries, nil
+}
+
+// Consume turns the reservation of the request into consumption. A request
+// that never reserved anything, because it was submitted before the ledger
+// existed, consumes its needs without a balance check.
+func (s *BudgetService) Consume(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus) ([]entity.BudgetLedgerEntry, error) {
+	if !tracksBudget(mpRequestHeader) {
+		return nil, nil
+	}
+
+	held, err := s.BudgetLedgerRepository.SumByMPRequestID(mpRequestHeader.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entity.BudgetLedgerEntry
+	for _, sum := range held {
+		if sum.Reserved == 0 {
+			continue
+		}
+		entries = append(entries, entity.BudgetLedgerEntry{
+			MPRequestHeaderID: mpRequestHeader.ID,
+			MPPlanningLineID:  sum.MPPlanningLineID,
+			EntryType:         entity.BudgetLedgerEntryTypeConsumption,
+			Balance:           sum.Balance,
+			Quantity:          sum.Reserved,
+			Reserved:          -sum.Reserved,
+			Consumed:          sum.Reserved,
+			MPRequestStatus:   status,
+		})
+	}
+
+	if len(held) > 0 {
+		return entries, nil
+	}
+
+	allocations, err := s.allocate(mpRequestHeader, false)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := workflow.BudgetBalanceFor(mpRequestHeader.RecruitmentType)
+	for _, allocation := range allocations {
+		entries = append(entries, entity.BudgetLedgerEntry{
+			MPRequestHeaderID: mpRequestHeader.ID,
+			MPPlanningLineID:  allocation.MPPlanningLineID,
+			EntryType:         entity.BudgetLedgerEntryTypeConsumption,
+			Balance:           balance,
+			Quantity:          allocation.Quantity,
+			Consumed:          allocation.Quantity,
+			MPRequestStatus:   status,
+		})
+	}
+
+	return entries, nil
+}
+
+// Release gives back everything the request holds, reserved or consumed,
+// with its planning lines locked.
+func (s *BudgetService) Release(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus) ([]entity.BudgetLedgerEntry, error) {
+	if mpRequestHeader.ID == uuid.Nil {
+		return nil, nil
+	}
+
+	held, err := s.BudgetLedgerRepository.SumByMPRequestID(mpRequestHeader.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	lineIDs := make([]uuid.UUID, 0, len(held))
+	for _, sum := range held {
+		lineIDs = append(lineIDs, sum.MPPlanningLineID)
+	}
+	if err := s.BudgetLedgerRepository.LockLines(lineIDs); err != nil {
+		return nil, err
+	}
+
+	var entries []entity.BudgetLedgerEntry
+	for _, sum := range held {
+		if sum.Reserved == 0 && sum.Consumed == 0 {
+			continue
+		}
+		entries = append(entries, entity.BudgetLedgerEntry{
+			MPRequestHeaderID: mpRequestHeader.ID,
+			MPPlanningLineID:  sum.MPPlanningLineID,
+			EntryType:         entity.BudgetLedgerEntryTypeRelease,
+			Balance:           sum.Balance,
+			Quantity:          sum.Reserved + sum.Consumed,
+			Reserved:          -sum.Reserved,
+			Consumed:          -sum.Consumed,
+			MPRequestStatus:   status,
+		})
+	}
+
+	return entries, nil
+}
+
+func (s *BudgetService) Record(entries []entity.BudgetLedgerEntry) error {
+	return s.BudgetLedgerRepository.Create(entries)
+}
+
+// Backfill records the requests that held or consumed budget before the
+// ledger existed, then recomputes every remaining balance from the ledger.
+// It is safe to run again: requests that already have entries are skipped.
+func (s *BudgetService) Backfill() error {
+	mpRequestHeaders, err := s.BudgetLedgerRepository.FindUntrackedMPRequests([]entity.MPRequestStatus{
+		entity.MPRequestStatusSubmitted,
+		entity.MPRequestStatusNeedApproval,
+		entity.MPRequestStatusApproved,
+		entity.MPRequestStatusInProgress,
+		entity.MPRequestStatusCompleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range *mpRequestHeaders {
+		mpRequestHeader := &(*mpRequestHeaders)[i]
+
+		var entries []entity.BudgetLedgerEntry
+		if mpRequestHeader.Status == entity.MPRequestStatusCompleted {
+			entries, err = s.Consume(mpRequestHeader, mpRequestHeader.Status)
+		} else {
+			entries, err = s.reserveUnchecked(mpRequestHeader)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.Record(entries); err != nil {
+			return err
+		}
+	}
+
+	s.Log.Infof("[BudgetService.Backfill] %d mp requests recorded in the budget ledger", len(*mpRequestHeaders))
+	return s.BudgetLedgerRepository.RefreshAllRemainingBalances()
+}
+
+func (s *BudgetService) reserveUnchecked(mpRequestHeader *entity.MPRequestHeader) ([]entity.BudgetLedgerEntry, error) {
+	allocations, err := s.allocate(mpRequestHeader, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entity.BudgetLedgerEntry
+	balance := workflow.BudgetBalanceFor(mpRequestHeader.RecruitmentType)
+	for _, allocation := range allocations {
+		entries = append(entries, entity.BudgetLedgerEntry{
+			MPRequestHeaderID: mpRequestHeader.ID,
+			MPPlanningLineID:  allocation.MPPlanningLineID,
+			EntryType:         entity.BudgetLedgerEntryTypeReservation,
+			Balance:           balance,
+			Quantity:          allocation.Quantity,
+			Reserved:          allocation.Quantity,
+			MPRequestStatus:   mpRequestHeader.Status,
+		})
+	}
+
+	return entries, nil
+}
+
+// allocate spreads the total needs of the request over the planning lines of
+// its job, lines at its job level and location first. What the request holds
+// itself is not counted against the lines. The lines are locked before their
+// ledger is summed, so a concurrent request waits for this one to be saved.
+func (s *BudgetService) allocate(mpRequestHeader *entity.MPRequestHeader, strict bool) ([]workflow.BudgetAllocation, error) {
+	mpPlanningLines, err := s.MPPlanningRepository.GetLinesByHeaderAndJobID(*mpRequestHeader.MPPlanningHeaderID, *mpRequestHeader.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if mpPlanningLines == nil {
+		mpPlanningLines = &[]entity.MPPlanningLine{}
+	}
+
+	lines := *mpPlanningLines
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lineMatch(mpRequestHeader, &lines[i]) > lineMatch(mpRequestHeader, &lines[j])
+	})
+
+	lineIDs := make([]uuid.UUID, 0, len(lines))
+	for _, line := range lines {
+		lineIDs = append(lineIDs, line.ID)
+	}
+
+	if err := s.BudgetLedgerRepository.LockLines(lineIDs); err != nil {
+		return nil, err
+	}
+
+	var excludeMPRequestID *uuid.UUID
+	if mpRequestHeader.ID != uuid.Nil {
+		excludeMPRequestID = &mpRequestHeader.ID
+	}
+
+	sums, err := s.BudgetLedgerRepository.SumByLineIDs(lineIDs, excludeMPRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := workflow.BudgetBalanceFor(mpRequestHeader.RecruitmentType)
+	held := make(map[uuid.UUID]int)
+	for _, sum := range sums {
+		if sum.Balance == balance {
+			held[sum.MPPlanningLineID] += sum.Reserved + sum.Consumed
+		}
+	}
+
+	budgetLines := make([]workflow.BudgetLine, 0, len(lines))
+	for _, line := range lines {
+		recruit := line.RecruitPH
+		if balance == entity.BudgetBalanceMT {
+			recruit = line.RecruitMT
+		}
+		budgetLines = append(budgetLines, workflow.BudgetLine{
+			MPPlanningLineID: line.ID,
+			Available:        recruit - held[line.ID],
+		})
+	}
+
+	return workflow.AllocateBudget(balance, budgetLines, mpRequestHeader.TotalNeeds, strict)
+}
+
+// tracksBudget reports whether the request draws on a planning. Off budget
+// requests and requests without a planning are outside the ledger.
+func tracksBudget(mpRequestHeader *entity.MPRequestHeader) bool {
+	return mpRequestHeader.MPRequestType != entity.MPRequestTypeEnumOffBudget &&
+		mpRequestHeader.MPPlanningHeaderID != nil &&
+		mpRequestHeader.JobID != nil &&
+		mpRequestHeader.TotalNeeds > 0
+}
+
+func lineMatch(mpRequestHeader *entity.MPRequestHeader, line *entity.MPPlanningLine) int {
+	match := 0
+	if mpRequestHeader.JobLevelID != nil && line.JobLevelID != nil && *mpRequestHeader.JobLevelID == *line.JobLevelID {
+		match += 2
+	}
+	if mpRequestHeader.ForOrganizationLocationID != nil && line.OrganizationLocationID != nil && *mpRequestHeader.ForOrganizationLocationID == *line.OrganizationLocationID {
+		match++
+	}
+	return match
+}
+
+func BudgetServiceFactory(viper *viper.Viper, log *logrus.Logger) IBudgetService {
+	budgetLedgerRepository := repository.BudgetLedgerRepositoryFactory(log)
+	mpPlanningRepository := repository.MPPlanningRepositoryFactory(log)
+	return NewBudgetService(viper, log, budgetLedgerRepository, mpPlanningRepository)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// fakeBudgetLedger is a ledger holding sums; it records the lines it locked.
+type fakeBudgetLedger struct {
+	repository.IBudgetLedgerRepository
+	byLine    []repository.BudgetLedgerSum
+	byRequest []repository.BudgetLedgerSum
+	locked    []uuid.UUID
+}
+
+func (l *fakeBudgetLedger) LockLines(lineIDs []uuid.UUID) error {
+	l.locked = append(l.locked, lineIDs...)
+	return nil
+}
+
+func (l *fakeBudgetLedger) SumByLineIDs(lineIDs []uuid.UUID, excludeMPRequestID *uuid.UUID) ([]repository.BudgetLedgerSum, error) {
+	return l.byLine, nil
+}
+
+func (l *fakeBudgetLedger) SumByMPRequestID(mpRequestID uuid.UUID) ([]repository.BudgetLedgerSum, error) {
+	return l.byRequest, nil
+}
+
+type fakePlanningLines struct {
+	repository.IMPPlanningRepository
+	lines []entity.MPPlanningLine
+}
+
+func (r *fakePlanningLines) GetLinesByHeaderAndJobID(headerID uuid.UUID, jobID uuid.UUID) (*[]entity.MPPlanningLine, error) {
+	lines := append([]entity.MPPlanningLine(nil), r.lines...)
+	return &lines, nil
+}
+
+func budgetService(ledger *fakeBudgetLedger, lines []entity.MPPlanningLine) IBudgetService {
+	return NewBudgetService(viper.New(), logrus.New(), ledger, &fakePlanningLines{lines: lines})
+}
+
+// budgetTest is a request for 3 professional hires at the job level and
+// location of the second of two planning lines.
+func budgetTest() (*entity.MPRequestHeader, []entity.MPPlanningLine) {
+	headerID, jobID, jobLevelID, locationID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	otherLevelID := uuid.New()
+	mpRequestHeader := &entity.MPRequestHeader{
+		ID:                     uuid.New(),
+		MPRequestType:          entity.MPRequestTypeEnumOnBudget,
+		RecruitmentType:        entity.RecruitmentTypeEnumPH,
+		MPPlanningHeaderID:     &headerID,
+		JobID:                  &jobID,
+		JobLevelID:             &jobLevelID,
+		OrganizationLocationID: &locationID,
+		TotalNeeds:             3,
+	}
+	lines := []entity.MPPlanningLine{
+		{ID: uuid.New(), JobID: &jobID, JobLevelID: &otherLevelID, RecruitPH: 5, RecruitMT: 1},
+		{ID: uuid.New(), JobID: &jobID, JobLevelID: &jobLevelID, OrganizationLocationID: &locationID, RecruitPH: 2, RecruitMT: 1},
+	}
+	return mpRequestHeader, lines
+}
+
+func TestBudgetServiceReserve(t *testing.T) {
+	mpRequestHeader, lines := budgetTest()
+	other, matching := lines[0].ID, lines[1].ID
+
+	tests := []struct {
+		name      string
+		byLine    []repository.BudgetLedgerSum
+		byRequest []repository.BudgetLedgerSum
+		want      []entity.BudgetLedgerEntry
+		wantErr   error
+	}{
+		{"matching line first", nil, nil, []entity.BudgetLedgerEntry{
+			{MPPlanningLineID: matching, EntryType: entity.BudgetLedgerEntryTypeReservation, Balance: entity.BudgetBalancePH, Quantity: 2, Reserved: 2},
+			{MPPlanningLineID: other, EntryType: entity.BudgetLedgerEntryTypeReservation, Balance: entity.BudgetBalancePH, Quantity: 1, Reserved: 1},
+		}, nil},
+		{"what others hold is left out", []repository.BudgetLedgerSum{
+			{MPPlanningLineID: matching, Balance: entity.BudgetBalancePH, Consumed: 2},
+			{MPPlanningLineID: other, Balance: entity.BudgetBalanceMT, Reserved: 1},
+		}, nil, []entity.BudgetLedgerEntry{
+			{MPPlanningLineID: other, EntryType: entity.BudgetLedgerEntryTypeReservation, Balance: entity.BudgetBalancePH, Quantity: 3, Reserved: 3},
+		}, nil},
+		{"a resubmitted request gives back what it held first", nil, []repository.BudgetLedgerSum{
+			{MPPlanningLineID: matching, Balance: entity.BudgetBalancePH, Reserved: 2},
+		}, []entity.BudgetLedgerEntry{
+			{MPPlanningLineID: matching, EntryType: entity.BudgetLedgerEntryTypeRelease, Balance: entity.BudgetBalancePH, Quantity: 2, Reserved: -2},
+			{MPPlanningLineID: matching, EntryType: entity.BudgetLedgerEntryTypeReservation, Balance: entity.BudgetBalancePH, Quantity: 2, Reserved: 2},
+			{MPPlanningLineID: other, EntryType: entity.BudgetLedgerEntryTypeReservation, Balance: entity.BudgetBalancePH, Quantity: 1, Reserved: 1},
+		}, nil},
+		{"over the plan", []repository.BudgetLedgerSum{
+			{MPPlanningLineID: other, Balance: entity.BudgetBalancePH, Reserved: 5},
+		}, nil, nil, workflow.ErrOverConsumption},
+	}
+
+	for _, tt := range tests {
+		ledger := &fakeBudgetLedger{byLine: tt.byLine, byRequest: tt.byRequest}
+		got, err := budgetService(ledger, lines).Reserve(mpRequestHeader, entity.MPRequestStatusSubmitted)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for i := range tt.want {
+			tt.want[i].MPRequestHeaderID = mpRequestHeader.ID
+			tt.want[i].MPRequestStatus = entity.MPRequestStatusSubmitted
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: entries = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if len(ledger.locked) == 0 {
+			t.Errorf("%s: the planning lines were not locked", tt.name)
+		}
+	}
+}
+
+func TestBudgetServiceConsume(t *testing.T) {
+	mpRequestHeader, lines := budgetTest()
+	other, matching := lines[0].ID, lines[1].ID
+
+	tests := []struct {
+		name      string
+		byRequest []repository.BudgetLedgerSum
+		want      []entity.BudgetLedgerEntry
+	}{
+		{"the reservation is consumed", []repository.BudgetLedgerSum{
+			{MPPlanningLineID: matching, Balance: entity.BudgetBalancePH, Reserved: 2},
+			{MPPlanningLineID: other, Balance: entity.BudgetBalancePH, Reserved: 1},
+		}, []entity.BudgetLedgerEntry{
+			{MPPlanningLineID: matching, EntryType: entity.BudgetLedgerEntryTypeConsumption, Balance: entity.BudgetBalancePH, Quantity: 2, Reserved: -2, Consumed: 2},
+			{MPPlanningLineID: other, EntryType: entity.BudgetLedgerEntryTypeConsumption, Balance: entity.BudgetBalancePH, Quantity: 1, Reserved: -1, Consumed: 1},
+		}},
+		{"already consumed", []repository.BudgetLedgerSum{
+			{MPPlanningLineID: matching, Balance: entity.BudgetBalancePH, Consumed: 3},
+		}, nil},
+		{"never reserved", nil, []entity.BudgetLedgerEntry{
+			{MPPlanningLineID: matching, EntryType: entity.BudgetLedgerEntryTypeConsumption, Balance: entity.BudgetBalancePH, Quantity: 2, Consumed: 2},
+			{MPPlanningLineID: other, EntryType: entity.BudgetLedgerEntryTypeConsumption, Balance: entity.BudgetBalancePH, Quantity: 1, Consumed: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		got, err := budgetService(&fakeBudgetLedger{byRequest: tt.byRequest}, lines).Consume(mpRequestHeader, entity.MPRequestStatusCompleted)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for i := range tt.want {
+			tt.want[i].MPRequestHeaderID = mpRequestHeader.ID
+			tt.want[i].MPRequestStatus = entity.MPRequestStatusCompleted
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: entries = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBudgetServiceRelease(t *testing.T) {
+	mpRequestHeader, lines := budgetTest()
+	other, matching := lines[0].ID, lines[1].ID
+
+	ledger := &fakeBudgetLedger{byRequest: []repository.BudgetLedgerSum{
+		{MPPlanningLineID: matching, Balance: entity.BudgetBalancePH, Reserved: 2},
+		{MPPlanningLineID: other, Balance: entity.BudgetBalancePH, Consumed: 1},
+		{MPPlanningLineID: other, Balance: entity.BudgetBalanceMT},
+	}}
+	got, err := budgetService(ledger, lines).Release(mpRequestHeader, entity.MPRequestStatusRejected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []entity.BudgetLedgerEntry{
+		{MPRequestHeaderID: mpRequestHeader.ID, MPPlanningLineID: matching, EntryType: entity.BudgetLedgerEntryTypeRelease, Balance: entity.BudgetBalancePH, Quantity: 2, Reserved: -2, MPRequestStatus: entity.MPRequestStatusRejected},
+		{MPRequestHeaderID: mpRequestHeader.ID, MPPlanningLineID: other, EntryType: entity.BudgetLedgerEntryTypeRelease, Balance: entity.BudgetBalancePH, Quantity: 1, Consumed: -1, MPRequestStatus: entity.MPRequestStatusRejected},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+	if len(ledger.locked) == 0 {
+		t.Error("the planning lines were not locked")
+	}
+}
+
+func TestBudgetServiceUntracked(t *testing.T) {
+	offBudget, lines := budgetTest()
+	offBudget.MPRequestType = entity.MPRequestTypeEnumOffBudget
+	withoutPlanning, _ := budgetTest()
+	withoutPlanning.MPPlanningHeaderID = nil
+
+	for _, mpRequestHeader := range []*entity.MPRequestHeader{offBudget, withoutPlanning} {
+		s := budgetService(&fakeBudgetLedger{}, lines)
+		if got, err := s.Reserve(mpRequestHeader, entity.MPRequestStatusSubmitted); got != nil || err != nil {
+			t.Errorf("Reserve = %+v, %v, want nothing", got, err)
+		}
+		if got, err := s.Consume(mpRequestHeader, entity.MPRequestStatusCompleted); got != nil || err != nil {
+			t.Errorf("Consume = %+v, %v, want nothing", got, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// IDocumentNumberService decides how documents are numbered. The format of
+// each sequence comes from numbering.<sequence> in the config (prefix,
+// date_layout, padding and reset), falling back to the format the documents
+// have always had. Numbering is handed to the repository that creates the
+// document, which takes the number in its own transaction; Preview only shows
+// what the next number would be.
+type IDocumentNumberService interface {
+	Numbering(sequence workflow.NumberSequence) *workflow.DocumentNumbering
+	Preview(sequence workflow.NumberSequence, at time.Time) (string, error)
+}
+
+type DocumentNumberService struct {
+	Viper                      *viper.Viper
+	Log                        *logrus.Logger
+	DocumentSequenceRepository repository.IDocumentSequenceRepository
+}
+
+func NewDocumentNumberService(viper *viper.Viper, log *logrus.Logger, documentSequenceRepository repository.IDocumentSequenceRepository) IDocumentNumberService {
+	return &DocumentNumberService{
+		Viper:                      viper,
+		Log:                        log,
+		DocumentSequenceRepository: documentSequenceRepository,
+	}
+}
+
+// numberSequenceTables are where the documents of each sequence are kept.
+var numberSequenceTables = map[workflow.NumberSequence]string{
+	workflow.NumberSequenceMPPlanning:    entity.MPPlanningHeader{}.TableName(),
+	workflow.NumberSequenceMPRequest:     entity.MPRequestHeader{}.TableName(),
+	workflow.NumberSequenceBatchCEO:      entity.BatchHeader{}.TableName(),
+	workflow.NumberSequenceBatchDirector: entity.BatchHeader{}.TableName(),
+}
+
+// Numbering numbers a document created now.
+func (s *DocumentNumberService) Numbering(sequence workflow.NumberSequence) *workflow.DocumentNumbering {
+	return s.numbering(sequence, timezone.Now())
+}
+
+func (s *DocumentNumberService) Preview(sequence workflow.NumberSequence, at time.Time) (string, error) {
+	documentNumber, err := s.DocumentSequenceRepository.Peek(s.numbering(sequence, at))
+	if err != nil {
+		s.Log.Errorf("[DocumentNumberService.Preview] " + err.Error())
+		return "", err
+	}
+
+	return documentNumber, nil
+}
+
+func (s *DocumentNumberService) numbering(sequence workflow.NumberSequence, at time.Time) *workflow.DocumentNumbering {
+	return &workflow.DocumentNumbering{
+		Sequence: sequence,
+		Format:   s.format(sequence),
+		At:       at,
+		Table:    numberSequenceTables[sequence],
+	}
+}
+
+func (s *DocumentNumberService) format(sequence workflow.NumberSequence) workflow.DocumentNumberFormat {
+	format := workflow.DefaultDocumentNumberFormats[sequence]
+	key := "numbering." + string(sequence) + "."
+
+	if s.Viper.IsSet(key + "prefix") {
+		format.Prefix = s.Viper.GetString(key + "prefix")
+	}
+	if s.Viper.IsSet(key + "date_layout") {
+		format.DateLayout = s.Viper.GetString(key + "date_layout")
+	}
+	if s.Viper.IsSet(key + "padding") {
+		format.Padding = s.Viper.GetInt(key + "padding")
+	}
+	if s.Viper.IsSet(key + "reset") {
+		switch reset := workflow.DocumentNumberReset(s.Viper.GetString(key + "reset")); reset {
+		case workflow.DocumentNumberResetDaily, workflow.DocumentNumberResetMonthly, workflow.DocumentNumberResetYearly, workflow.DocumentNumberResetNever:
+			format.Reset = reset
+		default:
+			s.Log.Warnf("[DocumentNumberService.format] unknown reset %q of %s, using %s", reset, sequence, format.Reset)
+		}
+	}
+
+	return format
+}
+
+func DocumentNumberServiceFactory(viper *viper.Viper, log *logrus.Logger) IDocumentNumberService {
+	documentSequenceRepository := repository.DocumentSequenceRepositoryFactory(log)
+	return NewDocumentNumberService(viper, log, documentSequenceRepository)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestDocumentNumberServiceFormat(t *testing.T) {
+	config := viper.New()
+	config.Set("numbering.mp_request.prefix", "REQ")
+	config.Set("numbering.mp_request.padding", 5)
+	config.Set("numbering.mp_request.reset", "monthly")
+	config.Set("numbering.mp_planning.date_layout", "")
+	config.Set("numbering.batch_ceo.reset", "weekly")
+
+	log := logrus.New()
+	log.SetLevel(logrus.ErrorLevel)
+	s := &DocumentNumberService{Viper: config, Log: log}
+
+	tests := []struct {
+		sequence workflow.NumberSequence
+		want     workflow.DocumentNumberFormat
+	}{
+		{workflow.NumberSequenceMPRequest, workflow.DocumentNumberFormat{Prefix: "REQ", DateLayout: "20060102", Padding: 5, Reset: workflow.DocumentNumberResetMonthly}},
+		{workflow.NumberSequenceMPPlanning, workflow.DocumentNumberFormat{Prefix: "MPP", DateLayout: "", Padding: 3, Reset: workflow.DocumentNumberResetDaily}},
+		// an unknown reset keeps the default
+		{workflow.NumberSequenceBatchCEO, workflow.DefaultDocumentNumberFormats[workflow.NumberSequenceBatchCEO]},
+		{workflow.NumberSequenceBatchDirector, workflow.DefaultDocumentNumberFormats[workflow.NumberSequenceBatchDirector]},
+	}
+
+	for _, tt := range tests {
+		if got := s.format(tt.sequence); got != tt.want {
+			t.Errorf("format(%s) = %+v, want %+v", tt.sequence, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentNumberServiceNumbering(t *testing.T) {
+	s := &DocumentNumberService{Viper: viper.New(), Log: logrus.New()}
+	at := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+	numbering := s.numbering(workflow.NumberSequenceBatchDirector, at)
+	if numbering.Sequence != workflow.NumberSequenceBatchDirector || !numbering.At.Equal(at) {
+		t.Errorf("numbering = %+v", numbering)
+	}
+	if numbering.Table != "batch_headers" {
+		t.Errorf("director batches are numbered from %q, want batch_headers", numbering.Table)
+	}
+	if got := numbering.Format.Format(at, 1); got != "MPP/BATCH/DIR/20261017/001" {
+		t.Errorf("first number = %q", got)
+	}
+}
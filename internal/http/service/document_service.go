@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/pdf"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// IDocumentService keeps the printable documents generated for a record.
+// Every rendering is saved under storage and recorded as an attachment of the
+// record, so there is a trail of what was printed and when.
+type IDocumentService interface {
+	Letterhead(title string) pdf.Letterhead
+	StorePDF(report *pdf.Report, ownerType string, ownerID uuid.UUID, fileName string) (*entity.ManpowerAttachment, error)
+}
+
+type DocumentService struct {
+	Viper                        *viper.Viper
+	Log                          *logrus.Logger
+	ManpowerAttachmentRepository repository.IManpowerAttachmentRepository
+}
+
+func NewDocumentService(viper *viper.Viper, log *logrus.Logger, manpowerAttachmentRepository repository.IManpowerAttachmentRepository) IDocumentService {
+	return &DocumentService{
+		Viper:                        viper,
+		Log:                          log,
+		ManpowerAttachmentRepository: manpowerAttachmentRepository,
+	}
+}
+
+// Letterhead is the company letterhead of app.company_name and
+// app.company_address, with the title of the document.
+func (s *DocumentService) Letterhead(title string) pdf.Letterhead {
+	company := s.Viper.GetString("app.company_name")
+	if company == "" {
+		company = "Julong Group"
+	}
+
+	return pdf.Letterhead{
+		Company: company,
+		Address: s.Viper.GetString("app.company_address"),
+		Title:   title,
+	}
+}
+
+// StorePDF writes the report to storage/<owner>/documents and records it as an
+// attachment of the owner. The file name is what the attachment is called,
+// the file itself is named after the time it was made.
+func (s *DocumentService) StorePDF(report *pdf.Report, ownerType string, ownerID uuid.UUID, fileName string) (*entity.ManpowerAttachment, error) {
+	dir := filepath.Join("storage", documentDirs[ownerType], "documents")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		s.Log.Errorf("[DocumentService.StorePDF] " + err.Error())
+		return nil, err
+	}
+
+	filePath := filepath.ToSlash(filepath.Join(dir, fmt.Sprintf("%d.pdf", time.Now().UnixNano())))
+	file, err := os.Create(filePath)
+	if err != nil {
+		s.Log.Errorf("[DocumentService.StorePDF] " + err.Error())
+		return nil, err
+	}
+
+	err = report.Write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filePath)
+		s.Log.Errorf("[DocumentService.StorePDF] " + err.Error())
+		return nil, err
+	}
+
+	attachment, err := s.ManpowerAttachmentRepository.Create(&entity.ManpowerAttachment{
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+		FileName:  fileName,
+		FileType:  "application/pdf",
+		FilePath:  filePath,
+	})
+	if err != nil {
+		os.Remove(filePath)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// documentDirs are the storage folders of the owners, the same as their
+// uploaded attachments use.
+var documentDirs = map[string]string{
+	"batch_headers":      "batch_header",
+	"mp_request_headers": "mp_request_header",
+}
+
+func DocumentServiceFactory(viper *viper.Viper, log *logrus.Logger) IDocumentService {
+	manpowerAttachmentRepository := repository.ManpowerAttachmentRepositoryFactory(log)
+	return NewDocumentService(viper, log, manpowerAttachmentRepository)
+}
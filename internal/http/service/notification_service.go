@@ -1,36 +1,57 @@
 package service
 
 import (
+	"context"
 	"errors"
 
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
+// NotificationDestination is the outbox destination of notifications, which
+// the outbox relay delivers through the Julong notification service.
+const NotificationDestination = "julong_notification"
+
+// INotificationService builds notifications as outbox messages. The document
+// notifications are returned to the caller so they can be saved in the same
+// transaction as the status change; an empty slice means nobody is notified.
 type INotificationService interface {
-	CreatePeriodNotification(createdBy string) error
+	CreatePeriodNotification(ctx context.Context, createdBy string) error
+	NewSubmittedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error)
+	NewNeedApprovalNotification(ctx context.Context, document NotificationDocument, approverID *uuid.UUID) ([]entity.OutboxMessage, error)
+	NewApprovedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error)
+	NewRejectedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error)
+	NewCompletedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error)
+	NewBatchReadyForCEONotification(ctx context.Context, document NotificationDocument) ([]entity.OutboxMessage, error)
+	NewApprovalReminderNotification(ctx context.Context, document NotificationDocument, approverID *uuid.UUID, permissionName string) ([]entity.OutboxMessage, error)
+	NewApprovalEscalationNotification(ctx context.Context, document NotificationDocument, approverID *uuid.UUID, permissionName string) ([]entity.OutboxMessage, error)
 }
 
 type NotificationService struct {
-	Viper         *viper.Viper
-	Log           *logrus.Logger
-	UserMessage   messaging.IUserMessage
-	JulongService IJulongService
+	Viper            *viper.Viper
+	Log              *logrus.Logger
+	UserMessage      messaging.IUserMessage
+	EmployeeMessage  messaging.IEmployeeMessage
+	OutboxRepository repository.IOutboxRepository
 }
 
-func NewNotificationService(viper *viper.Viper, log *logrus.Logger, userMessage messaging.IUserMessage, julongService IJulongService) INotificationService {
+func NewNotificationService(viper *viper.Viper, log *logrus.Logger, userMessage messaging.IUserMessage, employeeMessage messaging.IEmployeeMessage, outboxRepository repository.IOutboxRepository) INotificationService {
 	return &NotificationService{
-		Viper:         viper,
-		Log:           log,
-		UserMessage:   userMessage,
-		JulongService: julongService,
+		Viper:            viper,
+		Log:              log,
+		UserMessage:      userMessage,
+		EmployeeMessage:  employeeMessage,
+		OutboxRepository: outboxRepository,
 	}
 }
 
-func (s *NotificationService) CreatePeriodNotification(createdBy string) error {
-	userIDs, err := s.UserMessage.SendGetUserIDsByPermissionNames([]string{"create-mpp"})
+func (s *NotificationService) CreatePeriodNotification(ctx context.Context, createdBy string) error {
+	userIDs, err := s.UserMessage.SendGetUserIDsByPermissionNames(ctx, []string{"create-mpp"})
 	if err != nil {
 		s.Log.Error(err)
 		return err
@@ -50,7 +71,13 @@ func (s *NotificationService) CreatePeriodNotification(createdBy string) error {
 		CreatedBy:   createdBy,
 	}
 
-	err = s.JulongService.CreateJulongNotification(payload)
+	outboxMessage, err := entity.NewOutboxMessage(entity.OutboxMessageTypeNotification, NotificationDestination, payload)
+	if err != nil {
+		s.Log.Error(err)
+		return err
+	}
+
+	err = s.OutboxRepository.Create([]entity.OutboxMessage{*outboxMessage})
 	if err != nil {
 		s.Log.Error(err)
 		return err
@@ -59,8 +86,103 @@ func (s *NotificationService) CreatePeriodNotification(createdBy string) error {
 	return nil
 }
 
+func (s *NotificationService) NewSubmittedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error) {
+	return s.newDocumentNotification(ctx, NotificationEventSubmitted, document, requestorID, "")
+}
+
+// NewNeedApprovalNotification notifies the pending approver, or the holders
+// of the approver permission of the document type when nobody is assigned.
+func (s *NotificationService) NewNeedApprovalNotification(ctx context.Context, document NotificationDocument, approverID *uuid.UUID) ([]entity.OutboxMessage, error) {
+	permissionName := s.Viper.GetString("notification.approver_permissions." + string(document.DocumentType))
+	return s.newDocumentNotification(ctx, NotificationEventNeedApproval, document, approverID, permissionName)
+}
+
+func (s *NotificationService) NewApprovedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error) {
+	return s.newDocumentNotification(ctx, NotificationEventApproved, document, requestorID, "")
+}
+
+func (s *NotificationService) NewRejectedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error) {
+	return s.newDocumentNotification(ctx, NotificationEventRejected, document, requestorID, "")
+}
+
+func (s *NotificationService) NewCompletedNotification(ctx context.Context, document NotificationDocument, requestorID *uuid.UUID) ([]entity.OutboxMessage, error) {
+	return s.newDocumentNotification(ctx, NotificationEventCompleted, document, requestorID, "")
+}
+
+func (s *NotificationService) NewBatchReadyForCEONotification(ctx context.Context, document NotificationDocument) ([]entity.OutboxMessage, error) {
+	permissionName := s.Viper.GetString("notification.approver_permissions.batch_ceo")
+	return s.newDocumentNotification(ctx, NotificationEventBatchReadyForCEO, document, nil, permissionName)
+}
+
+func (s *NotificationService) NewApprovalReminderNotification(ctx context.Context, document NotificationDocument, approverID *uuid.UUID, permissionName string) ([]entity.OutboxMessage, error) {
+	return s.newDocumentNotification(ctx, NotificationEventApprovalReminder, document, approverID, permissionName)
+}
+
+func (s *NotificationService) NewApprovalEscalationNotification(ctx context.Context, document NotificationDocument, approverID *uuid.UUID, permissionName string) ([]entity.OutboxMessage, error) {
+	return s.newDocumentNotification(ctx, NotificationEventApprovalEscalated, document, approverID, permissionName)
+}
+
+// newDocumentNotification renders the event template for the employee's user.
+// The holders of permissionName are notified instead when the employee is
+// unknown or has no user.
+func (s *NotificationService) newDocumentNotification(ctx context.Context, event NotificationEvent, document NotificationDocument, employeeID *uuid.UUID, permissionName string) ([]entity.OutboxMessage, error) {
+	userIDs, err := s.findRecipientUserIDs(ctx, employeeID, permissionName)
+	if err != nil {
+		s.Log.Error(err)
+		return nil, err
+	}
+
+	if len(userIDs) == 0 {
+		s.Log.Warnf("[NotificationService.newDocumentNotification] no recipients for %s notification of %s", event, document.DocumentNumber)
+		return nil, nil
+	}
+
+	name, message, err := notificationTemplates[event].render(document)
+	if err != nil {
+		s.Log.Error(err)
+		return nil, err
+	}
+
+	payload := &request.CreateNotificationRequest{
+		Application: "MANPOWER",
+		Name:        name,
+		URL:         documentLink(s.Viper.GetString("notification.links."+string(document.DocumentType)), document),
+		Message:     message,
+		UserIDs:     userIDs,
+		CreatedBy:   s.Viper.GetString("notification.system_user_id"),
+	}
+
+	outboxMessage, err := entity.NewOutboxMessage(entity.OutboxMessageTypeNotification, NotificationDestination, payload)
+	if err != nil {
+		s.Log.Error(err)
+		return nil, err
+	}
+
+	return []entity.OutboxMessage{*outboxMessage}, nil
+}
+
+func (s *NotificationService) findRecipientUserIDs(ctx context.Context, employeeID *uuid.UUID, permissionName string) ([]string, error) {
+	if employeeID != nil {
+		employee, err := s.EmployeeMessage.SendFindEmployeeByIDMessage(ctx, request.SendFindEmployeeByIDMessageRequest{
+			ID: employeeID.String(),
+		})
+		if err != nil {
+			s.Log.Errorf("[NotificationService.findRecipientUserIDs] " + err.Error())
+		} else if employee != nil && employee.UserID != "" {
+			return []string{employee.UserID}, nil
+		}
+	}
+
+	if permissionName == "" {
+		return nil, nil
+	}
+
+	return s.UserMessage.SendGetUserIDsByPermissionNames(ctx, []string{permissionName})
+}
+
 func NotificationServiceFactory(viper *viper.Viper, log *logrus.Logger) INotificationService {
 	userMessage := messaging.UserMessageFactory(log)
-	julongService := JulongServiceFactory(viper, log)
-	return NewNotificationService(viper, log, userMessage, julongService)
+	employeeMessage := messaging.EmployeeMessageFactory(log)
+	outboxRepository := repository.OutboxRepositoryFactory(log)
+	return NewNotificationService(viper, log, userMessage, employeeMessage, outboxRepository)
 }
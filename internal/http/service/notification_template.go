@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type NotificationEvent string
+
+const (
+	NotificationEventSubmitted         NotificationEvent = "submitted"
+	NotificationEventNeedApproval      NotificationEvent = "need_approval"
+	NotificationEventApproved          NotificationEvent = "approved"
+	NotificationEventRejected          NotificationEvent = "rejected"
+	NotificationEventCompleted         NotificationEvent = "completed"
+	NotificationEventBatchReadyForCEO  NotificationEvent = "batch_ready_for_ceo"
+	NotificationEventApprovalReminder  NotificationEvent = "approval_reminder"
+	NotificationEventApprovalEscalated NotificationEvent = "approval_escalated"
+)
+
+// NotificationDocument is the document a notification is about. Notes is only
+// rendered by the templates that mention it, such as rejections.
+type NotificationDocument struct {
+	DocumentType   entity.ApprovalChainDocumentType
+	ID             uuid.UUID
+	DocumentNumber string
+	Notes          string
+}
+
+type notificationTemplate struct {
+	Name    *template.Template
+	Message *template.Template
+}
+
+type notificationTemplateData struct {
+	DocumentName   string
+	DocumentNumber string
+	Notes          string
+}
+
+var documentNames = map[entity.ApprovalChainDocumentType]string{
+	entity.ApprovalChainDocumentTypeMPPlanning: "MP Planning",
+	entity.ApprovalChainDocumentTypeMPRequest:  "MP Request",
+	entity.ApprovalChainDocumentTypeBatch:      "Batch",
+}
+
+// defaultDocumentLinks are the frontend pages of each document type, used
+// when notification.links.<document_type> is not configured.
+var defaultDocumentLinks = map[entity.ApprovalChainDocumentType]string{
+	entity.ApprovalChainDocumentTypeMPPlanning: "/d/mp-planning/:id",
+	entity.ApprovalChainDocumentTypeMPRequest:  "/d/mp-request/:id",
+	entity.ApprovalChainDocumentTypeBatch:      "/d/batch/:id",
+}
+
+var notificationTemplates = map[NotificationEvent]notificationTemplate{
+	NotificationEventSubmitted: newNotificationTemplate(
+		"{{.DocumentName}} - Submitted",
+		"{{.DocumentName}} {{.DocumentNumber}} has been submitted and is now waiting for approval.",
+	),
+	NotificationEventNeedApproval: newNotificationTemplate(
+		"{{.DocumentName}} - Need Your Approval",
+		"{{.DocumentName}} {{.DocumentNumber}} is waiting for your approval. Please review it.",
+	),
+	NotificationEventApproved: newNotificationTemplate(
+		"{{.DocumentName}} - Approved",
+		"{{.DocumentName}} {{.DocumentNumber}} has been approved.",
+	),
+	NotificationEventRejected: newNotificationTemplate(
+		"{{.DocumentName}} - Rejected",
+		"{{.DocumentName}} {{.DocumentNumber}} has been rejected.{{if .Notes}} Notes: {{.Notes}}{{end}}",
+	),
+	NotificationEventCompleted: newNotificationTemplate(
+		"{{.DocumentName}} - Completed",
+		"{{.DocumentName}} {{.DocumentNumber}} has been completed.",
+	),
+	NotificationEventBatchReadyForCEO: newNotificationTemplate(
+		"{{.DocumentName}} - Ready for CEO Approval",
+		"{{.DocumentName}} {{.DocumentNumber}} has been approved by the directors and is ready for CEO approval.",
+	),
+	NotificationEventApprovalReminder: newNotificationTemplate(
+		"{{.DocumentName}} - Approval Reminder",
+		"{{.DocumentName}} {{.DocumentNumber}} is still waiting for your approval. Please review it before it is escalated.",
+	),
+	NotificationEventApprovalEscalated: newNotificationTemplate(
+		"{{.DocumentName}} - Approval Escalated",
+		"{{.DocumentName}} {{.DocumentNumber}} passed its approval deadline and has been escalated to you. Please review it.",
+	),
+}
+
+func newNotificationTemplate(name string, message string) notificationTemplate {
+	return notificationTemplate{
+		Name:    template.Must(template.New("name").Parse(name)),
+		Message: template.Must(template.New("message").Parse(message)),
+	}
+}
+
+// render returns the notification name and message of the event for the document.
+func (t notificationTemplate) render(document NotificationDocument) (string, string, error) {
+	data := notificationTemplateData{
+		DocumentName:   documentNames[document.DocumentType],
+		DocumentNumber: document.DocumentNumber,
+		Notes:          document.Notes,
+	}
+
+	var name, message bytes.Buffer
+	if err := t.Name.Execute(&name, data); err != nil {
+		return "", "", err
+	}
+	if err := t.Message.Execute(&message, data); err != nil {
+		return "", "", err
+	}
+
+	return name.String(), message.String(), nil
+}
+
+func documentLink(pattern string, document NotificationDocument) string {
+	if pattern == "" {
+		pattern = defaultDocumentLinks[document.DocumentType]
+	}
+	return strings.ReplaceAll(pattern, ":id", document.ID.String())
+}
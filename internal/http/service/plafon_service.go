@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// IPlafonService checks planning lines and manpower requests against the
+// plafon of their job. Decide runs before the document is saved so a blocked
+// save never reaches the database; Apply runs after, once the document has an
+// id, to store its flags and open the override it asked for.
+type IPlafonService interface {
+	Enforcement(documentType entity.PlafonOverrideDocumentType) workflow.PlafonEnforcement
+	CheckMPPlanningLines(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeLineIDs []uuid.UUID, headcount int) (*workflow.PlafonCheck, error)
+	CheckMPRequest(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeRequestID *uuid.UUID, totalNeeds int) (*workflow.PlafonCheck, error)
+	Decide(documentType entity.PlafonOverrideDocumentType, documentID *uuid.UUID, check *workflow.PlafonCheck, reason string) (*workflow.PlafonDecision, error)
+	Apply(documentType entity.PlafonOverrideDocumentType, documentID uuid.UUID, check *workflow.PlafonCheck, decision *workflow.PlafonDecision, reason string) error
+	WithContext(ctx context.Context) IPlafonService
+}
+
+type PlafonService struct {
+	Viper                    *viper.Viper
+	Log                      *logrus.Logger
+	JobPlafonRepository      repository.IJobPlafonRepository
+	PlafonOverrideRepository repository.IPlafonOverrideRepository
+}
+
+func NewPlafonService(viper *viper.Viper, log *logrus.Logger, jobPlafonRepository repository.IJobPlafonRepository, plafonOverrideRepository repository.IPlafonOverrideRepository) IPlafonService {
+	return &PlafonService{
+		Viper:                    viper,
+		Log:                      log,
+		JobPlafonRepository:      jobPlafonRepository,
+		PlafonOverrideRepository: plafonOverrideRepository,
+	}
+}
+
+// WithContext is the service with its repositories run under ctx.
+func (s *PlafonService) WithContext(ctx context.Context) IPlafonService {
+	bound := *s
+	bound.JobPlafonRepository = s.JobPlafonRepository.WithContext(ctx)
+	bound.PlafonOverrideRepository = s.PlafonOverrideRepository.WithContext(ctx)
+	return &bound
+}
+
+// Enforcement reads plafon.enforcement.<document_type>, warn by default.
+func (s *PlafonService) Enforcement(documentType entity.PlafonOverrideDocumentType) workflow.PlafonEnforcement {
+	return workflow.ParsePlafonEnforcement(s.Viper.GetString("plafon.enforcement." + string(documentType)))
+}
+
+// CheckMPPlanningLines counts the existing employees and planned recruits of
+// the job in the period, with the given lines replaced by the headcount being
+// saved for them. It returns nil when enforcement is off.
+func (s *PlafonService) CheckMPPlanningLines(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeLineIDs []uuid.UUID, headcount int) (*workflow.PlafonCheck, error) {
+	if s.Enforcement(entity.PlafonOverrideDocumentTypeMPPlanningLine) == workflow.PlafonEnforcementOff {
+		return nil, nil
+	}
+
+	check, err := s.newCheck(mppPeriodID, jobID)
+	if err != nil || check.Plafon == 0 {
+		return check, err
+	}
+
+	existing, recruit, err := s.JobPlafonRepository.SumPlannedHeadcount(mppPeriodID, jobID, excludeLineIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	check.Headcount = existing + recruit + headcount
+	return check, nil
+}
+
+// CheckMPRequest counts the existing employees planned for the job in the
+// period and the needs of its other manpower requests, plus the needs being
+// saved. It returns nil when enforcement is off.
+func (s *PlafonService) CheckMPRequest(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeRequestID *uuid.UUID, totalNeeds int) (*workflow.PlafonCheck, error) {
+	if s.Enforcement(entity.PlafonOverrideDocumentTypeMPRequest) == workflow.PlafonEnforcementOff {
+		return nil, nil
+	}
+
+	check, err := s.newCheck(mppPeriodID, jobID)
+	if err != nil || check.Plafon == 0 {
+		return check, err
+	}
+
+	existing, _, err := s.JobPlafonRepository.SumPlannedHeadcount(mppPeriodID, jobID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	requested, err := s.JobPlafonRepository.SumRequestedHeadcount(mppPeriodID, jobID, excludeRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	check.Headcount = existing + requested + totalNeeds
+	return check, nil
+}
+
+func (s *PlafonService) Decide(documentType entity.PlafonOverrideDocumentType, documentID *uuid.UUID, check *workflow.PlafonCheck, reason string) (*workflow.PlafonDecision, error) {
+	var last *entity.PlafonOverride
+	if documentID != nil && check.Over() {
+		var err error
+		last, err = s.PlafonOverrideRepository.FindLatestByDocument(documentType, *documentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decision, err := workflow.DecidePlafon(s.Enforcement(documentType), check, reason, last)
+	if err != nil {
+		return nil, err
+	}
+
+	if decision.IsOverPlafon {
+		s.Log.Warnf("[PlafonService.Decide] %s %v: job %s reaches %d against a plafon of %d", documentType, documentID, check.JobID, check.Headcount, check.Plafon)
+	}
+
+	return decision, nil
+}
+
+func (s *PlafonService) Apply(documentType entity.PlafonOverrideDocumentType, documentID uuid.UUID, check *workflow.PlafonCheck, decision *workflow.PlafonDecision, reason string) error {
+	if decision.RequestOverride {
+		if _, err := s.PlafonOverrideRepository.Create(&entity.PlafonOverride{
+			DocumentType: documentType,
+			DocumentID:   documentID,
+			MPPPeriodID:  check.MPPPeriodID,
+			JobID:        check.JobID,
+			Plafon:       check.Plafon,
+			Headcount:    check.Headcount,
+			Reason:       reason,
+			Status:       entity.PlafonOverrideStatusPending,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return s.PlafonOverrideRepository.UpdateDocumentFlags(documentType, documentID, decision.IsOverPlafon, decision.OverrideStatus)
+}
+
+func (s *PlafonService) newCheck(mppPeriodID uuid.UUID, jobID uuid.UUID) (*workflow.PlafonCheck, error) {
+	check := &workflow.PlafonCheck{
+		MPPPeriodID: mppPeriodID,
+		JobID:       jobID,
+	}
+
+	jobPlafon, err := s.JobPlafonRepository.FindByJobId(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if jobPlafon != nil {
+		check.Plafon = jobPlafon.Plafon
+	}
+
+	return check, nil
+}
+
+func PlafonServiceFactory(viper *viper.Viper, log *logrus.Logger) IPlafonService {
+	jobPlafonRepository := repository.JobPlafonRepositoryFactory(log)
+	plafonOverrideRepository := repository.PlafonOverrideRepositoryFactory(log)
+	return NewPlafonService(viper, log, jobPlafonRepository, plafonOverrideRepository)
+}
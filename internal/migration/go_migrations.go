@@ -0,0 +1,61 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// goMigrations are the migrations written in Go, for changes that need more
+// than SQL. They never read the entities, which keep changing after the
+// migration is written.
+var goMigrations = []Migration{
+	{
+		// the schema as AutoMigrate left it before the migrations were
+		// versioned, from the copy of the entities in initial_schema.go; on a
+		// database that already has it only the missing tables, columns and
+		// indexes are added
+		Version: "20261017000000",
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(initialModels()...)
+		},
+		Down: func(tx *gorm.DB) error {
+			models := initialModels()
+			for i := len(models) - 1; i >= 0; i-- {
+				if err := tx.Migrator().DropTable(models[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+func initialModels() []interface{} {
+	return []interface{}{
+		&initialJobPlafon{},
+		&initialMPPPeriod{},
+		&initialMPPlanningHeader{},
+		&initialMPPlanningLine{},
+		&initialMajor{},
+		&initialManpowerAttachment{},
+		&initialRequestCategory{},
+		&initialMPRequestHeader{},
+		&initialRequestMajor{},
+		&initialMPRequestApprovalHistory{},
+		&initialMPPlanningApprovalHistory{},
+		&initialBatchHeader{},
+		&initialBatchLine{},
+		&initialApprovalChain{},
+		&initialApprovalChainStep{},
+		&initialApprovalDelegation{},
+		&initialApprovalSLA{},
+		&initialOutboxMessage{},
+		&initialPlafonOverride{},
+		&initialBudgetLedgerEntry{},
+		&initialMPPlanningLineImport{},
+		&initialMPPlanningLineImportRow{},
+		&initialDocumentSequence{},
+		&initialAuditLog{},
+		&initialDataFix{},
+	}
+}
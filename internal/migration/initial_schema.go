@@ -0,0 +1,494 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The tables as the entities defined them when the migrations started to be
+// versioned, for initial_schema. They are a copy so that the first migration
+// keeps creating the same schema however the entities change afterwards;
+// change the schema with a new migration, never here.
+
+type initialApprovalChain struct {
+	gorm.Model
+	ID                 uuid.UUID                  `gorm:"type:char(36);primaryKey;"`
+	OrganizationID     *uuid.UUID                 `gorm:"type:char(36);not null;"`
+	DocumentType       string                     `gorm:"type:varchar(50);not null;"`
+	Name               string                     `gorm:"type:varchar(255);not null;"`
+	IsActive           bool                       `gorm:"type:boolean;default:false;"`
+	ApprovalChainSteps []initialApprovalChainStep `gorm:"foreignKey:ApprovalChainID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (initialApprovalChain) TableName() string {
+	return "approval_chains"
+}
+
+type initialApprovalChainStep struct {
+	gorm.Model
+	ID              uuid.UUID            `gorm:"type:char(36);primaryKey;"`
+	ApprovalChainID uuid.UUID            `gorm:"type:char(36);not null;"`
+	Sequence        int                  `gorm:"type:int;not null;"`
+	Level           string               `gorm:"type:varchar(255);not null;"`
+	ApproverID      *uuid.UUID           `gorm:"type:char(36);"`
+	ApproverName    string               `gorm:"type:varchar(255);"`
+	Condition       string               `gorm:"type:varchar(50);default:'ALL';"`
+	ApprovalChain   initialApprovalChain `gorm:"foreignKey:ApprovalChainID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (initialApprovalChainStep) TableName() string {
+	return "approval_chain_steps"
+}
+
+type initialApprovalDelegation struct {
+	gorm.Model
+	ID            uuid.UUID  `gorm:"type:char(36);primaryKey;"`
+	DelegatorID   *uuid.UUID `gorm:"type:char(36);not null;"`
+	DelegatorName string     `gorm:"type:varchar(255);"`
+	DelegateID    *uuid.UUID `gorm:"type:char(36);not null;"`
+	DelegateName  string     `gorm:"type:varchar(255);"`
+	Scope         string     `gorm:"type:varchar(50);default:'all';"`
+	StartDate     time.Time  `gorm:"type:date;not null;"`
+	EndDate       time.Time  `gorm:"type:date;not null;"`
+	Reason        string     `gorm:"type:text;"`
+}
+
+func (initialApprovalDelegation) TableName() string {
+	return "approval_delegations"
+}
+
+type initialApprovalSLA struct {
+	gorm.Model
+	ID                 uuid.UUID  `gorm:"type:char(36);primaryKey;"`
+	DocumentType       string     `gorm:"type:varchar(50);not null;"`
+	Level              string     `gorm:"type:varchar(255);"`
+	ReminderAfterDays  int        `gorm:"type:int;not null;"`
+	EscalateAfterDays  int        `gorm:"type:int;not null;"`
+	EscalateToLevel    string     `gorm:"type:varchar(255);"`
+	BackupApproverID   *uuid.UUID `gorm:"type:char(36);"`
+	BackupApproverName string     `gorm:"type:varchar(255);"`
+	PermissionName     string     `gorm:"type:varchar(255);"`
+	IsActive           bool       `gorm:"type:boolean;default:false;"`
+}
+
+func (initialApprovalSLA) TableName() string {
+	return "approval_slas"
+}
+
+type initialAuditLog struct {
+	gorm.Model
+	ID        uuid.UUID `gorm:"type:char(36);primaryKey;"`
+	Action    string    `gorm:"type:varchar(10);not null"`
+	Entity    string    `gorm:"type:varchar(100);not null;index:idx_audit_logs_entity"`
+	EntityID  string    `gorm:"type:varchar(36);not null;index:idx_audit_logs_entity"`
+	Field     string    `gorm:"type:varchar(100);default:null"`
+	OldValue  *string   `gorm:"type:text;default:null"`
+	NewValue  *string   `gorm:"type:text;default:null"`
+	ActorID   string    `gorm:"type:varchar(36);default:null;index"`
+	ActorName string    `gorm:"type:varchar(255);default:null"`
+	RequestID string    `gorm:"type:varchar(64);default:null;index"`
+}
+
+func (initialAuditLog) TableName() string {
+	return "audit_logs"
+}
+
+type initialBatchHeader struct {
+	gorm.Model
+	ID                  uuid.UUID          `gorm:"type:char(36);primaryKey;"`
+	DocumentNumber      string             `gorm:"type:varchar(255);not null;"`
+	DocumentDate        time.Time          `gorm:"default:null;"`
+	ApproverID          *uuid.UUID         `gorm:"type:char(36);default:null;"`
+	ApproverName        string             `gorm:"type:varchar(255);default:null;"`
+	Status              string             `gorm:"default:null"`
+	ApproverType        string             `gorm:"type:varchar(255);default:CEO"`
+	OrganizationID      *uuid.UUID         `gorm:"type:char(36);default:null;"`
+	ApprovalRequestedAt *time.Time         `gorm:"default:null;"`
+	ApprovalRemindedAt  *time.Time         `gorm:"default:null;"`
+	BatchLines          []initialBatchLine `gorm:"foreignKey:BatchHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialBatchHeader) TableName() string {
+	return "batch_headers"
+}
+
+type initialBatchLine struct {
+	gorm.Model
+	ID                     uuid.UUID               `gorm:"type:char(36);primaryKey;"`
+	BatchHeaderID          uuid.UUID               `gorm:"type:char(36);not null;"`
+	MPPlanningHeaderID     uuid.UUID               `gorm:"type:char(36);not null;"`
+	OrganizationID         uuid.UUID               `gorm:"type:char(36);not null;"`
+	OrganizationLocationID uuid.UUID               `gorm:"type:char(36);not null;"`
+	BatchHeader            initialBatchHeader      `gorm:"foreignKey:BatchHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	MPPlanningHeader       initialMPPlanningHeader `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialBatchLine) TableName() string {
+	return "batch_lines"
+}
+
+type initialBudgetLedgerEntry struct {
+	gorm.Model
+	ID                uuid.UUID              `gorm:"type:char(36);primaryKey;"`
+	MPRequestHeaderID uuid.UUID              `gorm:"type:char(36);not null;index"`
+	MPPlanningLineID  uuid.UUID              `gorm:"type:char(36);not null;index"`
+	EntryType         string                 `gorm:"type:varchar(20);not null"`
+	Balance           string                 `gorm:"type:varchar(5);not null"`
+	Quantity          int                    `gorm:"type:int;default:0"`
+	Reserved          int                    `gorm:"type:int;default:0"`
+	Consumed          int                    `gorm:"type:int;default:0"`
+	MPRequestStatus   string                 `gorm:"type:varchar(50)"`
+	MPRequestHeader   initialMPRequestHeader `gorm:"foreignKey:MPRequestHeaderID;references:ID"`
+}
+
+func (initialBudgetLedgerEntry) TableName() string {
+	return "budget_ledger_entries"
+}
+
+type initialDataFix struct {
+	gorm.Model
+	ID   uuid.UUID `gorm:"type:char(36);primaryKey;"`
+	Name string    `gorm:"type:varchar(100);not null;uniqueIndex"`
+	Note string    `gorm:"type:text;default:null"`
+}
+
+func (initialDataFix) TableName() string {
+	return "data_fixes"
+}
+
+type initialDocumentSequence struct {
+	gorm.Model
+	ID           uuid.UUID `gorm:"type:char(36);primaryKey;"`
+	DocumentType string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_document_sequences_type_period"`
+	Period       string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_document_sequences_type_period"`
+	LastNumber   int       `gorm:"type:int;not null;default:0"`
+}
+
+func (initialDocumentSequence) TableName() string {
+	return "document_sequences"
+}
+
+type initialJobPlafon struct {
+	gorm.Model
+	ID     uuid.UUID  `gorm:"type:char(36);primaryKey;"`
+	JobID  *uuid.UUID `gorm:"type:char(36);not null;unique"`
+	Plafon int        `gorm:"type:int;default:0"`
+}
+
+func (initialJobPlafon) TableName() string {
+	return "job_plafons"
+}
+
+type initialMajor struct {
+	gorm.Model
+	ID             uuid.UUID             `gorm:"type:char(36);primaryKey;"`
+	Major          string                `gorm:"type:varchar(255);not null;"`
+	EducationLevel string                `gorm:"type:varchar(255);not null;"`
+	RequestMajors  []initialRequestMajor `gorm:"foreignKey:MajorID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialMajor) TableName() string {
+	return "majors"
+}
+
+type initialManpowerAttachment struct {
+	gorm.Model
+	ID        uuid.UUID `gorm:"type:char(36);primaryKey;"`
+	OwnerType string    `gorm:"type:varchar(255);not null;"`
+	OwnerID   uuid.UUID `gorm:"type:char(36);not null;"`
+	FileName  string    `gorm:"type:varchar(255);not null;"`
+	FileType  string    `gorm:"type:varchar(255);not null;"`
+	FilePath  string    `gorm:"type:text;not null;"`
+}
+
+func (initialManpowerAttachment) TableName() string {
+	return "manpower_attachments"
+}
+
+type initialMPPlanningApprovalHistory struct {
+	gorm.Model
+	ID                  uuid.UUID                   `gorm:"type:char(36);primaryKey;"`
+	MPPlanningHeaderID  uuid.UUID                   `gorm:"type:char(36);"`
+	ApproverID          uuid.UUID                   `gorm:"type:char(36);"`
+	ApproverName        string                      `gorm:"type:varchar(255);"`
+	OnBehalfOfID        *uuid.UUID                  `gorm:"type:char(36);"`
+	OnBehalfOfName      string                      `gorm:"type:varchar(255);"`
+	Notes               string                      `gorm:"type:text;"`
+	Level               string                      `gorm:"type:varchar(255);"`
+	Status              string                      `gorm:"not null"`
+	MPPlanningHeader    initialMPPlanningHeader     `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ManpowerAttachments []initialManpowerAttachment `gorm:"polymorphicType:OwnerType;polymorphicId:OwnerID;polymorphicValue:mp_planning_approval_histories"`
+}
+
+func (initialMPPlanningApprovalHistory) TableName() string {
+	return "mp_planning_approval_histories"
+}
+
+type initialMPPlanningHeader struct {
+	gorm.Model
+	ID                          uuid.UUID                          `gorm:"type:char(36);primaryKey;"`
+	MPPPeriodID                 uuid.UUID                          `gorm:"type:char(36); not null;"`
+	OrganizationID              *uuid.UUID                         `gorm:"type:char(36);not null;"`
+	EmpOrganizationID           *uuid.UUID                         `gorm:"type:char(36);not null;"`
+	JobID                       *uuid.UUID                         `gorm:"type:char(36);not null; not null;"`
+	OrganizationLocationID      *uuid.UUID                         `gorm:"type:char(36);not null;"`
+	DocumentNumber              string                             `gorm:"type:varchar(255);not null;unique"`
+	DocumentDate                time.Time                          `gorm:"type:date;not null;"`
+	Notes                       string                             `gorm:"type:text;default:null"`
+	TotalRecruit                float64                            `gorm:"type:decimal(18,2);default:0"`
+	TotalPromote                float64                            `gorm:"ty	pe:decimal(18,2);default:0"`
+	Status                      string                             `gorm:"default:'DRAFT'"`
+	RecommendedBy               string                             `gorm:"type:text;"`
+	ApprovedBy                  string                             `gorm:"type:text;default:null"`
+	RequestorID                 *uuid.UUID                         `gorm:"type:char(36);"`
+	NotesAttach                 string                             `gorm:"type:text;"`
+	ApproverManagerID           *uuid.UUID                         `gorm:"type:char(36);"`
+	NotesManager                string                             `gorm:"type:text;"`
+	ApproverRecruitmentID       *uuid.UUID                         `gorm:"type:char(36);"`
+	NotesRecruitment            string                             `gorm:"type:text;"`
+	NextApproverID              *uuid.UUID                         `gorm:"type:char(36);"`
+	NextApproverLevel           string                             `gorm:"type:varchar(255);"`
+	ApprovalRequestedAt         *time.Time                         `gorm:"default:null;"`
+	ApprovalRemindedAt          *time.Time                         `gorm:"default:null;"`
+	MPPPeriod                   initialMPPPeriod                   `gorm:"foreignKey:MPPPeriodID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	MPPlanningLines             []initialMPPlanningLine            `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ManpowerAttachments         []initialManpowerAttachment        `gorm:"polymorphicType:OwnerType;polymorphicId:OwnerID;polymorphicValue:mp_planning_headers"`
+	MPRequestHeaders            []initialMPRequestHeader           `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	MPPlanningApprovalHistories []initialMPPlanningApprovalHistory `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	BatchLines                  []initialBatchLine                 `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialMPPlanningHeader) TableName() string {
+	return "mp_planning_headers"
+}
+
+type initialMPPlanningLine struct {
+	gorm.Model
+	ID                     uuid.UUID               `gorm:"type:char(36);primaryKey;"`
+	MPPlanningHeaderID     uuid.UUID               `gorm:"type:char(36);not null"`
+	OrganizationLocationID *uuid.UUID              `gorm:"type:char(36)"`
+	JobLevelID             *uuid.UUID              `gorm:"type:char(36);not null"`
+	JobID                  *uuid.UUID              `gorm:"type:char(36);not null"`
+	Existing               int                     `gorm:"type:int;default:0"`
+	Recruit                int                     `gorm:"type:int;default:0"`
+	SuggestedRecruit       int                     `gorm:"type:int;default:0"`
+	Promotion              int                     `gorm:"type:int;default:0"`
+	Total                  int                     `gorm:"type:int;default:0"`
+	RecruitPH              int                     `gorm:"type:int;default:0"`
+	RemainingBalancePH     int                     `gorm:"type:int;default:0"`
+	RecruitMT              int                     `gorm:"type:int;default:0"`
+	RemainingBalanceMT     int                     `gorm:"type:int;default:0"`
+	IsOverPlafon           bool                    `gorm:"type:boolean;default:false"`
+	PlafonOverrideStatus   string                  `gorm:"type:varchar(20);default:null"`
+	MPPlanningHeader       initialMPPlanningHeader `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialMPPlanningLine) TableName() string {
+	return "mp_planning_lines"
+}
+
+type initialMPPlanningLineImport struct {
+	gorm.Model
+	ID                       uuid.UUID                        `gorm:"type:char(36);primaryKey;"`
+	MPPlanningHeaderID       uuid.UUID                        `gorm:"type:char(36);not null;index"`
+	FileName                 string                           `gorm:"type:varchar(255);not null"`
+	Status                   string                           `gorm:"type:varchar(20);default:'PENDING'"`
+	TotalRows                int                              `gorm:"type:int;default:0"`
+	ErrorRows                int                              `gorm:"type:int;default:0"`
+	ImportedBy               *uuid.UUID                       `gorm:"type:char(36);default:null"`
+	ConfirmedAt              *time.Time                       `gorm:"default:null"`
+	MPPlanningLineImportRows []initialMPPlanningLineImportRow `gorm:"foreignKey:MPPlanningLineImportID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialMPPlanningLineImport) TableName() string {
+	return "mp_planning_line_imports"
+}
+
+type initialMPPlanningLineImportRow struct {
+	gorm.Model
+	ID                       uuid.UUID  `gorm:"type:char(36);primaryKey;"`
+	MPPlanningLineImportID   uuid.UUID  `gorm:"type:char(36);not null;index"`
+	RowNumber                int        `gorm:"type:int;not null"`
+	OrganizationLocationName string     `gorm:"type:varchar(255)"`
+	JobLevelName             string     `gorm:"type:varchar(255)"`
+	JobName                  string     `gorm:"type:varchar(255)"`
+	OrganizationLocationID   *uuid.UUID `gorm:"type:char(36);default:null"`
+	JobLevelID               *uuid.UUID `gorm:"type:char(36);default:null"`
+	JobID                    *uuid.UUID `gorm:"type:char(36);default:null"`
+	Existing                 int        `gorm:"type:int;default:0"`
+	RecruitMT                int        `gorm:"type:int;default:0"`
+	RecruitPH                int        `gorm:"type:int;default:0"`
+	Promotion                int        `gorm:"type:int;default:0"`
+	MPPlanningLineID         *uuid.UUID `gorm:"type:char(36);default:null"`
+	Errors                   string     `gorm:"type:text;default:null"`
+}
+
+func (initialMPPlanningLineImportRow) TableName() string {
+	return "mp_planning_line_import_rows"
+}
+
+type initialMPRequestApprovalHistory struct {
+	gorm.Model
+	ID                  uuid.UUID                   `gorm:"type:char(36);primaryKey;"`
+	MPRequestHeaderID   uuid.UUID                   `gorm:"type:char(36);"`
+	ApproverID          uuid.UUID                   `gorm:"type:char(36);"`
+	ApproverName        string                      `gorm:"type:varchar(255);"`
+	OnBehalfOfID        *uuid.UUID                  `gorm:"type:char(36);"`
+	OnBehalfOfName      string                      `gorm:"type:varchar(255);"`
+	Notes               string                      `gorm:"type:text;"`
+	Level               string                      `gorm:"type:varchar(255);"`
+	Status              string                      `gorm:"not null"`
+	MPRequestHeader     initialMPRequestHeader      `gorm:"foreignKey:MPRequestHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ManpowerAttachments []initialManpowerAttachment `gorm:"polymorphicType:OwnerType;polymorphicId:OwnerID;polymorphicValue:mp_request_approval_histories"`
+}
+
+func (initialMPRequestApprovalHistory) TableName() string {
+	return "mp_request_approval_histories"
+}
+
+type initialMPRequestHeader struct {
+	gorm.Model
+	ID                         uuid.UUID                         `gorm:"type:char(36);primaryKey;"`
+	OrganizationID             *uuid.UUID                        `gorm:"type:char(36);not null;"`
+	OrganizationLocationID     *uuid.UUID                        `gorm:"type:char(36);not null"`
+	ForOrganizationID          *uuid.UUID                        `gorm:"type:char(36);not null"`
+	ForOrganizationLocationID  *uuid.UUID                        `gorm:"type:char(36);not null"`
+	ForOrganizationStructureID *uuid.UUID                        `gorm:"type:char(36);not null"`
+	JobID                      *uuid.UUID                        `gorm:"type:char(36);not null"`
+	RequestCategoryID          uuid.UUID                         `gorm:"type:char(36);not null"`
+	ExpectedDate               *time.Time                        `gorm:"type:date;null;"`
+	Experiences                string                            `gorm:"type:text;default:null"`
+	DocumentNumber             string                            `gorm:"type:varchar(255);not null;unique;"`
+	DocumentDate               time.Time                         `gorm:"type:date;not null;"`
+	MaleNeeds                  int                               `gorm:"type:int;default:0"`
+	FemaleNeeds                int                               `gorm:"type:int;default:0"`
+	AnyGender                  int                               `gorm:"type:int;default:0"`
+	MinimumAge                 int                               `gorm:"type:int;default:0"`
+	MaximumAge                 int                               `gorm:"type:int;default:0"`
+	MinimumExperience          int                               `gorm:"type:int;default:0"`
+	MaritalStatus              string                            `gorm:"default:'single'not null"`
+	MinimumEducation           string                            `gorm:"default:'s1';not null"`
+	RequiredQualification      string                            `gorm:"type:text;default:null"`
+	Certificate                string                            `gorm:"type:text;default:null"`
+	ComputerSkill              string                            `gorm:"type:text;default:null"`
+	LanguageSkill              string                            `gorm:"type:text;default:null"`
+	OtherSkill                 string                            `gorm:"type:text;default:null"`
+	Jobdesc                    string                            `gorm:"type:text;not null"`
+	SalaryMin                  string                            `gorm:"type:varchar(255);not null"`
+	SalaryMax                  string                            `gorm:"type:varchar(255);not null"`
+	RequestorID                *uuid.UUID                        `gorm:"type:char(36);not null"`
+	DepartmentHead             *uuid.UUID                        `gorm:"type:char(36);null"`
+	VpGmDirector               *uuid.UUID                        `gorm:"type:text;default:null"`
+	CEO                        *uuid.UUID                        `gorm:"type:text;default:null"`
+	HrdHoUnit                  *uuid.UUID                        `gorm:"type:char(36);null"`
+	MPPlanningHeaderID         *uuid.UUID                        `gorm:"type:char(36);null"`
+	Status                     string                            `gorm:"default:'DRAFT'"`
+	MPRequestType              string                            `gorm:"default:'ON_BUDGET'"`
+	RecruitmentType            string                            `gorm:"type:text;default:not null"`
+	MPPPeriodID                uuid.UUID                         `gorm:"type:char(36);null"`
+	NotesDepartmentHead        string                            `gorm:"type:text;default:null"`
+	NotesVpGmDirector          string                            `gorm:"type:text;default:null"`
+	NotesCEO                   string                            `gorm:"type:text;default:null"`
+	NotesHrdHo                 string                            `gorm:"type:text;default:null"`
+	TotalNeeds                 int                               `gorm:"type:int;default:0"`
+	EmpOrganizationID          *uuid.UUID                        `gorm:"type:char(36);null"`
+	JobLevelID                 *uuid.UUID                        `gorm:"type:char(36);null"`
+	IsReplacement              bool                              `gorm:"default:false"`
+	GradeID                    *uuid.UUID                        `gorm:"type:char(36);null"`
+	NextApproverID             *uuid.UUID                        `gorm:"type:char(36);null"`
+	NextApproverLevel          string                            `gorm:"type:varchar(255);default:null"`
+	ApprovalRequestedAt        *time.Time                        `gorm:"default:null"`
+	ApprovalRemindedAt         *time.Time                        `gorm:"default:null"`
+	IsOverPlafon               bool                              `gorm:"type:boolean;default:false"`
+	PlafonOverrideStatus       string                            `gorm:"type:varchar(20);default:null"`
+	RequestCategory            initialRequestCategory            `gorm:"foreignKey:RequestCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	RequestMajors              []initialRequestMajor             `gorm:"foreignKey:MPRequestHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	MPPlanningHeader           initialMPPlanningHeader           `gorm:"foreignKey:MPPlanningHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	MPRequestApprovalHistories []initialMPRequestApprovalHistory `gorm:"foreignKey:MPRequestHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	MPPPeriod                  initialMPPPeriod                  `gorm:"foreignKey:MPPPeriodID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialMPRequestHeader) TableName() string {
+	return "mp_request_headers"
+}
+
+type initialMPPPeriod struct {
+	gorm.Model
+	ID                uuid.UUID `gorm:"type:char(36);primaryKey;"`
+	Title             string
+	StartDate         time.Time                 `gorm:"type:date"`
+	EndDate           time.Time                 `gorm:"type:date"`
+	BudgetStartDate   time.Time                 `gorm:"type:date"`
+	BudgetEndDate     time.Time                 `gorm:"type:date"`
+	Status            string                    `gorm:"default:'open'"`
+	MPPlanningHeaders []initialMPPlanningHeader `gorm:"foreignKey:MPPPeriodID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialMPPPeriod) TableName() string {
+	return "mpp_periods"
+}
+
+type initialOutboxMessage struct {
+	gorm.Model
+	ID            uuid.UUID  `gorm:"type:char(36);primaryKey;"`
+	MessageType   string     `gorm:"type:varchar(100);not null;index"`
+	Destination   string     `gorm:"type:varchar(255);not null;"`
+	Payload       string     `gorm:"type:text;not null;"`
+	Status        string     `gorm:"type:varchar(20);default:'PENDING';index"`
+	Attempts      int        `gorm:"type:int;default:0"`
+	NextAttemptAt time.Time  `gorm:"not null;index"`
+	LastError     string     `gorm:"type:text;default:null"`
+	SentAt        *time.Time `gorm:"default:null"`
+}
+
+func (initialOutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+type initialPlafonOverride struct {
+	gorm.Model
+	ID           uuid.UUID  `gorm:"type:char(36);primaryKey;"`
+	DocumentType string     `gorm:"type:varchar(50);not null;index:idx_plafon_overrides_document"`
+	DocumentID   uuid.UUID  `gorm:"type:char(36);not null;index:idx_plafon_overrides_document"`
+	MPPPeriodID  uuid.UUID  `gorm:"type:char(36);not null;"`
+	JobID        uuid.UUID  `gorm:"type:char(36);not null;"`
+	Plafon       int        `gorm:"type:int;default:0"`
+	Headcount    int        `gorm:"type:int;default:0"`
+	Reason       string     `gorm:"type:text;not null"`
+	Status       string     `gorm:"type:varchar(20);default:'PENDING'"`
+	ApproverID   *uuid.UUID `gorm:"type:char(36);"`
+	ApproverName string     `gorm:"type:varchar(255);"`
+	Notes        string     `gorm:"type:text;default:null"`
+	DecidedAt    *time.Time `gorm:"default:null"`
+}
+
+func (initialPlafonOverride) TableName() string {
+	return "plafon_overrides"
+}
+
+type initialRequestCategory struct {
+	gorm.Model
+	ID               uuid.UUID                `gorm:"type:char(36);primaryKey;"`
+	Name             string                   `gorm:"type:varchar(255);not null;"`
+	IsReplacement    bool                     `gorm:"type:boolean;default:false;"`
+	MPRequestHeaders []initialMPRequestHeader `gorm:"foreignKey:RequestCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialRequestCategory) TableName() string {
+	return "request_categories"
+}
+
+type initialRequestMajor struct {
+	gorm.Model
+	ID                uuid.UUID              `gorm:"type:char(36);primaryKey;"`
+	MajorID           uuid.UUID              `gorm:"type:char(36);"`
+	MPRequestHeaderID uuid.UUID              `gorm:"type:char(36);"`
+	Major             initialMajor           `gorm:"foreignKey:MajorID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	MPRequestHeader   initialMPRequestHeader `gorm:"foreignKey:MPRequestHeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (initialRequestMajor) TableName() string {
+	return "request_majors"
+}
@@ -0,0 +1,208 @@
+// Package migration versions the database schema. Each migration has a
+// version, the UTC time it was created as yyyymmddhhmmss, and changes the
+// schema up and back down again. A migration is either written in Go, in
+// goMigrations, or in SQL, as a <version>_<name>.up.sql and .down.sql pair in
+// sql/mysql and sql/postgres. The versions applied are kept in
+// schema_migrations.
+//
+// The schema the entities had when versioning started is the first migration;
+// every change after it is a migration of its own, the entities are no longer
+// migrated automatically.
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VersionLayout is how the version of a migration is written.
+const VersionLayout = "20060102150405"
+
+// Migration is one change of the schema. Down is nil when the change cannot
+// be undone.
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration is a migration that has been applied.
+type SchemaMigration struct {
+	Version   string    `gorm:"type:varchar(14);primaryKey"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+//go:embed sql
+var sqlFiles embed.FS
+
+// Migrations are the migrations for the dialect, oldest first.
+func Migrations(dialect string) ([]Migration, error) {
+	migrations, err := sqlMigrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+	migrations = append(migrations, goMigrations...)
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("migration %s is defined twice", migrations[i].Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// sqlMigrations reads the SQL migrations of the dialect. A version written
+// for one dialect but not the other is an error, so a migration is not
+// forgotten on one of the databases.
+func sqlMigrations(dialect string) ([]Migration, error) {
+	byDialect := make(map[string]map[string]*Migration)
+	for _, d := range []string{"mysql", "postgres"} {
+		migrations, err := readSQLMigrations(d)
+		if err != nil {
+			return nil, err
+		}
+		byDialect[d] = migrations
+	}
+
+	current, ok := byDialect[dialect]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver: %s", dialect)
+	}
+	for d, migrations := range byDialect {
+		for version := range migrations {
+			if _, ok := current[version]; !ok {
+				return nil, fmt.Errorf("migration %s exists for %s but not for %s", version, d, dialect)
+			}
+		}
+	}
+
+	result := make([]Migration, 0, len(current))
+	for _, migration := range current {
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migration %s of %s has no up.sql", migration.Version, dialect)
+		}
+		result = append(result, *migration)
+	}
+	return result, nil
+}
+
+func readSQLMigrations(dialect string) (map[string]*Migration, error) {
+	dir := path.Join("sql", dialect)
+	entries, err := fs.ReadDir(sqlFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make(map[string]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".sql") {
+			continue
+		}
+
+		version, name, direction, err := parseFileName(fileName)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(sqlFiles, path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := migrations[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			migrations[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration %s of %s is named both %s and %s", version, dialect, migration.Name, name)
+		}
+
+		switch direction {
+		case "up":
+			migration.Up = execSQL(string(content))
+		case "down":
+			migration.Down = execSQL(string(content))
+		}
+	}
+
+	return migrations, nil
+}
+
+// parseFileName splits <version>_<name>.<up|down>.sql.
+func parseFileName(fileName string) (version string, name string, direction string, err error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 {
+		return "", "", "", fmt.Errorf("migration %s is neither .up.sql nor .down.sql", fileName)
+	}
+	base, direction = base[:dot], base[dot+1:]
+	if direction != "up" && direction != "down" {
+		return "", "", "", fmt.Errorf("migration %s is neither .up.sql nor .down.sql", fileName)
+	}
+
+	version, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return "", "", "", fmt.Errorf("migration %s is not named <version>_<name>", fileName)
+	}
+	if _, err := time.Parse(VersionLayout, version); err != nil {
+		return "", "", "", fmt.Errorf("migration %s does not start with a %s version", fileName, VersionLayout)
+	}
+
+	return version, name, direction, nil
+}
+
+// execSQL runs the statements of a SQL migration one by one, as the drivers
+// do not take several statements at once. A statement ends with a semicolon
+// at the end of a line; lines starting with -- are comments.
+func execSQL(content string) func(tx *gorm.DB) error {
+	var statements []string
+	var statement strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(statement.String()))
+			statement.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(statement.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return errors.New(err.Error() + " in " + firstLine(statement))
+			}
+		}
+		return nil
+	}
+}
+
+func firstLine(statement string) string {
+	line, _, _ := strings.Cut(statement, "\n")
+	return line
+}
@@ -0,0 +1,219 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Runner applies and rolls back the migrations of a database. Each migration
+// runs in a transaction with its schema_migrations row, though MySQL commits
+// every schema statement on its own, so a failed migration there may have to
+// be cleaned up by hand.
+type Runner struct {
+	DB         *gorm.DB
+	Log        *logrus.Logger
+	Migrations []Migration
+}
+
+// MigrationStatus is a migration and when it was applied, nil when it is
+// pending. Unknown is set for a version the database has but this build does
+// not.
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	AppliedAt *time.Time
+	Unknown   bool
+}
+
+func NewRunner(db *gorm.DB, log *logrus.Logger) (*Runner, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	return &Runner{
+		DB:         db,
+		Log:        log,
+		Migrations: migrations,
+	}, nil
+}
+
+// Up applies the pending migrations, oldest first, at most steps of them
+// when steps is positive.
+func (r *Runner) Up(steps int) ([]Migration, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range r.Migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if steps > 0 && len(done) == steps {
+			break
+		}
+
+		err := r.DB.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s_%s: %w", migration.Version, migration.Name, err)
+		}
+		r.Log.Infof("[Runner.Up] applied %s_%s", migration.Version, migration.Name)
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (r *Runner) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be at least 1")
+	}
+
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]Migration, len(r.Migrations))
+	for _, migration := range r.Migrations {
+		known[migration.Version] = migration
+	}
+
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+
+	var done []Migration
+	for _, version := range versions {
+		if len(done) == steps {
+			break
+		}
+
+		migration, ok := known[version]
+		if !ok {
+			return done, fmt.Errorf("migration %s_%s is applied but not in this build", version, applied[version].Name)
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("migration %s_%s cannot be rolled back", migration.Version, migration.Name)
+		}
+
+		err := r.DB.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Where("version = ?", migration.Version).Delete(&SchemaMigration{}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s_%s: %w", migration.Version, migration.Name, err)
+		}
+		r.Log.Infof("[Runner.Down] rolled back %s_%s", migration.Version, migration.Name)
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status lists every migration, known or applied, oldest first.
+func (r *Runner) Status() ([]MigrationStatus, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(r.Migrations))
+	for _, migration := range r.Migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if schemaMigration, ok := applied[migration.Version]; ok {
+			appliedAt := schemaMigration.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, schemaMigration := range applied {
+		appliedAt := schemaMigration.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   schemaMigration.Version,
+			Name:      schemaMigration.Name,
+			AppliedAt: &appliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+func (r *Runner) applied() (map[string]SchemaMigration, error) {
+	var schemaMigrations []SchemaMigration
+	if err := r.DB.Find(&schemaMigrations).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]SchemaMigration, len(schemaMigrations))
+	for _, schemaMigration := range schemaMigrations {
+		applied[schemaMigration.Version] = schemaMigration
+	}
+	return applied, nil
+}
+
+var nonNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty SQL migration named name for both databases under
+// dir, versioned at now, and returns the files written.
+func Create(dir string, name string, now time.Time) ([]string, error) {
+	name = strings.Trim(nonNameCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("the migration needs a name")
+	}
+	version := now.UTC().Format(VersionLayout)
+
+	var files []string
+	for _, dialect := range []string{"mysql", "postgres"} {
+		dialectDir := filepath.Join(dir, dialect)
+		if err := os.MkdirAll(dialectDir, 0755); err != nil {
+			return files, err
+		}
+
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dialectDir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+			if _, err := os.Stat(file); err == nil {
+				return files, fmt.Errorf("%s already exists", file)
+			}
+			content := fmt.Sprintf("-- %s %s for %s\n", name, direction, dialect)
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				return files, err
+			}
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,131 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Seed puts the reference data in the database. Every record is looked up by
+// its natural key, so seeding again never duplicates a record. Periods that
+// are there are left alone, as their dates and status move on in use.
+func Seed(db *gorm.DB, log *logrus.Logger) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := seedMPPPeriods(tx); err != nil {
+			return err
+		}
+		if err := seedRequestCategories(tx); err != nil {
+			return err
+		}
+		if err := seedMajors(tx); err != nil {
+			return err
+		}
+
+		log.Info("[Seed] mpp periods, request categories and majors seeded")
+		return nil
+	})
+}
+
+// seedMPPPeriods creates the periods missing by title.
+func seedMPPPeriods(tx *gorm.DB) error {
+	mppPeriods := []entity.MPPPeriod{
+		{
+			Title:           "MPP Period 1",
+			StartDate:       date("2024-06-01"),
+			EndDate:         date("2025-07-01"),
+			BudgetStartDate: date("2024-06-01"),
+			BudgetEndDate:   date("2025-07-01"),
+			Status:          entity.MPPeriodStatusOpen,
+		},
+		{
+			Title:           "MPP Period 2",
+			StartDate:       date("2023-06-01"),
+			EndDate:         date("2024-07-01"),
+			BudgetStartDate: date("2023-06-01"),
+			BudgetEndDate:   date("2024-07-01"),
+			Status:          entity.MPPeriodStatusComplete,
+		},
+	}
+
+	for _, mppPeriod := range mppPeriods {
+		err := tx.Where("title = ?", mppPeriod.Title).
+			Attrs(map[string]interface{}{
+				"start_date":        mppPeriod.StartDate,
+				"end_date":          mppPeriod.EndDate,
+				"budget_start_date": mppPeriod.BudgetStartDate,
+				"budget_end_date":   mppPeriod.BudgetEndDate,
+				"status":            mppPeriod.Status,
+			}).
+			FirstOrCreate(&entity.MPPPeriod{Title: mppPeriod.Title}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedRequestCategories upserts the categories by name.
+func seedRequestCategories(tx *gorm.DB) error {
+	requestCategories := []entity.RequestCategory{
+		{Name: "Undur Diri", IsReplacement: true},
+		{Name: "Dimutasikan", IsReplacement: true},
+		{Name: "Pensiun", IsReplacement: true},
+		{Name: "Diberhentikan", IsReplacement: true},
+		{Name: "Promosi", IsReplacement: true},
+		{Name: "Meninggal Dunia", IsReplacement: true},
+		{Name: "Posisi Baru", IsReplacement: false},
+		{Name: "Pegawai Baru", IsReplacement: false},
+	}
+
+	for _, requestCategory := range requestCategories {
+		err := tx.Where("name = ?", requestCategory.Name).
+			Assign(map[string]interface{}{
+				"is_replacement": requestCategory.IsReplacement,
+			}).
+			FirstOrCreate(&entity.RequestCategory{Name: requestCategory.Name}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedMajors creates the majors missing; a major is its name and education
+// level, there is nothing else to update.
+func seedMajors(tx *gorm.DB) error {
+	majors := []entity.Major{
+		{Major: "S1 Teknik Informatika", EducationLevel: entity.EducationLevelEnumBachelor},
+		{Major: "S2 Teknik Elektro", EducationLevel: entity.EducationLevelEnumBachelor},
+		{Major: "S3 Teknik Mesin", EducationLevel: entity.EducationLevelEnumBachelor},
+		{Major: "D1 Teknik Informatika", EducationLevel: entity.EducationLevelEnumD1},
+		{Major: "D2 Teknik Informatika", EducationLevel: entity.EducationLevelEnumD2},
+		{Major: "SD", EducationLevel: entity.EducationLevelEnumSD},
+		{Major: "SMP", EducationLevel: entity.EducationLevelEnumSMP},
+		{Major: "SMA", EducationLevel: entity.EducationLevelEnumSMA},
+		{Major: "S2 Teknik Informatika", EducationLevel: entity.EducationLevelEnumMaster},
+		{Major: "S3 Teknik Informatika", EducationLevel: entity.EducationLevelEnumDoctoral},
+		{Major: "Belum Sekolah", EducationLevel: entity.EducationLevelEnumTK},
+	}
+
+	for _, major := range majors {
+		err := tx.Where("major = ? AND education_level = ?", major.Major, major.EducationLevel).
+			FirstOrCreate(&entity.Major{Major: major.Major, EducationLevel: major.EducationLevel}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
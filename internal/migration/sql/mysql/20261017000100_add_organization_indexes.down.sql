@@ -0,0 +1,4 @@
+DROP INDEX idx_mp_planning_lines_job_header ON mp_planning_lines;
+DROP INDEX idx_mp_request_headers_for_organization ON mp_request_headers;
+DROP INDEX idx_mp_request_headers_organization ON mp_request_headers;
+DROP INDEX idx_mp_planning_headers_organization_period ON mp_planning_headers;
@@ -0,0 +1,5 @@
+-- the version of the documents updated with optimistic locking
+ALTER TABLE mp_planning_headers ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE mp_planning_lines ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE mp_request_headers ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE batch_headers ADD COLUMN version int NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+ALTER TABLE batch_headers DROP COLUMN escalated_level;
+ALTER TABLE batch_headers DROP COLUMN escalated_at;
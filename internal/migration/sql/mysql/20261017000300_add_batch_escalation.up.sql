@@ -0,0 +1,3 @@
+-- the approver type the SLA escalated a batch to, so it is escalated once per step
+ALTER TABLE batch_headers ADD COLUMN escalated_at datetime(3) NULL;
+ALTER TABLE batch_headers ADD COLUMN escalated_level varchar(255) NULL;
@@ -0,0 +1 @@
+ALTER TABLE mp_planning_line_imports DROP COLUMN header_version;
@@ -0,0 +1,4 @@
+DROP INDEX idx_mp_planning_lines_job_header;
+DROP INDEX idx_mp_request_headers_for_organization;
+DROP INDEX idx_mp_request_headers_organization;
+DROP INDEX idx_mp_planning_headers_organization_period;
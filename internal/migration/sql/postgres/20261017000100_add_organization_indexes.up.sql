@@ -0,0 +1,7 @@
+-- the organization scope of the planning and request lists
+CREATE INDEX idx_mp_planning_headers_organization_period ON mp_planning_headers (organization_id, mpp_period_id);
+CREATE INDEX idx_mp_request_headers_organization ON mp_request_headers (organization_id);
+CREATE INDEX idx_mp_request_headers_for_organization ON mp_request_headers (for_organization_id);
+-- the lines are looked up by job within a header; job_id leads so MySQL keeps
+-- its own index for the foreign key of the header
+CREATE INDEX idx_mp_planning_lines_job_header ON mp_planning_lines (job_id, mp_planning_header_id);
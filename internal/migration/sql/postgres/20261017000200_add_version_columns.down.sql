@@ -0,0 +1,4 @@
+ALTER TABLE batch_headers DROP COLUMN version;
+ALTER TABLE mp_request_headers DROP COLUMN version;
+ALTER TABLE mp_planning_lines DROP COLUMN version;
+ALTER TABLE mp_planning_headers DROP COLUMN version;
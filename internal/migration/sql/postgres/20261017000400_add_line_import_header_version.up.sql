@@ -0,0 +1,2 @@
+-- the planning version a line import dry run was checked against
+ALTER TABLE mp_planning_line_imports ADD COLUMN header_version int NOT NULL DEFAULT 0;
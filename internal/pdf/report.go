@@ -0,0 +1,377 @@
+// Package pdf lays out the printable documents of the service on top of
+// fpdf: A4 pages of fields, tables and signature boxes in the standard
+// Helvetica fonts, which every PDF reader has built in, so nothing needs to be
+// embedded and documents can be produced offline.
+package pdf
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Every measure is in points, on an A4 page.
+const (
+	margin      = 40.0
+	bodySize    = 9.0
+	lineHeight  = 12.0
+	cellPadding = 4.0
+	footerSpace = 24.0
+)
+
+// Letterhead is printed at the top of every page of a report.
+type Letterhead struct {
+	Company string
+	Address string
+	Title   string
+}
+
+// Field is a label and its value, printed side by side.
+type Field struct {
+	Label string
+	Value string
+}
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Column of a table. Width is a share of the width of the page; the widths of
+// a table need not add up to anything, they are scaled to fit.
+type Column struct {
+	Title string
+	Width float64
+	Align Align
+}
+
+// Row of a table. Bold rows are the subtotals.
+type Row struct {
+	Cells []string
+	Bold  bool
+}
+
+// Signature is one approver of the signature block.
+type Signature struct {
+	Role   string
+	Name   string
+	Status string
+	Date   string
+	Notes  string
+}
+
+// font is the style of the Helvetica face, "" for regular and "B" for bold.
+type font string
+
+const (
+	regular font = ""
+	bold    font = "B"
+)
+
+// Report lays a document out from top to bottom, starting a new page, with
+// the letterhead, whenever the next block does not fit.
+type Report struct {
+	doc          *fpdf.Fpdf
+	letterhead   Letterhead
+	encode       func(string) string
+	pageHeight   float64
+	contentWidth float64
+	y            float64
+}
+
+func NewReport(letterhead Letterhead) *Report {
+	doc := fpdf.New("P", "pt", "A4", "")
+	doc.SetMargins(margin, margin, margin)
+	doc.SetCellMargin(0)
+	doc.SetAutoPageBreak(false, 0)
+	doc.AliasNbPages("")
+
+	pageWidth, pageHeight := doc.GetPageSize()
+	r := &Report{
+		doc:          doc,
+		letterhead:   letterhead,
+		encode:       doc.UnicodeTranslatorFromDescriptor(""),
+		pageHeight:   pageHeight,
+		contentWidth: pageWidth - 2*margin,
+	}
+	doc.SetTitle(letterhead.Title, true)
+	doc.SetProducer("julong-manpower", true)
+	doc.SetFooterFunc(r.footer)
+
+	r.newPage()
+	return r
+}
+
+func (r *Report) newPage() {
+	r.doc.AddPage()
+	r.y = margin
+
+	r.text(margin, r.y+14, bold, 14, r.encode(r.letterhead.Company))
+	r.y += 18
+	if r.letterhead.Address != "" {
+		for _, line := range r.wrap(regular, 8, r.letterhead.Address, r.contentWidth) {
+			r.text(margin, r.y+9, regular, 8, line)
+			r.y += 10
+		}
+	}
+	r.y += 4
+	r.line(margin, r.y, margin+r.contentWidth, r.y, 1.2)
+	r.y += 18
+
+	title := r.encode(r.letterhead.Title)
+	r.text(margin+(r.contentWidth-r.width(bold, 12, title))/2, r.y, bold, 12, title)
+	r.y += 16
+}
+
+// footer numbers the page; fpdf puts the page count in place of its alias
+// when the document is written.
+func (r *Report) footer() {
+	y := r.pageHeight - footerSpace
+	r.text(margin, y, regular, 8, r.encode(r.letterhead.Title))
+
+	page := "Page " + strconv.Itoa(r.doc.PageNo()) + " of {nb}"
+	r.text(margin+r.contentWidth-r.width(regular, 8, page), y, regular, 8, page)
+}
+
+// ensure starts a new page unless height still fits on this one.
+func (r *Report) ensure(height float64) {
+	if r.y+height > r.pageHeight-footerSpace-12 {
+		r.newPage()
+	}
+}
+
+// Space leaves an empty gap.
+func (r *Report) Space(height float64) {
+	r.y += height
+}
+
+// Heading prints the title of a section.
+func (r *Report) Heading(text string) {
+	r.ensure(2*lineHeight + 6)
+	r.y += 6
+	r.text(margin, r.y+10, bold, 10, r.encode(text))
+	r.y += 14
+	r.line(margin, r.y, margin+r.contentWidth, r.y, 0.5)
+	r.y += 4
+}
+
+// Fields prints labels and values in two columns, wrapping long values.
+func (r *Report) Fields(fields []Field) {
+	labelWidth := r.contentWidth * 0.3
+	valueWidth := r.contentWidth - labelWidth
+	for _, field := range fields {
+		label := r.encode(field.Label)
+		for i, line := range r.wrap(regular, bodySize, field.Value, valueWidth) {
+			page := r.doc.PageNo()
+			r.ensure(lineHeight)
+			// the label goes again on top of a value that runs onto a new page
+			if i == 0 || r.doc.PageNo() != page {
+				r.text(margin, r.y+bodySize, bold, bodySize, label)
+			}
+			r.text(margin+labelWidth, r.y+bodySize, regular, bodySize, line)
+			r.y += lineHeight
+		}
+	}
+}
+
+// Table prints rows under a shaded header. The header is printed again on
+// every page the table runs onto.
+func (r *Report) Table(columns []Column, rows []Row) {
+	total := 0.0
+	for _, column := range columns {
+		total += column.Width
+	}
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		widths[i] = r.contentWidth * column.Width / total
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Title
+	}
+
+	headerHeight := r.rowHeight(bold, header, widths)
+	r.ensure(headerHeight + lineHeight + 2*cellPadding)
+	r.tableRow(columns, widths, header, bold, 0.85)
+
+	for _, row := range rows {
+		rowFont := regular
+		if row.Bold {
+			rowFont = bold
+		}
+		if r.y+r.rowHeight(rowFont, row.Cells, widths) > r.pageHeight-footerSpace-12 {
+			r.newPage()
+			r.tableRow(columns, widths, header, bold, 0.85)
+		}
+		gray := -1.0
+		if row.Bold {
+			gray = 0.95
+		}
+		r.tableRow(columns, widths, row.Cells, rowFont, gray)
+	}
+	r.y += 6
+}
+
+func (r *Report) rowHeight(rowFont font, cells []string, widths []float64) float64 {
+	lines := 1
+	for i, cell := range cells {
+		if i >= len(widths) {
+			break
+		}
+		if n := len(r.wrap(rowFont, bodySize, cell, widths[i]-2*cellPadding)); n > lines {
+			lines = n
+		}
+	}
+	return float64(lines)*lineHeight + 2*cellPadding - (lineHeight - bodySize)
+}
+
+func (r *Report) tableRow(columns []Column, widths []float64, cells []string, rowFont font, gray float64) {
+	height := r.rowHeight(rowFont, cells, widths)
+	x := margin
+	for i, width := range widths {
+		r.rect(x, r.y, width, height, gray)
+		if i < len(cells) {
+			for j, line := range r.wrap(rowFont, bodySize, cells[i], width-2*cellPadding) {
+				lineX := x + cellPadding
+				if columns[i].Align == AlignRight {
+					lineX = x + width - cellPadding - r.width(rowFont, bodySize, line)
+				}
+				r.text(lineX, r.y+cellPadding+bodySize+float64(j)*lineHeight-1, rowFont, bodySize, line)
+			}
+		}
+		x += width
+	}
+	r.y += height
+}
+
+// signaturesPerRow is how many signatures are printed side by side.
+const signaturesPerRow = 4
+
+// Signatures prints a box for each approver, with room above the name to
+// sign by hand.
+func (r *Report) Signatures(signatures []Signature) {
+	width := r.contentWidth / signaturesPerRow
+	for start := 0; start < len(signatures); start += signaturesPerRow {
+		end := start + signaturesPerRow
+		if end > len(signatures) {
+			end = len(signatures)
+		}
+
+		height := 0.0
+		for _, signature := range signatures[start:end] {
+			if h := r.signatureHeight(signature, width); h > height {
+				height = h
+			}
+		}
+		r.ensure(height)
+
+		for i, signature := range signatures[start:end] {
+			r.signature(margin+float64(i)*width, width, height, signature)
+		}
+		r.y += height
+	}
+}
+
+const signatureSpace = 44.0
+
+func (r *Report) signatureHeight(signature Signature, width float64) float64 {
+	lines := len(r.wrap(bold, bodySize, signature.Role, width-2*cellPadding))
+	lines += len(r.wrap(bold, bodySize, signature.Name, width-2*cellPadding))
+	lines += 2
+	if signature.Notes != "" {
+		lines += len(r.wrap(regular, 8, signature.Notes, width-2*cellPadding))
+	}
+	return float64(lines)*lineHeight + signatureSpace + 2*cellPadding
+}
+
+func (r *Report) signature(x float64, width float64, height float64, signature Signature) {
+	r.rect(x, r.y, width, height, -1)
+	textWidth := width - 2*cellPadding
+
+	y := r.y + cellPadding + bodySize
+	for _, line := range r.wrap(bold, bodySize, signature.Role, textWidth) {
+		r.text(x+(width-r.width(bold, bodySize, line))/2, y, bold, bodySize, line)
+		y += lineHeight
+	}
+
+	y += signatureSpace
+	r.line(x+2*cellPadding, y-bodySize-3, x+width-2*cellPadding, y-bodySize-3, 0.5)
+	for _, line := range r.wrap(bold, bodySize, signature.Name, textWidth) {
+		r.text(x+(width-r.width(bold, bodySize, line))/2, y, bold, bodySize, line)
+		y += lineHeight
+	}
+
+	for _, text := range []string{signature.Status, signature.Date} {
+		line := r.encode(text)
+		r.text(x+(width-r.width(regular, bodySize, line))/2, y, regular, bodySize, line)
+		y += lineHeight
+	}
+	if signature.Notes != "" {
+		for _, line := range r.wrap(regular, 8, signature.Notes, textWidth) {
+			r.text(x+cellPadding, y, regular, 8, line)
+			y += lineHeight
+		}
+	}
+}
+
+// Write writes the report to w as a PDF file.
+func (r *Report) Write(w io.Writer) error {
+	return r.doc.Output(w)
+}
+
+// text writes a line already encoded for the font with its baseline at y.
+func (r *Report) text(x float64, y float64, textFont font, size float64, line string) {
+	r.doc.SetFont("Helvetica", string(textFont), size)
+	r.doc.Text(x, y, line)
+}
+
+// width is how wide a line already encoded for the font is.
+func (r *Report) width(textFont font, size float64, line string) float64 {
+	r.doc.SetFont("Helvetica", string(textFont), size)
+	return r.doc.GetStringWidth(line)
+}
+
+// wrap encodes text for the font and breaks it into lines no wider than
+// width, between words where it can. Line breaks in text are kept, and empty
+// text is one empty line.
+func (r *Report) wrap(textFont font, size float64, text string, width float64) []string {
+	r.doc.SetFont("Helvetica", string(textFont), size)
+
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		paragraph = strings.Join(strings.Fields(paragraph), " ")
+		split := r.doc.SplitLines([]byte(r.encode(paragraph)), width)
+		if len(split) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		for _, line := range split {
+			lines = append(lines, strings.TrimSpace(string(line)))
+		}
+	}
+	return lines
+}
+
+// line draws a line of the given width between two points.
+func (r *Report) line(x1 float64, y1 float64, x2 float64, y2 float64, lineWidth float64) {
+	r.doc.SetLineWidth(lineWidth)
+	r.doc.Line(x1, y1, x2, y2)
+}
+
+// rect draws an outlined box with its top left corner at x, y. Gray is the
+// fill, from 0 for black to 1 for white, or below 0 for none.
+func (r *Report) rect(x float64, y float64, width float64, height float64, gray float64) {
+	r.doc.SetLineWidth(0.5)
+	if gray < 0 {
+		r.doc.Rect(x, y, width, height, "D")
+		return
+	}
+	level := int(gray * 255)
+	r.doc.SetFillColor(level, level, level)
+	r.doc.Rect(x, y, width, height, "FD")
+}
@@ -0,0 +1,129 @@
+package rabbitmq
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// Session works on one broker connection until ctx is done, when it returns
+// nil, or until it fails. A session that fails makes the manager reconnect.
+type Session func(ctx context.Context, conn *amqp091.Connection) error
+
+// ConnectionManager keeps a connection to the broker open and runs the
+// sessions on it. When the connection drops it reconnects with an exponential
+// backoff and starts the sessions again, so queues are declared again and
+// consuming and publishing resume.
+type ConnectionManager struct {
+	Log       *logrus.Logger
+	URL       string
+	RPCClient *messaging.RPCClient
+	ready     atomic.Bool
+}
+
+func NewConnectionManager(log *logrus.Logger, url string, rpcClient *messaging.RPCClient) *ConnectionManager {
+	return &ConnectionManager{
+		Log:       log,
+		URL:       url,
+		RPCClient: rpcClient,
+	}
+}
+
+// Ready reports whether the manager is connected and the sessions are running.
+func (m *ConnectionManager) Ready() bool {
+	return m.ready.Load()
+}
+
+func (m *ConnectionManager) setReady(ready bool) {
+	m.ready.Store(ready)
+	m.RPCClient.SetReady(ready)
+}
+
+// Run connects and runs the sessions until ctx is done. Sessions are stopped
+// one by one in reverse order and each is waited for, so a consumer
+// registered after the producer finishes its in-flight message while the
+// producer can still publish the reply.
+func (m *ConnectionManager) Run(ctx context.Context, sessions ...Session) {
+	backoff := minReconnectBackoff
+	for {
+		conn, err := amqp091.Dial(m.URL)
+		if err != nil {
+			m.Log.Errorf("[ConnectionManager.Run] fail to connect, retry in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxReconnectBackoff)
+			continue
+		}
+		backoff = minReconnectBackoff
+		m.Log.Infof("[ConnectionManager.Run] connected to rabbitmq")
+
+		closed := conn.NotifyClose(make(chan *amqp091.Error, 1))
+		stops := m.startSessions(ctx, conn, sessions)
+		m.setReady(true)
+
+		select {
+		case <-ctx.Done():
+			m.setReady(false)
+			stopSessions(stops)
+			if err := conn.Close(); err != nil {
+				m.Log.Errorf("[ConnectionManager.Run] fail to close connection: %v", err)
+			}
+			m.Log.Infof("[ConnectionManager.Run] connection closed")
+			return
+		case amqpErr := <-closed:
+			m.setReady(false)
+			m.Log.Errorf("[ConnectionManager.Run] connection lost, reconnecting: %v", amqpErr)
+			stopSessions(stops)
+		}
+	}
+}
+
+// stopSessions stops the sessions in reverse order.
+func stopSessions(stops []func()) {
+	for i := len(stops) - 1; i >= 0; i-- {
+		stops[i]()
+	}
+}
+
+// startSessions runs every session in its own goroutine and returns for each
+// one a function that stops it and waits until it returned.
+func (m *ConnectionManager) startSessions(ctx context.Context, conn *amqp091.Connection, sessions []Session) []func() {
+	stops := make([]func(), 0, len(sessions))
+	for _, session := range sessions {
+		sessionCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func(session Session) {
+			defer wg.Done()
+			if err := session(sessionCtx, conn); err != nil {
+				m.Log.Errorf("[ConnectionManager.startSessions] session failed: %v", err)
+				// closing the connection restarts every session on a new one
+				conn.Close()
+			}
+		}(session)
+		stops = append(stops, func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+	return stops
+}
+
+func ConnectionManagerFactory(viper *viper.Viper, log *logrus.Logger) *ConnectionManager {
+	rpcClient := messaging.RPCClientFactory(log)
+	return NewConnectionManager(log, viper.GetString("rabbitmq.url"), rpcClient)
+}
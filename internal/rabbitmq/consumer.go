@@ -1,302 +1,267 @@
 package rabbitmq
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"os"
+	"fmt"
+	"sync"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
-	"github.com/IlhamSetiaji/julong-manpower-be/utils"
-	"github.com/google/uuid"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-func InitConsumer(viper *viper.Viper, log *logrus.Logger) {
-	// conn
-	conn, err := amqp091.Dial(viper.GetString("rabbitmq.url"))
-	if err != nil {
-		log.Printf("ERROR: fail init consumer: %s", err.Error())
-		os.Exit(1)
-	}
+const (
+	// DeadLetterQueue keeps the messages that failed on redelivery too, with
+	// the failure in the x-error header.
+	DeadLetterQueue = "julong_manpower_dead_letter"
 
-	log.Printf("INFO: done init consumer conn")
+	defaultPrefetch = 10
+	defaultWorkers  = 4
+)
 
-	// create channel
-	amqpChannel, err := conn.Channel()
-	if err != nil {
-		log.Printf("ERROR: fail create channel: %s", err.Error())
-		os.Exit(1)
-	}
+// ConsumerSession consumes the julong_manpower queue with a pool of workers.
+// Each message is acked once it is handled, see handleDelivery. When stopped
+// the workers stop taking messages and finish the ones in flight.
+func ConsumerSession(viper *viper.Viper, log *logrus.Logger) Session {
+	rpcClient := messaging.RPCClientFactory(log)
+	registry := HandlerRegistryFactory(viper, log)
 
-	// create queue
-	queue, err := amqpChannel.QueueDeclare(
-		"julong_manpower", // channelname
-		true,              // durable
-		false,             // delete when unused
-		false,             // exclusive
-		false,             // no-wait
-		nil,               // arguments
-	)
-	if err != nil {
-		log.Printf("ERROR: fail create queue: %s", err.Error())
-		os.Exit(1)
+	prefetch := viper.GetInt("rabbitmq.prefetch")
+	if prefetch <= 0 {
+		prefetch = defaultPrefetch
 	}
-
-	// channel
-	msgChannel, err := amqpChannel.Consume(
-		queue.Name, // queue
-		"",         // consumer
-		false,      // auto-ack
-		false,      // exclusive
-		false,      // no-local
-		false,      // no-wait
-		nil,        // args
-	)
-	if err != nil {
-		log.Printf("ERROR: fail create channel: %s", err.Error())
-		os.Exit(1)
+	workers := viper.GetInt("rabbitmq.workers")
+	if workers <= 0 {
+		workers = defaultWorkers
 	}
 
-	// consume
-	for {
-		select {
-		case msg := <-msgChannel:
-			// unmarshal
-			docRply := &response.RabbitMQResponse{}
-			docMsg := &request.RabbitMQRequest{}
-			err = json.Unmarshal(msg.Body, docRply)
-			if err != nil {
-				log.Printf("ERROR: fail unmarshl: %s", msg.Body)
-				continue
-			}
-			log.Printf("INFO: received docRply: %v", docRply)
-
-			err = json.Unmarshal(msg.Body, docMsg)
-			if err != nil {
-				log.Printf("ERROR: fail unmarshl: %s", msg.Body)
-				continue
-			}
-			log.Printf("INFO: received docMsg: %v", docMsg)
-
-			// ack for message
-			err = msg.Ack(true)
-			if err != nil {
-				log.Printf("ERROR: fail to ack: %s", err.Error())
-			}
-
-			// find waiting channel(with uid) and forward the reply to it
-			if rchan, ok := utils.Rchans[docRply.ID]; ok {
-				rchan <- *docRply
-			}
-
-			handleMsg(docMsg, log, viper)
+	return func(ctx context.Context, conn *amqp091.Connection) error {
+		// create channel
+		amqpChannel, err := conn.Channel()
+		if err != nil {
+			log.Printf("ERROR: fail create channel: %s", err.Error())
+			return err
 		}
-	}
-}
+		defer amqpChannel.Close()
 
-func handleMsg(docMsg *request.RabbitMQRequest, log *logrus.Logger, viper *viper.Viper) {
-	// switch case
-	var msgData map[string]interface{}
-
-	switch docMsg.MessageType {
-	case "reply":
-		log.Printf("INFO: received reply message")
-		return
-	case "find_job_plafon_by_job_id":
-		jobID, ok := docMsg.MessageData["job_id"].(string)
-		if !ok {
-			log.Printf("Invalid request format: missing 'job_id'")
-			msgData = map[string]interface{}{
-				"error": errors.New("missing 'job_id'").Error(),
-			}
-			break
+		// limit the unacked messages the broker hands to the workers
+		if err := amqpChannel.Qos(prefetch, 0, false); err != nil {
+			log.Printf("ERROR: fail set qos: %s", err.Error())
+			return err
 		}
 
-		messageFactory := messaging.JobPlafonMessageFactory(log)
-		message, err := messageFactory.FindJobPlafonByJobIDMessage(uuid.MustParse(jobID))
-
+		// create queues
+		queue, err := amqpChannel.QueueDeclare(
+			messaging.ReplyQueue, // channelname
+			true,                 // durable
+			false,                // delete when unused
+			false,                // exclusive
+			false,                // no-wait
+			nil,                  // arguments
+		)
 		if err != nil {
-			log.Printf("Failed to execute message: %v", err)
-			msgData = map[string]interface{}{
-				"error": err.Error(),
-			}
-			break
+			log.Printf("ERROR: fail create queue: %s", err.Error())
+			return err
 		}
 
-		msgData = map[string]interface{}{
-			"id":     message.ID,
-			"plafon": message.Plafon,
-		}
-	case "find_mp_request_header_by_id":
-		mpRequestHeaderID, ok := docMsg.MessageData["mp_request_header_id"].(string)
-		if !ok {
-			log.Printf("Invalid request format: missing 'mp_request_header_id'")
-			msgData = map[string]interface{}{
-				"error": errors.New("missing 'mp_request_header_id'").Error(),
-			}
-			break
+		_, err = amqpChannel.QueueDeclare(
+			DeadLetterQueue, // channelname
+			true,            // durable
+			false,           // delete when unused
+			false,           // exclusive
+			false,           // no-wait
+			nil,             // arguments
+		)
+		if err != nil {
+			log.Printf("ERROR: fail create queue: %s", err.Error())
+			return err
 		}
 
-		uc := usecase.MPRequestUseCaseFactory(viper, log)
-		mpRequestHeader, err := uc.FindByIDOnly(uuid.MustParse(mpRequestHeaderID))
+		// channel
+		msgChannel, err := amqpChannel.Consume(
+			queue.Name, // queue
+			"",         // consumer
+			false,      // auto-ack
+			false,      // exclusive
+			false,      // no-local
+			false,      // no-wait
+			nil,        // args
+		)
 		if err != nil {
-			log.Printf("Failed to execute usecase: %v", err)
-			msgData = map[string]interface{}{
-				"error": err.Error(),
-			}
-			break
+			log.Printf("ERROR: fail create channel: %s", err.Error())
+			return err
 		}
 
-		msgData = map[string]interface{}{
-			"mp_request_header": mpRequestHeader,
-		}
-	case "find_mp_request_header_by_id_tidak_lengkap":
-		mpRequestHeaderID, ok := docMsg.MessageData["mp_request_header_id"].(string)
-		if !ok {
-			log.Printf("Invalid request format: missing 'mp_request_header_id'")
-			msgData = map[string]interface{}{
-				"error": errors.New("missing 'mp_request_header_id'").Error(),
-			}
-			break
-		}
+		log.Printf("INFO: done init consumer channel with %d workers", workers)
 
-		uc := usecase.MPRequestUseCaseFactory(viper, log)
-		mpRequestHeader, err := uc.FindByIDOnlyForMessage(uuid.MustParse(mpRequestHeaderID))
-		if err != nil {
-			log.Printf("Failed to execute usecase: %v", err)
-			msgData = map[string]interface{}{
-				"error": err.Error(),
-			}
-			break
-		}
+		// handlers keep running on shutdown so in-flight messages are finished
+		handlerCtx := context.WithoutCancel(ctx)
 
-		msgData = map[string]interface{}{
-			"mp_request_header": mpRequestHeader,
-		}
-	case "find_mp_request_headers_by_majors":
-		majorStrings, ok := docMsg.MessageData["majors"].([]interface{})
-		if !ok {
-			log.Printf("Invalid request format: missing 'majors'")
-			msgData = map[string]interface{}{
-				"error": errors.New("missing 'majors'").Error(),
-			}
-			break
+		// consume
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case msg, ok := <-msgChannel:
+						if !ok {
+							return
+						}
+						handleDelivery(handlerCtx, log, amqpChannel, registry, rpcClient, msg)
+					}
+				}
+			}()
 		}
+		wg.Wait()
 
-		educationLevelStrings, ok := docMsg.MessageData["education_levels"].([]interface{})
-		if !ok {
-			log.Printf("Invalid request format: missing 'education_level'")
-			msgData = map[string]interface{}{
-				"error": errors.New("missing 'education_level'").Error(),
-			}
-			break
+		if ctx.Err() != nil {
+			return nil
 		}
+		return errors.New("consumer delivery channel closed")
+	}
+}
 
-		majors := make([]string, len(majorStrings))
-		for i, major := range majorStrings {
-			majors[i], ok = major.(string)
-			if !ok {
-				log.Printf("Invalid request format: 'majors' should be a list of strings")
-				msgData = map[string]interface{}{
-					"error": errors.New("'majors' should be a list of strings").Error(),
-				}
-				break
-			}
-		}
+// handleDelivery hands replies to the calls waiting for them and answers
+// requests with their registered handler. A message is acked after it is
+// handled. A retryable failure is requeued once and dead-lettered when it
+// fails again.
+func handleDelivery(ctx context.Context, log *logrus.Logger, amqpChannel *amqp091.Channel, registry *HandlerRegistry, rpcClient *messaging.RPCClient, msg amqp091.Delivery) {
+	// unmarshal
+	docRply := &response.RabbitMQResponse{}
+	docMsg := &request.RabbitMQRequest{}
+	if err := json.Unmarshal(msg.Body, docRply); err != nil {
+		log.Printf("ERROR: fail unmarshl: %s", msg.Body)
+		deadLetter(log, amqpChannel, msg, err)
+		return
+	}
+	if err := json.Unmarshal(msg.Body, docMsg); err != nil {
+		log.Printf("ERROR: fail unmarshl: %s", msg.Body)
+		deadLetter(log, amqpChannel, msg, err)
+		return
+	}
 
-		majorUseCase := usecase.MajorUsecaseFactory(log)
-		var majorIds []string
-		for _, major := range majors {
-			eduLevel, ok := educationLevelStrings[0].(string)
-			if !ok {
-				log.Printf("Invalid request format: 'education_level' should be a string")
-				msgData = map[string]interface{}{
-					"error": errors.New("'education_level' should be a string").Error(),
-				}
-				break
-			}
-			majorResponse, err := majorUseCase.FindILikeMajorAndEducationLevel(major, eduLevel)
-			if err != nil {
-				log.Printf("Failed to execute usecase: %v", err)
-				msgData = map[string]interface{}{
-					"error": err.Error(),
-				}
-				break
-			}
-			if majorResponse == nil {
-				log.Printf("Major not found: %s", major)
-				msgData = map[string]interface{}{
-					"error": errors.New("major not found").Error(),
-				}
-				break
-			}
+	// forward the reply to the call waiting for it, services that do not
+	// set the correlation id reply with the request id in the body
+	correlationID := msg.CorrelationId
+	if correlationID == "" {
+		correlationID = docRply.ID
+	}
+	if rpcClient.Deliver(correlationID, *docRply) || docMsg.MessageType == "reply" {
+		ack(log, msg)
+		return
+	}
 
-			log.Printf("INFO: found major: %v", majorResponse)
+	log.Printf("INFO: received docMsg: %v", docMsg)
 
-			for _, m := range *majorResponse {
-				majorIds = append(majorIds, m.ID)
-			}
-		}
+	if docMsg.ReplyTo == "" {
+		docMsg.ReplyTo = msg.ReplyTo
+	}
 
-		var mpRequestHeadersResp []*response.MPRequestHeaderResponse
-		if len(majorIds) >= 0 {
-			mprUseCase := usecase.MPRequestUseCaseFactory(viper, log)
-			mpRequestHeaders, err := mprUseCase.FindAllByMajorIdsMessage(majorIds)
-			if err != nil {
-				log.Printf("Failed to execute usecase: %v", err)
-				msgData = map[string]interface{}{
-					"error": err.Error(),
-				}
-				break
-			}
-			mpRequestHeadersResp = mpRequestHeaders
-		} else {
-			log.Printf("No majors found")
-			msgData = map[string]interface{}{
-				"error": errors.New("no majors found").Error(),
-			}
-			break
-		}
+	handler, ok := registry.Handler(docMsg.MessageType)
+	if !ok {
+		log.Printf("Unknown message type, please recheck your type: %s", docMsg.MessageType)
+		reply(docMsg, correlationID, errorMessageData(ErrUnknownMessageType))
+		ack(log, msg)
+		return
+	}
 
-		if len(mpRequestHeadersResp) == 0 {
-			log.Printf("No MPRequestHeaders found for the given majors")
-			msgData = map[string]interface{}{
-				"error": errors.New("no MPRequestHeaders found for the given majors").Error(),
+	msgData, err := runHandler(ctx, handler, docMsg.MessageData)
+	if err != nil && IsRetryable(err) {
+		if !msg.Redelivered {
+			log.Printf("ERROR: fail to handle %s, requeue: %v", docMsg.MessageType, err)
+			if err := msg.Nack(false, true); err != nil {
+				log.Printf("ERROR: fail to nack: %s", err.Error())
 			}
-			break
+			return
 		}
 
-		var mprIds []string
-		for _, mpr := range mpRequestHeadersResp {
-			mprIds = append(mprIds, mpr.ID.String())
-		}
+		log.Printf("ERROR: fail to handle %s again, dead-letter: %v", docMsg.MessageType, err)
+		reply(docMsg, correlationID, errorMessageData(err))
+		deadLetter(log, amqpChannel, msg, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to handle %s: %v", docMsg.MessageType, err)
+		msgData = errorMessageData(err)
+	}
 
-		log.Printf("INFO: found MPRequestHeaders: %v", mprIds)
-		msgData = map[string]interface{}{
-			"mp_request_headers": mpRequestHeadersResp,
-		}
-	default:
-		log.Printf("Unknown message type, please recheck your type: %s", docMsg.MessageType)
+	reply(docMsg, correlationID, msgData)
+	ack(log, msg)
+}
 
-		msgData = map[string]interface{}{
-			"error": errors.New("unknown message type").Error(),
+// runHandler turns a panicking handler into a retryable failure.
+func runHandler(ctx context.Context, handler MessageHandler, messageData map[string]interface{}) (msgData map[string]interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = Retryable(fmt.Errorf("handler panicked: %v", recovered))
 		}
+	}()
+
+	return handler(ctx, messageData)
+}
+
+func errorMessageData(err error) map[string]interface{} {
+	return map[string]interface{}{
+		"error": err.Error(),
 	}
-	// reply
-	reply := response.RabbitMQResponse{
-		ID: docMsg.ID,
-		// MessageType: docMsg.MessageType,
-		MessageType: "reply",
-		MessageData: msgData,
+}
+
+func reply(docMsg *request.RabbitMQRequest, correlationID string, msgData map[string]interface{}) {
+	if docMsg.ReplyTo == "" {
+		return
 	}
-	msg := RabbitMsg{
-		QueueName: docMsg.ReplyTo,
-		Reply:     reply,
+
+	rchan <- RabbitMsg{
+		QueueName:     docMsg.ReplyTo,
+		CorrelationID: correlationID,
+		Reply: response.RabbitMQResponse{
+			ID:          docMsg.ID,
+			MessageType: "reply",
+			MessageData: msgData,
+		},
 	}
-	rchan <- msg
+}
+
+func ack(log *logrus.Logger, msg amqp091.Delivery) {
+	if err := msg.Ack(false); err != nil {
+		log.Printf("ERROR: fail to ack: %s", err.Error())
+	}
+}
+
+// deadLetter moves the message to the dead letter queue. When that fails the
+// message is requeued rather than lost.
+func deadLetter(log *logrus.Logger, amqpChannel *amqp091.Channel, msg amqp091.Delivery, cause error) {
+	err := amqpChannel.Publish(
+		"",              // exchange
+		DeadLetterQueue, // routing key
+		false,           // mandatory
+		false,           // immediate
+		amqp091.Publishing{
+			ContentType:   msg.ContentType,
+			CorrelationId: msg.CorrelationId,
+			ReplyTo:       msg.ReplyTo,
+			Headers: amqp091.Table{
+				"x-error": cause.Error(),
+			},
+			Body: msg.Body,
+		},
+	)
+	if err != nil {
+		log.Printf("ERROR: fail to dead-letter: %s", err.Error())
+		if err := msg.Nack(false, true); err != nil {
+			log.Printf("ERROR: fail to nack: %s", err.Error())
+		}
+		return
+	}
+
+	ack(log, msg)
 }
@@ -0,0 +1,128 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// messageHandlers answers the requests other services send to the
+// julong_manpower queue. Its use cases are built once and shared by the
+// consumer workers.
+type messageHandlers struct {
+	Log                 *logrus.Logger
+	JobPlafonRepository repository.IJobPlafonRepository
+	MPRequestUseCase    usecase.IMPRequestUseCase
+	MajorUseCase        usecase.IMajorUsecase
+	LookupCache         *messaging.LookupCache
+}
+
+func (h *messageHandlers) findJobPlafonByJobID(ctx context.Context, payload *request.FindJobPlafonByJobIDMessageRequest) (map[string]interface{}, error) {
+	jobPlafon, err := h.JobPlafonRepository.FindByJobId(uuid.MustParse(payload.JobID))
+	if err != nil {
+		return nil, Retryable(err)
+	}
+
+	if jobPlafon == nil {
+		return nil, errors.New("job plafon not found")
+	}
+
+	return map[string]interface{}{
+		"id":     jobPlafon.ID,
+		"plafon": jobPlafon.Plafon,
+	}, nil
+}
+
+func (h *messageHandlers) findMPRequestHeaderByID(ctx context.Context, payload *request.FindMPRequestHeaderByIDMessageRequest) (map[string]interface{}, error) {
+	mpRequestHeader, err := h.MPRequestUseCase.FindByIDOnly(uuid.MustParse(payload.MPRequestHeaderID))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"mp_request_header": mpRequestHeader,
+	}, nil
+}
+
+func (h *messageHandlers) findMPRequestHeaderByIDMinimal(ctx context.Context, payload *request.FindMPRequestHeaderByIDMessageRequest) (map[string]interface{}, error) {
+	mpRequestHeader, err := h.MPRequestUseCase.FindByIDOnlyForMessage(uuid.MustParse(payload.MPRequestHeaderID))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"mp_request_header": mpRequestHeader,
+	}, nil
+}
+
+// findMPRequestHeadersByMajors finds the requests of the majors matching the
+// given names at the first education level.
+func (h *messageHandlers) findMPRequestHeadersByMajors(ctx context.Context, payload *request.FindMPRequestHeadersByMajorsMessageRequest) (map[string]interface{}, error) {
+	var majorIDs []string
+	for _, major := range payload.Majors {
+		majorResponses, err := h.MajorUseCase.FindILikeMajorAndEducationLevel(major, payload.EducationLevels[0])
+		if err != nil {
+			return nil, Retryable(err)
+		}
+		if majorResponses == nil {
+			return nil, errors.New("major not found")
+		}
+
+		for _, majorResponse := range *majorResponses {
+			majorIDs = append(majorIDs, majorResponse.ID)
+		}
+	}
+
+	mpRequestHeaders, err := h.MPRequestUseCase.FindAllByMajorIdsMessage(majorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mpRequestHeaders) == 0 {
+		return nil, errors.New("no MPRequestHeaders found for the given majors")
+	}
+
+	return map[string]interface{}{
+		"mp_request_headers": mpRequestHeaders,
+	}, nil
+}
+
+// portalDataChanged drops the changed portal data from the lookup cache, so
+// the next lookup reads it from the portal again.
+func (h *messageHandlers) portalDataChanged(ctx context.Context, payload *request.PortalDataChangedMessageRequest) (map[string]interface{}, error) {
+	if err := h.LookupCache.Invalidate(messaging.LookupKind(payload.Entity), payload.IDs); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"invalidated": len(payload.IDs),
+	}, nil
+}
+
+// HandlerRegistryFactory registers the handler of every message type the
+// julong_manpower queue answers.
+func HandlerRegistryFactory(viper *viper.Viper, log *logrus.Logger) *HandlerRegistry {
+	h := &messageHandlers{
+		Log:                 log,
+		JobPlafonRepository: repository.JobPlafonRepositoryFactory(log),
+		MPRequestUseCase:    usecase.MPRequestUseCaseFactory(viper, log),
+		MajorUseCase:        usecase.MajorUsecaseFactory(log),
+		LookupCache:         messaging.LookupCacheFactory(log),
+	}
+
+	registry := NewHandlerRegistry(log, config.NewValidator(viper))
+	Register(registry, "find_job_plafon_by_job_id", h.findJobPlafonByJobID)
+	Register(registry, "find_mp_request_header_by_id", h.findMPRequestHeaderByID)
+	Register(registry, "find_mp_request_header_by_id_tidak_lengkap", h.findMPRequestHeaderByIDMinimal)
+	Register(registry, "find_mp_request_headers_by_majors", h.findMPRequestHeadersByMajors)
+	Register(registry, "portal_data_changed", h.portalDataChanged)
+	return registry
+}
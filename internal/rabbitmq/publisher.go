@@ -1,92 +1,186 @@
 package rabbitmq
 
 import (
+	"context"
 	"encoding/json"
-	"os"
+	"errors"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
 	"github.com/IlhamSetiaji/julong-manpower-be/utils"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 )
 
 type RabbitMsg struct {
-	QueueName string                    `json:"queueName"`
-	Reply     response.RabbitMQResponse `json:"reply"`
+	QueueName     string                    `json:"queueName"`
+	CorrelationID string                    `json:"correlationId"`
+	Reply         response.RabbitMQResponse `json:"reply"`
 }
 
 var rchan = make(chan RabbitMsg, 10)
 
-func InitProducer(viper *viper.Viper, log *logrus.Logger) {
-	// conn
-	conn, err := amqp091.Dial(viper.GetString("rabbitmq.url"))
-	if err != nil {
-		log.Printf("ERROR: fail init consumer: %s", err.Error())
-		os.Exit(1)
+// ProducerSession publishes the outgoing requests, the replies of the consumer
+// and the domain events. When stopped it first publishes what is still queued.
+func ProducerSession(log *logrus.Logger) Session {
+	return func(ctx context.Context, conn *amqp091.Connection) error {
+		// create channel
+		amqpChannel, err := conn.Channel()
+		if err != nil {
+			log.Printf("ERROR: fail create channel: %s", err.Error())
+			return err
+		}
+		defer amqpChannel.Close()
+
+		// events go through their own channel in confirm mode so the outbox
+		// only marks an event sent once the broker has it
+		eventChannel, err := conn.Channel()
+		if err != nil {
+			log.Printf("ERROR: fail create event channel: %s", err.Error())
+			return err
+		}
+		defer eventChannel.Close()
+
+		if err := eventChannel.Confirm(false); err != nil {
+			log.Printf("ERROR: fail enable publisher confirms: %s", err.Error())
+			return err
+		}
+
+		declaredExchanges := make(map[string]bool)
+
+		log.Printf("INFO: done init producer channel")
+
+		for {
+			select {
+			case msg := <-utils.Pchan:
+				reportPublish(msg, publishMessage(log, amqpChannel, msg))
+			case msg := <-rchan:
+				publishReply(log, amqpChannel, msg)
+			case msg := <-utils.Echan:
+				publishEvent(ctx, eventChannel, declaredExchanges, msg)
+				if eventChannel.IsClosed() {
+					// a failed declare or publish closes the channel, reconnect
+					return errors.New("event channel closed")
+				}
+			case <-ctx.Done():
+				for {
+					select {
+					case msg := <-utils.Pchan:
+						reportPublish(msg, publishMessage(log, amqpChannel, msg))
+					case msg := <-rchan:
+						publishReply(log, amqpChannel, msg)
+					case msg := <-utils.Echan:
+						publishEvent(context.WithoutCancel(ctx), eventChannel, declaredExchanges, msg)
+					default:
+						return nil
+					}
+				}
+			}
+		}
 	}
+}
 
-	log.Printf("INFO: done init producer conn")
+func publishMessage(log *logrus.Logger, amqpChannel *amqp091.Channel, msg utils.RabbitMsg) error {
+	// marshal
+	data, err := json.Marshal(&msg.Message)
+	if err != nil {
+		log.Printf("ERROR: fail marshal: %s", err.Error())
+		return err
+	}
 
-	// create channel
-	amqpChannel, err := conn.Channel()
+	// publish message
+	err = amqpChannel.Publish(
+		"",            // exchange
+		msg.QueueName, // routing key
+		false,         // mandatory
+		false,         // immediate
+		amqp091.Publishing{
+			ContentType:   "text/plain",
+			CorrelationId: msg.Message.ID,
+			ReplyTo:       msg.Message.ReplyTo,
+			Body:          data,
+		},
+	)
 	if err != nil {
-		log.Printf("ERROR: fail create channel: %s", err.Error())
-		os.Exit(1)
+		log.Printf("ERROR: fail publish msg: %s", err.Error())
+		return err
 	}
 
-	for {
-		select {
-		case msg := <-utils.Pchan:
-			// marshal
-			data, err := json.Marshal(&msg.Message)
-			if err != nil {
-				log.Printf("ERROR: fail marshal: %s", err.Error())
-				continue
-			}
+	log.Printf("INFO: published msg: %v", msg.Message)
+	return nil
+}
 
-			// publish message
-			err = amqpChannel.Publish(
-				"",            // exchange
-				msg.QueueName, // routing key
-				false,         // mandatory
-				false,         // immediate
-				amqp091.Publishing{
-					ContentType: "text/plain",
-					Body:        data,
-				},
-			)
-			if err != nil {
-				log.Printf("ERROR: fail publish msg: %s", err.Error())
-				continue
-			}
+// reportPublish hands the outcome of publishing msg to the call waiting for it.
+func reportPublish(msg utils.RabbitMsg, err error) {
+	if msg.Result != nil {
+		msg.Result <- err
+	}
+}
 
-			log.Printf("INFO: published msg: %v", msg.Message)
-		case msg := <-rchan:
-			// marshal
-			data, err := json.Marshal(&msg.Reply)
-			if err != nil {
-				log.Printf("ERROR: fail marshal: %s", err.Error())
-				continue
-			}
+func publishReply(log *logrus.Logger, amqpChannel *amqp091.Channel, msg RabbitMsg) {
+	// marshal
+	data, err := json.Marshal(&msg.Reply)
+	if err != nil {
+		log.Printf("ERROR: fail marshal: %s", err.Error())
+		return
+	}
 
-			// publish message
-			err = amqpChannel.Publish(
-				"",            // exchange
-				msg.QueueName, // routing key
-				false,         // mandatory
-				false,         // immediate
-				amqp091.Publishing{
-					ContentType: "text/plain",
-					Body:        data,
-				},
-			)
-			if err != nil {
-				log.Printf("ERROR: fail publish msg: %s", err.Error())
-				continue
-			}
+	// publish message
+	err = amqpChannel.Publish(
+		"",            // exchange
+		msg.QueueName, // routing key
+		false,         // mandatory
+		false,         // immediate
+		amqp091.Publishing{
+			ContentType:   "text/plain",
+			CorrelationId: msg.CorrelationID,
+			Body:          data,
+		},
+	)
+	if err != nil {
+		log.Printf("ERROR: fail publish msg: %s", err.Error())
+		return
+	}
+
+	log.Printf("INFO: published msg: %v to: %s", msg.Reply, msg.QueueName)
+}
 
-			log.Printf("INFO: published msg: %v to: %s", msg.Reply, msg.QueueName)
+// publishEvent publishes a persistent domain event on its topic exchange,
+// declaring the exchange on first use, and reports the broker confirmation on
+// msg.Result. A closing channel nacks the confirmations still pending.
+func publishEvent(ctx context.Context, eventChannel *amqp091.Channel, declaredExchanges map[string]bool, msg utils.EventMsg) {
+	if !declaredExchanges[msg.Exchange] {
+		if err := eventChannel.ExchangeDeclare(msg.Exchange, amqp091.ExchangeTopic, true, false, false, false, nil); err != nil {
+			msg.Result <- err
+			return
 		}
+		declaredExchanges[msg.Exchange] = true
+	}
+
+	confirmation, err := eventChannel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		msg.Exchange,   // exchange
+		msg.RoutingKey, // routing key
+		false,          // mandatory
+		false,          // immediate
+		amqp091.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp091.Persistent,
+			MessageId:    msg.MessageID,
+			Type:         msg.RoutingKey,
+			Headers:      amqp091.Table{"version": int32(msg.Version)},
+			Body:         msg.Body,
+		},
+	)
+	if err != nil {
+		msg.Result <- err
+		return
 	}
+
+	go func() {
+		if !confirmation.Wait() {
+			msg.Result <- errors.New("event was not confirmed by the broker")
+			return
+		}
+		msg.Result <- nil
+	}()
 }
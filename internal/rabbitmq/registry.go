@@ -0,0 +1,83 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUnknownMessageType is replied to messages no handler is registered for.
+var ErrUnknownMessageType = errors.New("unknown message type")
+
+// MessageHandler answers a message with the data of its reply. An error is
+// replied to the sender, unless it is retryable, see Retryable.
+type MessageHandler func(ctx context.Context, messageData map[string]interface{}) (map[string]interface{}, error)
+
+// HandlerRegistry maps the message types of the julong_manpower queue to
+// their handlers.
+type HandlerRegistry struct {
+	Log      *logrus.Logger
+	Validate *validator.Validate
+	handlers map[string]MessageHandler
+}
+
+func NewHandlerRegistry(log *logrus.Logger, validate *validator.Validate) *HandlerRegistry {
+	return &HandlerRegistry{
+		Log:      log,
+		Validate: validate,
+		handlers: make(map[string]MessageHandler),
+	}
+}
+
+// Register adds the handler of a message type. The message data is decoded
+// into a new T and validated with its validate tags first, so the handler
+// only sees payloads that match its schema.
+func Register[T any](r *HandlerRegistry, messageType string, handler func(ctx context.Context, payload *T) (map[string]interface{}, error)) {
+	r.handlers[messageType] = func(ctx context.Context, messageData map[string]interface{}) (map[string]interface{}, error) {
+		payload := new(T)
+		data, err := json.Marshal(messageData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", messageType, err)
+		}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", messageType, err)
+		}
+		if err := r.Validate.Struct(payload); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", messageType, err)
+		}
+
+		return handler(ctx, payload)
+	}
+}
+
+func (r *HandlerRegistry) Handler(messageType string) (MessageHandler, bool) {
+	handler, ok := r.handlers[messageType]
+	return handler, ok
+}
+
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable marks a failure that may pass on another try, such as a database
+// error. The message is requeued once and dead-lettered if it fails again.
+func Retryable(err error) error {
+	return &retryableError{err: err}
+}
+
+func IsRetryable(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IApprovalChainRepository interface {
+	FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.ApprovalChain, int64, error)
+	FindById(id uuid.UUID) (*entity.ApprovalChain, error)
+	FindActiveByOrganizationIDAndDocumentType(organizationID uuid.UUID, documentType entity.ApprovalChainDocumentType) (*entity.ApprovalChain, error)
+	Create(approvalChain *entity.ApprovalChain) (*entity.ApprovalChain, error)
+	Update(approvalChain *entity.ApprovalChain) (*entity.ApprovalChain, error)
+	Delete(id uuid.UUID) error
+}
+
+type ApprovalChainRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewApprovalChainRepository(log *logrus.Logger, db *gorm.DB) IApprovalChainRepository {
+	return &ApprovalChainRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+func (r *ApprovalChainRepository) FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.ApprovalChain, int64, error) {
+	var approvalChains []entity.ApprovalChain
+	var total int64
+
+	query := r.DB.Model(&entity.ApprovalChain{})
+
+	if filter != nil {
+		if organizationID, ok := filter["organization_id"]; ok {
+			query = query.Where("organization_id = ?", organizationID)
+		}
+		if documentType, ok := filter["document_type"]; ok {
+			query = query.Where("document_type = ?", documentType)
+		}
+	}
+
+	if search != "" {
+		query = query.Where("name LIKE ?", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[ApprovalChainRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[ApprovalChainRepository.FindAllPaginated] " + err.Error())
+	}
+
+	if err := query.Preload("ApprovalChainSteps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&approvalChains).Error; err != nil {
+		r.Log.Errorf("[ApprovalChainRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[ApprovalChainRepository.FindAllPaginated] " + err.Error())
+	}
+
+	return &approvalChains, total, nil
+}
+
+func (r *ApprovalChainRepository) FindById(id uuid.UUID) (*entity.ApprovalChain, error) {
+	var approvalChain entity.ApprovalChain
+
+	if err := r.DB.Preload("ApprovalChainSteps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Where("id = ?", id).First(&approvalChain).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Log.Warn("[ApprovalChainRepository.FindById] Approval chain not found")
+			return nil, nil
+		} else {
+			r.Log.Errorf("[ApprovalChainRepository.FindById] " + err.Error())
+			return nil, errors.New("[ApprovalChainRepository.FindById] " + err.Error())
+		}
+	}
+
+	return &approvalChain, nil
+}
+
+func (r *ApprovalChainRepository) FindActiveByOrganizationIDAndDocumentType(organizationID uuid.UUID, documentType entity.ApprovalChainDocumentType) (*entity.ApprovalChain, error) {
+	var approvalChain entity.ApprovalChain
+
+	if err := r.DB.Preload("ApprovalChainSteps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Where("organization_id = ? AND document_type = ? AND is_active = ?", organizationID, documentType, true).First(&approvalChain).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		} else {
+			r.Log.Errorf("[ApprovalChainRepository.FindActiveByOrganizationIDAndDocumentType] " + err.Error())
+			return nil, errors.New("[ApprovalChainRepository.FindActiveByOrganizationIDAndDocumentType] " + err.Error())
+		}
+	}
+
+	return &approvalChain, nil
+}
+
+func (r *ApprovalChainRepository) Create(approvalChain *entity.ApprovalChain) (*entity.ApprovalChain, error) {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[ApprovalChainRepository.Create] " + tx.Error.Error())
+		return nil, errors.New("[ApprovalChainRepository.Create] " + tx.Error.Error())
+	}
+
+	if approvalChain.IsActive {
+		if err := r.deactivateOthers(tx, approvalChain); err != nil {
+			tx.Rollback()
+			r.Log.Errorf("[ApprovalChainRepository.Create] " + err.Error())
+			return nil, errors.New("[ApprovalChainRepository.Create] " + err.Error())
+		}
+	}
+
+	if err := tx.Create(approvalChain).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Create] " + err.Error())
+		return nil, errors.New("[ApprovalChainRepository.Create] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Create] " + err.Error())
+		return nil, errors.New("[ApprovalChainRepository.Create] " + err.Error())
+	}
+
+	return r.FindById(approvalChain.ID)
+}
+
+// Update replaces the chain header and all of its steps.
+func (r *ApprovalChainRepository) Update(approvalChain *entity.ApprovalChain) (*entity.ApprovalChain, error) {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[ApprovalChainRepository.Update] " + tx.Error.Error())
+		return nil, errors.New("[ApprovalChainRepository.Update] " + tx.Error.Error())
+	}
+
+	if approvalChain.IsActive {
+		if err := r.deactivateOthers(tx, approvalChain); err != nil {
+			tx.Rollback()
+			r.Log.Errorf("[ApprovalChainRepository.Update] " + err.Error())
+			return nil, errors.New("[ApprovalChainRepository.Update] " + err.Error())
+		}
+	}
+
+	if err := tx.Model(&entity.ApprovalChain{}).Where("id = ?", approvalChain.ID).Select("OrganizationID", "DocumentType", "Name", "IsActive").Updates(&entity.ApprovalChain{
+		OrganizationID: approvalChain.OrganizationID,
+		DocumentType:   approvalChain.DocumentType,
+		Name:           approvalChain.Name,
+		IsActive:       approvalChain.IsActive,
+	}).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Update] " + err.Error())
+		return nil, errors.New("[ApprovalChainRepository.Update] " + err.Error())
+	}
+
+	if err := tx.Unscoped().Where("approval_chain_id = ?", approvalChain.ID).Delete(&entity.ApprovalChainStep{}).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Update] " + err.Error())
+		return nil, errors.New("[ApprovalChainRepository.Update] " + err.Error())
+	}
+
+	for i := range approvalChain.ApprovalChainSteps {
+		approvalChain.ApprovalChainSteps[i].ApprovalChainID = approvalChain.ID
+		if err := tx.Create(&approvalChain.ApprovalChainSteps[i]).Error; err != nil {
+			tx.Rollback()
+			r.Log.Errorf("[ApprovalChainRepository.Update] " + err.Error())
+			return nil, errors.New("[ApprovalChainRepository.Update] " + err.Error())
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Update] " + err.Error())
+		return nil, errors.New("[ApprovalChainRepository.Update] " + err.Error())
+	}
+
+	return r.FindById(approvalChain.ID)
+}
+
+func (r *ApprovalChainRepository) Delete(id uuid.UUID) error {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[ApprovalChainRepository.Delete] " + tx.Error.Error())
+		return errors.New("[ApprovalChainRepository.Delete] " + tx.Error.Error())
+	}
+
+	if err := tx.Where("approval_chain_id = ?", id).Delete(&entity.ApprovalChainStep{}).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Delete] " + err.Error())
+		return errors.New("[ApprovalChainRepository.Delete] " + err.Error())
+	}
+
+	if err := tx.Where("id = ?", id).Delete(&entity.ApprovalChain{}).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Delete] " + err.Error())
+		return errors.New("[ApprovalChainRepository.Delete] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[ApprovalChainRepository.Delete] " + err.Error())
+		return errors.New("[ApprovalChainRepository.Delete] " + err.Error())
+	}
+
+	return nil
+}
+
+// deactivateOthers keeps a single active chain per organization and document type.
+func (r *ApprovalChainRepository) deactivateOthers(tx *gorm.DB, approvalChain *entity.ApprovalChain) error {
+	return tx.Model(&entity.ApprovalChain{}).
+		Where("organization_id = ? AND document_type = ? AND id <> ?", approvalChain.OrganizationID, approvalChain.DocumentType, approvalChain.ID).
+		Update("is_active", false).Error
+}
+
+// NextApprover is the chain step a status change moves a document to.
+type NextApprover struct {
+	ID    *uuid.UUID
+	Level string
+}
+
+// updateNextApprover moves the document of model with id to next in tx; a nil next leaves its step.
+func updateNextApprover(tx *gorm.DB, model interface{}, id uuid.UUID, next *NextApprover) error {
+	if next == nil {
+		return nil
+	}
+	return tx.Model(model).Where("id = ?", id).Updates(map[string]interface{}{
+		"next_approver_id":    next.ID,
+		"next_approver_level": next.Level,
+	}).Error
+}
+
+func ApprovalChainRepositoryFactory(log *logrus.Logger) IApprovalChainRepository {
+	db := config.NewDatabase()
+	return NewApprovalChainRepository(log, db)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IApprovalDelegationRepository interface {
+	FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.ApprovalDelegation, int64, error)
+	FindById(id uuid.UUID) (*entity.ApprovalDelegation, error)
+	FindActive(delegatorID uuid.UUID, delegateID uuid.UUID, scope entity.ApprovalDelegationScope, date time.Time) (*entity.ApprovalDelegation, error)
+	CountOverlapping(delegation *entity.ApprovalDelegation) (int64, error)
+	Create(delegation *entity.ApprovalDelegation) (*entity.ApprovalDelegation, error)
+	Update(delegation *entity.ApprovalDelegation) (*entity.ApprovalDelegation, error)
+	Delete(id uuid.UUID) error
+}
+
+type ApprovalDelegationRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewApprovalDelegationRepository(log *logrus.Logger, db *gorm.DB) IApprovalDelegationRepository {
+	return &ApprovalDelegationRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+func (r *ApprovalDelegationRepository) FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.ApprovalDelegation, int64, error) {
+	var delegations []entity.ApprovalDelegation
+	var total int64
+
+	query := r.DB.Model(&entity.ApprovalDelegation{})
+
+	if filter != nil {
+		if delegatorID, ok := filter["delegator_id"]; ok {
+			query = query.Where("delegator_id = ?", delegatorID)
+		}
+		if delegateID, ok := filter["delegate_id"]; ok {
+			query = query.Where("delegate_id = ?", delegateID)
+		}
+		if scope, ok := filter["scope"]; ok {
+			query = query.Where("scope = ?", scope)
+		}
+		if activeOn, ok := filter["active_on"]; ok {
+			query = query.Where("start_date <= ? AND end_date >= ?", activeOn, activeOn)
+		}
+	}
+
+	if search != "" {
+		query = query.Where("delegator_name LIKE ? OR delegate_name LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[ApprovalDelegationRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[ApprovalDelegationRepository.FindAllPaginated] " + err.Error())
+	}
+
+	if err := query.Order("start_date DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&delegations).Error; err != nil {
+		r.Log.Errorf("[ApprovalDelegationRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[ApprovalDelegationRepository.FindAllPaginated] " + err.Error())
+	}
+
+	return &delegations, total, nil
+}
+
+func (r *ApprovalDelegationRepository) FindById(id uuid.UUID) (*entity.ApprovalDelegation, error) {
+	var delegation entity.ApprovalDelegation
+
+	if err := r.DB.Where("id = ?", id).First(&delegation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Log.Warn("[ApprovalDelegationRepository.FindById] Approval delegation not found")
+			return nil, nil
+		} else {
+			r.Log.Errorf("[ApprovalDelegationRepository.FindById] " + err.Error())
+			return nil, errors.New("[ApprovalDelegationRepository.FindById] " + err.Error())
+		}
+	}
+
+	return &delegation, nil
+}
+
+// FindActive returns the delegation letting the delegate act for the delegator on date for scope.
+func (r *ApprovalDelegationRepository) FindActive(delegatorID uuid.UUID, delegateID uuid.UUID, scope entity.ApprovalDelegationScope, date time.Time) (*entity.ApprovalDelegation, error) {
+	var delegation entity.ApprovalDelegation
+	day := date.Format("2006-01-02")
+
+	if err := r.DB.Where("delegator_id = ? AND delegate_id = ?", delegatorID, delegateID).
+		Where("scope IN ?", []entity.ApprovalDelegationScope{scope, entity.ApprovalDelegationScopeAll}).
+		Where("start_date <= ? AND end_date >= ?", day, day).
+		First(&delegation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		} else {
+			r.Log.Errorf("[ApprovalDelegationRepository.FindActive] " + err.Error())
+			return nil, errors.New("[ApprovalDelegationRepository.FindActive] " + err.Error())
+		}
+	}
+
+	return &delegation, nil
+}
+
+// CountOverlapping counts the other delegations of the delegator colliding with the given one.
+func (r *ApprovalDelegationRepository) CountOverlapping(delegation *entity.ApprovalDelegation) (int64, error) {
+	var total int64
+
+	query := r.DB.Model(&entity.ApprovalDelegation{}).
+		Where("delegator_id = ? AND id <> ?", delegation.DelegatorID, delegation.ID).
+		Where("start_date <= ? AND end_date >= ?", delegation.EndDate.Format("2006-01-02"), delegation.StartDate.Format("2006-01-02"))
+
+	if delegation.Scope != entity.ApprovalDelegationScopeAll {
+		query = query.Where("scope IN ?", []entity.ApprovalDelegationScope{delegation.Scope, entity.ApprovalDelegationScopeAll})
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[ApprovalDelegationRepository.CountOverlapping] " + err.Error())
+		return 0, errors.New("[ApprovalDelegationRepository.CountOverlapping] " + err.Error())
+	}
+
+	return total, nil
+}
+
+func (r *ApprovalDelegationRepository) Create(delegation *entity.ApprovalDelegation) (*entity.ApprovalDelegation, error) {
+	if err := r.DB.Create(delegation).Error; err != nil {
+		r.Log.Errorf("[ApprovalDelegationRepository.Create] " + err.Error())
+		return nil, errors.New("[ApprovalDelegationRepository.Create] " + err.Error())
+	}
+
+	return r.FindById(delegation.ID)
+}
+
+func (r *ApprovalDelegationRepository) Update(delegation *entity.ApprovalDelegation) (*entity.ApprovalDelegation, error) {
+	if err := r.DB.Model(&entity.ApprovalDelegation{}).Where("id = ?", delegation.ID).
+		Select("DelegatorID", "DelegatorName", "DelegateID", "DelegateName", "Scope", "StartDate", "EndDate", "Reason").
+		Updates(delegation).Error; err != nil {
+		r.Log.Errorf("[ApprovalDelegationRepository.Update] " + err.Error())
+		return nil, errors.New("[ApprovalDelegationRepository.Update] " + err.Error())
+	}
+
+	return r.FindById(delegation.ID)
+}
+
+func (r *ApprovalDelegationRepository) Delete(id uuid.UUID) error {
+	if err := r.DB.Where("id = ?", id).Delete(&entity.ApprovalDelegation{}).Error; err != nil {
+		r.Log.Errorf("[ApprovalDelegationRepository.Delete] " + err.Error())
+		return errors.New("[ApprovalDelegationRepository.Delete] " + err.Error())
+	}
+
+	return nil
+}
+
+func ApprovalDelegationRepositoryFactory(log *logrus.Logger) IApprovalDelegationRepository {
+	db := config.NewDatabase()
+	return NewApprovalDelegationRepository(log, db)
+}
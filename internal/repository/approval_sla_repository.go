@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IApprovalSLARepository interface {
+	FindAllPaginated(page int, pageSize int, filter map[string]interface{}) (*[]entity.ApprovalSLA, int64, error)
+	FindAllActive() (*[]entity.ApprovalSLA, error)
+	FindById(id uuid.UUID) (*entity.ApprovalSLA, error)
+	FindByDocumentTypeAndLevel(documentType entity.ApprovalChainDocumentType, level string) (*entity.ApprovalSLA, error)
+	Create(approvalSLA *entity.ApprovalSLA) (*entity.ApprovalSLA, error)
+	Update(approvalSLA *entity.ApprovalSLA) (*entity.ApprovalSLA, error)
+	Delete(id uuid.UUID) error
+}
+
+type ApprovalSLARepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewApprovalSLARepository(log *logrus.Logger, db *gorm.DB) IApprovalSLARepository {
+	return &ApprovalSLARepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+func (r *ApprovalSLARepository) FindAllPaginated(page int, pageSize int, filter map[string]interface{}) (*[]entity.ApprovalSLA, int64, error) {
+	var approvalSLAs []entity.ApprovalSLA
+	var total int64
+
+	query := r.DB.Model(&entity.ApprovalSLA{})
+
+	if filter != nil {
+		if documentType, ok := filter["document_type"]; ok {
+			query = query.Where("document_type = ?", documentType)
+		}
+		if level, ok := filter["level"]; ok {
+			query = query.Where("level = ?", level)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[ApprovalSLARepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[ApprovalSLARepository.FindAllPaginated] " + err.Error())
+	}
+
+	if err := query.Order("document_type ASC, level ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&approvalSLAs).Error; err != nil {
+		r.Log.Errorf("[ApprovalSLARepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[ApprovalSLARepository.FindAllPaginated] " + err.Error())
+	}
+
+	return &approvalSLAs, total, nil
+}
+
+func (r *ApprovalSLARepository) FindAllActive() (*[]entity.ApprovalSLA, error) {
+	var approvalSLAs []entity.ApprovalSLA
+
+	if err := r.DB.Where("is_active = ?", true).Find(&approvalSLAs).Error; err != nil {
+		r.Log.Errorf("[ApprovalSLARepository.FindAllActive] " + err.Error())
+		return nil, errors.New("[ApprovalSLARepository.FindAllActive] " + err.Error())
+	}
+
+	return &approvalSLAs, nil
+}
+
+func (r *ApprovalSLARepository) FindById(id uuid.UUID) (*entity.ApprovalSLA, error) {
+	var approvalSLA entity.ApprovalSLA
+
+	if err := r.DB.Where("id = ?", id).First(&approvalSLA).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Log.Warn("[ApprovalSLARepository.FindById] Approval SLA not found")
+			return nil, nil
+		} else {
+			r.Log.Errorf("[ApprovalSLARepository.FindById] " + err.Error())
+			return nil, errors.New("[ApprovalSLARepository.FindById] " + err.Error())
+		}
+	}
+
+	return &approvalSLA, nil
+}
+
+func (r *ApprovalSLARepository) FindByDocumentTypeAndLevel(documentType entity.ApprovalChainDocumentType, level string) (*entity.ApprovalSLA, error) {
+	var approvalSLA entity.ApprovalSLA
+
+	if err := r.DB.Where("document_type = ? AND level = ?", documentType, level).First(&approvalSLA).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		} else {
+			r.Log.Errorf("[ApprovalSLARepository.FindByDocumentTypeAndLevel] " + err.Error())
+			return nil, errors.New("[ApprovalSLARepository.FindByDocumentTypeAndLevel] " + err.Error())
+		}
+	}
+
+	return &approvalSLA, nil
+}
+
+func (r *ApprovalSLARepository) Create(approvalSLA *entity.ApprovalSLA) (*entity.ApprovalSLA, error) {
+	if err := r.DB.Create(approvalSLA).Error; err != nil {
+		r.Log.Errorf("[ApprovalSLARepository.Create] " + err.Error())
+		return nil, errors.New("[ApprovalSLARepository.Create] " + err.Error())
+	}
+
+	return r.FindById(approvalSLA.ID)
+}
+
+func (r *ApprovalSLARepository) Update(approvalSLA *entity.ApprovalSLA) (*entity.ApprovalSLA, error) {
+	if err := r.DB.Model(&entity.ApprovalSLA{}).Where("id = ?", approvalSLA.ID).
+		Select("DocumentType", "Level", "ReminderAfterDays", "EscalateAfterDays", "EscalateToLevel", "BackupApproverID", "BackupApproverName", "PermissionName", "IsActive").
+		Updates(approvalSLA).Error; err != nil {
+		r.Log.Errorf("[ApprovalSLARepository.Update] " + err.Error())
+		return nil, errors.New("[ApprovalSLARepository.Update] " + err.Error())
+	}
+
+	return r.FindById(approvalSLA.ID)
+}
+
+func (r *ApprovalSLARepository) Delete(id uuid.UUID) error {
+	if err := r.DB.Where("id = ?", id).Delete(&entity.ApprovalSLA{}).Error; err != nil {
+		r.Log.Errorf("[ApprovalSLARepository.Delete] " + err.Error())
+		return errors.New("[ApprovalSLARepository.Delete] " + err.Error())
+	}
+
+	return nil
+}
+
+// approvalClockUpdates restarts the SLA clock of a document whose status
+// changes. Only documents entering NEED APPROVAL start a new clock.
+func approvalClockUpdates(needApproval bool) map[string]interface{} {
+	var requestedAt *time.Time
+	if needApproval {
+		now := time.Now()
+		requestedAt = &now
+	}
+
+	return map[string]interface{}{
+		"approval_requested_at": requestedAt,
+		"approval_reminded_at":  nil,
+	}
+}
+
+func ApprovalSLARepositoryFactory(log *logrus.Logger) IApprovalSLARepository {
+	db := config.NewDatabase()
+	return NewApprovalSLARepository(log, db)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// IAuditLogRepository reads the audit log the audit callbacks write.
+type IAuditLogRepository interface {
+	FindAllPaginated(page int, pageSize int, filter map[string]interface{}) (*[]entity.AuditLog, int64, error)
+}
+
+type AuditLogRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewAuditLogRepository(log *logrus.Logger, db *gorm.DB) IAuditLogRepository {
+	return &AuditLogRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+func (r *AuditLogRepository) FindAllPaginated(page int, pageSize int, filter map[string]interface{}) (*[]entity.AuditLog, int64, error) {
+	var auditLogs []entity.AuditLog
+	var total int64
+
+	query := r.DB.Model(&entity.AuditLog{})
+
+	if filter != nil {
+		if entityName, ok := filter["entity"]; ok {
+			query = query.Where("entity = ?", entityName)
+		}
+		if entityID, ok := filter["entity_id"]; ok {
+			query = query.Where("entity_id = ?", entityID)
+		}
+		if field, ok := filter["field"]; ok {
+			query = query.Where("field = ?", field)
+		}
+		if action, ok := filter["action"]; ok {
+			query = query.Where("action = ?", action)
+		}
+		if actorID, ok := filter["actor_id"]; ok {
+			query = query.Where("actor_id = ?", actorID)
+		}
+		if requestID, ok := filter["request_id"]; ok {
+			query = query.Where("request_id = ?", requestID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[AuditLogRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[AuditLogRepository.FindAllPaginated] " + err.Error())
+	}
+
+	if err := query.Order("created_at DESC").Order("field ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&auditLogs).Error; err != nil {
+		r.Log.Errorf("[AuditLogRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[AuditLogRepository.FindAllPaginated] " + err.Error())
+	}
+
+	return &auditLogs, total, nil
+}
+
+func AuditLogRepositoryFactory(log *logrus.Logger) IAuditLogRepository {
+	db := config.NewDatabase()
+	return NewAuditLogRepository(log, db)
+}
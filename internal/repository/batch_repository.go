@@ -1,41 +1,60 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type IBatchRepository interface {
-	CreateBatchHeaderAndLines(batchHeader *entity.BatchHeader, batchLines []entity.BatchLine) (*entity.BatchHeader, error)
+	CreateBatchHeaderAndLines(batchHeader *entity.BatchHeader, batchLines []entity.BatchLine, numbering *workflow.DocumentNumbering) (*entity.BatchHeader, error)
 	InsertLinesByBatchHeaderID(batchHeaderID string, batchLines []entity.BatchLine) error
 	FindByStatusApproverTypeOrgID(status entity.BatchHeaderApprovalStatus, approverType string, orgID string) (*entity.BatchHeader, error)
 	DeleteLinesNotInBatchLines(batchHeaderID string, batchLines []entity.BatchLine) error
 	FindByStatus(status entity.BatchHeaderApprovalStatus, approverType string, orgID string) (*entity.BatchHeader, error)
 	FindById(id string) (*entity.BatchHeader, error)
+	FindApprovalHistories(batchHeader *entity.BatchHeader) ([]entity.MPPlanningApprovalHistory, error)
 	FindByNeedApproval(approverType string, orgID string) (*entity.BatchHeader, error)
 	GetHeadersByDocumentDate(documentDate string) ([]entity.BatchHeader, error)
 	FindByCurrentDocumentDateAndStatus(status entity.BatchHeaderApprovalStatus) (*entity.BatchHeader, error)
-	UpdateStatusBatchHeader(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string) error
-	UpdateStatusBatchHeaderForDirector(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string) error
+	UpdateStatusBatchHeader(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string, delegation *entity.ApprovalDelegation, outboxMessages []entity.OutboxMessage) error
+	UpdateStatusBatchHeaderForDirector(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string, delegation *entity.ApprovalDelegation, outboxMessages []entity.OutboxMessage) error
 	GetBatchHeadersByStatus(status entity.BatchHeaderApprovalStatus, approverType entity.BatchHeaderApproverType, orgID string) ([]entity.BatchHeader, error)
 	GetBatchHeadersByStatusPaginated(status entity.BatchHeaderApprovalStatus, approverType entity.BatchHeaderApproverType, orgID string, page, pageSize int, search string, sort map[string]interface{}, employeeID uuid.UUID) ([]entity.BatchHeader, int64, error)
+	FindAwaitingApproval() (*[]entity.BatchHeader, error)
+	MarkApprovalReminded(id uuid.UUID, remindedAt time.Time, outboxMessages []entity.OutboxMessage) error
+	EscalateApproval(id uuid.UUID, approverType entity.BatchHeaderApproverType, approvalHistories []entity.MPPlanningApprovalHistory, outboxMessages []entity.OutboxMessage) error
+	WithContext(ctx context.Context) IBatchRepository
 }
 
 type BatchRepository struct {
-	Log *logrus.Logger
-	DB  *gorm.DB
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Workflow workflow.IApprovalWorkflow
 }
 
-func NewBatchRepository(log *logrus.Logger, db *gorm.DB) IBatchRepository {
+func NewBatchRepository(log *logrus.Logger, db *gorm.DB, approvalWorkflow workflow.IApprovalWorkflow) IBatchRepository {
 	return &BatchRepository{
-		Log: log,
-		DB:  db,
+		Log:      log,
+		DB:       db,
+		Workflow: approvalWorkflow,
+	}
+}
+
+// WithContext is the repository with its statements run under ctx.
+func (r *BatchRepository) WithContext(ctx context.Context) IBatchRepository {
+	return &BatchRepository{
+		Log:      r.Log,
+		DB:       r.DB.WithContext(ctx),
+		Workflow: r.Workflow,
 	}
 }
 
@@ -137,11 +156,24 @@ func (r *BatchRepository) FindByStatusApproverTypeOrgID(status entity.BatchHeade
 	return &batchHeader, nil
 }
 
-func (r *BatchRepository) CreateBatchHeaderAndLines(batchHeader *entity.BatchHeader, batchLines []entity.BatchLine) (*entity.BatchHeader, error) {
+// CreateBatchHeaderAndLines saves the batch, numbered by numbering unless it is nil.
+func (r *BatchRepository) CreateBatchHeaderAndLines(batchHeader *entity.BatchHeader, batchLines []entity.BatchLine, numbering *workflow.DocumentNumbering) (*entity.BatchHeader, error) {
 	tx := r.DB.Begin()
+	if numbering != nil {
+		documentNumber, err := reserveDocumentNumber(tx, numbering)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		batchHeader.DocumentNumber = documentNumber
+	}
 	if batchHeader.Status == "" {
 		batchHeader.Status = entity.BatchHeaderApprovalStatusNeedApproval
 	}
+	if batchHeader.Status == entity.BatchHeaderApprovalStatusNeedApproval {
+		now := time.Now()
+		batchHeader.ApprovalRequestedAt = &now
+	}
 	if err := tx.Create(batchHeader).Error; err != nil {
 		tx.Rollback()
 		return nil, err
@@ -249,6 +281,27 @@ func (r *BatchRepository) FindById(id string) (*entity.BatchHeader, error) {
 	return &batchHeader, nil
 }
 
+// FindApprovalHistories returns, oldest first, the histories the batch wrote to its plannings.
+func (r *BatchRepository) FindApprovalHistories(batchHeader *entity.BatchHeader) ([]entity.MPPlanningApprovalHistory, error) {
+	planningIDs := make([]uuid.UUID, 0, len(batchHeader.BatchLines))
+	for _, bl := range batchHeader.BatchLines {
+		planningIDs = append(planningIDs, bl.MPPlanningHeaderID)
+	}
+
+	var approvalHistories []entity.MPPlanningApprovalHistory
+	if len(planningIDs) == 0 {
+		return approvalHistories, nil
+	}
+
+	levels := []entity.MPPlanningApprovalHistoryLevel{entity.MPPlanningApprovalHistoryLevelDirekturUnit, entity.MPPlanningApprovalHistoryLevelCEO}
+	if err := r.DB.Where("mp_planning_header_id IN ? AND level IN ? AND created_at >= ?", planningIDs, levels, batchHeader.CreatedAt).Order("created_at ASC").Find(&approvalHistories).Error; err != nil {
+		r.Log.Errorf("[BatchRepository.FindApprovalHistories] " + err.Error())
+		return nil, errors.New("[BatchRepository.FindApprovalHistories] " + err.Error())
+	}
+
+	return approvalHistories, nil
+}
+
 func (r *BatchRepository) FindByNeedApproval(approverType string, orgID string) (*entity.BatchHeader, error) {
 	var batchHeader entity.BatchHeader
 	var whereApproverType string
@@ -288,7 +341,7 @@ func (r *BatchRepository) GetHeadersByDocumentDate(documentDate string) ([]entit
 
 func (r *BatchRepository) FindByCurrentDocumentDateAndStatus(status entity.BatchHeaderApprovalStatus) (*entity.BatchHeader, error) {
 	var batchHeader entity.BatchHeader
-	dateNow := time.Now()
+	dateNow := timezone.Now()
 	if err := r.DB.Preload("BatchLines.MPPlanningHeader.MPPPeriod").Preload("BatchLines.MPPlanningHeader.MPPlanningLines").Where("status = ? AND document_date = ?", status, dateNow.Format("2006-01-02")).First(&batchHeader).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.Log.Warnf("Batch header with status %s and document date %s not found", status, dateNow.Format("2006-01-02"))
@@ -302,8 +355,25 @@ func (r *BatchRepository) FindByCurrentDocumentDateAndStatus(status entity.Batch
 	return &batchHeader, nil
 }
 
-func (r *BatchRepository) UpdateStatusBatchHeader(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string) error {
-	tx := r.DB.Begin()
+func (r *BatchRepository) UpdateStatusBatchHeader(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string, delegation *entity.ApprovalDelegation, outboxMessages []entity.OutboxMessage) error {
+	return r.updateStatusBatchHeader("[BatchRepository.UpdateStatusBatchHeader] ", batchHeader, status, approvedBy, approverName, delegation, entity.BatchHeaderApproverTypeCEO, outboxMessages)
+}
+
+func (r *BatchRepository) UpdateStatusBatchHeaderForDirector(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string, delegation *entity.ApprovalDelegation, outboxMessages []entity.OutboxMessage) error {
+	return r.updateStatusBatchHeader("[BatchRepository.UpdateStatusBatchHeaderForDirector] ", batchHeader, status, approvedBy, approverName, delegation, entity.BatchHeaderApproverTypeDirector, outboxMessages)
+}
+
+func (r *BatchRepository) updateStatusBatchHeader(logPrefix string, batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string, delegation *entity.ApprovalDelegation, approverType entity.BatchHeaderApproverType, outboxMessages []entity.OutboxMessage) error {
+	transition, err := r.Workflow.Transition(workflow.DocumentTypeBatch, string(batchHeader.Status), string(status), string(approverType))
+	if err != nil {
+		r.Log.Errorf("%s%v", logPrefix, err)
+		return err
+	}
+
+	planningLevel := entity.MPPlanningApprovalHistoryLevelCEO
+	if approverType == entity.BatchHeaderApproverTypeDirector {
+		planningLevel = entity.MPPlanningApprovalHistoryLevelDirekturUnit
+	}
 
 	var approvedByPtr uuid.UUID
 
@@ -311,9 +381,8 @@ func (r *BatchRepository) UpdateStatusBatchHeader(batchHeader *entity.BatchHeade
 		approvedByPtr = uuid.MustParse(approvedBy)
 	}
 
-	// loop through the batch lines and update the status
-	for _, bl := range batchHeader.BatchLines {
-		var mppPeriodCompleted entity.MPPPeriod
+	var mppPeriodCompleted entity.MPPPeriod
+	if transition.Has(workflow.SideEffectCompletePeriod) {
 		if err := r.DB.Where("status = ?", entity.MPPeriodStatusComplete).First(&mppPeriodCompleted).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				r.Log.Warnf("MPP Period with status %s not found", entity.MPPeriodStatusComplete)
@@ -322,292 +391,217 @@ func (r *BatchRepository) UpdateStatusBatchHeader(batchHeader *entity.BatchHeade
 				return err
 			}
 		}
+	}
+
+	tx := r.DB.Begin()
+
+	if err := claimVersion(tx, &entity.BatchHeader{}, batchHeader.ID, batchHeader.Version); err != nil {
+		tx.Rollback()
+		r.Log.Warnf(logPrefix + err.Error())
+		return err
+	}
+	batchHeader.Version++
+
+	// loop through the batch lines and update the status
+	for _, bl := range batchHeader.BatchLines {
+		if !transition.Has(workflow.SideEffectCascadePlanningStatus) {
+			break
+		}
 
-		if status != entity.BatchHeaderApprovalStatusCompleted {
-			var approvalHistory *entity.MPPlanningApprovalHistory
+		planningTransition, err := r.Workflow.Transition(workflow.DocumentTypeMPPlanning, string(bl.MPPlanningHeader.Status), string(status), string(planningLevel))
+		if err != nil {
+			tx.Rollback()
+			r.Log.Errorf("%s%v", logPrefix, err)
+			return err
+		}
+
+		if transition.Has(workflow.SideEffectCompletePeriod) {
+			if mppPeriodCompleted.ID != uuid.Nil && mppPeriodCompleted.ID != bl.MPPlanningHeader.MPPPeriodID {
+				tx.Rollback()
+				r.Log.Warnf("MPP Period with status %s found: %v", entity.MPPeriodStatusComplete, mppPeriodCompleted)
+				return errors.New("MPP Period with status complete found")
+			}
+		}
 
+		var approvalHistory *entity.MPPlanningApprovalHistory
+		if planningTransition.Has(workflow.SideEffectRecordApprovalHistory) {
 			approvalHistory = &entity.MPPlanningApprovalHistory{
 				MPPlanningHeaderID: bl.MPPlanningHeaderID,
-				ApproverID:         uuid.MustParse(approvedBy),
+				ApproverID:         approvedByPtr,
 				ApproverName:       approverName,
 				Notes:              "",
-				Level:              string(entity.MPPlanningApprovalHistoryLevelCEO),
+				Level:              string(planningLevel),
 				Status:             entity.MPPlanningApprovalHistoryStatus(status),
 			}
+		}
+		if approvalHistory != nil && delegation != nil {
+			approvalHistory.OnBehalfOfID = delegation.DelegatorID
+			approvalHistory.OnBehalfOfName = delegation.DelegatorName
+		}
 
-			if approvalHistory != nil {
-				if approvalHistory.Status != entity.MPPlanningApprovalHistoryStatusRejected {
-					if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelHRDUnit) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-							// ApprovedBy:        approvedBy,
-							ApproverManagerID: &approvalHistory.ApproverID,
-							NotesManager:      approvalHistory.Notes,
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-						}
-					} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelRecruitment) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-							// ApprovedBy:            approvedBy,
-							ApproverRecruitmentID: &approvalHistory.ApproverID,
-							NotesRecruitment:      approvalHistory.Notes,
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-						}
-					} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelDirekturUnit) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-							// ApprovedBy:            approvedBy,
-							RecommendedBy: approvalHistory.ApproverID.String(),
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-						}
-					} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelCEO) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status:     entity.MPPlaningStatus(status),
-							ApprovedBy: approvedBy,
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-						}
-					} else {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-						}
-					}
-				} else {
-					if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-						Status: entity.MPPlaningStatus(status),
-					}).Error; err != nil {
-						tx.Rollback()
-						r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-						return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-					}
-				}
-			} else {
-				if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-					Status: entity.MPPlaningStatus(status),
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-					return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-				}
-			}
+		// the batch decides for the planning, whatever version its editors hold
+		if err := claimVersion(tx, &entity.MPPlanningHeader{}, bl.MPPlanningHeaderID, AnyVersion); err != nil {
+			tx.Rollback()
+			r.Log.Errorf(logPrefix + err.Error())
+			return err
+		}
 
-			if approvalHistory != nil {
-				if err := tx.Create(approvalHistory).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-					return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-				}
-			}
+		updates, columns := workflow.MPPlanningHeaderUpdates(string(status), approvedBy, approvalHistory)
+		query := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID)
+		if columns != nil {
+			query = query.Select(columns)
+		}
+		if err := query.Updates(updates).Error; err != nil {
+			tx.Rollback()
+			r.Log.Errorf(logPrefix + err.Error())
+			return errors.New(logPrefix + err.Error())
+		}
 
-			if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", batchHeader.ID).Updates(&entity.BatchHeader{
-				Status:       status,
-				ApproverID:   &approvedByPtr,
-				ApproverName: approverName,
-			}).Error; err != nil {
-				tx.Rollback()
-				r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-				return err
-			}
-		} else {
-			if mppPeriodCompleted.ID != uuid.Nil {
-				if mppPeriodCompleted.ID != bl.MPPlanningHeader.MPPPeriodID {
-					r.Log.Warnf("MPP Period with status %s found: %v", entity.MPPeriodStatusComplete, mppPeriodCompleted)
-					return errors.New("MPP Period with status complete found")
-				}
-			}
-			if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-				Status: entity.MPPlaningStatus(status),
-			}).Error; err != nil {
+		if approvalHistory != nil {
+			if err := tx.Create(approvalHistory).Error; err != nil {
 				tx.Rollback()
-				r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-				return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
+				r.Log.Errorf(logPrefix + err.Error())
+				return errors.New(logPrefix + err.Error())
 			}
+		}
 
+		if transition.Has(workflow.SideEffectCompletePeriod) {
 			mppPeriod := bl.MPPlanningHeader.MPPPeriod
 			if err := tx.Model(&entity.MPPPeriod{}).Where("id = ?", mppPeriod.ID).Updates(&entity.MPPPeriod{
 				Status: entity.MPPeriodStatusComplete,
 			}).Error; err != nil {
 				tx.Rollback()
-				r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-				return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
+				r.Log.Errorf(logPrefix + err.Error())
+				return errors.New(logPrefix + err.Error())
 			}
+		}
+	}
 
-			if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", batchHeader.ID).Updates(&entity.BatchHeader{
-				Status: status,
-				// ApproverID:   &approvedByPtr,
-				// ApproverName: approverName,
-			}).Error; err != nil {
-				tx.Rollback()
-				r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-				return err
-			}
+	batchUpdates := &entity.BatchHeader{
+		Status: status,
+	}
+	if transition.Has(workflow.SideEffectAssignApprover) {
+		batchUpdates.ApproverID = &approvedByPtr
+		batchUpdates.ApproverName = approverName
+	}
 
-		}
+	if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", batchHeader.ID).Updates(batchUpdates).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf(logPrefix + err.Error())
+		return err
+	}
+
+	clockUpdates := approvalClockUpdates(status == entity.BatchHeaderApprovalStatusNeedApproval)
+	// a decision ends the step the SLA may have escalated
+	clockUpdates["escalated_at"] = nil
+	clockUpdates["escalated_level"] = nil
+	if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", batchHeader.ID).Updates(clockUpdates).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf(logPrefix + err.Error())
+		return err
+	}
+
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf(logPrefix + err.Error())
+		return err
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
+		r.Log.Errorf(logPrefix + err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (r *BatchRepository) UpdateStatusBatchHeaderForDirector(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approvedBy string, approverName string) error {
+// FindAwaitingApproval returns the batch headers waiting in NEED APPROVAL with a running SLA clock.
+func (r *BatchRepository) FindAwaitingApproval() (*[]entity.BatchHeader, error) {
+	var batchHeaders []entity.BatchHeader
+
+	// a batch already escalated to the approver type it waits on is not escalated again
+	if err := r.DB.Preload("BatchLines").Where("status = ? AND approval_requested_at IS NOT NULL AND (escalated_level IS NULL OR escalated_level <> approver_type)", entity.BatchHeaderApprovalStatusNeedApproval).Find(&batchHeaders).Error; err != nil {
+		r.Log.Errorf("[BatchRepository.FindAwaitingApproval] " + err.Error())
+		return nil, errors.New("[BatchRepository.FindAwaitingApproval] " + err.Error())
+	}
+
+	return &batchHeaders, nil
+}
+
+func (r *BatchRepository) MarkApprovalReminded(id uuid.UUID, remindedAt time.Time, outboxMessages []entity.OutboxMessage) error {
 	tx := r.DB.Begin()
 
-	var approvedByPtr uuid.UUID
+	if tx.Error != nil {
+		r.Log.Errorf("[BatchRepository.MarkApprovalReminded] " + tx.Error.Error())
+		return errors.New("[BatchRepository.MarkApprovalReminded] " + tx.Error.Error())
+	}
 
-	if approvedBy != "" {
-		approvedByPtr = uuid.MustParse(approvedBy)
+	if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", id).Update("approval_reminded_at", remindedAt).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BatchRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[BatchRepository.MarkApprovalReminded] " + err.Error())
 	}
 
-	// loop through the batch lines and update the status
-	for _, bl := range batchHeader.BatchLines {
-		var mppPeriodCompleted entity.MPPPeriod
-		if err := r.DB.Where("status = ?", entity.MPPeriodStatusComplete).First(&mppPeriodCompleted).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				r.Log.Warnf("MPP Period with status %s not found", entity.MPPeriodStatusComplete)
-			} else {
-				r.Log.Error(err)
-				return err
-			}
-		}
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BatchRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[BatchRepository.MarkApprovalReminded] " + err.Error())
+	}
 
-		if status != entity.BatchHeaderApprovalStatusCompleted {
-			var approvalHistory *entity.MPPlanningApprovalHistory
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BatchRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[BatchRepository.MarkApprovalReminded] " + err.Error())
+	}
 
-			approvalHistory = &entity.MPPlanningApprovalHistory{
-				MPPlanningHeaderID: bl.MPPlanningHeaderID,
-				ApproverID:         uuid.MustParse(approvedBy),
-				ApproverName:       approverName,
-				Notes:              "",
-				Level:              string(entity.MPPlanningApprovalHistoryLevelDirekturUnit),
-				Status:             entity.MPPlanningApprovalHistoryStatus(status),
-			}
+	return nil
+}
 
-			if approvalHistory != nil {
-				if approvalHistory.Status != entity.MPPlanningApprovalHistoryStatusRejected {
-					if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelHRDUnit) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-							// ApprovedBy:        approvedBy,
-							ApproverManagerID: &approvalHistory.ApproverID,
-							NotesManager:      approvalHistory.Notes,
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-						}
-					} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelRecruitment) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-							// ApprovedBy:            approvedBy,
-							ApproverRecruitmentID: &approvalHistory.ApproverID,
-							NotesRecruitment:      approvalHistory.Notes,
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-						}
-					} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelDirekturUnit) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-							// ApprovedBy:            approvedBy,
-							RecommendedBy: approvalHistory.ApproverID.String(),
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeader] " + err.Error())
-						}
-					} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelCEO) {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status:     entity.MPPlaningStatus(status),
-							ApprovedBy: approvedBy,
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-						}
-					} else {
-						if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-							Status: entity.MPPlaningStatus(status),
-						}).Error; err != nil {
-							tx.Rollback()
-							r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-							return errors.New("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-						}
-					}
-				} else {
-					if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-						Status: entity.MPPlaningStatus(status),
-					}).Error; err != nil {
-						tx.Rollback()
-						r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-						return errors.New("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-					}
-				}
-			} else {
-				if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", bl.MPPlanningHeaderID).Updates(&entity.MPPlanningHeader{
-					Status: entity.MPPlaningStatus(status),
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-					return errors.New("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-				}
-			}
+// EscalateApproval moves a pending batch to another approver type and restarts its SLA clock.
+func (r *BatchRepository) EscalateApproval(id uuid.UUID, approverType entity.BatchHeaderApproverType, approvalHistories []entity.MPPlanningApprovalHistory, outboxMessages []entity.OutboxMessage) error {
+	tx := r.DB.Begin()
 
-			if approvalHistory != nil {
-				if err := tx.Create(approvalHistory).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-					return errors.New("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-				}
-			}
+	if tx.Error != nil {
+		r.Log.Errorf("[BatchRepository.EscalateApproval] " + tx.Error.Error())
+		return errors.New("[BatchRepository.EscalateApproval] " + tx.Error.Error())
+	}
 
-			if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", batchHeader.ID).Updates(&entity.BatchHeader{
-				Status:       status,
-				ApproverID:   &approvedByPtr,
-				ApproverName: approverName,
-			}).Error; err != nil {
-				tx.Rollback()
-				r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-				return err
-			}
-		} else {
-			if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", batchHeader.ID).Updates(&entity.BatchHeader{
-				Status: status,
-				// ApproverID:   &approvedByPtr,
-				// ApproverName: approverName,
-			}).Error; err != nil {
-				tx.Rollback()
-				r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-				return err
-			}
+	escalatedAt := time.Now()
+	if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"approver_type":   approverType,
+		"escalated_at":    escalatedAt,
+		"escalated_level": string(approverType),
+	}).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BatchRepository.EscalateApproval] " + err.Error())
+		return errors.New("[BatchRepository.EscalateApproval] " + err.Error())
+	}
 
+	if err := tx.Model(&entity.BatchHeader{}).Where("id = ?", id).Updates(approvalClockUpdates(true)).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BatchRepository.EscalateApproval] " + err.Error())
+		return errors.New("[BatchRepository.EscalateApproval] " + err.Error())
+	}
+
+	for i := range approvalHistories {
+		if err := tx.Create(&approvalHistories[i]).Error; err != nil {
+			tx.Rollback()
+			r.Log.Errorf("[BatchRepository.EscalateApproval] " + err.Error())
+			return errors.New("[BatchRepository.EscalateApproval] " + err.Error())
 		}
 	}
 
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BatchRepository.EscalateApproval] " + err.Error())
+		return errors.New("[BatchRepository.EscalateApproval] " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		r.Log.Errorf("[BatchRepository.UpdateStatusBatchHeaderForDirector] " + err.Error())
-		return err
+		r.Log.Errorf("[BatchRepository.EscalateApproval] " + err.Error())
+		return errors.New("[BatchRepository.EscalateApproval] " + err.Error())
 	}
 
 	return nil
@@ -615,5 +609,6 @@ func (r *BatchRepository) UpdateStatusBatchHeaderForDirector(batchHeader *entity
 
 func BatchRepositoryFactory(log *logrus.Logger) IBatchRepository {
 	db := config.NewDatabase()
-	return NewBatchRepository(log, db)
+	approvalWorkflow := workflow.ApprovalWorkflowFactory(log)
+	return NewBatchRepository(log, db, approvalWorkflow)
 }
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BudgetLedgerEntries builds the ledger entries of a change with ledger bound to its transaction.
+type BudgetLedgerEntries func(ledger IBudgetLedgerRepository) ([]entity.BudgetLedgerEntry, error)
+
+// BudgetLedgerSum is what the ledger holds on one balance of a planning line.
+type BudgetLedgerSum struct {
+	MPPlanningLineID uuid.UUID
+	Balance          entity.BudgetBalance
+	Reserved         int
+	Consumed         int
+}
+
+type IBudgetLedgerRepository interface {
+	FindAllByLineIDPaginated(lineID uuid.UUID, page int, pageSize int) (*[]entity.BudgetLedgerEntry, int64, error)
+	LockLines(lineIDs []uuid.UUID) error
+	SumByLineIDs(lineIDs []uuid.UUID, excludeMPRequestID *uuid.UUID) ([]BudgetLedgerSum, error)
+	SumByMPRequestID(mpRequestID uuid.UUID) ([]BudgetLedgerSum, error)
+	CountByMPRequestID(mpRequestID uuid.UUID) (int64, error)
+	FindUntrackedMPRequests(statuses []entity.MPRequestStatus) (*[]entity.MPRequestHeader, error)
+	Create(entries []entity.BudgetLedgerEntry) error
+	RefreshAllRemainingBalances() error
+	WithContext(ctx context.Context) IBudgetLedgerRepository
+}
+
+type BudgetLedgerRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewBudgetLedgerRepository(log *logrus.Logger, db *gorm.DB) IBudgetLedgerRepository {
+	return &BudgetLedgerRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+// WithContext is the repository with its statements run under ctx.
+func (r *BudgetLedgerRepository) WithContext(ctx context.Context) IBudgetLedgerRepository {
+	return &BudgetLedgerRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
+func (r *BudgetLedgerRepository) FindAllByLineIDPaginated(lineID uuid.UUID, page int, pageSize int) (*[]entity.BudgetLedgerEntry, int64, error) {
+	var entries []entity.BudgetLedgerEntry
+	var total int64
+
+	query := r.DB.Model(&entity.BudgetLedgerEntry{}).Where("mp_planning_line_id = ?", lineID)
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.FindAllByLineIDPaginated] " + err.Error())
+		return nil, 0, errors.New("[BudgetLedgerRepository.FindAllByLineIDPaginated] " + err.Error())
+	}
+
+	// deleted requests stay in the history of the line
+	if err := query.Preload("MPRequestHeader", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&entries).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.FindAllByLineIDPaginated] " + err.Error())
+		return nil, 0, errors.New("[BudgetLedgerRepository.FindAllByLineIDPaginated] " + err.Error())
+	}
+
+	return &entries, total, nil
+}
+
+// LockLines locks the planning lines until the transaction of the repository ends.
+func (r *BudgetLedgerRepository) LockLines(lineIDs []uuid.UUID) error {
+	if len(lineIDs) == 0 {
+		return nil
+	}
+
+	var lockedIDs []uuid.UUID
+	if err := r.DB.Model(&entity.MPPlanningLine{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", lineIDs).Pluck("id", &lockedIDs).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.LockLines] " + err.Error())
+		return errors.New("[BudgetLedgerRepository.LockLines] " + err.Error())
+	}
+
+	return nil
+}
+
+// SumByLineIDs sums the ledger of the lines per balance, leaving out the entries of the request.
+func (r *BudgetLedgerRepository) SumByLineIDs(lineIDs []uuid.UUID, excludeMPRequestID *uuid.UUID) ([]BudgetLedgerSum, error) {
+	var sums []BudgetLedgerSum
+
+	if len(lineIDs) == 0 {
+		return sums, nil
+	}
+
+	query := r.DB.Model(&entity.BudgetLedgerEntry{}).
+		Select("mp_planning_line_id, balance, COALESCE(SUM(reserved), 0) AS reserved, COALESCE(SUM(consumed), 0) AS consumed").
+		Where("mp_planning_line_id IN ?", lineIDs)
+
+	if excludeMPRequestID != nil {
+		query = query.Where("mp_request_header_id <> ?", excludeMPRequestID)
+	}
+
+	if err := query.Group("mp_planning_line_id, balance").Scan(&sums).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.SumByLineIDs] " + err.Error())
+		return nil, errors.New("[BudgetLedgerRepository.SumByLineIDs] " + err.Error())
+	}
+
+	return sums, nil
+}
+
+// SumByMPRequestID sums what the request still holds per line and balance.
+func (r *BudgetLedgerRepository) SumByMPRequestID(mpRequestID uuid.UUID) ([]BudgetLedgerSum, error) {
+	var sums []BudgetLedgerSum
+
+	if err := r.DB.Model(&entity.BudgetLedgerEntry{}).
+		Select("mp_planning_line_id, balance, COALESCE(SUM(reserved), 0) AS reserved, COALESCE(SUM(consumed), 0) AS consumed").
+		Where("mp_request_header_id = ?", mpRequestID).
+		Group("mp_planning_line_id, balance").
+		Scan(&sums).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.SumByMPRequestID] " + err.Error())
+		return nil, errors.New("[BudgetLedgerRepository.SumByMPRequestID] " + err.Error())
+	}
+
+	return sums, nil
+}
+
+func (r *BudgetLedgerRepository) CountByMPRequestID(mpRequestID uuid.UUID) (int64, error) {
+	var total int64
+
+	if err := r.DB.Model(&entity.BudgetLedgerEntry{}).Where("mp_request_header_id = ?", mpRequestID).Count(&total).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.CountByMPRequestID] " + err.Error())
+		return 0, errors.New("[BudgetLedgerRepository.CountByMPRequestID] " + err.Error())
+	}
+
+	return total, nil
+}
+
+// FindUntrackedMPRequests finds the on budget requests in the statuses without a ledger entry.
+func (r *BudgetLedgerRepository) FindUntrackedMPRequests(statuses []entity.MPRequestStatus) (*[]entity.MPRequestHeader, error) {
+	var mpRequestHeaders []entity.MPRequestHeader
+
+	if err := r.DB.Where("status IN ? AND mp_planning_header_id IS NOT NULL AND mp_request_type <> ?", statuses, entity.MPRequestTypeEnumOffBudget).
+		Where("NOT EXISTS (SELECT 1 FROM budget_ledger_entries WHERE budget_ledger_entries.mp_request_header_id = mp_request_headers.id AND budget_ledger_entries.deleted_at IS NULL)").
+		Order("created_at ASC").
+		Find(&mpRequestHeaders).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.FindUntrackedMPRequests] " + err.Error())
+		return nil, errors.New("[BudgetLedgerRepository.FindUntrackedMPRequests] " + err.Error())
+	}
+
+	return &mpRequestHeaders, nil
+}
+
+func (r *BudgetLedgerRepository) Create(entries []entity.BudgetLedgerEntry) error {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.Create] " + tx.Error.Error())
+		return errors.New("[BudgetLedgerRepository.Create] " + tx.Error.Error())
+	}
+
+	if err := appendBudgetLedgerEntries(tx, entries); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BudgetLedgerRepository.Create] " + err.Error())
+		return errors.New("[BudgetLedgerRepository.Create] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[BudgetLedgerRepository.Create] " + err.Error())
+		return errors.New("[BudgetLedgerRepository.Create] " + err.Error())
+	}
+
+	return nil
+}
+
+// RefreshAllRemainingBalances recomputes the remaining balances of every planning line.
+func (r *BudgetLedgerRepository) RefreshAllRemainingBalances() error {
+	if err := r.DB.Model(&entity.MPPlanningLine{}).Where("1 = 1").UpdateColumns(remainingBalanceUpdates()).Error; err != nil {
+		r.Log.Errorf("[BudgetLedgerRepository.RefreshAllRemainingBalances] " + err.Error())
+		return errors.New("[BudgetLedgerRepository.RefreshAllRemainingBalances] " + err.Error())
+	}
+
+	return nil
+}
+
+// buildBudgetLedgerEntries builds the entries with the ledger bound to tx and
+// saves them, inside the caller's transaction.
+func buildBudgetLedgerEntries(tx *gorm.DB, log *logrus.Logger, entries BudgetLedgerEntries) error {
+	if entries == nil {
+		return nil
+	}
+
+	built, err := entries(NewBudgetLedgerRepository(log, tx))
+	if err != nil {
+		return err
+	}
+
+	return appendBudgetLedgerEntries(tx, built)
+}
+
+// appendBudgetLedgerEntries saves the entries and recomputes the remaining
+// balances of the lines they touch, inside the caller's transaction.
+func appendBudgetLedgerEntries(tx *gorm.DB, entries []entity.BudgetLedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&entries).Error; err != nil {
+		return err
+	}
+
+	var lineIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, entry := range entries {
+		if !seen[entry.MPPlanningLineID] {
+			seen[entry.MPPlanningLineID] = true
+			lineIDs = append(lineIDs, entry.MPPlanningLineID)
+		}
+	}
+
+	return refreshRemainingBalances(tx, lineIDs)
+}
+
+func refreshRemainingBalances(tx *gorm.DB, lineIDs []uuid.UUID) error {
+	if len(lineIDs) == 0 {
+		return nil
+	}
+
+	return tx.Model(&entity.MPPlanningLine{}).Where("id IN ?", lineIDs).UpdateColumns(remainingBalanceUpdates()).Error
+}
+
+func remainingBalanceUpdates() map[string]interface{} {
+	held := "(SELECT COALESCE(SUM(reserved + consumed), 0) FROM budget_ledger_entries WHERE budget_ledger_entries.mp_planning_line_id = mp_planning_lines.id AND budget_ledger_entries.balance = ? AND budget_ledger_entries.deleted_at IS NULL)"
+
+	return map[string]interface{}{
+		"remaining_balance_mt": gorm.Expr("recruit_mt - "+held, entity.BudgetBalanceMT),
+		"remaining_balance_ph": gorm.Expr("recruit_ph - "+held, entity.BudgetBalancePH),
+	}
+}
+
+func BudgetLedgerRepositoryFactory(log *logrus.Logger) IBudgetLedgerRepository {
+	db := config.NewDatabase()
+	return NewBudgetLedgerRepository(log, db)
+}
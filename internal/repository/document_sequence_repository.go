@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IDocumentSequenceRepository previews document numbers; saving a document reserves its number.
+type IDocumentSequenceRepository interface {
+	Peek(numbering *workflow.DocumentNumbering) (string, error)
+}
+
+type DocumentSequenceRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewDocumentSequenceRepository(log *logrus.Logger, db *gorm.DB) IDocumentSequenceRepository {
+	return &DocumentSequenceRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+// Peek is the number the next document would get, without reserving it.
+func (r *DocumentSequenceRepository) Peek(numbering *workflow.DocumentNumbering) (string, error) {
+	var sequence entity.DocumentSequence
+	err := r.DB.Where("document_type = ? AND period = ?", string(numbering.Sequence), numbering.Format.Period(numbering.At)).First(&sequence).Error
+	if err == nil {
+		return numbering.Format.Format(numbering.At, sequence.LastNumber+1), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		r.Log.Errorf("[DocumentSequenceRepository.Peek] " + err.Error())
+		return "", errors.New("[DocumentSequenceRepository.Peek] " + err.Error())
+	}
+
+	highest, err := highestDocumentNumber(r.DB, numbering)
+	if err != nil {
+		r.Log.Errorf("[DocumentSequenceRepository.Peek] " + err.Error())
+		return "", errors.New("[DocumentSequenceRepository.Peek] " + err.Error())
+	}
+
+	return numbering.Format.Format(numbering.At, highest+1), nil
+}
+
+// reserveDocumentNumber takes the next number of the sequence in tx. The
+// sequence row stays locked until tx ends, so concurrent documents wait for
+// each other, and a rolled back document leaves no gap.
+func reserveDocumentNumber(tx *gorm.DB, numbering *workflow.DocumentNumbering) (string, error) {
+	period := numbering.Format.Period(numbering.At)
+
+	sequence, err := lockDocumentSequence(tx, numbering, period)
+	if err != nil {
+		return "", err
+	}
+
+	if sequence == nil {
+		// a new sequence carries on from the documents numbered before it
+		// existed; when two transactions create it at once, the second insert
+		// is ignored
+		highest, err := highestDocumentNumber(tx, numbering)
+		if err != nil {
+			return "", err
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.DocumentSequence{
+			DocumentType: string(numbering.Sequence),
+			Period:       period,
+			LastNumber:   highest,
+		}).Error; err != nil {
+			return "", err
+		}
+
+		sequence, err = lockDocumentSequence(tx, numbering, period)
+		if err != nil {
+			return "", err
+		}
+		if sequence == nil {
+			return "", errors.New("document sequence " + string(numbering.Sequence) + " " + period + " was not created")
+		}
+	}
+
+	sequence.LastNumber++
+	if err := tx.Model(&entity.DocumentSequence{}).Where("id = ?", sequence.ID).Update("last_number", sequence.LastNumber).Error; err != nil {
+		return "", err
+	}
+
+	return numbering.Format.Format(numbering.At, sequence.LastNumber), nil
+}
+
+// lockDocumentSequence locks the sequence row of the period, nil when there
+// is none yet.
+func lockDocumentSequence(tx *gorm.DB, numbering *workflow.DocumentNumbering, period string) (*entity.DocumentSequence, error) {
+	var sequence entity.DocumentSequence
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("document_type = ? AND period = ?", string(numbering.Sequence), period).First(&sequence).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sequence, nil
+}
+
+// highestDocumentNumber is the highest sequence number of the documents in
+// the table that have the format and period of numbering, deleted ones
+// included, 0 when there are none.
+func highestDocumentNumber(db *gorm.DB, numbering *workflow.DocumentNumbering) (int, error) {
+	if numbering.Table == "" {
+		return 0, nil
+	}
+
+	head := numbering.Format.Head(numbering.At)
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+	var documentNumbers []string
+	if err := db.Table(numbering.Table).Where("document_number LIKE ?", escaper.Replace(head)+"%").Pluck("document_number", &documentNumbers).Error; err != nil {
+		return 0, err
+	}
+
+	highest := 0
+	for _, documentNumber := range documentNumbers {
+		if number, ok := numbering.Format.SequenceNumber(numbering.At, documentNumber); ok && number > highest {
+			highest = number
+		}
+	}
+	return highest, nil
+}
+
+func DocumentSequenceRepositoryFactory(log *logrus.Logger) IDocumentSequenceRepository {
+	db := config.NewDatabase()
+	return NewDocumentSequenceRepository(log, db)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/google/uuid"
+)
+
+func documentSequenceTest() (*workflow.DocumentNumbering, []string, uuid.UUID) {
+	numbering := &workflow.DocumentNumbering{
+		Sequence: workflow.NumberSequenceMPRequest,
+		Format:   workflow.DefaultDocumentNumberFormats[workflow.NumberSequenceMPRequest],
+		At:       time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+		Table:    "mp_request_headers",
+	}
+	return numbering, []string{"id", "document_type", "period", "last_number"}, uuid.New()
+}
+
+func TestReserveDocumentNumber(t *testing.T) {
+	numbering, sequenceColumns, sequenceID := documentSequenceTest()
+	db, mock := mockDB(t)
+	mock.MatchExpectationsInOrder(true)
+
+	// the row is locked first and the documents are not scanned
+	mock.ExpectQuery("SELECT \\* FROM `document_sequences` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows(sequenceColumns).AddRow(sequenceID, "mp_request", "20261017", 4))
+	mock.ExpectExec("UPDATE `document_sequences` SET `last_number`=.*").
+		WithArgs(5, sqlmock.AnyArg(), sequenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	got, err := reserveDocumentNumber(db, numbering)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "MPR/20261017/005" {
+		t.Errorf("number = %s, want MPR/20261017/005", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReserveDocumentNumberNewSequence(t *testing.T) {
+	numbering, sequenceColumns, sequenceID := documentSequenceTest()
+	db, mock := mockDB(t)
+	mock.MatchExpectationsInOrder(true)
+
+	mock.ExpectQuery("SELECT \\* FROM `document_sequences` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows(sequenceColumns))
+	mock.ExpectQuery("SELECT `document_number` FROM `mp_request_headers` WHERE document_number LIKE").
+		WillReturnRows(sqlmock.NewRows([]string{"document_number"}).AddRow("MPR/20261017/002").AddRow("MPR/20261017/007"))
+	mock.ExpectExec("INSERT INTO `document_sequences`").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT \\* FROM `document_sequences` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows(sequenceColumns).AddRow(sequenceID, "mp_request", "20261017", 7))
+	mock.ExpectExec("UPDATE `document_sequences` SET `last_number`=.*").
+		WithArgs(8, sqlmock.AnyArg(), sequenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	got, err := reserveDocumentNumber(db, numbering)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "MPR/20261017/008" {
+		t.Errorf("number = %s, want MPR/20261017/008", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// findInIDBatches reads query in batches of batchSize ordered by id, each one
+// starting after the last id of the one before, and hands them to fn. Keyset
+// batches keep long exports steady where offsets would slow down, and only
+// one batch is held at a time.
+func findInIDBatches[T any](query *gorm.DB, batchSize int, id func(*T) uuid.UUID, fn func([]T) error) error {
+	query = query.Session(&gorm.Session{})
+
+	var last *uuid.UUID
+	for {
+		var rows []T
+
+		batch := query.Order("id ASC").Limit(batchSize)
+		if last != nil {
+			batch = batch.Where("id > ?", *last)
+		}
+		if err := batch.Find(&rows).Error; err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		if err := fn(rows); err != nil {
+			return err
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+
+		lastID := id(&rows[len(rows)-1])
+		last = &lastID
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
@@ -14,9 +15,12 @@ type IJobPlafonRepository interface {
 	FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.JobPlafon, int64, error)
 	FindById(id uuid.UUID) (*entity.JobPlafon, error)
 	FindByJobId(jobId uuid.UUID) (*entity.JobPlafon, error)
-	Create(jobPlafon *entity.JobPlafon) (*entity.JobPlafon, error)
-	Update(jobPlafon *entity.JobPlafon) (*entity.JobPlafon, error)
-	Delete(id uuid.UUID) error
+	SumPlannedHeadcount(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeLineIDs []uuid.UUID) (int, int, error)
+	SumRequestedHeadcount(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeRequestID *uuid.UUID) (int, error)
+	Create(jobPlafon *entity.JobPlafon, outboxMessages []entity.OutboxMessage) (*entity.JobPlafon, error)
+	Update(jobPlafon *entity.JobPlafon, outboxMessages []entity.OutboxMessage) (*entity.JobPlafon, error)
+	Delete(id uuid.UUID, outboxMessages []entity.OutboxMessage) error
+	WithContext(ctx context.Context) IJobPlafonRepository
 }
 
 type JobPlafonRepository struct {
@@ -31,6 +35,14 @@ func NewJobPlafonRepository(log *logrus.Logger, db *gorm.DB) IJobPlafonRepositor
 	}
 }
 
+// WithContext is the repository with its statements run under ctx.
+func (r *JobPlafonRepository) WithContext(ctx context.Context) IJobPlafonRepository {
+	return &JobPlafonRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
 func (r *JobPlafonRepository) FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.JobPlafon, int64, error) {
 	var jobPlafons []entity.JobPlafon
 	var total int64
@@ -94,7 +106,53 @@ func (r *JobPlafonRepository) FindByJobId(jobId uuid.UUID) (*entity.JobPlafon, e
 	return &jobPlafon, nil
 }
 
-func (r *JobPlafonRepository) Create(jobPlafon *entity.JobPlafon) (*entity.JobPlafon, error) {
+// SumPlannedHeadcount sums the existing and planned headcount of the job in the period.
+func (r *JobPlafonRepository) SumPlannedHeadcount(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeLineIDs []uuid.UUID) (int, int, error) {
+	var sums struct {
+		Existing int
+		Recruit  int
+	}
+
+	query := r.DB.Model(&entity.MPPlanningLine{}).
+		Select("COALESCE(SUM(mp_planning_lines.existing), 0) AS existing, COALESCE(SUM(mp_planning_lines.recruit_ph + mp_planning_lines.recruit_mt), 0) AS recruit").
+		Joins("JOIN mp_planning_headers ON mp_planning_headers.id = mp_planning_lines.mp_planning_header_id AND mp_planning_headers.deleted_at IS NULL").
+		Where("mp_planning_headers.mpp_period_id = ? AND mp_planning_lines.job_id = ?", mppPeriodID, jobID).
+		Where("mp_planning_headers.status <> ?", entity.MPPlaningStatusReject)
+
+	if len(excludeLineIDs) > 0 {
+		query = query.Where("mp_planning_lines.id NOT IN ?", excludeLineIDs)
+	}
+
+	if err := query.Scan(&sums).Error; err != nil {
+		r.Log.Errorf("[JobPlafonRepository.SumPlannedHeadcount] " + err.Error())
+		return 0, 0, errors.New("[JobPlafonRepository.SumPlannedHeadcount] " + err.Error())
+	}
+
+	return sums.Existing, sums.Recruit, nil
+}
+
+// SumRequestedHeadcount sums the needs of the requests for the job in the period.
+func (r *JobPlafonRepository) SumRequestedHeadcount(mppPeriodID uuid.UUID, jobID uuid.UUID, excludeRequestID *uuid.UUID) (int, error) {
+	var total int
+
+	query := r.DB.Model(&entity.MPRequestHeader{}).
+		Select("COALESCE(SUM(total_needs), 0)").
+		Where("mpp_period_id = ? AND job_id = ?", mppPeriodID, jobID).
+		Where("status <> ?", entity.MPRequestStatusRejected)
+
+	if excludeRequestID != nil {
+		query = query.Where("id <> ?", excludeRequestID)
+	}
+
+	if err := query.Scan(&total).Error; err != nil {
+		r.Log.Errorf("[JobPlafonRepository.SumRequestedHeadcount] " + err.Error())
+		return 0, errors.New("[JobPlafonRepository.SumRequestedHeadcount] " + err.Error())
+	}
+
+	return total, nil
+}
+
+func (r *JobPlafonRepository) Create(jobPlafon *entity.JobPlafon, outboxMessages []entity.OutboxMessage) (*entity.JobPlafon, error) {
 	tx := r.DB.Begin()
 
 	if tx.Error != nil {
@@ -108,6 +166,12 @@ func (r *JobPlafonRepository) Create(jobPlafon *entity.JobPlafon) (*entity.JobPl
 		return nil, err
 	}
 
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[JobPlafonRepository.Create] " + err.Error())
+		return nil, errors.New("[JobPlafonRepository.Create] " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[JobPlafonRepository.Create] " + err.Error())
@@ -117,7 +181,7 @@ func (r *JobPlafonRepository) Create(jobPlafon *entity.JobPlafon) (*entity.JobPl
 	return jobPlafon, nil
 }
 
-func (r *JobPlafonRepository) Update(jobPlafon *entity.JobPlafon) (*entity.JobPlafon, error) {
+func (r *JobPlafonRepository) Update(jobPlafon *entity.JobPlafon, outboxMessages []entity.OutboxMessage) (*entity.JobPlafon, error) {
 	tx := r.DB.Begin()
 
 	if tx.Error != nil {
@@ -131,6 +195,12 @@ func (r *JobPlafonRepository) Update(jobPlafon *entity.JobPlafon) (*entity.JobPl
 		return nil, err
 	}
 
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[JobPlafonRepository.Update] " + err.Error())
+		return nil, errors.New("[JobPlafonRepository.Update] " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[JobPlafonRepository.Update] " + err.Error())
@@ -140,7 +210,7 @@ func (r *JobPlafonRepository) Update(jobPlafon *entity.JobPlafon) (*entity.JobPl
 	return jobPlafon, nil
 }
 
-func (r *JobPlafonRepository) Delete(id uuid.UUID) error {
+func (r *JobPlafonRepository) Delete(id uuid.UUID, outboxMessages []entity.OutboxMessage) error {
 	tx := r.DB.Begin()
 
 	if tx.Error != nil {
@@ -154,6 +224,12 @@ func (r *JobPlafonRepository) Delete(id uuid.UUID) error {
 		return errors.New("[JobPlafonRepository.Delete] " + err.Error())
 	}
 
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[JobPlafonRepository.Delete] " + err.Error())
+		return errors.New("[JobPlafonRepository.Delete] " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[JobPlafonRepository.Delete] " + err.Error())
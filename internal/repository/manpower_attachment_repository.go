@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IManpowerAttachmentRepository interface {
+	Create(attachment *entity.ManpowerAttachment) (*entity.ManpowerAttachment, error)
+}
+
+type ManpowerAttachmentRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewManpowerAttachmentRepository(log *logrus.Logger, db *gorm.DB) IManpowerAttachmentRepository {
+	return &ManpowerAttachmentRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+func (r *ManpowerAttachmentRepository) Create(attachment *entity.ManpowerAttachment) (*entity.ManpowerAttachment, error) {
+	if err := r.DB.Create(attachment).Error; err != nil {
+		r.Log.Errorf("[ManpowerAttachmentRepository.Create] " + err.Error())
+		return nil, errors.New("[ManpowerAttachmentRepository.Create] " + err.Error())
+	}
+
+	return attachment, nil
+}
+
+func ManpowerAttachmentRepositoryFactory(log *logrus.Logger) IManpowerAttachmentRepository {
+	db := config.NewDatabase()
+	return NewManpowerAttachmentRepository(log, db)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IMPPlanningLineImportRepository interface {
+	Create(mpPlanningLineImport *entity.MPPlanningLineImport) (*entity.MPPlanningLineImport, error)
+	FindById(id uuid.UUID) (*entity.MPPlanningLineImport, error)
+	UpdateStatus(id uuid.UUID, from entity.MPPlanningLineImportStatus, to entity.MPPlanningLineImportStatus) (bool, error)
+	WithContext(ctx context.Context) IMPPlanningLineImportRepository
+}
+
+type MPPlanningLineImportRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewMPPlanningLineImportRepository(log *logrus.Logger, db *gorm.DB) IMPPlanningLineImportRepository {
+	return &MPPlanningLineImportRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+// WithContext is the repository with its statements run under ctx.
+func (r *MPPlanningLineImportRepository) WithContext(ctx context.Context) IMPPlanningLineImportRepository {
+	return &MPPlanningLineImportRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
+// Create saves the import together with its rows.
+func (r *MPPlanningLineImportRepository) Create(mpPlanningLineImport *entity.MPPlanningLineImport) (*entity.MPPlanningLineImport, error) {
+	if err := r.DB.Create(mpPlanningLineImport).Error; err != nil {
+		r.Log.Errorf("[MPPlanningLineImportRepository.Create] " + err.Error())
+		return nil, errors.New("[MPPlanningLineImportRepository.Create] " + err.Error())
+	}
+
+	return r.FindById(mpPlanningLineImport.ID)
+}
+
+func (r *MPPlanningLineImportRepository) FindById(id uuid.UUID) (*entity.MPPlanningLineImport, error) {
+	var mpPlanningLineImport entity.MPPlanningLineImport
+
+	if err := r.DB.Preload("MPPlanningLineImportRows", func(db *gorm.DB) *gorm.DB { return db.Order("row_number ASC") }).Where("id = ?", id).First(&mpPlanningLineImport).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.Log.Errorf("[MPPlanningLineImportRepository.FindById] " + err.Error())
+		return nil, errors.New("[MPPlanningLineImportRepository.FindById] " + err.Error())
+	}
+
+	return &mpPlanningLineImport, nil
+}
+
+// UpdateStatus moves the import from one status to another, false when it was not in from.
+func (r *MPPlanningLineImportRepository) UpdateStatus(id uuid.UUID, from entity.MPPlanningLineImportStatus, to entity.MPPlanningLineImportStatus) (bool, error) {
+	updates := map[string]interface{}{
+		"status":     to,
+		"updated_at": time.Now().UTC(),
+	}
+	if to == entity.MPPlanningLineImportStatusConfirmed {
+		updates["confirmed_at"] = time.Now().UTC()
+	} else {
+		updates["confirmed_at"] = nil
+	}
+
+	result := r.DB.Model(&entity.MPPlanningLineImport{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	if result.Error != nil {
+		r.Log.Errorf("[MPPlanningLineImportRepository.UpdateStatus] " + result.Error.Error())
+		return false, errors.New("[MPPlanningLineImportRepository.UpdateStatus] " + result.Error.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func MPPlanningLineImportRepositoryFactory(log *logrus.Logger) IMPPlanningLineImportRepository {
+	db := config.NewDatabase()
+	return NewMPPlanningLineImportRepository(log, db)
+}
@@ -1,21 +1,24 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IMPPlanningRepository interface {
-	GetHighestDocumentNumberByDate(date string) (int, error)
 	CountMPPlanningHeaderByMPPPeriodIDAndApproverType(mppPeriodID uuid.UUID, approverType string) (int64, error)
 	FindAllHeadersPaginated(page int, pageSize int, search string, approverType string, orgLocationId string, orgId string, status entity.MPPlaningStatus, requestorId string) (*[]entity.MPPlanningHeader, int64, error)
-	FindAllHeadersByRequestorIDPaginated(requestorID uuid.UUID, page int, pageSize int, search string) (*[]entity.MPPlanningHeader, int64, error)
+	FindAllHeadersInBatches(search string, approverType string, orgLocationId string, orgId string, status entity.MPPlaningStatus, requestorId string, mppPeriodId string, batchSize int, fn func([]entity.MPPlanningHeader) error) error
+	FindAllHeadersByRequestorIDPaginated(requestorID uuid.UUID, page int, pageSize int, search string, orgID string) (*[]entity.MPPlanningHeader, int64, error)
 	FindAllHeaders() (*[]entity.MPPlanningHeader, error)
 	FindHeaderByRequestorOrganizationLocationNotStatus(requestorID uuid.UUID, organizationLocationID uuid.UUID, status entity.MPPlaningStatus) (*entity.MPPlanningHeader, error)
 	FindAllHeadersByOrganizationLocationID(organizationLocationID uuid.UUID) (*[]entity.MPPlanningHeader, error)
@@ -25,6 +28,7 @@ type IMPPlanningRepository interface {
 	GetHeadersBySomething(something map[string]interface{}) (*[]entity.MPPlanningHeader, error)
 	GetHeadersByOrganizationID(organizationID uuid.UUID) (*[]entity.MPPlanningHeader, error)
 	FindAllHeadersByStatusAndMPPeriodID(status entity.MPPlaningStatus, mppPeriodID uuid.UUID) (*[]entity.MPPlanningHeader, error)
+	FindAllHeadersByStatusesAndMPPeriodIDNewestFirst(statuses []entity.MPPlaningStatus, mppPeriodID uuid.UUID, organizationID *uuid.UUID) (*[]entity.MPPlanningHeader, error)
 	FindAllHeadersByStatusAndMPPPeriodIDPaginated(status entity.MPPlaningStatus, mppPeriodID uuid.UUID, page int, pageSize int) (*[]entity.MPPlanningHeader, int64, error)
 	CountTotalApprovalHistoryByStatus(mpPlanningHeaderId uuid.UUID, status entity.MPPlanningApprovalHistoryStatus) (int64, error)
 	FindHeaderByOrganizationLocationID(organizationLocationID uuid.UUID) (*entity.MPPlanningHeader, error)
@@ -33,16 +37,22 @@ type IMPPlanningRepository interface {
 	FindAllHeadersGroupedApproverByOrg(organizationID string, status entity.MPPlaningStatus, approver string, requestorId string) (*entity.MPPlanningHeader, error)
 	GetAllHeadersGroupedApproverByOrg(organizationID string, status entity.MPPlaningStatus, approver string, requestorId string) (*[]entity.MPPlanningHeader, error)
 	GetHeadersByStatus(status entity.MPPlaningStatus) (*[]entity.MPPlanningHeader, error)
-	UpdateStatusHeader(id uuid.UUID, status string, approvedBy string, approvalHistory *entity.MPPlanningApprovalHistory) error
+	UpdateStatusHeader(id uuid.UUID, version int, status string, approvedBy string, nextApprover *NextApprover, approvalHistory *entity.MPPlanningApprovalHistory, outboxMessages []entity.OutboxMessage) error
+	FindAwaitingApproval() (*[]entity.MPPlanningHeader, error)
+	MarkApprovalReminded(id uuid.UUID, remindedAt time.Time, outboxMessages []entity.OutboxMessage) error
+	EscalateApproval(id uuid.UUID, nextApproverID *uuid.UUID, nextApproverLevel string, approvalHistory *entity.MPPlanningApprovalHistory, outboxMessages []entity.OutboxMessage) error
 	GetHeadersByDocumentDate(documentDate string) (*[]entity.MPPlanningHeader, error)
 	GetHeadersByCreatedAt(createdAt string) (*[]entity.MPPlanningHeader, error)
-	CreateHeader(mppHeader *entity.MPPlanningHeader) (*entity.MPPlanningHeader, error)
+	CreateHeader(mppHeader *entity.MPPlanningHeader, numbering *workflow.DocumentNumbering) (*entity.MPPlanningHeader, error)
+	CreateHeadersWithLines(mppHeaders []entity.MPPlanningHeader, numbering *workflow.DocumentNumbering) ([]entity.MPPlanningHeader, error)
 	UpdateHeader(mppHeader *entity.MPPlanningHeader) (*entity.MPPlanningHeader, error)
+	ClaimHeaderVersion(id uuid.UUID, version int) error
 	StoreAttachmentToHeader(mppHeader *entity.MPPlanningHeader, attachment entity.ManpowerAttachment) (*entity.MPPlanningHeader, error)
 	StoreAttachmentToApprovalHistory(mppApprovalHistory *entity.MPPlanningApprovalHistory, attachment entity.ManpowerAttachment) (*entity.MPPlanningApprovalHistory, error)
 	StoreAttachmentsToApprovalHistory(mppApprovalHistories *entity.MPPlanningApprovalHistory, attachments []entity.ManpowerAttachment) (*entity.MPPlanningApprovalHistory, error)
 	GetPlanningApprovalHistoryByHeaderId(headerId uuid.UUID) (*[]entity.MPPlanningApprovalHistory, error)
 	GetPlanningApprovalHistoryAttachmentsByApprovalHistoryId(approvalHistoryId uuid.UUID) (*[]entity.ManpowerAttachment, error)
+	FindApprovalHistoryById(id uuid.UUID) (*entity.MPPlanningApprovalHistory, error)
 	DeleteAttachmentFromHeader(mppHeader *entity.MPPlanningHeader, attachmentID uuid.UUID) (*entity.MPPlanningHeader, error)
 	DeleteHeader(id uuid.UUID) error
 	FindHeaderByMPPPeriodId(mppPeriodId uuid.UUID) (*entity.MPPlanningHeader, error)
@@ -51,7 +61,6 @@ type IMPPlanningRepository interface {
 	FindLineByIdOnly(id uuid.UUID) (*entity.MPPlanningLine, error)
 	CreateLine(mppLine *entity.MPPlanningLine) (*entity.MPPlanningLine, error)
 	UpdateLine(mppLine *entity.MPPlanningLine) (*entity.MPPlanningLine, error)
-	UpdateLineRemainingBalances(id uuid.UUID, remainingBalanceMT int, remainingBalancePH int) error
 	UpdateLineByHeaderIDAndJobID(headerID uuid.UUID, jobID uuid.UUID, mppLine *entity.MPPlanningLine) (*entity.MPPlanningLine, error)
 	FindLineByHeaderIDAndJobID(headerID uuid.UUID, jobID uuid.UUID) (*entity.MPPlanningLine, error)
 	GetLinesByHeaderAndJobID(headerID uuid.UUID, jobID uuid.UUID) (*[]entity.MPPlanningLine, error)
@@ -62,6 +71,7 @@ type IMPPlanningRepository interface {
 	FindAllLinesByHeaderID(headerID uuid.UUID) (*[]entity.MPPlanningLine, error)
 	FindLineByHeaderID(headerID uuid.UUID) (*entity.MPPlanningLine, error)
 	FindByKeys(keys map[string]interface{}) (*entity.MPPlanningHeader, error)
+	WithContext(ctx context.Context) IMPPlanningRepository
 }
 
 type MPPlanningRepository struct {
@@ -76,6 +86,14 @@ func NewMPPlanningRepository(log *logrus.Logger, db *gorm.DB) IMPPlanningReposit
 	}
 }
 
+// WithContext is the repository with its statements run under ctx.
+func (r *MPPlanningRepository) WithContext(ctx context.Context) IMPPlanningRepository {
+	return &MPPlanningRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
 func (r *MPPlanningRepository) FindAllHeaders() (*[]entity.MPPlanningHeader, error) {
 	var mppHeaders []entity.MPPlanningHeader
 
@@ -92,20 +110,6 @@ func (r *MPPlanningRepository) FindAllHeaders() (*[]entity.MPPlanningHeader, err
 	return &mppHeaders, nil
 }
 
-func (r *MPPlanningRepository) GetHighestDocumentNumberByDate(date string) (int, error) {
-	var maxNumber int
-	err := r.DB.Raw(`
-			SELECT COALESCE(MAX(CAST(SUBSTRING(document_number FROM '[0-9]+$') AS INTEGER)), 0)
-			FROM mp_planning_headers
-			WHERE DATE(created_at) = ?
-	`, date).Scan(&maxNumber).Error
-	if err != nil {
-		r.Log.Errorf("[MPPlanningRepository.GetHighestDocumentNumberByDate] error when querying max document number: %v", err)
-		return 0, err
-	}
-	return maxNumber, nil
-}
-
 func (r *MPPlanningRepository) CountMPPlanningHeaderByMPPPeriodIDAndApproverType(mppPeriodID uuid.UUID, approverType string) (int64, error) {
 	var total int64
 
@@ -220,6 +224,23 @@ func (r *MPPlanningRepository) FindAllHeadersByStatusAndMPPeriodID(status entity
 	return &mppHeaders, nil
 }
 
+// FindAllHeadersByStatusesAndMPPeriodIDNewestFirst finds the headers of the period in statuses, newest first.
+func (r *MPPlanningRepository) FindAllHeadersByStatusesAndMPPeriodIDNewestFirst(statuses []entity.MPPlaningStatus, mppPeriodID uuid.UUID, organizationID *uuid.UUID) (*[]entity.MPPlanningHeader, error) {
+	var mppHeaders []entity.MPPlanningHeader
+
+	query := r.DB.Preload("MPPlanningLines").Where("status IN ? AND mpp_period_id = ?", statuses, mppPeriodID)
+	if organizationID != nil {
+		query = query.Where("organization_id = ?", *organizationID)
+	}
+
+	if err := query.Order("document_date DESC").Order("created_at DESC").Find(&mppHeaders).Error; err != nil {
+		r.Log.Errorf("[MPPlanningRepository.FindAllHeadersByStatusesAndMPPeriodIDNewestFirst] " + err.Error())
+		return nil, errors.New("[MPPlanningRepository.FindAllHeadersByStatusesAndMPPeriodIDNewestFirst] " + err.Error())
+	}
+
+	return &mppHeaders, nil
+}
+
 func (r *MPPlanningRepository) FindAllHeadersByStatusAndMPPPeriodIDPaginated(status entity.MPPlaningStatus, mppPeriodID uuid.UUID, page int, pageSize int) (*[]entity.MPPlanningHeader, int64, error) {
 	var mppHeaders []entity.MPPlanningHeader
 	var total int64
@@ -288,6 +309,25 @@ func (r *MPPlanningRepository) FindAllHeadersPaginated(page int, pageSize int, s
 
 	query := r.DB.Model(&entity.MPPlanningHeader{}).Preload("MPPlanningLines").Preload("MPPPeriod")
 
+	query = r.applyFindAllHeadersFilter(query, search, approverType, orgLocationId, orgId, status, requestorId)
+
+	countQuery := query.Session(&gorm.Session{})
+	if err := countQuery.Count(&total).Error; err != nil {
+		r.Log.Errorf("[MPPlanningRepository.FindAllHeadersPaginated - count  side] " + err.Error())
+		return nil, 0, errors.New("[MPPlanningRepository.FindAllHeadersPaginated - count side] " + err.Error())
+	}
+
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&mppHeaders).Error; err != nil {
+		r.Log.Errorf("[MPPlanningRepository.FindAllHeadersPaginated - pagination side] " + err.Error())
+		return nil, 0, errors.New("[MPPlanningRepository.FindAllHeadersPaginated - pagination side] " + err.Error())
+	}
+
+	return &mppHeaders, total, nil
+}
+
+// applyFindAllHeadersFilter applies the search and filters of the planning
+// header list to query. The export reads the list through it too.
+func (r *MPPlanningRepository) applyFindAllHeadersFilter(query *gorm.DB, search string, approverType string, orgLocationId string, orgId string, status entity.MPPlaningStatus, requestorId string) *gorm.DB {
 	if search != "" {
 		query = query.Where("document_number LIKE ?", "%"+search+"%")
 	}
@@ -319,21 +359,27 @@ func (r *MPPlanningRepository) FindAllHeadersPaginated(page int, pageSize int, s
 		query = query.Where("status != 'COMPLETED'")
 	}
 
-	countQuery := query.Session(&gorm.Session{})
-	if err := countQuery.Count(&total).Error; err != nil {
-		r.Log.Errorf("[MPPlanningRepository.FindAllHeadersPaginated - count  side] " + err.Error())
-		return nil, 0, errors.New("[MPPlanningRepository.FindAllHeadersPaginated - count side] " + err.Error())
+	return query
+}
+
+// FindAllHeadersInBatches hands the planning headers of the list to fn in batches ordered by id.
+func (r *MPPlanningRepository) FindAllHeadersInBatches(search string, approverType string, orgLocationId string, orgId string, status entity.MPPlaningStatus, requestorId string, mppPeriodId string, batchSize int, fn func([]entity.MPPlanningHeader) error) error {
+	query := r.DB.Model(&entity.MPPlanningHeader{}).Preload("MPPlanningLines").Preload("MPPPeriod")
+	query = r.applyFindAllHeadersFilter(query, search, approverType, orgLocationId, orgId, status, requestorId)
+
+	if mppPeriodId != "" {
+		query = query.Where("mpp_period_id = ?", mppPeriodId)
 	}
 
-	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&mppHeaders).Error; err != nil {
-		r.Log.Errorf("[MPPlanningRepository.FindAllHeadersPaginated - pagination side] " + err.Error())
-		return nil, 0, errors.New("[MPPlanningRepository.FindAllHeadersPaginated - pagination side] " + err.Error())
+	if err := findInIDBatches(query, batchSize, func(m *entity.MPPlanningHeader) uuid.UUID { return m.ID }, fn); err != nil {
+		r.Log.Errorf("[MPPlanningRepository.FindAllHeadersInBatches] " + err.Error())
+		return errors.New("[MPPlanningRepository.FindAllHeadersInBatches] " + err.Error())
 	}
 
-	return &mppHeaders, total, nil
+	return nil
 }
 
-func (r *MPPlanningRepository) FindAllHeadersByRequestorIDPaginated(requestorID uuid.UUID, page int, pageSize int, search string) (*[]entity.MPPlanningHeader, int64, error) {
+func (r *MPPlanningRepository) FindAllHeadersByRequestorIDPaginated(requestorID uuid.UUID, page int, pageSize int, search string, orgID string) (*[]entity.MPPlanningHeader, int64, error) {
 	var mppHeaders []entity.MPPlanningHeader
 
 	query := r.DB.Model(&entity.MPPlanningHeader{}).Preload("MPPlanningLines").Preload("MPPPeriod").Where("requestor_id = ?", requestorID)
@@ -342,6 +388,10 @@ func (r *MPPlanningRepository) FindAllHeadersByRequestorIDPaginated(requestorID
 		query = query.Where("name LIKE ?", "%"+search+"%")
 	}
 
+	if orgID != "" {
+		query = query.Where("organization_id = ?", orgID)
+	}
+
 	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&mppHeaders).Error; err != nil {
 		r.Log.Errorf("[MPPlanningRepository.FindAllHeadersByRequestorIDPaginated] " + err.Error())
 		return nil, 0, errors.New("[MPPlanningRepository.FindAllHeadersByRequestorIDPaginated] " + err.Error())
@@ -479,7 +529,7 @@ func (r *MPPlanningRepository) FindAllHeadersGroupedApprover(organizationLocatio
 		}
 	}
 
-	r.Log.Infof("Header: ", mppHeader.DocumentNumber)
+	r.Log.Infof("Header: %s", mppHeader.DocumentNumber)
 
 	return &mppHeader, nil
 }
@@ -580,7 +630,7 @@ func (r *MPPlanningRepository) FindAllHeadersGroupedApproverByOrg(organizationID
 		}
 	}
 
-	r.Log.Infof("Header: ", mppHeader.DocumentNumber)
+	r.Log.Infof("Header: %s", mppHeader.DocumentNumber)
 
 	return &mppHeader, nil
 }
@@ -633,7 +683,7 @@ func (r *MPPlanningRepository) GetAllHeadersGroupedApproverByOrg(organizationID
 					return nil, errors.New("[MPPlanningRepository.GetAllHeadersGroupedApproverByOrg] " + err.Error())
 				}
 			} else {
-				r.Log.Infof("ketemu ini headernya: %s", mppHeaders)
+				r.Log.Infof("ketemu ini headernya: %v", mppHeaders)
 			}
 		} else {
 			if status != "" {
@@ -652,7 +702,7 @@ func (r *MPPlanningRepository) GetAllHeadersGroupedApproverByOrg(organizationID
 					return nil, errors.New("[MPPlanningRepository.GetAllHeadersGroupedApproverByOrg] " + err.Error())
 				}
 			} else {
-				r.Log.Infof("ketemu ini headernya: %s", mppHeaders)
+				r.Log.Infof("ketemu ini headernya: %v", mppHeaders)
 			}
 		}
 	} else {
@@ -680,7 +730,7 @@ func (r *MPPlanningRepository) GetAllHeadersGroupedApproverByOrg(organizationID
 		}
 	}
 
-	r.Log.Infof("Header: ", mppHeaders)
+	r.Log.Infof("Header: %v", mppHeaders)
 
 	return &mppHeaders, nil
 }
@@ -780,7 +830,7 @@ func (r *MPPlanningRepository) GetHeadersByCreatedAt(createdAt string) (*[]entit
 	return &mppHeaders, nil
 }
 
-func (r *MPPlanningRepository) UpdateStatusHeader(id uuid.UUID, status string, approvedBy string, approvalHistory *entity.MPPlanningApprovalHistory) error {
+func (r *MPPlanningRepository) UpdateStatusHeader(id uuid.UUID, version int, status string, approvedBy string, nextApprover *NextApprover, approvalHistory *entity.MPPlanningApprovalHistory, outboxMessages []entity.OutboxMessage) error {
 	tx := r.DB.Begin()
 
 	if tx.Error != nil {
@@ -788,81 +838,33 @@ func (r *MPPlanningRepository) UpdateStatusHeader(id uuid.UUID, status string, a
 		return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + tx.Error.Error())
 	}
 
-	if approvalHistory != nil {
-		if approvalHistory.Status != entity.MPPlanningApprovalHistoryStatusRejected {
-			if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelHRDUnit) {
-				if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(&entity.MPPlanningHeader{
-					Status: entity.MPPlaningStatus(status),
-					// ApprovedBy:        approvedBy,
-					ApproverManagerID: &approvalHistory.ApproverID,
-					NotesManager:      approvalHistory.Notes,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-					return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-				}
-			} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelDirekturUnit) {
-				if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(&entity.MPPlanningHeader{
-					Status: entity.MPPlaningStatus(status),
-					// ApprovedBy:            approvedBy,
-					RecommendedBy: approvedBy,
-					// ApproverRecruitmentID: &approvalHistory.ApproverID,
-					// NotesRecruitment:      approvalHistory.Notes,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-					return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-				}
-			} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelRecruitment) {
-				if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(&entity.MPPlanningHeader{
-					Status: entity.MPPlaningStatus(status),
-					// ApprovedBy:            approvedBy,
-					ApproverRecruitmentID: &approvalHistory.ApproverID,
-					NotesRecruitment:      approvalHistory.Notes,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-					return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-				}
-			} else if approvalHistory.Level == string(entity.MPPlanningApprovalHistoryLevelCEO) {
-				if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(&entity.MPPlanningHeader{
-					Status:     entity.MPPlaningStatus(status),
-					ApprovedBy: approvedBy,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-					return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-				}
-			} else {
-				if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(&entity.MPPlanningHeader{
-					Status: entity.MPPlaningStatus(status),
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-					return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-				}
-			}
-		} else {
-			if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Select("Status", "ApprovedBy", "RecommendedBy", "ApproverRecruitmentID", "ApproverManagerID").Updates(&entity.MPPlanningHeader{
-				Status:                entity.MPPlaningStatus(status),
-				ApprovedBy:            "",
-				RecommendedBy:         "",
-				ApproverRecruitmentID: nil,
-				ApproverManagerID:     nil,
-			}).Error; err != nil {
-				tx.Rollback()
-				r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-				return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-			}
-		}
-	} else {
-		if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(&entity.MPPlanningHeader{
-			Status: entity.MPPlaningStatus(status),
-		}).Error; err != nil {
-			tx.Rollback()
-			r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-			return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
-		}
+	if err := claimVersion(tx, &entity.MPPlanningHeader{}, id, version); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+		return err
+	}
+
+	updates, columns := workflow.MPPlanningHeaderUpdates(status, approvedBy, approvalHistory)
+	query := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id)
+	if columns != nil {
+		query = query.Select(columns)
+	}
+	if err := query.Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+		return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+	}
+
+	if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(approvalClockUpdates(status == string(entity.MPPlaningStatusNeedApproval))).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+		return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+	}
+
+	if err := updateNextApprover(tx, &entity.MPPlanningHeader{}, id, nextApprover); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+		return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
 	}
 
 	if approvalHistory != nil {
@@ -873,6 +875,12 @@ func (r *MPPlanningRepository) UpdateStatusHeader(id uuid.UUID, status string, a
 		}
 	}
 
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+		return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPlanningRepository.UpdateStatusHeader] " + err.Error())
@@ -882,7 +890,97 @@ func (r *MPPlanningRepository) UpdateStatusHeader(id uuid.UUID, status string, a
 	return nil
 }
 
-func (r *MPPlanningRepository) CreateHeader(mppHeader *entity.MPPlanningHeader) (*entity.MPPlanningHeader, error) {
+// FindAwaitingApproval returns the planning headers waiting in NEED APPROVAL with a running SLA clock.
+func (r *MPPlanningRepository) FindAwaitingApproval() (*[]entity.MPPlanningHeader, error) {
+	var headers []entity.MPPlanningHeader
+
+	query := r.DB.Where("status = ? AND approval_requested_at IS NOT NULL", entity.MPPlaningStatusNeedApproval).
+		Where("NOT EXISTS (SELECT 1 FROM batch_lines JOIN batch_headers ON batch_headers.id = batch_lines.batch_header_id WHERE batch_lines.mp_planning_header_id = mp_planning_headers.id AND batch_headers.status = ? AND batch_lines.deleted_at IS NULL AND batch_headers.deleted_at IS NULL)", entity.BatchHeaderApprovalStatusNeedApproval)
+
+	if err := query.Find(&headers).Error; err != nil {
+		r.Log.Errorf("[MPPlanningRepository.FindAwaitingApproval] " + err.Error())
+		return nil, errors.New("[MPPlanningRepository.FindAwaitingApproval] " + err.Error())
+	}
+
+	return &headers, nil
+}
+
+func (r *MPPlanningRepository) MarkApprovalReminded(id uuid.UUID, remindedAt time.Time, outboxMessages []entity.OutboxMessage) error {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[MPPlanningRepository.MarkApprovalReminded] " + tx.Error.Error())
+		return errors.New("[MPPlanningRepository.MarkApprovalReminded] " + tx.Error.Error())
+	}
+
+	if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Update("approval_reminded_at", remindedAt).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[MPPlanningRepository.MarkApprovalReminded] " + err.Error())
+	}
+
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[MPPlanningRepository.MarkApprovalReminded] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[MPPlanningRepository.MarkApprovalReminded] " + err.Error())
+	}
+
+	return nil
+}
+
+// EscalateApproval hands the pending approval over to another approver and restarts its SLA clock.
+func (r *MPPlanningRepository) EscalateApproval(id uuid.UUID, nextApproverID *uuid.UUID, nextApproverLevel string, approvalHistory *entity.MPPlanningApprovalHistory, outboxMessages []entity.OutboxMessage) error {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[MPPlanningRepository.EscalateApproval] " + tx.Error.Error())
+		return errors.New("[MPPlanningRepository.EscalateApproval] " + tx.Error.Error())
+	}
+
+	if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Select("NextApproverID", "NextApproverLevel").Updates(&entity.MPPlanningHeader{
+		NextApproverID:    nextApproverID,
+		NextApproverLevel: nextApproverLevel,
+	}).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPPlanningRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", id).Updates(approvalClockUpdates(true)).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPPlanningRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := tx.Create(approvalHistory).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPPlanningRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPPlanningRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPPlanningRepository.EscalateApproval] " + err.Error())
+	}
+
+	return nil
+}
+
+// CreateHeader saves the header, numbered by numbering unless it is nil.
+func (r *MPPlanningRepository) CreateHeader(mppHeader *entity.MPPlanningHeader, numbering *workflow.DocumentNumbering) (*entity.MPPlanningHeader, error) {
 	tx := r.DB.Begin()
 
 	if tx.Error != nil {
@@ -890,6 +988,16 @@ func (r *MPPlanningRepository) CreateHeader(mppHeader *entity.MPPlanningHeader)
 		return nil, errors.New("[MPPlanningRepository.CreateHeader] " + tx.Error.Error())
 	}
 
+	if numbering != nil {
+		documentNumber, err := reserveDocumentNumber(tx, numbering)
+		if err != nil {
+			tx.Rollback()
+			r.Log.Errorf("[MPPlanningRepository.CreateHeader] " + err.Error())
+			return nil, errors.New("[MPPlanningRepository.CreateHeader] " + err.Error())
+		}
+		mppHeader.DocumentNumber = documentNumber
+	}
+
 	mppHeader.CreatedAt = time.Now()
 
 	if err := tx.Create(&mppHeader).Error; err != nil {
@@ -914,6 +1022,56 @@ func (r *MPPlanningRepository) CreateHeader(mppHeader *entity.MPPlanningHeader)
 	return mppHeader, nil
 }
 
+// CreateHeadersWithLines creates the headers with their lines in one transaction.
+func (r *MPPlanningRepository) CreateHeadersWithLines(mppHeaders []entity.MPPlanningHeader, numbering *workflow.DocumentNumbering) ([]entity.MPPlanningHeader, error) {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[MPPlanningRepository.CreateHeadersWithLines] " + tx.Error.Error())
+		return nil, errors.New("[MPPlanningRepository.CreateHeadersWithLines] " + tx.Error.Error())
+	}
+
+	for i := range mppHeaders {
+		mppHeader := &mppHeaders[i]
+
+		if numbering != nil {
+			documentNumber, err := reserveDocumentNumber(tx, numbering)
+			if err != nil {
+				tx.Rollback()
+				r.Log.Errorf("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+				return nil, errors.New("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+			}
+			mppHeader.DocumentNumber = documentNumber
+		}
+
+		if err := tx.Omit(clause.Associations).Create(mppHeader).Error; err != nil {
+			tx.Rollback()
+			r.Log.Errorf("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+			return nil, errors.New("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+		}
+
+		for j := range mppHeader.MPPlanningLines {
+			mppHeader.MPPlanningLines[j].MPPlanningHeaderID = mppHeader.ID
+		}
+
+		if len(mppHeader.MPPlanningLines) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&mppHeader.MPPlanningLines).Error; err != nil {
+				tx.Rollback()
+				r.Log.Errorf("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+				return nil, errors.New("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+		return nil, errors.New("[MPPlanningRepository.CreateHeadersWithLines] " + err.Error())
+	}
+
+	return mppHeaders, nil
+}
+
 func (r *MPPlanningRepository) UpdateHeader(mppHeader *entity.MPPlanningHeader) (*entity.MPPlanningHeader, error) {
 	tx := r.DB.Begin()
 
@@ -922,6 +1080,13 @@ func (r *MPPlanningRepository) UpdateHeader(mppHeader *entity.MPPlanningHeader)
 		return nil, errors.New("[MPPlanningRepository.UpdateHeader] " + tx.Error.Error())
 	}
 
+	if err := claimVersion(tx, &entity.MPPlanningHeader{}, mppHeader.ID, mppHeader.Version); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPPlanningRepository.UpdateHeader] " + err.Error())
+		return nil, err
+	}
+	mppHeader.Version++
+
 	if err := tx.Model(&entity.MPPlanningHeader{}).Where("id = ?", mppHeader.ID).Updates(mppHeader).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPlanningRepository.UpdateHeader] " + err.Error())
@@ -937,6 +1102,16 @@ func (r *MPPlanningRepository) UpdateHeader(mppHeader *entity.MPPlanningHeader)
 	return mppHeader, nil
 }
 
+// ClaimHeaderVersion moves the header with id on from version.
+func (r *MPPlanningRepository) ClaimHeaderVersion(id uuid.UUID, version int) error {
+	if err := claimVersion(r.DB, &entity.MPPlanningHeader{}, id, version); err != nil {
+		r.Log.Warnf("[MPPlanningRepository.ClaimHeaderVersion] " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (r *MPPlanningRepository) StoreAttachmentToHeader(mppHeader *entity.MPPlanningHeader, attachment entity.ManpowerAttachment) (*entity.MPPlanningHeader, error) {
 	tx := r.DB.Begin()
 
@@ -1044,6 +1219,20 @@ func (r *MPPlanningRepository) GetPlanningApprovalHistoryAttachmentsByApprovalHi
 	return &attachments, nil
 }
 
+func (r *MPPlanningRepository) FindApprovalHistoryById(id uuid.UUID) (*entity.MPPlanningApprovalHistory, error) {
+	var mppApprovalHistory entity.MPPlanningApprovalHistory
+
+	if err := r.DB.Where("id = ?", id).First(&mppApprovalHistory).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.Log.Errorf("[MPPlanningRepository.FindApprovalHistoryById] " + err.Error())
+		return nil, errors.New("[MPPlanningRepository.FindApprovalHistoryById] " + err.Error())
+	}
+
+	return &mppApprovalHistory, nil
+}
+
 func (r *MPPlanningRepository) DeleteAttachmentFromHeader(mppHeader *entity.MPPlanningHeader, attachmentID uuid.UUID) (*entity.MPPlanningHeader, error) {
 	tx := r.DB.Begin()
 
@@ -1176,6 +1365,12 @@ func (r *MPPlanningRepository) CreateLine(mppLine *entity.MPPlanningLine) (*enti
 		return nil, errors.New("[MPPlanningRepository.CreateLine] " + tx.Error.Error())
 	}
 
+	if err := lockEditablePlanning(tx, mppLine.MPPlanningHeaderID); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPPlanningRepository.CreateLine] " + err.Error())
+		return nil, err
+	}
+
 	if err := tx.Create(mppLine).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPlanningRepository.CreateLine] " + err.Error())
@@ -1199,42 +1394,45 @@ func (r *MPPlanningRepository) UpdateLine(mppLine *entity.MPPlanningLine) (*enti
 		return nil, errors.New("[MPPlanningRepository.UpdateLine] " + tx.Error.Error())
 	}
 
+	if err := lockEditablePlanning(tx, mppLine.MPPlanningHeaderID); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPPlanningRepository.UpdateLine] " + err.Error())
+		return nil, err
+	}
+
+	if err := claimVersion(tx, &entity.MPPlanningLine{}, mppLine.ID, mppLine.Version); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPPlanningRepository.UpdateLine] " + err.Error())
+		return nil, err
+	}
+	mppLine.Version++
+
 	if err := tx.Model(mppLine).Where("id = ?", mppLine.ID).Updates(mppLine).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPlanningRepository.UpdateLine] " + err.Error())
 		return nil, errors.New("[MPPlanningRepository.UpdateLine] " + err.Error())
 	}
 
-	if err := tx.Commit().Error; err != nil {
+	// the remaining balances follow the budget ledger, not the request
+	if err := refreshRemainingBalances(tx, []uuid.UUID{mppLine.ID}); err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPlanningRepository.UpdateLine] " + err.Error())
 		return nil, errors.New("[MPPlanningRepository.UpdateLine] " + err.Error())
 	}
 
-	return mppLine, nil
-}
-
-func (r *MPPlanningRepository) UpdateLineRemainingBalances(id uuid.UUID, remainingBalanceMT int, remainingBalancePH int) error {
-	tx := r.DB.Begin()
-
-	if tx.Error != nil {
-		r.Log.Errorf("[MPPlanningRepository.UpdateLineRemainingBalances] " + tx.Error.Error())
-		return errors.New("[MPPlanningRepository.UpdateLineRemainingBalances] " + tx.Error.Error())
-	}
-
-	if err := tx.Model(&entity.MPPlanningLine{}).Where("id = ?", id).Updates(map[string]interface{}{"remaining_balance_mt": remainingBalanceMT, "remaining_balance_ph": remainingBalancePH}).Error; err != nil {
+	if err := tx.Select("remaining_balance_mt", "remaining_balance_ph").Where("id = ?", mppLine.ID).First(mppLine).Error; err != nil {
 		tx.Rollback()
-		r.Log.Errorf("[MPPlanningRepository.UpdateLineRemainingBalances] " + err.Error())
-		return errors.New("[MPPlanningRepository.UpdateLineRemainingBalances] " + err.Error())
+		r.Log.Errorf("[MPPlanningRepository.UpdateLine] " + err.Error())
+		return nil, errors.New("[MPPlanningRepository.UpdateLine] " + err.Error())
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		r.Log.Errorf("[MPPlanningRepository.UpdateLineRemainingBalances] " + err.Error())
-		return errors.New("[MPPlanningRepository.UpdateLineRemainingBalances] " + err.Error())
+		r.Log.Errorf("[MPPlanningRepository.UpdateLine] " + err.Error())
+		return nil, errors.New("[MPPlanningRepository.UpdateLine] " + err.Error())
 	}
 
-	return nil
+	return mppLine, nil
 }
 
 func (r *MPPlanningRepository) UpdateLineByHeaderIDAndJobID(headerID uuid.UUID, jobID uuid.UUID, mppLine *entity.MPPlanningLine) (*entity.MPPlanningLine, error) {
@@ -1300,6 +1498,12 @@ func (r *MPPlanningRepository) DeleteLine(id uuid.UUID) error {
 		return errors.New("[MPPlanningRepository.DeleteLine] " + tx.Error.Error())
 	}
 
+	if err := lockEditablePlanningsOfLines(tx, []uuid.UUID{id}); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPPlanningRepository.DeleteLine] " + err.Error())
+		return err
+	}
+
 	if err := tx.Where("id = ?", id).Delete(&entity.MPPlanningLine{}).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPlanningRepository.DeleteLine] " + err.Error())
@@ -1403,6 +1607,12 @@ func (r *MPPlanningRepository) DeleteLinesByIDs(ids []uuid.UUID) error {
 		return errors.New("[MPPlanningRepository.DeleteLinesByIDs] " + tx.Error.Error())
 	}
 
+	if err := lockEditablePlanningsOfLines(tx, ids); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPPlanningRepository.DeleteLinesByIDs] " + err.Error())
+		return err
+	}
+
 	if err := tx.Where("id IN ?", ids).Delete(&entity.MPPlanningLine{}).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPlanningRepository.DeleteLinesByIDs] " + err.Error())
@@ -1,33 +1,40 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IMPRequestRepository interface {
-	GetHighestDocumentNumberByDate(date string) (int, error)
-	Create(mpRequestHeader *entity.MPRequestHeader) (*entity.MPRequestHeader, error)
+	Create(mpRequestHeader *entity.MPRequestHeader, numbering *workflow.DocumentNumbering, ledgerEntries BudgetLedgerEntries) (*entity.MPRequestHeader, error)
 	FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) ([]entity.MPRequestHeader, int64, error)
+	FindAllInBatches(search string, filter map[string]interface{}, batchSize int, fn func([]entity.MPRequestHeader) error) error
 	FindAll() ([]entity.MPRequestHeader, error)
 	GetHeadersByDocumentDate(documentDate string) ([]entity.MPRequestHeader, error)
 	GetHeadersByCreatedAt(createdAt string) ([]entity.MPRequestHeader, error)
 	GetRequestApprovalHistoryByHeaderId(headerID uuid.UUID, status entity.MPRequestApprovalHistoryStatus) ([]entity.MPRequestApprovalHistory, error)
 	FindById(id uuid.UUID) (*entity.MPRequestHeader, error)
 	FindByIDOnly(id uuid.UUID) (*entity.MPRequestHeader, error)
-	Update(mpRequestHeader *entity.MPRequestHeader) (*entity.MPRequestHeader, error)
-	UpdateStatusHeader(id uuid.UUID, status string, approvedBy string, approvalHistory *entity.MPRequestApprovalHistory) error
+	Update(mpRequestHeader *entity.MPRequestHeader, ledgerEntries BudgetLedgerEntries) (*entity.MPRequestHeader, error)
+	UpdateStatusHeader(id uuid.UUID, version int, status string, approvedBy string, nextApprover *NextApprover, approvalHistory *entity.MPRequestApprovalHistory, ledgerEntries BudgetLedgerEntries, outboxMessages []entity.OutboxMessage) error
+	FindAwaitingApproval() (*[]entity.MPRequestHeader, error)
+	MarkApprovalReminded(id uuid.UUID, remindedAt time.Time, outboxMessages []entity.OutboxMessage) error
+	EscalateApproval(id uuid.UUID, nextApproverID *uuid.UUID, nextApproverLevel string, approvalHistory *entity.MPRequestApprovalHistory, outboxMessages []entity.OutboxMessage) error
 	StoreAttachmentToApprovalHistory(mppApprovalHistory *entity.MPRequestApprovalHistory, attachment entity.ManpowerAttachment) (*entity.MPRequestApprovalHistory, error)
-	DeleteHeader(id uuid.UUID) error
+	DeleteHeader(id uuid.UUID, ledgerEntries BudgetLedgerEntries) error
 	CountTotalApprovalHistoryByStatus(mpHeaderID uuid.UUID, status entity.MPRequestApprovalHistoryStatus) (int64, error)
 	FindByKeys(keys map[string]interface{}) (*entity.MPRequestHeader, error)
 	FindAllByMajorIds(majorIds []string) ([]entity.MPRequestHeader, error)
+	WithContext(ctx context.Context) IMPRequestRepository
 }
 
 type MPRequestRepository struct {
@@ -39,6 +46,14 @@ func NewMPRequestRepository(log *logrus.Logger, db *gorm.DB) IMPRequestRepositor
 	return &MPRequestRepository{Log: log, DB: db}
 }
 
+// WithContext is the repository with its statements run under ctx.
+func (r *MPRequestRepository) WithContext(ctx context.Context) IMPRequestRepository {
+	return &MPRequestRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
 func (r *MPRequestRepository) FindAll() ([]entity.MPRequestHeader, error) {
 	var mpRequestHeaders []entity.MPRequestHeader
 
@@ -67,20 +82,6 @@ func (r *MPRequestRepository) FindAllByMajorIds(majorIds []string) ([]entity.MPR
 	return mpRequestHeaders, nil
 }
 
-func (r *MPRequestRepository) GetHighestDocumentNumberByDate(date string) (int, error) {
-	var maxNumber int
-	err := r.DB.Raw(`
-			SELECT COALESCE(MAX(CAST(SUBSTRING(document_number FROM '[0-9]+$') AS INTEGER)), 0)
-			FROM mp_request_headers
-			WHERE DATE(created_at) = ?
-	`, date).Scan(&maxNumber).Error
-	if err != nil {
-		r.Log.Errorf("[MPPlanningRepository.GetHighestDocumentNumberByDate] error when querying max document number: %v", err)
-		return 0, err
-	}
-	return maxNumber, nil
-}
-
 func (r *MPRequestRepository) FindByIDOnly(id uuid.UUID) (*entity.MPRequestHeader, error) {
 	var mpRequestHeader entity.MPRequestHeader
 
@@ -135,15 +136,32 @@ func (r *MPRequestRepository) CountTotalApprovalHistoryByStatus(mpHeaderID uuid.
 	return total, nil
 }
 
-func (r *MPRequestRepository) Create(mpRequestHeader *entity.MPRequestHeader) (*entity.MPRequestHeader, error) {
+// Create saves the request with its budget ledger entries, numbered by numbering unless it is nil.
+func (r *MPRequestRepository) Create(mpRequestHeader *entity.MPRequestHeader, numbering *workflow.DocumentNumbering, ledgerEntries BudgetLedgerEntries) (*entity.MPRequestHeader, error) {
 	tx := r.DB.Begin()
 
+	if numbering != nil {
+		documentNumber, err := reserveDocumentNumber(tx, numbering)
+		if err != nil {
+			tx.Rollback()
+			r.Log.Errorf("[MPRequestRepository.Create] error when reserve document number: %v", err)
+			return nil, errors.New("[MPRequestRepository.Create] error when reserve document number " + err.Error())
+		}
+		mpRequestHeader.DocumentNumber = documentNumber
+	}
+
 	if err := tx.Create(mpRequestHeader).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPRequestRepository.Create] error when create mp request header: %v", err)
 		return nil, errors.New("[MPRequestRepository.Create] error when create mp request header " + err.Error())
 	}
 
+	if err := buildBudgetLedgerEntries(tx, r.Log, ledgerEntries); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.Create] error when create budget ledger entries: %v", err)
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPRequestRepository.Create] error when commit transaction: %v", err)
@@ -197,15 +215,29 @@ func (r *MPRequestRepository) GetHeadersByCreatedAt(createdAt string) ([]entity.
 	return mpRequestHeaders, nil
 }
 
-func (r *MPRequestRepository) Update(mpRequestHeader *entity.MPRequestHeader) (*entity.MPRequestHeader, error) {
+// Update saves the request together with its budget ledger entries.
+func (r *MPRequestRepository) Update(mpRequestHeader *entity.MPRequestHeader, ledgerEntries BudgetLedgerEntries) (*entity.MPRequestHeader, error) {
 	tx := r.DB.Begin()
 
+	if err := claimVersion(tx, &entity.MPRequestHeader{}, mpRequestHeader.ID, mpRequestHeader.Version); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPRequestRepository.Update] " + err.Error())
+		return nil, err
+	}
+	mpRequestHeader.Version++
+
 	if err := tx.Where("id = ?", mpRequestHeader.ID).Updates(&mpRequestHeader).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPRequestRepository.Update] error when update mp request header: %v", err)
 		return nil, errors.New("[MPRequestRepository.Update] error when update mp request header " + err.Error())
 	}
 
+	if err := buildBudgetLedgerEntries(tx, r.Log, ledgerEntries); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.Update] error when create budget ledger entries: %v", err)
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPRequestRepository.Update] error when commit transaction: %v", err)
@@ -220,7 +252,8 @@ func (r *MPRequestRepository) Update(mpRequestHeader *entity.MPRequestHeader) (*
 	return mpRequestHeader, nil
 }
 
-func (r *MPRequestRepository) DeleteHeader(id uuid.UUID) error {
+// DeleteHeader deletes the request together with the ledger entries giving back what it held.
+func (r *MPRequestRepository) DeleteHeader(id uuid.UUID, ledgerEntries BudgetLedgerEntries) error {
 	tx := r.DB.Begin()
 
 	if tx.Error != nil {
@@ -230,12 +263,18 @@ func (r *MPRequestRepository) DeleteHeader(id uuid.UUID) error {
 
 	var mprHeader entity.MPRequestHeader
 
-	if err := tx.First(&mprHeader, id).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&mprHeader, id).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPRequestRepository.DeleteHeader] error when query mp request header: %v", err)
 		return errors.New("[MPRequestRepository.DeleteHeader] error when query mp request header " + err.Error())
 	}
 
+	if err := buildBudgetLedgerEntries(tx, r.Log, ledgerEntries); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.DeleteHeader] error when create budget ledger entries: %v", err)
+		return errors.New("[MPRequestRepository.DeleteHeader] error when create budget ledger entries " + err.Error())
+	}
+
 	if err := tx.Where("id = ?", id).Delete(&mprHeader).Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPRequestRepository.DeleteHeader] error when delete mp request header: %v", err)
@@ -292,10 +331,30 @@ func (r *MPRequestRepository) StoreAttachmentToApprovalHistory(mpApprovalHistory
 func (r *MPRequestRepository) FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) ([]entity.MPRequestHeader, int64, error) {
 	var mpRequestHeaders []entity.MPRequestHeader
 	var total int64
-	var isAdmin bool = false
 	// var includedIDs []string = []string{}
 
-	query := r.DB.Preload("MPPPeriod").Preload("RequestCategory").Preload("RequestMajors.Major").Model(&entity.MPRequestHeader{})
+	// the filters may use OR, they are grouped so the organization scope applies to all of them
+	conditions := r.applyFindAllFilter(r.DB.Session(&gorm.Session{NewDB: true}), search, filter)
+	query := r.DB.Preload("MPPPeriod").Preload("RequestCategory").Preload("RequestMajors.Major").Model(&entity.MPRequestHeader{}).Where(conditions)
+	query = applyOrganizationScope(query, filter)
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[MPRequestRepository.FindAllPaginated] error when count mp request headers: %v", err)
+		return nil, 0, errors.New("[MPRequestRepository.FindAllPaginated] error when count mp request headers " + err.Error())
+	}
+
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&mpRequestHeaders).Error; err != nil {
+		r.Log.Errorf("[MPRequestRepository.FindAllPaginated] error when find mp request headers: %v", err)
+		return nil, 0, errors.New("[MPRequestRepository.FindAllPaginated] error when find mp request headers " + err.Error())
+	}
+
+	return mpRequestHeaders, total, nil
+}
+
+// applyFindAllFilter applies the search and filters of the mp request list to
+// query. The export reads the list through it too.
+func (r *MPRequestRepository) applyFindAllFilter(query *gorm.DB, search string, filter map[string]interface{}) *gorm.DB {
+	var isAdmin bool = false
 
 	if search != "" {
 		query = query.Where("document_number LIKE ?", "%"+search+"%")
@@ -364,20 +423,34 @@ func (r *MPRequestRepository) FindAllPaginated(page int, pageSize int, search st
 		}
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		r.Log.Errorf("[MPRequestRepository.FindAllPaginated] error when count mp request headers: %v", err)
-		return nil, 0, errors.New("[MPRequestRepository.FindAllPaginated] error when count mp request headers " + err.Error())
+	return query
+}
+
+// applyOrganizationScope keeps the mp requests of the organization the caller
+// is limited to, or for which it was raised.
+func applyOrganizationScope(query *gorm.DB, filter map[string]interface{}) *gorm.DB {
+	if organizationID, ok := filter["scope_organization_id"]; ok {
+		query = query.Where("(organization_id = ? OR for_organization_id = ?)", organizationID, organizationID)
 	}
+	return query
+}
 
-	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&mpRequestHeaders).Error; err != nil {
-		r.Log.Errorf("[MPRequestRepository.FindAllPaginated] error when find mp request headers: %v", err)
-		return nil, 0, errors.New("[MPRequestRepository.FindAllPaginated] error when find mp request headers " + err.Error())
+// FindAllInBatches hands the mp requests of the list to fn in batches ordered by id.
+func (r *MPRequestRepository) FindAllInBatches(search string, filter map[string]interface{}, batchSize int, fn func([]entity.MPRequestHeader) error) error {
+	// the filters may use OR, they are grouped so the batch condition applies to all of them
+	conditions := r.applyFindAllFilter(r.DB.Session(&gorm.Session{NewDB: true}), search, filter)
+	query := r.DB.Preload("MPPPeriod").Preload("RequestCategory").Preload("RequestMajors.Major").Model(&entity.MPRequestHeader{}).Where(conditions)
+	query = applyOrganizationScope(query, filter)
+
+	if err := findInIDBatches(query, batchSize, func(m *entity.MPRequestHeader) uuid.UUID { return m.ID }, fn); err != nil {
+		r.Log.Errorf("[MPRequestRepository.FindAllInBatches] " + err.Error())
+		return errors.New("[MPRequestRepository.FindAllInBatches] " + err.Error())
 	}
 
-	return mpRequestHeaders, total, nil
+	return nil
 }
 
-func (r *MPRequestRepository) UpdateStatusHeader(id uuid.UUID, status string, approvedBy string, approvalHistory *entity.MPRequestApprovalHistory) error {
+func (r *MPRequestRepository) UpdateStatusHeader(id uuid.UUID, version int, status string, approvedBy string, nextApprover *NextApprover, approvalHistory *entity.MPRequestApprovalHistory, ledgerEntries BudgetLedgerEntries, outboxMessages []entity.OutboxMessage) error {
 	tx := r.DB.Begin()
 
 	if tx.Error != nil {
@@ -385,6 +458,12 @@ func (r *MPRequestRepository) UpdateStatusHeader(id uuid.UUID, status string, ap
 		return errors.New("[MPPlanningRepository.UpdateStatusHeader] " + tx.Error.Error())
 	}
 
+	if err := claimVersion(tx, &entity.MPRequestHeader{}, id, version); err != nil {
+		tx.Rollback()
+		r.Log.Warnf("[MPRequestRepository.UpdateStatusHeader] " + err.Error())
+		return err
+	}
+
 	var approvedByPtr *uuid.UUID
 
 	if approvedBy != "" {
@@ -397,76 +476,27 @@ func (r *MPRequestRepository) UpdateStatusHeader(id uuid.UUID, status string, ap
 		approvedByPtr = &approvedByUUID
 	}
 
-	if approvalHistory != nil {
-		if approvalHistory.Status != entity.MPRequestApprovalHistoryStatusRejected {
-			r.Log.Info("Ini bukan reject")
-			if approvalHistory.Level == string(entity.MPRequestApprovalHistoryLevelCEO) {
-				if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(&entity.MPRequestHeader{
-					Status: entity.MPRequestStatus(status),
-					CEO:    approvedByPtr,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
-					return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
-				}
-			} else if approvalHistory.Level == string(entity.MPRequestApprovalHistoryLevelVP) {
-				if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(&entity.MPRequestHeader{
-					Status:       entity.MPRequestStatus(status),
-					VpGmDirector: approvedByPtr,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
-					return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
-				}
-			} else if approvalHistory.Level == string(entity.MPRequestApprovalHistoryLevelHeadDept) {
-				if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(&entity.MPRequestHeader{
-					Status:         entity.MPRequestStatus(status),
-					DepartmentHead: approvedByPtr,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
-					return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
-				}
-			} else if approvalHistory.Level == string(entity.MPRequestApprovalHistoryLevelStaff) {
-				if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(&entity.MPRequestHeader{
-					Status: entity.MPRequestStatus(status),
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
-					return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
-				}
-			} else if approvalHistory.Level == string(entity.MPPRequestApprovalHistoryLevelHRDHO) {
-				if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(&entity.MPRequestHeader{
-					Status:    entity.MPRequestStatus(status),
-					HrdHoUnit: approvedByPtr,
-				}).Error; err != nil {
-					tx.Rollback()
-					r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
-					return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
-				}
-			}
-		} else {
-			r.Log.Info("Ini reject")
-			if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Select("Status", "DepartmentHead", "VpGmDirector", "CEO", "HrdHoUnit").Updates(&entity.MPRequestHeader{
-				Status:         entity.MPRequestStatus(status),
-				DepartmentHead: nil,
-				VpGmDirector:   nil,
-				CEO:            nil,
-				HrdHoUnit:      nil,
-			}).Error; err != nil {
-				tx.Rollback()
-				r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
-				return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
-			}
-		}
-	} else {
-		if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(&entity.MPRequestHeader{
-			Status: entity.MPRequestStatus(status),
-		}).Error; err != nil {
-			tx.Rollback()
-			r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
-			return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
-		}
+	updates, columns := workflow.MPRequestHeaderUpdates(status, approvedByPtr, approvalHistory)
+	query := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id)
+	if columns != nil {
+		query = query.Select(columns)
+	}
+	if err := query.Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update mp request header: %v", err)
+		return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update mp request header " + err.Error())
+	}
+
+	if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(approvalClockUpdates(status == string(entity.MPRequestStatusNeedApproval))).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update approval clock: %v", err)
+		return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update approval clock " + err.Error())
+	}
+
+	if err := updateNextApprover(tx, &entity.MPRequestHeader{}, id, nextApprover); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when update next approver: %v", err)
+		return errors.New("[MPRequestRepository.UpdateStatusHeader] error when update next approver " + err.Error())
 	}
 
 	if approvalHistory != nil {
@@ -477,6 +507,18 @@ func (r *MPRequestRepository) UpdateStatusHeader(id uuid.UUID, status string, ap
 		}
 	}
 
+	if err := buildBudgetLedgerEntries(tx, r.Log, ledgerEntries); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when create budget ledger entries: %v", err)
+		return err
+	}
+
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when create outbox messages: %v", err)
+		return errors.New("[MPRequestRepository.UpdateStatusHeader] error when create outbox messages " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPRequestRepository.UpdateStatusHeader] error when commit transaction: %v", err)
@@ -486,6 +528,94 @@ func (r *MPRequestRepository) UpdateStatusHeader(id uuid.UUID, status string, ap
 	return nil
 }
 
+// FindAwaitingApproval returns the request headers waiting in NEED APPROVAL with a running SLA clock.
+func (r *MPRequestRepository) FindAwaitingApproval() (*[]entity.MPRequestHeader, error) {
+	var headers []entity.MPRequestHeader
+
+	query := r.DB.Where("status = ? AND approval_requested_at IS NOT NULL", entity.MPRequestStatusNeedApproval)
+
+	if err := query.Find(&headers).Error; err != nil {
+		r.Log.Errorf("[MPRequestRepository.FindAwaitingApproval] " + err.Error())
+		return nil, errors.New("[MPRequestRepository.FindAwaitingApproval] " + err.Error())
+	}
+
+	return &headers, nil
+}
+
+func (r *MPRequestRepository) MarkApprovalReminded(id uuid.UUID, remindedAt time.Time, outboxMessages []entity.OutboxMessage) error {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[MPRequestRepository.MarkApprovalReminded] " + tx.Error.Error())
+		return errors.New("[MPRequestRepository.MarkApprovalReminded] " + tx.Error.Error())
+	}
+
+	if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Update("approval_reminded_at", remindedAt).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[MPRequestRepository.MarkApprovalReminded] " + err.Error())
+	}
+
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[MPRequestRepository.MarkApprovalReminded] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.MarkApprovalReminded] " + err.Error())
+		return errors.New("[MPRequestRepository.MarkApprovalReminded] " + err.Error())
+	}
+
+	return nil
+}
+
+// EscalateApproval hands the pending approval over to another approver and restarts its SLA clock.
+func (r *MPRequestRepository) EscalateApproval(id uuid.UUID, nextApproverID *uuid.UUID, nextApproverLevel string, approvalHistory *entity.MPRequestApprovalHistory, outboxMessages []entity.OutboxMessage) error {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[MPRequestRepository.EscalateApproval] " + tx.Error.Error())
+		return errors.New("[MPRequestRepository.EscalateApproval] " + tx.Error.Error())
+	}
+
+	if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Select("NextApproverID", "NextApproverLevel").Updates(&entity.MPRequestHeader{
+		NextApproverID:    nextApproverID,
+		NextApproverLevel: nextApproverLevel,
+	}).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPRequestRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := tx.Model(&entity.MPRequestHeader{}).Where("id = ?", id).Updates(approvalClockUpdates(true)).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPRequestRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := tx.Create(approvalHistory).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPRequestRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPRequestRepository.EscalateApproval] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPRequestRepository.EscalateApproval] " + err.Error())
+		return errors.New("[MPRequestRepository.EscalateApproval] " + err.Error())
+	}
+
+	return nil
+}
+
 func (r *MPRequestRepository) FindByKeys(keys map[string]interface{}) (*entity.MPRequestHeader, error) {
 	var mpRequestHeader entity.MPRequestHeader
 
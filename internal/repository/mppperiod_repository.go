@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -14,13 +16,14 @@ import (
 type IMPPPeriodRepository interface {
 	FindAllPaginated(page int, pageSize int, search string) (*[]entity.MPPPeriod, int64, error)
 	FindById(id uuid.UUID) (*entity.MPPPeriod, error)
-	Create(mppPeriod *entity.MPPPeriod) (*entity.MPPPeriod, error)
-	Update(mppPeriod *entity.MPPPeriod) (*entity.MPPPeriod, error)
+	Create(mppPeriod *entity.MPPPeriod, outboxMessages []entity.OutboxMessage) (*entity.MPPPeriod, error)
+	Update(mppPeriod *entity.MPPPeriod, outboxMessages []entity.OutboxMessage) (*entity.MPPPeriod, error)
 	Delete(id uuid.UUID) error
 	FindByCurrentDateAndStatus(status entity.MPPPeriodStatus) (*entity.MPPPeriod, error)
 	FindByStatus(status entity.MPPPeriodStatus) (*entity.MPPPeriod, error)
 	GetMPPPeriodsByStartDate(date time.Time) (*[]entity.MPPPeriod, error)
 	GetMPPPeriodsByEndDate(date time.Time) (*[]entity.MPPPeriod, error)
+	WithContext(ctx context.Context) IMPPPeriodRepository
 }
 
 type MPPPeriodRepository struct {
@@ -35,6 +38,14 @@ func NewMPPPeriodRepository(log *logrus.Logger, db *gorm.DB) IMPPPeriodRepositor
 	}
 }
 
+// WithContext is the repository with its statements run under ctx.
+func (r *MPPPeriodRepository) WithContext(ctx context.Context) IMPPPeriodRepository {
+	return &MPPPeriodRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
 func (r *MPPPeriodRepository) FindByStatus(status entity.MPPPeriodStatus) (*entity.MPPPeriod, error) {
 	var mppPeriod entity.MPPPeriod
 
@@ -97,19 +108,14 @@ func MPPPeriodRepositoryFactory(log *logrus.Logger) IMPPPeriodRepository {
 	return NewMPPPeriodRepository(log, db)
 }
 
-func (r *MPPPeriodRepository) Create(mppPeriod *entity.MPPPeriod) (*entity.MPPPeriod, error) {
+func (r *MPPPeriodRepository) Create(mppPeriod *entity.MPPPeriod, outboxMessages []entity.OutboxMessage) (*entity.MPPPeriod, error) {
 	tx := r.DB.Begin()
 	if tx.Error != nil {
 		r.Log.Errorf("[MPPPeriodRepository.Create] " + tx.Error.Error())
 		return nil, errors.New("[MPPPeriodRepository.Create] " + tx.Error.Error())
 	}
 
-	if mppPeriod.Status != "draft" {
-		dateNow := time.Now().Format("2006-01-02")
-		if dateNow < mppPeriod.StartDate.Format("2006-01-02") {
-			mppPeriod.Status = entity.MPPeriodStatusNotOpen
-		}
-	}
+	mppPeriod.Status = mppPeriod.SavedStatus(timezone.Now())
 
 	if err := tx.Create(mppPeriod).Error; err != nil {
 		tx.Rollback()
@@ -117,6 +123,12 @@ func (r *MPPPeriodRepository) Create(mppPeriod *entity.MPPPeriod) (*entity.MPPPe
 		return nil, errors.New("[MPPPeriodRepository.Create] " + err.Error())
 	}
 
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPPeriodRepository.Create] " + err.Error())
+		return nil, errors.New("[MPPPeriodRepository.Create] " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPPeriodRepository.Create] " + err.Error())
@@ -126,19 +138,14 @@ func (r *MPPPeriodRepository) Create(mppPeriod *entity.MPPPeriod) (*entity.MPPPe
 	return mppPeriod, nil
 }
 
-func (r *MPPPeriodRepository) Update(mppPeriod *entity.MPPPeriod) (*entity.MPPPeriod, error) {
+func (r *MPPPeriodRepository) Update(mppPeriod *entity.MPPPeriod, outboxMessages []entity.OutboxMessage) (*entity.MPPPeriod, error) {
 	tx := r.DB.Begin()
 	if tx.Error != nil {
 		r.Log.Errorf("[MPPPeriodRepository.Update] " + tx.Error.Error())
 		return nil, errors.New("[MPPPeriodRepository.Update] " + tx.Error.Error())
 	}
 
-	if mppPeriod.Status != "draft" {
-		dateNow := time.Now().Format("2006-01-02")
-		if dateNow < mppPeriod.StartDate.Format("2006-01-02") {
-			mppPeriod.Status = entity.MPPeriodStatusNotOpen
-		}
-	}
+	mppPeriod.Status = mppPeriod.SavedStatus(timezone.Now())
 
 	if err := tx.Model(mppPeriod).Where("id = ?", mppPeriod.ID).Updates(mppPeriod).Error; err != nil {
 		tx.Rollback()
@@ -146,6 +153,12 @@ func (r *MPPPeriodRepository) Update(mppPeriod *entity.MPPPeriod) (*entity.MPPPe
 		return nil, errors.New("[MPPPeriodRepository.Update] " + err.Error())
 	}
 
+	if err := createOutboxMessages(tx, outboxMessages); err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[MPPPeriodRepository.Update] " + err.Error())
+		return nil, errors.New("[MPPPeriodRepository.Update] " + err.Error())
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		r.Log.Errorf("[MPPPeriodRepository.Update] " + err.Error())
@@ -179,7 +192,7 @@ func (r *MPPPeriodRepository) Delete(id uuid.UUID) error {
 func (r *MPPPeriodRepository) FindByCurrentDateAndStatus(status entity.MPPPeriodStatus) (*entity.MPPPeriod, error) {
 	var mppPeriod entity.MPPPeriod
 
-	err := r.DB.Where("status = ? AND start_date <= ? AND end_date >= ?", status, time.Now().Format("2006-01-02"), time.Now().Format("2006-01-02")).First(&mppPeriod).Error
+	err := r.DB.Where("status = ? AND start_date <= ? AND end_date >= ?", status, timezone.Today(), timezone.Today()).First(&mppPeriod).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
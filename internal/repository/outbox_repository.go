@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IOutboxRepository interface {
+	FindAllPaginated(page int, pageSize int, filter map[string]interface{}) (*[]entity.OutboxMessage, int64, error)
+	FindById(id uuid.UUID) (*entity.OutboxMessage, error)
+	FindDue(now time.Time, limit int) (*[]entity.OutboxMessage, error)
+	Claim(id uuid.UUID, now time.Time, leaseUntil time.Time) (bool, error)
+	MarkSent(id uuid.UUID, sentAt time.Time) error
+	MarkFailed(id uuid.UUID, status entity.OutboxMessageStatus, attempts int, nextAttemptAt time.Time, lastError string) error
+	Replay(id uuid.UUID) error
+	Create(outboxMessages []entity.OutboxMessage) error
+}
+
+type OutboxRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewOutboxRepository(log *logrus.Logger, db *gorm.DB) IOutboxRepository {
+	return &OutboxRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+func (r *OutboxRepository) FindAllPaginated(page int, pageSize int, filter map[string]interface{}) (*[]entity.OutboxMessage, int64, error) {
+	var outboxMessages []entity.OutboxMessage
+	var total int64
+
+	query := r.DB.Model(&entity.OutboxMessage{})
+
+	if filter != nil {
+		if status, ok := filter["status"]; ok {
+			query = query.Where("status = ?", status)
+		}
+		if messageType, ok := filter["message_type"]; ok {
+			query = query.Where("message_type = ?", messageType)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[OutboxRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[OutboxRepository.FindAllPaginated] " + err.Error())
+	}
+
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&outboxMessages).Error; err != nil {
+		r.Log.Errorf("[OutboxRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[OutboxRepository.FindAllPaginated] " + err.Error())
+	}
+
+	return &outboxMessages, total, nil
+}
+
+func (r *OutboxRepository) FindById(id uuid.UUID) (*entity.OutboxMessage, error) {
+	var outboxMessage entity.OutboxMessage
+
+	if err := r.DB.Where("id = ?", id).First(&outboxMessage).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Log.Warn("[OutboxRepository.FindById] Outbox message not found")
+			return nil, nil
+		} else {
+			r.Log.Errorf("[OutboxRepository.FindById] " + err.Error())
+			return nil, errors.New("[OutboxRepository.FindById] " + err.Error())
+		}
+	}
+
+	return &outboxMessage, nil
+}
+
+// FindDue returns the pending and failed messages whose next attempt is due, oldest first.
+func (r *OutboxRepository) FindDue(now time.Time, limit int) (*[]entity.OutboxMessage, error) {
+	var outboxMessages []entity.OutboxMessage
+
+	if err := r.DB.Where("status IN ? AND next_attempt_at <= ?", []entity.OutboxMessageStatus{entity.OutboxMessageStatusPending, entity.OutboxMessageStatusFailed}, now).
+		Order("next_attempt_at ASC").Limit(limit).Find(&outboxMessages).Error; err != nil {
+		r.Log.Errorf("[OutboxRepository.FindDue] " + err.Error())
+		return nil, errors.New("[OutboxRepository.FindDue] " + err.Error())
+	}
+
+	return &outboxMessages, nil
+}
+
+// Claim leases a due message until leaseUntil, false when another relay claimed it first.
+func (r *OutboxRepository) Claim(id uuid.UUID, now time.Time, leaseUntil time.Time) (bool, error) {
+	result := r.DB.Model(&entity.OutboxMessage{}).
+		Where("id = ? AND status IN ? AND next_attempt_at <= ?", id, []entity.OutboxMessageStatus{entity.OutboxMessageStatusPending, entity.OutboxMessageStatusFailed}, now).
+		Update("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		r.Log.Errorf("[OutboxRepository.Claim] " + result.Error.Error())
+		return false, errors.New("[OutboxRepository.Claim] " + result.Error.Error())
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *OutboxRepository) MarkSent(id uuid.UUID, sentAt time.Time) error {
+	if err := r.DB.Model(&entity.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     entity.OutboxMessageStatusSent,
+		"sent_at":    sentAt,
+		"last_error": nil,
+	}).Error; err != nil {
+		r.Log.Errorf("[OutboxRepository.MarkSent] " + err.Error())
+		return errors.New("[OutboxRepository.MarkSent] " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(id uuid.UUID, status entity.OutboxMessageStatus, attempts int, nextAttemptAt time.Time, lastError string) error {
+	if err := r.DB.Model(&entity.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	}).Error; err != nil {
+		r.Log.Errorf("[OutboxRepository.MarkFailed] " + err.Error())
+		return errors.New("[OutboxRepository.MarkFailed] " + err.Error())
+	}
+
+	return nil
+}
+
+// Replay queues a message again for immediate delivery with a fresh attempt count.
+func (r *OutboxRepository) Replay(id uuid.UUID) error {
+	if err := r.DB.Model(&entity.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          entity.OutboxMessageStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error; err != nil {
+		r.Log.Errorf("[OutboxRepository.Replay] " + err.Error())
+		return errors.New("[OutboxRepository.Replay] " + err.Error())
+	}
+
+	return nil
+}
+
+// Create saves messages that do not belong to a state change of their own.
+func (r *OutboxRepository) Create(outboxMessages []entity.OutboxMessage) error {
+	if err := createOutboxMessages(r.DB, outboxMessages); err != nil {
+		r.Log.Errorf("[OutboxRepository.Create] " + err.Error())
+		return errors.New("[OutboxRepository.Create] " + err.Error())
+	}
+
+	return nil
+}
+
+// createOutboxMessages saves the outbox messages of a state change inside the
+// transaction of that change, so both are committed or rolled back together.
+func createOutboxMessages(tx *gorm.DB, outboxMessages []entity.OutboxMessage) error {
+	if len(outboxMessages) == 0 {
+		return nil
+	}
+
+	return tx.Create(&outboxMessages).Error
+}
+
+func OutboxRepositoryFactory(log *logrus.Logger) IOutboxRepository {
+	db := config.NewDatabase()
+	return NewOutboxRepository(log, db)
+}
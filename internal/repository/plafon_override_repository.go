@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type IPlafonOverrideRepository interface {
+	FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.PlafonOverride, int64, error)
+	FindById(id uuid.UUID) (*entity.PlafonOverride, error)
+	FindLatestByDocument(documentType entity.PlafonOverrideDocumentType, documentID uuid.UUID) (*entity.PlafonOverride, error)
+	Create(override *entity.PlafonOverride) (*entity.PlafonOverride, error)
+	UpdateDocumentFlags(documentType entity.PlafonOverrideDocumentType, documentID uuid.UUID, isOverPlafon bool, status entity.PlafonOverrideStatus) error
+	UpdateStatus(override *entity.PlafonOverride) (*entity.PlafonOverride, error)
+	WithContext(ctx context.Context) IPlafonOverrideRepository
+}
+
+type PlafonOverrideRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewPlafonOverrideRepository(log *logrus.Logger, db *gorm.DB) IPlafonOverrideRepository {
+	return &PlafonOverrideRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+// WithContext is the repository with its statements run under ctx.
+func (r *PlafonOverrideRepository) WithContext(ctx context.Context) IPlafonOverrideRepository {
+	return &PlafonOverrideRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
+func (r *PlafonOverrideRepository) FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*[]entity.PlafonOverride, int64, error) {
+	var overrides []entity.PlafonOverride
+	var total int64
+
+	query := r.DB.Model(&entity.PlafonOverride{})
+
+	if filter != nil {
+		if status, ok := filter["status"]; ok {
+			query = query.Where("status = ?", status)
+		}
+		if documentType, ok := filter["document_type"]; ok {
+			query = query.Where("document_type = ?", documentType)
+		}
+		if jobID, ok := filter["job_id"]; ok {
+			query = query.Where("job_id = ?", jobID)
+		}
+		if mppPeriodID, ok := filter["mpp_period_id"]; ok {
+			query = query.Where("mpp_period_id = ?", mppPeriodID)
+		}
+	}
+
+	if search != "" {
+		query = query.Where("reason LIKE ? OR approver_name LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.Log.Errorf("[PlafonOverrideRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[PlafonOverrideRepository.FindAllPaginated] " + err.Error())
+	}
+
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&overrides).Error; err != nil {
+		r.Log.Errorf("[PlafonOverrideRepository.FindAllPaginated] " + err.Error())
+		return nil, 0, errors.New("[PlafonOverrideRepository.FindAllPaginated] " + err.Error())
+	}
+
+	return &overrides, total, nil
+}
+
+func (r *PlafonOverrideRepository) FindById(id uuid.UUID) (*entity.PlafonOverride, error) {
+	var override entity.PlafonOverride
+
+	if err := r.DB.Where("id = ?", id).First(&override).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Log.Warn("[PlafonOverrideRepository.FindById] Plafon override not found")
+			return nil, nil
+		} else {
+			r.Log.Errorf("[PlafonOverrideRepository.FindById] " + err.Error())
+			return nil, errors.New("[PlafonOverrideRepository.FindById] " + err.Error())
+		}
+	}
+
+	return &override, nil
+}
+
+// FindLatestByDocument returns the most recent override requested for the document.
+func (r *PlafonOverrideRepository) FindLatestByDocument(documentType entity.PlafonOverrideDocumentType, documentID uuid.UUID) (*entity.PlafonOverride, error) {
+	var override entity.PlafonOverride
+
+	if err := r.DB.Where("document_type = ? AND document_id = ?", documentType, documentID).
+		Order("created_at DESC").First(&override).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		} else {
+			r.Log.Errorf("[PlafonOverrideRepository.FindLatestByDocument] " + err.Error())
+			return nil, errors.New("[PlafonOverrideRepository.FindLatestByDocument] " + err.Error())
+		}
+	}
+
+	return &override, nil
+}
+
+func (r *PlafonOverrideRepository) Create(override *entity.PlafonOverride) (*entity.PlafonOverride, error) {
+	if err := r.DB.Create(override).Error; err != nil {
+		r.Log.Errorf("[PlafonOverrideRepository.Create] " + err.Error())
+		return nil, errors.New("[PlafonOverrideRepository.Create] " + err.Error())
+	}
+
+	return r.FindById(override.ID)
+}
+
+// UpdateDocumentFlags writes the plafon flags of a planning line or manpower request.
+func (r *PlafonOverrideRepository) UpdateDocumentFlags(documentType entity.PlafonOverrideDocumentType, documentID uuid.UUID, isOverPlafon bool, status entity.PlafonOverrideStatus) error {
+	if err := r.documentModel(r.DB, documentType).Where("id = ?", documentID).
+		UpdateColumns(map[string]interface{}{
+			"is_over_plafon":         isOverPlafon,
+			"plafon_override_status": documentOverrideStatus(status),
+		}).Error; err != nil {
+		r.Log.Errorf("[PlafonOverrideRepository.UpdateDocumentFlags] " + err.Error())
+		return errors.New("[PlafonOverrideRepository.UpdateDocumentFlags] " + err.Error())
+	}
+
+	return nil
+}
+
+// UpdateStatus saves the decision on the override and copies its status onto the document.
+func (r *PlafonOverrideRepository) UpdateStatus(override *entity.PlafonOverride) (*entity.PlafonOverride, error) {
+	tx := r.DB.Begin()
+
+	if tx.Error != nil {
+		r.Log.Errorf("[PlafonOverrideRepository.UpdateStatus] " + tx.Error.Error())
+		return nil, errors.New("[PlafonOverrideRepository.UpdateStatus] " + tx.Error.Error())
+	}
+
+	if err := tx.Model(&entity.PlafonOverride{}).Where("id = ?", override.ID).
+		Select("Status", "ApproverID", "ApproverName", "Notes", "DecidedAt").
+		Updates(override).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[PlafonOverrideRepository.UpdateStatus] " + err.Error())
+		return nil, errors.New("[PlafonOverrideRepository.UpdateStatus] " + err.Error())
+	}
+
+	if err := r.documentModel(tx, override.DocumentType).Where("id = ?", override.DocumentID).
+		UpdateColumn("plafon_override_status", override.Status).Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[PlafonOverrideRepository.UpdateStatus] " + err.Error())
+		return nil, errors.New("[PlafonOverrideRepository.UpdateStatus] " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		r.Log.Errorf("[PlafonOverrideRepository.UpdateStatus] " + err.Error())
+		return nil, errors.New("[PlafonOverrideRepository.UpdateStatus] " + err.Error())
+	}
+
+	return r.FindById(override.ID)
+}
+
+func (r *PlafonOverrideRepository) documentModel(db *gorm.DB, documentType entity.PlafonOverrideDocumentType) *gorm.DB {
+	if documentType == entity.PlafonOverrideDocumentTypeMPRequest {
+		return db.Model(&entity.MPRequestHeader{})
+	}
+	return db.Model(&entity.MPPlanningLine{})
+}
+
+func documentOverrideStatus(status entity.PlafonOverrideStatus) interface{} {
+	if status == "" {
+		return nil
+	}
+	return status
+}
+
+func PlafonOverrideRepositoryFactory(log *logrus.Logger) IPlafonOverrideRepository {
+	db := config.NewDatabase()
+	return NewPlafonOverrideRepository(log, db)
+}
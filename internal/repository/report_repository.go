@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Plan vs actual dimensions, in the order rows are grouped by.
+const (
+	PlanVsActualDimensionMPPPeriod            = "mpp_period"
+	PlanVsActualDimensionOrganization         = "organization"
+	PlanVsActualDimensionOrganizationLocation = "organization_location"
+	PlanVsActualDimensionJobLevel             = "job_level"
+	PlanVsActualDimensionJob                  = "job"
+)
+
+var PlanVsActualDimensions = []string{
+	PlanVsActualDimensionMPPPeriod,
+	PlanVsActualDimensionOrganization,
+	PlanVsActualDimensionOrganizationLocation,
+	PlanVsActualDimensionJobLevel,
+	PlanVsActualDimensionJob,
+}
+
+// planVsActualColumn is how a dimension is read from the planning lines and
+// from the manpower requests. A request counts for the organization of the
+// planning it draws on, or the organization it is made for when it has none.
+type planVsActualColumn struct {
+	alias   string
+	planned string
+	actual  string
+}
+
+var planVsActualColumns = map[string]planVsActualColumn{
+	PlanVsActualDimensionMPPPeriod:            {alias: "mpp_period_id", planned: "h.mpp_period_id", actual: "r.mpp_period_id"},
+	PlanVsActualDimensionOrganization:         {alias: "organization_id", planned: "h.organization_id", actual: "COALESCE(h.organization_id, r.for_organization_id)"},
+	PlanVsActualDimensionOrganizationLocation: {alias: "organization_location_id", planned: "l.organization_location_id", actual: "r.for_organization_location_id"},
+	PlanVsActualDimensionJobLevel:             {alias: "job_level_id", planned: "l.job_level_id", actual: "r.job_level_id"},
+	PlanVsActualDimensionJob:                  {alias: "job_id", planned: "l.job_id", actual: "r.job_id"},
+}
+
+type PlanVsActualFilter struct {
+	MPPPeriodID            *uuid.UUID
+	OrganizationID         *uuid.UUID
+	OrganizationLocationID *uuid.UUID
+	JobLevelID             *uuid.UUID
+	JobID                  *uuid.UUID
+	GroupBy                []string
+}
+
+// PlanVsActualRow holds the sums of one group, nil ids for the dimensions not grouped by.
+type PlanVsActualRow struct {
+	MPPPeriodID            *uuid.UUID
+	OrganizationID         *uuid.UUID
+	OrganizationLocationID *uuid.UUID
+	JobLevelID             *uuid.UUID
+	JobID                  *uuid.UUID
+	Existing               int
+	Recruit                int
+	RecruitMT              int
+	RecruitPH              int
+	RequestedMT            int
+	RequestedPH            int
+	CompletedMT            int
+	CompletedPH            int
+}
+
+type IReportRepository interface {
+	SumPlanned(filter *PlanVsActualFilter) ([]PlanVsActualRow, error)
+	SumRequested(filter *PlanVsActualFilter) ([]PlanVsActualRow, error)
+}
+
+type ReportRepository struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewReportRepository(log *logrus.Logger, db *gorm.DB) IReportRepository {
+	return &ReportRepository{
+		Log: log,
+		DB:  db,
+	}
+}
+
+// SumPlanned sums the lines of approved and completed planning headers.
+func (r *ReportRepository) SumPlanned(filter *PlanVsActualFilter) ([]PlanVsActualRow, error) {
+	var rows []PlanVsActualRow
+
+	selects, groups := planVsActualGroups(filter.GroupBy, func(c planVsActualColumn) string { return c.planned })
+	selects = append(selects,
+		"COALESCE(SUM(l.existing), 0) AS existing",
+		"COALESCE(SUM(l.recruit), 0) AS recruit",
+		"COALESCE(SUM(l.recruit_mt), 0) AS recruit_mt",
+		"COALESCE(SUM(l.recruit_ph), 0) AS recruit_ph",
+	)
+
+	query := r.DB.Table("mp_planning_lines AS l").
+		Select(strings.Join(selects, ", ")).
+		Joins("JOIN mp_planning_headers AS h ON h.id = l.mp_planning_header_id AND h.deleted_at IS NULL").
+		Where("l.deleted_at IS NULL").
+		Where("h.status IN ?", []entity.MPPlaningStatus{entity.MPPlaningStatusApproved, entity.MPPlaningStatusComplete})
+	query = planVsActualWhere(query, filter, func(c planVsActualColumn) string { return c.planned })
+
+	if len(groups) > 0 {
+		query = query.Group(strings.Join(groups, ", "))
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		r.Log.Errorf("[ReportRepository.SumPlanned] " + err.Error())
+		return nil, errors.New("[ReportRepository.SumPlanned] " + err.Error())
+	}
+
+	return rows, nil
+}
+
+// SumRequested sums the needs of the requests past draft and of the completed ones, by MT and PH.
+func (r *ReportRepository) SumRequested(filter *PlanVsActualFilter) ([]PlanVsActualRow, error) {
+	var rows []PlanVsActualRow
+
+	selects, groups := planVsActualGroups(filter.GroupBy, func(c planVsActualColumn) string { return c.actual })
+	selects = append(selects,
+		"COALESCE(SUM(CASE WHEN r.recruitment_type = @mt THEN r.total_needs ELSE 0 END), 0) AS requested_mt",
+		"COALESCE(SUM(CASE WHEN r.recruitment_type <> @mt THEN r.total_needs ELSE 0 END), 0) AS requested_ph",
+		"COALESCE(SUM(CASE WHEN r.status = @completed AND r.recruitment_type = @mt THEN r.total_needs ELSE 0 END), 0) AS completed_mt",
+		"COALESCE(SUM(CASE WHEN r.status = @completed AND r.recruitment_type <> @mt THEN r.total_needs ELSE 0 END), 0) AS completed_ph",
+	)
+
+	query := r.DB.Table("mp_request_headers AS r").
+		Select(strings.Join(selects, ", "), map[string]interface{}{
+			"mt":        entity.RecruitmentTypeEnumMT,
+			"completed": entity.MPRequestStatusCompleted,
+		}).
+		Joins("LEFT JOIN mp_planning_headers AS h ON h.id = r.mp_planning_header_id AND h.deleted_at IS NULL").
+		Where("r.deleted_at IS NULL").
+		Where("r.status IN ?", []entity.MPRequestStatus{
+			entity.MPRequestStatusSubmitted,
+			entity.MPRequestStatusNeedApproval,
+			entity.MPRequestStatusApproved,
+			entity.MPRequestStatusInProgress,
+			entity.MPRequestStatusCompleted,
+		})
+	query = planVsActualWhere(query, filter, func(c planVsActualColumn) string { return c.actual })
+
+	if len(groups) > 0 {
+		query = query.Group(strings.Join(groups, ", "))
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		r.Log.Errorf("[ReportRepository.SumRequested] " + err.Error())
+		return nil, errors.New("[ReportRepository.SumRequested] " + err.Error())
+	}
+
+	return rows, nil
+}
+
+// planVsActualGroups selects and groups by the expression of each dimension,
+// aliased to its id column. Unknown dimensions are ignored.
+func planVsActualGroups(groupBy []string, expression func(planVsActualColumn) string) ([]string, []string) {
+	var selects, groups []string
+	for _, dimension := range PlanVsActualDimensions {
+		if !containsString(groupBy, dimension) {
+			continue
+		}
+		column := planVsActualColumns[dimension]
+		selects = append(selects, expression(column)+" AS "+column.alias)
+		groups = append(groups, expression(column))
+	}
+	return selects, groups
+}
+
+func planVsActualWhere(query *gorm.DB, filter *PlanVsActualFilter, expression func(planVsActualColumn) string) *gorm.DB {
+	filters := map[string]*uuid.UUID{
+		PlanVsActualDimensionMPPPeriod:            filter.MPPPeriodID,
+		PlanVsActualDimensionOrganization:         filter.OrganizationID,
+		PlanVsActualDimensionOrganizationLocation: filter.OrganizationLocationID,
+		PlanVsActualDimensionJobLevel:             filter.JobLevelID,
+		PlanVsActualDimensionJob:                  filter.JobID,
+	}
+	for _, dimension := range PlanVsActualDimensions {
+		if id := filters[dimension]; id != nil {
+			query = query.Where(expression(planVsActualColumns[dimension])+" = ?", *id)
+		}
+	}
+	return query
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func ReportRepositoryFactory(log *logrus.Logger) IReportRepository {
+	db := config.NewDatabase()
+	return NewReportRepository(log, db)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/config"
@@ -14,6 +15,7 @@ type IRequestMajorRepository interface {
 	Create(requestMajor *entity.RequestMajor) (*entity.RequestMajor, error)
 	Delete(id uuid.UUID) error
 	DeleteByMPRequestHeaderID(mpRequestHeaderID uuid.UUID) error
+	WithContext(ctx context.Context) IRequestMajorRepository
 }
 
 type RequestMajorRepository struct {
@@ -25,6 +27,14 @@ func NewRequestMajorRepository(log *logrus.Logger, db *gorm.DB) IRequestMajorRep
 	return &RequestMajorRepository{Log: log, DB: db}
 }
 
+// WithContext is the repository with its statements run under ctx.
+func (r *RequestMajorRepository) WithContext(ctx context.Context) IRequestMajorRepository {
+	return &RequestMajorRepository{
+		Log: r.Log,
+		DB:  r.DB.WithContext(ctx),
+	}
+}
+
 func (r *RequestMajorRepository) Create(requestMajor *entity.RequestMajor) (*entity.RequestMajor, error) {
 	tx := r.DB.Begin()
 
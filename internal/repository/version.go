@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AnyVersion claims a record at whatever version it is, for system and bulk changes.
+const AnyVersion = 0
+
+// claimVersion moves the record of model with id from version to the next
+// one, in tx before the change itself is written. When another change got
+// there first the record is no longer at version and workflow.ErrVersionConflict
+// is returned; the row stays locked until tx ends, so the change that claimed
+// it is the only one written.
+func claimVersion(tx *gorm.DB, model interface{}, id uuid.UUID, version int) error {
+	query := tx.Model(model).Where("id = ?", id)
+	if version != AnyVersion {
+		query = query.Where("version = ?", version)
+	}
+	result := query.UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return errors.New("[claimVersion] " + result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		var current struct{ Version int }
+		if err := tx.Model(model).Select("version").Where("id = ?", id).Take(&current).Error; err != nil {
+			return errors.New("[claimVersion] " + err.Error())
+		}
+		return workflow.CheckVersion(current.Version, version)
+	}
+	return nil
+}
+
+// lockEditablePlanning locks the planning header with id in tx and tells
+// whether its lines may be changed. The lock holds a status change back until
+// tx ends, so lines are never written into a planning that has moved on.
+func lockEditablePlanning(tx *gorm.DB, headerID uuid.UUID) error {
+	var mpPlanningHeader entity.MPPlanningHeader
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where("id = ?", headerID).First(&mpPlanningHeader).Error; err != nil {
+		return errors.New("[lockEditablePlanning] " + err.Error())
+	}
+	return workflow.CheckPlanningLinesEditable(mpPlanningHeader.Status)
+}
+
+// lockEditablePlanningsOfLines does lockEditablePlanning for the headers of
+// the lines with ids.
+func lockEditablePlanningsOfLines(tx *gorm.DB, ids []uuid.UUID) error {
+	var headerIDs []uuid.UUID
+	if err := tx.Model(&entity.MPPlanningLine{}).Where("id IN ?", ids).Distinct().Pluck("mp_planning_header_id", &headerIDs).Error; err != nil {
+		return errors.New("[lockEditablePlanningsOfLines] " + err.Error())
+	}
+	for _, headerID := range headerIDs {
+		if err := lockEditablePlanning(tx, headerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/google/uuid"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func mockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:                 logger.Discard,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+func TestClaimVersion(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name    string
+		version int
+		update  string
+		claimed int64
+		current int
+		wantErr error
+	}{
+		{"claimed", 3, "UPDATE `mp_request_headers` SET `version`=version + 1 WHERE id = ? AND version = ? AND `mp_request_headers`.`deleted_at` IS NULL", 1, 0, nil},
+		{"another change got there first", 3, "UPDATE `mp_request_headers` SET `version`=version + 1 WHERE id = ? AND version = ? AND `mp_request_headers`.`deleted_at` IS NULL", 0, 4, workflow.ErrVersionConflict},
+		{"any version", AnyVersion, "UPDATE `mp_request_headers` SET `version`=version + 1 WHERE id = ? AND `mp_request_headers`.`deleted_at` IS NULL", 1, 0, nil},
+	}
+
+	for _, tt := range tests {
+		db, mock := mockDB(t)
+		mock.MatchExpectationsInOrder(true)
+
+		update := mock.ExpectExec(regexp.QuoteMeta(tt.update))
+		if tt.version != AnyVersion {
+			update.WithArgs(id, tt.version)
+		} else {
+			update.WithArgs(id)
+		}
+		update.WillReturnResult(sqlmock.NewResult(0, tt.claimed))
+		if tt.claimed == 0 {
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT `version` FROM `mp_request_headers` WHERE id = ? AND `mp_request_headers`.`deleted_at` IS NULL LIMIT ?")).
+				WithArgs(id, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(tt.current))
+		}
+
+		err := claimVersion(db, &entity.MPRequestHeader{}, id, tt.version)
+		if tt.wantErr == nil && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestClaimVersionError(t *testing.T) {
+	db, mock := mockDB(t)
+	mock.ExpectExec("UPDATE").WillReturnError(errors.New("connection lost"))
+
+	err := claimVersion(db, &entity.MPRequestHeader{}, uuid.New(), 1)
+	if err == nil || errors.Is(err, workflow.ErrVersionConflict) {
+		t.Errorf("err = %v, want the database error", err)
+	}
+}
@@ -0,0 +1,147 @@
+package timezone
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Localize turns the timestamps in value into the business timezone, for
+// value to be written out by the API. A timestamp is a time.Time field whose
+// JSON name ends in "_at" (or whose Go name ends in "At" when it has no JSON
+// name); other times are dates, held at midnight UTC, and are left alone so
+// they keep their day. Values reached through pointers, slices and maps are
+// changed in place; a struct passed by value is copied.
+func Localize(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Struct {
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		v = copied
+	}
+
+	l := localizer{location: Location(), visited: make(map[uintptr]bool)}
+	l.walk(v)
+	return v.Interface()
+}
+
+type localizer struct {
+	location *time.Location
+	visited  map[uintptr]bool
+}
+
+func (l *localizer) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || l.visited[v.Pointer()] {
+			return
+		}
+		l.visited[v.Pointer()] = true
+		l.walk(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.Ptr {
+			l.walk(elem)
+			return
+		}
+		if !v.CanSet() || !hasTimestamps(elem.Type()) {
+			return
+		}
+		copied := reflect.New(elem.Type()).Elem()
+		copied.Set(elem)
+		l.walk(copied)
+		v.Set(copied)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldValue := v.Field(i)
+			if isTimestamp(field) && fieldValue.CanSet() {
+				l.localize(fieldValue)
+				continue
+			}
+			l.walk(fieldValue)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Array && !v.CanSet() {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			l.walk(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() || !hasTimestamps(v.Type().Elem()) {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := iter.Value()
+			if elem.Kind() == reflect.Ptr {
+				l.walk(elem)
+				continue
+			}
+			copied := reflect.New(elem.Type()).Elem()
+			copied.Set(elem)
+			l.walk(copied)
+			v.SetMapIndex(iter.Key(), copied)
+		}
+	}
+}
+
+// localize turns the time.Time or *time.Time in v into the business timezone.
+func (l *localizer) localize(v reflect.Value) {
+	switch {
+	case v.Type() == timeType:
+		if t := v.Interface().(time.Time); !t.IsZero() {
+			v.Set(reflect.ValueOf(t.In(l.location)))
+		}
+	case v.Kind() == reflect.Ptr && v.Type().Elem() == timeType:
+		if v.IsNil() {
+			return
+		}
+		if t := v.Elem().Interface().(time.Time); !t.IsZero() {
+			localized := t.In(l.location)
+			v.Set(reflect.ValueOf(&localized))
+		}
+	}
+}
+
+func isTimestamp(field reflect.StructField) bool {
+	if field.Type != timeType && (field.Type.Kind() != reflect.Ptr || field.Type.Elem() != timeType) {
+		return false
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return false
+	}
+	if name == "" {
+		return strings.HasSuffix(field.Name, "At")
+	}
+	return strings.HasSuffix(name, "_at")
+}
+
+// hasTimestamps tells whether values of t can hold a timestamp, so maps of
+// plain values are not copied for nothing.
+func hasTimestamps(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return t != timeType
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+)
+
+type localizeLine struct {
+	ApprovedAt *time.Time `json:"approved_at"`
+}
+
+type localizeDocument struct {
+	CreatedAt    time.Time               `json:"created_at"`
+	DocumentDate time.Time               `json:"document_date"`
+	UpdatedAt    time.Time               // no JSON name, read from the Go name
+	Hidden       time.Time               `json:"-"`
+	DeletedAt    *time.Time              `json:"deleted_at,omitempty"`
+	Lines        []localizeLine          `json:"lines"`
+	ByID         map[string]localizeLine `json:"by_id"`
+	Extra        interface{}             `json:"extra"`
+	Parent       *localizeDocument       `json:"parent"`
+	createdAt    time.Time
+}
+
+func configureJakarta(t *testing.T) *time.Location {
+	t.Helper()
+	if err := Configure("Asia/Jakarta"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Configure(DefaultName) })
+	return Location()
+}
+
+func TestLocalize(t *testing.T) {
+	jakarta := configureJakarta(t)
+	at := time.Date(2026, time.October, 17, 20, 0, 0, 0, time.UTC)
+	day := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	approvedAt := at
+
+	document := &localizeDocument{
+		CreatedAt:    at,
+		DocumentDate: day,
+		UpdatedAt:    at,
+		Hidden:       at,
+		Lines:        []localizeLine{{ApprovedAt: &approvedAt}, {}},
+		ByID:         map[string]localizeLine{"a": {ApprovedAt: &approvedAt}},
+		Extra:        localizeLine{ApprovedAt: &approvedAt},
+		createdAt:    at,
+	}
+	// a cycle is walked once
+	document.Parent = document
+
+	if got := Localize(document); got != document {
+		t.Fatalf("a pointer is localized in place, got %v", got)
+	}
+
+	if document.CreatedAt.Location() != jakarta || !document.CreatedAt.Equal(at) {
+		t.Errorf("created_at = %v", document.CreatedAt)
+	}
+	if document.CreatedAt.Day() != 18 {
+		t.Errorf("created_at is on day %d in Jakarta, want 18", document.CreatedAt.Day())
+	}
+	if document.UpdatedAt.Location() != jakarta {
+		t.Errorf("UpdatedAt without a JSON name = %v", document.UpdatedAt)
+	}
+	if document.DocumentDate.Location() != time.UTC {
+		t.Errorf("a date is left alone, got %v", document.DocumentDate)
+	}
+	if document.Hidden.Location() != time.UTC {
+		t.Errorf("a field left out of JSON is left alone, got %v", document.Hidden)
+	}
+	if document.createdAt.Location() != time.UTC {
+		t.Errorf("an unexported field is left alone, got %v", document.createdAt)
+	}
+	if document.DeletedAt != nil {
+		t.Errorf("a nil timestamp stays nil, got %v", document.DeletedAt)
+	}
+	if document.Lines[0].ApprovedAt.Location() != jakarta || document.Lines[1].ApprovedAt != nil {
+		t.Errorf("lines = %+v", document.Lines)
+	}
+	if document.ByID["a"].ApprovedAt.Location() != jakarta {
+		t.Errorf("map values = %+v", document.ByID)
+	}
+	if extra := document.Extra.(localizeLine); extra.ApprovedAt.Location() != jakarta {
+		t.Errorf("interface value = %+v", extra)
+	}
+	if approvedAt.Location() != time.UTC {
+		t.Errorf("the time a pointer points at is not changed, got %v", approvedAt)
+	}
+}
+
+func TestLocalizeStructByValue(t *testing.T) {
+	jakarta := configureJakarta(t)
+	at := time.Date(2026, time.October, 17, 20, 0, 0, 0, time.UTC)
+
+	document := localizeDocument{CreatedAt: at}
+	localized := Localize(document).(localizeDocument)
+
+	if localized.CreatedAt.Location() != jakarta {
+		t.Errorf("copy created_at = %v", localized.CreatedAt)
+	}
+	if document.CreatedAt.Location() != time.UTC {
+		t.Errorf("original created_at = %v, want it untouched", document.CreatedAt)
+	}
+}
+
+func TestLocalizeLeavesZeroAndPlainValues(t *testing.T) {
+	configureJakarta(t)
+
+	if Localize(nil) != nil {
+		t.Error("Localize(nil) is not nil")
+	}
+
+	document := &localizeDocument{}
+	Localize(document)
+	if !document.CreatedAt.IsZero() || document.CreatedAt.Location() != time.UTC {
+		t.Errorf("zero created_at = %v", document.CreatedAt)
+	}
+
+	values := map[string]int{"a": 1}
+	if got := Localize(values).(map[string]int); got["a"] != 1 {
+		t.Errorf("plain map = %v", got)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { Configure(DefaultName) })
+
+	if err := Configure("Mars/Olympus_Mons"); err == nil {
+		t.Error("an unknown timezone was accepted")
+	}
+	if err := Configure(""); err != nil || Location().String() != DefaultName {
+		t.Errorf("empty name = %v, %v, want %s", Location(), err, DefaultName)
+	}
+
+	if err := Configure("Asia/Makassar"); err != nil {
+		t.Fatal(err)
+	}
+	// 17:00 UTC is already the next day in Makassar, UTC+8
+	if got := Date(time.Date(2026, time.October, 17, 17, 0, 0, 0, time.UTC)); got != "2026-10-18" {
+		t.Errorf("Date = %s, want 2026-10-18", got)
+	}
+
+	today := TodayDate()
+	if today.Location() != time.UTC || today.Hour() != 0 || today.Format(DateLayout) != Today() {
+		t.Errorf("TodayDate = %v, Today = %s", today, Today())
+	}
+}
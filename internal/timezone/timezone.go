@@ -0,0 +1,78 @@
+// Package timezone keeps the timezone of the business. Times are stored in
+// UTC and only turned into the business timezone where they meet people: in
+// the responses of the API, in the dates compared with "today" and in the
+// schedule of the jobs.
+package timezone
+
+import (
+	"sync"
+	"time"
+
+	// the zone database is embedded so the timezone loads on hosts without one
+	_ "time/tzdata"
+)
+
+// DefaultName is the timezone used when app.timezone is not set.
+const DefaultName = "Asia/Jakarta"
+
+// DateLayout is how dates without a time are written.
+const DateLayout = "2006-01-02"
+
+var (
+	mu       sync.RWMutex
+	location = mustLoad(DefaultName)
+)
+
+// Configure sets the business timezone from its IANA name, the default when
+// name is empty.
+func Configure(name string) error {
+	if name == "" {
+		name = DefaultName
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	location = loc
+	mu.Unlock()
+	return nil
+}
+
+// Location is the business timezone.
+func Location() *time.Location {
+	mu.RLock()
+	defer mu.RUnlock()
+	return location
+}
+
+// Now is the current time in the business timezone.
+func Now() time.Time {
+	return time.Now().In(Location())
+}
+
+// Today is the current date in the business timezone, as DateLayout.
+func Today() string {
+	return Now().Format(DateLayout)
+}
+
+// Date is the date of t in the business timezone, as DateLayout.
+func Date(t time.Time) string {
+	return t.In(Location()).Format(DateLayout)
+}
+
+// TodayDate is the current date in the business timezone at midnight UTC,
+// the way dates without a time are held, like the ones parsed from requests.
+func TodayDate() time.Time {
+	now := Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func mustLoad(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
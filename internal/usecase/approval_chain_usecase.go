@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type IApprovalChainUseCase interface {
+	FindAllPaginated(req *request.FindAllPaginatedApprovalChainRequest) (*response.FindAllPaginatedApprovalChainResponse, error)
+	FindById(req *request.FindByIdApprovalChainRequest) (*response.ApprovalChainResponse, error)
+	Create(req *request.CreateApprovalChainRequest) (*response.ApprovalChainResponse, error)
+	Update(req *request.UpdateApprovalChainRequest) (*response.ApprovalChainResponse, error)
+	Delete(req *request.DeleteApprovalChainRequest) error
+	WithContext(ctx context.Context) IApprovalChainUseCase
+}
+
+type ApprovalChainUseCase struct {
+	boundContext
+	Log                     *logrus.Logger
+	ApprovalChainRepository repository.IApprovalChainRepository
+	OrganizationMessage     messaging.IOrganizationMessage
+	ApprovalChainDTO        dto.IApprovalChainDTO
+}
+
+func NewApprovalChainUseCase(log *logrus.Logger, repo repository.IApprovalChainRepository, orgMessage messaging.IOrganizationMessage, approvalChainDTO dto.IApprovalChainDTO) IApprovalChainUseCase {
+	return &ApprovalChainUseCase{
+		Log:                     log,
+		ApprovalChainRepository: repo,
+		OrganizationMessage:     orgMessage,
+		ApprovalChainDTO:        approvalChainDTO,
+	}
+}
+
+// WithContext is the use case bound to ctx, so the messages it sends to the
+// portal are cancelled with the request.
+func (uc *ApprovalChainUseCase) WithContext(ctx context.Context) IApprovalChainUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	return &scoped
+}
+
+func (uc *ApprovalChainUseCase) FindAllPaginated(req *request.FindAllPaginatedApprovalChainRequest) (*response.FindAllPaginatedApprovalChainResponse, error) {
+	filter := make(map[string]interface{})
+	if req.OrganizationID != "" {
+		filter["organization_id"] = req.OrganizationID
+	}
+	if req.DocumentType != "" {
+		filter["document_type"] = req.DocumentType
+	}
+
+	approvalChains, total, err := uc.ApprovalChainRepository.FindAllPaginated(req.Page, req.PageSize, req.Search, filter)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.FindAllPaginated] " + err.Error())
+		return nil, err
+	}
+
+	approvalChainResponses := make([]response.ApprovalChainResponse, 0, len(*approvalChains))
+	for _, approvalChain := range *approvalChains {
+		if err := uc.fillOrganizationName(&approvalChain); err != nil {
+			uc.Log.Errorf("[ApprovalChainUseCase.FindAllPaginated] " + err.Error())
+			return nil, err
+		}
+		approvalChainResponses = append(approvalChainResponses, *uc.ApprovalChainDTO.ConvertApprovalChainEntityToResponse(&approvalChain))
+	}
+
+	return &response.FindAllPaginatedApprovalChainResponse{
+		ApprovalChains: approvalChainResponses,
+		Total:          total,
+	}, nil
+}
+
+func (uc *ApprovalChainUseCase) FindById(req *request.FindByIdApprovalChainRequest) (*response.ApprovalChainResponse, error) {
+	approvalChain, err := uc.ApprovalChainRepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.FindById] " + err.Error())
+		return nil, err
+	}
+
+	if approvalChain == nil {
+		return nil, errors.New("Approval chain not found")
+	}
+
+	if err := uc.fillOrganizationName(approvalChain); err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.FindById] " + err.Error())
+		return nil, err
+	}
+
+	return uc.ApprovalChainDTO.ConvertApprovalChainEntityToResponse(approvalChain), nil
+}
+
+func (uc *ApprovalChainUseCase) Create(req *request.CreateApprovalChainRequest) (*response.ApprovalChainResponse, error) {
+	steps, err := uc.buildSteps(req.DocumentType, req.Steps)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	orgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
+		ID: req.OrganizationID.String(),
+	})
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	if orgExist == nil {
+		return nil, errors.New("Organization not found")
+	}
+
+	approvalChain, err := uc.ApprovalChainRepository.Create(&entity.ApprovalChain{
+		OrganizationID:     &req.OrganizationID,
+		DocumentType:       req.DocumentType,
+		Name:               req.Name,
+		IsActive:           req.IsActive == nil || *req.IsActive,
+		ApprovalChainSteps: steps,
+	})
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	approvalChain.OrganizationName = orgExist.Name
+
+	return uc.ApprovalChainDTO.ConvertApprovalChainEntityToResponse(approvalChain), nil
+}
+
+func (uc *ApprovalChainUseCase) Update(req *request.UpdateApprovalChainRequest) (*response.ApprovalChainResponse, error) {
+	exist, err := uc.ApprovalChainRepository.FindById(req.ID)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	if exist == nil {
+		return nil, errors.New("Approval chain not found")
+	}
+
+	steps, err := uc.buildSteps(req.DocumentType, req.Steps)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	orgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
+		ID: req.OrganizationID.String(),
+	})
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	if orgExist == nil {
+		return nil, errors.New("Organization not found")
+	}
+
+	approvalChain, err := uc.ApprovalChainRepository.Update(&entity.ApprovalChain{
+		ID:                 req.ID,
+		OrganizationID:     &req.OrganizationID,
+		DocumentType:       req.DocumentType,
+		Name:               req.Name,
+		IsActive:           req.IsActive == nil || *req.IsActive,
+		ApprovalChainSteps: steps,
+	})
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	approvalChain.OrganizationName = orgExist.Name
+
+	return uc.ApprovalChainDTO.ConvertApprovalChainEntityToResponse(approvalChain), nil
+}
+
+func (uc *ApprovalChainUseCase) Delete(req *request.DeleteApprovalChainRequest) error {
+	exist, err := uc.ApprovalChainRepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[ApprovalChainUseCase.Delete] " + err.Error())
+		return err
+	}
+
+	if exist == nil {
+		return errors.New("Approval chain not found")
+	}
+
+	return uc.ApprovalChainRepository.Delete(exist.ID)
+}
+
+func (uc *ApprovalChainUseCase) buildSteps(documentType entity.ApprovalChainDocumentType, reqSteps []request.ApprovalChainStepRequest) ([]entity.ApprovalChainStep, error) {
+	validLevels := approvalChainLevels(documentType)
+	sequences := make(map[int]bool)
+	steps := make([]entity.ApprovalChainStep, 0, len(reqSteps))
+
+	for _, reqStep := range reqSteps {
+		if !validLevels[reqStep.Level] {
+			return nil, fmt.Errorf("Level %s is not valid for document type %s", reqStep.Level, documentType)
+		}
+
+		if sequences[reqStep.Sequence] {
+			return nil, fmt.Errorf("Sequence %d is used more than once", reqStep.Sequence)
+		}
+		sequences[reqStep.Sequence] = true
+
+		condition := reqStep.Condition
+		if condition == "" {
+			condition = entity.ApprovalChainStepConditionAll
+		}
+
+		steps = append(steps, entity.ApprovalChainStep{
+			Sequence:     reqStep.Sequence,
+			Level:        reqStep.Level,
+			ApproverID:   reqStep.ApproverID,
+			ApproverName: reqStep.ApproverName,
+			Condition:    condition,
+		})
+	}
+
+	return steps, nil
+}
+
+func (uc *ApprovalChainUseCase) fillOrganizationName(approvalChain *entity.ApprovalChain) error {
+	if approvalChain.OrganizationID == nil {
+		return nil
+	}
+
+	orgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
+		ID: approvalChain.OrganizationID.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if orgExist != nil {
+		approvalChain.OrganizationName = orgExist.Name
+	}
+
+	return nil
+}
+
+func approvalChainLevels(documentType entity.ApprovalChainDocumentType) map[string]bool {
+	switch documentType {
+	case entity.ApprovalChainDocumentTypeMPPlanning:
+		return map[string]bool{
+			string(entity.MPPlanningApprovalHistoryLevelHRDUnit):      true,
+			string(entity.MPPlanningApprovalHistoryLevelDirekturUnit): true,
+			string(entity.MPPlanningApprovalHistoryLevelRecruitment):  true,
+			string(entity.MPPlanningApprovalHistoryLevelCEO):          true,
+		}
+	case entity.ApprovalChainDocumentTypeMPRequest:
+		return map[string]bool{
+			string(entity.MPRequestApprovalHistoryLevelStaff):    true,
+			string(entity.MPRequestApprovalHistoryLevelHeadDept): true,
+			string(entity.MPRequestApprovalHistoryLevelVP):       true,
+			string(entity.MPRequestApprovalHistoryLevelCEO):      true,
+			string(entity.MPPRequestApprovalHistoryLevelHRDHO):   true,
+		}
+	case entity.ApprovalChainDocumentTypeBatch:
+		return map[string]bool{
+			string(entity.BatchHeaderApproverTypeDirector): true,
+			string(entity.BatchHeaderApproverTypeCEO):      true,
+		}
+	default:
+		return map[string]bool{}
+	}
+}
+
+func ApprovalChainUseCaseFactory(log *logrus.Logger) IApprovalChainUseCase {
+	repo := repository.ApprovalChainRepositoryFactory(log)
+	orgMessage := messaging.OrganizationMessageFactory(log)
+	approvalChainDTO := dto.ApprovalChainDTOFactory(log)
+	return NewApprovalChainUseCase(log, repo, orgMessage, approvalChainDTO)
+}
@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type IApprovalDelegationUseCase interface {
+	FindAllPaginated(req *request.FindAllPaginatedApprovalDelegationRequest) (*response.FindAllPaginatedApprovalDelegationResponse, error)
+	FindById(req *request.FindByIdApprovalDelegationRequest) (*response.ApprovalDelegationResponse, error)
+	Create(req *request.CreateApprovalDelegationRequest) (*response.ApprovalDelegationResponse, error)
+	Update(req *request.UpdateApprovalDelegationRequest) (*response.ApprovalDelegationResponse, error)
+	Delete(req *request.DeleteApprovalDelegationRequest) error
+	WithContext(ctx context.Context) IApprovalDelegationUseCase
+}
+
+type ApprovalDelegationUseCase struct {
+	boundContext
+	Log                          *logrus.Logger
+	ApprovalDelegationRepository repository.IApprovalDelegationRepository
+	EmployeeMessage              messaging.IEmployeeMessage
+	ApprovalDelegationDTO        dto.IApprovalDelegationDTO
+}
+
+func NewApprovalDelegationUseCase(log *logrus.Logger, repo repository.IApprovalDelegationRepository, employeeMessage messaging.IEmployeeMessage, approvalDelegationDTO dto.IApprovalDelegationDTO) IApprovalDelegationUseCase {
+	return &ApprovalDelegationUseCase{
+		Log:                          log,
+		ApprovalDelegationRepository: repo,
+		EmployeeMessage:              employeeMessage,
+		ApprovalDelegationDTO:        approvalDelegationDTO,
+	}
+}
+
+// WithContext is the use case bound to ctx, so the messages it sends to the
+// portal are cancelled with the request.
+func (uc *ApprovalDelegationUseCase) WithContext(ctx context.Context) IApprovalDelegationUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	return &scoped
+}
+
+func (uc *ApprovalDelegationUseCase) FindAllPaginated(req *request.FindAllPaginatedApprovalDelegationRequest) (*response.FindAllPaginatedApprovalDelegationResponse, error) {
+	filter := make(map[string]interface{})
+	if req.DelegatorID != "" {
+		filter["delegator_id"] = req.DelegatorID
+	}
+	if req.DelegateID != "" {
+		filter["delegate_id"] = req.DelegateID
+	}
+	if req.Scope != "" {
+		filter["scope"] = req.Scope
+	}
+	if req.ActiveOn != "" {
+		filter["active_on"] = req.ActiveOn
+	}
+
+	delegations, total, err := uc.ApprovalDelegationRepository.FindAllPaginated(req.Page, req.PageSize, req.Search, filter)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.FindAllPaginated] " + err.Error())
+		return nil, err
+	}
+
+	delegationResponses := make([]response.ApprovalDelegationResponse, 0, len(*delegations))
+	for _, delegation := range *delegations {
+		delegationResponses = append(delegationResponses, *uc.ApprovalDelegationDTO.ConvertApprovalDelegationEntityToResponse(&delegation))
+	}
+
+	return &response.FindAllPaginatedApprovalDelegationResponse{
+		ApprovalDelegations: delegationResponses,
+		Total:               total,
+	}, nil
+}
+
+func (uc *ApprovalDelegationUseCase) FindById(req *request.FindByIdApprovalDelegationRequest) (*response.ApprovalDelegationResponse, error) {
+	delegation, err := uc.ApprovalDelegationRepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.FindById] " + err.Error())
+		return nil, err
+	}
+
+	if delegation == nil {
+		return nil, errors.New("Approval delegation not found")
+	}
+
+	return uc.ApprovalDelegationDTO.ConvertApprovalDelegationEntityToResponse(delegation), nil
+}
+
+func (uc *ApprovalDelegationUseCase) Create(req *request.CreateApprovalDelegationRequest) (*response.ApprovalDelegationResponse, error) {
+	if req.DelegatorID == uuid.Nil {
+		req.DelegatorID = req.Actor.EmployeeID
+	}
+	if err := checkDelegator(req.Actor, &req.DelegatorID); err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	delegation, err := uc.buildDelegation(uuid.Nil, req.DelegatorID, req.DelegateID, req.Scope, req.StartDate, req.EndDate, req.Reason)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	created, err := uc.ApprovalDelegationRepository.Create(delegation)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	return uc.ApprovalDelegationDTO.ConvertApprovalDelegationEntityToResponse(created), nil
+}
+
+func (uc *ApprovalDelegationUseCase) Update(req *request.UpdateApprovalDelegationRequest) (*response.ApprovalDelegationResponse, error) {
+	exist, err := uc.ApprovalDelegationRepository.FindById(req.ID)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	if exist == nil {
+		return nil, errors.New("Approval delegation not found")
+	}
+
+	if req.DelegatorID == uuid.Nil && exist.DelegatorID != nil {
+		req.DelegatorID = *exist.DelegatorID
+	}
+	if err := checkDelegator(req.Actor, exist.DelegatorID); err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Update] " + err.Error())
+		return nil, err
+	}
+	if err := checkDelegator(req.Actor, &req.DelegatorID); err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	delegation, err := uc.buildDelegation(req.ID, req.DelegatorID, req.DelegateID, req.Scope, req.StartDate, req.EndDate, req.Reason)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	updated, err := uc.ApprovalDelegationRepository.Update(delegation)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	return uc.ApprovalDelegationDTO.ConvertApprovalDelegationEntityToResponse(updated), nil
+}
+
+func (uc *ApprovalDelegationUseCase) Delete(req *request.DeleteApprovalDelegationRequest) error {
+	exist, err := uc.ApprovalDelegationRepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Delete] " + err.Error())
+		return err
+	}
+
+	if exist == nil {
+		return errors.New("Approval delegation not found")
+	}
+
+	if err := checkDelegator(req.Actor, exist.DelegatorID); err != nil {
+		uc.Log.Errorf("[ApprovalDelegationUseCase.Delete] " + err.Error())
+		return err
+	}
+
+	return uc.ApprovalDelegationRepository.Delete(exist.ID)
+}
+
+func (uc *ApprovalDelegationUseCase) buildDelegation(id uuid.UUID, delegatorID uuid.UUID, delegateID uuid.UUID, scope entity.ApprovalDelegationScope, startDate string, endDate string, reason string) (*entity.ApprovalDelegation, error) {
+	if delegatorID == delegateID {
+		return nil, errors.New("Delegator and delegate cannot be the same employee")
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if end.Before(start) {
+		return nil, errors.New("End date cannot be before start date")
+	}
+
+	if scope == "" {
+		scope = entity.ApprovalDelegationScopeAll
+	}
+
+	delegator, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
+		ID: delegatorID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if delegator == nil {
+		return nil, errors.New("Delegator not found")
+	}
+
+	delegate, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
+		ID: delegateID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if delegate == nil {
+		return nil, errors.New("Delegate not found")
+	}
+
+	delegation := &entity.ApprovalDelegation{
+		ID:            id,
+		DelegatorID:   &delegatorID,
+		DelegatorName: delegator.Name,
+		DelegateID:    &delegateID,
+		DelegateName:  delegate.Name,
+		Scope:         scope,
+		StartDate:     start,
+		EndDate:       end,
+		Reason:        reason,
+	}
+
+	overlapping, err := uc.ApprovalDelegationRepository.CountOverlapping(delegation)
+	if err != nil {
+		return nil, err
+	}
+	if overlapping > 0 {
+		return nil, errors.New("Delegator already has a delegation in this date range")
+	}
+
+	return delegation, nil
+}
+
+// checkDelegator tells whether the actor may manage the delegations of
+// delegatorID: their own, or anyone's with ManageAll.
+func checkDelegator(actor request.DelegationActor, delegatorID *uuid.UUID) error {
+	if actor.ManageAll {
+		return nil
+	}
+	if delegatorID == nil || actor.EmployeeID == uuid.Nil || *delegatorID != actor.EmployeeID {
+		return workflow.ErrNotDelegator
+	}
+	return nil
+}
+
+// resolveApprovalDelegation finds the delegation an approver acts under. An
+// explicit onBehalfOfID must be backed by an active delegation; otherwise the
+// pending approver of the approval chain is tried, so a delegate can approve
+// without naming the person they stand in for.
+func resolveApprovalDelegation(repo repository.IApprovalDelegationRepository, scope entity.ApprovalDelegationScope, approverID uuid.UUID, onBehalfOfID *uuid.UUID, nextApproverID *uuid.UUID) (*entity.ApprovalDelegation, error) {
+	delegatorID := onBehalfOfID
+	if delegatorID == nil {
+		delegatorID = nextApproverID
+	}
+
+	if delegatorID == nil || *delegatorID == approverID {
+		return nil, nil
+	}
+
+	delegation, err := repo.FindActive(*delegatorID, approverID, scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if delegation == nil && onBehalfOfID != nil {
+		return nil, workflow.ErrInvalidDelegation
+	}
+
+	return delegation, nil
+}
+
+func onBehalfOfID(delegation *entity.ApprovalDelegation) *uuid.UUID {
+	if delegation == nil {
+		return nil
+	}
+	return delegation.DelegatorID
+}
+
+func onBehalfOfName(delegation *entity.ApprovalDelegation) string {
+	if delegation == nil {
+		return ""
+	}
+	return delegation.DelegatorName
+}
+
+func ApprovalDelegationUseCaseFactory(log *logrus.Logger) IApprovalDelegationUseCase {
+	repo := repository.ApprovalDelegationRepositoryFactory(log)
+	employeeMessage := messaging.EmployeeMessageFactory(log)
+	approvalDelegationDTO := dto.ApprovalDelegationDTOFactory(log)
+	return NewApprovalDelegationUseCase(log, repo, employeeMessage, approvalDelegationDTO)
+}
@@ -0,0 +1,584 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/service"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IApprovalSLAUseCase interface {
+	FindAllPaginated(req *request.FindAllPaginatedApprovalSLARequest) (*response.FindAllPaginatedApprovalSLAResponse, error)
+	FindById(req *request.FindByIdApprovalSLARequest) (*response.ApprovalSLAResponse, error)
+	Create(req *request.CreateApprovalSLARequest) (*response.ApprovalSLAResponse, error)
+	Update(req *request.UpdateApprovalSLARequest) (*response.ApprovalSLAResponse, error)
+	Delete(req *request.DeleteApprovalSLARequest) error
+	ProcessOverdueApprovals(now time.Time) error
+	WithContext(ctx context.Context) IApprovalSLAUseCase
+}
+
+type ApprovalSLAUseCase struct {
+	boundContext
+	Log                     *logrus.Logger
+	ApprovalSLARepository   repository.IApprovalSLARepository
+	ApprovalChainRepository repository.IApprovalChainRepository
+	MPPlanningRepository    repository.IMPPlanningRepository
+	MPRequestRepository     repository.IMPRequestRepository
+	BatchRepository         repository.IBatchRepository
+	EmployeeMessage         messaging.IEmployeeMessage
+	NotificationService     service.INotificationService
+	ApprovalSLADTO          dto.IApprovalSLADTO
+}
+
+func NewApprovalSLAUseCase(
+	log *logrus.Logger,
+	approvalSLARepository repository.IApprovalSLARepository,
+	approvalChainRepository repository.IApprovalChainRepository,
+	mpPlanningRepository repository.IMPPlanningRepository,
+	mpRequestRepository repository.IMPRequestRepository,
+	batchRepository repository.IBatchRepository,
+	employeeMessage messaging.IEmployeeMessage,
+	notificationService service.INotificationService,
+	approvalSLADTO dto.IApprovalSLADTO,
+) IApprovalSLAUseCase {
+	return &ApprovalSLAUseCase{
+		Log:                     log,
+		ApprovalSLARepository:   approvalSLARepository,
+		ApprovalChainRepository: approvalChainRepository,
+		MPPlanningRepository:    mpPlanningRepository,
+		MPRequestRepository:     mpRequestRepository,
+		BatchRepository:         batchRepository,
+		EmployeeMessage:         employeeMessage,
+		NotificationService:     notificationService,
+		ApprovalSLADTO:          approvalSLADTO,
+	}
+}
+
+// WithContext is the use case bound to ctx, so the messages it sends to the
+// portal are cancelled with the request.
+func (uc *ApprovalSLAUseCase) WithContext(ctx context.Context) IApprovalSLAUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	return &scoped
+}
+
+func (uc *ApprovalSLAUseCase) FindAllPaginated(req *request.FindAllPaginatedApprovalSLARequest) (*response.FindAllPaginatedApprovalSLAResponse, error) {
+	filter := make(map[string]interface{})
+	if req.DocumentType != "" {
+		filter["document_type"] = req.DocumentType
+	}
+	if req.Level != "" {
+		filter["level"] = req.Level
+	}
+
+	approvalSLAs, total, err := uc.ApprovalSLARepository.FindAllPaginated(req.Page, req.PageSize, filter)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.FindAllPaginated] " + err.Error())
+		return nil, err
+	}
+
+	approvalSLAResponses := make([]response.ApprovalSLAResponse, 0, len(*approvalSLAs))
+	for _, approvalSLA := range *approvalSLAs {
+		approvalSLAResponses = append(approvalSLAResponses, *uc.ApprovalSLADTO.ConvertApprovalSLAEntityToResponse(&approvalSLA))
+	}
+
+	return &response.FindAllPaginatedApprovalSLAResponse{
+		ApprovalSLAs: approvalSLAResponses,
+		Total:        total,
+	}, nil
+}
+
+func (uc *ApprovalSLAUseCase) FindById(req *request.FindByIdApprovalSLARequest) (*response.ApprovalSLAResponse, error) {
+	approvalSLA, err := uc.ApprovalSLARepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.FindById] " + err.Error())
+		return nil, err
+	}
+
+	if approvalSLA == nil {
+		return nil, errors.New("Approval SLA not found")
+	}
+
+	return uc.ApprovalSLADTO.ConvertApprovalSLAEntityToResponse(approvalSLA), nil
+}
+
+func (uc *ApprovalSLAUseCase) Create(req *request.CreateApprovalSLARequest) (*response.ApprovalSLAResponse, error) {
+	approvalSLA, err := uc.buildApprovalSLA(uuid.Nil, req.DocumentType, req.Level, req.ReminderAfterDays, req.EscalateAfterDays, req.EscalateToLevel, req.BackupApproverID, req.PermissionName, req.IsActive)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	created, err := uc.ApprovalSLARepository.Create(approvalSLA)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	return uc.ApprovalSLADTO.ConvertApprovalSLAEntityToResponse(created), nil
+}
+
+func (uc *ApprovalSLAUseCase) Update(req *request.UpdateApprovalSLARequest) (*response.ApprovalSLAResponse, error) {
+	exist, err := uc.ApprovalSLARepository.FindById(req.ID)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	if exist == nil {
+		return nil, errors.New("Approval SLA not found")
+	}
+
+	approvalSLA, err := uc.buildApprovalSLA(req.ID, req.DocumentType, req.Level, req.ReminderAfterDays, req.EscalateAfterDays, req.EscalateToLevel, req.BackupApproverID, req.PermissionName, req.IsActive)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	updated, err := uc.ApprovalSLARepository.Update(approvalSLA)
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	return uc.ApprovalSLADTO.ConvertApprovalSLAEntityToResponse(updated), nil
+}
+
+func (uc *ApprovalSLAUseCase) Delete(req *request.DeleteApprovalSLARequest) error {
+	exist, err := uc.ApprovalSLARepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.Delete] " + err.Error())
+		return err
+	}
+
+	if exist == nil {
+		return errors.New("Approval SLA not found")
+	}
+
+	return uc.ApprovalSLARepository.Delete(exist.ID)
+}
+
+func (uc *ApprovalSLAUseCase) buildApprovalSLA(id uuid.UUID, documentType entity.ApprovalChainDocumentType, level string, reminderAfterDays int, escalateAfterDays int, escalateToLevel string, backupApproverID *uuid.UUID, permissionName string, isActive *bool) (*entity.ApprovalSLA, error) {
+	validLevels := approvalChainLevels(documentType)
+	if level != "" && !validLevels[level] {
+		return nil, fmt.Errorf("Level %s is not valid for document type %s", level, documentType)
+	}
+	if escalateToLevel != "" && !validLevels[escalateToLevel] {
+		return nil, fmt.Errorf("Level %s is not valid for document type %s", escalateToLevel, documentType)
+	}
+
+	exist, err := uc.ApprovalSLARepository.FindByDocumentTypeAndLevel(documentType, level)
+	if err != nil {
+		return nil, err
+	}
+	if exist != nil && exist.ID != id {
+		return nil, errors.New("Approval SLA for this document type and level already exists")
+	}
+
+	approvalSLA := &entity.ApprovalSLA{
+		ID:                id,
+		DocumentType:      documentType,
+		Level:             level,
+		ReminderAfterDays: reminderAfterDays,
+		EscalateAfterDays: escalateAfterDays,
+		EscalateToLevel:   escalateToLevel,
+		BackupApproverID:  backupApproverID,
+		PermissionName:    permissionName,
+		IsActive:          isActive == nil || *isActive,
+	}
+
+	if backupApproverID != nil {
+		backupApprover, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
+			ID: backupApproverID.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if backupApprover == nil {
+			return nil, errors.New("Backup approver not found")
+		}
+		approvalSLA.BackupApproverName = backupApprover.Name
+	}
+
+	return approvalSLA, nil
+}
+
+// ProcessOverdueApprovals sends a reminder for every document that passed the
+// reminder deadline of its approval level and escalates the ones that passed
+// the escalation deadline. A failing document is logged and skipped so it
+// does not hold up the rest of the run.
+func (uc *ApprovalSLAUseCase) ProcessOverdueApprovals(now time.Time) error {
+	approvalSLAs, err := uc.ApprovalSLARepository.FindAllActive()
+	if err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.ProcessOverdueApprovals] " + err.Error())
+		return err
+	}
+
+	if len(*approvalSLAs) == 0 {
+		return nil
+	}
+
+	policies := make(map[entity.ApprovalChainDocumentType]map[string]*entity.ApprovalSLA)
+	for i := range *approvalSLAs {
+		approvalSLA := &(*approvalSLAs)[i]
+		if policies[approvalSLA.DocumentType] == nil {
+			policies[approvalSLA.DocumentType] = make(map[string]*entity.ApprovalSLA)
+		}
+		policies[approvalSLA.DocumentType][approvalSLA.Level] = approvalSLA
+	}
+
+	if err := uc.processMPPlanningApprovals(now, policies[entity.ApprovalChainDocumentTypeMPPlanning]); err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.ProcessOverdueApprovals] " + err.Error())
+	}
+
+	if err := uc.processMPRequestApprovals(now, policies[entity.ApprovalChainDocumentTypeMPRequest]); err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.ProcessOverdueApprovals] " + err.Error())
+	}
+
+	if err := uc.processBatchApprovals(now, policies[entity.ApprovalChainDocumentTypeBatch]); err != nil {
+		uc.Log.Errorf("[ApprovalSLAUseCase.ProcessOverdueApprovals] " + err.Error())
+	}
+
+	return nil
+}
+
+func (uc *ApprovalSLAUseCase) processMPPlanningApprovals(now time.Time, policies map[string]*entity.ApprovalSLA) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	headers, err := uc.MPPlanningRepository.FindAwaitingApproval()
+	if err != nil {
+		return err
+	}
+
+	for _, header := range *headers {
+		approvalSLA := findApprovalSLA(policies, header.NextApproverLevel)
+		if approvalSLA == nil {
+			continue
+		}
+
+		workingDays := workingDaysBetween(*header.ApprovalRequestedAt, now)
+
+		if workingDays >= approvalSLA.EscalateAfterDays {
+			var approvalChain *entity.ApprovalChain
+			if header.OrganizationID != nil {
+				approvalChain, err = uc.ApprovalChainRepository.FindActiveByOrganizationIDAndDocumentType(*header.OrganizationID, entity.ApprovalChainDocumentTypeMPPlanning)
+				if err != nil {
+					uc.Log.Errorf("[ApprovalSLAUseCase.processMPPlanningApprovals] " + err.Error())
+					continue
+				}
+			}
+
+			approverID, approverName, level, ok := escalationTarget(approvalSLA, approvalChain, header.NextApproverLevel, header.NextApproverID, "")
+			if !ok {
+				uc.Log.Infof("[ApprovalSLAUseCase.processMPPlanningApprovals] no escalation target for mp planning header %s", header.ID)
+				continue
+			}
+
+			approvalHistory := &entity.MPPlanningApprovalHistory{
+				MPPlanningHeaderID: header.ID,
+				ApproverName:       approverName,
+				Notes:              escalationNotes(header.NextApproverLevel, level, workingDays),
+				Level:              level,
+				Status:             entity.MPPlanningApprovalHistoryStatusEscalated,
+			}
+			if approverID != nil {
+				approvalHistory.ApproverID = *approverID
+			}
+
+			outboxMessages, err := uc.NotificationService.NewApprovalEscalationNotification(uc.ctx(), service.NotificationDocument{
+				DocumentType:   entity.ApprovalChainDocumentTypeMPPlanning,
+				ID:             header.ID,
+				DocumentNumber: header.DocumentNumber,
+			}, approverID, approvalSLA.PermissionName)
+			if err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPPlanningApprovals] " + err.Error())
+			}
+
+			if err := uc.MPPlanningRepository.EscalateApproval(header.ID, approverID, level, approvalHistory, outboxMessages); err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPPlanningApprovals] " + err.Error())
+				continue
+			}
+			continue
+		}
+
+		if workingDays >= approvalSLA.ReminderAfterDays && header.ApprovalRemindedAt == nil {
+			outboxMessages, err := uc.NotificationService.NewApprovalReminderNotification(uc.ctx(), service.NotificationDocument{
+				DocumentType:   entity.ApprovalChainDocumentTypeMPPlanning,
+				ID:             header.ID,
+				DocumentNumber: header.DocumentNumber,
+			}, header.NextApproverID, approvalSLA.PermissionName)
+			if err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPPlanningApprovals] " + err.Error())
+			}
+
+			if err := uc.MPPlanningRepository.MarkApprovalReminded(header.ID, now, outboxMessages); err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPPlanningApprovals] " + err.Error())
+				continue
+			}
+		}
+	}
+
+	return nil
+}
+
+func (uc *ApprovalSLAUseCase) processMPRequestApprovals(now time.Time, policies map[string]*entity.ApprovalSLA) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	headers, err := uc.MPRequestRepository.FindAwaitingApproval()
+	if err != nil {
+		return err
+	}
+
+	for _, header := range *headers {
+		approvalSLA := findApprovalSLA(policies, header.NextApproverLevel)
+		if approvalSLA == nil {
+			continue
+		}
+
+		workingDays := workingDaysBetween(*header.ApprovalRequestedAt, now)
+
+		if workingDays >= approvalSLA.EscalateAfterDays {
+			var approvalChain *entity.ApprovalChain
+			if header.OrganizationID != nil {
+				approvalChain, err = uc.ApprovalChainRepository.FindActiveByOrganizationIDAndDocumentType(*header.OrganizationID, entity.ApprovalChainDocumentTypeMPRequest)
+				if err != nil {
+					uc.Log.Errorf("[ApprovalSLAUseCase.processMPRequestApprovals] " + err.Error())
+					continue
+				}
+			}
+
+			approverID, approverName, level, ok := escalationTarget(approvalSLA, approvalChain, header.NextApproverLevel, header.NextApproverID, header.MPRequestType)
+			if !ok {
+				uc.Log.Infof("[ApprovalSLAUseCase.processMPRequestApprovals] no escalation target for mp request header %s", header.ID)
+				continue
+			}
+
+			approvalHistory := &entity.MPRequestApprovalHistory{
+				MPRequestHeaderID: header.ID,
+				ApproverName:      approverName,
+				Notes:             escalationNotes(header.NextApproverLevel, level, workingDays),
+				Level:             level,
+				Status:            entity.MPRequestApprovalHistoryStatusEscalated,
+			}
+			if approverID != nil {
+				approvalHistory.ApproverID = *approverID
+			}
+
+			outboxMessages, err := uc.NotificationService.NewApprovalEscalationNotification(uc.ctx(), service.NotificationDocument{
+				DocumentType:   entity.ApprovalChainDocumentTypeMPRequest,
+				ID:             header.ID,
+				DocumentNumber: header.DocumentNumber,
+			}, approverID, approvalSLA.PermissionName)
+			if err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPRequestApprovals] " + err.Error())
+			}
+
+			if err := uc.MPRequestRepository.EscalateApproval(header.ID, approverID, level, approvalHistory, outboxMessages); err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPRequestApprovals] " + err.Error())
+				continue
+			}
+			continue
+		}
+
+		if workingDays >= approvalSLA.ReminderAfterDays && header.ApprovalRemindedAt == nil {
+			outboxMessages, err := uc.NotificationService.NewApprovalReminderNotification(uc.ctx(), service.NotificationDocument{
+				DocumentType:   entity.ApprovalChainDocumentTypeMPRequest,
+				ID:             header.ID,
+				DocumentNumber: header.DocumentNumber,
+			}, header.NextApproverID, approvalSLA.PermissionName)
+			if err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPRequestApprovals] " + err.Error())
+			}
+
+			if err := uc.MPRequestRepository.MarkApprovalReminded(header.ID, now, outboxMessages); err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processMPRequestApprovals] " + err.Error())
+				continue
+			}
+		}
+	}
+
+	return nil
+}
+
+// processBatchApprovals handles batches, whose level is their approver type.
+// Escalating a batch moves it to the configured approver type (or keeps the
+// current one) and notifies the backup approver.
+func (uc *ApprovalSLAUseCase) processBatchApprovals(now time.Time, policies map[string]*entity.ApprovalSLA) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	batchHeaders, err := uc.BatchRepository.FindAwaitingApproval()
+	if err != nil {
+		return err
+	}
+
+	for _, batchHeader := range *batchHeaders {
+		currentLevel := string(batchHeader.ApproverType)
+		approvalSLA := findApprovalSLA(policies, currentLevel)
+		if approvalSLA == nil {
+			continue
+		}
+
+		workingDays := workingDaysBetween(*batchHeader.ApprovalRequestedAt, now)
+
+		if workingDays >= approvalSLA.EscalateAfterDays {
+			level := currentLevel
+			if approvalSLA.EscalateToLevel != "" {
+				level = approvalSLA.EscalateToLevel
+			}
+
+			if level == currentLevel && approvalSLA.BackupApproverID == nil {
+				uc.Log.Infof("[ApprovalSLAUseCase.processBatchApprovals] no escalation target for batch header %s", batchHeader.ID)
+				continue
+			}
+
+			approvalHistories := make([]entity.MPPlanningApprovalHistory, 0, len(batchHeader.BatchLines))
+			for _, batchLine := range batchHeader.BatchLines {
+				approvalHistory := entity.MPPlanningApprovalHistory{
+					MPPlanningHeaderID: batchLine.MPPlanningHeaderID,
+					ApproverName:       approvalSLA.BackupApproverName,
+					Notes:              escalationNotes(currentLevel, level, workingDays),
+					Level:              level,
+					Status:             entity.MPPlanningApprovalHistoryStatusEscalated,
+				}
+				if approvalSLA.BackupApproverID != nil {
+					approvalHistory.ApproverID = *approvalSLA.BackupApproverID
+				}
+				approvalHistories = append(approvalHistories, approvalHistory)
+			}
+
+			outboxMessages, err := uc.NotificationService.NewApprovalEscalationNotification(uc.ctx(), service.NotificationDocument{
+				DocumentType:   entity.ApprovalChainDocumentTypeBatch,
+				ID:             batchHeader.ID,
+				DocumentNumber: batchHeader.DocumentNumber,
+			}, approvalSLA.BackupApproverID, approvalSLA.PermissionName)
+			if err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processBatchApprovals] " + err.Error())
+			}
+
+			if err := uc.BatchRepository.EscalateApproval(batchHeader.ID, entity.BatchHeaderApproverType(level), approvalHistories, outboxMessages); err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processBatchApprovals] " + err.Error())
+				continue
+			}
+			continue
+		}
+
+		if workingDays >= approvalSLA.ReminderAfterDays && batchHeader.ApprovalRemindedAt == nil {
+			outboxMessages, err := uc.NotificationService.NewApprovalReminderNotification(uc.ctx(), service.NotificationDocument{
+				DocumentType:   entity.ApprovalChainDocumentTypeBatch,
+				ID:             batchHeader.ID,
+				DocumentNumber: batchHeader.DocumentNumber,
+			}, nil, approvalSLA.PermissionName)
+			if err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processBatchApprovals] " + err.Error())
+			}
+
+			if err := uc.BatchRepository.MarkApprovalReminded(batchHeader.ID, now, outboxMessages); err != nil {
+				uc.Log.Errorf("[ApprovalSLAUseCase.processBatchApprovals] " + err.Error())
+				continue
+			}
+		}
+	}
+
+	return nil
+}
+
+// findApprovalSLA returns the SLA of the level, or the document type default.
+func findApprovalSLA(policies map[string]*entity.ApprovalSLA, level string) *entity.ApprovalSLA {
+	if approvalSLA, ok := policies[level]; ok {
+		return approvalSLA
+	}
+	return policies[""]
+}
+
+// escalationTarget picks who takes over an overdue approval: the backup
+// approver first, then the escalation level from the SLA, then the next step
+// of the approval chain.
+func escalationTarget(approvalSLA *entity.ApprovalSLA, approvalChain *entity.ApprovalChain, currentLevel string, currentApproverID *uuid.UUID, mpRequestType entity.MPRequestTypeEnum) (*uuid.UUID, string, string, bool) {
+	level := currentLevel
+	if approvalSLA.EscalateToLevel != "" {
+		level = approvalSLA.EscalateToLevel
+	}
+
+	if approvalSLA.BackupApproverID != nil && (currentApproverID == nil || *currentApproverID != *approvalSLA.BackupApproverID) {
+		return approvalSLA.BackupApproverID, approvalSLA.BackupApproverName, level, true
+	}
+
+	if approvalSLA.EscalateToLevel != "" && approvalSLA.EscalateToLevel != currentLevel {
+		if approvalChain != nil {
+			for _, step := range approvalChain.ApprovalChainSteps {
+				if step.Level == approvalSLA.EscalateToLevel && step.AppliesTo(mpRequestType) {
+					return step.ApproverID, step.ApproverName, step.Level, true
+				}
+			}
+		}
+		return nil, "", approvalSLA.EscalateToLevel, true
+	}
+
+	if approvalChain != nil && currentLevel != "" {
+		if step := approvalChain.NextStep(currentLevel, mpRequestType); step != nil {
+			return step.ApproverID, step.ApproverName, step.Level, true
+		}
+	}
+
+	return nil, "", "", false
+}
+
+func escalationNotes(fromLevel string, toLevel string, workingDays int) string {
+	if fromLevel == "" {
+		fromLevel = "pending approver"
+	}
+	if toLevel == "" {
+		toLevel = "pending approver"
+	}
+	return fmt.Sprintf("Escalated from %s to %s after %d working days without approval", fromLevel, toLevel, workingDays)
+}
+
+// workingDaysBetween counts the Monday to Friday days that passed between from and to.
+func workingDaysBetween(from time.Time, to time.Time) int {
+	days := 0
+	for day := from.AddDate(0, 0, 1); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if weekday := day.Weekday(); weekday != time.Saturday && weekday != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+func ApprovalSLAUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IApprovalSLAUseCase {
+	approvalSLARepository := repository.ApprovalSLARepositoryFactory(log)
+	approvalChainRepository := repository.ApprovalChainRepositoryFactory(log)
+	mpPlanningRepository := repository.MPPlanningRepositoryFactory(log)
+	mpRequestRepository := repository.MPRequestRepositoryFactory(log)
+	batchRepository := repository.BatchRepositoryFactory(log)
+	employeeMessage := messaging.EmployeeMessageFactory(log)
+	notificationService := service.NotificationServiceFactory(viper, log)
+	approvalSLADTO := dto.ApprovalSLADTOFactory(log)
+	return NewApprovalSLAUseCase(
+		log,
+		approvalSLARepository,
+		approvalChainRepository,
+		mpPlanningRepository,
+		mpRequestRepository,
+		batchRepository,
+		employeeMessage,
+		notificationService,
+		approvalSLADTO,
+	)
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type IAuditLogUseCase interface {
+	FindAllPaginated(req *request.FindAllPaginatedAuditLogRequest) (*response.FindAllPaginatedAuditLogResponse, error)
+}
+
+type AuditLogUseCase struct {
+	Log                *logrus.Logger
+	Viper              *viper.Viper
+	AuditLogRepository repository.IAuditLogRepository
+	AuditLogDTO        dto.IAuditLogDTO
+}
+
+func NewAuditLogUseCase(log *logrus.Logger, viper *viper.Viper, auditLogRepository repository.IAuditLogRepository, auditLogDTO dto.IAuditLogDTO) IAuditLogUseCase {
+	return &AuditLogUseCase{
+		Log:                log,
+		Viper:              viper,
+		AuditLogRepository: auditLogRepository,
+		AuditLogDTO:        auditLogDTO,
+	}
+}
+
+// FindAllPaginated is the history of the records of an entity, or of one of
+// them, newest first.
+func (uc *AuditLogUseCase) FindAllPaginated(req *request.FindAllPaginatedAuditLogRequest) (*response.FindAllPaginatedAuditLogResponse, error) {
+	filter := map[string]interface{}{
+		"entity": req.Entity,
+	}
+	if req.EntityID != "" {
+		filter["entity_id"] = req.EntityID
+	}
+	if req.Field != "" {
+		filter["field"] = req.Field
+	}
+	if req.Action != "" {
+		filter["action"] = entity.AuditAction(req.Action)
+	}
+	if req.ActorID != "" {
+		filter["actor_id"] = req.ActorID
+	}
+	if req.RequestID != "" {
+		filter["request_id"] = req.RequestID
+	}
+
+	auditLogs, total, err := uc.AuditLogRepository.FindAllPaginated(req.Page, req.PageSize, filter)
+	if err != nil {
+		uc.Log.Errorf("[AuditLogUseCase.FindAllPaginated] " + err.Error())
+		return nil, err
+	}
+
+	auditLogResponses := make([]response.AuditLogResponse, 0, len(*auditLogs))
+	for _, auditLog := range *auditLogs {
+		auditLogResponses = append(auditLogResponses, *uc.AuditLogDTO.ConvertAuditLogEntityToResponse(&auditLog))
+	}
+
+	return &response.FindAllPaginatedAuditLogResponse{
+		AuditLogs: auditLogResponses,
+		Total:     total,
+	}, nil
+}
+
+func AuditLogUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IAuditLogUseCase {
+	auditLogRepository := repository.AuditLogRepositoryFactory(log)
+	auditLogDTO := dto.AuditLogDTOFactory(log)
+	return NewAuditLogUseCase(log, viper, auditLogRepository, auditLogDTO)
+}
@@ -1,16 +1,23 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"io"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/export"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/service"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/pdf"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -21,8 +28,12 @@ type IBatchUsecase interface {
 	GetBatchedMPPlanningHeaders(approverType string, orgID string) (*[]response.MPPlanningHeaderResponse, error)
 	FindByStatus(status entity.BatchHeaderApprovalStatus, approverType string, orgID string) (*response.BatchResponse, error)
 	FindById(id string) (*response.BatchResponse, error)
+	FindOrganizationID(id string) (*uuid.UUID, error)
+	FindPlanningOrganizationID(mpPlanningHeaderID string) (*uuid.UUID, error)
 	GetOrganizationsForBatchApproval(id string) (*[]response.OrganizationResponse, error)
 	FindDocumentByID(id string) (*response.RealDocumentBatchResponse, error)
+	ExportDocument(id string, format export.Format, w io.Writer) error
+	RenderDocumentPDF(id string) (*response.ManpowerAttachmentResponse, error)
 	FindByNeedApproval(approverType string, orgID string) (*response.RealDocumentBatchResponse, error)
 	FindByCurrentDocumentDateAndStatus(status entity.BatchHeaderApprovalStatus) (*response.BatchResponse, error)
 	UpdateStatusBatchHeader(req *request.UpdateStatusBatchHeaderRequest) (*response.BatchResponse, error)
@@ -31,18 +42,26 @@ type IBatchUsecase interface {
 	GetBatchHeadersByStatusPaginated(status entity.BatchHeaderApprovalStatus, approverType string, orgID string, page, pageSize int, search string, sort map[string]interface{}, employeeID uuid.UUID) (*[]response.CompletedBatchResponse, int64, error)
 	TriggerCreate(approverType string, orgID string) (bool, error)
 	MPPlanningDetailsByBatchHeader(batchHeaderID uuid.UUID, page, pageSize int, search string, sort map[string]interface{}) (*[]response.MPPlanningHeaderResponse, int64, error)
+	WithContext(ctx context.Context) IBatchUsecase
 }
 
 type BatchUsecase struct {
-	Viper            *viper.Viper
-	Log              *logrus.Logger
-	Repo             repository.IBatchRepository
-	OrgMessage       messaging.IOrganizationMessage
-	EmpMessage       messaging.IEmployeeMessage
-	batchDTO         dto.IBatchDTO
-	mpPlanningRepo   repository.IMPPlanningRepository
-	JobPlafonMessage messaging.IJobPlafonMessage
-	MPPlanningDTO    dto.IMPPlanningDTO
+	boundContext
+	Viper                  *viper.Viper
+	Log                    *logrus.Logger
+	Repo                   repository.IBatchRepository
+	OrgMessage             messaging.IOrganizationMessage
+	EmpMessage             messaging.IEmployeeMessage
+	batchDTO               dto.IBatchDTO
+	mpPlanningRepo         repository.IMPPlanningRepository
+	JobPlafonMessage       messaging.IJobPlafonMessage
+	MPPlanningDTO          dto.IMPPlanningDTO
+	NotificationService    service.INotificationService
+	PortalDataHelper       helper.IPortalDataHelper
+	DocumentService        service.IDocumentService
+	DocumentNumberService  service.IDocumentNumberService
+	ApprovalChainRepo      repository.IApprovalChainRepository
+	ApprovalDelegationRepo repository.IApprovalDelegationRepository
 }
 
 func NewBatchUsecase(
@@ -55,20 +74,42 @@ func NewBatchUsecase(
 	mpPlanningRepo repository.IMPPlanningRepository,
 	jpMessage messaging.IJobPlafonMessage,
 	mpPlanningDTO dto.IMPPlanningDTO,
+	notificationService service.INotificationService,
+	portalDataHelper helper.IPortalDataHelper,
+	documentService service.IDocumentService,
+	documentNumberService service.IDocumentNumberService,
+	approvalChainRepo repository.IApprovalChainRepository,
+	approvalDelegationRepo repository.IApprovalDelegationRepository,
 ) IBatchUsecase {
 	return &BatchUsecase{
-		Viper:            viper,
-		Log:              log,
-		Repo:             repo,
-		OrgMessage:       orgMessage,
-		EmpMessage:       empMessage,
-		batchDTO:         batchDTO,
-		mpPlanningRepo:   mpPlanningRepo,
-		JobPlafonMessage: jpMessage,
-		MPPlanningDTO:    mpPlanningDTO,
+		Viper:                  viper,
+		Log:                    log,
+		Repo:                   repo,
+		OrgMessage:             orgMessage,
+		EmpMessage:             empMessage,
+		batchDTO:               batchDTO,
+		mpPlanningRepo:         mpPlanningRepo,
+		JobPlafonMessage:       jpMessage,
+		MPPlanningDTO:          mpPlanningDTO,
+		NotificationService:    notificationService,
+		PortalDataHelper:       portalDataHelper,
+		DocumentService:        documentService,
+		DocumentNumberService:  documentNumberService,
+		ApprovalChainRepo:      approvalChainRepo,
+		ApprovalDelegationRepo: approvalDelegationRepo,
 	}
 }
 
+// WithContext is the use case with its repositories bound to ctx, so what it
+// changes is audited under the actor of the request.
+func (uc *BatchUsecase) WithContext(ctx context.Context) IBatchUsecase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	scoped.Repo = uc.Repo.WithContext(ctx)
+	scoped.mpPlanningRepo = uc.mpPlanningRepo.WithContext(ctx)
+	return &scoped
+}
+
 func (uc *BatchUsecase) GetCompletedBatchHeader(page, pageSize int, search string, sort map[string]interface{}, employeeID uuid.UUID) (*[]response.CompletedBatchResponse, int64, error) {
 	batchHeaders, total, err := uc.Repo.GetBatchHeadersByStatusPaginated(entity.BatchHeaderApprovalStatusCompleted, entity.BatchHeaderApproverTypeCEO, "", page, pageSize, search, sort, employeeID)
 	if err != nil {
@@ -352,7 +393,7 @@ func (uc *BatchUsecase) GetOrganizationsForBatchApproval(id string) (*[]response
 		orgIds[i] = bl.OrganizationID.String()
 	}
 
-	ogrs, err := uc.OrgMessage.SendFindAllOrganizationMessage(orgIds)
+	ogrs, err := uc.OrgMessage.SendFindAllOrganizationMessage(uc.ctx(), orgIds)
 
 	if err != nil {
 		uc.Log.Errorf("[BatchUsecase.GetOrganizationsForBatchApproval] " + err.Error())
@@ -376,45 +417,28 @@ func (uc *BatchUsecase) GetOrganizationsForBatchApproval(id string) (*[]response
 }
 
 func (uc *BatchUsecase) CreateBatchHeaderAndLines(req *request.CreateBatchHeaderAndLinesRequest) (*response.BatchResponse, error) {
-	dateNow := time.Now()
-	documentNumber := ""
+	dateNow := timezone.TodayDate()
 	approverType := entity.BatchHeaderApproverTypeCEO
+	numberSequence := workflow.NumberSequenceBatchCEO
+	if req.ApproverType != "" && req.ApproverType != entity.BatchHeaderApproverTypeCEO {
+		approverType = entity.BatchHeaderApproverTypeDirector
+		numberSequence = workflow.NumberSequenceBatchDirector
+	}
 
-	foundBatchHeader, err := uc.Repo.GetHeadersByDocumentDate(dateNow.Format("2006-01-02"))
+	delegation, err := resolveApprovalDelegation(uc.ApprovalDelegationRepo, entity.ApprovalDelegationScopeBatch, uuid.MustParse(req.ApproverID), req.OnBehalfOfID, nil)
 	if err != nil {
-		uc.Log.Errorf("[MPPlanningUseCase.GenerateDocumentNumber] " + err.Error())
+		uc.Log.Errorf("[BatchUsecase.CreateBatchHeaderAndLines] " + err.Error())
 		return nil, err
 	}
 
-	if req.ApproverType != "" {
-		if req.ApproverType == entity.BatchHeaderApproverTypeCEO {
-			if foundBatchHeader == nil {
-				documentNumber = "MPP/BATCH/" + dateNow.Format("20060102") + "/001"
-			} else {
-				documentNumber = "MPP/BATCH/" + dateNow.Format("20060102") + "/" + fmt.Sprintf("%03d", len(*&foundBatchHeader)+1)
-			}
-		} else {
-			if foundBatchHeader == nil {
-				documentNumber = "MPP/BATCH/DIR/" + dateNow.Format("20060102") + "/001"
-			} else {
-				documentNumber = "MPP/BATCH/DIR/" + dateNow.Format("20060102") + "/" + fmt.Sprintf("%03d", len(*&foundBatchHeader)+1)
-			}
-			approverType = entity.BatchHeaderApproverTypeDirector
-		}
-	} else {
-		if foundBatchHeader == nil {
-			documentNumber = "MPP/BATCH/" + dateNow.Format("20060102") + "/001"
-		} else {
-			documentNumber = "MPP/BATCH/" + dateNow.Format("20060102") + "/" + fmt.Sprintf("%03d", len(*&foundBatchHeader)+1)
-		}
-	}
-
 	var batchHeader *entity.BatchHeader
 
 	var orgID uuid.UUID
 	if req.OrganizationID != "" {
 		orgID = uuid.MustParse(req.OrganizationID)
 	}
+	// a batch is numbered when it is saved, unless it comes with its number
+	var numbering *workflow.DocumentNumbering
 	if req.DocumentNumber != "" {
 		batchHeader = &entity.BatchHeader{
 			DocumentNumber: req.DocumentNumber,
@@ -425,12 +449,12 @@ func (uc *BatchUsecase) CreateBatchHeaderAndLines(req *request.CreateBatchHeader
 		}
 	} else {
 		batchHeader = &entity.BatchHeader{
-			DocumentNumber: documentNumber,
 			DocumentDate:   dateNow,
 			Status:         entity.BatchHeaderApprovalStatusNeedApproval,
 			ApproverType:   approverType,
 			OrganizationID: &orgID,
 		}
+		numbering = uc.DocumentNumberService.Numbering(numberSequence)
 	}
 
 	// batchLines := make([]entity.BatchLine, len(req.BatchLines))
@@ -471,7 +495,7 @@ func (uc *BatchUsecase) CreateBatchHeaderAndLines(req *request.CreateBatchHeader
 
 		if mpLine.OrganizationLocationID != nil {
 			// Check if organization location exist
-			orgLocExist, err := uc.OrgMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+			orgLocExist, err := uc.OrgMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 				ID: mpLine.OrganizationLocationID.String(),
 			})
 
@@ -489,7 +513,7 @@ func (uc *BatchUsecase) CreateBatchHeaderAndLines(req *request.CreateBatchHeader
 
 		if mpLine.MPPlanningHeader.OrganizationID != nil {
 			// Check if organization exist
-			orgExist, err := uc.OrgMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+			orgExist, err := uc.OrgMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 				ID: mpLine.MPPlanningHeader.OrganizationID.String(),
 			})
 
@@ -568,14 +592,14 @@ func (uc *BatchUsecase) CreateBatchHeaderAndLines(req *request.CreateBatchHeader
 		}
 
 		if approverType == entity.BatchHeaderApproverTypeCEO {
-			err = uc.updateMpPlanningHeaderStatus(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName)
+			err = uc.updateMpPlanningHeaderStatus(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName, delegation)
 
 			if err != nil {
 				uc.Log.Errorf("[BatchUsecase.CreateBatchHeaderAndLines] " + err.Error())
 				return nil, err
 			}
 		} else {
-			err = uc.updateMpPlanningHeaderStatusDirector(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName)
+			err = uc.updateMpPlanningHeaderStatusDirector(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName, delegation)
 
 			if err != nil {
 				uc.Log.Errorf("[BatchUsecase.CreateBatchHeaderAndLines] " + err.Error())
@@ -583,23 +607,23 @@ func (uc *BatchUsecase) CreateBatchHeaderAndLines(req *request.CreateBatchHeader
 			}
 		}
 
-		return uc.batchDTO.ConvertBatchHeaderEntityToResponse(findBatchHeader), nil
+		return uc.batchDTO.ConvertBatchHeaderEntityToResponse(uc.ctx(), findBatchHeader), nil
 	}
 
-	resp, err := uc.Repo.CreateBatchHeaderAndLines(batchHeader, batchLines)
+	resp, err := uc.Repo.CreateBatchHeaderAndLines(batchHeader, batchLines, numbering)
 	if err != nil {
 		return nil, err
 	}
 
 	if approverType == entity.BatchHeaderApproverTypeCEO {
-		err = uc.updateMpPlanningHeaderStatus(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName)
+		err = uc.updateMpPlanningHeaderStatus(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName, delegation)
 
 		if err != nil {
 			uc.Log.Errorf("[BatchUsecase.CreateBatchHeaderAndLines] " + err.Error())
 			return nil, err
 		}
 	} else {
-		err = uc.updateMpPlanningHeaderStatusDirector(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName)
+		err = uc.updateMpPlanningHeaderStatusDirector(batchLines, uuid.MustParse(req.ApproverID), req.ApproverName, delegation)
 
 		if err != nil {
 			uc.Log.Errorf("[BatchUsecase.CreateBatchHeaderAndLines] " + err.Error())
@@ -607,23 +631,25 @@ func (uc *BatchUsecase) CreateBatchHeaderAndLines(req *request.CreateBatchHeader
 		}
 	}
 
-	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(resp), nil
+	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(uc.ctx(), resp), nil
 }
 
-func (uc *BatchUsecase) updateMpPlanningHeaderStatusDirector(batchLines []entity.BatchLine, approverID uuid.UUID, approverName string) error {
+func (uc *BatchUsecase) updateMpPlanningHeaderStatusDirector(batchLines []entity.BatchLine, approverID uuid.UUID, approverName string, delegation *entity.ApprovalDelegation) error {
 	for _, bl := range batchLines {
 		approvalHistory := &entity.MPPlanningApprovalHistory{
 			MPPlanningHeaderID: bl.MPPlanningHeaderID,
 			Notes:              "",
 			ApproverID:         approverID,
 			ApproverName:       approverName,
+			OnBehalfOfID:       onBehalfOfID(delegation),
+			OnBehalfOfName:     onBehalfOfName(delegation),
 			Level:              string(entity.MPPlanningApprovalHistoryLevelHRDUnit),
 			Status:             entity.MPPlanningApprovalHistoryStatusNeedApproval,
 		}
 
 		uc.Log.Infof("approver id direktur: %s", approverID.String())
 
-		err := uc.mpPlanningRepo.UpdateStatusHeader(bl.MPPlanningHeaderID, string(entity.MPPlanningApprovalHistoryStatusNeedApproval), "", approvalHistory)
+		err := uc.mpPlanningRepo.UpdateStatusHeader(bl.MPPlanningHeaderID, repository.AnyVersion, string(entity.MPPlanningApprovalHistoryStatusNeedApproval), "", nil, approvalHistory, nil)
 		if err != nil {
 			uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
 			return err
@@ -633,12 +659,14 @@ func (uc *BatchUsecase) updateMpPlanningHeaderStatusDirector(batchLines []entity
 	return nil
 }
 
-func (uc *BatchUsecase) updateMpPlanningHeaderStatus(batchLines []entity.BatchLine, approverID uuid.UUID, approverName string) error {
+func (uc *BatchUsecase) updateMpPlanningHeaderStatus(batchLines []entity.BatchLine, approverID uuid.UUID, approverName string, delegation *entity.ApprovalDelegation) error {
 	for _, bl := range batchLines {
 		approvalHistory := &entity.MPPlanningApprovalHistory{
 			MPPlanningHeaderID: bl.MPPlanningHeaderID,
 			ApproverID:         approverID,
 			ApproverName:       approverName,
+			OnBehalfOfID:       onBehalfOfID(delegation),
+			OnBehalfOfName:     onBehalfOfName(delegation),
 			Notes:              "",
 			Level:              string(entity.MPPlanningApprovalHistoryLevelRecruitment),
 			Status:             entity.MPPlanningApprovalHistoryStatusNeedApproval,
@@ -646,7 +674,7 @@ func (uc *BatchUsecase) updateMpPlanningHeaderStatus(batchLines []entity.BatchLi
 
 		uc.Log.Infof("approver id: %s", approverID.String())
 
-		err := uc.mpPlanningRepo.UpdateStatusHeader(bl.MPPlanningHeaderID, string(entity.MPPlanningApprovalHistoryStatusNeedApproval), approverID.String(), approvalHistory)
+		err := uc.mpPlanningRepo.UpdateStatusHeader(bl.MPPlanningHeaderID, repository.AnyVersion, string(entity.MPPlanningApprovalHistoryStatusNeedApproval), approverID.String(), nil, approvalHistory, nil)
 		if err != nil {
 			uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
 			return err
@@ -666,7 +694,45 @@ func (uc *BatchUsecase) FindByStatus(status entity.BatchHeaderApprovalStatus, ap
 		return nil, errors.New("Batch not found")
 	}
 
-	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(resp), nil
+	uc.enrichBatchLines(resp.BatchLines)
+
+	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(uc.ctx(), resp), nil
+}
+
+// FindOrganizationID is the organization the batch was made for, nil when
+// there is no such batch.
+func (uc *BatchUsecase) FindOrganizationID(id string) (*uuid.UUID, error) {
+	batchHeader, err := uc.Repo.FindById(id)
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.FindOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if batchHeader == nil {
+		return nil, nil
+	}
+
+	return batchHeader.OrganizationID, nil
+}
+
+// FindPlanningOrganizationID is the organization of a planning put in a batch.
+func (uc *BatchUsecase) FindPlanningOrganizationID(mpPlanningHeaderID string) (*uuid.UUID, error) {
+	id, err := uuid.Parse(mpPlanningHeaderID)
+	if err != nil {
+		return nil, nil
+	}
+
+	mpPlanningHeader, err := uc.mpPlanningRepo.FindHeaderById(id)
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.FindPlanningOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningHeader == nil {
+		return nil, nil
+	}
+
+	return mpPlanningHeader.OrganizationID, nil
 }
 
 func (uc *BatchUsecase) FindById(id string) (*response.BatchResponse, error) {
@@ -679,7 +745,9 @@ func (uc *BatchUsecase) FindById(id string) (*response.BatchResponse, error) {
 		return nil, errors.New("Batch not found")
 	}
 
-	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(resp), nil
+	uc.enrichBatchLines(resp.BatchLines)
+
+	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(uc.ctx(), resp), nil
 }
 
 func (uc *BatchUsecase) UpdateStatusBatchHeader(req *request.UpdateStatusBatchHeaderRequest) (*response.BatchResponse, error) {
@@ -693,21 +761,177 @@ func (uc *BatchUsecase) UpdateStatusBatchHeader(req *request.UpdateStatusBatchHe
 		return nil, errors.New("Batch not found")
 	}
 
+	if err := workflow.CheckVersion(batchHeader.Version, req.Version); err != nil {
+		uc.Log.Warnf("[BatchUsecase.UpdateStatusBatchHeader] " + err.Error())
+		return nil, err
+	}
+
+	delegation, err := uc.resolveApprover(batchHeader, req)
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.UpdateStatusBatchHeader] " + err.Error())
+		return nil, err
+	}
+
+	events, err := uc.statusChangeEvents(batchHeader, req.Status)
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.UpdateStatusBatchHeader] " + err.Error())
+		return nil, err
+	}
+
 	if req.ApproverType == "" || req.ApproverType == entity.BatchHeaderApproverTypeCEO {
-		err = uc.Repo.UpdateStatusBatchHeader(batchHeader, req.Status, req.ApprovedBy, req.ApproverName)
+		outboxMessages := append(uc.statusChangeNotifications(batchHeader, req.Status, entity.BatchHeaderApproverTypeCEO), events...)
+		err = uc.Repo.UpdateStatusBatchHeader(batchHeader, req.Status, req.ApprovedBy, req.ApproverName, delegation, outboxMessages)
 		if err != nil {
 			uc.Log.Errorf("[BatchUsecase.UpdateStatusBatchHeader] " + err.Error())
 			return nil, err
 		}
 	} else {
-		err = uc.Repo.UpdateStatusBatchHeaderForDirector(batchHeader, req.Status, req.ApprovedBy, req.ApproverName)
+		outboxMessages := append(uc.statusChangeNotifications(batchHeader, req.Status, entity.BatchHeaderApproverTypeDirector), events...)
+		err = uc.Repo.UpdateStatusBatchHeaderForDirector(batchHeader, req.Status, req.ApprovedBy, req.ApproverName, delegation, outboxMessages)
 		if err != nil {
 			uc.Log.Errorf("[BatchUsecase.UpdateStatusBatchHeader] " + err.Error())
 			return nil, err
 		}
 	}
 
-	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(batchHeader), nil
+	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(uc.ctx(), batchHeader), nil
+}
+
+// resolveApprover makes sure the approver deciding on a batch is the one the
+// approval chain of its organization names for the level the batch waits on,
+// or their delegate, and returns the delegation they act under. Batches of
+// organizations without an active chain are not restricted.
+func (uc *BatchUsecase) resolveApprover(batchHeader *entity.BatchHeader, req *request.UpdateStatusBatchHeaderRequest) (*entity.ApprovalDelegation, error) {
+	approverID, err := uuid.Parse(req.ApprovedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	level := req.ApproverType
+	if level == "" {
+		level = entity.BatchHeaderApproverTypeCEO
+	}
+
+	deciding := batchHeader.Status == entity.BatchHeaderApprovalStatusNeedApproval &&
+		(req.Status == entity.BatchHeaderApprovalStatusApproved || req.Status == entity.BatchHeaderApprovalStatusRejected)
+
+	var step *entity.ApprovalChainStep
+	if deciding && batchHeader.OrganizationID != nil {
+		approvalChain, err := uc.ApprovalChainRepo.FindActiveByOrganizationIDAndDocumentType(*batchHeader.OrganizationID, entity.ApprovalChainDocumentTypeBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		if approvalChain != nil {
+			step, err = workflow.PendingChainStep(approvalChain, string(level))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var nextApproverID *uuid.UUID
+	if step != nil {
+		nextApproverID = step.ApproverID
+	}
+
+	delegation, err := resolveApprovalDelegation(uc.ApprovalDelegationRepo, entity.ApprovalDelegationScopeBatch, approverID, req.OnBehalfOfID, nextApproverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if step == nil {
+		return delegation, nil
+	}
+
+	actingFor := approverID
+	if delegation != nil {
+		actingFor = *delegation.DelegatorID
+	}
+
+	if err := workflow.CheckNextApprover(step.Level, step.ApproverID, string(level), actingFor); err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// statusChangeNotifications builds the notifications that tell the requestor
+// of every batched planning what happened to it, and the CEO approvers once
+// the directors approved a batch. Failures are only logged so a lost
+// notification never blocks a status change.
+func (uc *BatchUsecase) statusChangeNotifications(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus, approverType entity.BatchHeaderApproverType) []entity.OutboxMessage {
+	var outboxMessages []entity.OutboxMessage
+	collect := func(notifications []entity.OutboxMessage, err error) {
+		if err != nil {
+			uc.Log.Errorf("[BatchUsecase.statusChangeNotifications] " + err.Error())
+			return
+		}
+		outboxMessages = append(outboxMessages, notifications...)
+	}
+
+	if approverType == entity.BatchHeaderApproverTypeDirector && status == entity.BatchHeaderApprovalStatusApproved {
+		collect(uc.NotificationService.NewBatchReadyForCEONotification(uc.ctx(), service.NotificationDocument{
+			DocumentType:   entity.ApprovalChainDocumentTypeBatch,
+			ID:             batchHeader.ID,
+			DocumentNumber: batchHeader.DocumentNumber,
+		}))
+	}
+
+	// a director completing a batch does not change the planning headers
+	if status == entity.BatchHeaderApprovalStatusCompleted && approverType == entity.BatchHeaderApproverTypeDirector {
+		return outboxMessages
+	}
+
+	for _, bl := range batchHeader.BatchLines {
+		document := service.NotificationDocument{
+			DocumentType:   entity.ApprovalChainDocumentTypeMPPlanning,
+			ID:             bl.MPPlanningHeaderID,
+			DocumentNumber: bl.MPPlanningHeader.DocumentNumber,
+		}
+
+		switch status {
+		case entity.BatchHeaderApprovalStatusApproved:
+			collect(uc.NotificationService.NewApprovedNotification(uc.ctx(), document, bl.MPPlanningHeader.RequestorID))
+		case entity.BatchHeaderApprovalStatusRejected:
+			collect(uc.NotificationService.NewRejectedNotification(uc.ctx(), document, bl.MPPlanningHeader.RequestorID))
+		case entity.BatchHeaderApprovalStatusCompleted:
+			collect(uc.NotificationService.NewCompletedNotification(uc.ctx(), document, bl.MPPlanningHeader.RequestorID))
+		}
+	}
+
+	return outboxMessages
+}
+
+// statusChangeEvents builds the domain events of a status change: approving a
+// batch approves every batched planning and completing it completes the batch.
+func (uc *BatchUsecase) statusChangeEvents(batchHeader *entity.BatchHeader, status entity.BatchHeaderApprovalStatus) ([]entity.OutboxMessage, error) {
+	var outboxMessages []entity.OutboxMessage
+
+	switch status {
+	case entity.BatchHeaderApprovalStatusApproved:
+		for _, bl := range batchHeader.BatchLines {
+			approved := bl.MPPlanningHeader
+			approved.Status = entity.MPPlaningStatusApproved
+
+			event, err := newDomainEventMessage(messaging.DomainEventMPPlanningApproved, uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), &approved))
+			if err != nil {
+				return nil, err
+			}
+			outboxMessages = append(outboxMessages, *event)
+		}
+	case entity.BatchHeaderApprovalStatusCompleted:
+		completed := *batchHeader
+		completed.Status = entity.BatchHeaderApprovalStatusCompleted
+
+		event, err := newDomainEventMessage(messaging.DomainEventBatchCompleted, uc.batchDTO.ConvertBatchHeaderEntityToResponse(uc.ctx(), &completed))
+		if err != nil {
+			return nil, err
+		}
+		outboxMessages = append(outboxMessages, *event)
+	}
+
+	return outboxMessages, nil
 }
 
 func (uc *BatchUsecase) FindDocumentByID(id string) (*response.RealDocumentBatchResponse, error) {
@@ -738,7 +962,239 @@ func (uc *BatchUsecase) FindDocumentByID(id string) (*response.RealDocumentBatch
 	// 	}
 	// }
 
-	return uc.batchDTO.ConvertRealDocumentBatchResponse(resp), nil
+	return uc.batchDTO.ConvertRealDocumentBatchResponse(uc.ctx(), resp), nil
+}
+
+var batchDocumentExportColumns = []string{
+	"Scope", "Operating Unit", "Budget Year", "Budget Range", "Existing Date",
+	"Grade", "Job Level", "Existing", "Promote", "Recruit", "Total",
+}
+
+// batchDocumentSection is one block of the batch document: the overall sums,
+// those of an organization or those of a location.
+type batchDocumentSection struct {
+	scope    string
+	document *response.DocumentBatchResponse
+}
+
+// batchDocumentSections lists the overall sums first, then those of each
+// organization followed by its locations.
+func batchDocumentSections(document *response.RealDocumentBatchResponse) []batchDocumentSection {
+	sections := []batchDocumentSection{{scope: "Overall", document: &document.Overall}}
+	for i := range document.OrganizationOverall {
+		organization := &document.OrganizationOverall[i]
+		sections = append(sections, batchDocumentSection{scope: "Organization", document: &organization.Overall})
+		for j := range organization.LocationOverall {
+			sections = append(sections, batchDocumentSection{scope: "Location", document: &organization.LocationOverall[j]})
+		}
+	}
+	return sections
+}
+
+type batchDocumentGrade struct {
+	name         string
+	calculations []response.DocumentCalculationBatchResponse
+}
+
+func (s *batchDocumentSection) grades() []batchDocumentGrade {
+	return []batchDocumentGrade{
+		{"Executive", s.document.Grade.Executive},
+		{"Non Executive", s.document.Grade.NonExecutive},
+		{"Total", s.document.Grade.Total},
+	}
+}
+
+// ExportDocument writes the batch document to w, section after section.
+func (uc *BatchUsecase) ExportDocument(id string, format export.Format, w io.Writer) error {
+	document, err := uc.FindDocumentByID(id)
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.ExportDocument] " + err.Error())
+		return err
+	}
+
+	writer, err := export.NewWriter(format, w, "Batch Document")
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteRow(batchDocumentExportColumns); err != nil {
+		uc.Log.Errorf("[BatchUsecase.ExportDocument] " + err.Error())
+		return err
+	}
+
+	for _, section := range batchDocumentSections(document) {
+		for _, grade := range section.grades() {
+			for _, calculation := range grade.calculations {
+				if err := writer.WriteRow([]string{
+					section.scope,
+					section.document.OperatingUnit,
+					section.document.BudgetYear,
+					section.document.BudgetRange,
+					section.document.ExistingDate,
+					grade.name,
+					calculation.JobLevelName,
+					export.Int(calculation.Existing),
+					export.Int(calculation.Promote),
+					export.Int(calculation.Recruit),
+					export.Int(calculation.Total),
+				}); err != nil {
+					uc.Log.Errorf("[BatchUsecase.ExportDocument] " + err.Error())
+					return err
+				}
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		uc.Log.Errorf("[BatchUsecase.ExportDocument] " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+var batchDocumentPDFColumns = []pdf.Column{
+	{Title: "Grade", Width: 2},
+	{Title: "Job Level", Width: 3},
+	{Title: "Existing", Width: 1.2, Align: pdf.AlignRight},
+	{Title: "Promote", Width: 1.2, Align: pdf.AlignRight},
+	{Title: "Recruit", Width: 1.2, Align: pdf.AlignRight},
+	{Title: "Total", Width: 1.2, Align: pdf.AlignRight},
+}
+
+// RenderDocumentPDF prints the batch document, signed by the approvers of the
+// batch, and keeps the file as an attachment of the batch.
+func (uc *BatchUsecase) RenderDocumentPDF(id string) (*response.ManpowerAttachmentResponse, error) {
+	batchHeader, err := uc.Repo.FindById(id)
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.RenderDocumentPDF] " + err.Error())
+		return nil, err
+	}
+
+	if batchHeader == nil {
+		return nil, errors.New("Batch not found")
+	}
+
+	document := uc.batchDTO.ConvertRealDocumentBatchResponse(uc.ctx(), batchHeader)
+
+	report := pdf.NewReport(uc.DocumentService.Letterhead("Manpower Planning Batch Document"))
+	report.Fields([]pdf.Field{
+		{Label: "Document Number", Value: batchHeader.DocumentNumber},
+		{Label: "Document Date", Value: printedDate(&batchHeader.DocumentDate)},
+		{Label: "Approver", Value: string(batchHeader.ApproverType)},
+		{Label: "Status", Value: string(batchHeader.Status)},
+	})
+
+	for _, section := range batchDocumentSections(document) {
+		report.Heading(section.scope + ": " + section.document.OperatingUnit)
+		report.Fields([]pdf.Field{
+			{Label: "Budget Year", Value: section.document.BudgetYear},
+			{Label: "Budget Range", Value: section.document.BudgetRange},
+			{Label: "Existing Date", Value: section.document.ExistingDate},
+		})
+
+		var rows []pdf.Row
+		for _, grade := range section.grades() {
+			for _, calculation := range grade.calculations {
+				rows = append(rows, pdf.Row{
+					Cells: []string{
+						grade.name,
+						calculation.JobLevelName,
+						export.Int(calculation.Existing),
+						export.Int(calculation.Promote),
+						export.Int(calculation.Recruit),
+						export.Int(calculation.Total),
+					},
+					Bold: calculation.IsTotal,
+				})
+			}
+		}
+		report.Table(batchDocumentPDFColumns, rows)
+	}
+
+	signatures, err := uc.batchSignatures(batchHeader)
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.RenderDocumentPDF] " + err.Error())
+		return nil, err
+	}
+	report.Heading("Approval")
+	report.Signatures(signatures)
+
+	attachment, err := uc.DocumentService.StorePDF(report, batchHeader.TableName(), batchHeader.ID, "batch-document-"+batchHeader.DocumentNumber+".pdf")
+	if err != nil {
+		uc.Log.Errorf("[BatchUsecase.RenderDocumentPDF] " + err.Error())
+		return nil, err
+	}
+
+	return &response.ManpowerAttachmentResponse{
+		ID:       attachment.ID.String(),
+		FileName: attachment.FileName,
+		FilePath: attachment.FilePath,
+		FileType: attachment.FileType,
+	}, nil
+}
+
+// batchSignatures signs the batch document with every decision taken on the
+// batch. A decision is written once for each planning of the batch, so it is
+// signed once; the step still waiting for a decision is left unsigned.
+func (uc *BatchUsecase) batchSignatures(batchHeader *entity.BatchHeader) ([]pdf.Signature, error) {
+	approvalHistories, err := uc.Repo.FindApprovalHistories(batchHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	type decision struct {
+		level      string
+		approverID uuid.UUID
+		status     entity.MPPlanningApprovalHistoryStatus
+	}
+	signed := make(map[decision]bool)
+
+	var signatures []pdf.Signature
+	for _, history := range approvalHistories {
+		if history.Status != entity.MPPlanningApprovalHistoryStatusApproved && history.Status != entity.MPPlanningApprovalHistoryStatusRejected {
+			continue
+		}
+		key := decision{level: history.Level, approverID: history.ApproverID, status: history.Status}
+		if signed[key] {
+			continue
+		}
+		signed[key] = true
+
+		role := history.Level
+		if history.OnBehalfOfName != "" {
+			role += " on behalf of " + history.OnBehalfOfName
+		}
+		signatures = append(signatures, pdf.Signature{
+			Role:   role,
+			Name:   history.ApproverName,
+			Status: string(history.Status),
+			Date:   printedDate(&history.CreatedAt),
+			Notes:  history.Notes,
+		})
+	}
+
+	if batchHeader.Status == entity.BatchHeaderApprovalStatusNeedApproval {
+		level := entity.MPPlanningApprovalHistoryLevelCEO
+		if batchHeader.ApproverType == entity.BatchHeaderApproverTypeDirector {
+			level = entity.MPPlanningApprovalHistoryLevelDirekturUnit
+		}
+		signatures = append(signatures, pdf.Signature{
+			Role:   string(level),
+			Status: string(batchHeader.Status),
+		})
+	}
+
+	return signatures, nil
+}
+
+// printedDate formats a date the way the printed documents show it, empty
+// when there is none.
+func printedDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("02 January 2006")
 }
 
 func (uc *BatchUsecase) FindByNeedApproval(approverType string, orgID string) (*response.RealDocumentBatchResponse, error) {
@@ -751,7 +1207,7 @@ func (uc *BatchUsecase) FindByNeedApproval(approverType string, orgID string) (*
 		return nil, errors.New("Batch not found")
 	}
 
-	return uc.batchDTO.ConvertRealDocumentBatchResponse(resp), nil
+	return uc.batchDTO.ConvertRealDocumentBatchResponse(uc.ctx(), resp), nil
 }
 
 func (uc *BatchUsecase) FindByCurrentDocumentDateAndStatus(status entity.BatchHeaderApprovalStatus) (*response.BatchResponse, error) {
@@ -760,7 +1216,7 @@ func (uc *BatchUsecase) FindByCurrentDocumentDateAndStatus(status entity.BatchHe
 		return nil, err
 	}
 
-	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(resp), nil
+	return uc.batchDTO.ConvertBatchHeaderEntityToResponse(uc.ctx(), resp), nil
 }
 
 func (uc *BatchUsecase) MPPlanningDetailsByBatchHeader(batchHeaderID uuid.UUID, page, pageSize int, search string, sort map[string]interface{}) (*[]response.MPPlanningHeaderResponse, int64, error) {
@@ -800,7 +1256,7 @@ func (uc *BatchUsecase) MPPlanningDetailsByBatchHeader(batchHeaderID uuid.UUID,
 	}
 	paginatedBatchLines := filteredBatchLines[start:end]
 
-	mpPlanningHeaders := make([]response.MPPlanningHeaderResponse, len(paginatedBatchLines))
+	entMPPlanningHeaders := make([]entity.MPPlanningHeader, len(paginatedBatchLines))
 	for i, bl := range paginatedBatchLines {
 		mpPlanningHeader, err := uc.mpPlanningRepo.FindHeaderById(bl.MPPlanningHeaderID)
 		if err != nil {
@@ -808,12 +1264,31 @@ func (uc *BatchUsecase) MPPlanningDetailsByBatchHeader(batchHeaderID uuid.UUID,
 			return nil, 0, err
 		}
 
-		mpPlanningHeaders[i] = *uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(mpPlanningHeader)
+		entMPPlanningHeaders[i] = *mpPlanningHeader
+	}
+
+	// the DTO looks up the names left empty one by one
+	if err := uc.PortalDataHelper.EnrichMPPlanningHeaders(uc.ctx(), entMPPlanningHeaders); err != nil {
+		uc.Log.Warnf("[BatchUsecase.MPPlanningDetailsByBatchHeader] " + err.Error())
+	}
+
+	mpPlanningHeaders := make([]response.MPPlanningHeaderResponse, len(entMPPlanningHeaders))
+	for i := range entMPPlanningHeaders {
+		mpPlanningHeaders[i] = *uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), &entMPPlanningHeaders[i])
 	}
 
 	return &mpPlanningHeaders, total, nil
 }
 
+// enrichBatchLines fills the portal names of the batch lines and their mp
+// planning headers for the whole batch at once. The DTO looks up the names
+// left empty one by one, so a failure only costs the batch lookups.
+func (uc *BatchUsecase) enrichBatchLines(batchLines []entity.BatchLine) {
+	if err := uc.PortalDataHelper.EnrichBatchLines(uc.ctx(), batchLines); err != nil {
+		uc.Log.Warnf("[BatchUsecase.enrichBatchLines] " + err.Error())
+	}
+}
+
 func BatchUsecaseFactory(viper *viper.Viper, log *logrus.Logger) IBatchUsecase {
 	repo := repository.BatchRepositoryFactory(log)
 	orgMessage := messaging.OrganizationMessageFactory(log)
@@ -822,5 +1297,11 @@ func BatchUsecaseFactory(viper *viper.Viper, log *logrus.Logger) IBatchUsecase {
 	mpPlanningRepo := repository.MPPlanningRepositoryFactory(log)
 	jpMessage := messaging.JobPlafonMessageFactory(log)
 	mpPlanningDTO := dto.MPPlanningDTOFactory(log)
-	return NewBatchUsecase(viper, log, repo, orgMessage, empMessage, batchDTO, mpPlanningRepo, jpMessage, mpPlanningDTO)
+	notificationService := service.NotificationServiceFactory(viper, log)
+	portalDataHelper := helper.PortalDataHelperFactory(log)
+	documentService := service.DocumentServiceFactory(viper, log)
+	documentNumberService := service.DocumentNumberServiceFactory(viper, log)
+	approvalChainRepo := repository.ApprovalChainRepositoryFactory(log)
+	approvalDelegationRepo := repository.ApprovalDelegationRepositoryFactory(log)
+	return NewBatchUsecase(viper, log, repo, orgMessage, empMessage, batchDTO, mpPlanningRepo, jpMessage, mpPlanningDTO, notificationService, portalDataHelper, documentService, documentNumberService, approvalChainRepo, approvalDelegationRepo)
 }
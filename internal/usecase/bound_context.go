@@ -0,0 +1,19 @@
+package usecase
+
+import "context"
+
+// boundContext is the context a use case was bound to with WithContext. The
+// messages a use case sends to the portal go out under it, so they are
+// cancelled with the request that caused them.
+type boundContext struct {
+	bound context.Context
+}
+
+// ctx is the bound context, or the background context of a use case that was
+// not bound to a request, like the one the consumers and schedulers use.
+func (b boundContext) ctx() context.Context {
+	if b.bound != nil {
+		return b.bound
+	}
+	return context.Background()
+}
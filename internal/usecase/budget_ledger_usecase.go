@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type IBudgetLedgerUseCase interface {
+	FindAllByLinePaginated(req *request.FindAllByLinePaginatedBudgetLedgerRequest) (*response.FindAllPaginatedBudgetLedgerResponse, error)
+	FindLineOrganizationID(lineID uuid.UUID) (*uuid.UUID, error)
+}
+
+type BudgetLedgerUseCase struct {
+	Log                    *logrus.Logger
+	BudgetLedgerRepository repository.IBudgetLedgerRepository
+	MPPlanningRepository   repository.IMPPlanningRepository
+	BudgetLedgerDTO        dto.IBudgetLedgerDTO
+}
+
+func NewBudgetLedgerUseCase(log *logrus.Logger, repo repository.IBudgetLedgerRepository, mpPlanningRepository repository.IMPPlanningRepository, budgetLedgerDTO dto.IBudgetLedgerDTO) IBudgetLedgerUseCase {
+	return &BudgetLedgerUseCase{
+		Log:                    log,
+		BudgetLedgerRepository: repo,
+		MPPlanningRepository:   mpPlanningRepository,
+		BudgetLedgerDTO:        budgetLedgerDTO,
+	}
+}
+
+// FindAllByLinePaginated lists the reservations, consumptions and releases of
+// a planning line, newest first, with the balances they left.
+func (uc *BudgetLedgerUseCase) FindAllByLinePaginated(req *request.FindAllByLinePaginatedBudgetLedgerRequest) (*response.FindAllPaginatedBudgetLedgerResponse, error) {
+	mpPlanningLine, err := uc.MPPlanningRepository.FindLineByIdOnly(uuid.MustParse(req.MPPlanningLineID))
+	if err != nil {
+		uc.Log.Errorf("[BudgetLedgerUseCase.FindAllByLinePaginated] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningLine == nil {
+		return nil, errors.New("MP Planning Line not found")
+	}
+
+	entries, total, err := uc.BudgetLedgerRepository.FindAllByLineIDPaginated(mpPlanningLine.ID, req.Page, req.PageSize)
+	if err != nil {
+		uc.Log.Errorf("[BudgetLedgerUseCase.FindAllByLinePaginated] " + err.Error())
+		return nil, err
+	}
+
+	entryResponses := make([]response.BudgetLedgerEntryResponse, 0, len(*entries))
+	for _, entry := range *entries {
+		entryResponses = append(entryResponses, *uc.BudgetLedgerDTO.ConvertBudgetLedgerEntryEntityToResponse(&entry))
+	}
+
+	return &response.FindAllPaginatedBudgetLedgerResponse{
+		MPPlanningLineID:   mpPlanningLine.ID,
+		RecruitMT:          mpPlanningLine.RecruitMT,
+		RemainingBalanceMT: mpPlanningLine.RemainingBalanceMT,
+		RecruitPH:          mpPlanningLine.RecruitPH,
+		RemainingBalancePH: mpPlanningLine.RemainingBalancePH,
+		Entries:            entryResponses,
+		Total:              total,
+	}, nil
+}
+
+// FindLineOrganizationID is the organization of the planning the line belongs to.
+func (uc *BudgetLedgerUseCase) FindLineOrganizationID(lineID uuid.UUID) (*uuid.UUID, error) {
+	mpPlanningLine, err := uc.MPPlanningRepository.FindLineByIdOnly(lineID)
+	if err != nil {
+		uc.Log.Errorf("[BudgetLedgerUseCase.FindLineOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningLine == nil {
+		return nil, nil
+	}
+
+	mpPlanningHeader, err := uc.MPPlanningRepository.FindHeaderById(mpPlanningLine.MPPlanningHeaderID)
+	if err != nil {
+		uc.Log.Errorf("[BudgetLedgerUseCase.FindLineOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningHeader == nil {
+		return nil, nil
+	}
+
+	return mpPlanningHeader.OrganizationID, nil
+}
+
+func BudgetLedgerUseCaseFactory(log *logrus.Logger) IBudgetLedgerUseCase {
+	repo := repository.BudgetLedgerRepositoryFactory(log)
+	mpPlanningRepository := repository.MPPlanningRepositoryFactory(log)
+	budgetLedgerDTO := dto.BudgetLedgerDTOFactory(log)
+	return NewBudgetLedgerUseCase(log, repo, mpPlanningRepository, budgetLedgerDTO)
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -21,9 +22,11 @@ type IJobPlafonUseCase interface {
 	Update(request *request.UpdateJobPlafonRequest) (*response.UpdateJobPlafonResponse, error)
 	Delete(request *request.DeleteJobPlafonRequest) error
 	SyncJobPlafon() error
+	WithContext(ctx context.Context) IJobPlafonUseCase
 }
 
 type JobPlafonUseCase struct {
+	boundContext
 	Log                 *logrus.Logger
 	JobPlafonRepository repository.IJobPlafonRepository
 	JobPlafonMessage    messaging.IJobPlafonMessage
@@ -39,9 +42,18 @@ func NewJobPlafonUseCase(log *logrus.Logger, repo repository.IJobPlafonRepositor
 	}
 }
 
+// WithContext is the use case with its repositories bound to ctx, so what it
+// changes is audited under the actor of the request.
+func (uc *JobPlafonUseCase) WithContext(ctx context.Context) IJobPlafonUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	scoped.JobPlafonRepository = uc.JobPlafonRepository.WithContext(ctx)
+	return &scoped
+}
+
 func (uc *JobPlafonUseCase) SyncJobPlafon() error {
 	// get jobs data using rabbitmq
-	jobPlafonMessageResponse, err := uc.JobMessage.SendGetAllJobDataMessage()
+	jobPlafonMessageResponse, err := uc.JobMessage.SendGetAllJobDataMessage(uc.ctx())
 	if err != nil {
 		uc.Log.Errorf("[JobPlafonUseCase.SyncJobPlafon] " + err.Error())
 		return err
@@ -57,11 +69,18 @@ func (uc *JobPlafonUseCase) SyncJobPlafon() error {
 
 		if jobPlafon == nil {
 			jobPlafonEntity := entity.JobPlafon{
+				ID:     uuid.New(),
 				JobID:  &job.ID,
 				Plafon: 0,
 			}
 
-			_, err := uc.JobPlafonRepository.Create(&jobPlafonEntity)
+			changedEvent, err := uc.changedEvent(messaging.JobPlafonChangeCreated, &jobPlafonEntity)
+			if err != nil {
+				uc.Log.Errorf("[JobPlafonUseCase.SyncJobPlafon] " + err.Error())
+				return err
+			}
+
+			_, err = uc.JobPlafonRepository.Create(&jobPlafonEntity, changedEvent)
 			if err != nil {
 				uc.Log.Errorf("[JobPlafonUseCase.SyncJobPlafon] " + err.Error())
 				return err
@@ -74,7 +93,7 @@ func (uc *JobPlafonUseCase) SyncJobPlafon() error {
 
 func (uc *JobPlafonUseCase) FindAllPaginated(req *request.FindAllPaginatedJobPlafonRequest) (*response.FindAllPaginatedJobPlafonResponse, error) {
 	// get jobs ids using rabbitmq
-	jobPlafonMessageResponse, err := uc.JobMessage.SendFindAllJobsByOrganizationIDMessage(req.OrganizationID)
+	jobPlafonMessageResponse, err := uc.JobMessage.SendFindAllJobsByOrganizationIDMessage(uc.ctx(), req.OrganizationID)
 	if err != nil {
 		uc.Log.Errorf("[JobPlafonUseCase.FindAllPaginated] " + err.Error())
 		return nil, err
@@ -101,7 +120,7 @@ func (uc *JobPlafonUseCase) FindAllPaginated(req *request.FindAllPaginatedJobPla
 	var filteredJobPlafons []entity.JobPlafon
 
 	for _, jobPlafon := range *jobPlafons {
-		messageResponse, err := uc.JobMessage.SendFindJobDataByIdMessage(request.SendFindJobByIDMessageRequest{
+		messageResponse, err := uc.JobMessage.SendFindJobDataByIdMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 			ID: jobPlafon.JobID.String(),
 		})
 
@@ -139,7 +158,7 @@ func (uc *JobPlafonUseCase) FindById(req *request.FindByIdJobPlafonRequest) (*re
 		return nil, err
 	}
 
-	jobResponse, err := uc.JobMessage.SendFindJobDataByIdMessage(request.SendFindJobByIDMessageRequest{
+	jobResponse, err := uc.JobMessage.SendFindJobDataByIdMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: jobPlafon.JobID.String(),
 	})
 
@@ -159,7 +178,7 @@ func (uc *JobPlafonUseCase) FindById(req *request.FindByIdJobPlafonRequest) (*re
 }
 
 func (uc *JobPlafonUseCase) FindByJobId(payload *request.FindByJobIdJobPlafonRequest) (*response.FindByJobIdJobPlafonResponse, error) {
-	messageResponse, err := uc.JobPlafonMessage.SendCheckJobExistMessage(request.CheckJobExistMessageRequest{
+	messageResponse, err := uc.JobPlafonMessage.SendCheckJobExistMessage(uc.ctx(), request.CheckJobExistMessageRequest{
 		ID: payload.JobID,
 	})
 
@@ -179,7 +198,7 @@ func (uc *JobPlafonUseCase) FindByJobId(payload *request.FindByJobIdJobPlafonReq
 		return nil, err
 	}
 
-	jobResponse, err := uc.JobMessage.SendFindJobDataByIdMessage(request.SendFindJobByIDMessageRequest{
+	jobResponse, err := uc.JobMessage.SendFindJobDataByIdMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: jobPlafon.JobID.String(),
 	})
 
@@ -211,7 +230,7 @@ func (uc *JobPlafonUseCase) Create(payload *request.CreateJobPlafonRequest) (*re
 		return nil, errors.New("job plafon already exist")
 	}
 
-	messageResponse, err := uc.JobPlafonMessage.SendCheckJobExistMessage(request.CheckJobExistMessageRequest{
+	messageResponse, err := uc.JobPlafonMessage.SendCheckJobExistMessage(uc.ctx(), request.CheckJobExistMessageRequest{
 		ID: payload.JobID,
 	})
 
@@ -226,11 +245,18 @@ func (uc *JobPlafonUseCase) Create(payload *request.CreateJobPlafonRequest) (*re
 	}
 
 	jobPlafonEntity := entity.JobPlafon{
+		ID:     uuid.New(),
 		JobID:  func(id string) *uuid.UUID { u := uuid.MustParse(id); return &u }(payload.JobID),
 		Plafon: payload.Plafon,
 	}
 
-	jobPlafon, err := uc.JobPlafonRepository.Create(&jobPlafonEntity)
+	changedEvent, err := uc.changedEvent(messaging.JobPlafonChangeCreated, &jobPlafonEntity)
+	if err != nil {
+		uc.Log.Errorf("[JobPlafonUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	jobPlafon, err := uc.JobPlafonRepository.Create(&jobPlafonEntity, changedEvent)
 	if err != nil {
 		uc.Log.Errorf("[JobPlafonUseCase.Create] " + err.Error())
 		return nil, err
@@ -242,7 +268,7 @@ func (uc *JobPlafonUseCase) Create(payload *request.CreateJobPlafonRequest) (*re
 }
 
 func (uc *JobPlafonUseCase) Update(payload *request.UpdateJobPlafonRequest) (*response.UpdateJobPlafonResponse, error) {
-	messageResponse, err := uc.JobPlafonMessage.SendCheckJobExistMessage(request.CheckJobExistMessageRequest{
+	messageResponse, err := uc.JobPlafonMessage.SendCheckJobExistMessage(uc.ctx(), request.CheckJobExistMessageRequest{
 		ID: payload.JobID,
 	})
 
@@ -261,7 +287,13 @@ func (uc *JobPlafonUseCase) Update(payload *request.UpdateJobPlafonRequest) (*re
 		JobID:  func(id string) *uuid.UUID { u := uuid.MustParse(id); return &u }(payload.JobID),
 		Plafon: payload.Plafon,
 	}
-	jobPlafon, err := uc.JobPlafonRepository.Update(jobPlafonEntity)
+	changedEvent, err := uc.changedEvent(messaging.JobPlafonChangeUpdated, jobPlafonEntity)
+	if err != nil {
+		uc.Log.Errorf("[JobPlafonUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	jobPlafon, err := uc.JobPlafonRepository.Update(jobPlafonEntity, changedEvent)
 	if err != nil {
 		uc.Log.Errorf("[JobPlafonUseCase.Update] " + err.Error())
 		return nil, err
@@ -273,7 +305,24 @@ func (uc *JobPlafonUseCase) Update(payload *request.UpdateJobPlafonRequest) (*re
 }
 
 func (uc *JobPlafonUseCase) Delete(request *request.DeleteJobPlafonRequest) error {
-	err := uc.JobPlafonRepository.Delete(uuid.MustParse(request.ID))
+	jobPlafon, err := uc.JobPlafonRepository.FindById(uuid.MustParse(request.ID))
+	if err != nil {
+		uc.Log.Errorf("[JobPlafonUseCase.Delete] " + err.Error())
+		return err
+	}
+
+	if jobPlafon == nil {
+		uc.Log.Errorf("[JobPlafonUseCase.Delete] Job plafon not found")
+		return errors.New("job plafon not found")
+	}
+
+	changedEvent, err := uc.changedEvent(messaging.JobPlafonChangeDeleted, jobPlafon)
+	if err != nil {
+		uc.Log.Errorf("[JobPlafonUseCase.Delete] " + err.Error())
+		return err
+	}
+
+	err = uc.JobPlafonRepository.Delete(jobPlafon.ID, changedEvent)
 	if err != nil {
 		uc.Log.Errorf("[JobPlafonUseCase.Delete] " + err.Error())
 		return err
@@ -282,6 +331,19 @@ func (uc *JobPlafonUseCase) Delete(request *request.DeleteJobPlafonRequest) erro
 	return nil
 }
 
+// changedEvent builds the job_plafon.changed event of a change to jobPlafon.
+func (uc *JobPlafonUseCase) changedEvent(change messaging.JobPlafonChange, jobPlafon *entity.JobPlafon) ([]entity.OutboxMessage, error) {
+	event, err := newDomainEventMessage(messaging.DomainEventJobPlafonChanged, messaging.JobPlafonChangedData{
+		Change:    change,
+		JobPlafon: jobPlafon,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []entity.OutboxMessage{*event}, nil
+}
+
 func JobPlafonUseCaseFactory(log *logrus.Logger) IJobPlafonUseCase {
 	repo := repository.JobPlafonRepositoryFactory(log)
 	message := messaging.JobPlafonMessageFactory(log)
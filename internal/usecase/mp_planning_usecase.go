@@ -1,18 +1,26 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/export"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/service"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -20,6 +28,7 @@ import (
 
 type IMPPlanningUseCase interface {
 	FindAllHeadersPaginated(request *request.FindAllHeadersPaginatedMPPlanningRequest) (*response.FindAllHeadersPaginatedMPPlanningResponse, error)
+	ExportHeaders(request *request.ExportMPPlanningHeadersRequest, format export.Format, w io.Writer) error
 	CountMPPlanningHeaderByMPPPeriodIDAndApproverType(mppPeriodID uuid.UUID, approverType string) (int64, error)
 	FindHeaderBySomething(request *request.MPPlanningHeaderRequest) (*response.MPPlanningHeaderResponse, error)
 	GetHeadersBySomething(request *request.MPPlanningHeaderRequest) ([]*response.MPPlanningHeaderResponse, error)
@@ -29,6 +38,10 @@ type IMPPlanningUseCase interface {
 	FindAllHeadersForBatchPaginated(request *request.FindAllHeadersPaginatedMPPlanningRequest) (*response.OrganizationLocationPaginatedResponse, error)
 	FindAllHeadersGroupedApproverPaginated(request *request.FindAllHeadersPaginatedMPPlanningRequest) (*response.OrganizationLocationPaginatedResponse, error)
 	FindById(request *request.FindHeaderByIdMPPlanningRequest) (*response.FindByIdMPPlanningResponse, error)
+	FindHeaderOrganizationID(headerID uuid.UUID) (*uuid.UUID, error)
+	FindLineOrganizationID(lineID uuid.UUID) (*uuid.UUID, error)
+	FindLineImportOrganizationID(importID uuid.UUID) (*uuid.UUID, error)
+	FindApprovalHistoryOrganizationID(approvalHistoryID uuid.UUID) (*uuid.UUID, error)
 	FindAllHeadersByStatusAndMPPeriodID(status entity.MPPlaningStatus, mppPeriodID uuid.UUID) ([]*response.MPPlanningHeaderResponse, error)
 	GetHeadersByMPPeriodCompletePaginated(organizationLocationID uuid.UUID, page, pageSize int) ([]*response.MPPlanningHeaderResponse, int64, error)
 	GenerateDocumentNumber(dateNow time.Time) (string, error)
@@ -48,38 +61,73 @@ type IMPPlanningUseCase interface {
 	UpdateLine(request *request.UpdateLineMPPlanningLineRequest) (*response.UpdateMPPlanningLineResponse, error)
 	DeleteLine(request *request.DeleteLineMPPlanningLineRequest) error
 	CreateOrUpdateBatchLineMPPlanningLines(request *request.CreateOrUpdateBatchLineMPPlanningLinesRequest) error
+	ImportLines(request *request.ImportMPPlanningLinesRequest, fileName string, file io.ReaderAt, size int64) (*response.MPPlanningLineImportResponse, error)
+	FindLineImportById(id uuid.UUID) (*response.MPPlanningLineImportResponse, error)
+	ConfirmLineImport(request *request.ConfirmMPPlanningLineImportRequest) (*response.MPPlanningLineImportResponse, error)
+	CopyFromPeriod(request *request.CopyMPPlanningFromPeriodRequest) (*response.CopyMPPlanningFromPeriodResponse, error)
+	WithContext(ctx context.Context) IMPPlanningUseCase
 }
 
 type MPPlanningUseCase struct {
-	Viper                *viper.Viper
-	Log                  *logrus.Logger
-	MPPlanningRepository repository.IMPPlanningRepository
-	JobPlafonRepository  repository.IJobPlafonRepository
-	OrganizationMessage  messaging.IOrganizationMessage
-	JobPlafonMessage     messaging.IJobPlafonMessage
-	UserMessage          messaging.IUserMessage
-	EmployeeMessage      messaging.IEmployeeMessage
-	MPPlanningDTO        dto.IMPPlanningDTO
-	MPPPeriodRepo        repository.IMPPPeriodRepository
-	JobMessage           messaging.IJobMessage
+	boundContext
+	Viper                  *viper.Viper
+	Log                    *logrus.Logger
+	MPPlanningRepository   repository.IMPPlanningRepository
+	JobPlafonRepository    repository.IJobPlafonRepository
+	OrganizationMessage    messaging.IOrganizationMessage
+	JobPlafonMessage       messaging.IJobPlafonMessage
+	UserMessage            messaging.IUserMessage
+	EmployeeMessage        messaging.IEmployeeMessage
+	MPPlanningDTO          dto.IMPPlanningDTO
+	MPPPeriodRepo          repository.IMPPPeriodRepository
+	JobMessage             messaging.IJobMessage
+	Workflow               workflow.IApprovalWorkflow
+	ApprovalChainRepo      repository.IApprovalChainRepository
+	ApprovalDelegationRepo repository.IApprovalDelegationRepository
+	NotificationService    service.INotificationService
+	PortalDataHelper       helper.IPortalDataHelper
+	PlafonService          service.IPlafonService
+	LineImportRepository   repository.IMPPlanningLineImportRepository
+	DocumentNumberService  service.IDocumentNumberService
 }
 
-func NewMPPlanningUseCase(viper *viper.Viper, log *logrus.Logger, repo repository.IMPPlanningRepository, message messaging.IOrganizationMessage, jpm messaging.IJobPlafonMessage, um messaging.IUserMessage, em messaging.IEmployeeMessage, jpr repository.IJobPlafonRepository, mpPlanningDTO dto.IMPPlanningDTO, mppPeriodRepo repository.IMPPPeriodRepository, jobMessage messaging.IJobMessage) IMPPlanningUseCase {
+func NewMPPlanningUseCase(viper *viper.Viper, log *logrus.Logger, repo repository.IMPPlanningRepository, message messaging.IOrganizationMessage, jpm messaging.IJobPlafonMessage, um messaging.IUserMessage, em messaging.IEmployeeMessage, jpr repository.IJobPlafonRepository, mpPlanningDTO dto.IMPPlanningDTO, mppPeriodRepo repository.IMPPPeriodRepository, jobMessage messaging.IJobMessage, approvalWorkflow workflow.IApprovalWorkflow, approvalChainRepo repository.IApprovalChainRepository, approvalDelegationRepo repository.IApprovalDelegationRepository, notificationService service.INotificationService, portalDataHelper helper.IPortalDataHelper, plafonService service.IPlafonService, lineImportRepository repository.IMPPlanningLineImportRepository, documentNumberService service.IDocumentNumberService) IMPPlanningUseCase {
 	return &MPPlanningUseCase{
-		Viper:                viper,
-		Log:                  log,
-		MPPlanningRepository: repo,
-		OrganizationMessage:  message,
-		JobPlafonMessage:     jpm,
-		UserMessage:          um,
-		EmployeeMessage:      em,
-		JobPlafonRepository:  jpr,
-		MPPlanningDTO:        mpPlanningDTO,
-		MPPPeriodRepo:        mppPeriodRepo,
-		JobMessage:           jobMessage,
+		Viper:                  viper,
+		Log:                    log,
+		MPPlanningRepository:   repo,
+		OrganizationMessage:    message,
+		JobPlafonMessage:       jpm,
+		UserMessage:            um,
+		EmployeeMessage:        em,
+		JobPlafonRepository:    jpr,
+		MPPlanningDTO:          mpPlanningDTO,
+		MPPPeriodRepo:          mppPeriodRepo,
+		JobMessage:             jobMessage,
+		Workflow:               approvalWorkflow,
+		ApprovalChainRepo:      approvalChainRepo,
+		ApprovalDelegationRepo: approvalDelegationRepo,
+		NotificationService:    notificationService,
+		PortalDataHelper:       portalDataHelper,
+		PlafonService:          plafonService,
+		LineImportRepository:   lineImportRepository,
+		DocumentNumberService:  documentNumberService,
 	}
 }
 
+// WithContext is the use case with its repositories bound to ctx, so what it
+// changes is audited under the actor of the request.
+func (uc *MPPlanningUseCase) WithContext(ctx context.Context) IMPPlanningUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	scoped.MPPlanningRepository = uc.MPPlanningRepository.WithContext(ctx)
+	scoped.JobPlafonRepository = uc.JobPlafonRepository.WithContext(ctx)
+	scoped.MPPPeriodRepo = uc.MPPPeriodRepo.WithContext(ctx)
+	scoped.LineImportRepository = uc.LineImportRepository.WithContext(ctx)
+	scoped.PlafonService = uc.PlafonService.WithContext(ctx)
+	return &scoped
+}
+
 func (uc *MPPlanningUseCase) FindAllHeadersPaginated(req *request.FindAllHeadersPaginatedMPPlanningRequest) (*response.FindAllHeadersPaginatedMPPlanningResponse, error) {
 	mpPlanningHeaders, total, err := uc.MPPlanningRepository.FindAllHeadersPaginated(req.Page, req.PageSize, req.Search, req.ApproverType, req.OrgLocationID, req.OrgID, entity.MPPlaningStatus(req.Status), req.RequestorID)
 	if err != nil {
@@ -87,123 +135,9 @@ func (uc *MPPlanningUseCase) FindAllHeadersPaginated(req *request.FindAllHeaders
 		return nil, err
 	}
 
-	for i, header := range *mpPlanningHeaders {
-		// Fetch organization names using RabbitMQ
-		messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
-			ID: header.OrganizationID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			return nil, err
-		}
-		header.OrganizationName = messageResponse.Name
-
-		// Fetch emp organization names using RabbitMQ
-		message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
-			ID: header.EmpOrganizationID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			return nil, err
-		}
-		header.EmpOrganizationName = message2Response.Name
-
-		// Fetch job names using RabbitMQ
-		messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
-			ID: header.JobID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			// return nil, err
-			header.JobName = ""
-		} else {
-			header.JobName = messageJobResposne.Name
-		}
-
-		messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
-			ID: header.RequestorID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			// return nil, err
-			header.RequestorName = ""
-		} else {
-			header.RequestorName = messageEmployeeResponse.Name
-		}
-
-		// fetch organization location names using RabbitMQ
-		messageOrgLocResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
-			ID: header.OrganizationLocationID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			return nil, err
-		}
-		header.OrganizationLocationName = messageOrgLocResponse.Name
-
-		if header.ApproverManagerID != nil {
-			messageApprManagerResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
-				ID: header.ApproverManagerID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-				// return nil, err
-				header.ApproverManagerName = ""
-			} else {
-				header.ApproverManagerName = messageApprManagerResponse.Name
-			}
-		}
-
-		if header.ApproverRecruitmentID != nil {
-			messageApprRecruitmentResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
-				ID: header.ApproverRecruitmentID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-				// return nil, err
-				header.ApproverRecruitmentName = ""
-			} else {
-				header.ApproverRecruitmentName = messageApprRecruitmentResponse.Name
-			}
-		}
-
-		for i, line := range *&header.MPPlanningLines {
-			// Fetch organization location names using RabbitMQ
-			messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
-				ID: line.OrganizationLocationID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-				// return nil, err
-				line.OrganizationLocationName = ""
-			} else {
-				line.OrganizationLocationName = messageResponse.Name
-			}
-
-			// Fetch job level names using RabbitMQ
-			message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
-				ID: line.JobLevelID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-				return nil, err
-			}
-			line.JobLevelName = message2Response.Name
-
-			// Fetch job names using RabbitMQ
-			messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
-				ID: line.JobID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-				return nil, err
-			}
-			line.JobName = messageJobResposne.Name
-
-			header.MPPlanningLines[i] = line
-		}
-
-		(*mpPlanningHeaders)[i] = header
+	if err := uc.PortalDataHelper.EnrichMPPlanningHeaders(uc.ctx(), *mpPlanningHeaders); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
+		return nil, err
 	}
 
 	return &response.FindAllHeadersPaginatedMPPlanningResponse{
@@ -225,6 +159,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersPaginated(req *request.FindAllHeaders
 					RecommendedBy:            header.RecommendedBy,
 					ApprovedBy:               header.ApprovedBy,
 					RequestorID:              header.RequestorID,
+					Version:                  header.Version,
 					NotesAttach:              header.NotesAttach,
 					OrganizationName:         header.OrganizationName,
 					EmpOrganizationName:      header.EmpOrganizationName,
@@ -235,6 +170,8 @@ func (uc *MPPlanningUseCase) FindAllHeadersPaginated(req *request.FindAllHeaders
 					ApproverRecruitmentID:    header.ApproverRecruitmentID,
 					NotesManager:             header.NotesManager,
 					NotesRecruitment:         header.NotesRecruitment,
+					NextApproverID:           header.NextApproverID,
+					NextApproverLevel:        header.NextApproverLevel,
 					OrganizationLocationName: header.OrganizationLocationName,
 					ApproverManagerName:      header.ApproverManagerName,
 					ApproverRecruitmentName:  header.ApproverRecruitmentName,
@@ -266,6 +203,9 @@ func (uc *MPPlanningUseCase) FindAllHeadersPaginated(req *request.FindAllHeaders
 								RemainingBalanceMT:       line.RemainingBalanceMT,
 								RecruitPH:                line.RecruitPH,
 								RecruitMT:                line.RecruitMT,
+								IsOverPlafon:             line.IsOverPlafon,
+								PlafonOverrideStatus:     line.PlafonOverrideStatus,
+								Version:                  line.Version,
 								OrganizationLocationName: line.OrganizationLocationName,
 								JobLevelName:             line.JobLevelName,
 								JobName:                  line.JobName,
@@ -281,6 +221,125 @@ func (uc *MPPlanningUseCase) FindAllHeadersPaginated(req *request.FindAllHeaders
 	}, nil
 }
 
+// prefetchPortalData loads the jobs and organization locations of the headers
+// and their lines into the lookup cache with one message per kind, instead of
+// one message per row. A failed prefetch only means the rows look them up one
+// by one.
+func (uc *MPPlanningUseCase) prefetchPortalData(mpPlanningHeaders []entity.MPPlanningHeader) {
+	var jobIDs, orgLocationIDs []string
+	for _, header := range mpPlanningHeaders {
+		if header.JobID != nil {
+			jobIDs = append(jobIDs, header.JobID.String())
+		}
+		if header.OrganizationLocationID != nil {
+			orgLocationIDs = append(orgLocationIDs, header.OrganizationLocationID.String())
+		}
+		for _, line := range header.MPPlanningLines {
+			if line.JobID != nil {
+				jobIDs = append(jobIDs, line.JobID.String())
+			}
+			if line.OrganizationLocationID != nil {
+				orgLocationIDs = append(orgLocationIDs, line.OrganizationLocationID.String())
+			}
+		}
+	}
+
+	if err := messaging.PrefetchJobs(uc.ctx(), uc.JobMessage, jobIDs); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.prefetchPortalData] " + err.Error())
+	}
+	if err := messaging.PrefetchOrganizationLocations(uc.ctx(), uc.OrganizationMessage, orgLocationIDs); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.prefetchPortalData] " + err.Error())
+	}
+}
+
+// exportBatchSize is how many headers an export reads and enriches at a time.
+const exportBatchSize = 500
+
+var mpPlanningExportColumns = []string{
+	"Document Number", "Document Date", "Period", "Status",
+	"Organization", "Organization Location", "Employee Organization", "Job", "Requestor",
+	"Total Recruit", "Total Promote",
+	"Line Organization Location", "Line Job Level", "Line Job",
+	"Existing", "Recruit", "Suggested Recruit", "Promotion", "Total",
+	"Recruit MT", "Remaining Balance MT", "Recruit PH", "Remaining Balance PH",
+}
+
+// ExportHeaders writes the planning headers of the list, one row per line, to
+// w. A header without lines still gets a row.
+func (uc *MPPlanningUseCase) ExportHeaders(req *request.ExportMPPlanningHeadersRequest, format export.Format, w io.Writer) error {
+	writer, err := export.NewWriter(format, w, "MP Plannings")
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteRow(mpPlanningExportColumns); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ExportHeaders] " + err.Error())
+		return err
+	}
+
+	err = uc.MPPlanningRepository.FindAllHeadersInBatches(req.Search, req.ApproverType, req.OrgLocationID, req.OrgID, entity.MPPlaningStatus(req.Status), req.RequestorID, req.MPPPeriodID, exportBatchSize, func(mpPlanningHeaders []entity.MPPlanningHeader) error {
+		if err := uc.PortalDataHelper.EnrichMPPlanningHeaders(uc.ctx(), mpPlanningHeaders); err != nil {
+			return err
+		}
+
+		for _, header := range mpPlanningHeaders {
+			headerCells := []string{
+				header.DocumentNumber,
+				export.Date(&header.DocumentDate),
+				header.MPPPeriod.Title,
+				string(header.Status),
+				header.OrganizationName,
+				header.OrganizationLocationName,
+				header.EmpOrganizationName,
+				header.JobName,
+				header.RequestorName,
+				export.Float(header.TotalRecruit),
+				export.Float(header.TotalPromote),
+			}
+
+			if len(header.MPPlanningLines) == 0 {
+				if err := writer.WriteRow(headerCells); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, line := range header.MPPlanningLines {
+				row := append(append([]string{}, headerCells...),
+					line.OrganizationLocationName,
+					line.JobLevelName,
+					line.JobName,
+					export.Int(line.Existing),
+					export.Int(line.Recruit),
+					export.Int(line.SuggestedRecruit),
+					export.Int(line.Promotion),
+					export.Int(line.Total),
+					export.Int(line.RecruitMT),
+					export.Int(line.RemainingBalanceMT),
+					export.Int(line.RecruitPH),
+					export.Int(line.RemainingBalancePH),
+				)
+				if err := writer.WriteRow(row); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ExportHeaders] " + err.Error())
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ExportHeaders] " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (uc *MPPlanningUseCase) CountMPPlanningHeaderByMPPPeriodIDAndApproverType(mppPeriodID uuid.UUID, approverType string) (int64, error) {
 	total, err := uc.MPPlanningRepository.CountMPPlanningHeaderByMPPPeriodIDAndApproverType(mppPeriodID, approverType)
 	if err != nil {
@@ -310,7 +369,7 @@ func (uc *MPPlanningUseCase) FindJobsByHeaderID(headerID uuid.UUID) (*[]response
 	}
 
 	// get jobs by ids
-	jobs, err := uc.JobMessage.SendFindAllJobsIDsMessage(jobIDs)
+	jobs, err := uc.JobMessage.SendFindAllJobsIDsMessage(uc.ctx(), jobIDs)
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.FindJobsByHeaderID] " + err.Error())
 		return nil, err
@@ -338,7 +397,7 @@ func (uc *MPPlanningUseCase) FindOrganizationLocationsByHeaderID(headerID uuid.U
 	}
 
 	// get organization locations by ids
-	orgLocs, err := uc.OrganizationMessage.SendFindAllOrganizationLocationsMessage(orgLocIDs)
+	orgLocs, err := uc.OrganizationMessage.SendFindAllOrganizationLocationsMessage(uc.ctx(), orgLocIDs)
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.FindOrganizationLocationsByHeaderID] " + err.Error())
 		return nil, err
@@ -385,7 +444,7 @@ func (uc *MPPlanningUseCase) FindHeaderBySomething(req *request.MPPlanningHeader
 		return nil, errors.New("MP Planning Header not found")
 	}
 
-	return uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(header), nil
+	return uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), header), nil
 }
 
 func (uc *MPPlanningUseCase) GetHeadersBySomething(req *request.MPPlanningHeaderRequest) ([]*response.MPPlanningHeaderResponse, error) {
@@ -429,7 +488,7 @@ func (uc *MPPlanningUseCase) GetHeadersBySomething(req *request.MPPlanningHeader
 		return nil, errors.New("MP Planning Header not found")
 	}
 
-	return uc.MPPlanningDTO.ConvertMPPlanningHeaderEntititesToResponse(headers), nil
+	return uc.MPPlanningDTO.ConvertMPPlanningHeaderEntititesToResponse(uc.ctx(), headers), nil
 }
 
 func (uc *MPPlanningUseCase) GetHeadersByMPPeriodCompletePaginated(organizationLocationID uuid.UUID, page, pageSize int) ([]*response.MPPlanningHeaderResponse, int64, error) {
@@ -448,9 +507,13 @@ func (uc *MPPlanningUseCase) GetHeadersByMPPeriodCompletePaginated(organizationL
 		uc.Log.Errorf("[MPPlanningUseCase.GetHeadersByMPPeriodComplete] " + err.Error())
 		return nil, 0, err
 	}
+	// the DTO looks up the names left empty one by one
+	if err := uc.PortalDataHelper.EnrichMPPlanningHeaders(uc.ctx(), *mpPlanningHeaders); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.GetHeadersByMPPeriodComplete] " + err.Error())
+	}
 	var responseHeaders []*response.MPPlanningHeaderResponse
 	for _, header := range *mpPlanningHeaders {
-		responseHeaders = append(responseHeaders, uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(&header))
+		responseHeaders = append(responseHeaders, uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), &header))
 	}
 	return responseHeaders, total, nil
 }
@@ -464,7 +527,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 
 	for i, header := range *mpPlanningHeaders {
 		// Fetch organization names using RabbitMQ
-		messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+		messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 			ID: header.OrganizationID.String(),
 		})
 		if err != nil {
@@ -474,7 +537,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 		header.OrganizationName = messageResponse.Name
 
 		// Fetch emp organization names using RabbitMQ
-		message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+		message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 			ID: header.EmpOrganizationID.String(),
 		})
 		if err != nil {
@@ -484,7 +547,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 		header.EmpOrganizationName = message2Response.Name
 
 		// Fetch job names using RabbitMQ
-		messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+		messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 			ID: header.JobID.String(),
 		})
 		if err != nil {
@@ -494,7 +557,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 		header.JobName = messageJobResposne.Name
 
 		// Fetch requestor names using RabbitMQ
-		messageUserResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		messageUserResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 			ID: header.RequestorID.String(),
 		})
 		if err != nil {
@@ -504,7 +567,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 		header.RequestorName = messageUserResponse.Name
 
 		// fetch organization location names using RabbitMQ
-		messageOrgLocResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+		messageOrgLocResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 			ID: header.OrganizationLocationID.String(),
 		})
 		if err != nil {
@@ -515,7 +578,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 
 		if header.ApproverManagerID != nil {
 			// fetch approver manager names using RabbitMQ
-			messageApprManagerResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+			messageApprManagerResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 				ID: header.ApproverManagerID.String(),
 			})
 			if err != nil {
@@ -527,7 +590,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 
 		if header.ApproverRecruitmentID != nil {
 			// fetch approver recruitment names using RabbitMQ
-			messageApprRecruitmentResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+			messageApprRecruitmentResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 				ID: header.ApproverRecruitmentID.String(),
 			})
 			if err != nil {
@@ -539,7 +602,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 
 		for i, line := range *&header.MPPlanningLines {
 			// Fetch organization location names using RabbitMQ
-			messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+			messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 				ID: line.OrganizationLocationID.String(),
 			})
 			if err != nil {
@@ -549,7 +612,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 			line.OrganizationLocationName = messageResponse.Name
 
 			// Fetch job level names using RabbitMQ
-			message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+			message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(uc.ctx(), request.SendFindJobLevelByIDMessageRequest{
 				ID: line.JobLevelID.String(),
 			})
 			if err != nil {
@@ -559,7 +622,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 			line.JobLevelName = message2Response.Name
 
 			// Fetch job names using RabbitMQ
-			messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+			messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 				ID: line.JobID.String(),
 			})
 			if err != nil {
@@ -576,7 +639,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByStatusAndMPPeriodID(status entity.M
 
 	var responseHeaders []*response.MPPlanningHeaderResponse
 	for _, header := range *mpPlanningHeaders {
-		responseHeaders = append(responseHeaders, uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(&header))
+		responseHeaders = append(responseHeaders, uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), &header))
 	}
 	return responseHeaders, nil
 }
@@ -625,7 +688,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersForBatchPaginated(req *request.FindAl
 		}
 	}
 	uc.Log.Infof("[MPPlanningUseCase.FindAllHeadersForBatchPaginated] includedIDs: %v", includedIDs)
-	orgLocs, err := uc.OrganizationMessage.SendFindOrganizationLocationsPaginatedMessage(req.Page, req.PageSize, req.Search, includedIDs, isNull, "")
+	orgLocs, err := uc.OrganizationMessage.SendFindOrganizationLocationsPaginatedMessage(uc.ctx(), req.Page, req.PageSize, req.Search, includedIDs, isNull, req.OrgID)
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersForBatchPaginated] " + err.Error())
 		return nil, err
@@ -653,7 +716,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersForBatchPaginated(req *request.FindAl
 
 		uc.Log.Info("Kontol")
 
-		orgLoc.MPPlanningHeader = uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(header)
+		orgLoc.MPPlanningHeader = uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), header)
 
 		orgLocs.OrganizationLocations[i] = orgLoc
 	}
@@ -715,7 +778,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersGroupedApproverPaginated(req *request
 	}
 	uc.Log.Info("Ini malah masuk sini")
 	uc.Log.Infof("[MPPlanningUseCase.FindAllHeadersGroupedApproverPaginated] includedIDs: %v", includedIDs)
-	orgLocs, err := uc.OrganizationMessage.SendFindOrganizationLocationsPaginatedMessage(req.Page, req.PageSize, req.Search, includedIDs, isNull, req.OrgID)
+	orgLocs, err := uc.OrganizationMessage.SendFindOrganizationLocationsPaginatedMessage(uc.ctx(), req.Page, req.PageSize, req.Search, includedIDs, isNull, req.OrgID)
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersGroupedApproverPaginated] " + err.Error())
 		return nil, err
@@ -739,7 +802,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersGroupedApproverPaginated(req *request
 			continue
 		}
 
-		orgLoc.MPPlanningHeader = uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(header)
+		orgLoc.MPPlanningHeader = uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), header)
 
 		orgLocs.OrganizationLocations[i] = orgLoc
 	}
@@ -759,6 +822,55 @@ func (uc *MPPlanningUseCase) UpdateStatusMPPlanningHeader(req *request.UpdateSta
 		return errors.New("MP Planning Header not found")
 	}
 
+	if err := workflow.CheckVersion(mpPlanningHeader.Version, req.Version); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
+		return err
+	}
+
+	transition, err := uc.Workflow.Transition(workflow.DocumentTypeMPPlanning, string(mpPlanningHeader.Status), string(req.Status), string(req.Level))
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] %v", err)
+		return err
+	}
+
+	// lines over their job plafon cannot move on until their override is approved
+	if req.Status != entity.MPPlaningStatusDraft && req.Status != entity.MPPlaningStatusReject {
+		for _, line := range mpPlanningHeader.MPPlanningLines {
+			if workflow.PlafonOverrideBlocks(line.IsOverPlafon, line.PlafonOverrideStatus) {
+				err := fmt.Errorf("%w: line %s is %s", workflow.ErrPlafonOverrideUnresolved, line.ID, line.PlafonOverrideStatus)
+				uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
+				return err
+			}
+		}
+	}
+
+	var approvalChain *entity.ApprovalChain
+	if mpPlanningHeader.OrganizationID != nil {
+		approvalChain, err = uc.ApprovalChainRepo.FindActiveByOrganizationIDAndDocumentType(*mpPlanningHeader.OrganizationID, entity.ApprovalChainDocumentTypeMPPlanning)
+		if err != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
+			return err
+		}
+	}
+
+	delegation, err := resolveApprovalDelegation(uc.ApprovalDelegationRepo, entity.ApprovalDelegationScopeMPPlanning, req.ApproverID, req.OnBehalfOfID, mpPlanningHeader.NextApproverID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
+		return err
+	}
+
+	actingFor := req.ApproverID
+	if delegation != nil {
+		actingFor = *delegation.DelegatorID
+	}
+
+	if approvalChain != nil && transition.Has(workflow.SideEffectRecordApprovalHistory) {
+		if err := workflow.CheckNextApprover(mpPlanningHeader.NextApproverLevel, mpPlanningHeader.NextApproverID, string(req.Level), actingFor); err != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
+			return err
+		}
+	}
+
 	// messageUserResponse, err := uc.UserMessage.SendFindUserByIDMessage(request.SendFindUserByIDMessageRequest{
 	// 	ID: req.ApproverID.String(),
 	// })
@@ -770,7 +882,7 @@ func (uc *MPPlanningUseCase) UpdateStatusMPPlanningHeader(req *request.UpdateSta
 	// 	uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] User not found")
 	// 	return errors.New("User not found")
 	// }
-	messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 		ID: req.ApproverID.String(),
 	})
 	if err != nil {
@@ -784,11 +896,13 @@ func (uc *MPPlanningUseCase) UpdateStatusMPPlanningHeader(req *request.UpdateSta
 
 	var approvalHistory *entity.MPPlanningApprovalHistory
 
-	if req.Status != entity.MPPlaningStatusSubmit && req.Status != entity.MPPlaningStatusDraft && req.Status != entity.MPPlaningStatusComplete {
+	if transition.Has(workflow.SideEffectRecordApprovalHistory) {
 		approvalHistory = &entity.MPPlanningApprovalHistory{
 			MPPlanningHeaderID: uuid.MustParse(req.ID),
 			ApproverID:         req.ApproverID,
 			ApproverName:       messageEmployeeResponse.Name,
+			OnBehalfOfID:       onBehalfOfID(delegation),
+			OnBehalfOfName:     onBehalfOfName(delegation),
 			Notes:              req.Notes,
 			Level:              string(req.Level),
 			Status: func() entity.MPPlanningApprovalHistoryStatus {
@@ -800,7 +914,34 @@ func (uc *MPPlanningUseCase) UpdateStatusMPPlanningHeader(req *request.UpdateSta
 		}
 	}
 
-	err = uc.MPPlanningRepository.UpdateStatusHeader(uuid.MustParse(req.ID), string(req.Status), req.ApprovedBy, approvalHistory)
+	nextApproverID := mpPlanningHeader.NextApproverID
+	var nextApproverLevel string
+	nextStep, moved := workflow.NextChainStep(approvalChain, transition, string(req.Level), "")
+	if moved {
+		nextApproverID = nil
+		if nextStep != nil {
+			nextApproverID = nextStep.ApproverID
+			nextApproverLevel = nextStep.Level
+		}
+	}
+
+	outboxMessages := uc.statusChangeNotifications(mpPlanningHeader, req.Status, nextApproverID, req.Notes)
+
+	if req.Status == entity.MPPlaningStatusApproved {
+		approvedEvent, err := uc.approvedEvent(mpPlanningHeader)
+		if err != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
+			return err
+		}
+		outboxMessages = append(outboxMessages, *approvedEvent)
+	}
+
+	var nextApprover *repository.NextApprover
+	if moved {
+		nextApprover = &repository.NextApprover{ID: nextApproverID, Level: nextApproverLevel}
+	}
+
+	err = uc.MPPlanningRepository.UpdateStatusHeader(uuid.MustParse(req.ID), req.Version, string(req.Status), req.ApprovedBy, nextApprover, approvalHistory, outboxMessages)
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.UpdateStatusMPPlanningHeader] " + err.Error())
 		return err
@@ -831,6 +972,61 @@ func (uc *MPPlanningUseCase) UpdateStatusMPPlanningHeader(req *request.UpdateSta
 	return nil
 }
 
+// statusChangeNotifications builds the notifications of a status change: the
+// requestor learns what happened to the planning and the pending approver is
+// asked for approval while one is still needed. Failures are only logged so a
+// lost notification never blocks a status change.
+func (uc *MPPlanningUseCase) statusChangeNotifications(mpPlanningHeader *entity.MPPlanningHeader, status entity.MPPlaningStatus, nextApproverID *uuid.UUID, notes string) []entity.OutboxMessage {
+	document := service.NotificationDocument{
+		DocumentType:   entity.ApprovalChainDocumentTypeMPPlanning,
+		ID:             mpPlanningHeader.ID,
+		DocumentNumber: mpPlanningHeader.DocumentNumber,
+		Notes:          notes,
+	}
+
+	var outboxMessages []entity.OutboxMessage
+	var errs []error
+	collect := func(notifications []entity.OutboxMessage, err error) {
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		outboxMessages = append(outboxMessages, notifications...)
+	}
+
+	switch status {
+	case entity.MPPlaningStatusSubmit:
+		collect(uc.NotificationService.NewSubmittedNotification(uc.ctx(), document, mpPlanningHeader.RequestorID))
+		collect(uc.NotificationService.NewNeedApprovalNotification(uc.ctx(), document, nextApproverID))
+	case entity.MPPlanningStatusInProgress, entity.MPPlaningStatusNeedApproval:
+		collect(uc.NotificationService.NewNeedApprovalNotification(uc.ctx(), document, nextApproverID))
+	case entity.MPPlaningStatusApproved:
+		collect(uc.NotificationService.NewApprovedNotification(uc.ctx(), document, mpPlanningHeader.RequestorID))
+		if nextApproverID != nil {
+			collect(uc.NotificationService.NewNeedApprovalNotification(uc.ctx(), document, nextApproverID))
+		}
+	case entity.MPPlaningStatusReject:
+		collect(uc.NotificationService.NewRejectedNotification(uc.ctx(), document, mpPlanningHeader.RequestorID))
+	case entity.MPPlaningStatusComplete:
+		collect(uc.NotificationService.NewCompletedNotification(uc.ctx(), document, mpPlanningHeader.RequestorID))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.statusChangeNotifications] " + err.Error())
+	}
+
+	return outboxMessages
+}
+
+// approvedEvent builds the mp_planning.approved event with the snapshot of the
+// planning as it is once approved.
+func (uc *MPPlanningUseCase) approvedEvent(mpPlanningHeader *entity.MPPlanningHeader) (*entity.OutboxMessage, error) {
+	approved := *mpPlanningHeader
+	approved.Status = entity.MPPlaningStatusApproved
+
+	return newDomainEventMessage(messaging.DomainEventMPPlanningApproved, uc.MPPlanningDTO.ConvertMPPlanningHeaderEntityToResponse(uc.ctx(), &approved))
+}
+
 func (uc *MPPlanningUseCase) CountTotalApprovalHistoryByStatus(headerID uuid.UUID, status entity.MPPlanningApprovalHistoryStatus) (int64, error) {
 	exist, err := uc.MPPlanningRepository.FindHeaderById(headerID)
 	if err != nil {
@@ -854,7 +1050,7 @@ func (uc *MPPlanningUseCase) CountTotalApprovalHistoryByStatus(headerID uuid.UUI
 
 func (uc *MPPlanningUseCase) RejectStatusPartialMPPlanningHeader(req *request.UpdateStatusPartialMPPlanningHeaderRequest) error {
 	// check employee using rabbitmq
-	messageEmpResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	messageEmpResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 		ID: req.ApproverID.String(),
 	})
 	if err != nil {
@@ -888,7 +1084,9 @@ func (uc *MPPlanningUseCase) RejectStatusPartialMPPlanningHeader(req *request.Up
 			Status:             entity.MPPlanningApprovalHistoryStatusRejected,
 		}
 
-		err = uc.MPPlanningRepository.UpdateStatusHeader(uuid.MustParse(payload.ID), string(entity.MPPlaningStatusReject), approvalHistory.ApproverName, approvalHistory)
+		outboxMessages := uc.statusChangeNotifications(mpPlanningHeader, entity.MPPlaningStatusReject, nil, payload.Notes)
+
+		err = uc.MPPlanningRepository.UpdateStatusHeader(uuid.MustParse(payload.ID), mpPlanningHeader.Version, string(entity.MPPlaningStatusReject), approvalHistory.ApproverName, nil, approvalHistory, outboxMessages)
 		if err != nil {
 			uc.Log.Errorf("[MPPlanningUseCase.RejectStatusPartialMPPlanningHeader] " + err.Error())
 			return err
@@ -900,7 +1098,7 @@ func (uc *MPPlanningUseCase) RejectStatusPartialMPPlanningHeader(req *request.Up
 
 func (uc *MPPlanningUseCase) RejectStatusPartialMPPlanningHeaderUsingPT(req *request.UpdateStatusPartialMPPlanningHeaderRequest) error {
 	// check employee using rabbitmq
-	messageEmpResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	messageEmpResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 		ID: req.ApproverID.String(),
 	})
 	if err != nil {
@@ -935,7 +1133,9 @@ func (uc *MPPlanningUseCase) RejectStatusPartialMPPlanningHeaderUsingPT(req *req
 				Status:             entity.MPPlanningApprovalHistoryStatusRejected,
 			}
 
-			err = uc.MPPlanningRepository.UpdateStatusHeader(mpPlanningHeader.ID, string(entity.MPPlaningStatusReject), approvalHistory.ApproverName, approvalHistory)
+			outboxMessages := uc.statusChangeNotifications(&mpPlanningHeader, entity.MPPlaningStatusReject, nil, payload.Notes)
+
+			err = uc.MPPlanningRepository.UpdateStatusHeader(mpPlanningHeader.ID, mpPlanningHeader.Version, string(entity.MPPlaningStatusReject), approvalHistory.ApproverName, nil, approvalHistory, outboxMessages)
 			if err != nil {
 				uc.Log.Errorf("[MPPlanningUseCase.RejectStatusPartialMPPlanningHeader] " + err.Error())
 				return err
@@ -986,154 +1186,17 @@ func (uc *MPPlanningUseCase) GetPlanningApprovalHistoryAttachmentsByApprovalHist
 }
 
 func (uc *MPPlanningUseCase) FindAllHeadersByRequestorIDPaginated(requestorID uuid.UUID, req *request.FindAllHeadersPaginatedMPPlanningRequest) (*response.FindAllHeadersPaginatedMPPlanningResponse, error) {
-	mpPlanningHeaders, total, err := uc.MPPlanningRepository.FindAllHeadersByRequestorIDPaginated(requestorID, req.Page, req.PageSize, req.Search)
+	mpPlanningHeaders, total, err := uc.MPPlanningRepository.FindAllHeadersByRequestorIDPaginated(requestorID, req.Page, req.PageSize, req.Search, req.OrgID)
 
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersByRequestorIDPaginated] " + err.Error())
 		return nil, err
 	}
 
-	for i, header := range *mpPlanningHeaders {
-		// Fetch organization names using RabbitMQ
-		messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
-			ID: header.OrganizationID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersByRequestorIDPaginated Message] " + err.Error())
-			return nil, err
-		}
-		header.OrganizationName = messageResponse.Name
-
-		// Fetch emp organization names using RabbitMQ
-		message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
-			ID: header.EmpOrganizationID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersByRequestorIDPaginated Message] " + err.Error())
-			return nil, err
-		}
-		header.EmpOrganizationName = message2Response.Name
-
-		// Fetch job names using RabbitMQ
-		messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
-			ID: header.JobID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersByRequestorIDPaginated Message] " + err.Error())
-			return nil, err
-		}
-		header.JobName = messageJobResposne.Name
-
-		// Fetch requestor names using RabbitMQ
-		// messageUserResponse, err := uc.UserMessage.SendFindUserByIDMessage(request.SendFindUserByIDMessageRequest{
-		// 	ID: header.RequestorID.String(),
-		// })
-		// if err != nil {
-		// 	uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersByRequestorIDPaginated Message] " + err.Error())
-		// 	return nil, err
-		// }
-		// header.RequestorName = messageUserResponse.Name
-		messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
-			ID: header.RequestorID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersByRequestorIDPaginated Message] " + err.Error())
-			// return nil,
-			header.RequestorName = ""
-		} else {
-			header.RequestorName = messageEmployeeResponse.Name
-		}
-
-		// fetch organization location names using RabbitMQ
-		messageOrgLocResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
-			ID: header.OrganizationLocationID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			return nil, err
-		}
-		header.OrganizationLocationName = messageOrgLocResponse.Name
-
-		if header.ApproverManagerID != nil {
-			// fetch approver manager names using RabbitMQ
-			// messageApprManagerResponse, err := uc.UserMessage.SendFindUserByIDMessage(request.SendFindUserByIDMessageRequest{
-			// 	ID: header.ApproverManagerID.String(),
-			// })
-			// if err != nil {
-			// 	uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			// 	return nil, err
-			// }
-			// header.ApproverManagerName = messageApprManagerResponse.Name
-			messageApprManagerResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
-				ID: header.ApproverManagerID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-				// return nil, err
-				header.ApproverManagerName = ""
-			} else {
-				header.ApproverManagerName = messageApprManagerResponse.Name
-			}
-		}
-
-		if header.ApproverRecruitmentID != nil {
-			// fetch approver recruitment names using RabbitMQ
-			// messageApprRecruitmentResponse, err := uc.UserMessage.SendFindUserByIDMessage(request.SendFindUserByIDMessageRequest{
-			// 	ID: header.ApproverRecruitmentID.String(),
-			// })
-			// if err != nil {
-			// 	uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-			// 	return nil, err
-			// }
-			// header.ApproverRecruitmentName = messageApprRecruitmentResponse.Name
-			messageApprRecruitmentResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
-				ID: header.ApproverRecruitmentID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersPaginated Message] " + err.Error())
-				// return nil, err
-				header.ApproverRecruitmentName = ""
-			} else {
-				header.ApproverRecruitmentName = messageApprRecruitmentResponse.Name
-			}
-		}
-
-		for i, line := range *&header.MPPlanningLines {
-			// Fetch organization location names using RabbitMQ
-			messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
-				ID: line.OrganizationLocationID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-				return nil, err
-			}
-			line.OrganizationLocationName = messageResponse.Name
-
-			// Fetch job level names using RabbitMQ
-			message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
-				ID: line.JobLevelID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-				return nil, err
-			}
-			line.JobLevelName = message2Response.Name
-
-			// Fetch job names using RabbitMQ
-			messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
-				ID: line.JobID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-				return nil, err
-			}
-			line.JobName = messageJobResposne.Name
-
-			header.MPPlanningLines[i] = line
-		}
-
-		(*mpPlanningHeaders)[i] = header
-	}
+	if err := uc.PortalDataHelper.EnrichMPPlanningHeaders(uc.ctx(), *mpPlanningHeaders); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindAllHeadersByRequestorIDPaginated Message] " + err.Error())
+		return nil, err
+	}
 
 	return &response.FindAllHeadersPaginatedMPPlanningResponse{
 		MPPlanningHeaders: func() []*response.MPPlanningHeaderResponse {
@@ -1154,6 +1217,7 @@ func (uc *MPPlanningUseCase) FindAllHeadersByRequestorIDPaginated(requestorID uu
 					RecommendedBy:            header.RecommendedBy,
 					ApprovedBy:               header.ApprovedBy,
 					RequestorID:              header.RequestorID,
+					Version:                  header.Version,
 					NotesAttach:              header.NotesAttach,
 					OrganizationName:         header.OrganizationName,
 					EmpOrganizationName:      header.EmpOrganizationName,
@@ -1164,6 +1228,8 @@ func (uc *MPPlanningUseCase) FindAllHeadersByRequestorIDPaginated(requestorID uu
 					ApproverRecruitmentID:    header.ApproverRecruitmentID,
 					NotesManager:             header.NotesManager,
 					NotesRecruitment:         header.NotesRecruitment,
+					NextApproverID:           header.NextApproverID,
+					NextApproverLevel:        header.NextApproverLevel,
 					ApproverManagerName:      header.ApproverManagerName,
 					ApproverRecruitmentName:  header.ApproverRecruitmentName,
 					CreatedAt:                header.CreatedAt,
@@ -1194,6 +1260,9 @@ func (uc *MPPlanningUseCase) FindAllHeadersByRequestorIDPaginated(requestorID uu
 								RemainingBalanceMT:       line.RemainingBalanceMT,
 								RecruitPH:                line.RecruitPH,
 								RecruitMT:                line.RecruitMT,
+								IsOverPlafon:             line.IsOverPlafon,
+								PlafonOverrideStatus:     line.PlafonOverrideStatus,
+								Version:                  line.Version,
 								OrganizationLocationName: line.OrganizationLocationName,
 								JobLevelName:             line.JobLevelName,
 								JobName:                  line.JobName,
@@ -1224,18 +1293,77 @@ func (uc *MPPlanningUseCase) FindAllHeadersByRequestorIDPaginated(requestorID uu
 //		return "MPP/" + dateNow.Format("20060102") + "/" + fmt.Sprintf("%03d", len(*foundMpPlanningHeader)+1), nil
 //	}
 func (uc *MPPlanningUseCase) GenerateDocumentNumber(dateNow time.Time) (string, error) {
-	dateStr := dateNow.Format("2006-01-02")
-	highestNumber, err := uc.MPPlanningRepository.GetHighestDocumentNumberByDate(dateStr)
+	documentNumber, err := uc.DocumentNumberService.Preview(workflow.NumberSequenceMPPlanning, dateNow)
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.GenerateDocumentNumber] " + err.Error())
 		return "", err
 	}
 
-	newNumber := highestNumber + 1
-	documentNumber := fmt.Sprintf("MPP/%s/%03d", dateNow.Format("20060102"), newNumber)
 	return documentNumber, nil
 }
 
+// FindHeaderOrganizationID is the organization of the planning header, nil
+// when there is no such header.
+func (uc *MPPlanningUseCase) FindHeaderOrganizationID(headerID uuid.UUID) (*uuid.UUID, error) {
+	mpPlanningHeader, err := uc.MPPlanningRepository.FindHeaderById(headerID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindHeaderOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningHeader == nil {
+		return nil, nil
+	}
+
+	return mpPlanningHeader.OrganizationID, nil
+}
+
+// FindLineOrganizationID is the organization of the planning the line
+// belongs to, nil when there is no such line.
+func (uc *MPPlanningUseCase) FindLineOrganizationID(lineID uuid.UUID) (*uuid.UUID, error) {
+	mpPlanningLine, err := uc.MPPlanningRepository.FindLineById(lineID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindLineOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningLine == nil {
+		return nil, nil
+	}
+
+	return uc.FindHeaderOrganizationID(mpPlanningLine.MPPlanningHeaderID)
+}
+
+// FindLineImportOrganizationID is the organization of the planning the import is for.
+func (uc *MPPlanningUseCase) FindLineImportOrganizationID(importID uuid.UUID) (*uuid.UUID, error) {
+	mpPlanningLineImport, err := uc.LineImportRepository.FindById(importID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindLineImportOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningLineImport == nil {
+		return nil, nil
+	}
+
+	return uc.FindHeaderOrganizationID(mpPlanningLineImport.MPPlanningHeaderID)
+}
+
+// FindApprovalHistoryOrganizationID is the organization of the planning the approval history is of.
+func (uc *MPPlanningUseCase) FindApprovalHistoryOrganizationID(approvalHistoryID uuid.UUID) (*uuid.UUID, error) {
+	mppApprovalHistory, err := uc.MPPlanningRepository.FindApprovalHistoryById(approvalHistoryID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindApprovalHistoryOrganizationID] " + err.Error())
+		return nil, err
+	}
+
+	if mppApprovalHistory == nil {
+		return nil, nil
+	}
+
+	return uc.FindHeaderOrganizationID(mppApprovalHistory.MPPlanningHeaderID)
+}
+
 func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningRequest) (*response.FindByIdMPPlanningResponse, error) {
 	mpPlanningHeader, err := uc.MPPlanningRepository.FindHeaderById(uuid.MustParse(req.ID))
 	if err != nil {
@@ -1248,8 +1376,10 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 		return nil, errors.New("MP Planning Header not found")
 	}
 
+	uc.prefetchPortalData([]entity.MPPlanningHeader{*mpPlanningHeader})
+
 	// Fetch organization names using RabbitMQ
-	messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 		ID: mpPlanningHeader.OrganizationID.String(),
 	})
 	if err != nil {
@@ -1259,7 +1389,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 	mpPlanningHeader.OrganizationName = messageResponse.Name
 
 	// Fetch emp organization names using RabbitMQ
-	message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 		ID: mpPlanningHeader.EmpOrganizationID.String(),
 	})
 	if err != nil {
@@ -1269,7 +1399,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 	mpPlanningHeader.EmpOrganizationName = message2Response.Name
 
 	// Fetch job names using RabbitMQ
-	messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: mpPlanningHeader.JobID.String(),
 	})
 	if err != nil {
@@ -1278,7 +1408,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 	}
 	mpPlanningHeader.JobName = messageJobResposne.Name
 
-	messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 		ID: mpPlanningHeader.RequestorID.String(),
 	})
 	if err != nil {
@@ -1289,7 +1419,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 		mpPlanningHeader.RequestorName = messageEmployeeResponse.Name
 	}
 
-	messageOrgLocResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	messageOrgLocResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: mpPlanningHeader.OrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -1300,7 +1430,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 
 	// fetch approved by rabbit mq
 	if mpPlanningHeader.ApprovedBy != "" {
-		messageApprovedByResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		messageApprovedByResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 			ID: mpPlanningHeader.ApprovedBy,
 		})
 		if err != nil {
@@ -1313,7 +1443,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 
 	// fetch approver manager names using RabbitMQ
 	if mpPlanningHeader.ApproverManagerID != nil {
-		messageApprManagerResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		messageApprManagerResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 			ID: mpPlanningHeader.ApproverManagerID.String(),
 		})
 		if err != nil {
@@ -1327,7 +1457,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 
 	// fetch approver recruitment names using RabbitMQ
 	if mpPlanningHeader.ApproverRecruitmentID != nil {
-		messageApprRecruitmentResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+		messageApprRecruitmentResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 			ID: mpPlanningHeader.ApproverRecruitmentID.String(),
 		})
 		if err != nil {
@@ -1341,7 +1471,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 
 	for i, line := range *&mpPlanningHeader.MPPlanningLines {
 		// Fetch organization location names using RabbitMQ
-		messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+		messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 			ID: line.OrganizationLocationID.String(),
 		})
 		if err != nil {
@@ -1351,7 +1481,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 		line.OrganizationLocationName = messageResponse.Name
 
 		// Fetch job level names using RabbitMQ
-		message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+		message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(uc.ctx(), request.SendFindJobLevelByIDMessageRequest{
 			ID: line.JobLevelID.String(),
 		})
 		if err != nil {
@@ -1362,7 +1492,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 		line.JobLevel = int(message2Response.Level)
 
 		// Fetch job names using RabbitMQ
-		messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+		messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 			ID: line.JobID.String(),
 		})
 		if err != nil {
@@ -1409,6 +1539,7 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 		RecommendedBy:            mpPlanningHeader.RecommendedBy,
 		ApprovedBy:               mpPlanningHeader.ApprovedBy,
 		RequestorID:              mpPlanningHeader.RequestorID,
+		Version:                  mpPlanningHeader.Version,
 		NotesAttach:              mpPlanningHeader.NotesAttach,
 		OrganizationName:         mpPlanningHeader.OrganizationName,
 		EmpOrganizationName:      mpPlanningHeader.EmpOrganizationName,
@@ -1423,6 +1554,8 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 		ApproverRecruitmentID:    mpPlanningHeader.ApproverRecruitmentID,
 		NotesManager:             mpPlanningHeader.NotesManager,
 		NotesRecruitment:         mpPlanningHeader.NotesRecruitment,
+		NextApproverID:           mpPlanningHeader.NextApproverID,
+		NextApproverLevel:        mpPlanningHeader.NextApproverLevel,
 		ApproverManagerName:      mpPlanningHeader.ApproverManagerName,
 		ApproverRecruitmentName:  mpPlanningHeader.ApproverRecruitmentName,
 		ApproverCEOName:          mpPlanningHeader.ApproverCEOName,
@@ -1456,6 +1589,9 @@ func (uc *MPPlanningUseCase) FindById(req *request.FindHeaderByIdMPPlanningReque
 					RemainingBalanceMT:       line.RemainingBalanceMT,
 					RecruitPH:                line.RecruitPH,
 					RecruitMT:                line.RecruitMT,
+					IsOverPlafon:             line.IsOverPlafon,
+					PlafonOverrideStatus:     line.PlafonOverrideStatus,
+					Version:                  line.Version,
 					OrganizationLocationName: line.OrganizationLocationName,
 					JobLevelName:             line.JobLevelName,
 					JobLevel:                 line.JobLevel,
@@ -1480,7 +1616,7 @@ func (uc *MPPlanningUseCase) FindHeaderByMPPPeriodId(req *request.FindHeaderByMP
 	}
 
 	// Fetch organization names using RabbitMQ
-	messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	messageResponse, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 		ID: mpPlanningHeader.OrganizationID.String(),
 	})
 	if err != nil {
@@ -1490,7 +1626,7 @@ func (uc *MPPlanningUseCase) FindHeaderByMPPPeriodId(req *request.FindHeaderByMP
 	mpPlanningHeader.OrganizationName = messageResponse.Name
 
 	// Fetch emp organization names using RabbitMQ
-	message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	message2Response, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 		ID: mpPlanningHeader.EmpOrganizationID.String(),
 	})
 	if err != nil {
@@ -1500,7 +1636,7 @@ func (uc *MPPlanningUseCase) FindHeaderByMPPPeriodId(req *request.FindHeaderByMP
 	mpPlanningHeader.EmpOrganizationName = message2Response.Name
 
 	// Fetch job names using RabbitMQ
-	messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: mpPlanningHeader.JobID.String(),
 	})
 	if err != nil {
@@ -1517,7 +1653,7 @@ func (uc *MPPlanningUseCase) FindHeaderByMPPPeriodId(req *request.FindHeaderByMP
 	// 	uc.Log.Errorf("[MPPlanningUseCase.FindHeaderByMPPPeriodId Message] " + err.Error())
 	// 	return nil, err
 	// }
-	messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	messageEmployeeResponse, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 		ID: mpPlanningHeader.RequestorID.String(),
 	})
 	if err != nil {
@@ -1574,7 +1710,7 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 	}
 
 	// Check if organization exist
-	orgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	orgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 		ID: req.OrganizationID.String(),
 	})
 
@@ -1589,7 +1725,7 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 	}
 
 	// Check if emp organization exist
-	empOrgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	empOrgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 		ID: req.EmpOrganizationID.String(),
 	})
 
@@ -1604,7 +1740,7 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 	}
 
 	// Check if job exist
-	jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: req.JobID.String(),
 	})
 
@@ -1632,7 +1768,7 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 	// 	uc.Log.Errorf("[MPPlanningUseCase.Create] Requestor not found")
 	// 	return nil, errors.New("Requestor not found")
 	// }
-	requestorExist, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	requestorExist, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 		ID: req.RequestorID.String(),
 	})
 
@@ -1647,7 +1783,7 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 	}
 
 	// check if organization location is exist
-	orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: req.OrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -1672,36 +1808,6 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 		return nil, errors.New("MP Planning Header already exist")
 	}
 
-	docNumber, err := uc.MPPlanningRepository.FindByKeys(map[string]interface{}{
-		"document_number": req.DocumentNumber,
-	})
-	if err != nil {
-		uc.Log.Errorf("[MPPlanningUseCase.Create] " + err.Error())
-		return nil, err
-	}
-
-	if docNumber != nil {
-		// Extract the last part of the document number
-		lastSlashIndex := strings.LastIndex(req.DocumentNumber, "/")
-		if lastSlashIndex == -1 {
-			// Handle error: invalid document number format
-			return nil, fmt.Errorf("invalid document number format")
-		}
-
-		lastPart := req.DocumentNumber[lastSlashIndex+1:]
-		lastDigit, err := strconv.Atoi(lastPart)
-		if err != nil {
-			// Handle error: last part is not a number
-			return nil, fmt.Errorf("last part of document number is not a number")
-		}
-
-		// Increment the last digit
-		lastDigit++
-
-		// Reconstruct the document number with the incremented digit
-		req.DocumentNumber = req.DocumentNumber[:lastSlashIndex+1] + fmt.Sprintf("%03d", lastDigit)
-	}
-
 	documentDate, err := time.Parse("2006-01-02", req.DocumentDate)
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.Create] " + err.Error())
@@ -1714,7 +1820,6 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 		EmpOrganizationID:      &req.EmpOrganizationID,
 		OrganizationLocationID: &req.OrganizationLocationID,
 		JobID:                  &req.JobID,
-		DocumentNumber:         req.DocumentNumber,
 		DocumentDate:           documentDate,
 		Notes:                  req.Notes,
 		TotalRecruit:           req.TotalRecruit,
@@ -1725,7 +1830,7 @@ func (uc *MPPlanningUseCase) Create(req *request.CreateHeaderMPPlanningRequest)
 		RequestorID:            &req.RequestorID,
 		NotesAttach:            req.NotesAttach,
 		// CreatedAt:              time.Now(),
-	})
+	}, uc.DocumentNumberService.Numbering(workflow.NumberSequenceMPPlanning))
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.Create] " + err.Error())
 		return nil, err
@@ -1806,13 +1911,18 @@ func (uc *MPPlanningUseCase) Update(req *request.UpdateHeaderMPPlanningRequest)
 		return nil, errors.New("MP Planning Header not found")
 	}
 
-	if exist.DocumentDate.Format("2006-01-02") < time.Now().Format("2006-01-02") {
+	if err := workflow.CheckVersion(exist.Version, req.Version); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	if exist.DocumentDate.Format("2006-01-02") < timezone.Today() {
 		if req.DocumentDate < exist.DocumentDate.Format("2006-01-02") {
 			uc.Log.Errorf("[MPPlanningUseCase.Update] Document Date cannot be less than existing Document Date")
 			return nil, errors.New("Document Date cannot be less than existing Document Date")
 		}
 	} else {
-		if req.DocumentDate < time.Now().Format("2006-01-02") {
+		if req.DocumentDate < timezone.Today() {
 			uc.Log.Errorf("[MPPlanningUseCase.Update] Document Date cannot be less than today")
 			return nil, errors.New("Document Date cannot be less than today")
 		}
@@ -1843,7 +1953,7 @@ func (uc *MPPlanningUseCase) Update(req *request.UpdateHeaderMPPlanningRequest)
 	}
 
 	// check if organization location is exist
-	orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: req.OrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -1869,7 +1979,7 @@ func (uc *MPPlanningUseCase) Update(req *request.UpdateHeaderMPPlanningRequest)
 		EmpOrganizationID:      &req.EmpOrganizationID,
 		OrganizationLocationID: &req.OrganizationLocationID,
 		JobID:                  &req.JobID,
-		DocumentNumber:         req.DocumentNumber,
+		DocumentNumber:         exist.DocumentNumber,
 		DocumentDate:           documentDate,
 		Notes:                  req.Notes,
 		TotalRecruit:           req.TotalRecruit,
@@ -1879,6 +1989,7 @@ func (uc *MPPlanningUseCase) Update(req *request.UpdateHeaderMPPlanningRequest)
 		ApprovedBy:             req.ApprovedBy,
 		RequestorID:            &req.RequestorID,
 		NotesAttach:            req.NotesAttach,
+		Version:                req.Version,
 	})
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.Update] " + err.Error())
@@ -1901,6 +2012,7 @@ func (uc *MPPlanningUseCase) Update(req *request.UpdateHeaderMPPlanningRequest)
 		ApprovedBy:        mpPlanningHeader.ApprovedBy,
 		RequestorID:       mpPlanningHeader.RequestorID.String(),
 		NotesAttach:       mpPlanningHeader.NotesAttach,
+		Version:           mpPlanningHeader.Version,
 		CreatedAt:         mpPlanningHeader.CreatedAt,
 		UpdatedAt:         mpPlanningHeader.UpdatedAt,
 		Attachments:       attachments,
@@ -1935,38 +2047,9 @@ func (uc *MPPlanningUseCase) FindAllLinesByHeaderIdPaginated(req *request.FindAl
 		return nil, err
 	}
 
-	for i, line := range *mpPlanningLines {
-		// Fetch organization location names using RabbitMQ
-		messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
-			ID: line.OrganizationLocationID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-			return nil, err
-		}
-		line.OrganizationLocationName = messageResponse.Name
-
-		// Fetch job level names using RabbitMQ
-		message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
-			ID: line.JobLevelID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-			return nil, err
-		}
-		line.JobLevelName = message2Response.Name
-
-		// Fetch job names using RabbitMQ
-		messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
-			ID: line.JobID.String(),
-		})
-		if err != nil {
-			uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
-			return nil, err
-		}
-		line.JobName = messageJobResposne.Name
-
-		(*mpPlanningLines)[i] = line
+	if err := uc.PortalDataHelper.EnrichMPPlanningLines(uc.ctx(), *mpPlanningLines); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindAllLinesByHeaderIdPaginated Message] " + err.Error())
+		return nil, err
 	}
 
 	return &response.FindAllLinesByHeaderIdPaginatedMPPlanningLineResponse{
@@ -1988,7 +2071,7 @@ func (uc *MPPlanningUseCase) FindLineById(req *request.FindLineByIdMPPlanningLin
 	}
 
 	// Fetch organization location names using RabbitMQ
-	messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+	messageResponse, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 		ID: mpPlanningLine.OrganizationLocationID.String(),
 	})
 	if err != nil {
@@ -1998,7 +2081,7 @@ func (uc *MPPlanningUseCase) FindLineById(req *request.FindLineByIdMPPlanningLin
 	mpPlanningLine.OrganizationLocationName = messageResponse.Name
 
 	// Fetch job level names using RabbitMQ
-	message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+	message2Response, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(uc.ctx(), request.SendFindJobLevelByIDMessageRequest{
 		ID: mpPlanningLine.JobLevelID.String(),
 	})
 	if err != nil {
@@ -2008,7 +2091,7 @@ func (uc *MPPlanningUseCase) FindLineById(req *request.FindLineByIdMPPlanningLin
 	mpPlanningLine.JobLevelName = message2Response.Name
 
 	// Fetch job names using RabbitMQ
-	messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	messageJobResposne, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: mpPlanningLine.JobID.String(),
 	})
 	if err != nil {
@@ -2025,7 +2108,7 @@ func (uc *MPPlanningUseCase) FindLineById(req *request.FindLineByIdMPPlanningLin
 func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineRequest) (*response.CreateMPPlanningLineResponse, error) {
 	if req.OrganizationLocationID != uuid.Nil {
 		// Check if organization location exist
-		orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+		orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 			ID: req.OrganizationLocationID.String(),
 		})
 
@@ -2041,7 +2124,7 @@ func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineReq
 	}
 
 	// Check if job level exist
-	jobLevelExist, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+	jobLevelExist, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(uc.ctx(), request.SendFindJobLevelByIDMessageRequest{
 		ID: req.JobLevelID.String(),
 	})
 
@@ -2056,7 +2139,7 @@ func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineReq
 	}
 
 	// Check if job exist
-	jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: req.JobID.String(),
 	})
 
@@ -2071,7 +2154,7 @@ func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineReq
 	}
 
 	// check if job level and job is exist
-	jobLevelJobExist, err := uc.JobPlafonMessage.SendCheckJobByJobLevelMessage(request.CheckJobByJobLevelRequest{
+	jobLevelJobExist, err := uc.JobPlafonMessage.SendCheckJobByJobLevelMessage(uc.ctx(), request.CheckJobByJobLevelRequest{
 		JobLevelID: req.JobLevelID.String(),
 		JobID:      req.JobID.String(),
 	})
@@ -2091,6 +2174,12 @@ func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineReq
 		return nil, errors.New("Recruit PH and Recruit MT cannot be 0")
 	}
 
+	plafonCheck, plafonDecision, err := uc.decideLinePlafon(req.MPPlanningHeaderID, req.JobID, nil, req.Existing+req.RecruitPH+req.RecruitMT, req.PlafonOverrideReason)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CreateLine] " + err.Error())
+		return nil, err
+	}
+
 	mpPlanningLine, err := uc.MPPlanningRepository.CreateLine(&entity.MPPlanningLine{
 		MPPlanningHeaderID:     req.MPPlanningHeaderID,
 		OrganizationLocationID: &req.OrganizationLocationID,
@@ -2111,6 +2200,11 @@ func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineReq
 		return nil, err
 	}
 
+	if err := uc.applyLinePlafon(mpPlanningLine, plafonCheck, plafonDecision, req.PlafonOverrideReason); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CreateLine] " + err.Error())
+		return nil, err
+	}
+
 	return &response.CreateMPPlanningLineResponse{
 		ID:                     mpPlanningLine.ID.String(),
 		MPPlanningHeaderID:     mpPlanningLine.MPPlanningHeaderID.String(),
@@ -2126,6 +2220,9 @@ func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineReq
 		RemainingBalanceMT:     mpPlanningLine.RemainingBalanceMT,
 		RecruitPH:              mpPlanningLine.RecruitPH,
 		RecruitMT:              mpPlanningLine.RecruitMT,
+		IsOverPlafon:           mpPlanningLine.IsOverPlafon,
+		PlafonOverrideStatus:   mpPlanningLine.PlafonOverrideStatus,
+		Version:                mpPlanningLine.Version,
 		CreatedAt:              mpPlanningLine.CreatedAt,
 		UpdatedAt:              mpPlanningLine.UpdatedAt,
 	}, nil
@@ -2134,7 +2231,7 @@ func (uc *MPPlanningUseCase) CreateLine(req *request.CreateLineMPPlanningLineReq
 func (uc *MPPlanningUseCase) UpdateLine(req *request.UpdateLineMPPlanningLineRequest) (*response.UpdateMPPlanningLineResponse, error) {
 	if req.OrganizationLocationID != uuid.Nil {
 		// Check if organization location exist
-		orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+		orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 			ID: req.OrganizationLocationID.String(),
 		})
 
@@ -2150,7 +2247,7 @@ func (uc *MPPlanningUseCase) UpdateLine(req *request.UpdateLineMPPlanningLineReq
 	}
 
 	// Check if job level exist
-	jobLevelExist, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+	jobLevelExist, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(uc.ctx(), request.SendFindJobLevelByIDMessageRequest{
 		ID: req.JobLevelID.String(),
 	})
 
@@ -2165,7 +2262,7 @@ func (uc *MPPlanningUseCase) UpdateLine(req *request.UpdateLineMPPlanningLineReq
 	}
 
 	// Check if job exist
-	jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+	jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 		ID: req.JobID.String(),
 	})
 
@@ -2191,8 +2288,13 @@ func (uc *MPPlanningUseCase) UpdateLine(req *request.UpdateLineMPPlanningLineReq
 		return nil, errors.New("MP Planning Line not found")
 	}
 
+	if err := workflow.CheckVersion(exist.Version, req.Version); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.UpdateLine] " + err.Error())
+		return nil, err
+	}
+
 	// check if job level and job is exist
-	jobLevelJobExist, err := uc.JobPlafonMessage.SendCheckJobByJobLevelMessage(request.CheckJobByJobLevelRequest{
+	jobLevelJobExist, err := uc.JobPlafonMessage.SendCheckJobByJobLevelMessage(uc.ctx(), request.CheckJobByJobLevelRequest{
 		JobLevelID: req.JobLevelID.String(),
 		JobID:      req.JobID.String(),
 	})
@@ -2212,9 +2314,15 @@ func (uc *MPPlanningUseCase) UpdateLine(req *request.UpdateLineMPPlanningLineReq
 		return nil, errors.New("Recruit PH and Recruit MT cannot be 0")
 	}
 
+	plafonCheck, plafonDecision, err := uc.decideLinePlafon(req.MPPlanningHeaderID, req.JobID, &exist.ID, req.Existing+req.RecruitPH+req.RecruitMT, req.PlafonOverrideReason)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.UpdateLine] " + err.Error())
+		return nil, err
+	}
+
 	mpPlanningLine, err := uc.MPPlanningRepository.UpdateLine(&entity.MPPlanningLine{
 		ID:                     req.ID,
-		MPPlanningHeaderID:     req.MPPlanningHeaderID,
+		MPPlanningHeaderID:     exist.MPPlanningHeaderID,
 		OrganizationLocationID: &req.OrganizationLocationID,
 		JobLevelID:             &req.JobLevelID,
 		JobID:                  &req.JobID,
@@ -2227,12 +2335,18 @@ func (uc *MPPlanningUseCase) UpdateLine(req *request.UpdateLineMPPlanningLineReq
 		RemainingBalanceMT:     req.RecruitMT,
 		RecruitPH:              req.RecruitPH,
 		RecruitMT:              req.RecruitMT,
+		Version:                req.Version,
 	})
 	if err != nil {
 		uc.Log.Errorf("[MPPlanningUseCase.UpdateLine] " + err.Error())
 		return nil, err
 	}
 
+	if err := uc.applyLinePlafon(mpPlanningLine, plafonCheck, plafonDecision, req.PlafonOverrideReason); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.UpdateLine] " + err.Error())
+		return nil, err
+	}
+
 	return &response.UpdateMPPlanningLineResponse{
 		ID:                     mpPlanningLine.ID.String(),
 		MPPlanningHeaderID:     mpPlanningLine.MPPlanningHeaderID.String(),
@@ -2248,6 +2362,9 @@ func (uc *MPPlanningUseCase) UpdateLine(req *request.UpdateLineMPPlanningLineReq
 		RemainingBalanceMT:     mpPlanningLine.RemainingBalanceMT,
 		RecruitPH:              mpPlanningLine.RecruitPH,
 		RecruitMT:              mpPlanningLine.RecruitMT,
+		IsOverPlafon:           mpPlanningLine.IsOverPlafon,
+		PlafonOverrideStatus:   mpPlanningLine.PlafonOverrideStatus,
+		Version:                mpPlanningLine.Version,
 		CreatedAt:              mpPlanningLine.CreatedAt,
 		UpdatedAt:              mpPlanningLine.UpdatedAt,
 	}, nil
@@ -2288,8 +2405,33 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 		return errors.New("MP Planning Header not found")
 	}
 
+	if err := workflow.CheckVersion(headerExist.Version, req.Version); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
+		return err
+	}
+
+	if err := workflow.CheckPlanningLinesEditable(headerExist.Status); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
+		return err
+	}
+
+	// check every job of the batch before saving, so a blocked job does not
+	// leave the batch half saved
+	plafonChecks, plafonDecisions, err := uc.decideBatchLinePlafons(headerExist.MPPPeriodID, req)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
+		return err
+	}
+
+	// the batch is saved line by line, so the header is claimed first and a
+	// second batch on the same version stops here
+	if err := uc.MPPlanningRepository.ClaimHeaderVersion(headerExist.ID, req.Version); err != nil {
+		uc.Log.Warnf("[MPPlanningUseCase.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
+		return err
+	}
+
 	var mpPlanningLineIds []uuid.UUID
-	for _, line := range req.MPPlanningLines {
+	for i, line := range req.MPPlanningLines {
 		// append mp planning line id
 		if line.IsCreate {
 			mpPlanningLineIds = append(mpPlanningLineIds, line.ID)
@@ -2297,7 +2439,7 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 
 		if line.OrganizationLocationID != uuid.Nil {
 			// Check if organization location exist
-			orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(request.SendFindOrganizationLocationByIDMessageRequest{
+			orgLocExist, err := uc.OrganizationMessage.SendFindOrganizationLocationByIDMessage(uc.ctx(), request.SendFindOrganizationLocationByIDMessageRequest{
 				ID: line.OrganizationLocationID.String(),
 			})
 
@@ -2313,7 +2455,7 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 		}
 
 		// Check if job level exist
-		jobLevelExist, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(request.SendFindJobLevelByIDMessageRequest{
+		jobLevelExist, err := uc.JobPlafonMessage.SendFindJobLevelByIDMessage(uc.ctx(), request.SendFindJobLevelByIDMessageRequest{
 			ID: line.JobLevelID.String(),
 		})
 
@@ -2328,7 +2470,7 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 		}
 
 		// Check if job exist
-		jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(request.SendFindJobByIDMessageRequest{
+		jobExist, err := uc.JobPlafonMessage.SendFindJobByIDMessage(uc.ctx(), request.SendFindJobByIDMessageRequest{
 			ID: line.JobID.String(),
 		})
 
@@ -2350,7 +2492,7 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 		}
 
 		// check if job level and job is exist
-		jobLevelJobExist, err := uc.JobPlafonMessage.SendCheckJobByJobLevelMessage(request.CheckJobByJobLevelRequest{
+		jobLevelJobExist, err := uc.JobPlafonMessage.SendCheckJobByJobLevelMessage(uc.ctx(), request.CheckJobByJobLevelRequest{
 			JobLevelID: line.JobLevelID.String(),
 			JobID:      line.JobID.String(),
 		})
@@ -2370,8 +2512,9 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 			return errors.New("Recruit PH and Recruit MT cannot be 0")
 		}
 
+		var savedLine *entity.MPPlanningLine
 		if exist == nil {
-			_, err := uc.MPPlanningRepository.CreateLine(&entity.MPPlanningLine{
+			savedLine, err = uc.MPPlanningRepository.CreateLine(&entity.MPPlanningLine{
 				ID:                     line.ID,
 				MPPlanningHeaderID:     req.MPPlanningHeaderID,
 				OrganizationLocationID: &line.OrganizationLocationID,
@@ -2392,7 +2535,7 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 				return err
 			}
 		} else {
-			_, err := uc.MPPlanningRepository.UpdateLine(&entity.MPPlanningLine{
+			savedLine, err = uc.MPPlanningRepository.UpdateLine(&entity.MPPlanningLine{
 				ID:                     line.ID,
 				MPPlanningHeaderID:     req.MPPlanningHeaderID,
 				OrganizationLocationID: &line.OrganizationLocationID,
@@ -2407,6 +2550,7 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 				RemainingBalanceMT:     line.RecruitMT,
 				RecruitPH:              line.RecruitPH,
 				RecruitMT:              line.RecruitMT,
+				Version:                exist.Version,
 			})
 			if err != nil {
 				uc.Log.Errorf("[MPPlanningUseCase.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
@@ -2414,6 +2558,10 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 			}
 		}
 
+		if err := uc.applyLinePlafon(savedLine, plafonChecks[line.JobID], plafonDecisions[i], line.PlafonOverrideReason); err != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.CreateOrUpdateBatchLineMPPlanningLines] " + err.Error())
+			return err
+		}
 	}
 
 	uc.Log.Infof("[MPPlanningUseCase.CreateOrUpdateBatchLineMPPlanningLines] mpPlanningLineIds: %v", mpPlanningLineIds)
@@ -2433,6 +2581,775 @@ func (uc *MPPlanningUseCase) CreateOrUpdateBatchLineMPPlanningLines(req *request
 	return nil
 }
 
+// lineImportMaxRows bounds the rows of a planning line import, the header
+// row included.
+const lineImportMaxRows = 5001
+
+// ImportLines reads a csv or xlsx of planning lines for a header and keeps it
+// as a dry run. Location, job level and job names are resolved against the
+// organization of the header; every problem is reported on its row and
+// nothing is saved to the lines until the import is confirmed.
+func (uc *MPPlanningUseCase) ImportLines(req *request.ImportMPPlanningLinesRequest, fileName string, file io.ReaderAt, size int64) (*response.MPPlanningLineImportResponse, error) {
+	format, err := export.ParseFormat(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+		return nil, fmt.Errorf("%w: %v", workflow.ErrPlanningImportUnreadable, err)
+	}
+
+	rows, err := export.ReadRows(format, file, size, lineImportMaxRows)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+		return nil, fmt.Errorf("%w: %v", workflow.ErrPlanningImportUnreadable, err)
+	}
+
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("%w: the file has no lines", workflow.ErrPlanningImportUnreadable)
+	}
+
+	columns, err := workflow.PlanningImportHeader(rows[0])
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+		return nil, fmt.Errorf("%w: %v", workflow.ErrPlanningImportUnreadable, err)
+	}
+
+	mpPlanningHeaderID, err := uuid.Parse(req.MPPlanningHeaderID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+		return nil, fmt.Errorf("%w: %v", workflow.ErrPlanningImportRequest, err)
+	}
+
+	mpPlanningHeader, err := uc.MPPlanningRepository.FindHeaderById(mpPlanningHeaderID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningHeader == nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] MP Planning Header not found")
+		return nil, errors.New("MP Planning Header not found")
+	}
+
+	lookup, err := uc.lineImportLookup(mpPlanningHeader)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+		return nil, err
+	}
+
+	existingLines := make(map[string]uuid.UUID, len(mpPlanningHeader.MPPlanningLines))
+	for _, line := range mpPlanningHeader.MPPlanningLines {
+		existingLines[lineImportKey(line.OrganizationLocationID, line.JobLevelID, line.JobID)] = line.ID
+	}
+
+	mpPlanningLineImport := &entity.MPPlanningLineImport{
+		MPPlanningHeaderID: mpPlanningHeader.ID,
+		FileName:           filepath.Base(fileName),
+		Status:             entity.MPPlanningLineImportStatusPending,
+		HeaderVersion:      mpPlanningHeader.Version,
+	}
+	if req.ImportedBy != "" {
+		importedBy, err := uuid.Parse(req.ImportedBy)
+		if err != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+			return nil, fmt.Errorf("%w: %v", workflow.ErrPlanningImportRequest, err)
+		}
+		mpPlanningLineImport.ImportedBy = &importedBy
+	}
+
+	seen := make(map[string]int)
+	for i, cells := range rows[1:] {
+		if isEmptyImportRow(cells) {
+			continue
+		}
+
+		row := lookup.resolve(mpPlanningHeader, i+2, cells, columns)
+
+		if row.Errors == "" {
+			key := lineImportKey(row.OrganizationLocationID, row.JobLevelID, row.JobID)
+			if first, ok := seen[key]; ok {
+				row.Errors = fmt.Sprintf("same location, job level and job as row %d", first)
+			} else {
+				seen[key] = row.RowNumber
+				if lineID, ok := existingLines[key]; ok {
+					row.MPPlanningLineID = &lineID
+				}
+			}
+		}
+
+		mpPlanningLineImport.TotalRows++
+		if row.Errors != "" {
+			mpPlanningLineImport.ErrorRows++
+		}
+		mpPlanningLineImport.MPPlanningLineImportRows = append(mpPlanningLineImport.MPPlanningLineImportRows, *row)
+	}
+
+	if mpPlanningLineImport.TotalRows == 0 {
+		return nil, fmt.Errorf("%w: the file has no lines", workflow.ErrPlanningImportUnreadable)
+	}
+
+	mpPlanningLineImport, err = uc.LineImportRepository.Create(mpPlanningLineImport)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ImportLines] " + err.Error())
+		return nil, err
+	}
+
+	return lineImportResponse(mpPlanningLineImport), nil
+}
+
+func (uc *MPPlanningUseCase) FindLineImportById(id uuid.UUID) (*response.MPPlanningLineImportResponse, error) {
+	mpPlanningLineImport, err := uc.LineImportRepository.FindById(id)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindLineImportById] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningLineImport == nil {
+		uc.Log.Errorf("[MPPlanningUseCase.FindLineImportById] MP Planning Line Import not found")
+		return nil, errors.New("MP Planning Line Import not found")
+	}
+
+	return lineImportResponse(mpPlanningLineImport), nil
+}
+
+// ConfirmLineImport saves the lines of a dry run through the batch line save,
+// plafon checks included. An import with row errors, or of a planning changed
+// since the dry run, cannot be confirmed.
+func (uc *MPPlanningUseCase) ConfirmLineImport(req *request.ConfirmMPPlanningLineImportRequest) (*response.MPPlanningLineImportResponse, error) {
+	id, err := uuid.Parse(req.ID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] " + err.Error())
+		return nil, fmt.Errorf("%w: %v", workflow.ErrPlanningImportRequest, err)
+	}
+
+	mpPlanningLineImport, err := uc.LineImportRepository.FindById(id)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningLineImport == nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] MP Planning Line Import not found")
+		return nil, errors.New("MP Planning Line Import not found")
+	}
+
+	if mpPlanningLineImport.Status == entity.MPPlanningLineImportStatusConfirmed {
+		return nil, workflow.ErrPlanningImportConfirmed
+	}
+
+	if mpPlanningLineImport.ErrorRows > 0 {
+		return nil, fmt.Errorf("%w: %d of %d rows", workflow.ErrPlanningImportInvalid, mpPlanningLineImport.ErrorRows, mpPlanningLineImport.TotalRows)
+	}
+
+	mpPlanningHeader, err := uc.MPPlanningRepository.FindHeaderById(mpPlanningLineImport.MPPlanningHeaderID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] " + err.Error())
+		return nil, err
+	}
+
+	if mpPlanningHeader == nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] MP Planning Header not found")
+		return nil, errors.New("MP Planning Header not found")
+	}
+
+	// the rows were checked against the planning as it was at the dry run
+	if err := workflow.CheckVersion(mpPlanningHeader.Version, mpPlanningLineImport.HeaderVersion); err != nil {
+		return nil, err
+	}
+
+	existingLines := make(map[string]*entity.MPPlanningLine, len(mpPlanningHeader.MPPlanningLines))
+	for i := range mpPlanningHeader.MPPlanningLines {
+		line := &mpPlanningHeader.MPPlanningLines[i]
+		existingLines[lineImportKey(line.OrganizationLocationID, line.JobLevelID, line.JobID)] = line
+	}
+
+	batchReq := &request.CreateOrUpdateBatchLineMPPlanningLinesRequest{
+		MPPlanningHeaderID: mpPlanningHeader.ID,
+		Version:            mpPlanningLineImport.HeaderVersion,
+	}
+	for _, row := range mpPlanningLineImport.MPPlanningLineImportRows {
+		counts := workflow.PlanningImportLine{
+			Existing:  row.Existing,
+			RecruitMT: row.RecruitMT,
+			RecruitPH: row.RecruitPH,
+			Promotion: row.Promotion,
+		}
+
+		line := request.BatchLineMPPlanningLineRequest{
+			OrganizationLocationID: *row.OrganizationLocationID,
+			JobLevelID:             *row.JobLevelID,
+			JobID:                  *row.JobID,
+			Existing:               counts.Existing,
+			Recruit:                counts.Recruit(),
+			Promotion:              counts.Promotion,
+			Total:                  counts.Total(),
+			RecruitPH:              counts.RecruitPH,
+			RecruitMT:              counts.RecruitMT,
+			IsCreate:               true,
+			PlafonOverrideReason:   req.PlafonOverrideReason,
+		}
+		if existing, ok := existingLines[lineImportKey(row.OrganizationLocationID, row.JobLevelID, row.JobID)]; ok {
+			line.ID = existing.ID
+			line.SuggestedRecruit = existing.SuggestedRecruit
+			line.IsCreate = false
+		}
+
+		batchReq.MPPlanningLines = append(batchReq.MPPlanningLines, line)
+	}
+
+	confirmed, err := uc.LineImportRepository.UpdateStatus(mpPlanningLineImport.ID, entity.MPPlanningLineImportStatusPending, entity.MPPlanningLineImportStatusConfirmed)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] " + err.Error())
+		return nil, err
+	}
+
+	if !confirmed {
+		return nil, workflow.ErrPlanningImportConfirmed
+	}
+
+	if err := uc.CreateOrUpdateBatchLineMPPlanningLines(batchReq); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] " + err.Error())
+		// the import can be confirmed again once the cause is fixed
+		if _, revertErr := uc.LineImportRepository.UpdateStatus(mpPlanningLineImport.ID, entity.MPPlanningLineImportStatusConfirmed, entity.MPPlanningLineImportStatusPending); revertErr != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.ConfirmLineImport] " + revertErr.Error())
+		}
+		return nil, err
+	}
+
+	return uc.FindLineImportById(mpPlanningLineImport.ID)
+}
+
+// lineImportLookup holds the locations and jobs of an organization by their
+// normalized names. Names are not unique in the portal, so each name keeps
+// every match.
+type lineImportLookup struct {
+	locations map[string][]uuid.UUID
+	jobs      map[string][]response.JobResponse
+}
+
+func (uc *MPPlanningUseCase) lineImportLookup(mpPlanningHeader *entity.MPPlanningHeader) (*lineImportLookup, error) {
+	lookup := &lineImportLookup{
+		locations: make(map[string][]uuid.UUID),
+		jobs:      make(map[string][]response.JobResponse),
+	}
+
+	if mpPlanningHeader.OrganizationID == nil {
+		return lookup, nil
+	}
+	orgID := mpPlanningHeader.OrganizationID.String()
+
+	const pageSize = 100
+	for page, read := 1, 0; ; page++ {
+		orgLocs, err := uc.OrganizationMessage.SendFindOrganizationLocationsPaginatedMessage(uc.ctx(), page, pageSize, "", nil, false, orgID)
+		if err != nil {
+			return nil, err
+		}
+		for _, orgLoc := range orgLocs.OrganizationLocations {
+			name := workflow.NormalizeImportName(orgLoc.Name)
+			lookup.locations[name] = append(lookup.locations[name], orgLoc.ID)
+		}
+		read += len(orgLocs.OrganizationLocations)
+		if len(orgLocs.OrganizationLocations) < pageSize || int64(read) >= orgLocs.Total {
+			break
+		}
+	}
+
+	jobs, err := uc.JobMessage.SendFindAllJobsByOrganizationIDMessage(uc.ctx(), orgID)
+	if err != nil {
+		return nil, err
+	}
+	var addJobs func(jobs []response.JobResponse)
+	addJobs = func(jobs []response.JobResponse) {
+		for _, job := range jobs {
+			name := workflow.NormalizeImportName(job.Name)
+			lookup.jobs[name] = append(lookup.jobs[name], job)
+			addJobs(job.Children)
+		}
+	}
+	if jobs != nil {
+		addJobs(*jobs)
+	}
+
+	return lookup, nil
+}
+
+// resolve reads a row of the spreadsheet and resolves its names. An empty
+// location is the location of the header.
+func (l *lineImportLookup) resolve(mpPlanningHeader *entity.MPPlanningHeader, rowNumber int, cells []string, columns map[string]int) *entity.MPPlanningLineImportRow {
+	cell := func(column string) string {
+		if index := columns[column]; index < len(cells) {
+			return strings.TrimSpace(cells[index])
+		}
+		return ""
+	}
+
+	row := &entity.MPPlanningLineImportRow{
+		RowNumber:                rowNumber,
+		OrganizationLocationName: cell(workflow.PlanningImportColumnLocation),
+		JobLevelName:             cell(workflow.PlanningImportColumnJobLevel),
+		JobName:                  cell(workflow.PlanningImportColumnJob),
+	}
+
+	var problems []string
+	counts := map[string]*int{
+		workflow.PlanningImportColumnExisting:  &row.Existing,
+		workflow.PlanningImportColumnRecruitMT: &row.RecruitMT,
+		workflow.PlanningImportColumnRecruitPH: &row.RecruitPH,
+		workflow.PlanningImportColumnPromotion: &row.Promotion,
+	}
+	for _, column := range workflow.PlanningImportColumns {
+		count, ok := counts[column]
+		if !ok {
+			continue
+		}
+		value, err := workflow.ParseImportCount(cell(column))
+		if err != nil {
+			problems = append(problems, column+": "+err.Error())
+			continue
+		}
+		*count = value
+	}
+
+	line := workflow.PlanningImportLine{
+		Existing:  row.Existing,
+		RecruitMT: row.RecruitMT,
+		RecruitPH: row.RecruitPH,
+		Promotion: row.Promotion,
+	}
+	problems = append(problems, line.Validate()...)
+
+	if row.OrganizationLocationName == "" {
+		if mpPlanningHeader.OrganizationLocationID == nil {
+			problems = append(problems, "location is required")
+		} else {
+			row.OrganizationLocationID = mpPlanningHeader.OrganizationLocationID
+		}
+	} else {
+		switch ids := l.locations[workflow.NormalizeImportName(row.OrganizationLocationName)]; len(ids) {
+		case 0:
+			problems = append(problems, fmt.Sprintf("location %q is not a location of the organization", row.OrganizationLocationName))
+		case 1:
+			row.OrganizationLocationID = &ids[0]
+		default:
+			problems = append(problems, fmt.Sprintf("location %q matches %d locations", row.OrganizationLocationName, len(ids)))
+		}
+	}
+
+	switch {
+	case row.JobName == "":
+		problems = append(problems, "job is required")
+	case row.JobLevelName == "":
+		problems = append(problems, "job level is required")
+	default:
+		jobs := l.jobs[workflow.NormalizeImportName(row.JobName)]
+		var matches []response.JobResponse
+		for _, job := range jobs {
+			if workflow.NormalizeImportName(job.JobLevel.Name) == workflow.NormalizeImportName(row.JobLevelName) {
+				matches = append(matches, job)
+			}
+		}
+
+		switch {
+		case len(jobs) == 0:
+			problems = append(problems, fmt.Sprintf("job %q is not a job of the organization", row.JobName))
+		case len(matches) == 0:
+			problems = append(problems, fmt.Sprintf("job %q is not at job level %q", row.JobName, row.JobLevelName))
+		case len(matches) > 1:
+			problems = append(problems, fmt.Sprintf("job %q matches %d jobs at job level %q", row.JobName, len(matches), row.JobLevelName))
+		default:
+			jobID, jobLevelID := matches[0].ID, matches[0].JobLevel.ID
+			row.JobID = &jobID
+			row.JobLevelID = &jobLevelID
+		}
+	}
+
+	row.Errors = strings.Join(problems, "\n")
+	return row
+}
+
+func lineImportResponse(mpPlanningLineImport *entity.MPPlanningLineImport) *response.MPPlanningLineImportResponse {
+	rows := make([]response.MPPlanningLineImportRowResponse, 0, len(mpPlanningLineImport.MPPlanningLineImportRows))
+	for _, row := range mpPlanningLineImport.MPPlanningLineImportRows {
+		line := workflow.PlanningImportLine{
+			Existing:  row.Existing,
+			RecruitMT: row.RecruitMT,
+			RecruitPH: row.RecruitPH,
+			Promotion: row.Promotion,
+		}
+
+		action := "create"
+		if row.MPPlanningLineID != nil {
+			action = "update"
+		}
+
+		errs := []string{}
+		if row.Errors != "" {
+			errs = strings.Split(row.Errors, "\n")
+		}
+
+		rows = append(rows, response.MPPlanningLineImportRowResponse{
+			RowNumber:                row.RowNumber,
+			Action:                   action,
+			OrganizationLocationName: row.OrganizationLocationName,
+			JobLevelName:             row.JobLevelName,
+			JobName:                  row.JobName,
+			OrganizationLocationID:   row.OrganizationLocationID,
+			JobLevelID:               row.JobLevelID,
+			JobID:                    row.JobID,
+			MPPlanningLineID:         row.MPPlanningLineID,
+			Existing:                 row.Existing,
+			RecruitMT:                row.RecruitMT,
+			RecruitPH:                row.RecruitPH,
+			Recruit:                  line.Recruit(),
+			Promotion:                row.Promotion,
+			Total:                    line.Total(),
+			Errors:                   errs,
+		})
+	}
+
+	return &response.MPPlanningLineImportResponse{
+		ID:                 mpPlanningLineImport.ID,
+		MPPlanningHeaderID: mpPlanningLineImport.MPPlanningHeaderID,
+		FileName:           mpPlanningLineImport.FileName,
+		Status:             mpPlanningLineImport.Status,
+		TotalRows:          mpPlanningLineImport.TotalRows,
+		ErrorRows:          mpPlanningLineImport.ErrorRows,
+		HeaderVersion:      mpPlanningLineImport.HeaderVersion,
+		ImportedBy:         mpPlanningLineImport.ImportedBy,
+		ConfirmedAt:        mpPlanningLineImport.ConfirmedAt,
+		CreatedAt:          mpPlanningLineImport.CreatedAt,
+		Rows:               rows,
+	}
+}
+
+func lineImportKey(organizationLocationID *uuid.UUID, jobLevelID *uuid.UUID, jobID *uuid.UUID) string {
+	parts := make([]string, 3)
+	for i, id := range []*uuid.UUID{organizationLocationID, jobLevelID, jobID} {
+		if id != nil {
+			parts[i] = id.String()
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+func isEmptyImportRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// decideLinePlafon checks a single line against the plafon of its job before
+// it is saved. lineID is nil for a new line.
+func (uc *MPPlanningUseCase) decideLinePlafon(headerID uuid.UUID, jobID uuid.UUID, lineID *uuid.UUID, headcount int, reason string) (*workflow.PlafonCheck, *workflow.PlafonDecision, error) {
+	mpPlanningHeader, err := uc.MPPlanningRepository.FindHeaderById(headerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if mpPlanningHeader == nil {
+		return nil, nil, errors.New("MP Planning Header not found")
+	}
+
+	var excludeLineIDs []uuid.UUID
+	if lineID != nil {
+		excludeLineIDs = append(excludeLineIDs, *lineID)
+	}
+
+	plafonCheck, err := uc.PlafonService.CheckMPPlanningLines(mpPlanningHeader.MPPPeriodID, jobID, excludeLineIDs, headcount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plafonDecision, err := uc.PlafonService.Decide(entity.PlafonOverrideDocumentTypeMPPlanningLine, lineID, plafonCheck, reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plafonCheck, plafonDecision, nil
+}
+
+// decideBatchLinePlafons checks the lines of a batch save job by job. The
+// saved and deleted lines are left out of the stored headcount and the saved
+// ones counted with their new values; the decisions follow the order of the
+// request lines.
+func (uc *MPPlanningUseCase) decideBatchLinePlafons(mppPeriodID uuid.UUID, req *request.CreateOrUpdateBatchLineMPPlanningLinesRequest) (map[uuid.UUID]*workflow.PlafonCheck, []*workflow.PlafonDecision, error) {
+	var excludeLineIDs []uuid.UUID
+	headcounts := make(map[uuid.UUID]int)
+	for _, line := range req.MPPlanningLines {
+		if line.ID != uuid.Nil {
+			excludeLineIDs = append(excludeLineIDs, line.ID)
+		}
+		headcounts[line.JobID] += line.Existing + line.RecruitPH + line.RecruitMT
+	}
+
+	for _, id := range req.DeletedLineIDs {
+		excludeLineIDs = append(excludeLineIDs, uuid.MustParse(id))
+	}
+
+	plafonChecks := make(map[uuid.UUID]*workflow.PlafonCheck, len(headcounts))
+	for jobID, headcount := range headcounts {
+		plafonCheck, err := uc.PlafonService.CheckMPPlanningLines(mppPeriodID, jobID, excludeLineIDs, headcount)
+		if err != nil {
+			return nil, nil, err
+		}
+		plafonChecks[jobID] = plafonCheck
+	}
+
+	plafonDecisions := make([]*workflow.PlafonDecision, len(req.MPPlanningLines))
+	for i, line := range req.MPPlanningLines {
+		var lineID *uuid.UUID
+		if line.ID != uuid.Nil {
+			lineID = &line.ID
+		}
+
+		plafonDecision, err := uc.PlafonService.Decide(entity.PlafonOverrideDocumentTypeMPPlanningLine, lineID, plafonChecks[line.JobID], line.PlafonOverrideReason)
+		if err != nil {
+			return nil, nil, err
+		}
+		plafonDecisions[i] = plafonDecision
+	}
+
+	return plafonChecks, plafonDecisions, nil
+}
+
+func (uc *MPPlanningUseCase) applyLinePlafon(mpPlanningLine *entity.MPPlanningLine, plafonCheck *workflow.PlafonCheck, plafonDecision *workflow.PlafonDecision, reason string) error {
+	if err := uc.PlafonService.Apply(entity.PlafonOverrideDocumentTypeMPPlanningLine, mpPlanningLine.ID, plafonCheck, plafonDecision, reason); err != nil {
+		return err
+	}
+
+	mpPlanningLine.IsOverPlafon = plafonDecision.IsOverPlafon
+	mpPlanningLine.PlafonOverrideStatus = plafonDecision.OverrideStatus
+	return nil
+}
+
+// CopyFromPeriod rolls the plannings of a period forward as drafts of another:
+// every organization location gets a DRAFTED planning with the lines of its
+// last APPROVED or COMPLETED planning of the source period, Existing read
+// again from the current headcount of the job. The job service only counts
+// the employees of a job, not of a job at a location, so a job planned at
+// several locations gets its whole headcount at each. Locations that already
+// plan in the target period are left alone. The plannings are saved in one
+// transaction, so a failure copies none of them.
+func (uc *MPPlanningUseCase) CopyFromPeriod(req *request.CopyMPPlanningFromPeriodRequest) (*response.CopyMPPlanningFromPeriodResponse, error) {
+	sourcePeriod, err := uc.MPPPeriodRepo.FindById(req.SourceMPPPeriodID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+		return nil, err
+	}
+	if sourcePeriod == nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] Source MPP Period not found")
+		return nil, errors.New("Source MPP Period not found")
+	}
+
+	targetPeriod, err := uc.MPPPeriodRepo.FindById(req.TargetMPPPeriodID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+		return nil, err
+	}
+	if targetPeriod == nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] Target MPP Period not found")
+		return nil, errors.New("Target MPP Period not found")
+	}
+
+	if err := workflow.CheckPlanningRolloverPeriods(sourcePeriod, targetPeriod); err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+		return nil, err
+	}
+
+	sourceHeaders, err := uc.MPPlanningRepository.FindAllHeadersByStatusesAndMPPeriodIDNewestFirst(workflow.PlanningRolloverSourceStatuses, sourcePeriod.ID, req.OrganizationID)
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+		return nil, err
+	}
+
+	targetHeaders, err := uc.MPPlanningRepository.GetHeadersBySomething(map[string]interface{}{
+		"mpp_period_id": targetPeriod.ID,
+	})
+	if err != nil {
+		uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+		return nil, err
+	}
+	plannedLocations := make(map[uuid.UUID]string)
+	if targetHeaders != nil {
+		for _, header := range *targetHeaders {
+			if header.OrganizationLocationID != nil {
+				plannedLocations[*header.OrganizationLocationID] = header.DocumentNumber
+			}
+		}
+	}
+
+	latestHeaders := workflow.LatestPlanningsByLocation(*sourceHeaders)
+
+	// the current headcount of every job the copied lines plan for
+	jobIDs := make([]string, 0)
+	seenJobs := make(map[uuid.UUID]bool)
+	for _, header := range latestHeaders {
+		for _, line := range header.MPPlanningLines {
+			if line.JobID != nil && !seenJobs[*line.JobID] {
+				seenJobs[*line.JobID] = true
+				jobIDs = append(jobIDs, line.JobID.String())
+			}
+		}
+	}
+	headcounts := make(map[uuid.UUID]int, len(jobIDs))
+	if len(jobIDs) > 0 {
+		jobs, err := uc.JobMessage.SendFindAllJobsIDsMessage(uc.ctx(), jobIDs)
+		if err != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+			return nil, err
+		}
+		if jobs != nil {
+			for _, job := range *jobs {
+				headcounts[job.ID] = job.Existing
+			}
+		}
+	}
+
+	documentDate := workflow.PlanningRolloverDocumentDate(targetPeriod, timezone.TodayDate())
+	report := &response.CopyMPPlanningFromPeriodResponse{
+		SourceMPPPeriodID: sourcePeriod.ID,
+		TargetMPPPeriodID: targetPeriod.ID,
+		CarryOverBalance:  req.CarryOverBalance,
+		DryRun:            req.DryRun,
+		Headers:           []response.CopiedMPPlanningHeaderResponse{},
+		SkippedHeaders:    []response.SkippedCopyMPPlanningHeaderResponse{},
+	}
+	// headcount copied for each job so far, not saved yet when the plafon of
+	// the next line is checked
+	copiedHeadcounts := make(map[uuid.UUID]int)
+	var mpPlanningHeaders []entity.MPPlanningHeader
+
+	for _, source := range latestHeaders {
+		skipHeader := func(reason string) {
+			report.SkippedHeaders = append(report.SkippedHeaders, response.SkippedCopyMPPlanningHeaderResponse{
+				SourceID:               source.ID,
+				SourceDocumentNumber:   source.DocumentNumber,
+				OrganizationLocationID: source.OrganizationLocationID,
+				Reason:                 reason,
+			})
+		}
+
+		if documentNumber, ok := plannedLocations[*source.OrganizationLocationID]; ok {
+			skipHeader("the location already has planning " + documentNumber + " in the target period")
+			continue
+		}
+
+		copied := response.CopiedMPPlanningHeaderResponse{
+			SourceID:               source.ID,
+			SourceDocumentNumber:   source.DocumentNumber,
+			SourceStatus:           source.Status,
+			OrganizationID:         source.OrganizationID,
+			OrganizationLocationID: source.OrganizationLocationID,
+			Lines:                  []response.CopiedMPPlanningLineResponse{},
+			SkippedLines:           []response.SkippedCopyMPPlanningLineResponse{},
+		}
+		var lines []entity.MPPlanningLine
+		var totalRecruit, totalPromote int
+		for i := range source.MPPlanningLines {
+			sourceLine := &source.MPPlanningLines[i]
+			skipLine := func(reason string) {
+				copied.SkippedLines = append(copied.SkippedLines, response.SkippedCopyMPPlanningLineResponse{
+					SourceLineID: sourceLine.ID,
+					JobID:        sourceLine.JobID,
+					Reason:       reason,
+				})
+			}
+
+			if sourceLine.JobID == nil {
+				skipLine("the line has no job")
+				continue
+			}
+			existing, ok := headcounts[*sourceLine.JobID]
+			if !ok {
+				skipLine("the job no longer exists")
+				continue
+			}
+
+			line := workflow.RolloverLine(sourceLine, existing, req.CarryOverBalance)
+			headcount := line.Existing + line.RecruitPH + line.RecruitMT
+
+			plafonCheck, err := uc.PlafonService.CheckMPPlanningLines(targetPeriod.ID, *line.JobID, nil, copiedHeadcounts[*line.JobID]+headcount)
+			if err != nil {
+				uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+				return nil, err
+			}
+			plafonDecision, err := uc.PlafonService.Decide(entity.PlafonOverrideDocumentTypeMPPlanningLine, nil, plafonCheck, "")
+			if err != nil {
+				if errors.Is(err, workflow.ErrOverPlafon) {
+					// an override needs a reason, which only the unit can give
+					skipLine(err.Error())
+					continue
+				}
+				uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+				return nil, err
+			}
+			line.IsOverPlafon = plafonDecision.IsOverPlafon
+			copiedHeadcounts[*line.JobID] += headcount
+
+			lines = append(lines, line)
+			totalRecruit += line.Recruit
+			totalPromote += line.Promotion
+			copied.Lines = append(copied.Lines, response.CopiedMPPlanningLineResponse{
+				SourceLineID:         sourceLine.ID,
+				JobLevelID:           line.JobLevelID,
+				JobID:                line.JobID,
+				PreviousExisting:     sourceLine.Existing,
+				Existing:             line.Existing,
+				CarriedOverRecruitPH: line.RecruitPH - sourceLine.RecruitPH,
+				CarriedOverRecruitMT: line.RecruitMT - sourceLine.RecruitMT,
+				RecruitPH:            line.RecruitPH,
+				RecruitMT:            line.RecruitMT,
+				Promotion:            line.Promotion,
+				Total:                line.Total,
+				IsOverPlafon:         line.IsOverPlafon,
+			})
+		}
+
+		if len(lines) == 0 {
+			skipHeader("none of its lines could be copied")
+			continue
+		}
+
+		mpPlanningHeaders = append(mpPlanningHeaders, entity.MPPlanningHeader{
+			MPPPeriodID:            targetPeriod.ID,
+			OrganizationID:         source.OrganizationID,
+			EmpOrganizationID:      source.EmpOrganizationID,
+			OrganizationLocationID: source.OrganizationLocationID,
+			JobID:                  source.JobID,
+			DocumentDate:           documentDate,
+			Notes:                  "Copied from " + source.DocumentNumber,
+			TotalRecruit:           float64(totalRecruit),
+			TotalPromote:           float64(totalPromote),
+			Status:                 entity.MPPlaningStatusDraft,
+			RequestorID:            source.RequestorID,
+			MPPlanningLines:        lines,
+		})
+
+		report.Headers = append(report.Headers, copied)
+		report.TotalHeaders++
+		report.TotalLines += len(copied.Lines)
+	}
+
+	if !req.DryRun && len(mpPlanningHeaders) > 0 {
+		mpPlanningHeaders, err = uc.MPPlanningRepository.CreateHeadersWithLines(mpPlanningHeaders, uc.DocumentNumberService.Numbering(workflow.NumberSequenceMPPlanning))
+		if err != nil {
+			uc.Log.Errorf("[MPPlanningUseCase.CopyFromPeriod] " + err.Error())
+			return nil, err
+		}
+		// the report lists the plannings in the order they were saved
+		for i := range mpPlanningHeaders {
+			report.Headers[i].ID = &mpPlanningHeaders[i].ID
+			report.Headers[i].DocumentNumber = mpPlanningHeaders[i].DocumentNumber
+		}
+	}
+
+	uc.Log.Infof("[MPPlanningUseCase.CopyFromPeriod] copied %d plannings with %d lines from period %s to %s, skipped %d plannings (dry run: %t)",
+		report.TotalHeaders, report.TotalLines, sourcePeriod.Title, targetPeriod.Title, len(report.SkippedHeaders), req.DryRun)
+
+	return report, nil
+}
+
 func MPPlanningUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IMPPlanningUseCase {
 	repo := repository.MPPlanningRepositoryFactory(log)
 	message := messaging.OrganizationMessageFactory(log)
@@ -2443,5 +3360,13 @@ func MPPlanningUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IMPPlannin
 	mpPlanningDTO := dto.MPPlanningDTOFactory(log)
 	mppPeriodRepo := repository.MPPPeriodRepositoryFactory(log)
 	jobMessage := messaging.JobMessageFactory(log)
-	return NewMPPlanningUseCase(viper, log, repo, message, jpm, um, em, jpr, mpPlanningDTO, mppPeriodRepo, jobMessage)
+	approvalWorkflow := workflow.ApprovalWorkflowFactory(log)
+	approvalChainRepo := repository.ApprovalChainRepositoryFactory(log)
+	approvalDelegationRepo := repository.ApprovalDelegationRepositoryFactory(log)
+	notificationService := service.NotificationServiceFactory(viper, log)
+	portalDataHelper := helper.PortalDataHelperFactory(log)
+	plafonService := service.PlafonServiceFactory(viper, log)
+	lineImportRepository := repository.MPPlanningLineImportRepositoryFactory(log)
+	documentNumberService := service.DocumentNumberServiceFactory(viper, log)
+	return NewMPPlanningUseCase(viper, log, repo, message, jpm, um, em, jpr, mpPlanningDTO, mppPeriodRepo, jobMessage, approvalWorkflow, approvalChainRepo, approvalDelegationRepo, notificationService, portalDataHelper, plafonService, lineImportRepository, documentNumberService)
 }
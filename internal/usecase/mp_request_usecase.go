@@ -1,19 +1,26 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"io"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/export"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/service"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/pdf"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/workflow"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -26,16 +33,21 @@ type IMPRequestUseCase interface {
 	GetRequestApprovalHistoryByHeaderId(headerID uuid.UUID, status string) ([]*response.MPRequestApprovalHistoryResponse, error)
 	FindByID(id uuid.UUID) (*response.MPRequestHeaderResponse, error)
 	FindByIDOnly(id uuid.UUID) (*response.MPRequestHeaderResponse, error)
+	FindOrganizationID(id uuid.UUID) (*uuid.UUID, error)
 	FindByIDOnlyForMessage(id uuid.UUID) (*response.MPRequestHeaderResponse, error)
 	FindAllByMajorIdsMessage(majorIDs []string) ([]*response.MPRequestHeaderResponse, error)
 	FindByIDForTesting(id uuid.UUID) (string, error)
 	FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*response.MPRequestPaginatedResponse, error)
+	Export(search string, filter map[string]interface{}, format export.Format, w io.Writer) error
+	RenderPDF(id uuid.UUID) (*response.ManpowerAttachmentResponse, error)
 	UpdateStatusHeader(req *request.UpdateMPRequestHeaderRequest) error
 	GenerateDocumentNumber(dateNow time.Time) (string, error)
 	CountTotalApprovalHistoryByStatus(headerID uuid.UUID, status entity.MPRequestApprovalHistoryStatus) (int64, error)
+	WithContext(ctx context.Context) IMPRequestUseCase
 }
 
 type MPRequestUseCase struct {
+	boundContext
 	Viper                  *viper.Viper
 	Log                    *logrus.Logger
 	MPRequestRepository    repository.IMPRequestRepository
@@ -49,6 +61,15 @@ type MPRequestUseCase struct {
 	MPRequestDTO           dto.IMPRequestDTO
 	MPPlanningRepository   repository.IMPPlanningRepository
 	MPRequestMessage       messaging.IMPRequestMessage
+	Workflow               workflow.IApprovalWorkflow
+	ApprovalChainRepo      repository.IApprovalChainRepository
+	ApprovalDelegationRepo repository.IApprovalDelegationRepository
+	NotificationService    service.INotificationService
+	PortalDataHelper       helper.IPortalDataHelper
+	PlafonService          service.IPlafonService
+	BudgetService          service.IBudgetService
+	DocumentService        service.IDocumentService
+	DocumentNumberService  service.IDocumentNumberService
 }
 
 func NewMPRequestUseCase(
@@ -65,6 +86,15 @@ func NewMPRequestUseCase(
 	mprDTO dto.IMPRequestDTO,
 	mpPlanningRepository repository.IMPPlanningRepository,
 	mpRequestMessage messaging.IMPRequestMessage,
+	approvalWorkflow workflow.IApprovalWorkflow,
+	approvalChainRepo repository.IApprovalChainRepository,
+	approvalDelegationRepo repository.IApprovalDelegationRepository,
+	notificationService service.INotificationService,
+	portalDataHelper helper.IPortalDataHelper,
+	plafonService service.IPlafonService,
+	budgetService service.IBudgetService,
+	documentService service.IDocumentService,
+	documentNumberService service.IDocumentNumberService,
 ) IMPRequestUseCase {
 	return &MPRequestUseCase{
 		Viper:                  viper,
@@ -80,11 +110,34 @@ func NewMPRequestUseCase(
 		MPRequestDTO:           mprDTO,
 		MPPlanningRepository:   mpPlanningRepository,
 		MPRequestMessage:       mpRequestMessage,
+		Workflow:               approvalWorkflow,
+		ApprovalChainRepo:      approvalChainRepo,
+		ApprovalDelegationRepo: approvalDelegationRepo,
+		NotificationService:    notificationService,
+		PortalDataHelper:       portalDataHelper,
+		PlafonService:          plafonService,
+		BudgetService:          budgetService,
+		DocumentService:        documentService,
+		DocumentNumberService:  documentNumberService,
 	}
 }
 
+// WithContext is the use case with its repositories bound to ctx, so what it
+// changes is audited under the actor of the request.
+func (uc *MPRequestUseCase) WithContext(ctx context.Context) IMPRequestUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	scoped.MPRequestRepository = uc.MPRequestRepository.WithContext(ctx)
+	scoped.MPPPeriodRepo = uc.MPPPeriodRepo.WithContext(ctx)
+	scoped.MPPlanningRepository = uc.MPPlanningRepository.WithContext(ctx)
+	scoped.RequestMajorRepository = uc.RequestMajorRepository.WithContext(ctx)
+	scoped.PlafonService = uc.PlafonService.WithContext(ctx)
+	scoped.BudgetService = uc.BudgetService.WithContext(ctx)
+	return &scoped
+}
+
 func (uc *MPRequestUseCase) Create(req *request.CreateMPRequestHeaderRequest) (*response.MPRequestHeaderResponse, error) {
-	if req.DocumentDate < time.Now().Format("2006-01-02") {
+	if req.DocumentDate < timezone.Today() {
 		uc.Log.Errorf("[MPRequestUseCase.Create] Document Date cannot be less than today")
 		return nil, errors.New("Document Date cannot be less than today")
 	}
@@ -106,46 +159,31 @@ func (uc *MPRequestUseCase) Create(req *request.CreateMPRequestHeaderRequest) (*
 	}
 
 	// check portal data
-	portalResponse, err := uc.MPRequestHelper.CheckPortalData(req)
+	portalResponse, err := uc.MPRequestHelper.CheckPortalData(uc.ctx(), req)
 	if err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.Create] error when check portal data: %v", err)
 		return nil, err
 	}
 
-	docNumber, err := uc.MPRequestRepository.FindByKeys(map[string]interface{}{"document_number": req.DocumentNumber})
+	mpRequestEntity := uc.MPRequestDTO.ConvertToEntity(req)
+	plafonCheck, plafonDecision, err := uc.decidePlafon(mpRequestEntity, nil, req.PlafonOverrideReason)
 	if err != nil {
-		uc.Log.Errorf("[MPRequestUseCase.Create] error when find by keys: %v", err)
+		uc.Log.Errorf("[MPRequestUseCase.Create] error when check job plafon: %v", err)
 		return nil, err
 	}
 
-	if docNumber != nil {
-		// Extract the last part of the document number
-		lastSlashIndex := strings.LastIndex(req.DocumentNumber, "/")
-		if lastSlashIndex == -1 {
-			// Handle error: invalid document number format
-			return nil, fmt.Errorf("invalid document number format")
-		}
-
-		lastPart := req.DocumentNumber[lastSlashIndex+1:]
-		lastDigit, err := strconv.Atoi(lastPart)
-		if err != nil {
-			// Handle error: last part is not a number
-			return nil, fmt.Errorf("last part of document number is not a number")
-		}
-
-		// Increment the last digit
-		lastDigit++
-
-		// Reconstruct the document number with the incremented digit
-		req.DocumentNumber = req.DocumentNumber[:lastSlashIndex+1] + fmt.Sprintf("%03d", lastDigit)
-	}
-
-	mpRequestHeader, err := uc.MPRequestRepository.Create(uc.MPRequestDTO.ConvertToEntity(req))
+	// the number is taken when the request is saved, whatever the client sent
+	mpRequestHeader, err := uc.MPRequestRepository.Create(mpRequestEntity, uc.DocumentNumberService.Numbering(workflow.NumberSequenceMPRequest), uc.budgetEntries(mpRequestEntity, mpRequestEntity.Status))
 	if err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.Create] error when create mp request header: %v", err)
 		return nil, err
 	}
 
+	if err := uc.applyPlafon(mpRequestHeader, plafonCheck, plafonDecision, req.PlafonOverrideReason); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.Create] error when apply job plafon: %v", err)
+		return nil, err
+	}
+
 	// create request major
 	for _, majorID := range req.MajorIDs {
 		reqMajor, err := uc.RequestMajorRepository.Create(&entity.RequestMajor{
@@ -175,7 +213,49 @@ func (uc *MPRequestUseCase) Create(req *request.CreateMPRequestHeaderRequest) (*
 	mpRequestHeader.MPPPeriod = *mppPeriod
 	mpRequestHeader.GradeName = portalResponse.GradeName
 
-	return uc.MPRequestDTO.ConvertToResponse(mpRequestHeader), nil
+	return uc.MPRequestDTO.ConvertToResponse(uc.ctx(), mpRequestHeader), nil
+}
+
+// decidePlafon checks the request against the plafon of its job before it is
+// saved. existingID is nil for a new request.
+func (uc *MPRequestUseCase) decidePlafon(mpRequestHeader *entity.MPRequestHeader, existingID *uuid.UUID, reason string) (*workflow.PlafonCheck, *workflow.PlafonDecision, error) {
+	plafonCheck, err := uc.PlafonService.CheckMPRequest(mpRequestHeader.MPPPeriodID, *mpRequestHeader.JobID, existingID, mpRequestHeader.TotalNeeds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plafonDecision, err := uc.PlafonService.Decide(entity.PlafonOverrideDocumentTypeMPRequest, existingID, plafonCheck, reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plafonCheck, plafonDecision, nil
+}
+
+func (uc *MPRequestUseCase) applyPlafon(mpRequestHeader *entity.MPRequestHeader, plafonCheck *workflow.PlafonCheck, plafonDecision *workflow.PlafonDecision, reason string) error {
+	if err := uc.PlafonService.Apply(entity.PlafonOverrideDocumentTypeMPRequest, mpRequestHeader.ID, plafonCheck, plafonDecision, reason); err != nil {
+		return err
+	}
+
+	mpRequestHeader.IsOverPlafon = plafonDecision.IsOverPlafon
+	mpRequestHeader.PlafonOverrideStatus = plafonDecision.OverrideStatus
+	return nil
+}
+
+// budgetEntries builds the ledger entries of a request saved in the status: a
+// request waiting for approval reserves its needs again, a draft or rejected
+// one gives back what it held. Completed requests keep their consumption.
+func (uc *MPRequestUseCase) budgetEntries(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus) repository.BudgetLedgerEntries {
+	return func(ledger repository.IBudgetLedgerRepository) ([]entity.BudgetLedgerEntry, error) {
+		budgetService := uc.BudgetService.WithLedger(ledger)
+		if workflow.HoldsBudget(status) {
+			return budgetService.Reserve(mpRequestHeader, status)
+		}
+		if status == entity.MPRequestStatusCompleted {
+			return nil, nil
+		}
+		return budgetService.Release(mpRequestHeader, status)
+	}
 }
 
 func (uc *MPRequestUseCase) FindByIDForTesting(id uuid.UUID) (string, error) {
@@ -191,7 +271,7 @@ func (uc *MPRequestUseCase) FindByIDForTesting(id uuid.UUID) (string, error) {
 	}
 
 	// send message to find organization by id
-	orgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
+	orgExist, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(uc.ctx(), request.SendFindOrganizationByIDMessageRequest{
 		ID: mpRequestHeader.OrganizationID.String(),
 	})
 
@@ -204,6 +284,22 @@ func (uc *MPRequestUseCase) FindByIDForTesting(id uuid.UUID) (string, error) {
 	return orgExist.Name, nil
 }
 
+// FindOrganizationID is the organization of the request, nil when there is
+// no such request.
+func (uc *MPRequestUseCase) FindOrganizationID(id uuid.UUID) (*uuid.UUID, error) {
+	mpRequestHeader, err := uc.MPRequestRepository.FindByIDOnly(id)
+	if err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.FindOrganizationID] error when find mp request header by id: %v", err)
+		return nil, err
+	}
+
+	if mpRequestHeader == nil {
+		return nil, nil
+	}
+
+	return mpRequestHeader.OrganizationID, nil
+}
+
 func (uc *MPRequestUseCase) FindByIDOnly(id uuid.UUID) (*response.MPRequestHeaderResponse, error) {
 	mpRequestHeader, err := uc.MPRequestRepository.FindByIDOnly(id)
 	if err != nil {
@@ -216,7 +312,7 @@ func (uc *MPRequestUseCase) FindByIDOnly(id uuid.UUID) (*response.MPRequestHeade
 		return nil, errors.New("mp request header is not exist")
 	}
 
-	return uc.MPRequestDTO.ConvertToResponse(mpRequestHeader), nil
+	return uc.MPRequestDTO.ConvertToResponse(uc.ctx(), mpRequestHeader), nil
 }
 
 func (uc *MPRequestUseCase) FindByIDOnlyForMessage(id uuid.UUID) (*response.MPRequestHeaderResponse, error) {
@@ -314,7 +410,11 @@ func (uc *MPRequestUseCase) Delete(id uuid.UUID) error {
 		return errors.New("mp request header is not exist")
 	}
 
-	err = uc.MPRequestRepository.DeleteHeader(id)
+	ledgerEntries := func(ledger repository.IBudgetLedgerRepository) ([]entity.BudgetLedgerEntry, error) {
+		return uc.BudgetService.WithLedger(ledger).Release(mpRequestHeader, mpRequestHeader.Status)
+	}
+
+	err = uc.MPRequestRepository.DeleteHeader(id, ledgerEntries)
 	if err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.Delete] error when delete mp request header: %v", err)
 		return err
@@ -339,16 +439,15 @@ func (uc *MPRequestUseCase) Delete(id uuid.UUID) error {
 // 	return "MPR/" + dateNow.Format("20060102") + "/" + fmt.Sprintf("%03d", len(*&foundMpRequestHeader)+1), nil
 // }
 
+// GenerateDocumentNumber previews the number of the next request. The request
+// is only given its number when it is created.
 func (uc *MPRequestUseCase) GenerateDocumentNumber(dateNow time.Time) (string, error) {
-	dateStr := dateNow.Format("2006-01-02")
-	highestNumber, err := uc.MPRequestRepository.GetHighestDocumentNumberByDate(dateStr)
+	documentNumber, err := uc.DocumentNumberService.Preview(workflow.NumberSequenceMPRequest, dateNow)
 	if err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.GenerateDocumentNumber] " + err.Error())
 		return "", err
 	}
 
-	newNumber := highestNumber + 1
-	documentNumber := fmt.Sprintf("MPR/%s/%03d", dateNow.Format("20060102"), newNumber)
 	return documentNumber, nil
 }
 
@@ -365,13 +464,18 @@ func (uc *MPRequestUseCase) Update(req *request.CreateMPRequestHeaderRequest) (*
 		return nil, errors.New("mp request header is not exist")
 	}
 
-	if mpRequestHeaderExist.DocumentDate.Format("2006-01-02") < time.Now().Format("2006-01-02") {
+	if err := workflow.CheckVersion(mpRequestHeaderExist.Version, req.Version); err != nil {
+		uc.Log.Warnf("[MPRequestUseCase.Update] %v", err)
+		return nil, err
+	}
+
+	if mpRequestHeaderExist.DocumentDate.Format("2006-01-02") < timezone.Today() {
 		if req.DocumentDate < mpRequestHeaderExist.DocumentDate.Format("2006-01-02") {
 			uc.Log.Errorf("[MPRequestUseCase.Update] Document Date cannot be less than existing Document Date")
 			return nil, errors.New("Document Date cannot be less than existing Document Date")
 		}
 	} else {
-		if req.DocumentDate < time.Now().Format("2006-01-02") {
+		if req.DocumentDate < timezone.Today() {
 			uc.Log.Errorf("[MPRequestUseCase.Update] Document Date cannot be less than today")
 			return nil, errors.New("Document Date cannot be less than today")
 		}
@@ -395,18 +499,37 @@ func (uc *MPRequestUseCase) Update(req *request.CreateMPRequestHeaderRequest) (*
 	}
 
 	// check portal data
-	portalResponse, err := uc.MPRequestHelper.CheckPortalData(req)
+	portalResponse, err := uc.MPRequestHelper.CheckPortalData(uc.ctx(), req)
 	if err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.Update] error when check portal data: %v", err)
 		return nil, err
 	}
 
-	mpRequestHeader, err := uc.MPRequestRepository.Update(uc.MPRequestDTO.ConvertToEntity(req))
+	mpRequestEntity := uc.MPRequestDTO.ConvertToEntity(req)
+	// a request keeps the number it was created with
+	mpRequestEntity.DocumentNumber = mpRequestHeaderExist.DocumentNumber
+	mpRequestEntity.Version = req.Version
+	plafonCheck, plafonDecision, err := uc.decidePlafon(mpRequestEntity, &mpRequestHeaderExist.ID, req.PlafonOverrideReason)
+	if err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.Update] error when check job plafon: %v", err)
+		return nil, err
+	}
+
+	status := mpRequestEntity.Status
+	if status == "" {
+		status = mpRequestHeaderExist.Status
+	}
+	mpRequestHeader, err := uc.MPRequestRepository.Update(mpRequestEntity, uc.budgetEntries(mpRequestEntity, status))
 	if err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.Update] error when create mp request header: %v", err)
 		return nil, err
 	}
 
+	if err := uc.applyPlafon(mpRequestHeader, plafonCheck, plafonDecision, req.PlafonOverrideReason); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.Update] error when apply job plafon: %v", err)
+		return nil, err
+	}
+
 	// create request major
 	for _, majorID := range req.MajorIDs {
 		err := uc.RequestMajorRepository.DeleteByMPRequestHeaderID(mpRequestHeader.ID)
@@ -441,7 +564,7 @@ func (uc *MPRequestUseCase) Update(req *request.CreateMPRequestHeaderRequest) (*
 	mpRequestHeader.MPPPeriod = *mppPeriod
 	mpRequestHeader.GradeName = portalResponse.GradeName
 
-	return uc.MPRequestDTO.ConvertToResponse(mpRequestHeader), nil
+	return uc.MPRequestDTO.ConvertToResponse(uc.ctx(), mpRequestHeader), nil
 }
 
 func (uc *MPRequestUseCase) FindByID(id uuid.UUID) (*response.MPRequestHeaderResponse, error) {
@@ -456,13 +579,22 @@ func (uc *MPRequestUseCase) FindByID(id uuid.UUID) (*response.MPRequestHeaderRes
 		return nil, errors.New("mp request header is not exist")
 	}
 
-	// check portal data
-	portalResponse, err := uc.MPRequestHelper.CheckPortalData(uc.MPRequestDTO.ConvertEntityToRequest(mpRequestHeader))
-	if err != nil {
+	if err := uc.applyPortalData(mpRequestHeader); err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.FindByID] error when check portal data: %v", err)
 		return nil, err
 	}
 
+	return uc.MPRequestDTO.ConvertToResponse(uc.ctx(), mpRequestHeader), nil
+}
+
+// applyPortalData fills the names of the organizations, job and employees of
+// the request from the portal.
+func (uc *MPRequestUseCase) applyPortalData(mpRequestHeader *entity.MPRequestHeader) error {
+	portalResponse, err := uc.MPRequestHelper.CheckPortalData(uc.ctx(), uc.MPRequestDTO.ConvertEntityToRequest(mpRequestHeader))
+	if err != nil {
+		return err
+	}
+
 	mpRequestHeader.OrganizationName = portalResponse.OrganizationName
 	mpRequestHeader.OrganizationCategory = portalResponse.OrganizationCategory
 	mpRequestHeader.OrganizationLocationName = portalResponse.OrganizationLocationName
@@ -484,22 +616,13 @@ func (uc *MPRequestUseCase) FindByID(id uuid.UUID) (*response.MPRequestHeaderRes
 	mpRequestHeader.CeoEmployeeJob = portalResponse.CeoEmployeeJob
 	mpRequestHeader.GradeName = portalResponse.GradeName
 
-	return uc.MPRequestDTO.ConvertToResponse(mpRequestHeader), nil
+	return nil
 }
 
 func (uc *MPRequestUseCase) FindAllPaginated(page int, pageSize int, search string, filter map[string]interface{}) (*response.MPRequestPaginatedResponse, error) {
-	var includedIDs []string
-	if filter["approver_type"] == "department_head" {
-		// find_all_org_structure_children_ids
-		orgStructureChildrenIDs, err := uc.OrganizationMessage.SendFindAllOrgStructureChildrenIDsMessage(filter["organization_structure_id"].(string))
-		if err != nil {
-			uc.Log.Errorf("[MPRequestUseCase.FindAllPaginated] error when find all org structure children ids: %v", err)
-			return nil, err
-		}
-
-		includedIDs = append(includedIDs, filter["organization_structure_id"].(string))
-		includedIDs = append(includedIDs, *orgStructureChildrenIDs...)
-		filter["included_ids"] = includedIDs
+	if err := uc.includeOrgStructureChildren(filter); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.FindAllPaginated] error when find all org structure children ids: %v", err)
+		return nil, err
 	}
 
 	mpRequestHeaders, total, err := uc.MPRequestRepository.FindAllPaginated(page, pageSize, search, filter)
@@ -508,83 +631,274 @@ func (uc *MPRequestUseCase) FindAllPaginated(page int, pageSize int, search stri
 		return nil, err
 	}
 
+	if err := uc.PortalDataHelper.EnrichMPRequestHeaders(uc.ctx(), mpRequestHeaders); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.FindAllPaginated] error when enrich portal data: %v", err)
+		return nil, err
+	}
+
 	var mpRequestHeaderResponses []response.MPRequestHeaderResponse
-	if filter["approver_type"] == "ceo" {
-		// check if mpr header.org id is type non field
-		for _, mpRequestHeader := range mpRequestHeaders {
-			orgCheck, err := uc.OrganizationMessage.SendFindOrganizationByIDMessage(request.SendFindOrganizationByIDMessageRequest{
-				ID: mpRequestHeader.OrganizationID.String(),
-			})
-			if err != nil {
-				uc.Log.Errorf("[MPRequestUseCase.FindAllPaginated] error when find organization by id: %v", err)
-				return nil, err
+	for _, mpRequestHeader := range mpRequestHeaders {
+		// the ceo only approves the requests of non field organizations
+		if filter["approver_type"] == "ceo" && mpRequestHeader.OrganizationCategory != "Non Field" {
+			continue
+		}
+
+		mpRequestHeaderResponses = append(mpRequestHeaderResponses, *uc.MPRequestDTO.ConvertToResponse(uc.ctx(), &mpRequestHeader))
+	}
+
+	return &response.MPRequestPaginatedResponse{
+		MPRequestHeader: mpRequestHeaderResponses,
+		Total:           total,
+	}, nil
+}
+
+var mpRequestExportColumns = []string{
+	"Document Number", "Document Date", "Period", "Status", "Request Type", "Recruitment Type", "Request Category",
+	"Organization", "Organization Location", "For Organization", "For Organization Location", "For Organization Structure",
+	"Employee Organization", "Job", "Job Level", "Grade", "Replacement",
+	"Male Needs", "Female Needs", "Any Gender", "Total Needs", "Expected Date",
+	"Minimum Age", "Maximum Age", "Minimum Experience", "Minimum Education", "Marital Status", "Majors",
+	"Requestor", "Department Head", "VP/GM/Director", "CEO", "HRD HO Unit",
+}
+
+// Export writes the mp requests of the list, with the same search and filters,
+// to w.
+func (uc *MPRequestUseCase) Export(search string, filter map[string]interface{}, format export.Format, w io.Writer) error {
+	if err := uc.includeOrgStructureChildren(filter); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.Export] error when find all org structure children ids: %v", err)
+		return err
+	}
+
+	writer, err := export.NewWriter(format, w, "MP Requests")
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteRow(mpRequestExportColumns); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.Export] error when write export: %v", err)
+		return err
+	}
+
+	err = uc.MPRequestRepository.FindAllInBatches(search, filter, exportBatchSize, func(mpRequestHeaders []entity.MPRequestHeader) error {
+		if err := uc.PortalDataHelper.EnrichMPRequestHeaders(uc.ctx(), mpRequestHeaders); err != nil {
+			return err
+		}
+
+		for _, header := range mpRequestHeaders {
+			// the ceo only approves the requests of non field organizations
+			if filter["approver_type"] == "ceo" && header.OrganizationCategory != "Non Field" {
+				continue
 			}
 
-			if orgCheck.OrganizationCategory == "Non Field" {
-				resp := uc.MPRequestDTO.ConvertToResponse(&mpRequestHeader)
-				portalResponse, err := uc.MPRequestHelper.CheckPortalDataMinimal(resp)
-				if err != nil {
-					uc.Log.Errorf("[MPRequestUseCase.FindAllPaginated] error when check portal data: %v", err)
-					return nil, err
-				}
+			majors := make([]string, 0, len(header.RequestMajors))
+			for _, requestMajor := range header.RequestMajors {
+				majors = append(majors, requestMajor.Major.Major)
+			}
 
-				// mpRequestHeader.OrganizationName = portalResponse.OrganizationName
-				// mpRequestHeader.OrganizationCategory = portalResponse.OrganizationCategory
-				// mpRequestHeader.OrganizationLocationName = portalResponse.OrganizationLocationName
-				// mpRequestHeader.ForOrganizationName = portalResponse.ForOrganizationName
-				// mpRequestHeader.ForOrganizationLocation = portalResponse.ForOrganizationLocationName
-				// mpRequestHeader.ForOrganizationStructure = portalResponse.ForOrganizationStructureName
-				// mpRequestHeader.JobName = portalResponse.JobName
-				// mpRequestHeader.RequestorName = portalResponse.RequestorName
-				// mpRequestHeader.DepartmentHeadName = portalResponse.DepartmentHeadName
-				// mpRequestHeader.VpGmDirectorName = portalResponse.VpGmDirectorName
-				// mpRequestHeader.CeoName = portalResponse.CeoName
-				// mpRequestHeader.HrdHoUnitName = portalResponse.HrdHoUnitName
-				// mpRequestHeader.EmpOrganizationName = portalResponse.EmpOrganizationName
-				// mpRequestHeader.JobLevelName = portalResponse.JobLevelName
-				// mpRequestHeader.JobLevel = portalResponse.JobLevel
-
-				// mpRequestHeaderResponses = append(mpRequestHeaderResponses, *uc.MPRequestDTO.ConvertToResponse(&mpRequestHeader))
-				mpRequestHeaderResponses = append(mpRequestHeaderResponses, *portalResponse)
+			replacement := "No"
+			if header.IsReplacement {
+				replacement = "Yes"
 			}
-		}
-	} else {
-		for _, mpRequestHeader := range mpRequestHeaders {
-			// check portal data
-			resp := uc.MPRequestDTO.ConvertToResponse(&mpRequestHeader)
-			portalResponse, err := uc.MPRequestHelper.CheckPortalDataMinimal(resp)
-			if err != nil {
-				uc.Log.Errorf("[MPRequestUseCase.FindAllPaginated] error when check portal data: %v", err)
-				return nil, err
+
+			if err := writer.WriteRow([]string{
+				header.DocumentNumber,
+				export.Date(&header.DocumentDate),
+				header.MPPPeriod.Title,
+				string(header.Status),
+				string(header.MPRequestType),
+				string(header.RecruitmentType),
+				header.RequestCategory.Name,
+				header.OrganizationName,
+				header.OrganizationLocationName,
+				header.ForOrganizationName,
+				header.ForOrganizationLocation,
+				header.ForOrganizationStructure,
+				header.EmpOrganizationName,
+				header.JobName,
+				header.JobLevelName,
+				header.GradeName,
+				replacement,
+				export.Int(header.MaleNeeds),
+				export.Int(header.FemaleNeeds),
+				export.Int(header.AnyGender),
+				export.Int(header.TotalNeeds),
+				export.Date(header.ExpectedDate),
+				export.Int(header.MinimumAge),
+				export.Int(header.MaximumAge),
+				export.Int(header.MinimumExperience),
+				string(header.MinimumEducation),
+				string(header.MaritalStatus),
+				strings.Join(majors, ", "),
+				header.RequestorName,
+				header.DepartmentHeadName,
+				header.VpGmDirectorName,
+				header.CeoName,
+				header.HrdHoUnitName,
+			}); err != nil {
+				return err
 			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.Export] error when export mp request headers: %v", err)
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.Export] error when write export: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RenderPDF prints the request form with a signature for the requestor and
+// for every step of its approval history, and keeps the file as an attachment
+// of the request.
+func (uc *MPRequestUseCase) RenderPDF(id uuid.UUID) (*response.ManpowerAttachmentResponse, error) {
+	mpRequestHeader, err := uc.MPRequestRepository.FindById(id)
+	if err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.RenderPDF] error when find mp request header by id: %v", err)
+		return nil, err
+	}
+
+	if mpRequestHeader == nil {
+		uc.Log.Errorf("[MPRequestUseCase.RenderPDF] mp request header with id %s is not exist", id.String())
+		return nil, errors.New("mp request header is not exist")
+	}
+
+	if err := uc.applyPortalData(mpRequestHeader); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.RenderPDF] error when check portal data: %v", err)
+		return nil, err
+	}
+
+	majors := make([]string, 0, len(mpRequestHeader.RequestMajors))
+	for _, requestMajor := range mpRequestHeader.RequestMajors {
+		majors = append(majors, requestMajor.Major.Major)
+	}
+
+	replacement := "No"
+	if mpRequestHeader.IsReplacement {
+		replacement = "Yes"
+	}
+
+	report := pdf.NewReport(uc.DocumentService.Letterhead("Manpower Request Form"))
+	report.Fields([]pdf.Field{
+		{Label: "Document Number", Value: mpRequestHeader.DocumentNumber},
+		{Label: "Document Date", Value: printedDate(&mpRequestHeader.DocumentDate)},
+		{Label: "Period", Value: mpRequestHeader.MPPPeriod.Title},
+		{Label: "Status", Value: string(mpRequestHeader.Status)},
+		{Label: "Request Type", Value: string(mpRequestHeader.MPRequestType)},
+		{Label: "Recruitment Type", Value: string(mpRequestHeader.RecruitmentType)},
+		{Label: "Request Category", Value: mpRequestHeader.RequestCategory.Name},
+	})
+
+	report.Heading("Position")
+	report.Fields([]pdf.Field{
+		{Label: "Organization", Value: mpRequestHeader.OrganizationName},
+		{Label: "Organization Location", Value: mpRequestHeader.OrganizationLocationName},
+		{Label: "For Organization", Value: mpRequestHeader.ForOrganizationName},
+		{Label: "For Organization Location", Value: mpRequestHeader.ForOrganizationLocation},
+		{Label: "For Organization Structure", Value: mpRequestHeader.ForOrganizationStructure},
+		{Label: "Employee Organization", Value: mpRequestHeader.EmpOrganizationName},
+		{Label: "Job", Value: mpRequestHeader.JobName},
+		{Label: "Job Level", Value: mpRequestHeader.JobLevelName},
+		{Label: "Grade", Value: mpRequestHeader.GradeName},
+		{Label: "Replacement", Value: replacement},
+		{Label: "Salary Range", Value: mpRequestHeader.SalaryMin + " - " + mpRequestHeader.SalaryMax},
+	})
+
+	report.Heading("Needs")
+	report.Fields([]pdf.Field{
+		{Label: "Male", Value: export.Int(mpRequestHeader.MaleNeeds)},
+		{Label: "Female", Value: export.Int(mpRequestHeader.FemaleNeeds)},
+		{Label: "Any Gender", Value: export.Int(mpRequestHeader.AnyGender)},
+		{Label: "Total", Value: export.Int(mpRequestHeader.TotalNeeds)},
+		{Label: "Expected Date", Value: printedDate(mpRequestHeader.ExpectedDate)},
+	})
+
+	report.Heading("Qualifications")
+	report.Fields([]pdf.Field{
+		{Label: "Age", Value: export.Int(mpRequestHeader.MinimumAge) + " - " + export.Int(mpRequestHeader.MaximumAge)},
+		{Label: "Minimum Experience", Value: export.Int(mpRequestHeader.MinimumExperience) + " years"},
+		{Label: "Experiences", Value: mpRequestHeader.Experiences},
+		{Label: "Minimum Education", Value: string(mpRequestHeader.MinimumEducation)},
+		{Label: "Majors", Value: strings.Join(majors, ", ")},
+		{Label: "Marital Status", Value: string(mpRequestHeader.MaritalStatus)},
+		{Label: "Required Qualification", Value: mpRequestHeader.RequiredQualification},
+		{Label: "Certificate", Value: mpRequestHeader.Certificate},
+		{Label: "Computer Skill", Value: mpRequestHeader.ComputerSkill},
+		{Label: "Language Skill", Value: mpRequestHeader.LanguageSkill},
+		{Label: "Other Skill", Value: mpRequestHeader.OtherSkill},
+	})
+
+	report.Heading("Job Description")
+	report.Fields([]pdf.Field{
+		{Label: "Job Description", Value: mpRequestHeader.Jobdesc},
+	})
 
-			// mpRequestHeader.OrganizationName = portalResponse.OrganizationName
-			// mpRequestHeader.OrganizationCategory = portalResponse.OrganizationCategory
-			// mpRequestHeader.OrganizationLocationName = portalResponse.OrganizationLocationName
-			// mpRequestHeader.ForOrganizationName = portalResponse.ForOrganizationName
-			// mpRequestHeader.ForOrganizationLocation = portalResponse.ForOrganizationLocationName
-			// mpRequestHeader.ForOrganizationStructure = portalResponse.ForOrganizationStructureName
-			// mpRequestHeader.JobName = portalResponse.JobName
-			// mpRequestHeader.RequestorName = portalResponse.RequestorName
-			// mpRequestHeader.DepartmentHeadName = portalResponse.DepartmentHeadName
-			// mpRequestHeader.VpGmDirectorName = portalResponse.VpGmDirectorName
-			// mpRequestHeader.CeoName = portalResponse.CeoName
-			// mpRequestHeader.HrdHoUnitName = portalResponse.HrdHoUnitName
-			// mpRequestHeader.EmpOrganizationName = portalResponse.EmpOrganizationName
-			// mpRequestHeader.JobLevelName = portalResponse.JobLevelName
-			// mpRequestHeader.JobLevel = portalResponse.JobLevel
-
-			// mpRequestHeaderResponses = append(mpRequestHeaderResponses, *uc.MPRequestDTO.ConvertToResponse(&mpRequestHeader))
-			mpRequestHeaderResponses = append(mpRequestHeaderResponses, *portalResponse)
+	histories := mpRequestHeader.MPRequestApprovalHistories
+	sort.SliceStable(histories, func(i, j int) bool {
+		return histories[i].CreatedAt.Before(histories[j].CreatedAt)
+	})
+
+	signatures := []pdf.Signature{{
+		Role: "Requested by",
+		Name: mpRequestHeader.RequestorName,
+		Date: printedDate(&mpRequestHeader.DocumentDate),
+	}}
+	for _, history := range histories {
+		role := history.Level
+		if history.OnBehalfOfName != "" {
+			role += " on behalf of " + history.OnBehalfOfName
 		}
+		signatures = append(signatures, pdf.Signature{
+			Role:   role,
+			Name:   history.ApproverName,
+			Status: string(history.Status),
+			Date:   printedDate(&history.CreatedAt),
+			Notes:  history.Notes,
+		})
 	}
+	report.Heading("Approval")
+	report.Signatures(signatures)
 
-	return &response.MPRequestPaginatedResponse{
-		MPRequestHeader: mpRequestHeaderResponses,
-		Total:           total,
+	attachment, err := uc.DocumentService.StorePDF(report, mpRequestHeader.TableName(), mpRequestHeader.ID, "mp-request-"+mpRequestHeader.DocumentNumber+".pdf")
+	if err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.RenderPDF] error when store pdf: %v", err)
+		return nil, err
+	}
+
+	return &response.ManpowerAttachmentResponse{
+		ID:       attachment.ID.String(),
+		FileName: attachment.FileName,
+		FilePath: attachment.FilePath,
+		FileType: attachment.FileType,
 	}, nil
 }
 
+// includeOrgStructureChildren scopes the list of a department head to their
+// organization structure and every structure under it.
+func (uc *MPRequestUseCase) includeOrgStructureChildren(filter map[string]interface{}) error {
+	if filter["approver_type"] != "department_head" {
+		return nil
+	}
+
+	// find_all_org_structure_children_ids
+	orgStructureChildrenIDs, err := uc.OrganizationMessage.SendFindAllOrgStructureChildrenIDsMessage(uc.ctx(), filter["organization_structure_id"].(string))
+	if err != nil {
+		return err
+	}
+
+	var includedIDs []string
+	includedIDs = append(includedIDs, filter["organization_structure_id"].(string))
+	includedIDs = append(includedIDs, *orgStructureChildrenIDs...)
+	filter["included_ids"] = includedIDs
+	return nil
+}
+
 func (uc *MPRequestUseCase) UpdateStatusHeader(req *request.UpdateMPRequestHeaderRequest) error {
 	// check if mp request header is exist
 	mpRequestHeader, err := uc.MPRequestRepository.FindById(uuid.MustParse(req.ID))
@@ -598,8 +912,54 @@ func (uc *MPRequestUseCase) UpdateStatusHeader(req *request.UpdateMPRequestHeade
 		return errors.New("mp request header is not exist")
 	}
 
+	if err := workflow.CheckVersion(mpRequestHeader.Version, req.Version); err != nil {
+		uc.Log.Warnf("[MPRequestUseCase.UpdateStatusHeader] %v", err)
+		return err
+	}
+
+	transition, err := uc.Workflow.Transition(workflow.DocumentTypeMPRequest, string(mpRequestHeader.Status), string(req.Status), string(req.Level))
+	if err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] illegal status transition: %v", err)
+		return err
+	}
+
+	// a request over its job plafon cannot move on until its override is approved
+	if req.Status != entity.MPRequestStatusDraft && req.Status != entity.MPRequestStatusRejected &&
+		workflow.PlafonOverrideBlocks(mpRequestHeader.IsOverPlafon, mpRequestHeader.PlafonOverrideStatus) {
+		err := fmt.Errorf("%w: request is %s", workflow.ErrPlafonOverrideUnresolved, mpRequestHeader.PlafonOverrideStatus)
+		uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] %v", err)
+		return err
+	}
+
+	var approvalChain *entity.ApprovalChain
+	if mpRequestHeader.OrganizationID != nil {
+		approvalChain, err = uc.ApprovalChainRepo.FindActiveByOrganizationIDAndDocumentType(*mpRequestHeader.OrganizationID, entity.ApprovalChainDocumentTypeMPRequest)
+		if err != nil {
+			uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when find approval chain: %v", err)
+			return err
+		}
+	}
+
+	delegation, err := resolveApprovalDelegation(uc.ApprovalDelegationRepo, entity.ApprovalDelegationScopeMPRequest, req.ApproverID, req.OnBehalfOfID, mpRequestHeader.NextApproverID)
+	if err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when resolve approval delegation: %v", err)
+		return err
+	}
+
+	actingFor := req.ApproverID
+	if delegation != nil {
+		actingFor = *delegation.DelegatorID
+	}
+
+	if approvalChain != nil && transition.Has(workflow.SideEffectRecordApprovalHistory) {
+		if err := workflow.CheckNextApprover(mpRequestHeader.NextApproverLevel, mpRequestHeader.NextApproverID, string(req.Level), actingFor); err != nil {
+			uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] %v", err)
+			return err
+		}
+	}
+
 	// check if approver ID is exist
-	approverExist, err := uc.EmpMessage.SendFindEmployeeByIDMessage(request.SendFindEmployeeByIDMessageRequest{
+	approverExist, err := uc.EmpMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
 		ID: req.ApproverID.String(),
 	})
 	if err != nil {
@@ -613,12 +973,13 @@ func (uc *MPRequestUseCase) UpdateStatusHeader(req *request.UpdateMPRequestHeade
 
 	var approvalHistory *entity.MPRequestApprovalHistory
 
-	if req.Status != entity.MPRequestStatusDraft && req.Status != entity.MPRequestStatusSubmitted {
-		uc.Log.Info("Ini masuk kesini ya kan")
+	if transition.Has(workflow.SideEffectRecordApprovalHistory) {
 		approvalHistory = &entity.MPRequestApprovalHistory{
 			MPRequestHeaderID: mpRequestHeader.ID,
 			ApproverID:        req.ApproverID,
 			ApproverName:      approverExist.Name,
+			OnBehalfOfID:      onBehalfOfID(delegation),
+			OnBehalfOfName:    onBehalfOfName(delegation),
 			Level:             string(req.Level),
 			Notes:             req.Notes,
 			Status: func() entity.MPRequestApprovalHistoryStatus {
@@ -636,41 +997,63 @@ func (uc *MPRequestUseCase) UpdateStatusHeader(req *request.UpdateMPRequestHeade
 		}
 	}
 
-	if req.Level == entity.MPPRequestApprovalHistoryLevelHRDHO && req.Status == entity.MPRequestStatusCompleted {
-		if mpRequestHeader.MPPlanningHeaderID != nil {
-			mpPlanningLines, err := uc.MPPlanningRepository.GetLinesByHeaderAndJobID(*mpRequestHeader.MPPlanningHeaderID, *mpRequestHeader.JobID)
-			if err != nil {
-				uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when get mp planning lines by header and job id: %v", err)
-				return err
-			}
+	ledgerEntries := func(ledger repository.IBudgetLedgerRepository) ([]entity.BudgetLedgerEntry, error) {
+		budgetService := uc.BudgetService.WithLedger(ledger)
+		switch {
+		case transition.Has(workflow.SideEffectReservePlanningBalance):
+			return budgetService.Reserve(mpRequestHeader, req.Status)
+		case transition.Has(workflow.SideEffectConsumePlanningBalance):
+			return budgetService.Consume(mpRequestHeader, req.Status)
+		case transition.Has(workflow.SideEffectReleasePlanningBalance):
+			return budgetService.Release(mpRequestHeader, req.Status)
+		default:
+			return nil, nil
+		}
+	}
 
-			if len(*mpPlanningLines) != 0 {
-				for _, mpPlanningLine := range *mpPlanningLines {
-					if mpRequestHeader.RecruitmentType == entity.RecruitmentTypeEnumMT {
-						uc.Log.Info("Mantappp")
-						mpPlanningLine.RemainingBalanceMT = mpPlanningLine.RemainingBalanceMT - mpRequestHeader.MaleNeeds - mpRequestHeader.FemaleNeeds
-					} else if mpRequestHeader.RecruitmentType == entity.RecruitmentTypeEnumPH {
-						mpPlanningLine.RemainingBalancePH = mpPlanningLine.RemainingBalancePH - mpRequestHeader.MaleNeeds - mpRequestHeader.FemaleNeeds
-					}
-
-					err = uc.MPPlanningRepository.UpdateLineRemainingBalances(mpPlanningLine.ID, mpPlanningLine.RemainingBalanceMT, mpPlanningLine.RemainingBalancePH)
-					if err != nil {
-						uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when update mp planning line: %v", err)
-						return err
-					}
-				}
-			}
+	nextApproverID := mpRequestHeader.NextApproverID
+	var nextApproverLevel string
+	nextStep, moved := workflow.NextChainStep(approvalChain, transition, string(req.Level), mpRequestHeader.MPRequestType)
+	if moved {
+		nextApproverID = nil
+		if nextStep != nil {
+			nextApproverID = nextStep.ApproverID
+			nextApproverLevel = nextStep.Level
+		}
+	}
+
+	outboxMessages := uc.statusChangeNotifications(mpRequestHeader, req.Status, nextApproverID, req.Notes)
+
+	if transition.Has(workflow.SideEffectCloneMPRequest) {
+		// queue the message to clone mpr, it is sent once the status change is committed
+		cloneMessage, err := entity.NewOutboxMessage(entity.OutboxMessageTypeCloneMPRequest, messaging.CloneMPRDestination, messaging.CloneMPRPayload{
+			MPRCloneID: mpRequestHeader.ID,
+		})
+		if err != nil {
+			uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when create clone mpr message: %v", err)
+			return err
 		}
+		outboxMessages = append(outboxMessages, *cloneMessage)
+	}
 
-		// send message to clone mpr
-		_, err := uc.MPRequestMessage.SendCloneMPR(mpRequestHeader.ID)
+	if req.Status == entity.MPRequestStatusCompleted {
+		completed := *mpRequestHeader
+		completed.Status = entity.MPRequestStatusCompleted
+
+		completedEvent, err := newDomainEventMessage(messaging.DomainEventMPRequestCompleted, uc.MPRequestDTO.ConvertToResponse(uc.ctx(), &completed))
 		if err != nil {
-			uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when send clone mpr message: %v", err)
+			uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when create completed event: %v", err)
 			return err
 		}
+		outboxMessages = append(outboxMessages, *completedEvent)
+	}
+
+	var nextApprover *repository.NextApprover
+	if moved {
+		nextApprover = &repository.NextApprover{ID: nextApproverID, Level: nextApproverLevel}
 	}
 
-	err = uc.MPRequestRepository.UpdateStatusHeader(uuid.MustParse(req.ID), string(req.Status), req.ApproverID.String(), approvalHistory)
+	err = uc.MPRequestRepository.UpdateStatusHeader(uuid.MustParse(req.ID), req.Version, string(req.Status), req.ApproverID.String(), nextApprover, approvalHistory, ledgerEntries, outboxMessages)
 	if err != nil {
 		uc.Log.Errorf("[MPRequestUseCase.UpdateStatusHeader] error when update mp request header: %v", err)
 		return err
@@ -697,6 +1080,52 @@ func (uc *MPRequestUseCase) UpdateStatusHeader(req *request.UpdateMPRequestHeade
 	return nil
 }
 
+// statusChangeNotifications builds the notifications of a status change: the
+// requestor learns what happened to the request and the pending approver is
+// asked for approval while one is still needed. Failures are only logged so a
+// lost notification never blocks a status change.
+func (uc *MPRequestUseCase) statusChangeNotifications(mpRequestHeader *entity.MPRequestHeader, status entity.MPRequestStatus, nextApproverID *uuid.UUID, notes string) []entity.OutboxMessage {
+	document := service.NotificationDocument{
+		DocumentType:   entity.ApprovalChainDocumentTypeMPRequest,
+		ID:             mpRequestHeader.ID,
+		DocumentNumber: mpRequestHeader.DocumentNumber,
+		Notes:          notes,
+	}
+
+	var outboxMessages []entity.OutboxMessage
+	var errs []error
+	collect := func(notifications []entity.OutboxMessage, err error) {
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		outboxMessages = append(outboxMessages, notifications...)
+	}
+
+	switch status {
+	case entity.MPRequestStatusSubmitted:
+		collect(uc.NotificationService.NewSubmittedNotification(uc.ctx(), document, mpRequestHeader.RequestorID))
+		collect(uc.NotificationService.NewNeedApprovalNotification(uc.ctx(), document, nextApproverID))
+	case entity.MPRequestStatusNeedApproval:
+		collect(uc.NotificationService.NewNeedApprovalNotification(uc.ctx(), document, nextApproverID))
+	case entity.MPRequestStatusApproved:
+		collect(uc.NotificationService.NewApprovedNotification(uc.ctx(), document, mpRequestHeader.RequestorID))
+		if nextApproverID != nil {
+			collect(uc.NotificationService.NewNeedApprovalNotification(uc.ctx(), document, nextApproverID))
+		}
+	case entity.MPRequestStatusRejected:
+		collect(uc.NotificationService.NewRejectedNotification(uc.ctx(), document, mpRequestHeader.RequestorID))
+	case entity.MPRequestStatusCompleted:
+		collect(uc.NotificationService.NewCompletedNotification(uc.ctx(), document, mpRequestHeader.RequestorID))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		uc.Log.Errorf("[MPRequestUseCase.statusChangeNotifications] " + err.Error())
+	}
+
+	return outboxMessages
+}
+
 func MPRequestUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IMPRequestUseCase {
 	mpRequestRepository := repository.MPRequestRepositoryFactory(log)
 	requestMajorRepository := repository.RequestMajorRepositoryFactory(log)
@@ -709,6 +1138,15 @@ func MPRequestUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IMPRequestU
 	mprDTO := dto.MPRequestDTOFactory(log, viper)
 	mpPlanningRepo := repository.MPPlanningRepositoryFactory(log)
 	mpRequestMessage := messaging.MPRequestMessageFactory(log)
+	approvalWorkflow := workflow.ApprovalWorkflowFactory(log)
+	approvalChainRepo := repository.ApprovalChainRepositoryFactory(log)
+	approvalDelegationRepo := repository.ApprovalDelegationRepositoryFactory(log)
+	notificationService := service.NotificationServiceFactory(viper, log)
+	portalDataHelper := helper.PortalDataHelperFactory(log)
+	plafonService := service.PlafonServiceFactory(viper, log)
+	budgetService := service.BudgetServiceFactory(viper, log)
+	documentService := service.DocumentServiceFactory(viper, log)
+	documentNumberService := service.DocumentNumberServiceFactory(viper, log)
 	return NewMPRequestUseCase(
 		viper,
 		log,
@@ -723,5 +1161,14 @@ func MPRequestUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IMPRequestU
 		mprDTO,
 		mpPlanningRepo,
 		mpRequestMessage,
+		approvalWorkflow,
+		approvalChainRepo,
+		approvalDelegationRepo,
+		notificationService,
+		portalDataHelper,
+		plafonService,
+		budgetService,
+		documentService,
+		documentNumberService,
 	)
 }
@@ -1,13 +1,17 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,6 +25,7 @@ type IMPPPeriodUseCase interface {
 	FindByCurrentDateAndStatus(request request.FindByCurrentDateAndStatusMPPPeriodRequest) (*response.FindByCurrentDateAndStatusMPPPeriodResponse, error)
 	UpdateStatusToOpenByDate(date time.Time) error
 	UpdateStatusToCloseByDate(date time.Time) error
+	WithContext(ctx context.Context) IMPPPeriodUseCase
 }
 
 type MPPPeriodUseCase struct {
@@ -35,6 +40,14 @@ func NewMPPPeriodUseCase(log *logrus.Logger, repo repository.IMPPPeriodRepositor
 	}
 }
 
+// WithContext is the use case with its repositories bound to ctx, so what it
+// changes is audited under the actor of the request.
+func (uc *MPPPeriodUseCase) WithContext(ctx context.Context) IMPPPeriodUseCase {
+	scoped := *uc
+	scoped.MPPPeriodRepository = uc.MPPPeriodRepository.WithContext(ctx)
+	return &scoped
+}
+
 func (uc *MPPPeriodUseCase) FindAllPaginated(req request.FindAllPaginatedMPPPeriodRequest) (*response.FindAllPaginatedMPPPeriodResponse, error) {
 	mppPeriods, total, err := uc.MPPPeriodRepository.FindAllPaginated(req.Page, req.PageSize, req.Search)
 	if err != nil {
@@ -114,6 +127,7 @@ func (uc *MPPPeriodUseCase) Create(req request.CreateMPPPeriodRequest) (*respons
 	}
 
 	mppPeriodEntity := &entity.MPPPeriod{
+		ID:              uuid.New(),
 		Title:           req.Title,
 		StartDate:       startDate,
 		EndDate:         endDate,
@@ -122,7 +136,13 @@ func (uc *MPPPeriodUseCase) Create(req request.CreateMPPPeriodRequest) (*respons
 		Status:          req.Status,
 	}
 
-	mppPeriod, err := uc.MPPPeriodRepository.Create(mppPeriodEntity)
+	openedEvent, err := uc.openedEvent(mppPeriodEntity, "")
+	if err != nil {
+		uc.Log.Errorf("[MPPPeriodUseCase.Create] " + err.Error())
+		return nil, err
+	}
+
+	mppPeriod, err := uc.MPPPeriodRepository.Create(mppPeriodEntity, openedEvent)
 	if err != nil {
 		uc.Log.Errorf("[MPPPeriodUseCase.Create] " + err.Error())
 		return nil, err
@@ -187,7 +207,20 @@ func (uc *MPPPeriodUseCase) Update(req request.UpdateMPPPeriodRequest) (*respons
 		Status:    req.Status,
 	}
 
-	mppPeriod, err := uc.MPPPeriodRepository.Update(mppPeriodEntity)
+	// the update only writes the fields above, the event reports the whole period
+	updated := *exist
+	updated.Title = mppPeriodEntity.Title
+	updated.StartDate = mppPeriodEntity.StartDate
+	updated.EndDate = mppPeriodEntity.EndDate
+	updated.Status = mppPeriodEntity.Status
+
+	openedEvent, err := uc.openedEvent(&updated, exist.Status)
+	if err != nil {
+		uc.Log.Errorf("[MPPPeriodUseCase.Update] " + err.Error())
+		return nil, err
+	}
+
+	mppPeriod, err := uc.MPPPeriodRepository.Update(mppPeriodEntity, openedEvent)
 	if err != nil {
 		uc.Log.Errorf("[MPPPeriodUseCase.Update] " + err.Error())
 		return nil, err
@@ -247,7 +280,14 @@ func (uc *MPPPeriodUseCase) UpdateStatusToOpenByDate(date time.Time) error {
 	for _, mppPeriod := range *mppPeriods {
 		if mppPeriod.Status == entity.MPPPeriodStatusDraft {
 			mppPeriod.Status = "open"
-			_, err := uc.MPPPeriodRepository.Update(&mppPeriod)
+
+			openedEvent, err := uc.openedEvent(&mppPeriod, entity.MPPPeriodStatusDraft)
+			if err != nil {
+				uc.Log.Errorf("[MPPPeriodScheduler.UpdateStatusToOpenByDate] " + err.Error())
+				return err
+			}
+
+			_, err = uc.MPPPeriodRepository.Update(&mppPeriod, openedEvent)
 			if err != nil {
 				uc.Log.Errorf("[MPPPeriodScheduler.UpdateStatusToOpenByDate] " + err.Error())
 				return err
@@ -269,7 +309,7 @@ func (uc *MPPPeriodUseCase) UpdateStatusToCloseByDate(date time.Time) error {
 	// loop mpp period to update status
 	for _, mppPeriod := range *mppPeriods {
 		mppPeriod.Status = "close"
-		_, err := uc.MPPPeriodRepository.Update(&mppPeriod)
+		_, err := uc.MPPPeriodRepository.Update(&mppPeriod, nil)
 		if err != nil {
 			uc.Log.Errorf("[MPPPeriodScheduler.UpdateStatusToCloseByDate] " + err.Error())
 			return err
@@ -279,6 +319,21 @@ func (uc *MPPPeriodUseCase) UpdateStatusToCloseByDate(date time.Time) error {
 	return nil
 }
 
+// openedEvent builds the mpp_period.opened event when saving mppPeriod opens
+// it. The repository keeps a period not open until its start date.
+func (uc *MPPPeriodUseCase) openedEvent(mppPeriod *entity.MPPPeriod, previousStatus entity.MPPPeriodStatus) ([]entity.OutboxMessage, error) {
+	if previousStatus == entity.MPPeriodStatusOpen || mppPeriod.SavedStatus(timezone.Now()) != entity.MPPeriodStatusOpen {
+		return nil, nil
+	}
+
+	event, err := newDomainEventMessage(messaging.DomainEventMPPPeriodOpened, mppPeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	return []entity.OutboxMessage{*event}, nil
+}
+
 func MPPPeriodUseCaseFactory(log *logrus.Logger) IMPPPeriodUseCase {
 	repo := repository.MPPPeriodRepositoryFactory(log)
 	return NewMPPPeriodUseCase(log, repo)
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/service"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultOutboxBatchSize   = 50
+	defaultOutboxMaxAttempts = 8
+	outboxBaseBackoff        = 30 * time.Second
+	outboxMaxBackoff         = 6 * time.Hour
+	// outboxLease is how long a claimed message stays hidden from other relays.
+	outboxLease = 5 * time.Minute
+	// outboxEventTimeout bounds the wait for the broker to confirm an event.
+	outboxEventTimeout = 30 * time.Second
+)
+
+type IOutboxUseCase interface {
+	FindAllPaginated(req *request.FindAllPaginatedOutboxMessageRequest) (*response.FindAllPaginatedOutboxMessageResponse, error)
+	Replay(req *request.ReplayOutboxMessageRequest) (*response.OutboxMessageResponse, error)
+	RelayPending(now time.Time) error
+	WithContext(ctx context.Context) IOutboxUseCase
+}
+
+type OutboxUseCase struct {
+	boundContext
+	Log              *logrus.Logger
+	Viper            *viper.Viper
+	OutboxRepository repository.IOutboxRepository
+	JulongService    service.IJulongService
+	MPRequestMessage messaging.IMPRequestMessage
+	EventPublisher   messaging.IEventPublisher
+	OutboxDTO        dto.IOutboxDTO
+}
+
+func NewOutboxUseCase(
+	log *logrus.Logger,
+	viper *viper.Viper,
+	outboxRepository repository.IOutboxRepository,
+	julongService service.IJulongService,
+	mpRequestMessage messaging.IMPRequestMessage,
+	eventPublisher messaging.IEventPublisher,
+	outboxDTO dto.IOutboxDTO,
+) IOutboxUseCase {
+	return &OutboxUseCase{
+		Log:              log,
+		Viper:            viper,
+		OutboxRepository: outboxRepository,
+		JulongService:    julongService,
+		MPRequestMessage: mpRequestMessage,
+		EventPublisher:   eventPublisher,
+		OutboxDTO:        outboxDTO,
+	}
+}
+
+// WithContext is the use case bound to ctx, so the messages it sends to the
+// portal are cancelled with the request.
+func (uc *OutboxUseCase) WithContext(ctx context.Context) IOutboxUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	return &scoped
+}
+
+func (uc *OutboxUseCase) FindAllPaginated(req *request.FindAllPaginatedOutboxMessageRequest) (*response.FindAllPaginatedOutboxMessageResponse, error) {
+	filter := make(map[string]interface{})
+	if req.Status != "" {
+		filter["status"] = req.Status
+	}
+	if req.MessageType != "" {
+		filter["message_type"] = req.MessageType
+	}
+
+	outboxMessages, total, err := uc.OutboxRepository.FindAllPaginated(req.Page, req.PageSize, filter)
+	if err != nil {
+		uc.Log.Errorf("[OutboxUseCase.FindAllPaginated] " + err.Error())
+		return nil, err
+	}
+
+	outboxMessageResponses := make([]response.OutboxMessageResponse, 0, len(*outboxMessages))
+	for _, outboxMessage := range *outboxMessages {
+		outboxMessageResponses = append(outboxMessageResponses, *uc.OutboxDTO.ConvertOutboxMessageEntityToResponse(&outboxMessage))
+	}
+
+	return &response.FindAllPaginatedOutboxMessageResponse{
+		OutboxMessages: outboxMessageResponses,
+		Total:          total,
+	}, nil
+}
+
+// Replay queues a dead or failed message for immediate delivery again.
+func (uc *OutboxUseCase) Replay(req *request.ReplayOutboxMessageRequest) (*response.OutboxMessageResponse, error) {
+	outboxMessage, err := uc.OutboxRepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[OutboxUseCase.Replay] " + err.Error())
+		return nil, err
+	}
+
+	if outboxMessage == nil {
+		return nil, errors.New("Outbox message not found")
+	}
+
+	if outboxMessage.Status != entity.OutboxMessageStatusDead && outboxMessage.Status != entity.OutboxMessageStatusFailed {
+		return nil, errors.New("Only dead or failed outbox messages can be replayed")
+	}
+
+	if err := uc.OutboxRepository.Replay(outboxMessage.ID); err != nil {
+		uc.Log.Errorf("[OutboxUseCase.Replay] " + err.Error())
+		return nil, err
+	}
+
+	replayed, err := uc.OutboxRepository.FindById(outboxMessage.ID)
+	if err != nil {
+		uc.Log.Errorf("[OutboxUseCase.Replay] " + err.Error())
+		return nil, err
+	}
+
+	return uc.OutboxDTO.ConvertOutboxMessageEntityToResponse(replayed), nil
+}
+
+// RelayPending delivers the due outbox messages. A message that fails is
+// retried with an exponential backoff until it reaches outbox.max_attempts,
+// after which it is dead-lettered until an admin replays it.
+func (uc *OutboxUseCase) RelayPending(now time.Time) error {
+	batchSize := uc.Viper.GetInt("outbox.batch_size")
+	if batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+	maxAttempts := uc.Viper.GetInt("outbox.max_attempts")
+	if maxAttempts <= 0 {
+		maxAttempts = defaultOutboxMaxAttempts
+	}
+
+	outboxMessages, err := uc.OutboxRepository.FindDue(now, batchSize)
+	if err != nil {
+		uc.Log.Errorf("[OutboxUseCase.RelayPending] " + err.Error())
+		return err
+	}
+
+	for _, outboxMessage := range *outboxMessages {
+		claimed, err := uc.OutboxRepository.Claim(outboxMessage.ID, now, now.Add(outboxLease))
+		if err != nil {
+			uc.Log.Errorf("[OutboxUseCase.RelayPending] " + err.Error())
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := uc.deliver(&outboxMessage); err != nil {
+			attempts := outboxMessage.Attempts + 1
+			status := entity.OutboxMessageStatusFailed
+			if attempts >= maxAttempts {
+				status = entity.OutboxMessageStatusDead
+			}
+			uc.Log.Warnf("[OutboxUseCase.RelayPending] delivery of outbox message %s failed on attempt %d: %v", outboxMessage.ID, attempts, err)
+
+			if err := uc.OutboxRepository.MarkFailed(outboxMessage.ID, status, attempts, time.Now().Add(outboxBackoff(attempts)), err.Error()); err != nil {
+				uc.Log.Errorf("[OutboxUseCase.RelayPending] " + err.Error())
+			}
+			continue
+		}
+
+		if err := uc.OutboxRepository.MarkSent(outboxMessage.ID, time.Now()); err != nil {
+			uc.Log.Errorf("[OutboxUseCase.RelayPending] " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (uc *OutboxUseCase) deliver(outboxMessage *entity.OutboxMessage) error {
+	switch outboxMessage.MessageType {
+	case entity.OutboxMessageTypeNotification:
+		var payload request.CreateNotificationRequest
+		if err := json.Unmarshal([]byte(outboxMessage.Payload), &payload); err != nil {
+			return err
+		}
+		return uc.JulongService.CreateJulongNotification(&payload)
+	case entity.OutboxMessageTypeCloneMPRequest:
+		var payload messaging.CloneMPRPayload
+		if err := json.Unmarshal([]byte(outboxMessage.Payload), &payload); err != nil {
+			return err
+		}
+		_, err := uc.MPRequestMessage.SendCloneMPR(uc.ctx(), payload.MPRCloneID)
+		return err
+	case entity.OutboxMessageTypeDomainEvent:
+		var event messaging.DomainEvent
+		if err := json.Unmarshal([]byte(outboxMessage.Payload), &event); err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), outboxEventTimeout)
+		defer cancel()
+		return uc.EventPublisher.Publish(ctx, &event)
+	default:
+		return fmt.Errorf("unknown outbox message type %q", outboxMessage.MessageType)
+	}
+}
+
+// newDomainEventMessage wraps the data snapshot in a domain event of
+// eventType, to be saved in the transaction of the change it reports.
+func newDomainEventMessage(eventType messaging.DomainEventType, data interface{}) (*entity.OutboxMessage, error) {
+	event, err := messaging.NewDomainEvent(eventType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewOutboxMessage(entity.OutboxMessageTypeDomainEvent, string(eventType), event)
+}
+
+// outboxBackoff doubles the wait after every failed attempt, up to outboxMaxBackoff.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func OutboxUseCaseFactory(viper *viper.Viper, log *logrus.Logger) IOutboxUseCase {
+	outboxRepository := repository.OutboxRepositoryFactory(log)
+	julongService := service.JulongServiceFactory(viper, log)
+	mpRequestMessage := messaging.MPRequestMessageFactory(log)
+	eventPublisher := messaging.EventPublisherFactory(log)
+	outboxDTO := dto.OutboxDTOFactory(log)
+	return NewOutboxUseCase(
+		log,
+		viper,
+		outboxRepository,
+		julongService,
+		mpRequestMessage,
+		eventPublisher,
+		outboxDTO,
+	)
+}
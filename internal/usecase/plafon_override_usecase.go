@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/dto"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/messaging"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type IPlafonOverrideUseCase interface {
+	FindAllPaginated(req *request.FindAllPaginatedPlafonOverrideRequest) (*response.FindAllPaginatedPlafonOverrideResponse, error)
+	FindById(req *request.FindByIdPlafonOverrideRequest) (*response.PlafonOverrideResponse, error)
+	UpdateStatus(req *request.UpdateStatusPlafonOverrideRequest) (*response.PlafonOverrideResponse, error)
+	WithContext(ctx context.Context) IPlafonOverrideUseCase
+}
+
+type PlafonOverrideUseCase struct {
+	boundContext
+	Log                      *logrus.Logger
+	PlafonOverrideRepository repository.IPlafonOverrideRepository
+	EmployeeMessage          messaging.IEmployeeMessage
+	PlafonOverrideDTO        dto.IPlafonOverrideDTO
+}
+
+func NewPlafonOverrideUseCase(log *logrus.Logger, repo repository.IPlafonOverrideRepository, employeeMessage messaging.IEmployeeMessage, plafonOverrideDTO dto.IPlafonOverrideDTO) IPlafonOverrideUseCase {
+	return &PlafonOverrideUseCase{
+		Log:                      log,
+		PlafonOverrideRepository: repo,
+		EmployeeMessage:          employeeMessage,
+		PlafonOverrideDTO:        plafonOverrideDTO,
+	}
+}
+
+// WithContext is the use case bound to ctx, so the messages it sends to the
+// portal are cancelled with the request.
+func (uc *PlafonOverrideUseCase) WithContext(ctx context.Context) IPlafonOverrideUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	scoped.PlafonOverrideRepository = uc.PlafonOverrideRepository.WithContext(ctx)
+	return &scoped
+}
+
+func (uc *PlafonOverrideUseCase) FindAllPaginated(req *request.FindAllPaginatedPlafonOverrideRequest) (*response.FindAllPaginatedPlafonOverrideResponse, error) {
+	filter := make(map[string]interface{})
+	if req.Status != "" {
+		filter["status"] = req.Status
+	}
+	if req.DocumentType != "" {
+		filter["document_type"] = req.DocumentType
+	}
+	if req.JobID != "" {
+		filter["job_id"] = req.JobID
+	}
+	if req.MPPPeriodID != "" {
+		filter["mpp_period_id"] = req.MPPPeriodID
+	}
+
+	overrides, total, err := uc.PlafonOverrideRepository.FindAllPaginated(req.Page, req.PageSize, req.Search, filter)
+	if err != nil {
+		uc.Log.Errorf("[PlafonOverrideUseCase.FindAllPaginated] " + err.Error())
+		return nil, err
+	}
+
+	overrideResponses := make([]response.PlafonOverrideResponse, 0, len(*overrides))
+	for _, override := range *overrides {
+		overrideResponses = append(overrideResponses, *uc.PlafonOverrideDTO.ConvertPlafonOverrideEntityToResponse(&override))
+	}
+
+	return &response.FindAllPaginatedPlafonOverrideResponse{
+		PlafonOverrides: overrideResponses,
+		Total:           total,
+	}, nil
+}
+
+func (uc *PlafonOverrideUseCase) FindById(req *request.FindByIdPlafonOverrideRequest) (*response.PlafonOverrideResponse, error) {
+	override, err := uc.PlafonOverrideRepository.FindById(uuid.MustParse(req.ID))
+	if err != nil {
+		uc.Log.Errorf("[PlafonOverrideUseCase.FindById] " + err.Error())
+		return nil, err
+	}
+
+	if override == nil {
+		return nil, errors.New("Plafon override not found")
+	}
+
+	return uc.PlafonOverrideDTO.ConvertPlafonOverrideEntityToResponse(override), nil
+}
+
+// UpdateStatus approves or rejects a pending override. The decision is copied
+// onto the planning line or manpower request, which lets it move on once
+// approved.
+func (uc *PlafonOverrideUseCase) UpdateStatus(req *request.UpdateStatusPlafonOverrideRequest) (*response.PlafonOverrideResponse, error) {
+	override, err := uc.PlafonOverrideRepository.FindById(req.ID)
+	if err != nil {
+		uc.Log.Errorf("[PlafonOverrideUseCase.UpdateStatus] " + err.Error())
+		return nil, err
+	}
+
+	if override == nil {
+		return nil, errors.New("Plafon override not found")
+	}
+
+	if override.Status != entity.PlafonOverrideStatusPending {
+		return nil, errors.New("Plafon override has already been " + string(override.Status))
+	}
+
+	approver, err := uc.EmployeeMessage.SendFindEmployeeByIDMessage(uc.ctx(), request.SendFindEmployeeByIDMessageRequest{
+		ID: req.ApproverID.String(),
+	})
+	if err != nil {
+		uc.Log.Errorf("[PlafonOverrideUseCase.UpdateStatus] " + err.Error())
+		return nil, err
+	}
+
+	if approver == nil {
+		return nil, errors.New("Approver not found")
+	}
+
+	decidedAt := time.Now()
+	override.Status = req.Status
+	override.ApproverID = &req.ApproverID
+	override.ApproverName = approver.Name
+	override.Notes = req.Notes
+	override.DecidedAt = &decidedAt
+
+	updated, err := uc.PlafonOverrideRepository.UpdateStatus(override)
+	if err != nil {
+		uc.Log.Errorf("[PlafonOverrideUseCase.UpdateStatus] " + err.Error())
+		return nil, err
+	}
+
+	return uc.PlafonOverrideDTO.ConvertPlafonOverrideEntityToResponse(updated), nil
+}
+
+func PlafonOverrideUseCaseFactory(log *logrus.Logger) IPlafonOverrideUseCase {
+	repo := repository.PlafonOverrideRepositoryFactory(log)
+	employeeMessage := messaging.EmployeeMessageFactory(log)
+	plafonOverrideDTO := dto.PlafonOverrideDTOFactory(log)
+	return NewPlafonOverrideUseCase(log, repo, employeeMessage, plafonOverrideDTO)
+}
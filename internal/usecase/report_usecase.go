@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/helper"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type IReportUseCase interface {
+	PlanVsActual(req *request.PlanVsActualReportRequest) (*response.PlanVsActualReportResponse, error)
+	WithContext(ctx context.Context) IReportUseCase
+}
+
+type ReportUseCase struct {
+	boundContext
+	Log              *logrus.Logger
+	ReportRepository repository.IReportRepository
+	MPPPeriodRepo    repository.IMPPPeriodRepository
+	PortalDataHelper helper.IPortalDataHelper
+}
+
+func NewReportUseCase(log *logrus.Logger, reportRepository repository.IReportRepository, mppPeriodRepo repository.IMPPPeriodRepository, portalDataHelper helper.IPortalDataHelper) IReportUseCase {
+	return &ReportUseCase{
+		Log:              log,
+		ReportRepository: reportRepository,
+		MPPPeriodRepo:    mppPeriodRepo,
+		PortalDataHelper: portalDataHelper,
+	}
+}
+
+// WithContext is the use case bound to ctx, so the messages it sends to the
+// portal are cancelled with the request.
+func (uc *ReportUseCase) WithContext(ctx context.Context) IReportUseCase {
+	scoped := *uc
+	scoped.boundContext = boundContext{ctx}
+	return &scoped
+}
+
+// PlanVsActual puts the planned and requested sums of each group side by side.
+// Groups with requests but no approved planning are kept, with nothing
+// planned, so unplanned hiring shows up too. Every dimension is grouped by
+// when the request names none.
+func (uc *ReportUseCase) PlanVsActual(req *request.PlanVsActualReportRequest) (*response.PlanVsActualReportResponse, error) {
+	groupBy := req.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = repository.PlanVsActualDimensions
+	}
+
+	filter := &repository.PlanVsActualFilter{
+		MPPPeriodID:            parseOptionalUUID(req.MPPPeriodID),
+		OrganizationID:         parseOptionalUUID(req.OrganizationID),
+		OrganizationLocationID: parseOptionalUUID(req.OrganizationLocationID),
+		JobLevelID:             parseOptionalUUID(req.JobLevelID),
+		JobID:                  parseOptionalUUID(req.JobID),
+		GroupBy:                groupBy,
+	}
+
+	planned, err := uc.ReportRepository.SumPlanned(filter)
+	if err != nil {
+		uc.Log.Errorf("[ReportUseCase.PlanVsActual] " + err.Error())
+		return nil, err
+	}
+
+	requested, err := uc.ReportRepository.SumRequested(filter)
+	if err != nil {
+		uc.Log.Errorf("[ReportUseCase.PlanVsActual] " + err.Error())
+		return nil, err
+	}
+
+	var rows []response.PlanVsActualRowResponse
+	index := make(map[string]int)
+	for _, sums := range append(planned, requested...) {
+		key := planVsActualKey(&sums)
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, response.PlanVsActualRowResponse{
+				MPPPeriodID:            sums.MPPPeriodID,
+				OrganizationID:         sums.OrganizationID,
+				OrganizationLocationID: sums.OrganizationLocationID,
+				JobLevelID:             sums.JobLevelID,
+				JobID:                  sums.JobID,
+			})
+		}
+		addPlanVsActualSums(&rows[i], &sums)
+	}
+
+	if rows == nil {
+		rows = []response.PlanVsActualRowResponse{}
+	}
+
+	if err := uc.PortalDataHelper.EnrichPlanVsActualRows(uc.ctx(), rows); err != nil {
+		uc.Log.Errorf("[ReportUseCase.PlanVsActual] " + err.Error())
+		return nil, err
+	}
+
+	periodTitles := make(map[uuid.UUID]string)
+	total := response.PlanVsActualRowResponse{}
+	for i := range rows {
+		row := &rows[i]
+		if row.MPPPeriodID != nil {
+			title, ok := periodTitles[*row.MPPPeriodID]
+			if !ok {
+				mppPeriod, err := uc.MPPPeriodRepo.FindById(*row.MPPPeriodID)
+				if err != nil {
+					uc.Log.Errorf("[ReportUseCase.PlanVsActual] " + err.Error())
+					return nil, err
+				}
+				if mppPeriod != nil {
+					title = mppPeriod.Title
+				}
+				periodTitles[*row.MPPPeriodID] = title
+			}
+			row.MPPPeriodTitle = title
+		}
+
+		setPlanVsActualPercentages(row)
+		addPlanVsActualRow(&total, row)
+	}
+	setPlanVsActualPercentages(&total)
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := &rows[i], &rows[j]
+		for _, pair := range [][2]string{
+			{a.MPPPeriodTitle, b.MPPPeriodTitle},
+			{a.OrganizationName, b.OrganizationName},
+			{a.OrganizationLocationName, b.OrganizationLocationName},
+		} {
+			if pair[0] != pair[1] {
+				return pair[0] < pair[1]
+			}
+		}
+		if a.JobLevel != b.JobLevel {
+			return a.JobLevel < b.JobLevel
+		}
+		return a.JobName < b.JobName
+	})
+
+	return &response.PlanVsActualReportResponse{
+		GroupBy: groupBy,
+		Rows:    rows,
+		Total:   total,
+	}, nil
+}
+
+func planVsActualKey(sums *repository.PlanVsActualRow) string {
+	ids := []*uuid.UUID{sums.MPPPeriodID, sums.OrganizationID, sums.OrganizationLocationID, sums.JobLevelID, sums.JobID}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		if id != nil {
+			parts[i] = id.String()
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+func addPlanVsActualSums(row *response.PlanVsActualRowResponse, sums *repository.PlanVsActualRow) {
+	row.Existing += sums.Existing
+	row.Recruit += sums.Recruit
+	row.RecruitMT += sums.RecruitMT
+	row.RecruitPH += sums.RecruitPH
+	row.RequestedMT += sums.RequestedMT
+	row.RequestedPH += sums.RequestedPH
+	row.Requested = row.RequestedMT + row.RequestedPH
+	row.CompletedMT += sums.CompletedMT
+	row.CompletedPH += sums.CompletedPH
+	row.Completed = row.CompletedMT + row.CompletedPH
+}
+
+func addPlanVsActualRow(total *response.PlanVsActualRowResponse, row *response.PlanVsActualRowResponse) {
+	addPlanVsActualSums(total, &repository.PlanVsActualRow{
+		Existing:    row.Existing,
+		Recruit:     row.Recruit,
+		RecruitMT:   row.RecruitMT,
+		RecruitPH:   row.RecruitPH,
+		RequestedMT: row.RequestedMT,
+		RequestedPH: row.RequestedPH,
+		CompletedMT: row.CompletedMT,
+		CompletedPH: row.CompletedPH,
+	})
+}
+
+func setPlanVsActualPercentages(row *response.PlanVsActualRowResponse) {
+	row.RequestedPercentage = percentage(row.Requested, row.Recruit)
+	row.UtilizationPercentage = percentage(row.Completed, row.Recruit)
+	row.UtilizationMTPercentage = percentage(row.CompletedMT, row.RecruitMT)
+	row.UtilizationPHPercentage = percentage(row.CompletedPH, row.RecruitPH)
+}
+
+// percentage is part of whole in percent, rounded to two decimals, or nil
+// when whole is zero.
+func percentage(part int, whole int) *float64 {
+	if whole == 0 {
+		return nil
+	}
+	value := math.Round(float64(part)/float64(whole)*10000) / 100
+	return &value
+}
+
+func parseOptionalUUID(value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+func ReportUseCaseFactory(log *logrus.Logger) IReportUseCase {
+	reportRepository := repository.ReportRepositoryFactory(log)
+	mppPeriodRepo := repository.MPPPeriodRepositoryFactory(log)
+	portalDataHelper := helper.PortalDataHelperFactory(log)
+	return NewReportUseCase(log, reportRepository, mppPeriodRepo, portalDataHelper)
+}
@@ -0,0 +1,50 @@
+package workflow
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+// CheckNextApprover makes sure the level and approver match the pending step of the chain.
+func CheckNextApprover(nextApproverLevel string, nextApproverID *uuid.UUID, level string, approverID uuid.UUID) error {
+	if nextApproverLevel == "" {
+		return nil
+	}
+
+	if nextApproverLevel != level {
+		return ErrNotNextApprover
+	}
+
+	if nextApproverID != nil && *nextApproverID != approverID {
+		return ErrNotNextApprover
+	}
+
+	return nil
+}
+
+// NextChainStep is the step a document waits for after the transition, false when the chain does not move.
+func NextChainStep(chain *entity.ApprovalChain, transition *Transition, level string, mpRequestType entity.MPRequestTypeEnum) (*entity.ApprovalChainStep, bool) {
+	switch {
+	case transition.Has(SideEffectClearApprovers):
+		return nil, true
+	case chain == nil:
+		return nil, false
+	case transition.Has(SideEffectStartApprovalChain):
+		return chain.NextStep("", mpRequestType), true
+	case transition.Has(SideEffectAssignApprover):
+		return chain.NextStep(level, mpRequestType), true
+	default:
+		return nil, false
+	}
+}
+
+// PendingChainStep is the step of the chain for the level a batch waits on.
+func PendingChainStep(chain *entity.ApprovalChain, level string) (*entity.ApprovalChainStep, error) {
+	for i := range chain.ApprovalChainSteps {
+		if chain.ApprovalChainSteps[i].Level == level {
+			return &chain.ApprovalChainSteps[i], nil
+		}
+	}
+
+	return nil, ErrNotNextApprover
+}
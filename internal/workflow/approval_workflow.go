@@ -0,0 +1,132 @@
+package workflow
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+type DocumentType string
+
+const (
+	DocumentTypeMPPlanning DocumentType = "mp_planning"
+	DocumentTypeMPRequest  DocumentType = "mp_request"
+	DocumentTypeBatch      DocumentType = "batch"
+)
+
+type SideEffect string
+
+const (
+	// SideEffectRecordApprovalHistory writes an approval history row for the transition.
+	SideEffectRecordApprovalHistory SideEffect = "record_approval_history"
+	// SideEffectAssignApprover stores the approver on the header column that belongs to the level.
+	SideEffectAssignApprover SideEffect = "assign_approver"
+	// SideEffectStartApprovalChain points the document at the first step of its approval chain.
+	SideEffectStartApprovalChain SideEffect = "start_approval_chain"
+	// SideEffectClearApprovers resets every approver column on the header.
+	SideEffectClearApprovers SideEffect = "clear_approvers"
+	// SideEffectReservePlanningBalance holds the request needs on the planning line balances.
+	SideEffectReservePlanningBalance SideEffect = "reserve_planning_balance"
+	// SideEffectConsumePlanningBalance turns what the request holds on the planning line balances into consumption.
+	SideEffectConsumePlanningBalance SideEffect = "consume_planning_balance"
+	// SideEffectReleasePlanningBalance gives back what the request holds on the planning line balances.
+	SideEffectReleasePlanningBalance SideEffect = "release_planning_balance"
+	// SideEffectCloneMPRequest sends the clone_mp_request message to the recruitment service.
+	SideEffectCloneMPRequest SideEffect = "clone_mp_request"
+	// SideEffectCascadePlanningStatus moves every planning header in the batch along with the batch.
+	SideEffectCascadePlanningStatus SideEffect = "cascade_planning_status"
+	// SideEffectCompletePeriod marks the MPP period of the batched planning headers as complete.
+	SideEffectCompletePeriod SideEffect = "complete_period"
+)
+
+type Transition struct {
+	From        string
+	To          string
+	Levels      []string
+	SideEffects []SideEffect
+}
+
+// AllowsLevel reports whether the level may perform the transition, any level when none are listed.
+func (t *Transition) AllowsLevel(level string) bool {
+	if len(t.Levels) == 0 {
+		return true
+	}
+	for _, l := range t.Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Transition) Has(effect SideEffect) bool {
+	for _, e := range t.SideEffects {
+		if e == effect {
+			return true
+		}
+	}
+	return false
+}
+
+type IApprovalWorkflow interface {
+	Transition(documentType DocumentType, from string, to string, level string) (*Transition, error)
+	AllowedTransitions(documentType DocumentType, from string, level string) []string
+}
+
+type ApprovalWorkflow struct {
+	Log   *logrus.Logger
+	Rules map[DocumentType][]Transition
+}
+
+func NewApprovalWorkflow(log *logrus.Logger, rules map[DocumentType][]Transition) IApprovalWorkflow {
+	return &ApprovalWorkflow{
+		Log:   log,
+		Rules: rules,
+	}
+}
+
+// Transition is the rule moving a document from one status to another, or a *TransitionError.
+func (w *ApprovalWorkflow) Transition(documentType DocumentType, from string, to string, level string) (*Transition, error) {
+	rules, ok := w.Rules[documentType]
+	if !ok {
+		w.Log.Errorf("[ApprovalWorkflow.Transition] unknown document type %s", documentType)
+		return nil, &TransitionError{DocumentType: documentType, From: from, To: to, Level: level, Reason: "unknown document type"}
+	}
+
+	statusMatched := false
+	for i := range rules {
+		if rules[i].From != from || rules[i].To != to {
+			continue
+		}
+		statusMatched = true
+		if rules[i].AllowsLevel(level) {
+			return &rules[i], nil
+		}
+	}
+
+	transitionErr := &TransitionError{DocumentType: documentType, From: from, To: to, Level: level}
+	if statusMatched {
+		transitionErr.Reason = "level is not allowed to perform this transition"
+	} else {
+		transitionErr.Reason = "transition is not allowed"
+	}
+
+	w.Log.Errorf("[ApprovalWorkflow.Transition] %v", transitionErr)
+	return nil, transitionErr
+}
+
+func (w *ApprovalWorkflow) AllowedTransitions(documentType DocumentType, from string, level string) []string {
+	allowed := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, rule := range w.Rules[documentType] {
+		if rule.From != from || seen[rule.To] || !rule.AllowsLevel(level) {
+			continue
+		}
+		seen[rule.To] = true
+		allowed = append(allowed, rule.To)
+	}
+
+	return allowed
+}
+
+func ApprovalWorkflowFactory(log *logrus.Logger) IApprovalWorkflow {
+	return NewApprovalWorkflow(log, DefaultRules())
+}
@@ -0,0 +1,162 @@
+package workflow
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/sirupsen/logrus"
+)
+
+func testWorkflow() IApprovalWorkflow {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewApprovalWorkflow(log, DefaultRules())
+}
+
+func TestApprovalWorkflowTransition(t *testing.T) {
+	var (
+		planningDraft        = string(entity.MPPlaningStatusDraft)
+		planningSubmitted    = string(entity.MPPlaningStatusSubmit)
+		planningNeedApproval = string(entity.MPPlaningStatusNeedApproval)
+		planningApproved     = string(entity.MPPlaningStatusApproved)
+		planningRejected     = string(entity.MPPlaningStatusReject)
+		planningCompleted    = string(entity.MPPlaningStatusComplete)
+		hrdUnit              = string(entity.MPPlanningApprovalHistoryLevelHRDUnit)
+		direkturUnit         = string(entity.MPPlanningApprovalHistoryLevelDirekturUnit)
+
+		requestDraft      = string(entity.MPRequestStatusDraft)
+		requestSubmitted  = string(entity.MPRequestStatusSubmitted)
+		requestApproved   = string(entity.MPRequestStatusApproved)
+		requestRejected   = string(entity.MPRequestStatusRejected)
+		requestInProgress = string(entity.MPRequestStatusInProgress)
+		requestCompleted  = string(entity.MPRequestStatusCompleted)
+		staff             = string(entity.MPRequestApprovalHistoryLevelStaff)
+		headDept          = string(entity.MPRequestApprovalHistoryLevelHeadDept)
+		hrdHO             = string(entity.MPPRequestApprovalHistoryLevelHRDHO)
+
+		batchNeedApproval = string(entity.BatchHeaderApprovalStatusNeedApproval)
+		batchApproved     = string(entity.BatchHeaderApprovalStatusApproved)
+		batchCompleted    = string(entity.BatchHeaderApprovalStatusCompleted)
+		director          = string(entity.BatchHeaderApproverTypeDirector)
+		ceo               = string(entity.BatchHeaderApproverTypeCEO)
+	)
+
+	tests := []struct {
+		name         string
+		documentType DocumentType
+		from         string
+		to           string
+		level        string
+		wantEffects  []SideEffect
+		wantReason   string
+	}{
+		// allowed moves and their side effects
+		{"planning submitted", DocumentTypeMPPlanning, planningDraft, planningSubmitted, "", []SideEffect{SideEffectStartApprovalChain}, ""},
+		{"planning sent back to draft", DocumentTypeMPPlanning, planningSubmitted, planningDraft, "", nil, ""},
+		{"planning passed on by HRD unit", DocumentTypeMPPlanning, planningSubmitted, planningNeedApproval, hrdUnit, []SideEffect{SideEffectRecordApprovalHistory, SideEffectAssignApprover}, ""},
+		{"planning rejected by HRD unit", DocumentTypeMPPlanning, planningSubmitted, planningRejected, hrdUnit, []SideEffect{SideEffectRecordApprovalHistory, SideEffectClearApprovers}, ""},
+		{"planning approved by the director", DocumentTypeMPPlanning, planningNeedApproval, planningApproved, direkturUnit, []SideEffect{SideEffectRecordApprovalHistory, SideEffectAssignApprover}, ""},
+		{"request submitted", DocumentTypeMPRequest, requestDraft, requestSubmitted, "", []SideEffect{SideEffectReservePlanningBalance, SideEffectStartApprovalChain}, ""},
+		{"request submitted by staff", DocumentTypeMPRequest, requestDraft, string(entity.MPRequestStatusNeedApproval), staff, []SideEffect{SideEffectReservePlanningBalance, SideEffectRecordApprovalHistory, SideEffectAssignApprover}, ""},
+		{"request withdrawn", DocumentTypeMPRequest, requestSubmitted, requestDraft, "", []SideEffect{SideEffectReleasePlanningBalance}, ""},
+		{"request rejected", DocumentTypeMPRequest, requestSubmitted, requestRejected, headDept, []SideEffect{SideEffectReleasePlanningBalance, SideEffectRecordApprovalHistory, SideEffectClearApprovers}, ""},
+		{"request completed by HRD HO", DocumentTypeMPRequest, requestInProgress, requestCompleted, hrdHO, []SideEffect{SideEffectConsumePlanningBalance, SideEffectCloneMPRequest, SideEffectRecordApprovalHistory, SideEffectAssignApprover}, ""},
+		{"batch approved", DocumentTypeBatch, batchNeedApproval, batchApproved, director, []SideEffect{SideEffectCascadePlanningStatus, SideEffectRecordApprovalHistory, SideEffectAssignApprover}, ""},
+		{"batch completed by the CEO", DocumentTypeBatch, batchApproved, batchCompleted, ceo, []SideEffect{SideEffectCascadePlanningStatus, SideEffectCompletePeriod}, ""},
+		{"batch completed by the director", DocumentTypeBatch, batchApproved, batchCompleted, director, nil, ""},
+
+		// forbidden moves
+		{"planning approved from draft", DocumentTypeMPPlanning, planningDraft, planningApproved, direkturUnit, nil, "transition is not allowed"},
+		{"planning reopened once completed", DocumentTypeMPPlanning, planningCompleted, planningDraft, "", nil, "transition is not allowed"},
+		{"planning approved by HRD unit", DocumentTypeMPPlanning, planningNeedApproval, planningApproved, hrdUnit, nil, "level is not allowed to perform this transition"},
+		{"planning approved without a level", DocumentTypeMPPlanning, planningNeedApproval, planningApproved, "", nil, "level is not allowed to perform this transition"},
+		{"request completed by the head of department", DocumentTypeMPRequest, requestApproved, requestCompleted, headDept, nil, "level is not allowed to perform this transition"},
+		{"request completed before approval", DocumentTypeMPRequest, requestSubmitted, requestCompleted, hrdHO, nil, "transition is not allowed"},
+		{"batch completed before approval", DocumentTypeBatch, batchNeedApproval, batchCompleted, ceo, nil, "transition is not allowed"},
+		{"unknown document", DocumentType("leave_request"), requestDraft, requestSubmitted, "", nil, "unknown document type"},
+	}
+
+	w := testWorkflow()
+	for _, tt := range tests {
+		transition, err := w.Transition(tt.documentType, tt.from, tt.to, tt.level)
+		if tt.wantReason != "" {
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) || !errors.Is(err, ErrIllegalTransition) {
+				t.Errorf("%s: err = %v, want a TransitionError", tt.name, err)
+				continue
+			}
+			if transitionErr.Reason != tt.wantReason {
+				t.Errorf("%s: reason = %q, want %q", tt.name, transitionErr.Reason, tt.wantReason)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if transition.From != tt.from || transition.To != tt.to {
+			t.Errorf("%s: transition = %s to %s", tt.name, transition.From, transition.To)
+		}
+		if !reflect.DeepEqual(transition.SideEffects, tt.wantEffects) {
+			t.Errorf("%s: side effects = %v, want %v", tt.name, transition.SideEffects, tt.wantEffects)
+		}
+	}
+}
+
+func TestApprovalWorkflowAllowedTransitions(t *testing.T) {
+	tests := []struct {
+		documentType DocumentType
+		from         string
+		level        string
+		want         []string
+	}{
+		{DocumentTypeMPPlanning, string(entity.MPPlaningStatusDraft), "", []string{string(entity.MPPlaningStatusDraft), string(entity.MPPlaningStatusSubmit)}},
+		{DocumentTypeMPPlanning, string(entity.MPPlaningStatusSubmit), string(entity.MPPlanningApprovalHistoryLevelHRDUnit), []string{
+			string(entity.MPPlaningStatusDraft),
+			string(entity.MPPlanningStatusInProgress),
+			string(entity.MPPlaningStatusNeedApproval),
+			string(entity.MPPlaningStatusReject),
+		}},
+		{DocumentTypeMPPlanning, string(entity.MPPlaningStatusComplete), string(entity.MPPlanningApprovalHistoryLevelCEO), []string{}},
+		{DocumentTypeMPRequest, string(entity.MPRequestStatusApproved), string(entity.MPPRequestApprovalHistoryLevelHRDHO), []string{
+			string(entity.MPRequestStatusInProgress),
+			string(entity.MPRequestStatusRejected),
+			string(entity.MPRequestStatusCompleted),
+		}},
+		{DocumentTypeMPRequest, string(entity.MPRequestStatusApproved), string(entity.MPRequestApprovalHistoryLevelHeadDept), []string{}},
+		// both completion rules lead to the same status, which is listed once
+		{DocumentTypeBatch, string(entity.BatchHeaderApprovalStatusApproved), string(entity.BatchHeaderApproverTypeCEO), []string{string(entity.BatchHeaderApprovalStatusCompleted)}},
+		{DocumentType("leave_request"), "DRAFT", "", []string{}},
+	}
+
+	w := testWorkflow()
+	for _, tt := range tests {
+		if got := w.AllowedTransitions(tt.documentType, tt.from, tt.level); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllowedTransitions(%s, %s, %q) = %q, want %q", tt.documentType, tt.from, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestTransitionAllowsLevel(t *testing.T) {
+	tests := []struct {
+		levels []string
+		level  string
+		want   bool
+	}{
+		{nil, "", true},
+		{nil, "Level CEO", true},
+		{[]string{"Level CEO"}, "Level CEO", true},
+		{[]string{"Level CEO"}, "Level VP", false},
+		{[]string{"Level CEO"}, "", false},
+	}
+
+	for _, tt := range tests {
+		transition := &Transition{Levels: tt.levels}
+		if got := transition.AllowsLevel(tt.level); got != tt.want {
+			t.Errorf("AllowsLevel(%v, %q) = %v, want %v", tt.levels, tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+// MPPlanningHeaderUpdates returns the header values of a status change and the columns to write explicitly.
+func MPPlanningHeaderUpdates(status string, approvedBy string, approvalHistory *entity.MPPlanningApprovalHistory) (*entity.MPPlanningHeader, []string) {
+	header := &entity.MPPlanningHeader{
+		Status: entity.MPPlaningStatus(status),
+	}
+
+	if approvalHistory == nil {
+		return header, nil
+	}
+
+	if approvalHistory.Status == entity.MPPlanningApprovalHistoryStatusRejected {
+		return header, []string{"Status", "ApprovedBy", "RecommendedBy", "ApproverRecruitmentID", "ApproverManagerID"}
+	}
+
+	approverID := approvalHistory.ApproverID
+	switch entity.MPPlanningApprovalHistoryLevel(approvalHistory.Level) {
+	case entity.MPPlanningApprovalHistoryLevelHRDUnit:
+		header.ApproverManagerID = &approverID
+		header.NotesManager = approvalHistory.Notes
+	case entity.MPPlanningApprovalHistoryLevelDirekturUnit:
+		header.RecommendedBy = approvedBy
+	case entity.MPPlanningApprovalHistoryLevelRecruitment:
+		header.ApproverRecruitmentID = &approverID
+		header.NotesRecruitment = approvalHistory.Notes
+	case entity.MPPlanningApprovalHistoryLevelCEO:
+		header.ApprovedBy = approvedBy
+	}
+
+	return header, nil
+}
+
+// MPRequestHeaderUpdates is the MP request counterpart of MPPlanningHeaderUpdates.
+func MPRequestHeaderUpdates(status string, approvedBy *uuid.UUID, approvalHistory *entity.MPRequestApprovalHistory) (*entity.MPRequestHeader, []string) {
+	header := &entity.MPRequestHeader{
+		Status: entity.MPRequestStatus(status),
+	}
+
+	if approvalHistory == nil {
+		return header, nil
+	}
+
+	if approvalHistory.Status == entity.MPRequestApprovalHistoryStatusRejected {
+		return header, []string{"Status", "DepartmentHead", "VpGmDirector", "CEO", "HrdHoUnit"}
+	}
+
+	switch entity.MPRequestApprovalHistoryLevel(approvalHistory.Level) {
+	case entity.MPRequestApprovalHistoryLevelCEO:
+		header.CEO = approvedBy
+	case entity.MPRequestApprovalHistoryLevelVP:
+		header.VpGmDirector = approvedBy
+	case entity.MPRequestApprovalHistoryLevelHeadDept:
+		header.DepartmentHead = approvedBy
+	case entity.MPPRequestApprovalHistoryLevelHRDHO:
+		header.HrdHoUnit = approvedBy
+	}
+
+	return header, nil
+}
@@ -0,0 +1,96 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+var ErrOverConsumption = errors.New("request needs exceed the remaining planning balance")
+
+type OverConsumptionError struct {
+	Balance   entity.BudgetBalance
+	Needs     int
+	Available int
+}
+
+func (e *OverConsumptionError) Error() string {
+	return fmt.Sprintf("%s: request needs %d on the %s balance but only %d is left", ErrOverConsumption.Error(), e.Needs, e.Balance, e.Available)
+}
+
+func (e *OverConsumptionError) Unwrap() error {
+	return ErrOverConsumption
+}
+
+// BudgetBalanceFor is the balance a recruitment type draws from; non staff to staff shares PH.
+func BudgetBalanceFor(recruitmentType entity.RecruitmentTypeEnum) entity.BudgetBalance {
+	if recruitmentType == entity.RecruitmentTypeEnumMT {
+		return entity.BudgetBalanceMT
+	}
+	return entity.BudgetBalancePH
+}
+
+// BudgetLine is a planning line a request may draw from, with what is still available.
+type BudgetLine struct {
+	MPPlanningLineID uuid.UUID
+	Available        int
+}
+
+type BudgetAllocation struct {
+	MPPlanningLineID uuid.UUID
+	Quantity         int
+}
+
+// AllocateBudget spreads the needs over the lines in order, putting the rest on the first line unless strict.
+func AllocateBudget(balance entity.BudgetBalance, lines []BudgetLine, needs int, strict bool) ([]BudgetAllocation, error) {
+	var allocations []BudgetAllocation
+	left := needs
+	available := 0
+
+	for _, line := range lines {
+		if line.Available <= 0 {
+			continue
+		}
+		available += line.Available
+		if left == 0 {
+			continue
+		}
+
+		quantity := min(line.Available, left)
+		allocations = append(allocations, BudgetAllocation{MPPlanningLineID: line.MPPlanningLineID, Quantity: quantity})
+		left -= quantity
+	}
+
+	if left == 0 {
+		return allocations, nil
+	}
+
+	if strict {
+		return nil, &OverConsumptionError{Balance: balance, Needs: needs, Available: available}
+	}
+
+	if len(lines) == 0 {
+		return allocations, nil
+	}
+
+	for i := range allocations {
+		if allocations[i].MPPlanningLineID == lines[0].MPPlanningLineID {
+			allocations[i].Quantity += left
+			return allocations, nil
+		}
+	}
+
+	return append([]BudgetAllocation{{MPPlanningLineID: lines[0].MPPlanningLineID, Quantity: left}}, allocations...), nil
+}
+
+// HoldsBudget reports whether a request in the status keeps its needs reserved.
+func HoldsBudget(status entity.MPRequestStatus) bool {
+	switch status {
+	case entity.MPRequestStatusSubmitted, entity.MPRequestStatusNeedApproval, entity.MPRequestStatusApproved, entity.MPRequestStatusInProgress:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,98 @@
+package workflow
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestAllocateBudget(t *testing.T) {
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	lines := []BudgetLine{
+		{MPPlanningLineID: first, Available: 2},
+		{MPPlanningLineID: second, Available: 0},
+		{MPPlanningLineID: third, Available: 3},
+	}
+
+	tests := []struct {
+		name          string
+		lines         []BudgetLine
+		needs         int
+		strict        bool
+		want          []BudgetAllocation
+		wantAvailable int
+	}{
+		{"fits on the first line", lines, 2, true, []BudgetAllocation{{first, 2}}, 0},
+		{"spread over the lines with balance", lines, 4, true, []BudgetAllocation{{first, 2}, {third, 2}}, 0},
+		{"every line used up", lines, 5, true, []BudgetAllocation{{first, 2}, {third, 3}}, 0},
+		{"too much when strict", lines, 6, true, nil, 5},
+		{"the rest on the first line", lines, 7, false, []BudgetAllocation{{first, 4}, {third, 3}}, 0},
+		{"the first line has nothing left", []BudgetLine{{second, 0}, {third, 1}}, 3, false, []BudgetAllocation{{second, 2}, {third, 1}}, 0},
+		{"no lines when strict", nil, 1, true, nil, 0},
+		{"no lines", nil, 1, false, nil, 0},
+		{"no needs", lines, 0, true, nil, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := AllocateBudget(entity.BudgetBalancePH, tt.lines, tt.needs, tt.strict)
+		if tt.strict && tt.want == nil && tt.needs > 0 {
+			var overErr *OverConsumptionError
+			if !errors.As(err, &overErr) || !errors.Is(err, ErrOverConsumption) {
+				t.Errorf("%s: err = %v, want an OverConsumptionError", tt.name, err)
+				continue
+			}
+			if overErr.Needs != tt.needs || overErr.Available != tt.wantAvailable || overErr.Balance != entity.BudgetBalancePH {
+				t.Errorf("%s: err = %+v", tt.name, overErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: allocations = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBudgetBalanceFor(t *testing.T) {
+	tests := []struct {
+		recruitmentType entity.RecruitmentTypeEnum
+		want            entity.BudgetBalance
+	}{
+		{entity.RecruitmentTypeEnumMT, entity.BudgetBalanceMT},
+		{entity.RecruitmentTypeEnumPH, entity.BudgetBalancePH},
+		{entity.RecruitmentTypeEnumNS, entity.BudgetBalancePH},
+	}
+
+	for _, tt := range tests {
+		if got := BudgetBalanceFor(tt.recruitmentType); got != tt.want {
+			t.Errorf("BudgetBalanceFor(%s) = %s, want %s", tt.recruitmentType, got, tt.want)
+		}
+	}
+}
+
+func TestHoldsBudget(t *testing.T) {
+	tests := []struct {
+		status entity.MPRequestStatus
+		want   bool
+	}{
+		{entity.MPRequestStatusDraft, false},
+		{entity.MPRequestStatusSubmitted, true},
+		{entity.MPRequestStatusNeedApproval, true},
+		{entity.MPRequestStatusApproved, true},
+		{entity.MPRequestStatusInProgress, true},
+		{entity.MPRequestStatusCompleted, false},
+		{entity.MPRequestStatusRejected, false},
+	}
+
+	for _, tt := range tests {
+		if got := HoldsBudget(tt.status); got != tt.want {
+			t.Errorf("HoldsBudget(%s) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
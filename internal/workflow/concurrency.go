@@ -0,0 +1,30 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+)
+
+var (
+	ErrVersionConflict     = errors.New("the document was changed by someone else, reload it and try again")
+	ErrPlanningLinesLocked = errors.New("planning lines can only be changed while the planning is drafted or rejected")
+)
+
+// CheckVersion tells whether a change made on version may be saved over the current one.
+func CheckVersion(current int, version int) error {
+	if current != version {
+		return fmt.Errorf("%w: it is at version %d, not %d", ErrVersionConflict, current, version)
+	}
+	return nil
+}
+
+// CheckPlanningLinesEditable tells whether the lines of a planning in status may still change.
+func CheckPlanningLinesEditable(status entity.MPPlaningStatus) error {
+	switch status {
+	case entity.MPPlaningStatusDraft, entity.MPPlaningStatusReject:
+		return nil
+	}
+	return fmt.Errorf("%w: it is %s", ErrPlanningLinesLocked, status)
+}
@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+)
+
+func TestCheckVersion(t *testing.T) {
+	if err := CheckVersion(3, 3); err != nil {
+		t.Errorf("same version: %v", err)
+	}
+
+	err := CheckVersion(4, 3)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+	if !strings.Contains(err.Error(), "it is at version 4, not 3") {
+		t.Errorf("err = %v, want it to name both versions", err)
+	}
+}
+
+func TestCheckPlanningLinesEditable(t *testing.T) {
+	tests := []struct {
+		status   entity.MPPlaningStatus
+		editable bool
+	}{
+		{entity.MPPlaningStatusDraft, true},
+		{entity.MPPlaningStatusReject, true},
+		{entity.MPPlaningStatusSubmit, false},
+		{entity.MPPlaningStatusApproved, false},
+		{entity.MPPlaningStatusComplete, false},
+		{entity.MPPlanningStatusInProgress, false},
+		{entity.MPPlaningStatusNeedApproval, false},
+	}
+
+	for _, tt := range tests {
+		err := CheckPlanningLinesEditable(tt.status)
+		if tt.editable {
+			if err != nil {
+				t.Errorf("%s: %v", tt.status, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrPlanningLinesLocked) {
+			t.Errorf("%s: err = %v, want ErrPlanningLinesLocked", tt.status, err)
+		}
+	}
+}
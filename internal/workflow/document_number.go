@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NumberSequence names a series of document numbers.
+type NumberSequence string
+
+const (
+	NumberSequenceMPPlanning    NumberSequence = "mp_planning"
+	NumberSequenceMPRequest     NumberSequence = "mp_request"
+	NumberSequenceBatchCEO      NumberSequence = "batch_ceo"
+	NumberSequenceBatchDirector NumberSequence = "batch_director"
+)
+
+type DocumentNumberReset string
+
+const (
+	DocumentNumberResetDaily   DocumentNumberReset = "daily"
+	DocumentNumberResetMonthly DocumentNumberReset = "monthly"
+	DocumentNumberResetYearly  DocumentNumberReset = "yearly"
+	DocumentNumberResetNever   DocumentNumberReset = "never"
+)
+
+// DocumentNumberFormat is how the numbers of a document type look and when they start again.
+type DocumentNumberFormat struct {
+	Prefix     string
+	DateLayout string
+	Padding    int
+	Reset      DocumentNumberReset
+}
+
+// DefaultDocumentNumberFormats are the formats used before they could be configured.
+var DefaultDocumentNumberFormats = map[NumberSequence]DocumentNumberFormat{
+	NumberSequenceMPPlanning:    {Prefix: "MPP", DateLayout: "20060102", Padding: 3, Reset: DocumentNumberResetDaily},
+	NumberSequenceMPRequest:     {Prefix: "MPR", DateLayout: "20060102", Padding: 3, Reset: DocumentNumberResetDaily},
+	NumberSequenceBatchCEO:      {Prefix: "MPP/BATCH", DateLayout: "20060102", Padding: 3, Reset: DocumentNumberResetDaily},
+	NumberSequenceBatchDirector: {Prefix: "MPP/BATCH/DIR", DateLayout: "20060102", Padding: 3, Reset: DocumentNumberResetDaily},
+}
+
+// Period is the key of the sequence a document made at t is numbered from.
+func (f DocumentNumberFormat) Period(t time.Time) string {
+	switch f.Reset {
+	case DocumentNumberResetDaily:
+		return t.Format("20060102")
+	case DocumentNumberResetMonthly:
+		return t.Format("200601")
+	case DocumentNumberResetYearly:
+		return t.Format("2006")
+	default:
+		return ""
+	}
+}
+
+// Head is the part of a number made at t before the sequence number, trailing slash included.
+func (f DocumentNumberFormat) Head(t time.Time) string {
+	var parts []string
+	if f.Prefix != "" {
+		parts = append(parts, f.Prefix)
+	}
+	if f.DateLayout != "" {
+		parts = append(parts, t.Format(f.DateLayout))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "/") + "/"
+}
+
+// Format is the document number with the given sequence number.
+func (f DocumentNumberFormat) Format(t time.Time, number int) string {
+	return f.Head(t) + fmt.Sprintf("%0*d", f.Padding, number)
+}
+
+// SequenceNumber reads the sequence number back from a document number made at t.
+func (f DocumentNumberFormat) SequenceNumber(t time.Time, documentNumber string) (int, bool) {
+	head := f.Head(t)
+	if !strings.HasPrefix(documentNumber, head) {
+		return 0, false
+	}
+	rest := documentNumber[len(head):]
+	if rest == "" || strings.Trim(rest, "0123456789") != "" {
+		return 0, false
+	}
+	number, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+// DocumentNumbering is the number a document is to be given when it is saved.
+type DocumentNumbering struct {
+	Sequence NumberSequence
+	Format   DocumentNumberFormat
+	At       time.Time
+	Table    string
+}
@@ -0,0 +1,93 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDocumentNumberFormatPeriod(t *testing.T) {
+	at := time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		reset DocumentNumberReset
+		want  string
+	}{
+		{DocumentNumberResetDaily, "20261017"},
+		{DocumentNumberResetMonthly, "202610"},
+		{DocumentNumberResetYearly, "2026"},
+		{DocumentNumberResetNever, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := (DocumentNumberFormat{Reset: tt.reset}).Period(at); got != tt.want {
+			t.Errorf("Period with reset %q = %q, want %q", tt.reset, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentNumberFormatFormat(t *testing.T) {
+	at := time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format DocumentNumberFormat
+		number int
+		want   string
+	}{
+		{"planning default", DefaultDocumentNumberFormats[NumberSequenceMPPlanning], 7, "MPP/20261017/007"},
+		{"director batch default", DefaultDocumentNumberFormats[NumberSequenceBatchDirector], 12, "MPP/BATCH/DIR/20261017/012"},
+		{"number wider than the padding", DocumentNumberFormat{Prefix: "MPR", Padding: 2}, 1234, "MPR/1234"},
+		{"no date", DocumentNumberFormat{Prefix: "MPR", Padding: 5}, 3, "MPR/00003"},
+		{"no prefix", DocumentNumberFormat{DateLayout: "2006", Padding: 3}, 3, "2026/003"},
+		{"bare number", DocumentNumberFormat{}, 42, "42"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.Format(at, tt.number); got != tt.want {
+			t.Errorf("%s: Format = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentNumberFormatSequenceNumber(t *testing.T) {
+	at := time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC)
+	planning := DefaultDocumentNumberFormats[NumberSequenceMPPlanning]
+	batch := DefaultDocumentNumberFormats[NumberSequenceBatchCEO]
+
+	tests := []struct {
+		name           string
+		format         DocumentNumberFormat
+		documentNumber string
+		want           int
+		wantOK         bool
+	}{
+		{"own number", planning, "MPP/20261017/007", 7, true},
+		{"past the padding", planning, "MPP/20261017/1001", 1001, true},
+		{"another day", planning, "MPP/20261016/007", 0, false},
+		{"a batch is not a planning", planning, "MPP/BATCH/20261017/007", 0, false},
+		{"a director batch is not a CEO batch", batch, "MPP/BATCH/DIR/20261017/001", 0, false},
+		{"no number", planning, "MPP/20261017/", 0, false},
+		{"not a number", planning, "MPP/20261017/00A", 0, false},
+		{"a signed number", planning, "MPP/20261017/-01", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.format.SequenceNumber(at, tt.documentNumber)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: SequenceNumber(%q) = %d, %v, want %d, %v", tt.name, tt.documentNumber, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDocumentNumberFormatRoundTrip(t *testing.T) {
+	at := time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)
+	for sequence, format := range DefaultDocumentNumberFormats {
+		for _, number := range []int{1, 99, 1000} {
+			got, ok := format.SequenceNumber(at, format.Format(at, number))
+			if !ok || got != number {
+				t.Errorf("%s: %d read back as %d, %v", sequence, number, got, ok)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrIllegalTransition = errors.New("illegal status transition")
+	ErrNotNextApprover   = errors.New("approver is not the next approver in the approval chain")
+	ErrInvalidDelegation = errors.New("approver has no active delegation from the person they act for")
+	ErrNotDelegator      = errors.New("only the delegator can manage this delegation")
+)
+
+type TransitionError struct {
+	DocumentType DocumentType
+	From         string
+	To           string
+	Level        string
+	Reason       string
+}
+
+func (e *TransitionError) Error() string {
+	if e.Level != "" {
+		return fmt.Sprintf("%s: %s cannot move from %s to %s as %s (%s)", ErrIllegalTransition.Error(), e.DocumentType, e.From, e.To, e.Level, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s cannot move from %s to %s (%s)", ErrIllegalTransition.Error(), e.DocumentType, e.From, e.To, e.Reason)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrIllegalTransition
+}
+
+func IsTransitionError(err error) bool {
+	var transitionErr *TransitionError
+	return errors.As(err, &transitionErr)
+}
@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+type PlafonEnforcement string
+
+const (
+	PlafonEnforcementBlock PlafonEnforcement = "block"
+	PlafonEnforcementWarn  PlafonEnforcement = "warn"
+	PlafonEnforcementOff   PlafonEnforcement = "off"
+)
+
+var (
+	ErrOverPlafon               = errors.New("headcount exceeds the job plafon")
+	ErrPlafonOverrideUnresolved = errors.New("plafon override has not been approved")
+)
+
+// ParsePlafonEnforcement reads an enforcement mode from the config, warn when unknown.
+func ParsePlafonEnforcement(value string) PlafonEnforcement {
+	switch PlafonEnforcement(strings.ToLower(strings.TrimSpace(value))) {
+	case PlafonEnforcementBlock:
+		return PlafonEnforcementBlock
+	case PlafonEnforcementOff:
+		return PlafonEnforcementOff
+	default:
+		return PlafonEnforcementWarn
+	}
+}
+
+// PlafonCheck is the headcount of a job in a period against its plafon, zero meaning no cap.
+type PlafonCheck struct {
+	MPPPeriodID uuid.UUID
+	JobID       uuid.UUID
+	Plafon      int
+	Headcount   int
+}
+
+func (c *PlafonCheck) Over() bool {
+	return c != nil && c.Plafon > 0 && c.Headcount > c.Plafon
+}
+
+type OverPlafonError struct {
+	JobID     uuid.UUID
+	Plafon    int
+	Headcount int
+}
+
+func (e *OverPlafonError) Error() string {
+	return fmt.Sprintf("%s: job %s would reach %d against a plafon of %d, give a plafon_override_reason to request an override", ErrOverPlafon.Error(), e.JobID, e.Headcount, e.Plafon)
+}
+
+func (e *OverPlafonError) Unwrap() error {
+	return ErrOverPlafon
+}
+
+// PlafonDecision is what a save does with the plafon flags of the document.
+type PlafonDecision struct {
+	IsOverPlafon    bool
+	OverrideStatus  entity.PlafonOverrideStatus
+	RequestOverride bool
+}
+
+// DecidePlafon applies the enforcement mode to a check, honouring an override that still covers it.
+func DecidePlafon(mode PlafonEnforcement, check *PlafonCheck, reason string, last *entity.PlafonOverride) (*PlafonDecision, error) {
+	if mode == PlafonEnforcementOff || !check.Over() {
+		return &PlafonDecision{}, nil
+	}
+
+	if mode == PlafonEnforcementWarn {
+		return &PlafonDecision{IsOverPlafon: true}, nil
+	}
+
+	if last != nil && last.Status != entity.PlafonOverrideStatusRejected && check.Headcount <= last.Headcount {
+		return &PlafonDecision{IsOverPlafon: true, OverrideStatus: last.Status}, nil
+	}
+
+	if strings.TrimSpace(reason) != "" {
+		return &PlafonDecision{IsOverPlafon: true, OverrideStatus: entity.PlafonOverrideStatusPending, RequestOverride: true}, nil
+	}
+
+	return nil, &OverPlafonError{JobID: check.JobID, Plafon: check.Plafon, Headcount: check.Headcount}
+}
+
+// PlafonOverrideBlocks reports whether a document over plafon still lacks an approved override.
+func PlafonOverrideBlocks(isOverPlafon bool, status entity.PlafonOverrideStatus) bool {
+	return isOverPlafon && (status == entity.PlafonOverrideStatusPending || status == entity.PlafonOverrideStatusRejected)
+}
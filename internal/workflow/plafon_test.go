@@ -0,0 +1,164 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestParsePlafonEnforcement(t *testing.T) {
+	tests := []struct {
+		value string
+		want  PlafonEnforcement
+	}{
+		{"block", PlafonEnforcementBlock},
+		{" BLOCK ", PlafonEnforcementBlock},
+		{"off", PlafonEnforcementOff},
+		{"warn", PlafonEnforcementWarn},
+		{"", PlafonEnforcementWarn},
+		{"strict", PlafonEnforcementWarn},
+	}
+
+	for _, tt := range tests {
+		if got := ParsePlafonEnforcement(tt.value); got != tt.want {
+			t.Errorf("ParsePlafonEnforcement(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPlafonCheckOver(t *testing.T) {
+	tests := []struct {
+		name  string
+		check *PlafonCheck
+		want  bool
+	}{
+		{"no check", nil, false},
+		{"no cap", &PlafonCheck{Plafon: 0, Headcount: 100}, false},
+		{"under", &PlafonCheck{Plafon: 10, Headcount: 9}, false},
+		{"at", &PlafonCheck{Plafon: 10, Headcount: 10}, false},
+		{"over", &PlafonCheck{Plafon: 10, Headcount: 11}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.check.Over(); got != tt.want {
+			t.Errorf("%s: Over() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecidePlafon(t *testing.T) {
+	over := &PlafonCheck{JobID: uuid.New(), Plafon: 10, Headcount: 12}
+	under := &PlafonCheck{JobID: uuid.New(), Plafon: 10, Headcount: 8}
+
+	tests := []struct {
+		name    string
+		mode    PlafonEnforcement
+		check   *PlafonCheck
+		reason  string
+		last    *entity.PlafonOverride
+		want    PlafonDecision
+		wantErr bool
+	}{
+		{
+			name:  "off ignores the plafon",
+			mode:  PlafonEnforcementOff,
+			check: over,
+			want:  PlafonDecision{},
+		},
+		{
+			name:  "under the plafon",
+			mode:  PlafonEnforcementBlock,
+			check: under,
+			want:  PlafonDecision{},
+		},
+		{
+			name:  "warn only flags",
+			mode:  PlafonEnforcementWarn,
+			check: over,
+			want:  PlafonDecision{IsOverPlafon: true},
+		},
+		{
+			name:    "block without a reason",
+			mode:    PlafonEnforcementBlock,
+			check:   over,
+			reason:  "  ",
+			wantErr: true,
+		},
+		{
+			name:   "block with a reason asks for an override",
+			mode:   PlafonEnforcementBlock,
+			check:  over,
+			reason: "new site",
+			want:   PlafonDecision{IsOverPlafon: true, OverrideStatus: entity.PlafonOverrideStatusPending, RequestOverride: true},
+		},
+		{
+			name:  "an approved override keeps covering the same headcount",
+			mode:  PlafonEnforcementBlock,
+			check: over,
+			last:  &entity.PlafonOverride{Status: entity.PlafonOverrideStatusApproved, Headcount: 12},
+			want:  PlafonDecision{IsOverPlafon: true, OverrideStatus: entity.PlafonOverrideStatusApproved},
+		},
+		{
+			name:  "a pending override keeps covering a smaller headcount",
+			mode:  PlafonEnforcementBlock,
+			check: over,
+			last:  &entity.PlafonOverride{Status: entity.PlafonOverrideStatusPending, Headcount: 15},
+			want:  PlafonDecision{IsOverPlafon: true, OverrideStatus: entity.PlafonOverrideStatusPending},
+		},
+		{
+			name:    "an override does not cover a larger headcount",
+			mode:    PlafonEnforcementBlock,
+			check:   over,
+			last:    &entity.PlafonOverride{Status: entity.PlafonOverrideStatusApproved, Headcount: 11},
+			wantErr: true,
+		},
+		{
+			name:   "a rejected override needs a new one",
+			mode:   PlafonEnforcementBlock,
+			check:  over,
+			reason: "asked again",
+			last:   &entity.PlafonOverride{Status: entity.PlafonOverrideStatusRejected, Headcount: 12},
+			want:   PlafonDecision{IsOverPlafon: true, OverrideStatus: entity.PlafonOverrideStatusPending, RequestOverride: true},
+		},
+	}
+
+	for _, tt := range tests {
+		decision, err := DecidePlafon(tt.mode, tt.check, tt.reason, tt.last)
+		if tt.wantErr {
+			var overErr *OverPlafonError
+			if !errors.As(err, &overErr) || !errors.Is(err, ErrOverPlafon) {
+				t.Errorf("%s: err = %v, want an OverPlafonError", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if *decision != tt.want {
+			t.Errorf("%s: decision = %+v, want %+v", tt.name, *decision, tt.want)
+		}
+	}
+}
+
+func TestPlafonOverrideBlocks(t *testing.T) {
+	tests := []struct {
+		isOverPlafon bool
+		status       entity.PlafonOverrideStatus
+		want         bool
+	}{
+		{false, entity.PlafonOverrideStatusPending, false},
+		{true, "", false},
+		{true, entity.PlafonOverrideStatusApproved, false},
+		{true, entity.PlafonOverrideStatusPending, true},
+		{true, entity.PlafonOverrideStatusRejected, true},
+	}
+
+	for _, tt := range tests {
+		if got := PlafonOverrideBlocks(tt.isOverPlafon, tt.status); got != tt.want {
+			t.Errorf("PlafonOverrideBlocks(%v, %q) = %v, want %v", tt.isOverPlafon, tt.status, got, tt.want)
+		}
+	}
+}
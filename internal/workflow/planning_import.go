@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPlanningImportUnreadable = errors.New("the planning line import cannot be read")
+	ErrPlanningImportInvalid    = errors.New("the planning line import has rows with errors")
+	ErrPlanningImportConfirmed  = errors.New("the planning line import is already confirmed")
+	ErrPlanningImportRequest    = errors.New("the planning line import request is invalid")
+)
+
+// Columns of a planning line import. Headers are matched without regard to
+// case, spaces, dashes or underscores.
+const (
+	PlanningImportColumnLocation  = "location"
+	PlanningImportColumnJobLevel  = "job level"
+	PlanningImportColumnJob       = "job"
+	PlanningImportColumnExisting  = "existing"
+	PlanningImportColumnRecruitMT = "recruit mt"
+	PlanningImportColumnRecruitPH = "recruit ph"
+	PlanningImportColumnPromotion = "promotion"
+)
+
+var PlanningImportColumns = []string{
+	PlanningImportColumnLocation,
+	PlanningImportColumnJobLevel,
+	PlanningImportColumnJob,
+	PlanningImportColumnExisting,
+	PlanningImportColumnRecruitMT,
+	PlanningImportColumnRecruitPH,
+	PlanningImportColumnPromotion,
+}
+
+// planningImportAliases are the other headers accepted for a column.
+var planningImportAliases = map[string]string{
+	"organization location": PlanningImportColumnLocation,
+	"job name":              PlanningImportColumnJob,
+	"promote":               PlanningImportColumnPromotion,
+}
+
+// PlanningImportHeader maps each column of the import to its index in the header row.
+func PlanningImportHeader(header []string) (map[string]int, error) {
+	indexes := make(map[string]int, len(PlanningImportColumns))
+	for i, cell := range header {
+		column := normalizeImportHeader(cell)
+		if alias, ok := planningImportAliases[column]; ok {
+			column = alias
+		}
+		if !containsColumn(PlanningImportColumns, column) {
+			continue
+		}
+		if _, ok := indexes[column]; ok {
+			return nil, fmt.Errorf("column %q is given twice", column)
+		}
+		indexes[column] = i
+	}
+
+	var missing []string
+	for _, column := range PlanningImportColumns {
+		if _, ok := indexes[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	return indexes, nil
+}
+
+// PlanningImportLine is the headcount of an imported row.
+type PlanningImportLine struct {
+	Existing  int
+	RecruitMT int
+	RecruitPH int
+	Promotion int
+}
+
+// Recruit is what the line recruits, MT and PH together.
+func (l *PlanningImportLine) Recruit() int {
+	return l.RecruitMT + l.RecruitPH
+}
+
+// Total is the headcount the line plans for, as the batch document sums it.
+func (l *PlanningImportLine) Total() int {
+	return l.Existing + l.Promotion + l.Recruit()
+}
+
+// Validate returns what is wrong with the headcount of the line.
+func (l *PlanningImportLine) Validate() []string {
+	var problems []string
+	for _, count := range []struct {
+		column string
+		value  int
+	}{
+		{PlanningImportColumnExisting, l.Existing},
+		{PlanningImportColumnRecruitMT, l.RecruitMT},
+		{PlanningImportColumnRecruitPH, l.RecruitPH},
+		{PlanningImportColumnPromotion, l.Promotion},
+	} {
+		if count.value < 0 {
+			problems = append(problems, count.column+" cannot be negative")
+		}
+	}
+	if l.RecruitMT == 0 && l.RecruitPH == 0 {
+		problems = append(problems, "recruit mt and recruit ph cannot both be 0")
+	}
+	return problems
+}
+
+// ParseImportCount reads a headcount cell, empty as 0 and whole decimals accepted.
+func ParseImportCount(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f != float64(int(f)) {
+		return 0, fmt.Errorf("%q is not a whole number", value)
+	}
+	return int(f), nil
+}
+
+// NormalizeImportName is how imported names are compared to the names of the portal.
+func NormalizeImportName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func normalizeImportHeader(header string) string {
+	header = strings.NewReplacer("_", " ", "-", " ").Replace(header)
+	return NormalizeImportName(header)
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
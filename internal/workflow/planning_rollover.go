@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+var ErrPlanningRolloverInvalid = errors.New("the planning cannot be copied between these periods")
+
+// PlanningRolloverSourceStatuses are the statuses a planning needs before it can be copied.
+var PlanningRolloverSourceStatuses = []entity.MPPlaningStatus{
+	entity.MPPlaningStatusApproved,
+	entity.MPPlaningStatusComplete,
+}
+
+// CheckPlanningRolloverPeriods tells whether the plannings of source may be copied into target.
+func CheckPlanningRolloverPeriods(source *entity.MPPPeriod, target *entity.MPPPeriod) error {
+	if source.ID == target.ID {
+		return fmt.Errorf("%w: the source and the target period are the same", ErrPlanningRolloverInvalid)
+	}
+	if target.Status == entity.MPPeriodStatusClose || target.Status == entity.MPPeriodStatusComplete {
+		return fmt.Errorf("%w: period %s is %s", ErrPlanningRolloverInvalid, target.Title, target.Status)
+	}
+	return nil
+}
+
+// LatestPlanningsByLocation keeps the first planning of every organization location.
+func LatestPlanningsByLocation(headers []entity.MPPlanningHeader) []entity.MPPlanningHeader {
+	seen := make(map[uuid.UUID]bool, len(headers))
+	var latest []entity.MPPlanningHeader
+	for _, header := range headers {
+		if header.OrganizationLocationID == nil || seen[*header.OrganizationLocationID] {
+			continue
+		}
+		seen[*header.OrganizationLocationID] = true
+		latest = append(latest, header)
+	}
+	return latest
+}
+
+// PlanningRolloverDocumentDate is today while it falls in the period, otherwise its first day.
+func PlanningRolloverDocumentDate(target *entity.MPPPeriod, today time.Time) time.Time {
+	if today.Before(target.StartDate) || today.After(target.EndDate) {
+		return target.StartDate
+	}
+	return today
+}
+
+// RolloverLine is the draft line copied from a line of an earlier planning.
+func RolloverLine(source *entity.MPPlanningLine, existing int, carryOverBalance bool) entity.MPPlanningLine {
+	counts := PlanningImportLine{
+		Existing:  existing,
+		RecruitPH: source.RecruitPH,
+		RecruitMT: source.RecruitMT,
+		Promotion: source.Promotion,
+	}
+	if carryOverBalance {
+		counts.RecruitPH += source.RemainingBalancePH
+		counts.RecruitMT += source.RemainingBalanceMT
+	}
+
+	return entity.MPPlanningLine{
+		OrganizationLocationID: source.OrganizationLocationID,
+		JobLevelID:             source.JobLevelID,
+		JobID:                  source.JobID,
+		Existing:               counts.Existing,
+		Recruit:                counts.Recruit(),
+		SuggestedRecruit:       source.SuggestedRecruit,
+		Promotion:              counts.Promotion,
+		Total:                  counts.Total(),
+		RecruitPH:              counts.RecruitPH,
+		RemainingBalancePH:     counts.RecruitPH,
+		RecruitMT:              counts.RecruitMT,
+		RemainingBalanceMT:     counts.RecruitMT,
+	}
+}
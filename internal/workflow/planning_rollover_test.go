@@ -0,0 +1,145 @@
+package workflow
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestCheckPlanningRolloverPeriods(t *testing.T) {
+	source := &entity.MPPPeriod{ID: uuid.New(), Title: "2025", Status: entity.MPPeriodStatusComplete}
+
+	tests := []struct {
+		name   string
+		target *entity.MPPPeriod
+		wantOK bool
+	}{
+		{"open period", &entity.MPPPeriod{ID: uuid.New(), Status: entity.MPPeriodStatusOpen}, true},
+		{"not open yet", &entity.MPPPeriod{ID: uuid.New(), Status: entity.MPPeriodStatusNotOpen}, true},
+		{"draft period", &entity.MPPPeriod{ID: uuid.New(), Status: entity.MPPPeriodStatusDraft}, true},
+		{"closed period", &entity.MPPPeriod{ID: uuid.New(), Status: entity.MPPeriodStatusClose}, false},
+		{"completed period", &entity.MPPPeriod{ID: uuid.New(), Status: entity.MPPeriodStatusComplete}, false},
+		{"the same period", &entity.MPPPeriod{ID: source.ID, Status: entity.MPPeriodStatusOpen}, false},
+	}
+
+	for _, tt := range tests {
+		err := CheckPlanningRolloverPeriods(source, tt.target)
+		if tt.wantOK {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrPlanningRolloverInvalid) {
+			t.Errorf("%s: err = %v, want ErrPlanningRolloverInvalid", tt.name, err)
+		}
+	}
+}
+
+func TestLatestPlanningsByLocation(t *testing.T) {
+	north, south := uuid.New(), uuid.New()
+	headers := []entity.MPPlanningHeader{
+		{DocumentNumber: "north new", OrganizationLocationID: &north},
+		{DocumentNumber: "no location"},
+		{DocumentNumber: "south", OrganizationLocationID: &south},
+		{DocumentNumber: "north old", OrganizationLocationID: &north},
+	}
+
+	var got []string
+	for _, header := range LatestPlanningsByLocation(headers) {
+		got = append(got, header.DocumentNumber)
+	}
+	if want := []string{"north new", "south"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("latest = %q, want %q", got, want)
+	}
+}
+
+func TestPlanningRolloverDocumentDate(t *testing.T) {
+	target := &entity.MPPPeriod{
+		StartDate: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2027, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		today time.Time
+		want  time.Time
+	}{
+		{time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC), target.StartDate},
+		{time.Date(2027, time.March, 5, 0, 0, 0, 0, time.UTC), time.Date(2027, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{target.EndDate, target.EndDate},
+		{time.Date(2028, time.January, 1, 0, 0, 0, 0, time.UTC), target.StartDate},
+	}
+
+	for _, tt := range tests {
+		if got := PlanningRolloverDocumentDate(target, tt.today); !got.Equal(tt.want) {
+			t.Errorf("PlanningRolloverDocumentDate(%s) = %s, want %s", tt.today.Format(time.DateOnly), got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestRolloverLine(t *testing.T) {
+	location, jobLevel, job := uuid.New(), uuid.New(), uuid.New()
+	source := &entity.MPPlanningLine{
+		ID:                     uuid.New(),
+		OrganizationLocationID: &location,
+		JobLevelID:             &jobLevel,
+		JobID:                  &job,
+		Existing:               10,
+		Recruit:                5,
+		SuggestedRecruit:       4,
+		Promotion:              1,
+		Total:                  16,
+		RecruitPH:              3,
+		RemainingBalancePH:     1,
+		RecruitMT:              2,
+		RemainingBalanceMT:     2,
+		IsOverPlafon:           true,
+		PlafonOverrideStatus:   entity.PlafonOverrideStatusApproved,
+		Version:                4,
+	}
+
+	tests := []struct {
+		name             string
+		carryOverBalance bool
+		want             entity.MPPlanningLine
+	}{
+		{"own recruits", false, entity.MPPlanningLine{
+			OrganizationLocationID: &location,
+			JobLevelID:             &jobLevel,
+			JobID:                  &job,
+			Existing:               12,
+			Recruit:                5,
+			SuggestedRecruit:       4,
+			Promotion:              1,
+			Total:                  18,
+			RecruitPH:              3,
+			RemainingBalancePH:     3,
+			RecruitMT:              2,
+			RemainingBalanceMT:     2,
+		}},
+		{"with the unfilled recruits", true, entity.MPPlanningLine{
+			OrganizationLocationID: &location,
+			JobLevelID:             &jobLevel,
+			JobID:                  &job,
+			Existing:               12,
+			Recruit:                8,
+			SuggestedRecruit:       4,
+			Promotion:              1,
+			Total:                  21,
+			RecruitPH:              4,
+			RemainingBalancePH:     4,
+			RecruitMT:              4,
+			RemainingBalanceMT:     4,
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := RolloverLine(source, 12, tt.carryOverBalance); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: RolloverLine = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
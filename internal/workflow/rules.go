@@ -0,0 +1,131 @@
+package workflow
+
+import "github.com/IlhamSetiaji/julong-manpower-be/internal/entity"
+
+var (
+	approvalEffects = []SideEffect{SideEffectRecordApprovalHistory, SideEffectAssignApprover}
+	rejectEffects   = []SideEffect{SideEffectRecordApprovalHistory, SideEffectClearApprovers}
+	startEffects    = []SideEffect{SideEffectStartApprovalChain}
+)
+
+func DefaultRules() map[DocumentType][]Transition {
+	return map[DocumentType][]Transition{
+		DocumentTypeMPPlanning: MPPlanningRules(),
+		DocumentTypeMPRequest:  MPRequestRules(),
+		DocumentTypeBatch:      BatchRules(),
+	}
+}
+
+func MPPlanningRules() []Transition {
+	var (
+		draft        = string(entity.MPPlaningStatusDraft)
+		submitted    = string(entity.MPPlaningStatusSubmit)
+		inProgress   = string(entity.MPPlanningStatusInProgress)
+		needApproval = string(entity.MPPlaningStatusNeedApproval)
+		approved     = string(entity.MPPlaningStatusApproved)
+		rejected     = string(entity.MPPlaningStatusReject)
+		completed    = string(entity.MPPlaningStatusComplete)
+
+		hrdUnit      = string(entity.MPPlanningApprovalHistoryLevelHRDUnit)
+		direkturUnit = string(entity.MPPlanningApprovalHistoryLevelDirekturUnit)
+		recruitment  = string(entity.MPPlanningApprovalHistoryLevelRecruitment)
+		ceo          = string(entity.MPPlanningApprovalHistoryLevelCEO)
+	)
+
+	return []Transition{
+		// requestor side
+		{From: draft, To: draft},
+		{From: draft, To: submitted, SideEffects: startEffects},
+		{From: rejected, To: draft},
+		{From: rejected, To: submitted, SideEffects: startEffects},
+		{From: submitted, To: draft},
+
+		// HRD unit review
+		{From: submitted, To: inProgress, Levels: []string{hrdUnit}, SideEffects: approvalEffects},
+		{From: submitted, To: needApproval, Levels: []string{hrdUnit}, SideEffects: approvalEffects},
+		{From: submitted, To: rejected, Levels: []string{hrdUnit}, SideEffects: rejectEffects},
+		{From: inProgress, To: needApproval, Levels: []string{hrdUnit}, SideEffects: approvalEffects},
+		{From: inProgress, To: rejected, Levels: []string{hrdUnit, direkturUnit}, SideEffects: rejectEffects},
+
+		// director and CEO batches
+		{From: needApproval, To: needApproval, Levels: []string{hrdUnit, recruitment}, SideEffects: approvalEffects},
+		{From: needApproval, To: approved, Levels: []string{direkturUnit, recruitment, ceo}, SideEffects: approvalEffects},
+		{From: needApproval, To: rejected, Levels: []string{direkturUnit, recruitment, ceo}, SideEffects: rejectEffects},
+		{From: approved, To: needApproval, Levels: []string{recruitment}, SideEffects: approvalEffects},
+		{From: approved, To: rejected, Levels: []string{recruitment, ceo}, SideEffects: rejectEffects},
+		{From: approved, To: completed, Levels: []string{recruitment, ceo}},
+	}
+}
+
+func MPRequestRules() []Transition {
+	var (
+		draft        = string(entity.MPRequestStatusDraft)
+		submitted    = string(entity.MPRequestStatusSubmitted)
+		needApproval = string(entity.MPRequestStatusNeedApproval)
+		approved     = string(entity.MPRequestStatusApproved)
+		rejected     = string(entity.MPRequestStatusRejected)
+		inProgress   = string(entity.MPRequestStatusInProgress)
+		completed    = string(entity.MPRequestStatusCompleted)
+
+		staff    = string(entity.MPRequestApprovalHistoryLevelStaff)
+		headDept = string(entity.MPRequestApprovalHistoryLevelHeadDept)
+		vp       = string(entity.MPRequestApprovalHistoryLevelVP)
+		ceo      = string(entity.MPRequestApprovalHistoryLevelCEO)
+		hrdHO    = string(entity.MPPRequestApprovalHistoryLevelHRDHO)
+	)
+
+	approvers := []string{headDept, vp, ceo}
+	rejecters := []string{headDept, vp, ceo, hrdHO}
+	completeEffects := append([]SideEffect{SideEffectConsumePlanningBalance, SideEffectCloneMPRequest}, approvalEffects...)
+	submitEffects := append([]SideEffect{SideEffectReservePlanningBalance}, startEffects...)
+	staffSubmitEffects := append([]SideEffect{SideEffectReservePlanningBalance}, approvalEffects...)
+	releaseEffects := append([]SideEffect{SideEffectReleasePlanningBalance}, rejectEffects...)
+
+	return []Transition{
+		// requestor side
+		{From: draft, To: draft},
+		{From: draft, To: submitted, SideEffects: submitEffects},
+		{From: rejected, To: draft},
+		{From: rejected, To: submitted, SideEffects: submitEffects},
+		{From: submitted, To: draft, SideEffects: []SideEffect{SideEffectReleasePlanningBalance}},
+		{From: draft, To: needApproval, Levels: []string{staff}, SideEffects: staffSubmitEffects},
+		{From: rejected, To: needApproval, Levels: []string{staff}, SideEffects: staffSubmitEffects},
+
+		// approval chain
+		{From: submitted, To: needApproval, Levels: []string{staff, headDept, vp}, SideEffects: approvalEffects},
+		{From: submitted, To: approved, Levels: approvers, SideEffects: approvalEffects},
+		{From: submitted, To: rejected, Levels: rejecters, SideEffects: releaseEffects},
+		{From: needApproval, To: needApproval, Levels: approvers, SideEffects: approvalEffects},
+		{From: needApproval, To: approved, Levels: approvers, SideEffects: approvalEffects},
+		{From: needApproval, To: rejected, Levels: rejecters, SideEffects: releaseEffects},
+
+		// HRD HO fulfilment
+		{From: approved, To: inProgress, Levels: []string{hrdHO}, SideEffects: approvalEffects},
+		{From: approved, To: rejected, Levels: []string{hrdHO}, SideEffects: releaseEffects},
+		{From: approved, To: completed, Levels: []string{hrdHO}, SideEffects: completeEffects},
+		{From: inProgress, To: rejected, Levels: []string{hrdHO}, SideEffects: releaseEffects},
+		{From: inProgress, To: completed, Levels: []string{hrdHO}, SideEffects: completeEffects},
+	}
+}
+
+func BatchRules() []Transition {
+	var (
+		needApproval = string(entity.BatchHeaderApprovalStatusNeedApproval)
+		approved     = string(entity.BatchHeaderApprovalStatusApproved)
+		rejected     = string(entity.BatchHeaderApprovalStatusRejected)
+		completed    = string(entity.BatchHeaderApprovalStatusCompleted)
+
+		director = string(entity.BatchHeaderApproverTypeDirector)
+		ceo      = string(entity.BatchHeaderApproverTypeCEO)
+	)
+
+	cascade := []SideEffect{SideEffectCascadePlanningStatus, SideEffectRecordApprovalHistory, SideEffectAssignApprover}
+
+	return []Transition{
+		{From: needApproval, To: approved, Levels: []string{director, ceo}, SideEffects: cascade},
+		{From: needApproval, To: rejected, Levels: []string{director, ceo}, SideEffects: cascade},
+		{From: rejected, To: needApproval, Levels: []string{director, ceo}},
+		{From: approved, To: completed, Levels: []string{ceo}, SideEffects: []SideEffect{SideEffectCascadePlanningStatus, SideEffectCompletePeriod}},
+		{From: approved, To: completed, Levels: []string{director}},
+	}
+}
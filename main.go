@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	// "github.com/IlhamSetiaji/go-rabbitmq-utils/rabbitmq"
@@ -11,6 +15,7 @@ import (
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/route"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/scheduler"
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/rabbitmq"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -23,8 +28,20 @@ func main() {
 	viper := config.NewViper()
 	log := config.NewLogrus(viper)
 
-	go rabbitmq.InitConsumer(viper, log)
-	go rabbitmq.InitProducer(viper, log)
+	// stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// rabbitmq has its own context because it is closed last. The consumer is
+	// registered after the producer so it is stopped first and the replies of
+	// its in-flight message are still published.
+	rabbitmqCtx, stopRabbitmq := context.WithCancel(context.Background())
+	connectionManager := rabbitmq.ConnectionManagerFactory(viper, log)
+	rabbitmqDone := make(chan struct{})
+	go func() {
+		defer close(rabbitmqDone)
+		connectionManager.Run(rabbitmqCtx, rabbitmq.ProducerSession(log), rabbitmq.ConsumerSession(viper, log))
+	}()
 
 	// err := rabbitmq.InitializeConnection(viper.GetString("rabbitmq.url"))
 	// if err != nil {
@@ -47,8 +64,8 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     strings.Split(viper.GetString("frontend.urls"), ","), // Frontend URL
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -72,8 +89,7 @@ func main() {
 	routeConfig.SetupRoutes()
 
 	// setup cron & scheduler
-	jakartaTime, _ := time.LoadLocation("Asia/Jakarta")
-	sch := cron.New(cron.WithLocation(jakartaTime))
+	sch := cron.New(cron.WithLocation(timezone.Location()))
 
 	schedulerFactory := scheduler.MPPPeriodSchedulerFactory(log)
 	_, err := sch.AddFunc("1 0 * * *", func() {
@@ -90,18 +106,67 @@ func main() {
 		log.Fatalf("failed to add cron job: %v", err)
 	}
 
+	slaCron := viper.GetString("sla.cron")
+	if slaCron == "" {
+		slaCron = "0 * * * *"
+	}
+	approvalSLAScheduler := scheduler.ApprovalSLASchedulerFactory(viper, log)
+	_, err = sch.AddFunc(slaCron, func() {
+		err := approvalSLAScheduler.ProcessOverdueApprovals()
+		if err != nil {
+			log.Errorf("Failed to process overdue approvals: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("failed to add cron job: %v", err)
+	}
+
+	outboxCron := viper.GetString("outbox.cron")
+	if outboxCron == "" {
+		outboxCron = "@every 10s"
+	}
+	outboxRelayScheduler := scheduler.OutboxRelaySchedulerFactory(viper, log)
+	_, err = sch.AddFunc(outboxCron, func() {
+		err := outboxRelayScheduler.RelayPending()
+		if err != nil {
+			log.Errorf("Failed to relay outbox messages: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("failed to add cron job: %v", err)
+	}
+
 	sch.Start()
 	log.Infof("Started cron job")
-	defer sch.Stop()
 
 	// run server
 	webPort := strconv.Itoa(viper.GetInt("web.port"))
-	err = app.Run(":" + webPort)
-	if err != nil {
-		log.Panicf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:    ":" + webPort,
+		Handler: app,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Panicf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Infof("Shutting down")
+
+	// wait for running cron jobs and http requests before closing rabbitmq,
+	// they may still wait for replies
+	<-sch.Stop().Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("Failed to shut down server: %v", err)
 	}
 
-	select {}
+	stopRabbitmq()
+	<-rabbitmqDone
+	log.Infof("Stopped")
 }
 
 func shouldExcludeFromCSRF(path string) bool {
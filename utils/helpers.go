@@ -2,17 +2,31 @@ package utils
 
 import (
 	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/request"
-	"github.com/IlhamSetiaji/julong-manpower-be/internal/http/response"
 )
 
 var ResponseChannel = make(chan map[string]interface{}, 100)
 
-var Rchans = make(map[string](chan response.RabbitMQResponse))
-
+// RabbitMsg is a request message to publish on a queue. The producer reports
+// the publish error, or nil once published, on Result when it is set.
 type RabbitMsg struct {
 	QueueName string                  `json:"queueName"`
 	Message   request.RabbitMQRequest `json:"message"`
+	Result    chan error              `json:"-"`
 }
 
 // channel to publish rabbit messages
 var Pchan = make(chan RabbitMsg, 10)
+
+// EventMsg is a domain event to publish on a topic exchange. The producer
+// reports the broker confirmation of the publish on Result.
+type EventMsg struct {
+	Exchange   string
+	RoutingKey string
+	MessageID  string
+	Version    int
+	Body       []byte
+	Result     chan error
+}
+
+// channel to publish domain events
+var Echan = make(chan EventMsg, 10)
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrVersionRequired = errors.New("the version of the document is required, send it in If-Match or as version")
+	ErrVersionInvalid  = errors.New("If-Match must be the version of the document")
+)
+
+// SetVersion sends the version of a document as its ETag, for the client to
+// send back in If-Match when it changes the document.
+func SetVersion(c *gin.Context, version int) {
+	c.Header("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// RequestVersion is the version of the document the client changes: the
+// If-Match header when it is sent, otherwise version from the body.
+func RequestVersion(c *gin.Context, version int) (int, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		if version <= 0 {
+			return 0, ErrVersionRequired
+		}
+		return version, nil
+	}
+
+	ifMatch = strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	headerVersion, err := strconv.Atoi(ifMatch)
+	if err != nil || headerVersion <= 0 {
+		return 0, ErrVersionInvalid
+	}
+	return headerVersion, nil
+}
+
+// VersionErrorResponse answers a request whose version could not be read.
+func VersionErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, ErrVersionRequired) {
+		ErrorResponse(c, http.StatusPreconditionRequired, "error", err.Error())
+		return
+	}
+	ErrorResponse(c, http.StatusBadRequest, "error", err.Error())
+}
+
+// ConflictResponse answers a change made on an old version of a document
+// with the document as it is now.
+func ConflictResponse(c *gin.Context, message string, current interface{}) {
+	FormatResponse(c, http.StatusConflict, "conflict", message, current)
+}
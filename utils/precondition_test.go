@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func testContext(ifMatch string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+	if ifMatch != "" {
+		c.Request.Header.Set("If-Match", ifMatch)
+	}
+	return c, recorder
+}
+
+func TestRequestVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		version int
+		want    int
+		wantErr error
+	}{
+		{"body version", "", 3, 3, nil},
+		{"no version", "", 0, 0, ErrVersionRequired},
+		{"If-Match wins over the body", `"5"`, 3, 5, nil},
+		{"weak If-Match", `W/"6"`, 0, 6, nil},
+		{"bare If-Match", " 7 ", 0, 7, nil},
+		{"not a version", `"abc"`, 3, 0, ErrVersionInvalid},
+		{"zero", `"0"`, 3, 0, ErrVersionInvalid},
+		{"any", "*", 3, 0, ErrVersionInvalid},
+	}
+
+	for _, tt := range tests {
+		c, _ := testContext(tt.ifMatch)
+		got, err := RequestVersion(c, tt.version)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: RequestVersion = %d, %v, want %d, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestVersionErrorResponse(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrVersionRequired, http.StatusPreconditionRequired},
+		{ErrVersionInvalid, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		c, recorder := testContext("")
+		VersionErrorResponse(c, tt.err)
+		if recorder.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, recorder.Code, tt.want)
+		}
+	}
+}
+
+func TestConflictResponse(t *testing.T) {
+	c, recorder := testContext(`"2"`)
+	SetVersion(c, 3)
+	ConflictResponse(c, "it is at version 3, not 2", map[string]int{"version": 3})
+
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+	if etag := recorder.Header().Get("ETag"); etag != `"3"` {
+		t.Errorf("ETag = %s, want \"3\"", etag)
+	}
+
+	var response struct {
+		Meta Meta           `json:"meta"`
+		Data map[string]int `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Meta.Status != "conflict" || response.Data["version"] != 3 {
+		t.Errorf("response = %+v, want the current document", response)
+	}
+}
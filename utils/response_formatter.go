@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"io"
 	"net/http"
 
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/export"
+	"github.com/IlhamSetiaji/julong-manpower-be/internal/timezone"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,6 +20,8 @@ type Response struct {
 	Data interface{} `json:"data,omitempty"`
 }
 
+// FormatResponse writes data with its timestamps in the business timezone;
+// they are kept in UTC everywhere else.
 func FormatResponse(c *gin.Context, code int, status string, message string, data interface{}) {
 	c.JSON(code, Response{
 		Meta: Meta{
@@ -24,7 +29,7 @@ func FormatResponse(c *gin.Context, code int, status string, message string, dat
 			Status:  status,
 			Message: message,
 		},
-		Data: data,
+		Data: timezone.Localize(data),
 	})
 }
 
@@ -53,3 +58,54 @@ func BadRequestResponse(c *gin.Context, message string, data interface{}) {
 func SuccessResponse(c *gin.Context, code int, message string, data interface{}) {
 	FormatResponse(c, code, "success", message, data)
 }
+
+// ExportResponse streams the output of write to the client as a file
+// download. The headers go out with the first bytes written, so an error
+// before anything was written still gets a JSON error response; after that
+// the download can only be cut short.
+func ExportResponse(c *gin.Context, format export.Format, fileName string, write func(w io.Writer) error) error {
+	w := &exportWriter{c: c, format: format, fileName: fileName}
+	if err := write(w); err != nil {
+		if w.started {
+			c.Abort()
+		} else {
+			ErrorResponse(c, http.StatusInternalServerError, "error", err.Error())
+		}
+		return err
+	}
+
+	w.start()
+	c.Writer.Flush()
+	return nil
+}
+
+type exportWriter struct {
+	c        *gin.Context
+	format   export.Format
+	fileName string
+	started  bool
+}
+
+func (w *exportWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.c.Header("Content-Type", w.format.ContentType())
+	w.c.Header("Content-Disposition", `attachment; filename="`+w.format.FileName(w.fileName)+`"`)
+	w.c.Status(http.StatusOK)
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.start()
+	return w.c.Writer.Write(p)
+}
+
+// DocumentResponse sends a stored document for the browser to show, named
+// fileName when it is saved. The id of its attachment goes along in the
+// X-Attachment-Id header.
+func DocumentResponse(c *gin.Context, attachmentID string, fileName string, filePath string) {
+	c.Header("Content-Disposition", `inline; filename="`+fileName+`"`)
+	c.Header("X-Attachment-Id", attachmentID)
+	c.File(filePath)
+}